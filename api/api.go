@@ -0,0 +1,10 @@
+// Package api provides the OpenAPI description of the REST API, from which
+// clients in other languages are generated.
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 description of the REST API in YAML.
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
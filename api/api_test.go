@@ -0,0 +1,67 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// refs returns the $ref values anywhere in node.
+func refs(node interface{}) []string {
+	var found []string
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				found = append(found, ref)
+				continue
+			}
+			found = append(found, refs(child)...)
+		}
+	case []interface{}:
+		for _, child := range value {
+			found = append(found, refs(child)...)
+		}
+	}
+	return found
+}
+
+func TestOpenAPI(t *testing.T) {
+	var spec map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(OpenAPI, &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+
+	// Every reference resolves within the document
+	for _, ref := range refs(spec) {
+		require.True(t, strings.HasPrefix(ref, "#/"), ref)
+		var node interface{} = spec
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			object, ok := node.(map[string]interface{})
+			require.True(t, ok, ref)
+			node, ok = object[part]
+			require.True(t, ok, "unresolved reference %s", ref)
+		}
+	}
+
+	// Generated clients name their methods after the unique operation IDs
+	operations := make(map[string]bool)
+	paths := spec["paths"].(map[string]interface{})
+	for path, item := range paths {
+		for method, operation := range item.(map[string]interface{}) {
+			if method == "parameters" {
+				continue
+			}
+			operation := operation.(map[string]interface{})
+			id, _ := operation["operationId"].(string)
+			require.NotEmpty(t, id, "%s %s has no operationId", method, path)
+			assert.False(t, operations[id], "duplicate operationId %s", id)
+			operations[id] = true
+			assert.NotEmpty(t, operation["responses"], "%s %s has no responses", method, path)
+		}
+	}
+	assert.Contains(t, operations, "submitSBOM")
+	assert.Contains(t, operations, "analyzeSBOM")
+}
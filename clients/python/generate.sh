@@ -0,0 +1,23 @@
+#!/usr/bin/env bash
+# Generates the sbom-sentinel Python package from api/openapi.yaml into
+# clients/python/sbom-sentinel with OpenAPI Generator, run from its Docker
+# image so that no Java toolchain is needed.
+#
+#   clients/python/generate.sh [version]
+#
+# The version defaults to 0.0.0.dev0; releases pass the tag without its v.
+set -euo pipefail
+
+version="${1:-0.0.0.dev0}"
+root="$(cd "$(dirname "$0")/../.." && pwd)"
+output=clients/python/sbom-sentinel
+generator=docker.io/openapitools/openapi-generator-cli:v7.10.0
+
+rm -rf "${root:?}/$output"
+docker run --rm --user "$(id -u):$(id -g)" -v "$root:/local" "$generator" generate \
+  --input-spec /local/api/openapi.yaml \
+  --generator-name python \
+  --output "/local/$output" \
+  --additional-properties "packageName=sbom_sentinel,projectName=sbom-sentinel,packageVersion=$version,packageUrl=https://github.com/hueyexe/SBOM-Sentinel"
+
+echo "Generated $output $version; build it with: python -m build $output"
@@ -0,0 +1,141 @@
+// Package cmd provides the admin commands for backing up and migrating SBOM Sentinel.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/backup"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// adminCmd groups the commands administering an SBOM Sentinel instance
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Back up, restore and migrate SBOM Sentinel instances",
+	Long: `Administer an SBOM Sentinel instance.
+
+export and import move the stored SBOMs and the Rego policies (including data
+files such as waivers) between instances as a single archive, without copying
+the raw database. Analysis results are not stored, so they are not exported;
+re-run the analyses after an import.`,
+}
+
+// adminExportCmd writes a backup archive
+var adminExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the SBOM database and policies to an archive",
+	Long: `Export every stored SBOM, with its submission time, and the policy and data
+files given with --policy (defaults to $POLICY_PATH) to a gzip-compressed tar
+archive.`,
+	Args: cobra.NoArgs,
+	RunE: runAdminExport,
+}
+
+// adminImportCmd restores a backup archive
+var adminImportCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import the SBOMs and policies in an export archive",
+	Long: `Import an archive created by 'sentinel-cli admin export'. SBOMs replace any
+stored SBOM with the same ID and keep their original submission times. Policy
+files are written to --policy-dir; existing files are kept unless
+--overwrite-policies is set.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdminImport,
+}
+
+func init() {
+	rootCmd.AddCommand(adminCmd)
+	adminCmd.AddCommand(adminExportCmd)
+	adminCmd.AddCommand(adminImportCmd)
+
+	adminCmd.PersistentFlags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+	adminExportCmd.Flags().StringP("out", "o", "", "Archive to write, e.g. backup.tar.gz (required)")
+	adminExportCmd.Flags().StringSlice("policy", nil, "Policy files or directories to include (defaults to $POLICY_PATH)")
+	adminImportCmd.Flags().String("policy-dir", "./policies", "Directory the archived policies are written to; empty skips them")
+	adminImportCmd.Flags().Bool("overwrite-policies", false, "Overwrite existing policy files")
+
+	adminExportCmd.MarkFlagRequired("out")
+}
+
+// runAdminExport executes the admin export command
+func runAdminExport(cmd *cobra.Command, args []string) error {
+	out, _ := cmd.Flags().GetString("out")
+
+	policies, _ := cmd.Flags().GetStringSlice("policy")
+	if !cmd.Flags().Changed("policy") {
+		for _, path := range strings.Split(os.Getenv("POLICY_PATH"), ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				policies = append(policies, path)
+			}
+		}
+	}
+
+	dbPath, _ := cmd.Flags().GetString("db")
+	dbPath = wiring.DatabasePath(dbPath)
+
+	repo, err := wiring.OpenRepository(dbPath)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	file, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	manifest, err := backup.Export(ctx, file, repo, policies)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		// Never leave a partial archive that could be mistaken for a backup
+		os.Remove(out)
+		return err
+	}
+
+	fmt.Printf("📦 Exported %d SBOMs and %d policy files from %s to %s\n", manifest.SBOMs, len(manifest.Policies), dbPath, out)
+	return nil
+}
+
+// runAdminImport executes the admin import command
+func runAdminImport(cmd *cobra.Command, args []string) error {
+	policyDir, _ := cmd.Flags().GetString("policy-dir")
+	overwrite, _ := cmd.Flags().GetBool("overwrite-policies")
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	dbPath, _ := cmd.Flags().GetString("db")
+	dbPath = wiring.DatabasePath(dbPath)
+
+	repo, err := wiring.OpenRepository(dbPath)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := backup.Import(ctx, file, repo, policyDir, overwrite)
+	if result != nil {
+		fmt.Printf("📥 Imported %d SBOMs into %s\n", result.SBOMs, dbPath)
+		if policyDir != "" && len(result.Manifest.Policies) > 0 {
+			skipped := len(result.Manifest.Policies) - len(result.Policies)
+			fmt.Printf("📜 Wrote %d policy files to %s (%d skipped)\n", len(result.Policies), policyDir, skipped)
+		}
+	}
+	return err
+}
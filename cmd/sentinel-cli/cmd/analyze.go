@@ -2,13 +2,29 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ignore"
 	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/registry"
+	"github.com/hueyexe/SBOM-Sentinel/internal/plugin"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/hueyexe/SBOM-Sentinel/internal/report"
+	"github.com/hueyexe/SBOM-Sentinel/internal/rules"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
 	"github.com/spf13/cobra"
 )
 
@@ -20,9 +36,19 @@ var analyzeCmd = &cobra.Command{
 
 Currently supports:
 - CycloneDX JSON format
+- Compiled Go binaries (embedded module build info)
 - License compliance analysis
 - AI-powered dependency health analysis (with --enable-ai-health-check)
 - Proactive vulnerability discovery using RAG (with --enable-proactive-scan)
+- SBOM quality scoring against NTIA minimum elements (with --enable-quality-check)
+- Cryptographic library inventory (with --enable-crypto-check)
+- Export-control tagging of encryption and networking components (with --enable-export-check)
+- Base image staleness of container images (with --enable-base-image-check)
+- Archived and abandoned source repositories on GitHub and GitLab (with --enable-repo-activity-check)
+
+Use --profile to enable a named bundle of agents: "quick" (known
+vulnerabilities), "compliance-only" (quality scoring), "full" (every agent),
+or a profile defined in the configuration file.
 
 The command will parse the SBOM file and display information about the
 components found within it, along with any security or compliance findings.`,
@@ -34,11 +60,30 @@ func init() {
 	rootCmd.AddCommand(analyzeCmd)
 
 	// Add flags specific to the analyze command
-	analyzeCmd.Flags().StringP("format", "f", "auto", "SBOM format (auto, cyclonedx)")
+	analyzeCmd.Flags().StringP("format", "f", "auto", "SBOM format (auto, cyclonedx, gobinary)")
 	analyzeCmd.Flags().BoolP("summary", "s", false, "Show only summary information")
+	addProfileFlag(analyzeCmd)
+	addPluginFlag(analyzeCmd)
+	addRulesFlag(analyzeCmd)
 	analyzeCmd.Flags().Bool("enable-ai-health-check", false, "Enable AI-powered dependency health analysis (requires Ollama)")
 	analyzeCmd.Flags().Bool("enable-proactive-scan", false, "Enable proactive vulnerability discovery using RAG (requires Ollama)")
+	addRAGFlags(analyzeCmd)
+	addPullModelsFlag(analyzeCmd)
 	analyzeCmd.Flags().Bool("enable-vuln-scan", false, "Enable known vulnerability scanning using OSV.dev database")
+	analyzeCmd.Flags().Bool("enable-quality-check", false, "Enable SBOM quality scoring against NTIA minimum elements")
+	analyzeCmd.Flags().Bool("enable-crypto-check", false, "Enable cryptographic library inventory with FIPS and post-quantum concerns")
+	analyzeCmd.Flags().Bool("enable-export-check", false, "Enable export-control tagging of encryption and networking components")
+	analyzeCmd.Flags().Bool("enable-base-image-check", false, "Enable base image staleness checks for container image SBOMs")
+	analyzeCmd.Flags().Bool("enable-repo-activity-check", false, "Enable activity checks of component source repositories on GitHub and GitLab")
+	analyzeCmd.Flags().StringSlice("policy", nil, "Rego policy files or directories (and data files such as waivers) gating the results; violations fail the command (requires opa)")
+	analyzeCmd.Flags().String("min-severity", "", "Hide findings below this severity (critical, high, medium, low, info); policies and notifications still see them")
+	analyzeCmd.Flags().Float64("min-confidence", 0, "Hide AI-derived findings whose LLM confidence is below this value (0 to 1); policies and notifications still see them")
+	analyzeCmd.Flags().Bool("no-group", false, "List every finding separately instead of grouping findings shared by several components")
+	analyzeCmd.Flags().StringSlice("license-ignore-scopes", []string{"excluded"}, "Component scopes skipped by license analysis (required, optional, excluded)")
+	addProjectFlags(analyzeCmd)
+	addTagFlag(analyzeCmd)
+	addIgnoreFlag(analyzeCmd)
+	addNotifyFlag(analyzeCmd)
 }
 
 // runAnalyze executes the analyze command
@@ -49,130 +94,223 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	verbose, _ := cmd.Flags().GetBool("verbose")
 	summary, _ := cmd.Flags().GetBool("summary")
 	format, _ := cmd.Flags().GetString("format")
-	enableAIHealthCheck, _ := cmd.Flags().GetBool("enable-ai-health-check")
-	enableProactiveScan, _ := cmd.Flags().GetBool("enable-proactive-scan")
-	enableVulnScan, _ := cmd.Flags().GetBool("enable-vuln-scan")
-
-	if verbose {
-		fmt.Printf("Analyzing SBOM file: %s\n", filePath)
-		fmt.Printf("Format: %s\n", format)
+	licenseIgnoreScopes, _ := cmd.Flags().GetStringSlice("license-ignore-scopes")
+	minSeverity, _ := cmd.Flags().GetString("min-severity")
+	noGroup, _ := cmd.Flags().GetBool("no-group")
+	if minSeverity != "" {
+		if err := core.ValidateSeverityThreshold(minSeverity); err != nil {
+			return fmt.Errorf("invalid --min-severity: %w", err)
+		}
+	}
+	minConfidence, _ := cmd.Flags().GetFloat64("min-confidence")
+	if minConfidence < 0 || minConfidence > 1 {
+		return fmt.Errorf("invalid --min-confidence %v (expected a number from 0 to 1)", minConfidence)
 	}
 
-	// Open the file
-	file, err := os.Open(filePath)
+	selection, err := agentSelection(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to open file '%s': %w", filePath, err)
+		return err
 	}
-	defer file.Close()
 
-	// For now, we only support CycloneDX JSON format
-	// In the future, we could auto-detect format or support multiple parsers
-	parser := ingestion.NewCycloneDXParser()
-
-	// Parse the SBOM
-	sbom, err := parser.Parse(file)
+	tags, err := tagFlag(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to parse SBOM: %w", err)
+		return err
 	}
 
-	// Display results
-	fmt.Printf("✅ Successfully parsed SBOM: %s\n", sbom.Name)
-	fmt.Printf("📦 Found %d components\n", len(sbom.Components))
-
-	// Run analysis agents
-	ctx := context.Background()
-	var allAnalysisResults []core.AnalysisResult
+	ignores, err := ignoreList(cmd)
+	if err != nil {
+		return err
+	}
 
-	// Run license analysis
-	licenseAgent := analysis.NewLicenseAgent()
+	// Load the gate policies up front so that a bad path fails before analysis
+	var gate policy.Evaluator
+	if policies, _ := cmd.Flags().GetStringSlice("policy"); len(policies) > 0 {
+		gate, err = policy.NewRegoEvaluator(policies...)
+		if err != nil {
+			return err
+		}
+	}
 
 	if verbose {
-		fmt.Printf("🔍 Running license analysis...\n")
+		fmt.Printf("Analyzing SBOM file: %s\n", filePath)
+		fmt.Printf("Format: %s\n", format)
 	}
 
-	licenseResults, err := licenseAgent.Analyze(ctx, *sbom)
+	sbom, normalization, err := loadSBOMFile(filePath, format, verbose)
 	if err != nil {
-		return fmt.Errorf("failed to run license analysis: %w", err)
+		return err
 	}
-	allAnalysisResults = append(allAnalysisResults, licenseResults...)
+	// Policies see the tags of --tag and of the SBOM's project
+	sbom.Tags = tags
+	sbom.Tags = sbom.EffectiveTags(selection.Projects)
 
-	// Run AI health check if enabled
-	if enableAIHealthCheck {
-		healthAgent := analysis.NewDependencyHealthAgent()
+	// Display results
+	fmt.Printf("✅ Successfully parsed SBOM: %s\n", sbom.Name)
+	fmt.Printf("📦 Found %d components\n", len(sbom.Components))
 
-		if verbose {
-			fmt.Printf("🤖 Running AI-powered dependency health analysis...\n")
+	if normalization.HasChanges() {
+		fmt.Printf("🧹 Normalized %d PURLs, %d licenses and removed %d duplicate components\n",
+			normalization.PURLsNormalized, normalization.LicensesCanonicalized, normalization.DuplicatesRemoved)
+		if normalization.InvalidPURLs > 0 {
+			fmt.Printf("⚠️  Removed %d invalid PURLs (use --verbose for details)\n", normalization.InvalidPURLs)
 		}
 
-		healthResults, err := healthAgent.Analyze(ctx, *sbom)
-		if err != nil {
-			fmt.Printf("Warning: AI health analysis failed: %v\n", err)
-		} else {
-			allAnalysisResults = append(allAnalysisResults, healthResults...)
+		if verbose {
+			for _, change := range normalization.Changes {
+				fmt.Printf("   • %s\n", change)
+			}
 		}
 	}
 
-	// Run proactive vulnerability scan if enabled
-	if enableProactiveScan {
-		proactiveAgent := analysis.NewProactiveVulnerabilityAgent()
+	// Run analysis agents, stopping on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-		if verbose {
-			fmt.Printf("🔍 Running proactive vulnerability discovery using RAG...\n")
-		}
+	timeouts, err := analysis.AgentTimeoutsFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid agent timeout configuration: %v\n", err)
+	}
 
-		proactiveResults, err := proactiveAgent.Analyze(ctx, *sbom)
-		if err != nil {
-			fmt.Printf("Warning: Proactive vulnerability scan failed: %v\n", err)
-		} else {
-			allAnalysisResults = append(allAnalysisResults, proactiveResults...)
+	// License analysis always runs and must succeed
+	selection.RequireLicense = true
+	selection.LicenseIgnoreScopes = licenseIgnoreScopes
+	selection.ConfigureProactive = func(agent *analysis.ProactiveVulnerabilityAgent) {
+		configureRAG(cmd, agent)
+	}
+	orchestrator := wiring.NewOrchestrator(timeouts, selection)
+
+	if verbose {
+		fmt.Printf("🔍 Running %d analysis agents concurrently...\n", len(orchestrator.Agents()))
+		for _, agent := range orchestrator.Agents() {
+			fmt.Printf(" • %s\n", agent.Name())
+			if parameterized, ok := agent.(analysis.ParameterizedAgent); ok {
+				printParameters(parameterized)
+			}
 		}
 	}
 
-	// Run vulnerability scan if enabled
-	if enableVulnScan {
-		vulnAgent := analysis.NewVulnerabilityScanningAgent()
+	if err := prepareModels(ctx, cmd, orchestrator); err != nil {
+		return err
+	}
 
-		if verbose {
-			fmt.Printf("🔍 Running known vulnerability scan using OSV.dev...\n")
-		}
+	analysisReport, err := orchestrator.Run(ctx, *sbom)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	// Accepted findings of the ignore file are left out of the results
+	allAnalysisResults, ignoredResults := ignores.Apply(analysisReport.Results)
+	allAnalysisResults, err = assignOwners(cmd, allAnalysisResults)
+	if err != nil {
+		return err
+	}
 
-		vulnResults, err := vulnAgent.Analyze(ctx, *sbom)
-		if err != nil {
-			fmt.Printf("Warning: Vulnerability scan failed: %v\n", err)
-		} else {
-			allAnalysisResults = append(allAnalysisResults, vulnResults...)
+	if verbose {
+		for _, run := range analysisReport.Runs {
+			fmt.Printf("   %s: %s in %s (%d findings)\n", run.Agent, run.Status, run.Duration.Round(time.Millisecond), run.Findings)
+			if usage := run.LLMUsage; usage != nil {
+				fmt.Printf("     LLM: %d requests, %d prompt + %d completion tokens in %s\n", usage.Requests, usage.PromptTokens, usage.CompletionTokens, usage.Duration.Round(time.Millisecond))
+			}
+		}
+	}
+	if failures := analysisReport.Failures(); len(failures) > 0 {
+		fmt.Printf("⚠️  Results are incomplete: %d agents did not finish\n", len(failures))
+		for _, run := range failures {
+			fmt.Printf("   • %s (%s): %v\n", run.Agent, run.Status, run.Err)
 		}
 	}
 
 	// Display analysis results if any findings were detected
 	if len(allAnalysisResults) > 0 {
 		fmt.Printf("\n🔬 Analysis Results:\n")
-		fmt.Printf("   Found %d issues:\n\n", len(allAnalysisResults))
-
-		for i, result := range allAnalysisResults {
-			severityIcon := getSeverityIcon(result.Severity)
-			fmt.Printf("   %d. %s [%s] %s\n", i+1, severityIcon, result.Severity, result.AgentName)
-			fmt.Printf("      %s\n", result.Finding)
-			if i < len(allAnalysisResults)-1 {
+		risk := analysis.ScoreRisk(allAnalysisResults)
+		fmt.Printf("   Found %d issues, risk score %d/100 (%s):\n\n", len(allAnalysisResults), risk.Score, risk.Level)
+
+		shownResults := core.FilterBySeverity(allAnalysisResults, minSeverity)
+		lowConfidence := len(shownResults)
+		shownResults = core.FilterByConfidence(shownResults, minConfidence)
+		lowConfidence -= len(shownResults)
+		// Findings shared by several components are listed once
+		groups := report.GroupFindings(shownResults)
+		if noGroup {
+			groups = report.UngroupedFindings(shownResults)
+		}
+		for i, group := range groups {
+			severityIcon := getSeverityIcon(group.Severity)
+			fmt.Printf("   %d. %s [%s] %s", i+1, severityIcon, group.Severity, group.AgentName)
+			if len(group.Results) > 1 {
+				fmt.Printf(" (%d components)", len(group.Results))
+			}
+			fmt.Printf("\n      %s\n", group.Finding)
+			if len(group.Results) > 1 {
+				fmt.Printf("      📦 %s\n", group.ComponentsLabel())
+			}
+			if owners := group.Owners(); len(owners) > 0 {
+				fmt.Printf("      👥 %s\n", strings.Join(owners, ", "))
+			}
+			if remediation := group.Remediation(); remediation != "" {
+				fmt.Printf("      🔧 %s\n", remediation)
+			}
+			if len(group.Results) == 1 && group.Results[0].Confidence != nil {
+				fmt.Printf("      🎯 Confidence %.0f%%\n", *group.Results[0].Confidence*100)
+			}
+			if len(group.Results) == 1 {
+				for _, citation := range group.Results[0].Citations {
+					fmt.Printf("      ↳ [%s] %s (%.0f%% match)\n", citation.ID, citation.Title, citation.Similarity*100)
+				}
+			}
+			if i < len(groups)-1 {
 				fmt.Printf("\n")
 			}
 		}
+		if hidden := len(allAnalysisResults) - len(shownResults); hidden > 0 {
+			if len(shownResults) > 0 {
+				fmt.Printf("\n")
+			}
+			if hidden > lowConfidence {
+				fmt.Printf("   (%d findings below %s hidden by --min-severity)\n", hidden-lowConfidence, minSeverity)
+			}
+			if lowConfidence > 0 {
+				fmt.Printf("   (%d findings below %.2f confidence hidden by --min-confidence)\n", lowConfidence, minConfidence)
+			}
+		}
 	} else {
 		fmt.Printf("\n✅ Analysis Complete: No issues detected\n")
-		if !enableAIHealthCheck {
+		if !selection.AIHealthCheck {
 			fmt.Printf("   💡 Tip: Use --enable-ai-health-check for AI-powered dependency health analysis\n")
 		}
-		if !enableProactiveScan {
+		if !selection.ProactiveScan {
 			fmt.Printf("   🔍 Tip: Use --enable-proactive-scan for proactive vulnerability discovery using RAG\n")
 		}
-		if !enableVulnScan {
+		if !selection.VulnScan {
 			fmt.Printf("   🛡️  Tip: Use --enable-vuln-scan for known vulnerability scanning using OSV.dev\n")
 		}
+		if !selection.QualityCheck {
+			fmt.Printf("   📏 Tip: Use --enable-quality-check for SBOM quality scoring\n")
+		}
+		if !selection.CryptoCheck {
+			fmt.Printf("   🔐 Tip: Use --enable-crypto-check for a cryptographic library inventory\n")
+		}
+		if !selection.ExportCheck {
+			fmt.Printf("   🌍 Tip: Use --enable-export-check for export-control tagging\n")
+		}
+		if !selection.BaseImageCheck && sbom.Metadata["componentType"] == "container" {
+			fmt.Printf("   🐳 Tip: Use --enable-base-image-check to check the container's base image\n")
+		}
+	}
+
+	if len(ignoredResults) > 0 {
+		fmt.Printf("\n🙈 Ignored Findings (%d):\n", len(ignoredResults))
+		for _, ignored := range ignoredResults {
+			fmt.Printf("   • %s [%s] %s\n", getSeverityIcon(ignored.Severity), ignored.Severity, ignored.Finding)
+			fmt.Printf("     ↳ %s: %s\n", ignored.Entry, ignored.Reason)
+		}
 	}
 
 	if !summary {
 		fmt.Printf("\n📋 SBOM Details:\n")
-		fmt.Printf("   ID: %s\n", sbom.ID)
+		if sbom.ID != "" {
+			fmt.Printf("   ID: %s\n", sbom.ID)
+		}
 		fmt.Printf("   Name: %s\n", sbom.Name)
 
 		if len(sbom.Metadata) > 0 {
@@ -194,21 +332,361 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 				if component.Version != "" {
 					fmt.Printf(" v%s", component.Version)
 				}
-				if component.License != "" {
-					fmt.Printf(" (%s)", component.License)
+				if licenses := component.DeclaredLicenses(); len(licenses) > 0 {
+					fmt.Printf(" (%s)", strings.Join(licenses, ", "))
+				}
+				if scope := component.EffectiveScope(); scope != core.ScopeRequired {
+					fmt.Printf(" [%s]", scope)
 				}
 				fmt.Printf("\n")
 
 				if verbose && component.PURL != "" {
 					fmt.Printf("     PURL: %s\n", component.PURL)
 				}
+				if verbose && component.Type != "" {
+					fmt.Printf("     Type: %s\n", component.Type)
+				}
 			}
 		}
+
+		if len(sbom.Services) > 0 {
+			fmt.Printf("\n🌐 Services:\n")
+			for _, service := range sbom.Services {
+				fmt.Printf("   • %s", service.Name)
+				if service.Version != "" {
+					fmt.Printf(" v%s", service.Version)
+				}
+				if service.Provider != "" {
+					fmt.Printf(" (%s)", service.Provider)
+				}
+				if service.CrossesTrustBoundary {
+					fmt.Printf(" [crosses trust boundary]")
+				}
+				fmt.Printf("\n")
+
+				if verbose {
+					for _, endpoint := range service.Endpoints {
+						fmt.Printf("     Endpoint: %s\n", endpoint)
+					}
+				}
+			}
+		}
+	}
+
+	if notifyChannels, _ := cmd.Flags().GetBool("notify"); notifyChannels {
+		sendNotifications(ctx, cmd, *sbom, allAnalysisResults)
+	}
+
+	if gate != nil {
+		input := policy.NewInput(*sbom, allAnalysisResults, analysisReport.AgentStatus())
+		decision, err := gate.Evaluate(ctx, input)
+		if err != nil {
+			return fmt.Errorf("policy evaluation failed: %w", err)
+		}
+
+		if decision.Passed() {
+			fmt.Printf("\n🚦 Policy Gate: ✅ passed\n")
+		} else {
+			fmt.Printf("\n🚦 Policy Gate: ❌ %d violations\n", len(decision.Violations))
+			for _, violation := range decision.Violations {
+				fmt.Printf("   • %s\n", violation)
+			}
+			return fmt.Errorf("policy gate failed with %d violations", len(decision.Violations))
+		}
+	}
+
+	return nil
+}
+
+// loadSBOMFile parses the SBOM file at filePath in the given format,
+// detecting it from the file header for ingestion.FormatAuto, and normalizes
+// its PURLs, licenses and duplicate components. An oci:// filePath fetches
+// the SBOM attached to that container image instead.
+func loadSBOMFile(filePath, format string, verbose bool) (*core.SBOM, ingestion.NormalizationReport, error) {
+	var source io.Reader
+	var image string
+	if strings.HasPrefix(filePath, registry.OCIScheme) {
+		// An SBOM attached to a container image
+		attached, err := fetchAttachedSBOM(context.Background(), filePath)
+		if err != nil {
+			return nil, ingestion.NormalizationReport{}, err
+		}
+		if verbose {
+			fmt.Printf("Fetched SBOM attached to %s (%s)\n", attached.image, attached.Source)
+		}
+		source, image = bytes.NewReader(attached.Data), attached.image
+	} else {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, ingestion.NormalizationReport{}, fmt.Errorf("failed to open file '%s': %w", filePath, err)
+		}
+		defer file.Close()
+		source = file
 	}
 
+	// Auto-detect the format from the file header if not given explicitly
+	reader := bufio.NewReader(source)
+	if format == ingestion.FormatAuto {
+		header, _ := reader.Peek(4)
+		format = ingestion.DetectFormat(header)
+
+		if verbose {
+			fmt.Printf("Detected format: %s\n", format)
+		}
+	}
+
+	parser, err := ingestion.NewParser(format)
+	if err != nil {
+		return nil, ingestion.NormalizationReport{}, err
+	}
+
+	sbom, err := parser.Parse(reader)
+	if err != nil {
+		return nil, ingestion.NormalizationReport{}, fmt.Errorf("failed to parse SBOM: %w", err)
+	}
+	if image != "" {
+		recordImage(sbom, image)
+	}
+
+	return sbom, ingestion.NewNormalizer().Normalize(sbom), nil
+}
+
+// addRAGFlags adds the retrieval flags of the proactive vulnerability scan to cmd.
+func addRAGFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("rag-top-k", analysis.DefaultRAGTopK, "Intelligence documents retrieved per component by the proactive scan (defaults to $RAG_TOP_K)")
+	cmd.Flags().Float64("rag-similarity-threshold", analysis.DefaultRAGSimilarityThreshold, "Minimum similarity (0-1) of documents used by the proactive scan (defaults to $RAG_SIMILARITY_THRESHOLD)")
+}
+
+// addPullModelsFlag registers the --pull-models flag pulling missing Ollama models.
+func addPullModelsFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("pull-models", false, "Pull Ollama models the AI-powered agents need but the server lacks, instead of failing (defaults to $OLLAMA_PULL_MODELS)")
+}
+
+// prepareModels makes sure the Ollama models of the AI-powered agents are
+// available before the analysis starts, so that a missing model fails the
+// command at once instead of every component's prompt timing out.
+func prepareModels(ctx context.Context, cmd *cobra.Command, orchestrator *analysis.Orchestrator) error {
+	if cmd.Flags().Changed("pull-models") {
+		pull, _ := cmd.Flags().GetBool("pull-models")
+		orchestrator.SetPullModels(pull)
+	}
+	if err := orchestrator.PrepareModels(ctx); err != nil {
+		return fmt.Errorf("AI-powered agents cannot run: %w", err)
+	}
 	return nil
 }
 
+// addProfileFlag registers the --profile flag selecting a named bundle of agents.
+func addProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().String("profile", "", "Analysis profile enabling a named bundle of agents (quick, compliance-only, full or one defined in the config file)")
+}
+
+// addPluginFlag registers the --plugin-dir flag loading third-party agents.
+func addPluginFlag(cmd *cobra.Command) {
+	cmd.Flags().String("plugin-dir", "", "Directory of sentinel-agent-* plugin executables run as additional agents (defaults to $SENTINEL_PLUGIN_DIR)")
+}
+
+// addRulesFlag registers the --rules-file flag enabling the rule engine.
+func addRulesFlag(cmd *cobra.Command) {
+	cmd.Flags().String("rules-file", "", "YAML file of CEL expression rules run by the rule engine agent (defaults to $SENTINEL_RULES_FILE)")
+}
+
+// addIgnoreFlag registers the --ignore-file flag excluding accepted findings.
+func addIgnoreFlag(cmd *cobra.Command) {
+	cmd.Flags().String("ignore-file", "", "File of accepted findings to exclude, each with a reason (defaults to "+ignore.FileName+" in the working directory, if present)")
+}
+
+// ignoreList loads the file named by --ignore-file or, without the flag,
+// the .sentinelignore file of the working directory. It returns nil if
+// there is no ignore file.
+func ignoreList(cmd *cobra.Command) (*ignore.List, error) {
+	path, _ := cmd.Flags().GetString("ignore-file")
+	if path == "" {
+		if _, err := os.Stat(ignore.FileName); err != nil {
+			return nil, nil
+		}
+		path = ignore.FileName
+	}
+	return ignore.LoadFile(path)
+}
+
+// addProjectFlags adds the flags declaring the analyzed project's license context.
+func addProjectFlags(cmd *cobra.Command) {
+	cmd.Flags().String("project-license", "", "SPDX license the project itself is released under; components it already covers are rated Low")
+	cmd.Flags().String("distribution", "", "How the project is distributed (saas, distributed, internal); rates license findings by the obligations it triggers")
+}
+
+// addTagFlag adds the --tag flag labelling the analyzed SBOM.
+func addTagFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArray("tag", nil, "Tag of the SBOM as key=value, such as env=prod, seen by policies in input.sbom.tags; may be repeated")
+}
+
+// tagFlag parses the tags given with --tag.
+func tagFlag(cmd *cobra.Command) (map[string]string, error) {
+	pairs, _ := cmd.Flags().GetStringArray("tag")
+	tags, err := core.ParseTags(pairs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --tag: %w", err)
+	}
+	return tags, nil
+}
+
+// agentSelection resolves the agents enabled by --profile and the --enable-*
+// flags; enable flags given explicitly override the profile. Project license
+// contexts come from the config file, overridden by --project-license and
+// --distribution where the command has them.
+func agentSelection(cmd *cobra.Command) (wiring.AgentSelection, error) {
+	var selection wiring.AgentSelection
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return selection, err
+	}
+	if name, _ := cmd.Flags().GetString("profile"); name != "" {
+		profile, err := cfg.Profile(name)
+		if err != nil {
+			return selection, err
+		}
+		selection = wiring.ProfileSelection(profile)
+	}
+
+	for flag, enabled := range map[string]*bool{
+		"enable-ai-health-check": &selection.AIHealthCheck,
+		"enable-proactive-scan":  &selection.ProactiveScan,
+		"enable-vuln-scan":       &selection.VulnScan,
+		"enable-quality-check":   &selection.QualityCheck,
+		"enable-crypto-check":    &selection.CryptoCheck,
+		"enable-export-check":    &selection.ExportCheck,
+
+		"enable-base-image-check":    &selection.BaseImageCheck,
+		"enable-repo-activity-check": &selection.RepoActivityCheck,
+	} {
+		if cmd.Flags().Changed(flag) {
+			*enabled, _ = cmd.Flags().GetBool(flag)
+		}
+	}
+
+	selection.Projects = cfg.Projects
+	selection.ExportRules = cfg.ExportControlRules
+	selection.VulnerableBaseImages = cfg.VulnerableBaseImages
+	selection.Plugins = pluginAgents(cmd)
+	wasmRules, err := plugin.WasmAgents(cfg.Rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Some rules are skipped: %v\n", err)
+	}
+	selection.Rules = wasmRules
+
+	rulesFile, _ := cmd.Flags().GetString("rules-file")
+	if rulesFile == "" {
+		rulesFile = os.Getenv("SENTINEL_RULES_FILE")
+	}
+	if rulesFile != "" {
+		expressionRules, err := rules.LoadFile(rulesFile)
+		if err != nil {
+			return selection, err
+		}
+		selection.RuleEngine = rules.NewAgent(expressionRules)
+	}
+
+	selection.ProjectOverride.License, _ = cmd.Flags().GetString("project-license")
+	selection.ProjectOverride.Distribution, _ = cmd.Flags().GetString("distribution")
+	if err := selection.ProjectOverride.Validate(); err != nil {
+		return selection, err
+	}
+	return selection, nil
+}
+
+// pluginAgents loads the plugins in --plugin-dir or $SENTINEL_PLUGIN_DIR.
+// Plugins that cannot be loaded are reported as warnings and skipped.
+func pluginAgents(cmd *cobra.Command) []*plugin.ProxyAgent {
+	dir, _ := cmd.Flags().GetString("plugin-dir")
+	if dir == "" {
+		dir = os.Getenv("SENTINEL_PLUGIN_DIR")
+	}
+	if dir == "" {
+		return nil
+	}
+
+	agents, err := plugin.Discover(context.Background(), dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Some plugins are not loaded: %v\n", err)
+	}
+	return agents
+}
+
+// loadConfig reads the file named by --config, or the default configuration.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		return config.Default(), nil
+	}
+	return config.Load(path)
+}
+
+// addNotifyFlag adds the --notify flag to an analysis command.
+func addNotifyFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("notify", false, "Send findings to the notification channels of the config file (Slack, Teams or webhooks)")
+}
+
+// sendNotifications sends findings to the notification channels of the
+// configuration file. Failures are reported as warnings, as the analysis
+// itself succeeded.
+func sendNotifications(ctx context.Context, cmd *cobra.Command, sbom core.SBOM, results []core.AnalysisResult) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Notifications not sent: %v\n", err)
+		return
+	}
+	if len(cfg.Notifications) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: Notifications not sent: the config file defines no notification channels\n")
+		return
+	}
+	if len(results) == 0 {
+		return
+	}
+
+	notification := notify.Notification{SBOMID: sbom.ID, SBOMName: sbom.Name, Results: results}
+	if err := notify.NewDispatcher(cfg.Notifications).Notify(ctx, notification); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to send notifications: %v\n", err)
+	}
+}
+
+// assignOwners assigns findings to the teams owning their components, by the
+// owners section of the configuration file.
+func assignOwners(cmd *cobra.Command, results []core.AnalysisResult) ([]core.AnalysisResult, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Owners.Assign(results), nil
+}
+
+// configureRAG applies the retrieval flags given on the command line to the
+// proactive agent, leaving the environment configuration for the others.
+func configureRAG(cmd *cobra.Command, agent *analysis.ProactiveVulnerabilityAgent) {
+	if cmd.Flags().Changed("rag-top-k") {
+		topK, _ := cmd.Flags().GetInt("rag-top-k")
+		agent.SetTopK(topK)
+	}
+	if cmd.Flags().Changed("rag-similarity-threshold") {
+		threshold, _ := cmd.Flags().GetFloat64("rag-similarity-threshold")
+		agent.SetSimilarityThreshold(threshold)
+	}
+}
+
+// printParameters prints the effective settings of a parameterized agent.
+func printParameters(agent analysis.ParameterizedAgent) {
+	parameters := agent.Parameters()
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("   %s: %s\n", name, parameters[name])
+	}
+}
+
 // getSeverityIcon returns an appropriate emoji icon for the given severity level.
 func getSeverityIcon(severity string) string {
 	switch severity {
@@ -0,0 +1,169 @@
+// Package cmd provides the analyze-all command for analyzing every stored SBOM.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// analyzeAllCmd represents the analyze-all command
+var analyzeAllCmd = &cobra.Command{
+	Use:   "analyze-all",
+	Short: "Analyze every SBOM stored in the database",
+	Long: `Run the selected analysis agents across every SBOM stored in the
+SBOM Sentinel database and print an organization-wide rollup of findings.
+
+This is useful when a new vulnerability is disclosed and you need to know
+which of your applications are affected. Identical findings reported for
+several SBOMs are merged, and the most severe and widespread issues are
+listed first.
+
+Components shared by several SBOMs are analyzed once by the agents that
+query OSV.dev or the LLM per component, and their findings are copied to
+every SBOM with the component.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeAll,
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeAllCmd)
+
+	analyzeAllCmd.Flags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+	addProfileFlag(analyzeAllCmd)
+	addPluginFlag(analyzeAllCmd)
+	addRulesFlag(analyzeAllCmd)
+	analyzeAllCmd.Flags().Bool("enable-ai-health-check", false, "Enable AI-powered dependency health analysis (requires Ollama)")
+	analyzeAllCmd.Flags().Bool("enable-proactive-scan", false, "Enable proactive vulnerability discovery using RAG (requires Ollama)")
+	addPullModelsFlag(analyzeAllCmd)
+	addRAGFlags(analyzeAllCmd)
+	analyzeAllCmd.Flags().Bool("enable-vuln-scan", false, "Enable known vulnerability scanning using OSV.dev database")
+	analyzeAllCmd.Flags().Bool("enable-quality-check", false, "Enable SBOM quality scoring against NTIA minimum elements")
+	analyzeAllCmd.Flags().Bool("enable-crypto-check", false, "Enable cryptographic library inventory with FIPS and post-quantum concerns")
+	analyzeAllCmd.Flags().Bool("enable-export-check", false, "Enable export-control tagging of encryption and networking components")
+	analyzeAllCmd.Flags().Bool("enable-base-image-check", false, "Enable base image staleness checks for container image SBOMs")
+	analyzeAllCmd.Flags().Bool("enable-repo-activity-check", false, "Enable activity checks of component source repositories on GitHub and GitLab")
+}
+
+// runAnalyzeAll executes the analyze-all command
+func runAnalyzeAll(cmd *cobra.Command, args []string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	dbPath, _ := cmd.Flags().GetString("db")
+
+	selection, err := agentSelection(cmd)
+	if err != nil {
+		return err
+	}
+	dbPath = wiring.DatabasePath(dbPath)
+
+	repo, err := wiring.OpenRepository(dbPath)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	// Stop on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	sboms, err := repo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load SBOMs: %w", err)
+	}
+
+	fmt.Printf("📚 Analyzing %d stored SBOMs from %s\n", len(sboms), dbPath)
+
+	timeouts, err := analysis.AgentTimeoutsFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid agent timeout configuration: %v\n", err)
+	}
+
+	// The license agent always runs; the rest are opt-in. A failing agent is
+	// counted against its SBOM rather than stopping the rollup.
+	selection.ConfigureProactive = func(agent *analysis.ProactiveVulnerabilityAgent) {
+		configureRAG(cmd, agent)
+		if verbose {
+			printParameters(agent)
+		}
+	}
+	orchestrator, dedup := wiring.NewOrchestrator(timeouts, selection).Deduplicate(sboms)
+	fmt.Printf("🧩 %d unique components across %d components\n", dedup.Unique, dedup.Components)
+	if err := prepareModels(ctx, cmd, orchestrator); err != nil {
+		return err
+	}
+
+	rollup := analysis.NewRollup()
+	for i, sbom := range sboms {
+		if ctx.Err() != nil {
+			fmt.Printf("⏹️  Interrupted after %d of %d SBOMs\n", i, len(sboms))
+			break
+		}
+
+		report, err := orchestrator.Run(ctx, sbom)
+		if err != nil {
+			fmt.Printf("⏹️  Interrupted after %d of %d SBOMs\n", i, len(sboms))
+			break
+		}
+
+		sbomResults := report.Results
+		rollup.Add(sbom.ID, sbomResults)
+		rollup.AddProject(sbom.Name, sbom.ID, sbomResults)
+		for _, run := range report.Runs {
+			if run.Status != analysis.AgentStatusOK {
+				rollup.AddFailure()
+				break
+			}
+		}
+
+		fmt.Printf("   [%d/%d] %s (%s): %d findings, risk score %d\n", i+1, len(sboms), sbom.Name, sbom.ID, len(sbomResults), analysis.ScoreRisk(sbomResults).Score)
+	}
+
+	rollup.SortFindings()
+
+	fmt.Printf("\n🏢 Organization-wide Rollup:\n")
+	fmt.Printf("   SBOMs analyzed: %d\n", len(sboms))
+	if rollup.SBOMsFailed > 0 {
+		fmt.Printf("   SBOMs with agent failures: %d\n", rollup.SBOMsFailed)
+	}
+	fmt.Printf("   Total findings: %d\n", rollup.TotalFindings)
+	for _, severity := range []string{"Critical", "High", "Medium", "Low"} {
+		if count := rollup.FindingsBySeverity[severity]; count > 0 {
+			fmt.Printf("   %s %s: %d\n", getSeverityIcon(severity), severity, count)
+		}
+	}
+
+	if len(rollup.Projects) > 0 {
+		fmt.Printf("\n📈 Projects by Risk:\n")
+		for i, project := range rollup.Projects {
+			if i >= 20 && !verbose {
+				fmt.Printf("   ... and %d more projects (use --verbose to see all)\n", len(rollup.Projects)-20)
+				break
+			}
+			fmt.Printf("   %d. %s — %d/100 (%s)\n", i+1, project.Project, project.Score, project.Level)
+		}
+	}
+
+	if len(rollup.Findings) > 0 {
+		fmt.Printf("\n🔬 Distinct Findings:\n")
+		for i, finding := range rollup.Findings {
+			if i >= 20 && !verbose {
+				fmt.Printf("   ... and %d more findings (use --verbose to see all)\n", len(rollup.Findings)-20)
+				break
+			}
+			fmt.Printf("   %d. %s [%s] %s — %d SBOMs\n", i+1, getSeverityIcon(finding.Severity), finding.Severity, finding.AgentName, len(finding.SBOMIDs))
+			fmt.Printf("      %s\n", finding.Finding)
+			if verbose {
+				for _, id := range finding.SBOMIDs {
+					fmt.Printf("      • %s\n", id)
+				}
+			}
+		}
+	}
+
+	return nil
+}
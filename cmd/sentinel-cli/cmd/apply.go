@@ -0,0 +1,120 @@
+// Package cmd provides the apply command reconciling a server with a declarative file.
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/apply"
+	"github.com/hueyexe/SBOM-Sentinel/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// applyCmd reconciles a running server with a declarative file
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile an SBOM Sentinel server with a declarative file",
+	Long: `Make a running SBOM Sentinel server match the file given with -f, so that
+projects, gate policies, waivers, notification webhooks, API keys and the
+watchlist are managed as code, reviewed and versioned like any other file.
+
+The file is the server's configuration file with an optional watchlist
+section. The configuration file is replaced as a whole and reloaded, and the
+watchlist is changed to list exactly the packages of the section. Before
+anything changes, the plan lists every entry added (+), changed (~) or
+removed (-); --plan only prints it.
+
+The server validates the configuration before replacing its own, and keeps
+the current file if it is invalid. Environment variables such as
+${SLACK_WEBHOOK_URL} are expanded on the server, so secrets stay out of the
+file, and policy paths are resolved on the server; give policies inline with
+'rego:' to apply them from a repository. Applying needs an admin API key.`,
+	Example: `  sentinel-cli apply -f sentinel.yaml --plan
+  sentinel-cli apply -f sentinel.yaml --server https://sentinel.example.com
+  SENTINEL_URL=https://sentinel.example.com SENTINEL_API_KEY=$ADMIN_KEY sentinel-cli apply -f sentinel.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runApply,
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringP("file", "f", "", "Declarative file to apply (required)")
+	applyCmd.Flags().String("server", "", "Server URL (defaults to $SENTINEL_URL or http://localhost:8080)")
+	applyCmd.Flags().String("api-key", "", "Admin API key (defaults to $SENTINEL_API_KEY)")
+	applyCmd.Flags().Bool("plan", false, "Only print what applying the file would change")
+
+	applyCmd.MarkFlagRequired("file")
+}
+
+// serverClient returns a client of the server given with --server and
+// --api-key, or in $SENTINEL_URL and $SENTINEL_API_KEY.
+func serverClient(cmd *cobra.Command) *client.Client {
+	server, _ := cmd.Flags().GetString("server")
+	if server == "" {
+		server = os.Getenv("SENTINEL_URL")
+	}
+	if server == "" {
+		server = "http://localhost:8080"
+	}
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	if apiKey == "" {
+		apiKey = os.Getenv("SENTINEL_API_KEY")
+	}
+	return client.New(server, client.WithAPIKey(apiKey))
+}
+
+// runApply executes the apply command
+func runApply(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("file")
+	planOnly, _ := cmd.Flags().GetBool("plan")
+
+	document, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := serverClient(cmd)
+	plan, err := apply.NewPlan(ctx, server, document)
+	if err != nil {
+		return fmt.Errorf("failed to plan %s: %w", path, err)
+	}
+	if plan.Empty() {
+		fmt.Println("✅ No changes: the server matches " + path)
+		return nil
+	}
+
+	fmt.Printf("📋 Plan for %s:\n", path)
+	for _, change := range plan.Changes {
+		fmt.Printf("   %s\n", change)
+	}
+	add, change, remove := plan.Counts()
+	fmt.Printf("   %d to add, %d to change, %d to remove\n", add, change, remove)
+	if planOnly {
+		return nil
+	}
+
+	response, err := plan.Apply(ctx, server)
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == "invalid_config" {
+		return fmt.Errorf("the server rejected %s: %s", path, apiErr.Message)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply %s: %w", path, err)
+	}
+
+	fmt.Printf("✅ Applied %s\n", path)
+	if response != nil {
+		fmt.Printf("   Profiles: %s\n", strings.Join(response.Profiles, ", "))
+		fmt.Printf("   Projects: %d, notification channels: %d, rules: %d, API keys: %d\n", response.Projects, response.NotificationChannels, response.Rules, response.APIKeys)
+		fmt.Printf("   Policy gate enabled: %t\n", response.PolicyGate)
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+// Package cmd provides the ci command for gating pipelines on SBOM analysis.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/scm"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/hueyexe/SBOM-Sentinel/internal/report"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// Report formats of the ci command.
+const (
+	ciOutputMarkdown = "markdown"
+	ciOutputJUnit    = "junit"
+)
+
+// Exit codes of the ci command.
+const (
+	ciExitFailed = 1
+	ciExitError  = 2
+)
+
+// ciCmd represents the ci command
+var ciCmd = &cobra.Command{
+	Use:   "ci [SBOM_FILE]",
+	Short: "Analyze an SBOM in a CI pipeline and fail on findings",
+	Long: `Analyze an SBOM file in a CI pipeline. The selected agents run as with
+'analyze', and the command exits with:
+
+  0  no finding reaches --fail-on and the policy gate (if any) passed
+  1  findings at or above --fail-on, or policy violations
+  2  the SBOM could not be analyzed
+
+A Markdown summary is appended to --summary-file, which defaults to
+$GITHUB_STEP_SUMMARY so that it appears on the GitHub Actions job page, or
+written to standard output. On GitHub Actions every finding is also reported
+as an annotation on the SBOM file.
+
+With --output junit the report is JUnit XML instead, written to
+--summary-file or standard output, for the test report views of GitLab,
+Jenkins and Azure DevOps. Each agent is a test suite and each finding a
+failed test case. Progress messages go to standard error.
+
+With --pr-comment the findings are also posted as a comment on the pull
+request (GitHub) or merge request (GitLab) that triggered the pipeline.
+Given --baseline, the SBOM of the target branch, the comment leads with the
+findings and dependency changes the pull request introduces. Later runs edit
+the same comment rather than adding new ones.
+
+To adopt the gate on a legacy project, record its current findings with
+--write-baseline baseline.json and commit the file; runs given
+--baseline baseline.json then only fail on findings introduced since. A
+finding stays accepted when its component is upgraded, but not when it
+moves to another component.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCI,
+	// A failed gate is not a usage error, and main reports the error
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(ciCmd)
+
+	ciCmd.Flags().StringP("format", "f", "auto", "SBOM format (auto, cyclonedx, gobinary)")
+	addProfileFlag(ciCmd)
+	addPluginFlag(ciCmd)
+	addRulesFlag(ciCmd)
+	ciCmd.Flags().Bool("enable-ai-health-check", false, "Enable AI-powered dependency health analysis (requires Ollama)")
+	ciCmd.Flags().Bool("enable-proactive-scan", false, "Enable proactive vulnerability discovery using RAG (requires Ollama)")
+	addPullModelsFlag(ciCmd)
+	addRAGFlags(ciCmd)
+	ciCmd.Flags().Bool("enable-vuln-scan", false, "Enable known vulnerability scanning using OSV.dev database")
+	ciCmd.Flags().Bool("enable-quality-check", false, "Enable SBOM quality scoring against NTIA minimum elements")
+	ciCmd.Flags().Bool("enable-crypto-check", false, "Enable cryptographic library inventory with FIPS and post-quantum concerns")
+	ciCmd.Flags().Bool("enable-export-check", false, "Enable export-control tagging of encryption and networking components")
+	ciCmd.Flags().Bool("enable-base-image-check", false, "Enable base image staleness checks for container image SBOMs")
+	ciCmd.Flags().Bool("enable-repo-activity-check", false, "Enable activity checks of component source repositories on GitHub and GitLab")
+	ciCmd.Flags().StringSlice("policy", nil, "Rego policy files or directories (and data files such as waivers) gating the results (requires opa)")
+	ciCmd.Flags().StringSlice("license-ignore-scopes", []string{"excluded"}, "Component scopes skipped by license analysis (required, optional, excluded)")
+	addProjectFlags(ciCmd)
+	addTagFlag(ciCmd)
+	addIgnoreFlag(ciCmd)
+	ciCmd.Flags().String("fail-on", "high", "Lowest finding severity that fails the run (critical, high, medium, low or none)")
+	ciCmd.Flags().StringP("output", "o", ciOutputMarkdown, "Report format: markdown or junit")
+	ciCmd.Flags().String("summary-file", "", "File the report is written to (Markdown defaults to $GITHUB_STEP_SUMMARY; otherwise standard output)")
+	ciCmd.Flags().Bool("no-group", false, "List every finding on its own row instead of grouping findings shared by several components")
+	ciCmd.Flags().Bool("annotations", os.Getenv("GITHUB_ACTIONS") == "true", "Print GitHub Actions annotations for findings (default on GitHub Actions)")
+	ciCmd.Flags().String("baseline", "", "Findings baseline whose findings do not fail the run, or SBOM of the target branch to diff findings and components against")
+	ciCmd.Flags().String("write-baseline", "", "Write the current findings to this file as a baseline for later runs' --baseline")
+	ciCmd.Flags().Bool("pr-comment", false, "Post the findings as a pull request or merge request comment")
+	ciCmd.Flags().String("pr-provider", scm.ProviderAuto, "Code host for --pr-comment: auto, github or gitlab")
+	ciCmd.Flags().String("pr-token", "", "Token for --pr-comment (default $SENTINEL_PR_TOKEN, then $GITHUB_TOKEN or $GITLAB_TOKEN)")
+	ciCmd.Flags().Int("pr", 0, "Pull or merge request number for --pr-comment (default from the CI environment)")
+	ciCmd.Flags().String("comment-key", "", "Identifies the comment updated by later runs (default the SBOM file path)")
+	addNotifyFlag(ciCmd)
+}
+
+// runCI executes the ci command
+func runCI(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	format, _ := cmd.Flags().GetString("format")
+	licenseIgnoreScopes, _ := cmd.Flags().GetStringSlice("license-ignore-scopes")
+	annotations, _ := cmd.Flags().GetBool("annotations")
+	noGroup, _ := cmd.Flags().GetBool("no-group")
+
+	// Configuration and analysis problems exit with 2 so that pipelines can
+	// tell a broken setup from a failed gate
+	fail := func(err error) error {
+		return &exitError{code: ciExitError, err: err}
+	}
+
+	failOn, _ := cmd.Flags().GetString("fail-on")
+	failOn, err := report.ParseFailOn(failOn)
+	if err != nil {
+		return fail(err)
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output != ciOutputMarkdown && output != ciOutputJUnit {
+		return fail(fmt.Errorf("invalid output format %q (expected markdown or junit)", output))
+	}
+
+	summaryFile, _ := cmd.Flags().GetString("summary-file")
+	if summaryFile == "" && output == ciOutputMarkdown {
+		summaryFile = os.Getenv("GITHUB_STEP_SUMMARY")
+	}
+
+	selection, err := agentSelection(cmd)
+	if err != nil {
+		return fail(err)
+	}
+
+	tags, err := tagFlag(cmd)
+	if err != nil {
+		return fail(err)
+	}
+
+	var commenter scm.Commenter
+	if prComment, _ := cmd.Flags().GetBool("pr-comment"); prComment {
+		provider, _ := cmd.Flags().GetString("pr-provider")
+		token, _ := cmd.Flags().GetString("pr-token")
+		number, _ := cmd.Flags().GetInt("pr")
+		commenter, err = scm.FromEnv(provider, number, token)
+		if err != nil {
+			return fail(fmt.Errorf("cannot post a pull request comment: %w", err))
+		}
+	}
+
+	var gate policy.Evaluator
+	if policies, _ := cmd.Flags().GetStringSlice("policy"); len(policies) > 0 {
+		gate, err = policy.NewRegoEvaluator(policies...)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	ignores, err := ignoreList(cmd)
+	if err != nil {
+		return fail(err)
+	}
+
+	sbom, _, err := loadSBOMFile(filePath, format, verbose)
+	if err != nil {
+		return fail(err)
+	}
+	// Policies see the tags of --tag and of the SBOM's project
+	sbom.Tags = tags
+	sbom.Tags = sbom.EffectiveTags(selection.Projects)
+
+	// The baseline is either a findings baseline or the SBOM of the target branch
+	var baseline *core.SBOM
+	var findingsBaseline *report.Baseline
+	baselineFile, _ := cmd.Flags().GetString("baseline")
+	if baselineFile != "" {
+		data, err := os.ReadFile(baselineFile)
+		if err != nil {
+			return fail(fmt.Errorf("failed to load baseline: %w", err))
+		}
+		if report.IsBaseline(data) {
+			findingsBaseline, err = report.ParseBaseline(data)
+		} else {
+			baseline, _, err = loadSBOMFile(baselineFile, format, verbose)
+		}
+		if err != nil {
+			return fail(fmt.Errorf("failed to load baseline: %w", err))
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	timeouts, err := analysis.AgentTimeoutsFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Invalid agent timeout configuration: %v\n", err)
+	}
+
+	selection.RequireLicense = true
+	selection.LicenseIgnoreScopes = licenseIgnoreScopes
+	selection.ConfigureProactive = func(agent *analysis.ProactiveVulnerabilityAgent) {
+		configureRAG(cmd, agent)
+	}
+	orchestrator := wiring.NewOrchestrator(timeouts, selection)
+
+	fmt.Fprintf(os.Stderr, "🔍 Analyzing %s (%d components) with %d agents\n", filePath, len(sbom.Components), len(orchestrator.Agents()))
+
+	if err := prepareModels(ctx, cmd, orchestrator); err != nil {
+		return fail(err)
+	}
+
+	analysisReport, err := orchestrator.Run(ctx, *sbom)
+	if err != nil {
+		return fail(fmt.Errorf("analysis failed: %w", err))
+	}
+
+	// Accepted findings of the ignore file neither fail the run nor reach
+	// policies, notifications or the baseline diff
+	results, ignored := ignores.Apply(analysisReport.Results)
+	results, err = assignOwners(cmd, results)
+	if err != nil {
+		return fail(err)
+	}
+	result := &report.Report{
+		Source:     filePath,
+		SBOM:       *sbom,
+		Agents:     analysisReport.AgentsRun(),
+		Results:    results,
+		Ignored:    ignored,
+		Incomplete: analysisReport.Failures(),
+		FailOn:     failOn,
+		NoGroup:    noGroup,
+		Baseline:   findingsBaseline,
+	}
+
+	// A new baseline accepts every current finding, including for this run
+	if writeBaseline, _ := cmd.Flags().GetString("write-baseline"); writeBaseline != "" {
+		result.Baseline = report.NewBaseline(filePath, results, core.Now())
+		if err := saveBaseline(writeBaseline, result.Baseline); err != nil {
+			return fail(err)
+		}
+		fmt.Fprintf(os.Stderr, "📌 Wrote baseline of %d findings to %s\n", len(results), writeBaseline)
+	}
+
+	if gate != nil {
+		input := policy.NewInput(*sbom, results, analysisReport.AgentStatus())
+		result.Policy, err = gate.Evaluate(ctx, input)
+		if err != nil {
+			return fail(fmt.Errorf("policy evaluation failed: %w", err))
+		}
+	}
+
+	var diff *report.Diff
+	if baseline != nil {
+		fmt.Fprintf(os.Stderr, "🔍 Analyzing baseline %s (%d components)\n", baselineFile, len(baseline.Components))
+		baselineReport, err := orchestrator.Run(ctx, *baseline)
+		if err != nil {
+			return fail(fmt.Errorf("baseline analysis failed: %w", err))
+		}
+		baselineResults, _ := ignores.Apply(baselineReport.Results)
+		diff = report.NewDiff(*baseline, baselineResults, *sbom, results)
+	}
+
+	if annotations {
+		if err := report.WriteGitHubAnnotations(os.Stdout, result); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := writeSummary(summaryFile, output, result); err != nil {
+		return fail(err)
+	}
+
+	if commenter != nil {
+		key, _ := cmd.Flags().GetString("comment-key")
+		if key == "" {
+			key = filePath
+		}
+		// The comment is informational, so failing to post it does not fail the run
+		if err := postComment(ctx, commenter, key, result, diff); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to post pull request comment: %v\n", err)
+		}
+	}
+
+	if notifyChannels, _ := cmd.Flags().GetBool("notify"); notifyChannels {
+		sendNotifications(ctx, cmd, *sbom, result.Results)
+	}
+
+	if !result.Passed() {
+		return &exitError{code: ciExitFailed, err: ciFailure(result)}
+	}
+	baselined := len(result.Baselined())
+	switch {
+	case failOn == report.FailOnNone:
+		fmt.Fprintf(os.Stderr, "✅ Passed: %d findings\n", len(result.Results))
+	case baselined > 0:
+		fmt.Fprintf(os.Stderr, "✅ Passed: %d findings, none new at or above %s (%d accepted in the baseline)\n", len(result.Results), failOn, baselined)
+	default:
+		fmt.Fprintf(os.Stderr, "✅ Passed: %d findings, none at or above %s\n", len(result.Results), failOn)
+	}
+	return nil
+}
+
+// writeSummary writes the report in the given format to path, or prints it
+// if path is empty.
+func writeSummary(path, output string, result *report.Report) error {
+	write := report.WriteMarkdown
+	if output == ciOutputJUnit {
+		write = report.WriteJUnit
+	}
+	if path == "" {
+		return write(os.Stdout, result)
+	}
+
+	// GitHub expects each step to append to the summary file, while a JUnit
+	// report must be a single document
+	flags := os.O_APPEND | os.O_CREATE | os.O_WRONLY
+	if output == ciOutputJUnit {
+		flags = os.O_TRUNC | os.O_CREATE | os.O_WRONLY
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open summary file: %w", err)
+	}
+	if err := write(file, result); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return file.Close()
+}
+
+// postComment posts the report as the pull request comment identified by key.
+func postComment(ctx context.Context, commenter scm.Commenter, key string, result *report.Report, diff *report.Diff) error {
+	var body bytes.Buffer
+	if err := report.WriteComment(&body, result, diff); err != nil {
+		return err
+	}
+
+	comment, err := commenter.Upsert(ctx, key, body.String())
+	if err != nil {
+		return err
+	}
+	if comment.URL != "" {
+		fmt.Fprintf(os.Stderr, "💬 Pull request comment %s: %s\n", comment.Action, comment.URL)
+	} else {
+		fmt.Fprintf(os.Stderr, "💬 Pull request comment %s\n", comment.Action)
+	}
+	return nil
+}
+
+// saveBaseline writes a findings baseline to path.
+func saveBaseline(path string, baseline *report.Baseline) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := report.WriteBaseline(file, baseline); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return file.Close()
+}
+
+// ciFailure describes why a ci run failed.
+func ciFailure(result *report.Report) error {
+	failing := len(result.Failing())
+	switch {
+	case result.Policy != nil && !result.Policy.Passed() && failing > 0:
+		return fmt.Errorf("%d findings at or above %s and %d policy violations", failing, result.FailOn, len(result.Policy.Violations))
+	case result.Policy != nil && !result.Policy.Passed():
+		return fmt.Errorf("policy gate failed with %d violations", len(result.Policy.Violations))
+	case result.Baseline != nil:
+		return fmt.Errorf("%d new findings at or above %s", failing, result.FailOn)
+	default:
+		return fmt.Errorf("%d findings at or above %s", failing, result.FailOn)
+	}
+}
@@ -0,0 +1,93 @@
+// Package cmd provides the crypto command for inventorying cryptographic libraries.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the crypto command.
+const (
+	cryptoOutputText = "text"
+	cryptoOutputCBOM = "cbom"
+)
+
+// cryptoCmd represents the crypto command
+var cryptoCmd = &cobra.Command{
+	Use:   "crypto [SBOM_FILE]",
+	Short: "Inventory the cryptographic libraries in an SBOM",
+	Long: `Identify the cryptographic libraries in an SBOM file, such as OpenSSL,
+Bouncy Castle and libsodium, together with the algorithms they provide,
+their FIPS 140 status and concerns such as end-of-life versions, legacy
+algorithms and quantum-vulnerable public-key algorithms.
+
+With --output cbom the inventory is written as a CycloneDX 1.6 Cryptography
+Bill of Materials (CBOM) for export-control and post-quantum readiness reviews.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCrypto,
+}
+
+func init() {
+	rootCmd.AddCommand(cryptoCmd)
+
+	cryptoCmd.Flags().StringP("format", "f", "auto", "SBOM format (auto, cyclonedx, gobinary)")
+	cryptoCmd.Flags().StringP("output", "o", cryptoOutputText, "Inventory format: text or cbom")
+}
+
+// runCrypto executes the crypto command
+func runCrypto(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	format, _ := cmd.Flags().GetString("format")
+
+	output, _ := cmd.Flags().GetString("output")
+	if output != cryptoOutputText && output != cryptoOutputCBOM {
+		return fmt.Errorf("invalid output format %q (expected text or cbom)", output)
+	}
+
+	sbom, _, err := loadSBOMFile(filePath, format, verbose)
+	if err != nil {
+		return err
+	}
+
+	assets := analysis.CryptoInventory(*sbom)
+	if output == cryptoOutputCBOM {
+		return report.WriteCBOM(os.Stdout, sbom.Name, assets)
+	}
+
+	printCryptoInventory(assets)
+	return nil
+}
+
+// printCryptoInventory prints each cryptographic library with its algorithms,
+// FIPS 140 status and concerns.
+func printCryptoInventory(assets []analysis.CryptoAsset) {
+	if len(assets) == 0 {
+		fmt.Printf("\n✅ No known cryptographic libraries\n")
+		return
+	}
+
+	fmt.Printf("\n🔐 Cryptographic Libraries:\n")
+	for i, asset := range assets {
+		names := make([]string, len(asset.Algorithms))
+		for j, algorithm := range asset.Algorithms {
+			names[j] = algorithm.Name
+		}
+
+		icon := "🔑"
+		if asset.Severe {
+			icon = getSeverityIcon("High")
+		}
+		fmt.Printf("   %d. %s %s %s (%s)\n", i+1, icon, asset.Component.Name, asset.Component.Version, asset.Library)
+		fmt.Printf("      Algorithms: %s\n", strings.Join(names, ", "))
+		fmt.Printf("      FIPS 140: %s\n", asset.FIPS)
+		for _, concern := range asset.Concerns {
+			fmt.Printf("      ⚠️  %s\n", concern)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package cmd provides the db commands for maintaining the local OSV mirror.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/osvdb"
+	"github.com/spf13/cobra"
+)
+
+// defaultMirrorEcosystems are the OSV ecosystems the vulnerability scanner recognizes.
+var defaultMirrorEcosystems = []string{"npm", "PyPI", "Maven", "Go", "crates.io", "NuGet", "Packagist", "RubyGems"}
+
+// dbCmd groups the commands operating on the local OSV mirror
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the local OSV vulnerability database",
+	Long: `Manage a local mirror of the OSV vulnerability database.
+
+When OSV_MIRROR_PATH points at the mirror, the vulnerability scanner answers
+queries for mirrored ecosystems locally instead of calling the OSV.dev API,
+avoiding its rate limits. Set OSV_OFFLINE=true to never call the API.`,
+}
+
+// dbSyncCmd downloads OSV ecosystem exports into the mirror
+var dbSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Download OSV ecosystem exports into the local database",
+	Long: `Download the OSV.dev bulk export (all.zip) of each ecosystem and import
+it into the local SQLite vulnerability database. Re-running sync updates
+changed records and removes withdrawn ones.`,
+	Args: cobra.NoArgs,
+	RunE: runDBSync,
+}
+
+// dbStatusCmd lists the ecosystems held by the mirror
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the ecosystems in the local database",
+	Args:  cobra.NoArgs,
+	RunE:  runDBStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbSyncCmd)
+	dbCmd.AddCommand(dbStatusCmd)
+
+	dbCmd.PersistentFlags().String("path", "", "OSV database path (defaults to $OSV_MIRROR_PATH or ./osv.db)")
+	dbSyncCmd.Flags().StringSlice("ecosystem", defaultMirrorEcosystems, "OSV ecosystems to download")
+}
+
+// openMirror opens the mirror named by --path, $OSV_MIRROR_PATH or the default.
+func openMirror(cmd *cobra.Command) (*osvdb.Mirror, string, error) {
+	path, _ := cmd.Flags().GetString("path")
+	if path == "" {
+		path = os.Getenv("OSV_MIRROR_PATH")
+	}
+	if path == "" {
+		path = "./osv.db"
+	}
+
+	mirror, err := osvdb.Open(path)
+	if err != nil {
+		return nil, path, fmt.Errorf("failed to open OSV database '%s': %w", path, err)
+	}
+	return mirror, path, nil
+}
+
+// runDBSync executes the db sync command
+func runDBSync(cmd *cobra.Command, args []string) error {
+	ecosystems, _ := cmd.Flags().GetStringSlice("ecosystem")
+
+	mirror, path, err := openMirror(cmd)
+	if err != nil {
+		return err
+	}
+	defer mirror.Close()
+
+	// Stop on Ctrl+C; the ecosystem being imported is rolled back
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("📥 Syncing %d OSV ecosystems into %s\n", len(ecosystems), path)
+
+	failed := 0
+	for _, ecosystem := range ecosystems {
+		if ctx.Err() != nil {
+			return fmt.Errorf("sync interrupted")
+		}
+
+		report, err := mirror.Sync(ctx, ecosystem)
+		if err != nil {
+			fmt.Printf("   ❌ %s: %v\n", ecosystem, err)
+			failed++
+			continue
+		}
+		fmt.Printf("   ✅ %s: %d vulnerabilities imported, %d withdrawn removed\n", ecosystem, report.Imported, report.Withdrawn)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d ecosystems failed to sync", failed, len(ecosystems))
+	}
+	return nil
+}
+
+// runDBStatus executes the db status command
+func runDBStatus(cmd *cobra.Command, args []string) error {
+	mirror, path, err := openMirror(cmd)
+	if err != nil {
+		return err
+	}
+	defer mirror.Close()
+
+	statuses, err := mirror.Ecosystems(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(statuses) == 0 {
+		fmt.Printf("📭 %s holds no ecosystems; run 'sentinel-cli db sync' to download them\n", path)
+		return nil
+	}
+
+	fmt.Printf("📚 OSV database %s:\n", path)
+	for _, status := range statuses {
+		fmt.Printf("   • %s: %d vulnerabilities (synced %s)\n", status.Ecosystem, status.Vulnerabilities, status.SyncedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package cmd provides the dtrack commands for exporting SBOMs to Dependency-Track.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/dtrack"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// dtrackCmd groups the commands integrating with Dependency-Track
+var dtrackCmd = &cobra.Command{
+	Use:   "dtrack",
+	Short: "Export SBOMs to Dependency-Track",
+	Long: `Keep a Dependency-Track instance in sync with the SBOMs stored by SBOM Sentinel.
+
+The instance is configured by $DTRACK_URL and $DTRACK_API_KEY; the key needs the
+BOM_UPLOAD and PROJECT_CREATION_UPLOAD permissions. When they are set, the
+server also pushes every submitted SBOM.`,
+}
+
+// dtrackPushCmd uploads stored SBOMs
+var dtrackPushCmd = &cobra.Command{
+	Use:   "push [sbom-id...]",
+	Short: "Upload stored SBOMs to Dependency-Track",
+	Long: `Upload the given stored SBOMs, or every stored SBOM, to Dependency-Track as
+CycloneDX. Each SBOM goes to the project named by --project-name and
+--project-version, templates in which {name} is the SBOM name, {id} its ID and
+any other {key} an SBOM metadata entry. Missing projects are created.`,
+	RunE: runDTrackPush,
+}
+
+func init() {
+	rootCmd.AddCommand(dtrackCmd)
+	dtrackCmd.AddCommand(dtrackPushCmd)
+
+	dtrackPushCmd.Flags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+	dtrackPushCmd.Flags().String("project-name", "", "Project name template (defaults to $DTRACK_PROJECT_NAME or {name})")
+	dtrackPushCmd.Flags().String("project-version", "", "Project version template (defaults to $DTRACK_PROJECT_VERSION)")
+}
+
+// runDTrackPush executes the dtrack push command
+func runDTrackPush(cmd *cobra.Command, args []string) error {
+	exporter, err := dtrack.ExporterFromEnv()
+	if err != nil {
+		return err
+	}
+	if exporter == nil {
+		return fmt.Errorf("DTRACK_URL and DTRACK_API_KEY must be set")
+	}
+
+	mapping := exporter.Mapping()
+	if cmd.Flags().Changed("project-name") {
+		mapping.Name, _ = cmd.Flags().GetString("project-name")
+	}
+	if cmd.Flags().Changed("project-version") {
+		mapping.Version, _ = cmd.Flags().GetString("project-version")
+	}
+	exporter = exporter.WithMapping(mapping)
+
+	dbPath, _ := cmd.Flags().GetString("db")
+	repo, err := wiring.OpenRepository(wiring.DatabasePath(dbPath))
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var sboms []core.SBOM
+	if len(args) == 0 {
+		sboms, err = repo.FindAll(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load SBOMs: %w", err)
+		}
+	}
+	for _, id := range args {
+		sbom, err := repo.FindByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to load SBOM %s: %w", id, err)
+		}
+		if sbom == nil {
+			return fmt.Errorf("SBOM %s not found", id)
+		}
+		sboms = append(sboms, *sbom)
+	}
+
+	// Keep going after a failed upload so that one bad SBOM does not block the rest
+	failed := 0
+	for _, sbom := range sboms {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		upload, err := exporter.Push(ctx, sbom)
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %s (%s): %v\n", sbom.Name, sbom.ID, err)
+			continue
+		}
+		project := upload.Project.Name
+		if upload.Project.Version != "" {
+			project += " " + upload.Project.Version
+		}
+		fmt.Printf("📤 %s (%s) → %s\n", sbom.Name, sbom.ID, project)
+	}
+
+	fmt.Printf("Pushed %d of %d SBOMs to Dependency-Track\n", len(sboms)-failed, len(sboms))
+	if failed > 0 {
+		return fmt.Errorf("failed to push %d SBOMs", failed)
+	}
+	return nil
+}
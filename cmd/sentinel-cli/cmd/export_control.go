@@ -0,0 +1,91 @@
+// Package cmd provides the export-control command for tagging components
+// with potential export-control relevance.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the export-control command.
+const (
+	exportOutputText = "text"
+	exportOutputCSV  = "csv"
+)
+
+// exportControlCmd represents the export-control command
+var exportControlCmd = &cobra.Command{
+	Use:   "export-control [SBOM_FILE]",
+	Short: "Inventory components with potential export-control relevance",
+	Long: `Tag the components of an SBOM file that may be relevant to export
+controls, such as cryptographic libraries, VPN and SSH implementations and
+networking stacks, with a category and a candidate ECCN (Export Control
+Classification Number) for review by trade compliance.
+
+Built-in rules can be extended with export_control_rules in the configuration
+file. With --output csv the inventory is written as CSV, one row per component
+and category.
+
+The tags are candidates for review, not a classification.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportControl,
+}
+
+func init() {
+	rootCmd.AddCommand(exportControlCmd)
+
+	exportControlCmd.Flags().StringP("format", "f", "auto", "SBOM format (auto, cyclonedx, gobinary)")
+	exportControlCmd.Flags().StringP("output", "o", exportOutputText, "Inventory format: text or csv")
+}
+
+// runExportControl executes the export-control command
+func runExportControl(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	format, _ := cmd.Flags().GetString("format")
+
+	output, _ := cmd.Flags().GetString("output")
+	if output != exportOutputText && output != exportOutputCSV {
+		return fmt.Errorf("invalid output format %q (expected text or csv)", output)
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	sbom, _, err := loadSBOMFile(filePath, format, verbose)
+	if err != nil {
+		return err
+	}
+
+	entries := analysis.NewExportControlAgent(cfg.ExportControlRules...).Inventory(*sbom)
+	if output == exportOutputCSV {
+		return report.WriteExportControlCSV(os.Stdout, sbom.Name, entries)
+	}
+
+	printExportControlInventory(entries)
+	return nil
+}
+
+// printExportControlInventory prints each tagged component with its
+// categories and candidate ECCNs.
+func printExportControlInventory(entries []analysis.ExportEntry) {
+	if len(entries) == 0 {
+		fmt.Printf("\n✅ No components with potential export-control relevance\n")
+		return
+	}
+
+	fmt.Printf("\n🌍 Export-Control Inventory:\n")
+	for i, entry := range entries {
+		fmt.Printf("   %d. %s %s\n", i+1, entry.Component.Name, entry.Component.Version)
+		for _, tag := range entry.Tags {
+			fmt.Printf("      %s — candidate ECCN %s: %s\n", tag.Category, tag.ECCN, tag.Reason)
+		}
+	}
+	fmt.Printf("\n   Candidate ECCNs are for trade-compliance review, not a classification.\n")
+}
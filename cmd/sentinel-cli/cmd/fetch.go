@@ -0,0 +1,160 @@
+// Package cmd provides the fetch command, which downloads the SBOM attached
+// to a container image in its registry.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/registry"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// fetchCmd represents the fetch command
+var fetchCmd = &cobra.Command{
+	Use:   "fetch <oci://IMAGE>",
+	Short: "Fetch the SBOM attached to a container image",
+	Long: `Download the CycloneDX SBOM attached to a container image in its registry,
+as an OCI referrer (oras attach, cosign with OCI 1.1 referrers) or with
+"cosign attach sbom". Images referenced by tag are resolved to their current
+digest. Registries are accessed anonymously.
+
+The SBOM can be written to a file with --output and stored in the database
+with --store, where it records the image digest so that the admission
+webhook finds it. Every command reading an SBOM file also accepts an
+oci:// reference, e.g.:
+
+  sentinel-cli analyze oci://ghcr.io/acme/app@sha256:...`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFetch,
+}
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+
+	fetchCmd.Flags().StringP("output", "o", "", "Write the SBOM to this file (- for stdout)")
+	fetchCmd.Flags().Bool("store", false, "Store the SBOM in the database")
+	fetchCmd.Flags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+}
+
+// runFetch executes the fetch command
+func runFetch(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	store, _ := cmd.Flags().GetBool("store")
+
+	location := args[0]
+	if !strings.HasPrefix(location, registry.OCIScheme) {
+		location = registry.OCIScheme + location
+	}
+	attached, err := fetchAttachedSBOM(context.Background(), location)
+	if err != nil {
+		return err
+	}
+
+	sbom, err := ingestion.NewCycloneDXParser().Parse(bytes.NewReader(attached.Data))
+	if err != nil {
+		return fmt.Errorf("failed to parse the SBOM attached to %s: %w", attached.image, err)
+	}
+	ingestion.NewNormalizer().Normalize(sbom)
+	recordImage(sbom, attached.image)
+
+	// With the SBOM on stdout, report on stderr
+	status := os.Stdout
+	if output == "-" {
+		status = os.Stderr
+		if _, err := os.Stdout.Write(attached.Data); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(status, "📦 Fetched SBOM %q of %s (%s): %d components\n", sbom.Name, attached.image, attached.Source, len(sbom.Components))
+
+	if output != "" && output != "-" {
+		if err := os.WriteFile(output, attached.Data, 0o644); err != nil {
+			return fmt.Errorf("failed to write SBOM: %w", err)
+		}
+		fmt.Fprintf(status, "💾 Written to %s\n", output)
+	}
+
+	if store {
+		dbPath, _ := cmd.Flags().GetString("db")
+		dbPath = wiring.DatabasePath(dbPath)
+		repo, err := wiring.OpenRepository(dbPath)
+		if err != nil {
+			return err
+		}
+		defer repo.Close()
+
+		id, stored, err := storeFetchedSBOM(context.Background(), repo, sbom)
+		if err != nil {
+			return err
+		}
+		if stored {
+			fmt.Fprintf(status, "🗄️  Stored in %s as %s\n", dbPath, id)
+		} else {
+			fmt.Fprintf(status, "🗄️  Already stored in %s as %s\n", dbPath, id)
+		}
+	}
+	return nil
+}
+
+// attachedSBOM is an SBOM fetched from a registry with the image it is
+// attached to.
+type attachedSBOM struct {
+	*registry.AttachedSBOM
+	// image is the image reference by digest, e.g. "ghcr.io/acme/app@sha256:..."
+	image string
+}
+
+// fetchAttachedSBOM downloads the SBOM attached to the image of an oci://
+// location, failing if none is attached.
+func fetchAttachedSBOM(ctx context.Context, location string) (*attachedSBOM, error) {
+	ref, err := registry.ParseReference(strings.TrimPrefix(location, registry.OCIScheme))
+	if err != nil {
+		return nil, err
+	}
+	attached, err := registry.NewClient().AttachedSBOM(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the SBOM attached to %s: %w", ref, err)
+	}
+	if attached == nil {
+		return nil, fmt.Errorf("no CycloneDX SBOM is attached to %s", ref)
+	}
+	return &attachedSBOM{AttachedSBOM: attached, image: ref.Name() + "@" + attached.Digest}, nil
+}
+
+// recordImage records in an SBOM's metadata the image, by digest, it was
+// attached to.
+func recordImage(sbom *core.SBOM, image string) {
+	if sbom.Metadata == nil {
+		sbom.Metadata = make(map[string]string)
+	}
+	sbom.Metadata["image"] = image
+}
+
+// storeFetchedSBOM stores an SBOM under a new ID, unless an SBOM with the
+// same serial number is already stored. It returns the SBOM's ID and
+// whether it was stored.
+func storeFetchedSBOM(ctx context.Context, repo storage.Repository, sbom *core.SBOM) (string, bool, error) {
+	if index, ok := repo.(storage.SerialIndex); ok && sbom.Metadata["serialNumber"] != "" {
+		existingID, err := index.FindBySerialNumber(ctx, sbom.Metadata["serialNumber"])
+		if err != nil {
+			return "", false, fmt.Errorf("failed to check for a stored copy: %w", err)
+		}
+		if existingID != "" {
+			return existingID, false, nil
+		}
+	}
+
+	sbom.ID = core.NewSBOMID()
+	if err := repo.Store(ctx, *sbom); err != nil {
+		return "", false, fmt.Errorf("failed to store SBOM: %w", err)
+	}
+	return sbom.ID, true, nil
+}
@@ -0,0 +1,223 @@
+// Package cmd provides the findings commands for triaging the findings
+// tracked across the analyses of a project.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// findingsCmd groups the commands triaging tracked findings
+var findingsCmd = &cobra.Command{
+	Use:   "findings",
+	Short: "List and triage the tracked findings of a project",
+	Long: `List and triage the findings tracked across the analyses of a project, the
+SBOMs sharing its name.
+
+Each finding has a triage state, set by the people remediating it:
+new, acknowledged, in-progress, resolved or false-positive, with an assignee
+and a note. Whether analyses still report the finding is tracked separately
+as its status, open or resolved. A resolved finding that is reported again
+starts over as new, unless it was triaged as a false positive.
+
+When the configuration file sets remediation SLAs by severity, findings are
+listed with their due date and open findings past it are marked overdue.`,
+}
+
+// findingsListCmd lists the tracked findings of a project
+var findingsListCmd = &cobra.Command{
+	Use:   "list <project>",
+	Short: "List the tracked findings of a project with their triage",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFindingsList,
+}
+
+// findingsTriageCmd changes the triage of findings
+var findingsTriageCmd = &cobra.Command{
+	Use:   "triage <project> <fingerprint>...",
+	Short: "Set the triage state, assignee or note of findings",
+	Long: `Set the triage state, assignee or note of findings of a project. Findings are
+named by their fingerprint, as listed by 'findings list', or by a prefix
+naming a single finding. Flags that are not given are left unchanged, and
+--assignee "" unassigns.`,
+	Example: `  sentinel-cli findings triage api 3f9a2c1e --state in-progress --assignee alice
+  sentinel-cli findings triage api 3f9a2c1e 7b01d4aa --state false-positive --note "Not reachable"`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runFindingsTriage,
+}
+
+func init() {
+	rootCmd.AddCommand(findingsCmd)
+	findingsCmd.AddCommand(findingsListCmd)
+	findingsCmd.AddCommand(findingsTriageCmd)
+
+	findingsCmd.PersistentFlags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+
+	findingsListCmd.Flags().String("status", "", "List only open or resolved findings")
+	findingsListCmd.Flags().String("triage", "", "List only findings in a triage state: "+strings.Join(storage.TriageStates, ", "))
+	findingsListCmd.Flags().String("assignee", "", "List only findings assigned to this person")
+	findingsListCmd.Flags().Bool("overdue", false, "List only open findings past the due date set by their SLA")
+
+	findingsTriageCmd.Flags().String("state", "", "Triage state: "+strings.Join(storage.TriageStates, ", "))
+	findingsTriageCmd.Flags().String("assignee", "", "Person working on the findings; empty to unassign")
+	findingsTriageCmd.Flags().String("note", "", "Note on the triage, e.g. why a finding is a false positive")
+}
+
+// runFindingsList executes the findings list command
+func runFindingsList(cmd *cobra.Command, args []string) error {
+	status, _ := cmd.Flags().GetString("status")
+	triage, _ := cmd.Flags().GetString("triage")
+	assignee, _ := cmd.Flags().GetString("assignee")
+	overdue, _ := cmd.Flags().GetBool("overdue")
+	if status != "" && status != storage.FindingOpen && status != storage.FindingResolved {
+		return fmt.Errorf("--status must be open or resolved")
+	}
+	if triage != "" && !slices.Contains(storage.TriageStates, triage) {
+		return fmt.Errorf("--triage must be one of %s", strings.Join(storage.TriageStates, ", "))
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	dbPath, _ := cmd.Flags().GetString("db")
+	repo, err := wiring.OpenRepository(wiring.DatabasePath(dbPath))
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	findings, err := repo.ProjectFindings(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	cfg.SLA.Annotate(findings, core.Now())
+
+	listed := 0
+	for _, finding := range findings {
+		if (status != "" && finding.Status != status) ||
+			(triage != "" && finding.Triage != triage) ||
+			(assignee != "" && finding.Assignee != assignee) ||
+			(overdue && !finding.Overdue) {
+			continue
+		}
+		listed++
+		printTrackedFinding(finding)
+	}
+	if listed == 0 {
+		fmt.Printf("No tracked findings of %s match\n", args[0])
+	}
+	return nil
+}
+
+// runFindingsTriage executes the findings triage command
+func runFindingsTriage(cmd *cobra.Command, args []string) error {
+	var update storage.TriageUpdate
+	if cmd.Flags().Changed("state") {
+		state, _ := cmd.Flags().GetString("state")
+		if !slices.Contains(storage.TriageStates, state) {
+			return fmt.Errorf("--state must be one of %s", strings.Join(storage.TriageStates, ", "))
+		}
+		update.State = &state
+	}
+	if cmd.Flags().Changed("assignee") {
+		assignee, _ := cmd.Flags().GetString("assignee")
+		assignee = strings.TrimSpace(assignee)
+		update.Assignee = &assignee
+	}
+	if cmd.Flags().Changed("note") {
+		note, _ := cmd.Flags().GetString("note")
+		update.Note = &note
+	}
+	if update.State == nil && update.Assignee == nil && update.Note == nil {
+		return fmt.Errorf("nothing to change: give --state, --assignee or --note")
+	}
+
+	dbPath, _ := cmd.Flags().GetString("db")
+	repo, err := wiring.OpenRepository(wiring.DatabasePath(dbPath))
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	project := args[0]
+	findings, err := repo.ProjectFindings(ctx, project)
+	if err != nil {
+		return err
+	}
+	// Every name is resolved before any finding is changed
+	fingerprints := make([]string, 0, len(args)-1)
+	for _, name := range args[1:] {
+		fingerprint, err := resolveFingerprint(findings, name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", project, err)
+		}
+		fingerprints = append(fingerprints, fingerprint)
+	}
+
+	for _, fingerprint := range fingerprints {
+		finding, err := repo.TriageFinding(ctx, project, fingerprint, update)
+		if err != nil {
+			return err
+		}
+		if finding == nil {
+			return fmt.Errorf("%s: finding %s not found", project, fingerprint)
+		}
+		printTrackedFinding(*finding)
+	}
+	return nil
+}
+
+// resolveFingerprint returns the fingerprint of the finding named by a
+// fingerprint or a prefix of one.
+func resolveFingerprint(findings []storage.FindingRecord, name string) (string, error) {
+	var matches []string
+	for _, finding := range findings {
+		if finding.Fingerprint == name {
+			return name, nil
+		}
+		if name != "" && strings.HasPrefix(finding.Fingerprint, name) {
+			matches = append(matches, finding.Fingerprint)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("finding %s not found", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%s names %d findings; give more of the fingerprint", name, len(matches))
+	}
+}
+
+// printTrackedFinding prints a tracked finding with its triage.
+func printTrackedFinding(finding storage.FindingRecord) {
+	fingerprint := finding.Fingerprint
+	if len(fingerprint) > 12 {
+		fingerprint = fingerprint[:12]
+	}
+	fmt.Printf("%s %s  [%s] %s  (%s, %s)\n", getSeverityIcon(finding.Severity), fingerprint, finding.Severity,
+		finding.Finding, finding.Status, finding.Triage)
+	if finding.Component != "" {
+		fmt.Printf("      📦 %s\n", finding.Component)
+	}
+	if finding.Assignee != "" {
+		fmt.Printf("      👤 %s\n", finding.Assignee)
+	}
+	if finding.TriageNote != "" {
+		fmt.Printf("      📝 %s\n", finding.TriageNote)
+	}
+	if finding.Overdue {
+		fmt.Printf("      ⏰ Overdue since %s\n", finding.DueAt.Format("2006-01-02"))
+	} else if finding.DueAt != nil && finding.Status == storage.FindingOpen {
+		fmt.Printf("      ⏳ Due %s\n", finding.DueAt.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,72 @@
+// Package cmd provides the prompts command for customizing the LLM prompts.
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/spf13/cobra"
+)
+
+// promptsCmd represents the prompts command
+var promptsCmd = &cobra.Command{
+	Use:   "prompts <dir>",
+	Short: "Write the built-in LLM prompt templates to a directory for editing",
+	Long: `Write the prompt templates of the AI health check and the proactive scan
+to the given directory, as a starting point for tuning their wording and
+output constraints. Point PROMPT_TEMPLATE_DIR at the directory to use the
+edited templates; a missing template falls back to the built-in one.
+
+Templates use Go text/template syntax. Both can refer to the component
+being analyzed, e.g. {{.Component.Name}}, {{.Component.Version}} and
+{{.Component.PURL}}. The dependency health template can also refer to the
+component's source repository, {{.Repository}}, empty if the SBOM gives
+none; the proactive scan template ranges over the retrieved {{.Documents}},
+each with an {{.ID}} and {{.Text}}.
+
+Existing files are left unchanged unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPrompts,
+}
+
+func init() {
+	rootCmd.AddCommand(promptsCmd)
+
+	promptsCmd.Flags().Bool("force", false, "Overwrite existing templates")
+}
+
+// runPrompts executes the prompts command
+func runPrompts(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	force, _ := cmd.Flags().GetBool("force")
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+
+	for _, name := range []string{analysis.HealthPromptTemplate, analysis.ProactivePromptTemplate} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil && !force {
+			fmt.Printf("⏭️  Keeping existing %s\n", path)
+			continue
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		source, err := analysis.BuiltinPromptTemplate(name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, source, 0o644); err != nil {
+			return fmt.Errorf("failed to write prompt template: %w", err)
+		}
+		fmt.Printf("📝 Wrote %s\n", path)
+	}
+
+	fmt.Printf("\nSet PROMPT_TEMPLATE_DIR=%s to use the templates\n", dir)
+	return nil
+}
@@ -0,0 +1,93 @@
+// Package cmd provides the prune command for enforcing SBOM retention.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/retention"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete stored SBOMs outside the retention policy",
+	Long: `Delete SBOMs from the SBOM Sentinel database according to a retention
+policy. SBOMs with the same name are versions of the same project:
+--keep-versions keeps the newest versions of each project, and --max-age
+deletes versions submitted longer ago, always keeping the newest one.
+--analysis-max-age deletes the analysis runs recorded longer ago, and the
+findings resolved longer ago.
+
+The policy defaults to $RETENTION_KEEP_VERSIONS, $RETENTION_MAX_AGE and
+$RETENTION_ANALYSIS_MAX_AGE. Use --dry-run to list the SBOMs that would be
+deleted without deleting anything.`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+	pruneCmd.Flags().Int("keep-versions", 0, "Newest SBOM versions kept per project (defaults to $RETENTION_KEEP_VERSIONS)")
+	pruneCmd.Flags().Duration("max-age", 0, "Delete SBOM versions older than this, e.g. 720h (defaults to $RETENTION_MAX_AGE)")
+	pruneCmd.Flags().Duration("analysis-max-age", 0, "Delete analysis runs and resolved findings older than this (defaults to $RETENTION_ANALYSIS_MAX_AGE)")
+	pruneCmd.Flags().Bool("dry-run", false, "List the SBOMs that would be deleted without deleting them")
+}
+
+// runPrune executes the prune command
+func runPrune(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	policy, err := retention.PolicyFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid retention configuration: %v\n", err)
+	}
+	if cmd.Flags().Changed("keep-versions") {
+		policy.KeepVersions, _ = cmd.Flags().GetInt("keep-versions")
+	}
+	if cmd.Flags().Changed("max-age") {
+		policy.MaxAge, _ = cmd.Flags().GetDuration("max-age")
+	}
+	if cmd.Flags().Changed("analysis-max-age") {
+		policy.MaxAnalysisAge, _ = cmd.Flags().GetDuration("analysis-max-age")
+	}
+	if !policy.Enabled() {
+		return fmt.Errorf("no retention policy: set --keep-versions, --max-age or --analysis-max-age")
+	}
+
+	dbPath, _ := cmd.Flags().GetString("db")
+	dbPath = wiring.DatabasePath(dbPath)
+
+	repo, err := wiring.OpenRepository(dbPath)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	// Stop on Ctrl+C; SBOMs already deleted stay deleted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := retention.NewJanitor(repo, policy).Prune(ctx, dryRun)
+	if report != nil {
+		verb := "Deleted"
+		if report.DryRun {
+			verb = "Would delete"
+		}
+		fmt.Printf("🧹 %s %d SBOMs from %s, keeping %d\n", verb, len(report.Pruned), dbPath, report.Kept)
+		for _, record := range report.Pruned {
+			fmt.Printf("   • %s (%s, submitted %s)\n", record.Name, record.ID, record.CreatedAt.Format(time.RFC3339))
+		}
+		if policy.MaxAnalysisAge > 0 && !report.DryRun {
+			fmt.Printf("🧹 Deleted %d analysis runs and %d resolved findings\n", report.PrunedAnalyses, report.PrunedFindings)
+		}
+	}
+	return err
+}
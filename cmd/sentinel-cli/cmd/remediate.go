@@ -0,0 +1,120 @@
+// Package cmd provides the remediate command for planning component upgrades.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the remediate command.
+const (
+	remediateOutputText     = "text"
+	remediateOutputMarkdown = "markdown"
+)
+
+// remediateCmd represents the remediate command
+var remediateCmd = &cobra.Command{
+	Use:   "remediate [SBOM_FILE]",
+	Short: "Plan the upgrades that fix an SBOM's known vulnerabilities",
+	Long: `Scan an SBOM file for known vulnerabilities and turn the findings into an
+upgrade plan. Findings are grouped by component, and each component is listed
+with its current version, the nearest version fixing all of its known
+vulnerabilities and the findings that upgrade resolves. The most severe
+upgrades come first; components without a known fix are listed last.
+
+With --output markdown the plan is a Markdown checklist, ready to paste into
+an issue or pull request.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemediate,
+}
+
+func init() {
+	rootCmd.AddCommand(remediateCmd)
+
+	remediateCmd.Flags().StringP("format", "f", "auto", "SBOM format (auto, cyclonedx, gobinary)")
+	remediateCmd.Flags().StringP("output", "o", remediateOutputText, "Plan format: text or markdown")
+}
+
+// runRemediate executes the remediate command
+func runRemediate(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	format, _ := cmd.Flags().GetString("format")
+
+	output, _ := cmd.Flags().GetString("output")
+	if output != remediateOutputText && output != remediateOutputMarkdown {
+		return fmt.Errorf("invalid output format %q (expected text or markdown)", output)
+	}
+
+	sbom, _, err := loadSBOMFile(filePath, format, verbose)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	timeouts, err := analysis.AgentTimeoutsFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Invalid agent timeout configuration: %v\n", err)
+	}
+
+	// Progress goes to standard error so that the Markdown plan can be redirected
+	fmt.Fprintf(os.Stderr, "🔍 Scanning %d components of %s for known vulnerabilities\n", len(sbom.Components), sbom.Name)
+
+	scanner := analysis.NewVulnerabilityScanningAgent()
+	results, err := analysis.RunAgent(ctx, scanner, timeouts.For(scanner.Name()), *sbom)
+	if err != nil {
+		return fmt.Errorf("vulnerability scan failed: %w", err)
+	}
+
+	plan := report.NewUpgradePlan(results)
+	if output == remediateOutputMarkdown {
+		return report.WriteUpgradePlanMarkdown(os.Stdout, sbom.Name, plan)
+	}
+
+	printUpgradePlan(plan)
+	return nil
+}
+
+// printUpgradePlan prints an upgrade plan as numbered steps, followed by
+// the components without a known fix.
+func printUpgradePlan(plan []report.Upgrade) {
+	if len(plan) == 0 {
+		fmt.Printf("\n✅ No known vulnerabilities\n")
+		return
+	}
+
+	var fixable, unfixable []report.Upgrade
+	for _, upgrade := range plan {
+		if upgrade.Fixable() {
+			fixable = append(fixable, upgrade)
+		} else {
+			unfixable = append(unfixable, upgrade)
+		}
+	}
+
+	if len(fixable) > 0 {
+		fmt.Printf("\n🔧 Upgrade Plan:\n")
+		for i, upgrade := range fixable {
+			fmt.Printf("   %d. %s %s %s → %s\n", i+1, getSeverityIcon(upgrade.Severity),
+				upgrade.Component.Name, upgrade.Component.Version, upgrade.To)
+			fmt.Printf("      Resolves %d findings: %s\n", len(upgrade.Findings), strings.Join(upgrade.VulnerabilityIDs(), ", "))
+		}
+	}
+
+	if len(unfixable) > 0 {
+		fmt.Printf("\n⚠️  No known fix:\n")
+		for _, upgrade := range unfixable {
+			fmt.Printf("   • %s %s %s\n", getSeverityIcon(upgrade.Severity), upgrade.Component.Name, upgrade.Component.Version)
+			fmt.Printf("      %d findings: %s\n", len(upgrade.Findings), strings.Join(upgrade.VulnerabilityIDs(), ", "))
+		}
+	}
+}
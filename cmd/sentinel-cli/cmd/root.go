@@ -2,6 +2,9 @@
 package cmd
 
 import (
+	"errors"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/buildinfo"
 	"github.com/spf13/cobra"
 )
 
@@ -15,7 +18,7 @@ license compliance, and dependency health using local AI engines.
 
 This CLI tool allows you to analyze SBOM documents in various formats
 including CycloneDX and SPDX.`,
-	Version: "0.1.0",
+	Version: buildinfo.String(),
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -24,7 +27,28 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// exitError is an error that ends the CLI with a specific exit code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit code for an error returned by Execute:
+// the code a command asked for, or 1.
+func ExitCode(err error) int {
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	return 1
+}
+
 func init() {
 	// Add global flags here if needed
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().String("config", "", "Configuration file (defaults to $SENTINEL_CONFIG or ./sentinel.yaml)")
 }
\ No newline at end of file
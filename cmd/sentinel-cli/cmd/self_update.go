@@ -0,0 +1,107 @@
+// Package cmd provides the self-update command, which replaces the CLI with
+// the latest release.
+package cmd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/buildinfo"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/release"
+	"github.com/hueyexe/SBOM-Sentinel/internal/versions"
+	"github.com/spf13/cobra"
+)
+
+// selfUpdateCmd represents the self-update command
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update sentinel-cli to the latest release",
+	Long: `Check GitHub for the latest release of SBOM Sentinel and, if it is newer,
+replace this binary with the release's binary for this platform.
+
+The download is verified against the SHA-256 checksums published with the
+release, and the checksums against their Ed25519 signature with the release
+key embedded in release builds. Builds without a release key, such as those
+built from source, need --skip-signature to update, and are only replaced
+with --force as their version is unknown.`,
+	Args: cobra.NoArgs,
+	RunE: runSelfUpdate,
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+
+	selfUpdateCmd.Flags().Bool("check", false, "Only report whether an update is available")
+	selfUpdateCmd.Flags().Bool("force", false, "Install the latest release even if it is not newer")
+	selfUpdateCmd.Flags().Bool("skip-signature", false, "Verify only the checksums of a build without a release key")
+	selfUpdateCmd.Flags().String("repository", release.DefaultRepository, "GitHub repository (owner/name) to update from")
+}
+
+// runSelfUpdate executes the self-update command
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	check, _ := cmd.Flags().GetBool("check")
+	force, _ := cmd.Flags().GetBool("force")
+	skipSignature, _ := cmd.Flags().GetBool("skip-signature")
+	repository, _ := cmd.Flags().GetString("repository")
+
+	var publicKey ed25519.PublicKey
+	if buildinfo.ReleaseKey != "" {
+		key, err := release.ParsePublicKey(buildinfo.ReleaseKey)
+		if err != nil {
+			return err
+		}
+		publicKey = key
+	} else if !skipSignature && !check {
+		return errors.New("this build has no release key to verify updates with; use --skip-signature to verify checksums only")
+	}
+
+	ctx := context.Background()
+	client := release.NewClient(release.DefaultBaseURL, repository, publicKey)
+	latest, err := client.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := buildinfo.Version
+	newer := versions.CompareSemver(latest.Version(), current) > 0
+	switch {
+	case !buildinfo.IsRelease() && !force:
+		fmt.Printf("ℹ️  This is a development build; the latest release is %s\n", latest.Version())
+		if !check {
+			fmt.Printf("   Use --force to replace it with the release\n")
+		}
+		return nil
+	case !newer && !force:
+		fmt.Printf("✅ sentinel-cli %s is up to date\n", current)
+		return nil
+	case check:
+		fmt.Printf("⬆️  Update available: %s → %s\n   %s\n", current, latest.Version(), latest.HTMLURL)
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate the running binary: %w", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("cannot locate the running binary: %w", err)
+	}
+
+	asset := release.BinaryName("sentinel-cli", runtime.GOOS, runtime.GOARCH)
+	fmt.Printf("⬇️  Downloading %s %s\n", asset, latest.TagName)
+	data, err := client.Download(ctx, latest, asset)
+	if err != nil {
+		return err
+	}
+	if err := release.Replace(executable, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Updated %s from %s to %s\n", executable, current, latest.Version())
+	return nil
+}
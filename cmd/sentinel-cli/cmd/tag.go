@@ -0,0 +1,222 @@
+// Package cmd provides the tag commands for labelling stored SBOMs.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// tagCmd groups the commands managing the tags of stored SBOMs
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage the tags of stored SBOMs",
+	Long: `Manage the tags of stored SBOMs, key=value labels such as team=payments,
+env=prod or criticality=high.
+
+Tags filter the SBOMs listed by the API (?tag=env=prod) and are seen by gate
+policies as input.sbom.tags, so that policies can be stricter for some
+SBOMs. Tags declared for a project in the config file apply to all of its
+SBOMs, unless an SBOM has its own tag of the same key.`,
+}
+
+// tagSetCmd adds or changes tags of an SBOM
+var tagSetCmd = &cobra.Command{
+	Use:   "set <sbom-id> <key=value>...",
+	Short: "Add or change tags of an SBOM, e.g. env=prod",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagSet,
+}
+
+// tagRemoveCmd removes tags of an SBOM
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <sbom-id> <key>...",
+	Short: "Remove tags of an SBOM",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagRemove,
+}
+
+// tagShowCmd shows the tags of an SBOM
+var tagShowCmd = &cobra.Command{
+	Use:   "show <sbom-id>",
+	Short: "Show the tags of an SBOM and of its project",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTagShow,
+}
+
+// tagListCmd lists the SBOMs with given tags
+var tagListCmd = &cobra.Command{
+	Use:   "list [key=value]...",
+	Short: "List the stored SBOMs with every given tag",
+	Args:  cobra.ArbitraryArgs,
+	RunE:  runTagList,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagSetCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagShowCmd)
+	tagCmd.AddCommand(tagListCmd)
+
+	tagCmd.PersistentFlags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+}
+
+// openTags opens the database named by --db, $DATABASE_PATH or the default.
+func openTags(cmd *cobra.Command) (*database.SQLiteRepository, error) {
+	dbPath, _ := cmd.Flags().GetString("db")
+	return wiring.OpenRepository(wiring.DatabasePath(dbPath))
+}
+
+// runTagSet executes the tag set command
+func runTagSet(cmd *cobra.Command, args []string) error {
+	changes, err := core.ParseTags(args[1:])
+	if err != nil {
+		return err
+	}
+	return updateTags(cmd, args[0], func(tags map[string]string) {
+		maps.Copy(tags, changes)
+	})
+}
+
+// runTagRemove executes the tag remove command
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	return updateTags(cmd, args[0], func(tags map[string]string) {
+		for _, key := range args[1:] {
+			delete(tags, strings.ToLower(strings.TrimSpace(key)))
+		}
+	})
+}
+
+// updateTags changes the tags of the SBOM with the given ID with update and
+// prints them.
+func updateTags(cmd *cobra.Command, id string, update func(tags map[string]string)) error {
+	repo, err := openTags(cmd)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	sbom, err := repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sbom == nil {
+		return fmt.Errorf("SBOM %s not found", id)
+	}
+
+	tags := maps.Clone(sbom.Tags)
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	update(tags)
+	tags, err = core.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if _, err := repo.SetTags(ctx, id, tags); err != nil {
+		return err
+	}
+
+	fmt.Printf("🏷️  Tagged %s (%s)\n", sbom.Name, id)
+	printTags("   ", tags)
+	return nil
+}
+
+// runTagShow executes the tag show command
+func runTagShow(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	repo, err := openTags(cmd)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	sbom, err := repo.FindByID(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	if sbom == nil {
+		return fmt.Errorf("SBOM %s not found", args[0])
+	}
+
+	fmt.Printf("🏷️  %s (%s)\n", sbom.Name, sbom.ID)
+	effective := sbom.EffectiveTags(cfg.Projects)
+	if len(effective) == 0 {
+		fmt.Println("   No tags")
+		return nil
+	}
+	for _, key := range sortedTagKeys(effective) {
+		fmt.Printf("   %s=%s", key, effective[key])
+		if _, own := sbom.Tags[key]; !own {
+			fmt.Print("  (project)")
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// runTagList executes the tag list command
+func runTagList(cmd *cobra.Command, args []string) error {
+	filter, err := core.ParseTags(args)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	repo, err := openTags(cmd)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	records, err := repo.ListRecords(context.Background())
+	if err != nil {
+		return err
+	}
+
+	listed := 0
+	for _, record := range records {
+		tags := core.SBOM{Name: record.Name, Tags: record.Tags}.EffectiveTags(cfg.Projects)
+		if !core.MatchesTags(tags, filter) {
+			continue
+		}
+		listed++
+		fmt.Printf("%s  %s  %s\n", record.ID, record.Name, record.CreatedAt.Format("2006-01-02"))
+		printTags("   ", tags)
+	}
+	if listed == 0 {
+		fmt.Println("No stored SBOMs match")
+	}
+	return nil
+}
+
+// printTags prints tags in key order, one per line.
+func printTags(indent string, tags map[string]string) {
+	for _, key := range sortedTagKeys(tags) {
+		fmt.Printf("%s%s=%s\n", indent, key, tags[key])
+	}
+}
+
+// sortedTagKeys returns the keys of tags in sorted order.
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
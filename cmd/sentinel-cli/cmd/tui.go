@@ -0,0 +1,120 @@
+// Package cmd provides the tui command for exploring analysis results interactively.
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/explorer"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui <sbom-id|SBOM_FILE>",
+	Short: "Explore the analysis results of an SBOM interactively",
+	Long: `Analyze an SBOM file, or an SBOM stored in the database by ID, and
+explore the results interactively instead of scrolling through the output of
+'analyze'.
+
+The explorer shows a list pane of findings, which can be filtered by
+severity (s), agent (a) and component (/), or of components (tab), beside
+a detail pane of the selected row; press enter to scroll the details, ? for
+the list of keys and q to quit. The agents are selected with the same flags
+as for 'analyze'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTUI,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().String("db", "", "SQLite database path for SBOM IDs (defaults to $DATABASE_PATH or ./sentinel.db)")
+	tuiCmd.Flags().StringP("format", "f", "auto", "SBOM format of files (auto, cyclonedx, gobinary)")
+	addProfileFlag(tuiCmd)
+	addPluginFlag(tuiCmd)
+	addRulesFlag(tuiCmd)
+	tuiCmd.Flags().Bool("enable-ai-health-check", false, "Enable AI-powered dependency health analysis (requires Ollama)")
+	tuiCmd.Flags().Bool("enable-proactive-scan", false, "Enable proactive vulnerability discovery using RAG (requires Ollama)")
+	addRAGFlags(tuiCmd)
+	tuiCmd.Flags().Bool("enable-vuln-scan", false, "Enable known vulnerability scanning using OSV.dev database")
+	tuiCmd.Flags().Bool("enable-quality-check", false, "Enable SBOM quality scoring against NTIA minimum elements")
+	tuiCmd.Flags().Bool("enable-crypto-check", false, "Enable cryptographic library inventory with FIPS and post-quantum concerns")
+	tuiCmd.Flags().Bool("enable-export-check", false, "Enable export-control tagging of encryption and networking components")
+	tuiCmd.Flags().Bool("enable-base-image-check", false, "Enable base image staleness checks for container image SBOMs")
+	tuiCmd.Flags().Bool("enable-repo-activity-check", false, "Enable activity checks of component source repositories on GitHub and GitLab")
+}
+
+// runTUI executes the tui command
+func runTUI(cmd *cobra.Command, args []string) error {
+	selection, err := agentSelection(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Stop the analysis on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	sbom, err := loadTUISBOM(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	timeouts, err := analysis.AgentTimeoutsFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid agent timeout configuration: %v\n", err)
+	}
+	selection.ConfigureProactive = func(agent *analysis.ProactiveVulnerabilityAgent) {
+		configureRAG(cmd, agent)
+	}
+	orchestrator := wiring.NewOrchestrator(timeouts, selection)
+
+	fmt.Printf("🔍 Analyzing %s with %d agents...\n", sbom.Name, len(orchestrator.Agents()))
+	report, err := orchestrator.Run(ctx, *sbom)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	for _, run := range report.Failures() {
+		fmt.Printf("⚠️  %s (%s): %v\n", run.Agent, run.Status, run.Err)
+	}
+	stop()
+
+	_, err = tea.NewProgram(explorer.New(*sbom, report.Results), tea.WithAltScreen()).Run()
+	return err
+}
+
+// loadTUISBOM reads the SBOM file at arg or, if there is none, the stored
+// SBOM with ID arg.
+func loadTUISBOM(ctx context.Context, cmd *cobra.Command, arg string) (*core.SBOM, error) {
+	if _, err := os.Stat(arg); err == nil {
+		format, _ := cmd.Flags().GetString("format")
+		sbom, _, err := loadSBOMFile(arg, format, false)
+		return sbom, err
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	dbPath, _ := cmd.Flags().GetString("db")
+	repo, err := wiring.OpenRepository(wiring.DatabasePath(dbPath))
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+
+	sbom, err := repo.FindByID(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SBOM %s: %w", arg, err)
+	}
+	if sbom == nil {
+		return nil, fmt.Errorf("no SBOM file or stored SBOM %s found", arg)
+	}
+	return sbom, nil
+}
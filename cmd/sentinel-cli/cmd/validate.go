@@ -0,0 +1,104 @@
+// Package cmd provides the validate command for checking SBOM documents
+// against the JSON Schema of their format.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the validate command.
+const (
+	validateOutputText = "text"
+	validateOutputJSON = "json"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [SBOM_FILE]",
+	Short: "Validate an SBOM against the CycloneDX or SPDX JSON Schema",
+	Long: `Validate a CycloneDX or SPDX JSON document strictly against the JSON
+Schema of its format and version, and report every violation by its JSON
+Pointer path. Unlike 'analyze', which accepts any document it can make sense
+of, unknown properties and malformed values are errors.
+
+Schemas covering the structure of CycloneDX 1.2-1.6 and SPDX 2.2-2.3 are
+bundled. For complete validation, such as of SPDX license IDs, download the
+official schemas (bom-1.6.schema.json with spdx.schema.json and
+jsf-0.82.schema.json, or spdx-schema.json) into a directory and pass it with
+--schema-dir or SENTINEL_SCHEMA_DIR.
+
+The command exits with 1 if the document is invalid.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidate,
+	// An invalid document is not a usage error, and main reports the error
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().String("schema-dir", os.Getenv("SENTINEL_SCHEMA_DIR"), "Directory of official JSON Schema files (default $SENTINEL_SCHEMA_DIR)")
+	validateCmd.Flags().StringP("output", "o", validateOutputText, "Report format: text or json")
+}
+
+// runValidate executes the validate command
+func runValidate(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+
+	output, _ := cmd.Flags().GetString("output")
+	if output != validateOutputText && output != validateOutputJSON {
+		return fmt.Errorf("invalid output format %q (expected text or json)", output)
+	}
+
+	validator := schema.NewValidator()
+	if dir, _ := cmd.Flags().GetString("schema-dir"); dir != "" {
+		if err := validator.LoadDir(dir); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file '%s': %w", filePath, err)
+	}
+
+	result, err := validator.Validate(data)
+	if err != nil {
+		return fmt.Errorf("failed to validate '%s': %w", filePath, err)
+	}
+
+	if output == validateOutputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to encode validation result: %w", err)
+		}
+	} else {
+		printValidationResult(filePath, result)
+	}
+
+	if !result.Valid() {
+		return &exitError{code: 1, err: fmt.Errorf("'%s' is not a valid %s document (%d violations)", filePath, result.Format, len(result.Violations))}
+	}
+	return nil
+}
+
+// printValidationResult prints the schema used and each violation.
+func printValidationResult(filePath string, result schema.Result) {
+	if result.Valid() {
+		fmt.Printf("✅ %s is a valid %s document (schema: %s)\n", filePath, result.Format, result.Schema)
+		return
+	}
+
+	fmt.Printf("❌ %s is not a valid %s document (schema: %s)\n", filePath, result.Format, result.Schema)
+	fmt.Printf("\n%d violations:\n", len(result.Violations))
+	for _, violation := range result.Violations {
+		fmt.Printf("   • %s\n", violation)
+	}
+}
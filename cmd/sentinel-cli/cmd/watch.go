@@ -0,0 +1,158 @@
+// Package cmd provides the watch commands for managing the package watchlist.
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/internal/watchlist"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+	"github.com/spf13/cobra"
+)
+
+// watchCmd groups the commands managing watched packages
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Manage the watchlist of packages alerted on",
+	Long: `Manage the watchlist of packages, identified by Package URL.
+
+When the server harvests security intelligence that mentions a watched
+package, every stored SBOM using the package is sent to the configured
+notification channels right away, without waiting for the SBOM to be
+analyzed again.`,
+}
+
+// watchAddCmd adds a package to the watchlist
+var watchAddCmd = &cobra.Command{
+	Use:   "add <purl>",
+	Short: "Watch a package, e.g. pkg:npm/lodash",
+	Long: `Watch the package with the given Package URL. Without a version every
+version is watched; pkg:npm/lodash@4.17.20 only watches that version.
+Qualifiers and subpaths are ignored.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatchAdd,
+}
+
+// watchListCmd lists the watched packages
+var watchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the watched packages",
+	Args:  cobra.NoArgs,
+	RunE:  runWatchList,
+}
+
+// watchRemoveCmd removes a package from the watchlist
+var watchRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Stop watching a package",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWatchRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.AddCommand(watchAddCmd)
+	watchCmd.AddCommand(watchListCmd)
+	watchCmd.AddCommand(watchRemoveCmd)
+
+	watchCmd.PersistentFlags().String("db", "", "SQLite database path (defaults to $DATABASE_PATH or ./sentinel.db)")
+	watchAddCmd.Flags().String("note", "", "Why the package is watched")
+}
+
+// openWatchlist opens the database named by --db, $DATABASE_PATH or the default.
+func openWatchlist(cmd *cobra.Command) (*database.SQLiteRepository, error) {
+	dbPath, _ := cmd.Flags().GetString("db")
+	return wiring.OpenRepository(wiring.DatabasePath(dbPath))
+}
+
+// runWatchAdd executes the watch add command
+func runWatchAdd(cmd *cobra.Command, args []string) error {
+	note, _ := cmd.Flags().GetString("note")
+
+	watch, err := watchlist.NewWatch(args[0], note)
+	if err != nil {
+		return err
+	}
+
+	repo, err := openWatchlist(cmd)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	stored, err := repo.AddWatch(ctx, watch)
+	if err != nil {
+		return err
+	}
+	if stored.ID != watch.ID {
+		fmt.Printf("👀 Already watching %s (%s)\n", stored.PURL, stored.ID)
+	} else {
+		fmt.Printf("👀 Watching %s (%s)\n", stored.PURL, stored.ID)
+	}
+
+	// Show what an alert would cover today
+	matcher, err := watchlist.NewMatcher(stored)
+	if err != nil {
+		return err
+	}
+	sboms, err := repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	used := 0
+	for _, sbom := range sboms {
+		if len(matcher.Components(sbom)) > 0 {
+			used++
+		}
+	}
+	fmt.Printf("   Used by %d of %d stored SBOMs\n", used, len(sboms))
+	return nil
+}
+
+// runWatchList executes the watch list command
+func runWatchList(cmd *cobra.Command, args []string) error {
+	repo, err := openWatchlist(cmd)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	watches, err := repo.ListWatches(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(watches) == 0 {
+		fmt.Println("No packages are watched")
+		return nil
+	}
+
+	for _, watch := range watches {
+		fmt.Printf("%s  %s", watch.ID, watch.PURL)
+		if watch.Note != "" {
+			fmt.Printf("  (%s)", watch.Note)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// runWatchRemove executes the watch remove command
+func runWatchRemove(cmd *cobra.Command, args []string) error {
+	repo, err := openWatchlist(cmd)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	deleted, err := repo.DeleteWatch(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("no watch with ID %s", args[0])
+	}
+	fmt.Printf("🗑️  Stopped watching %s\n", args[0])
+	return nil
+}
@@ -3,44 +3,342 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/api"
+	"github.com/hueyexe/SBOM-Sentinel/internal/admission"
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/buildinfo"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/jobs"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/dtrack"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/registry"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/hueyexe/SBOM-Sentinel/internal/plugin"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/hueyexe/SBOM-Sentinel/internal/retention"
+	"github.com/hueyexe/SBOM-Sentinel/internal/rules"
+	"github.com/hueyexe/SBOM-Sentinel/internal/schema"
+	"github.com/hueyexe/SBOM-Sentinel/internal/sla"
 	"github.com/hueyexe/SBOM-Sentinel/internal/transport/rest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/watchlist"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
 )
 
+// serverRepository is the storage the server runs on: the SQLite database,
+// or memory in demo mode.
+type serverRepository interface {
+	storage.Repository
+	storage.Pruner
+	storage.Tagger
+	storage.Watchlist
+	storage.FindingHistory
+	storage.FindingTriage
+	storage.SLABreaches
+	storage.IdempotencyStore
+	Ping(ctx context.Context) error
+	Close() error
+}
+
 func main() {
-	fmt.Println("SBOM Sentinel Server - Starting...")
+	showVersion := flag.Bool("version", false, "Print the version and exit")
+	initialize := flag.Bool("init", false, "Write a default config file and create the database, then exit")
+	seed := flag.Bool("seed", false, "With --init, also harvest the security intelligence corpus into a persistent vector store")
+	admissionWebhook := flag.Bool("admission-webhook", false, "Serve the Kubernetes validating admission webhook instead of the API")
+	demo := flag.Bool("demo", false, "Keep SBOMs and findings in memory instead of the database; nothing is written to disk")
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("sentinel-server version %s\n", buildinfo.String())
+		return
+	}
+	if *initialize {
+		if err := runInit(*seed); err != nil {
+			log.Fatalf("Initialization failed: %v", err)
+		}
+		return
+	}
+
+	fmt.Printf("SBOM Sentinel Server %s - Starting...\n", buildinfo.Version)
 
-	// Initialize SQLite database
-	dbPath := os.Getenv("DATABASE_PATH")
-	if dbPath == "" {
-		dbPath = "./sentinel.db"
+	// Initialize SQLite database, or keep everything in memory in demo mode
+	var repo serverRepository
+	if *demo {
+		repo = memory.NewMemoryRepository()
+		fmt.Println("Demo mode: SBOMs and findings are kept in memory and lost on exit")
+	} else {
+		dbPath := wiring.DatabasePath("")
+		sqlite, err := wiring.OpenRepository(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		repo = sqlite
+		fmt.Printf("Database initialized: %s\n", dbPath)
 	}
+	defer repo.Close()
 
-	repo, err := database.NewSQLiteRepository(dbPath)
+	// Prune old SBOM versions in the background if a retention policy is set
+	retentionPolicy, err := retention.PolicyFromEnv()
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		fmt.Printf("Warning: Invalid retention configuration: %v\n", err)
+	}
+	retentionSchedule, err := retention.ScheduleFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid retention configuration: %v\n", err)
+	}
+	if retentionPolicy.Enabled() {
+		fmt.Printf("Retention: pruning every %s (dry run: %t)\n", retentionSchedule.Interval, retentionSchedule.DryRun)
+		retention.NewJanitor(repo, retentionPolicy).Start(context.Background(), retentionSchedule)
 	}
-	defer repo.Close()
 
-	fmt.Printf("Database initialized: %s\n", dbPath)
+	// Submitted SBOMs are also pushed to Dependency-Track if it is configured
+	var submissions storage.Repository = repo
+	exporter, err := dtrack.ExporterFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Dependency-Track export disabled: %v\n", err)
+	}
+	if exporter != nil {
+		fmt.Printf("Dependency-Track export enabled: %s\n", os.Getenv("DTRACK_URL"))
+		submissions = exporter.Syncing(repo)
+	}
 
-	// Configure routes
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write([]byte(`{"status":"ok","service":"sbom-sentinel"}`)); err != nil {
-			log.Printf("Error writing health check response: %v", err)
+	// The security intelligence corpus is shared by all proactive scans and
+	// harvested on first use
+	intelligence := analysis.NewIntelligenceStoreFromEnv()
+	if err := intelligence.ConfigureRefreshFromEnv(); err != nil {
+		fmt.Printf("Warning: Invalid intelligence refresh configuration: %v\n", err)
+	}
+	intelligence.StartRefresher(context.Background())
+
+	// New intelligence mentioning watched packages used by stored SBOMs is
+	// sent to the notification channels
+	intelligence.OnNewIntelligence(watchlist.NewAlerter(repo, repo, rest.WatchlistNotifier).Listen)
+
+	// Findings open past the SLA of their severity are sent to the same
+	// channels; the SLA is read per check so that reloads apply
+	slaInterval, err := sla.IntervalFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid SLA configuration: %v\n", err)
+	}
+	if len(config.Default().SLA) > 0 {
+		fmt.Printf("SLA: checking for breaches every %s\n", slaInterval)
+	}
+	sla.NewMonitor(repo, repo, repo, func() sla.Policy { return config.Default().SLA }, rest.SLANotifier).Start(context.Background(), slaInterval)
+
+	// Agent time limits are read per request; report invalid settings once
+	if _, err := analysis.AgentTimeoutsFromEnv(); err != nil {
+		fmt.Printf("Warning: Invalid agent timeout configuration: %v\n", err)
+	}
+
+	// Analysis profiles are read per request; load the config file once up front
+	profiles := config.Default().ProfileNames()
+	fmt.Printf("Analysis profiles: %s\n", strings.Join(profiles, ", "))
+
+	// Plugin agents are discovered once and run in every analysis
+	for _, agent := range plugin.AgentsFromEnv() {
+		fmt.Printf("Plugin agent loaded: %s (%s)\n", agent.Name(), agent.Path())
+	}
+
+	// Custom rules are read per request with the config file; report problems once up front
+	if rules, err := plugin.WasmAgents(config.Default().Rules); err != nil {
+		fmt.Printf("Warning: Some rules are skipped: %v\n", err)
+	} else if len(rules) > 0 {
+		fmt.Printf("Custom rules enabled: %d rules\n", len(rules))
+	}
+
+	// Expression rules run in every analysis; report problems once up front
+	if engine := rules.AgentFromEnv(); engine != nil {
+		fmt.Printf("Rule engine enabled: %d rules from %s\n", len(engine.Rules()), os.Getenv("SENTINEL_RULES_FILE"))
+	}
+
+	// Gate policies are evaluated against every analysis; report problems once up front
+	if policy.EvaluatorFromEnv() != nil {
+		fmt.Printf("Policy gate enabled: %s\n", os.Getenv("POLICY_PATH"))
+	}
+
+	// Findings of every analysis are sent to the configured channels
+	if channels := config.Default().Notifications; len(channels) > 0 {
+		fmt.Printf("Notifications enabled: %d channels\n", len(channels))
+	}
+
+	// SIGHUP reloads the configuration file and policies, as does the admin
+	// reload endpoint
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+	go func() {
+		for range reloads {
+			reloadConfiguration()
 		}
-	})
+	}()
+
+	// In admission webhook mode, only the webhook and probes are served
+	if *admissionWebhook {
+		log.Fatal(serveAdmissionWebhook(repo, intelligence))
+	}
+
+	// Analyses requested with ?async=true are queued and processed in the
+	// background, in process or from Redis
+	queue, err := jobs.QueueFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid job queue configuration: %v", err)
+	}
+	defer queue.Close()
+	workers, err := jobs.WorkersFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid job worker configuration: %v\n", err)
+	}
+	switch {
+	case workers == 0 && os.Getenv("JOB_QUEUE_URL") == "":
+		fmt.Println("Warning: JOB_WORKERS=0 without JOB_QUEUE_URL: queued analyses are never processed")
+	case workers == 0:
+		fmt.Println("Job queue: Redis, processed by sentinel-worker")
+	case os.Getenv("JOB_QUEUE_URL") != "":
+		fmt.Printf("Job queue: Redis, %d workers\n", workers)
+	}
+	// SIGINT and SIGTERM stop the server; analyses interrupted by them are
+	// queued again for another worker
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	working := make(chan struct{})
+	go func() {
+		defer close(working)
+		jobs.Work(ctx, queue, workers, rest.AnalysisJobHandler(repo, intelligence))
+	}()
+
+	// Without API_KEYS or api_keys in the config file every request is allowed
+	auth, err := rest.AuthorizerFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid API key configuration: %v", err)
+	}
+	if auth.Enabled() {
+		fmt.Println("API key authentication enabled")
+	}
+
+	// Submissions larger than the configured limits are rejected
+	limits, err := rest.LimitsFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid submission limit configuration: %v\n", err)
+	}
+	fmt.Printf("Submission limits: %d bytes, %d components\n", limits.MaxUploadBytes, limits.MaxComponents)
+
+	// Browser frontends on other origins need CORS headers
+	cors, err := rest.CORSFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid CORS configuration: %v\n", err)
+	}
+	if cors.Enabled() {
+		fmt.Printf("CORS enabled for origins: %s\n", strings.Join(cors.AllowedOrigins, ", "))
+	}
+
+	// Clients of v1 are told when it is deprecated and removed
+	versioning, err := rest.VersioningFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid API versioning configuration: %v\n", err)
+	}
+	if !versioning.Sunset.IsZero() {
+		fmt.Printf("API v1 sunset: %s\n", versioning.Sunset.Format(time.DateOnly))
+	}
 
-	// API v1 routes
-	http.HandleFunc("/api/v1/sboms", rest.SubmitSBOMHandler(repo))
-	http.HandleFunc("/api/v1/sboms/get", rest.GetSBOMHandler(repo))
-	http.HandleFunc("/api/v1/sboms/", rest.AnalyzeSBOMHandler(repo)) // Handles /api/v1/sboms/{id}/analyze
+	// Strict validation schemas are loaded on first use; report problems once up front
+	if _, err := schema.FromEnv(); err != nil {
+		fmt.Printf("Warning: Invalid schema directory, using bundled schemas: %v\n", err)
+	} else if dir := os.Getenv("SENTINEL_SCHEMA_DIR"); dir != "" {
+		fmt.Printf("Strict validation schemas: %s\n", dir)
+	}
+
+	// Configure routes
+	// Probes need no API key; only the database is required to serve requests
+	healthChecks := []rest.HealthCheck{
+		{Name: "database", Critical: true, Check: repo.Ping},
+		{Name: "vector_store", Check: intelligence.Ping},
+		{Name: "ollama", Check: analysis.CheckOllama},
+	}
+	if redisQueue, ok := queue.(*jobs.RedisQueue); ok {
+		healthChecks = append(healthChecks, rest.HealthCheck{Name: "job_queue", Check: redisQueue.Ping})
+	}
+	http.HandleFunc("/health", rest.LivenessHandler()) // Legacy alias of /healthz
+	http.HandleFunc("/healthz", rest.LivenessHandler())
+	http.HandleFunc("/readyz", rest.ReadinessHandler(healthChecks))
+	// Scraped by Prometheus like the probes; it exposes only agent totals
+	http.HandleFunc("/metrics", rest.MetricsHandler())
+	// The API description is public so that clients can be generated from it
+	http.HandleFunc("/api/openapi.yaml", rest.OpenAPIHandler(api.OpenAPI))
+
+	// Every API route is served as v1 and, through the compatibility shim
+	// returning structured findings, as v2
+	handleAPI := func(path string, handler http.HandlerFunc) {
+		http.HandleFunc(rest.V1Prefix+path, versioning.V1(http.DefaultServeMux, handler))
+		http.HandleFunc(rest.V2Prefix+path, rest.V2(handler))
+	}
+
+	// API routes; viewers may read, analysts may submit and analyze, and
+	// destructive operations are reserved for admins
+	documentRoles := map[string]rest.Role{
+		http.MethodGet:    rest.RoleViewer,
+		http.MethodPost:   rest.RoleAnalyst,
+		http.MethodDelete: rest.RoleAdmin,
+	}
+	sbomRoles := map[string]rest.Role{
+		http.MethodGet:  rest.RoleViewer,
+		http.MethodPost: rest.RoleAnalyst,
+	}
+	storedSBOMRoles := map[string]rest.Role{
+		http.MethodGet:    rest.RoleViewer,
+		http.MethodDelete: rest.RoleAdmin,
+	}
+	tagRoles := map[string]rest.Role{
+		http.MethodGet:   rest.RoleViewer,
+		http.MethodPut:   rest.RoleAnalyst,
+		http.MethodPatch: rest.RoleAnalyst,
+	}
+	// Analysts triage the findings of the analyses they run
+	findingRoles := map[string]rest.Role{
+		http.MethodGet:   rest.RoleViewer,
+		http.MethodPatch: rest.RoleAnalyst,
+	}
+	// Analysts may cancel the analyses they are allowed to start
+	jobRoles := map[string]rest.Role{
+		http.MethodGet:    rest.RoleViewer,
+		http.MethodDelete: rest.RoleAnalyst,
+	}
+	// Retries with the same Idempotency-Key replay the first response
+	idempotency := rest.NewIdempotency(repo)
+	handleAPI("/sboms", auth.RequireByMethod(sbomRoles, rest.ListSBOMsHandler(repo, limits.Limit(idempotency.Idempotent(rest.SubmitSBOMHandler(submissions))))))
+	handleAPI("/sboms/batch", auth.Require(rest.RoleAnalyst, limits.Limit(idempotency.Idempotent(rest.SubmitSBOMBatchHandler(submissions)))))
+	http.HandleFunc("/api/v1/sboms/get", versioning.V1(http.DefaultServeMux, auth.Require(rest.RoleViewer, rest.GetSBOMHandler(repo)))) // Legacy ?id= form of /api/v1/sboms/{id}
+	handleAPI("/sboms/{id}", auth.RequireByMethod(storedSBOMRoles, rest.DeleteSBOMHandler(repo, repo, rest.GetSBOMHandler(repo))))
+	handleAPI("/sboms/{id}/tags", auth.RequireByMethod(tagRoles, rest.SBOMTagsHandler(repo, repo)))
+	handleAPI("/sboms/{id}/analyze", auth.Require(rest.RoleAnalyst, idempotency.Idempotent(rest.AsyncAnalyzeHandler(repo, queue, rest.AnalyzeSBOMHandler(repo, intelligence)))))
+	handleAPI("/jobs/{id}", auth.RequireByMethod(jobRoles, rest.JobHandler(queue)))
+	handleAPI("/analyses/bulk", auth.Require(rest.RoleAnalyst, rest.BulkAnalyzeHandler(repo, intelligence)))
+	handleAPI("/components", auth.Require(rest.RoleViewer, rest.ListComponentsHandler(repo)))
+	handleAPI("/owners", auth.Require(rest.RoleViewer, rest.OwnersHandler()))
+	handleAPI("/stats", auth.Require(rest.RoleViewer, rest.StatsHandler(repo, repo)))
+	handleAPI("/projects/{id}/trends", auth.Require(rest.RoleViewer, rest.ProjectTrendsHandler(repo, repo, intelligence)))
+	handleAPI("/projects/{id}/findings", auth.RequireByMethod(findingRoles, rest.TriageFindingsHandler(repo, repo, rest.ProjectFindingsHandler(repo))))
+	handleAPI("/projects/{id}/findings/{fingerprint}", auth.RequireByMethod(findingRoles, rest.TriageFindingsHandler(repo, repo, rest.ProjectFindingHandler(repo))))
+	handleAPI("/vulnerabilities/{id}/affected", auth.Require(rest.RoleViewer, rest.AffectedSBOMsHandler(repo, analysis.NewVulnerabilityScanningAgent())))
+	handleAPI("/intelligence/status", auth.Require(rest.RoleViewer, rest.IntelligenceStatusHandler(intelligence)))
+	handleAPI("/intelligence/documents", auth.RequireByMethod(documentRoles, rest.IntelligenceDocumentsHandler(intelligence)))
+	handleAPI("/intelligence/documents/{id...}", auth.RequireByMethod(documentRoles, rest.IntelligenceDocumentsHandler(intelligence))) // Document IDs may contain slashes
+	handleAPI("/watchlist", auth.RequireByMethod(documentRoles, rest.WatchlistHandler(repo)))
+	handleAPI("/watchlist/{id}", auth.RequireByMethod(documentRoles, rest.WatchlistHandler(repo)))
+	handleAPI("/admin/reload", auth.Require(rest.RoleAdmin, rest.ReloadHandler()))
+	handleAPI("/admin/config", auth.Require(rest.RoleAdmin, rest.ConfigHandler()))
+	handleAPI("/admin/waivers", auth.Require(rest.RoleAdmin, rest.WaiversHandler()))
+	handleAPI("/admin/api-keys/{name}/rotate", auth.Require(rest.RoleAdmin, rest.RotateAPIKeyHandler()))
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -49,12 +347,182 @@ func main() {
 
 	fmt.Printf("Server starting on port %s\n", port)
 	fmt.Println("Available endpoints:")
+	fmt.Println("  GET  /api/openapi.yaml                     - OpenAPI description of the API")
+	fmt.Println("  GET  /api/v1/sboms                         - List stored SBOMs")
+	fmt.Println("       Query params: ?name=project")
+	fmt.Println("                     ?tag=env=prod")
 	fmt.Println("  POST /api/v1/sboms                         - Submit SBOM file")
-	fmt.Println("  GET  /api/v1/sboms/get                     - Retrieve SBOM by ID")
+	fmt.Println("       Query params: ?validate=strict")
+	fmt.Println("                     ?tag=env=prod")
+	fmt.Println("                     ?force=true")
+	fmt.Println("                     ?replace=true")
+	fmt.Println("  POST /api/v1/sboms/batch                   - Submit several SBOM files, all or none")
+	fmt.Println("       Query params: as for POST /api/v1/sboms")
+	fmt.Println("  GET  /api/v1/sboms/{id}                    - Retrieve SBOM by ID")
+	fmt.Println("       Query params: ?fields=name,version&offset=0&limit=100")
+	fmt.Println("  DELETE /api/v1/sboms/{id}                  - Delete a stored SBOM")
+	fmt.Println("  GET  /api/v1/sboms/get?id={id}             - Retrieve SBOM by ID (legacy query form)")
+	fmt.Println("  GET  /api/v1/sboms/{id}/tags               - Tags of an SBOM and its project")
+	fmt.Println("  PUT  /api/v1/sboms/{id}/tags               - Replace the tags of an SBOM")
+	fmt.Println("  PATCH /api/v1/sboms/{id}/tags              - Add, change or remove (null) tags of an SBOM")
 	fmt.Println("  POST /api/v1/sboms/{id}/analyze            - Analyze stored SBOM")
-	fmt.Println("       Query params: ?enable-ai-health-check=true")
+	fmt.Println("       Query params: ?profile=quick")
+	fmt.Println("                     ?enable-ai-health-check=true")
 	fmt.Println("                     ?enable-proactive-scan=true")
-	fmt.Println("  GET  /health                               - Health check")
+	fmt.Println("                     ?enable-vuln-scan=true")
+	fmt.Println("                     ?enable-quality-check=true")
+	fmt.Println("                     ?enable-crypto-check=true")
+	fmt.Println("                     ?enable-export-check=true")
+	fmt.Println("                     ?enable-base-image-check=true")
+	fmt.Println("                     ?enable-repo-activity-check=true")
+	fmt.Println("                     ?rag-top-k=3&rag-similarity-threshold=0.3")
+	fmt.Println("                     ?min_severity=medium")
+	fmt.Println("                     ?min_confidence=0.7")
+	fmt.Println("                     ?async=true&priority=interactive|scheduled")
+	fmt.Println("  GET  /api/v1/jobs/{id}                     - Status and result of an asynchronous analysis")
+	fmt.Println("  DELETE /api/v1/jobs/{id}                   - Cancel a queued or running analysis")
+	fmt.Println("  POST /api/v1/analyses/bulk                 - Analyze every stored SBOM (NDJSON stream)")
+	fmt.Println("  GET  /api/v1/components                    - Component inventory across all SBOMs")
+	fmt.Println("       Query params: ?license=MIT&ecosystem=npm&owner=payments&tag=env=prod")
+	fmt.Println("  GET  /api/v1/owners                        - Teams owning components, from the config file")
+	fmt.Println("  GET  /api/v1/stats                         - Totals, open findings and riskiest components for dashboards")
+	fmt.Println("  GET  /api/v1/projects/{id}/trends          - Findings and license risk across a project's SBOM versions")
+	fmt.Println("       Query params: ?limit=20 plus the analyze params")
+	fmt.Println("  GET  /api/v1/projects/{id}/findings        - Finding lifecycle and mean time to remediate of a project")
+	fmt.Println("       Query params: ?status=open|resolved&triage=in-progress&assignee=alice")
+	fmt.Println("  PATCH /api/v1/projects/{id}/findings       - Triage several findings: state, assignee and note")
+	fmt.Println("  GET  /api/v1/projects/{id}/findings/{fp}   - A tracked finding with its triage")
+	fmt.Println("  PATCH /api/v1/projects/{id}/findings/{fp}  - Triage a finding: state, assignee and note")
+	fmt.Println("  GET  /api/v1/vulnerabilities/{id}/affected - SBOMs affected by a CVE/GHSA/OSV ID")
+	fmt.Println("  GET  /api/v1/intelligence/status           - Security intelligence corpus size and last refresh")
+	fmt.Println("  GET  /api/v1/intelligence/documents        - List manually added intelligence documents")
+	fmt.Println("  POST /api/v1/intelligence/documents        - Add an internal advisory to the corpus")
+	fmt.Println("  DELETE /api/v1/intelligence/documents/{id} - Remove a document from the corpus")
+	fmt.Println("  GET  /api/v1/watchlist                     - List watched packages")
+	fmt.Println("  POST /api/v1/watchlist                     - Watch a package by PURL for intelligence alerts")
+	fmt.Println("  DELETE /api/v1/watchlist/{id}              - Stop watching a package")
+	fmt.Println("  POST /api/v1/admin/reload                  - Reload the config file and policies (also SIGHUP)")
+	fmt.Println("  GET  /api/v1/admin/config                  - Current config file")
+	fmt.Println("  PUT  /api/v1/admin/config                  - Replace and reload the config file (sentinel-cli apply)")
+	fmt.Println("  GET  /api/v1/admin/waivers                 - Waivers of the config file")
+	fmt.Println("  POST /api/v1/admin/waivers                 - Add a waiver to the config file")
+	fmt.Println("  PUT  /api/v1/admin/waivers                 - Replace the waivers of the config file")
+	fmt.Println("  POST /api/v1/admin/api-keys/{name}/rotate  - Replace an API key of the config file with a new one")
+	fmt.Println("  /api/v2/...                                - Every endpoint above except the legacy query form, with structured findings")
+	fmt.Println("  GET  /healthz                              - Liveness probe (also /health)")
+	fmt.Println("  GET  /readyz                               - Readiness probe with dependency status")
+	fmt.Println("  GET  /metrics                              - Prometheus metrics of agent runs and LLM usage")
 
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	// Responses are compressed for clients accepting gzip, and browser
+	// frontends on allowed origins may call the API
+	server := &http.Server{Addr: ":" + port, Handler: cors.Handler(rest.Compress(http.DefaultServeMux))}
+	go func() {
+		<-ctx.Done()
+		// Requests in flight get shutdownTimeout to complete
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	<-working
+	fmt.Println("Server stopped")
+}
+
+// shutdownTimeout is how long the server waits for requests in flight when
+// it is stopped.
+const shutdownTimeout = 30 * time.Second
+
+// serveAdmissionWebhook serves the validating admission webhook on
+// /validate over TLS, as the Kubernetes API server requires, with the
+// certificate and key in ADMISSION_TLS_CERT and ADMISSION_TLS_KEY.
+func serveAdmissionWebhook(repo serverRepository, intelligence *vectordb.IntelligenceStore) error {
+	settings, err := admission.SettingsFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid admission webhook configuration: %v\n", err)
+	}
+	if policy.EvaluatorFromEnv() == nil && settings.FailOn == "" {
+		return errors.New("the admission webhook needs gate policies (POLICY_PATH) or ADMISSION_FAIL_ON to decide")
+	}
+	certFile, keyFile := os.Getenv("ADMISSION_TLS_CERT"), os.Getenv("ADMISSION_TLS_KEY")
+	if certFile == "" || keyFile == "" {
+		return errors.New("the admission webhook needs a TLS certificate and key (ADMISSION_TLS_CERT, ADMISSION_TLS_KEY)")
+	}
+
+	resolver := admission.NewResolver(repo, registry.NewClient())
+	healthChecks := []rest.HealthCheck{{Name: "database", Critical: true, Check: repo.Ping}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validate", rest.AdmissionHandler(resolver, intelligence, settings))
+	mux.HandleFunc("/healthz", rest.LivenessHandler())
+	mux.HandleFunc("/readyz", rest.ReadinessHandler(healthChecks))
+
+	port := os.Getenv("ADMISSION_PORT")
+	if port == "" {
+		port = "8443"
+	}
+	fmt.Printf("Admission webhook starting on port %s (profile %s, require SBOM: %t)\n", port, settings.Profile, settings.RequireSBOM)
+	fmt.Println("  POST /validate - Validate an AdmissionReview")
+	return http.ListenAndServeTLS(":"+port, certFile, keyFile, mux)
+}
+
+// runInit prepares a deployment: it writes the default configuration file
+// unless one exists or the configuration is given in the environment,
+// creates the database with its directory and, if seed is set, harvests the
+// intelligence corpus. A failed harvest is only a warning, as the server
+// harvests on first use anyway.
+func runInit(seed bool) error {
+	fmt.Printf("SBOM Sentinel Server %s - Initializing...\n", buildinfo.Version)
+
+	if os.Getenv(config.EnvYAML) != "" {
+		fmt.Printf("Configuration: read from $%s, no file written\n", config.EnvYAML)
+	} else {
+		path := config.Path()
+		written, err := config.WriteDefault(path)
+		if err != nil {
+			return err
+		}
+		if written {
+			fmt.Printf("Configuration written: %s\n", path)
+		} else {
+			fmt.Printf("Configuration exists, kept: %s\n", path)
+		}
+	}
+	if _, err := config.Reload(); err != nil {
+		return err
+	}
+
+	dbPath := wiring.DatabasePath("")
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	repo, err := wiring.OpenRepository(dbPath)
+	if err != nil {
+		return err
+	}
+	repo.Close()
+	fmt.Printf("Database initialized: %s\n", dbPath)
+
+	if !seed {
+		return nil
+	}
+	// The in-memory store is rebuilt by every process, so seeding it is pointless
+	if backend := vectordb.ConfigFromEnv().Backend; backend == "" || strings.EqualFold(backend, vectordb.BackendMemory) {
+		fmt.Println("Warning: Intelligence not seeded: VECTOR_DB is not a persistent vector store")
+		return nil
+	}
+	if err := analysis.NewIntelligenceStoreFromEnv().EnsureInitialized(context.Background()); err != nil {
+		fmt.Printf("Warning: Intelligence not seeded, the server harvests on first use: %v\n", err)
+	}
+	return nil
+}
+
+// reloadConfiguration reloads the configuration on SIGHUP and reports the outcome.
+func reloadConfiguration() {
+	response, err := rest.Reload()
+	if err != nil {
+		fmt.Printf("Warning: Configuration reload incomplete, previous settings kept: %v\n", err)
+	}
+	fmt.Printf("Configuration reloaded: profiles %s, %d notification channels, policy gate enabled: %t\n",
+		strings.Join(response.Profiles, ", "), response.NotificationChannels, response.PolicyGate)
 }
@@ -0,0 +1,104 @@
+// Package main provides the entry point for the SBOM Sentinel worker.
+// This binary runs the analyses queued through the API server's job queue,
+// so that analysis capacity scales separately from the API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/buildinfo"
+	"github.com/hueyexe/SBOM-Sentinel/internal/jobs"
+	"github.com/hueyexe/SBOM-Sentinel/internal/transport/rest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
+)
+
+func main() {
+	showVersion := flag.Bool("version", false, "Print the version and exit")
+	defaultWorkers, workersErr := jobs.WorkersFromEnv()
+	workers := flag.Int("workers", defaultWorkers, "Number of analyses run at a time (default $JOB_WORKERS or 2)")
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("sentinel-worker version %s\n", buildinfo.String())
+		return
+	}
+
+	fmt.Printf("SBOM Sentinel Worker %s - Starting...\n", buildinfo.Version)
+	if workersErr != nil {
+		fmt.Printf("Warning: Invalid job worker configuration: %v\n", workersErr)
+	}
+	if *workers < 1 {
+		log.Fatalf("--workers must be at least 1, got %d", *workers)
+	}
+
+	// An in-process queue would only hold this process's own jobs
+	if os.Getenv("JOB_QUEUE_URL") == "" {
+		log.Fatal("sentinel-worker needs the job queue shared with the API server (JOB_QUEUE_URL)")
+	}
+	queue, err := jobs.QueueFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid job queue configuration: %v", err)
+	}
+	defer queue.Close()
+
+	// Workers read the SBOMs and record findings in the server's database
+	dbPath := wiring.DatabasePath("")
+	repo, err := wiring.OpenRepository(dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer repo.Close()
+	fmt.Printf("Database initialized: %s\n", dbPath)
+
+	// The security intelligence corpus is shared by the worker's proactive
+	// scans and harvested on first use
+	intelligence := analysis.NewIntelligenceStoreFromEnv()
+	if err := intelligence.ConfigureRefreshFromEnv(); err != nil {
+		fmt.Printf("Warning: Invalid intelligence refresh configuration: %v\n", err)
+	}
+	intelligence.StartRefresher(context.Background())
+
+	if _, err := analysis.AgentTimeoutsFromEnv(); err != nil {
+		fmt.Printf("Warning: Invalid agent timeout configuration: %v\n", err)
+	}
+
+	// Probes and metrics for the orchestrator running the worker pods
+	healthChecks := []rest.HealthCheck{
+		{Name: "database", Critical: true, Check: repo.Ping},
+		{Name: "vector_store", Check: intelligence.Ping},
+		{Name: "ollama", Check: analysis.CheckOllama},
+	}
+	if redisQueue, ok := queue.(*jobs.RedisQueue); ok {
+		healthChecks = append(healthChecks, rest.HealthCheck{Name: "job_queue", Critical: true, Check: redisQueue.Ping})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", rest.LivenessHandler())
+	mux.HandleFunc("/readyz", rest.ReadinessHandler(healthChecks))
+	mux.HandleFunc("/metrics", rest.MetricsHandler())
+	port := os.Getenv("WORKER_PORT")
+	if port == "" {
+		port = "8081"
+	}
+	go func() {
+		if err := http.ListenAndServe(":"+port, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to serve probes: %v", err)
+		}
+	}()
+
+	// SIGINT and SIGTERM stop the worker; interrupted analyses are queued
+	// again for another worker
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Processing analysis jobs with %d workers (probes and metrics on port %s)\n", *workers, port)
+	jobs.Work(ctx, queue, *workers, rest.AnalysisJobHandler(repo, intelligence))
+	fmt.Println("Worker stopped")
+}
@@ -0,0 +1,169 @@
+// Package admission provides the lookup of the SBOM of a container image,
+// among the stored SBOMs or attached to the image in its registry.
+package admission
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/registry"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// SourceStored is the source of a resolved SBOM found among the stored SBOMs.
+const SourceStored = "stored"
+
+// maxCachedAttachments bounds how many SBOMs attached to images are cached.
+const maxCachedAttachments = 1000
+
+// digestPattern matches SHA-256 image digests in SBOM metadata.
+var digestPattern = regexp.MustCompile(`sha256:[0-9a-f]{64}`)
+
+// Registry is the part of the registry client used to find SBOMs attached
+// to images.
+type Registry interface {
+	Digest(ctx context.Context, ref registry.Reference) (string, error)
+	AttachedSBOM(ctx context.Context, ref registry.Reference) (*registry.AttachedSBOM, error)
+}
+
+// attachment is a cached SBOM attached to an image.
+type attachment struct {
+	sbom   *core.SBOM
+	source string
+}
+
+// Resolved is the SBOM found for an image.
+type Resolved struct {
+	SBOM *core.SBOM
+	// Digest is the image digest the SBOM was found for
+	Digest string
+	// Source is SourceStored, or registry.SourceReferrer or
+	// registry.SourceCosign for an SBOM attached to the image
+	Source string
+}
+
+// Resolver finds the SBOM of a container image by its digest: first among
+// the stored SBOMs whose metadata records the digest, such as the subject
+// of a CycloneDX SBOM generated from the image, then among the CycloneDX
+// SBOMs attached to the image in its registry as OCI referrers or with
+// cosign. Images
+// referenced by tag are resolved to their current digest first. It is safe
+// for concurrent use.
+type Resolver struct {
+	repo     storage.Repository
+	registry Registry
+
+	// attachments caches the SBOMs attached to images by image digest,
+	// which identifies immutable content
+	mu          sync.Mutex
+	attachments map[string]attachment
+}
+
+// NewResolver creates a resolver searching repo and, if reg is not nil,
+// image registries.
+func NewResolver(repo storage.Repository, reg Registry) *Resolver {
+	return &Resolver{repo: repo, registry: reg, attachments: make(map[string]attachment)}
+}
+
+// Resolve returns the SBOM of image, or nil if none is found.
+func (r *Resolver) Resolve(ctx context.Context, image string) (*Resolved, error) {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Digest == "" {
+		if r.registry == nil {
+			return nil, fmt.Errorf("image %s is not referenced by digest", image)
+		}
+		if ref.Digest, err = r.registry.Digest(ctx, ref); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s to a digest: %w", image, err)
+		}
+	}
+
+	sbom, err := r.stored(ctx, ref.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if sbom != nil {
+		return &Resolved{SBOM: sbom, Digest: ref.Digest, Source: SourceStored}, nil
+	}
+
+	if r.registry == nil {
+		return nil, nil
+	}
+	attached, err := r.attached(ctx, ref)
+	if err != nil || attached.sbom == nil {
+		return nil, err
+	}
+	return &Resolved{SBOM: attached.sbom, Digest: ref.Digest, Source: attached.source}, nil
+}
+
+// stored returns the most recently stored SBOM of the image with digest.
+func (r *Resolver) stored(ctx context.Context, digest string) (*core.SBOM, error) {
+	sboms, err := r.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search stored SBOMs: %w", err)
+	}
+	for i := len(sboms) - 1; i >= 0; i-- {
+		for _, recorded := range ImageDigests(sboms[i]) {
+			if recorded == digest {
+				return &sboms[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// attached downloads the SBOM attached to the referenced image in its
+// registry. Found SBOMs are cached; the absence of one is not, as an SBOM
+// may be attached later.
+func (r *Resolver) attached(ctx context.Context, ref registry.Reference) (attachment, error) {
+	r.mu.Lock()
+	cached, ok := r.attachments[ref.Digest]
+	r.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	attached, err := r.registry.AttachedSBOM(ctx, ref)
+	if err != nil {
+		return attachment{}, fmt.Errorf("failed to fetch the SBOM attached to %s: %w", ref, err)
+	}
+	if attached == nil {
+		return attachment{}, nil
+	}
+	sbom, err := ingestion.NewCycloneDXParser().Parse(bytes.NewReader(attached.Data))
+	if err != nil {
+		return attachment{}, fmt.Errorf("failed to parse the SBOM attached to %s: %w", ref, err)
+	}
+	ingestion.NewNormalizer().Normalize(sbom)
+	result := attachment{sbom: sbom, source: attached.Source}
+
+	r.mu.Lock()
+	if len(r.attachments) >= maxCachedAttachments {
+		clear(r.attachments)
+	}
+	r.attachments[ref.Digest] = result
+	r.mu.Unlock()
+	return result, nil
+}
+
+// ImageDigests returns the image digests recorded in an SBOM's metadata:
+// the digest in the package URL of its subject, and any SHA-256 digest in
+// other metadata, such as the version of a syft image SBOM or Trivy's
+// RepoDigests property.
+func ImageDigests(sbom core.SBOM) []string {
+	var digests []string
+	if ref, ok := registry.ReferenceFromPURL(sbom.Metadata["componentPurl"]); ok && ref.Digest != "" {
+		digests = append(digests, ref.Digest)
+	}
+	for _, value := range sbom.Metadata {
+		digests = append(digests, digestPattern.FindAllString(value, -1)...)
+	}
+	return digests
+}
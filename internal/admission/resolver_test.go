@@ -0,0 +1,135 @@
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/registry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	appDigest   = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	otherDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// fakeRepository serves a fixed list of SBOMs.
+type fakeRepository struct {
+	sboms []core.SBOM
+}
+
+func (f *fakeRepository) Store(ctx context.Context, sbom core.SBOM) error { return nil }
+
+func (f *fakeRepository) FindByID(ctx context.Context, id string) (*core.SBOM, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) FindAll(ctx context.Context) ([]core.SBOM, error) {
+	return f.sboms, nil
+}
+
+// fakeRegistry resolves tags to digests and serves SBOMs attached to digests
+// with cosign.
+type fakeRegistry struct {
+	digests  map[string]string
+	attached map[string][]byte
+	fetches  int
+}
+
+func (f *fakeRegistry) Digest(ctx context.Context, ref registry.Reference) (string, error) {
+	digest, ok := f.digests[ref.String()]
+	if !ok {
+		return "", errors.New("404 Not Found")
+	}
+	return digest, nil
+}
+
+func (f *fakeRegistry) AttachedSBOM(ctx context.Context, ref registry.Reference) (*registry.AttachedSBOM, error) {
+	data, ok := f.attached[ref.Digest]
+	if !ok {
+		return nil, nil
+	}
+	f.fetches++
+	return &registry.AttachedSBOM{Digest: ref.Digest, Source: registry.SourceCosign, Data: data}, nil
+}
+
+func TestResolver_Stored(t *testing.T) {
+	repo := &fakeRepository{sboms: []core.SBOM{
+		{ID: "old", Metadata: map[string]string{"componentVersion": appDigest}},
+		{ID: "other", Metadata: map[string]string{"componentVersion": otherDigest}},
+		{ID: "new", Metadata: map[string]string{"componentPurl": "pkg:oci/app@sha256%3A1111111111111111111111111111111111111111111111111111111111111111?repository_url=ghcr.io/acme/app"}},
+	}}
+	resolver := NewResolver(repo, &fakeRegistry{digests: map[string]string{"ghcr.io/acme/app:1.0": appDigest}})
+
+	// The most recently stored SBOM of the digest is used
+	resolved, err := resolver.Resolve(context.Background(), "ghcr.io/acme/app@"+appDigest)
+	require.NoError(t, err)
+	require.NotNil(t, resolved)
+	assert.Equal(t, "new", resolved.SBOM.ID)
+	assert.Equal(t, SourceStored, resolved.Source)
+
+	// A tag is resolved to its digest first
+	resolved, err = resolver.Resolve(context.Background(), "ghcr.io/acme/app:1.0")
+	require.NoError(t, err)
+	require.NotNil(t, resolved)
+	assert.Equal(t, appDigest, resolved.Digest)
+
+	_, err = resolver.Resolve(context.Background(), "ghcr.io/acme/app:2.0")
+	assert.ErrorContains(t, err, "failed to resolve ghcr.io/acme/app:2.0 to a digest")
+
+	// Without a registry, only digest references can be resolved
+	resolved, err = NewResolver(repo, nil).Resolve(context.Background(), "ghcr.io/acme/unknown@"+otherDigest)
+	require.NoError(t, err)
+	assert.Equal(t, "other", resolved.SBOM.ID)
+	_, err = NewResolver(repo, nil).Resolve(context.Background(), "ghcr.io/acme/app:1.0")
+	assert.ErrorContains(t, err, "not referenced by digest")
+}
+
+func TestResolver_Attached(t *testing.T) {
+	reg := &fakeRegistry{attached: map[string][]byte{
+		appDigest: []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","metadata":{"component":{"type":"container","name":"acme/app"}},"components":[{"name":"lodash","version":"4.17.20"}]}`),
+	}}
+	resolver := NewResolver(&fakeRepository{}, reg)
+
+	resolved, err := resolver.Resolve(context.Background(), "ghcr.io/acme/app@"+appDigest)
+	require.NoError(t, err)
+	require.NotNil(t, resolved)
+	assert.Equal(t, registry.SourceCosign, resolved.Source)
+	assert.Equal(t, "acme/app", resolved.SBOM.Name)
+	require.Len(t, resolved.SBOM.Components, 1)
+
+	// The SBOM of a digest is downloaded once
+	_, err = resolver.Resolve(context.Background(), "ghcr.io/acme/app@"+appDigest)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reg.fetches)
+
+	resolved, err = resolver.Resolve(context.Background(), "ghcr.io/acme/app@"+otherDigest)
+	require.NoError(t, err)
+	assert.Nil(t, resolved)
+}
+
+func TestSettingsFromEnv(t *testing.T) {
+	t.Setenv("ADMISSION_PROFILE", "")
+	t.Setenv("ADMISSION_REQUIRE_SBOM", "")
+	t.Setenv("ADMISSION_FAIL_ON", "")
+	settings, err := SettingsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Settings{Profile: DefaultProfile}, settings)
+
+	t.Setenv("ADMISSION_PROFILE", "full")
+	t.Setenv("ADMISSION_REQUIRE_SBOM", "true")
+	t.Setenv("ADMISSION_FAIL_ON", "high")
+	settings, err = SettingsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Settings{Profile: "full", RequireSBOM: true, FailOn: "high"}, settings)
+
+	t.Setenv("ADMISSION_REQUIRE_SBOM", "always")
+	t.Setenv("ADMISSION_FAIL_ON", "severe")
+	_, err = SettingsFromEnv()
+	assert.ErrorContains(t, err, "ADMISSION_REQUIRE_SBOM must be true or false")
+	assert.ErrorContains(t, err, `ADMISSION_FAIL_ON: unknown severity "severe"`)
+}
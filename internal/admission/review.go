@@ -0,0 +1,114 @@
+// Package admission provides the Kubernetes AdmissionReview types of the
+// validating admission webhook, which checks the SBOMs of the container
+// images in admitted workloads, and the extraction of those images.
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Identifiers of the admission.k8s.io/v1 AdmissionReview.
+const (
+	APIVersion = "admission.k8s.io/v1"
+	Kind       = "AdmissionReview"
+)
+
+// Review is an AdmissionReview, carrying a request from the API server or
+// the webhook's response to it.
+type Review struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Request    *Request  `json:"request,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// Request describes the object being admitted.
+type Request struct {
+	UID       string           `json:"uid"`
+	Kind      GroupVersionKind `json:"kind"`
+	Namespace string           `json:"namespace,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Operation string           `json:"operation,omitempty"`
+	Object    json.RawMessage  `json:"object,omitempty"`
+}
+
+// GroupVersionKind identifies the type of the admitted object.
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// Response is the webhook's decision on a request.
+type Response struct {
+	UID     string  `json:"uid"`
+	Allowed bool    `json:"allowed"`
+	Status  *Status `json:"status,omitempty"`
+	// Warnings are shown to the client, e.g. by kubectl, whether or not the
+	// request is allowed
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Status explains a denied request.
+type Status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Container is a container of an admitted workload.
+type Container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// podSpec holds the containers of a pod.
+type podSpec struct {
+	Containers          []Container `json:"containers"`
+	InitContainers      []Container `json:"initContainers"`
+	EphemeralContainers []Container `json:"ephemeralContainers"`
+}
+
+// podTemplate is the pod template of a workload.
+type podTemplate struct {
+	Spec podSpec `json:"spec"`
+}
+
+// Containers returns the init, regular and ephemeral containers of an
+// admitted object: a Pod, a workload with a pod template such as a
+// Deployment, StatefulSet, DaemonSet, ReplicaSet or Job, or a CronJob.
+// Other objects have no containers.
+func Containers(object json.RawMessage) ([]Container, error) {
+	if len(object) == 0 {
+		return nil, nil
+	}
+
+	var parsed struct {
+		Spec struct {
+			podSpec
+			Template    *podTemplate `json:"template"`
+			JobTemplate *struct {
+				Spec struct {
+					Template *podTemplate `json:"template"`
+				} `json:"spec"`
+			} `json:"jobTemplate"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(object, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode admitted object: %w", err)
+	}
+
+	spec := parsed.Spec.podSpec
+	switch {
+	case parsed.Spec.Template != nil:
+		spec = parsed.Spec.Template.Spec
+	case parsed.Spec.JobTemplate != nil && parsed.Spec.JobTemplate.Spec.Template != nil:
+		spec = parsed.Spec.JobTemplate.Spec.Template.Spec
+	}
+
+	var containers []Container
+	containers = append(containers, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	containers = append(containers, spec.EphemeralContainers...)
+	return containers, nil
+}
@@ -0,0 +1,48 @@
+package admission
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainers(t *testing.T) {
+	tests := []struct {
+		name   string
+		object string
+		want   []Container
+	}{
+		{
+			name:   "Pod",
+			object: `{"kind":"Pod","spec":{"initContainers":[{"name":"migrate","image":"acme/migrate:1"}],"containers":[{"name":"app","image":"acme/app:1"}]}}`,
+			want:   []Container{{Name: "migrate", Image: "acme/migrate:1"}, {Name: "app", Image: "acme/app:1"}},
+		},
+		{
+			name:   "Deployment",
+			object: `{"kind":"Deployment","spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"app","image":"acme/app:1"}]}}}}`,
+			want:   []Container{{Name: "app", Image: "acme/app:1"}},
+		},
+		{
+			name:   "CronJob",
+			object: `{"kind":"CronJob","spec":{"schedule":"@daily","jobTemplate":{"spec":{"template":{"spec":{"containers":[{"name":"report","image":"acme/report:1"}]}}}}}}`,
+			want:   []Container{{Name: "report", Image: "acme/report:1"}},
+		},
+		{
+			name:   "ConfigMap",
+			object: `{"kind":"ConfigMap","data":{"key":"value"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers, err := Containers(json.RawMessage(tt.object))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, containers)
+		})
+	}
+
+	_, err := Containers(json.RawMessage(`{"spec":[]}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+// Package admission provides the settings of the admission webhook.
+package admission
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// DefaultProfile is the analysis profile run on admitted images unless
+// ADMISSION_PROFILE is set.
+const DefaultProfile = "quick"
+
+// Settings control how the admission webhook decides.
+type Settings struct {
+	// Profile is the analysis profile run on the SBOM of every image
+	Profile string
+	// RequireSBOM denies images whose SBOM cannot be found; otherwise they
+	// are admitted with a warning
+	RequireSBOM bool
+	// FailOn denies images with findings at or above this severity; empty
+	// leaves the decision to the gate policies alone
+	FailOn string
+}
+
+// SettingsFromEnv reads the webhook settings from ADMISSION_PROFILE,
+// ADMISSION_REQUIRE_SBOM and ADMISSION_FAIL_ON. Invalid values are reported
+// and replaced by their defaults.
+func SettingsFromEnv() (Settings, error) {
+	settings := Settings{Profile: DefaultProfile}
+	var errs []error
+
+	if profile := strings.TrimSpace(os.Getenv("ADMISSION_PROFILE")); profile != "" {
+		settings.Profile = profile
+	}
+	if value := os.Getenv("ADMISSION_REQUIRE_SBOM"); value != "" {
+		required, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ADMISSION_REQUIRE_SBOM must be true or false, got %q", value))
+		}
+		settings.RequireSBOM = required
+	}
+	if value := strings.TrimSpace(os.Getenv("ADMISSION_FAIL_ON")); value != "" {
+		if err := core.ValidateSeverityThreshold(value); err != nil {
+			errs = append(errs, fmt.Errorf("ADMISSION_FAIL_ON: %w", err))
+		} else {
+			settings.FailOn = value
+		}
+	}
+	return settings, errors.Join(errs...)
+}
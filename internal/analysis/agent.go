@@ -23,3 +23,56 @@ type AnalysisAgent interface {
 	// if the analysis cannot be completed.
 	Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error)
 }
+
+// ParameterizedAgent is implemented by agents whose findings depend on tunable
+// settings. The effective values are recorded with the analysis so that its
+// results can be reproduced.
+type ParameterizedAgent interface {
+	AnalysisAgent
+
+	// Parameters returns the effective settings of the agent by name.
+	Parameters() map[string]string
+}
+
+// ComponentAgent is implemented by agents that analyze each component of an
+// SBOM on its own, such as by querying an API or an LLM per component. A bulk
+// analysis can then analyze a component shared by many SBOMs once and copy
+// its findings to the others; see Orchestrator.Deduplicate.
+type ComponentAgent interface {
+	AnalysisAgent
+
+	// ComponentKeys returns a key for each component of sbom identifying how
+	// the agent analyzes it: components with equal keys, in any SBOM, have
+	// the same findings. Components the agent skips have an empty key.
+	ComponentKeys(sbom core.SBOM) []string
+
+	// AnalyzeComponents analyzes the given components of sbom, calling found
+	// with the index and findings of each component analyzed. Components that
+	// cannot be analyzed are logged and skipped; an error means the agent as a
+	// whole failed.
+	AnalyzeComponents(ctx context.Context, sbom core.SBOM, components []core.Component, found func(i int, results []core.AnalysisResult)) error
+}
+
+// ModelAgent is implemented by agents that prompt Ollama models. The
+// Orchestrator makes sure their models are available before running them;
+// see Orchestrator.PrepareModels.
+type ModelAgent interface {
+	AnalysisAgent
+
+	// OllamaModels returns the Ollama server the agent prompts and the
+	// models it uses there.
+	OllamaModels() (host string, models []OllamaModel)
+}
+
+// analyzeEachComponent runs a component agent across every component of
+// sbom, as its Analyze method.
+func analyzeEachComponent(ctx context.Context, agent ComponentAgent, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	var results []core.AnalysisResult
+	err := agent.AnalyzeComponents(ctx, sbom, sbom.Components, func(_ int, found []core.AnalysisResult) {
+		results = append(results, found...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,355 @@
+// Package analysis provides base-image staleness checks for SBOMs generated
+// from container images.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/cache"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/registry"
+)
+
+// ImageRegistry looks up the tags of an image repository and the digest a
+// tag currently points to.
+type ImageRegistry interface {
+	Tags(ctx context.Context, ref registry.Reference) ([]string, error)
+	Digest(ctx context.Context, ref registry.Reference) (string, error)
+}
+
+// cachedRegistry is an ImageRegistry keeping the lookups of another in a
+// cache, so that images sharing a base image query its registry once.
+// Failed lookups are not cached.
+type cachedRegistry struct {
+	registry ImageRegistry
+	cache    *cache.Cache
+}
+
+// Tags returns the cached tags of ref's repository or looks them up.
+func (c cachedRegistry) Tags(ctx context.Context, ref registry.Reference) ([]string, error) {
+	key := "tags " + ref.Name()
+	var tags []string
+	if c.cache.GetJSON(ctx, cache.SourceRegistry, key, &tags) {
+		return tags, nil
+	}
+	tags, err := c.registry.Tags(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.SetJSON(ctx, cache.SourceRegistry, key, tags)
+	return tags, nil
+}
+
+// Digest returns the cached digest of ref or looks it up.
+func (c cachedRegistry) Digest(ctx context.Context, ref registry.Reference) (string, error) {
+	key := "digest " + ref.String()
+	if digest, ok := c.cache.Get(ctx, cache.SourceRegistry, key); ok {
+		return string(digest), nil
+	}
+	digest, err := c.registry.Digest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	c.cache.Set(ctx, cache.SourceRegistry, key, []byte(digest))
+	return digest, nil
+}
+
+// VulnerableImage declares base images known to be vulnerable. A reference
+// with a tag or digest matches only that tag or digest; a reference without
+// either matches every image of the repository.
+type VulnerableImage struct {
+	Reference string `yaml:"reference"`
+	Reason    string `yaml:"reason"`
+}
+
+// Validate checks that the reference is a valid image reference.
+func (v *VulnerableImage) Validate() error {
+	if strings.TrimSpace(v.Reference) == "" {
+		return errors.New("reference is required")
+	}
+	_, err := registry.ParseReference(v.Reference)
+	return err
+}
+
+// matches reports whether ref is covered by the declaration.
+func (v VulnerableImage) matches(ref registry.Reference) bool {
+	declared, err := registry.ParseReference(v.Reference)
+	if err != nil || declared.Registry != ref.Registry || declared.Repository != ref.Repository {
+		return false
+	}
+	if declared.Digest != "" {
+		return declared.Digest == ref.Digest
+	}
+	return declared.Tag == "" || declared.Tag == ref.Tag
+}
+
+// imageRelease is a release line of an official image and the date its
+// security support ended. Tags match the line itself, or the line followed
+// by "." or "-", e.g. "3.16.2" and "3.16-slim" match line "3.16".
+type imageRelease struct {
+	line string
+	eol  string
+}
+
+// endOfLifeImages lists release lines of official Docker Hub images whose
+// security support has ended or ends on a known date.
+var endOfLifeImages = map[string][]imageRelease{
+	"alpine": {
+		{"3.12", "2022-05-01"}, {"3.13", "2022-11-01"}, {"3.14", "2023-05-01"}, {"3.15", "2023-11-01"},
+		{"3.16", "2024-05-23"}, {"3.17", "2024-11-22"}, {"3.18", "2025-05-09"}, {"3.19", "2025-11-01"},
+		{"3.20", "2026-04-01"}, {"3.21", "2026-11-01"},
+	},
+	"debian": {
+		{"9", "2022-06-30"}, {"stretch", "2022-06-30"},
+		{"10", "2024-06-30"}, {"buster", "2024-06-30"},
+		{"11", "2026-08-31"}, {"bullseye", "2026-08-31"},
+	},
+	"ubuntu": {
+		{"16.04", "2021-04-30"}, {"xenial", "2021-04-30"},
+		{"18.04", "2023-05-31"}, {"bionic", "2023-05-31"},
+		{"20.04", "2025-05-31"}, {"focal", "2025-05-31"},
+	},
+	"centos": {
+		{"7", "2024-06-30"}, {"centos7", "2024-06-30"},
+		{"8", "2021-12-31"}, {"centos8", "2021-12-31"},
+	},
+	"node": {
+		{"12", "2022-04-30"}, {"14", "2023-04-30"}, {"16", "2023-09-11"}, {"18", "2025-04-30"}, {"20", "2026-04-30"},
+	},
+	"python": {
+		{"3.6", "2021-12-23"}, {"3.7", "2023-06-27"}, {"3.8", "2024-10-07"}, {"3.9", "2025-10-31"},
+	},
+}
+
+// operatingSystemImages maps operating-system component names to the
+// official image of the distribution.
+var operatingSystemImages = map[string]string{
+	"alpine":    "alpine",
+	"debian":    "debian",
+	"ubuntu":    "ubuntu",
+	"centos":    "centos",
+	"fedora":    "fedora",
+	"rocky":     "rockylinux",
+	"almalinux": "almalinux",
+	"amzn":      "amazonlinux",
+}
+
+// Metadata annotations declaring the base image an image was built from.
+const (
+	baseNameAnnotation   = "org.opencontainers.image.base.name"
+	baseDigestAnnotation = "org.opencontainers.image.base.digest"
+)
+
+// BaseImage is the base image a container image was built from, and how it
+// was identified.
+type BaseImage struct {
+	Reference registry.Reference
+	Source    string
+}
+
+// ContainerBaseImage identifies the base image of an SBOM generated from a
+// container image. The OCI base image annotations are preferred, then the
+// image itself if it is an official Docker Hub image, then the official image
+// of its operating system. It reports false for SBOMs of other subjects or
+// if no base image is identified.
+func ContainerBaseImage(sbom core.SBOM) (BaseImage, bool) {
+	if sbom.Metadata["componentType"] != "container" {
+		return BaseImage{}, false
+	}
+
+	if name := sbom.Metadata[baseNameAnnotation]; name != "" {
+		if ref, err := registry.ParseReference(name); err == nil {
+			if ref.Digest == "" {
+				ref.Digest = sbom.Metadata[baseDigestAnnotation]
+			}
+			return BaseImage{Reference: ref, Source: "base image annotation"}, true
+		}
+	}
+
+	if ref, ok := subjectImage(sbom); ok && ref.Registry == registry.DockerHub && strings.HasPrefix(ref.Repository, "library/") && ref.Tag != "" {
+		return BaseImage{Reference: ref, Source: "official image"}, true
+	}
+
+	for _, component := range sbom.Components {
+		if component.Type != "operating-system" || component.Version == "" {
+			continue
+		}
+		image, ok := operatingSystemImages[strings.ToLower(component.Name)]
+		if !ok {
+			continue
+		}
+		if ref, err := registry.ParseReference(image + ":" + component.Version); err == nil {
+			return BaseImage{Reference: ref, Source: "operating system"}, true
+		}
+	}
+
+	return BaseImage{}, false
+}
+
+// subjectImage returns the reference of the image an SBOM describes, from
+// its OCI package URL or from its name and version.
+func subjectImage(sbom core.SBOM) (registry.Reference, bool) {
+	if ref, ok := registry.ReferenceFromPURL(sbom.Metadata["componentPurl"]); ok {
+		return ref, true
+	}
+
+	ref, err := registry.ParseReference(sbom.Name)
+	if err != nil {
+		return registry.Reference{}, false
+	}
+	if version := sbom.Metadata["componentVersion"]; strings.HasPrefix(version, "sha256:") {
+		ref.Digest = version
+	} else if version != "" && ref.Tag == "" {
+		ref.Tag = version
+	}
+	return ref, true
+}
+
+// patchTag matches tags with a major.minor.patch version and an optional
+// variant suffix, e.g. "3.19.1" or "20.11.0-alpine3.19".
+var patchTag = regexp.MustCompile(`^(v?\d+\.\d+)\.(\d+)(.*)$`)
+
+// newerPatchTag returns the tag of the latest patch release newer than tag
+// in the same major.minor line and variant, or false if tag is the latest
+// or not a major.minor.patch tag.
+func newerPatchTag(tag string, tags []string) (string, bool) {
+	current := patchTag.FindStringSubmatch(tag)
+	if current == nil {
+		return "", false
+	}
+	latest, _ := strconv.Atoi(current[2])
+
+	newest := ""
+	for _, candidate := range tags {
+		match := patchTag.FindStringSubmatch(candidate)
+		if match == nil || match[1] != current[1] || match[3] != current[3] {
+			continue
+		}
+		if patch, err := strconv.Atoi(match[2]); err == nil && patch > latest {
+			latest, newest = patch, candidate
+		}
+	}
+	return newest, newest != ""
+}
+
+// endOfLife returns the end of security support of the release line the
+// tag of an official image belongs to.
+func endOfLife(ref registry.Reference) (imageRelease, bool) {
+	if ref.Registry != registry.DockerHub || ref.Tag == "" {
+		return imageRelease{}, false
+	}
+	for _, release := range endOfLifeImages[strings.TrimPrefix(ref.Repository, "library/")] {
+		rest, ok := strings.CutPrefix(ref.Tag, release.line)
+		if ok && (rest == "" || rest[0] == '.' || rest[0] == '-') {
+			return release, true
+		}
+	}
+	return imageRelease{}, false
+}
+
+// BaseImageAgent checks the base image of container images for end-of-life
+// releases, known vulnerable images, newer patch releases and tags that have
+// been republished since the image was built.
+type BaseImageAgent struct {
+	registry   ImageRegistry
+	vulnerable []VulnerableImage
+	now        func() time.Time
+}
+
+// NewBaseImageAgent creates a BaseImageAgent that queries the image's
+// registry and reports the given vulnerable images. Registry responses are
+// kept in the shared cache configured by the CACHE_* variables.
+func NewBaseImageAgent(vulnerable ...VulnerableImage) *BaseImageAgent {
+	var imageRegistry ImageRegistry = registry.NewClient()
+	if shared := cache.Shared(); shared != nil {
+		imageRegistry = cachedRegistry{registry: imageRegistry, cache: shared}
+	}
+	return NewBaseImageAgentWithRegistry(imageRegistry, vulnerable...)
+}
+
+// NewBaseImageAgentWithRegistry creates a BaseImageAgent that looks up tags
+// and digests in the given registry. A nil registry limits the agent to the
+// checks that need no registry access.
+func NewBaseImageAgentWithRegistry(imageRegistry ImageRegistry, vulnerable ...VulnerableImage) *BaseImageAgent {
+	return &BaseImageAgent{registry: imageRegistry, vulnerable: vulnerable, now: time.Now}
+}
+
+// Name returns the identifier for this analysis agent.
+func (ba *BaseImageAgent) Name() string {
+	return "Base Image Agent"
+}
+
+// Analyze checks the base image of an SBOM generated from a container image.
+// End-of-life and known vulnerable base images are rated High; newer patch
+// releases and republished tags are rated Medium. SBOMs of other subjects
+// have no findings. Registry failures are reported as warnings and skip the
+// checks that need the registry.
+func (ba *BaseImageAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	base, ok := ContainerBaseImage(sbom)
+	if !ok {
+		return nil, nil
+	}
+
+	ref := base.Reference
+	component := &core.ComponentRef{Name: ref.Name(), Version: ref.Tag}
+	var results []core.AnalysisResult
+	report := func(severity, finding string) {
+		results = append(results, core.AnalysisResult{
+			AgentName: ba.Name(),
+			Finding:   fmt.Sprintf("Base image '%s' (%s): %s", ref, base.Source, finding),
+			Severity:  severity,
+			Component: component,
+		})
+	}
+
+	for _, vulnerable := range ba.vulnerable {
+		if vulnerable.matches(ref) {
+			reason := vulnerable.Reason
+			if reason == "" {
+				reason = "declared vulnerable"
+			}
+			report(core.SeverityHigh, fmt.Sprintf("known vulnerable base image: %s. Rebuild on a fixed base image.", reason))
+			break
+		}
+	}
+
+	if release, ok := endOfLife(ref); ok {
+		if eol, err := time.Parse("2006-01-02", release.eol); err == nil && !ba.now().Before(eol) {
+			report(core.SeverityHigh, fmt.Sprintf("release %s reached end of life on %s and no longer receives security fixes. Move to a supported release.", release.line, release.eol))
+		}
+	}
+
+	if ba.registry == nil || ref.Tag == "" {
+		return results, nil
+	}
+
+	tags, err := ba.registry.Tags(ctx, ref)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		fmt.Printf("Warning: Failed to list tags of base image %s: %v\n", ref.Name(), err)
+	} else if newer, ok := newerPatchTag(ref.Tag, tags); ok {
+		report(core.SeverityMedium, fmt.Sprintf("newer patch release '%s' is available. Rebuild on %s:%s.", newer, ref.Name(), newer))
+	}
+
+	if ref.Digest != "" {
+		digest, err := ba.registry.Digest(ctx, ref)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			fmt.Printf("Warning: Failed to resolve base image %s: %v\n", ref.Name(), err)
+		} else if digest != ref.Digest {
+			report(core.SeverityMedium, fmt.Sprintf("tag '%s' has been republished since the image was built (now %s). Rebuild to pick up the updated base image.", ref.Tag, digest))
+		}
+	}
+
+	return results, nil
+}
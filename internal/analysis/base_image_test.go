@@ -0,0 +1,206 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/cache"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeImageRegistry serves tags and digests by repository and tag.
+type fakeImageRegistry struct {
+	tags    map[string][]string
+	digests map[string]string
+}
+
+func (f *fakeImageRegistry) Tags(ctx context.Context, ref registry.Reference) ([]string, error) {
+	tags, ok := f.tags[ref.Repository]
+	if !ok {
+		return nil, errors.New("repository not found")
+	}
+	return tags, nil
+}
+
+func (f *fakeImageRegistry) Digest(ctx context.Context, ref registry.Reference) (string, error) {
+	digest, ok := f.digests[ref.Repository+":"+ref.Tag]
+	if !ok {
+		return "", errors.New("manifest not found")
+	}
+	return digest, nil
+}
+
+func containerSBOM(metadata map[string]string, components ...core.Component) core.SBOM {
+	metadata["componentType"] = "container"
+	return core.SBOM{Name: "ghcr.io/acme/payments-api:1.4.0", Components: components, Metadata: metadata}
+}
+
+func TestContainerBaseImage(t *testing.T) {
+	// Annotations take precedence over the operating system
+	base, ok := ContainerBaseImage(containerSBOM(map[string]string{
+		"org.opencontainers.image.base.name":   "docker.io/library/alpine:3.19.1",
+		"org.opencontainers.image.base.digest": "sha256:aaa",
+	}, core.Component{Name: "debian", Version: "12.5", Type: "operating-system"}))
+	require.True(t, ok)
+	assert.Equal(t, "alpine:3.19.1@sha256:aaa", base.Reference.String())
+	assert.Equal(t, "base image annotation", base.Source)
+
+	// Official images are their own base
+	base, ok = ContainerBaseImage(core.SBOM{Name: "python", Metadata: map[string]string{
+		"componentType":    "container",
+		"componentVersion": "3.11.4-slim",
+	}})
+	require.True(t, ok)
+	assert.Equal(t, "python:3.11.4-slim", base.Reference.String())
+	assert.Equal(t, "official image", base.Source)
+
+	base, ok = ContainerBaseImage(containerSBOM(map[string]string{}, core.Component{Name: "debian", Version: "12.5", Type: "operating-system"}))
+	require.True(t, ok)
+	assert.Equal(t, "debian:12.5", base.Reference.String())
+	assert.Equal(t, "operating system", base.Source)
+
+	_, ok = ContainerBaseImage(core.SBOM{Name: "app", Metadata: map[string]string{"componentType": "application"}})
+	assert.False(t, ok)
+	_, ok = ContainerBaseImage(containerSBOM(map[string]string{}, core.Component{Name: "lodash", Version: "4.17.21"}))
+	assert.False(t, ok)
+}
+
+func TestNewerPatchTag(t *testing.T) {
+	tags := []string{"3.19", "3.19.0", "3.19.1", "3.19.4", "3.19.2", "3.20.0", "3.19.9-rc1", "20.11.0-alpine3.19", "20.11.1-alpine3.19", "20.11.3-slim"}
+
+	newer, ok := newerPatchTag("3.19.1", tags)
+	assert.True(t, ok)
+	assert.Equal(t, "3.19.4", newer)
+
+	newer, ok = newerPatchTag("20.11.0-alpine3.19", tags)
+	assert.True(t, ok)
+	assert.Equal(t, "20.11.1-alpine3.19", newer)
+
+	_, ok = newerPatchTag("3.19.4", tags)
+	assert.False(t, ok)
+	_, ok = newerPatchTag("3.19", tags)
+	assert.False(t, ok)
+}
+
+func TestBaseImageAgent_Analyze(t *testing.T) {
+	fake := &fakeImageRegistry{
+		tags:    map[string][]string{"library/alpine": {"3.18.4", "3.18.6", "3.19.1"}},
+		digests: map[string]string{"library/alpine:3.18.4": "sha256:new"},
+	}
+	agent := NewBaseImageAgentWithRegistry(fake, VulnerableImage{Reference: "alpine:3.18.4", Reason: "CVE-2023-5363 in its OpenSSL"})
+	agent.now = func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+	assert.Equal(t, "Base Image Agent", agent.Name())
+
+	sbom := containerSBOM(map[string]string{
+		"org.opencontainers.image.base.name":   "alpine:3.18.4",
+		"org.opencontainers.image.base.digest": "sha256:old",
+	})
+
+	results, err := agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, core.SeverityHigh, results[0].Severity)
+	assert.Equal(t, "Base image 'alpine:3.18.4@sha256:old' (base image annotation): known vulnerable base image: CVE-2023-5363 in its OpenSSL. Rebuild on a fixed base image.", results[0].Finding)
+	assert.Equal(t, &core.ComponentRef{Name: "alpine", Version: "3.18.4"}, results[0].Component)
+
+	assert.Equal(t, core.SeverityHigh, results[1].Severity)
+	assert.Contains(t, results[1].Finding, "release 3.18 reached end of life on 2025-05-09")
+
+	assert.Equal(t, core.SeverityMedium, results[2].Severity)
+	assert.Contains(t, results[2].Finding, "newer patch release '3.18.6' is available")
+
+	assert.Equal(t, core.SeverityMedium, results[3].Severity)
+	assert.Contains(t, results[3].Finding, "tag '3.18.4' has been republished since the image was built (now sha256:new)")
+
+	// A supported, current base image has no findings
+	agent.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+	fake.digests["library/alpine:3.18.6"] = "sha256:current"
+	results, err = agent.Analyze(context.Background(), containerSBOM(map[string]string{
+		"org.opencontainers.image.base.name": "alpine:3.18.6@sha256:current",
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Registry failures leave the offline checks
+	results, err = agent.Analyze(context.Background(), containerSBOM(map[string]string{
+		"org.opencontainers.image.base.name": "ghcr.io/acme/base:1.0.0",
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// SBOMs of other subjects are not checked
+	results, err = agent.Analyze(context.Background(), core.SBOM{Name: "alpine:3.18.4", Metadata: map[string]string{"componentType": "application"}})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestVulnerableImage_Validate(t *testing.T) {
+	assert.NoError(t, (&VulnerableImage{Reference: "node"}).Validate())
+	assert.ErrorContains(t, (&VulnerableImage{}).Validate(), "reference is required")
+	assert.Error(t, (&VulnerableImage{Reference: "Node:18"}).Validate())
+}
+
+func TestVulnerableImage_Matches(t *testing.T) {
+	ref, err := registry.ParseReference("docker.io/library/node:18.17.0@sha256:abc")
+	require.NoError(t, err)
+
+	assert.True(t, VulnerableImage{Reference: "node"}.matches(ref))
+	assert.True(t, VulnerableImage{Reference: "node:18.17.0"}.matches(ref))
+	assert.True(t, VulnerableImage{Reference: "node@sha256:abc"}.matches(ref))
+	assert.False(t, VulnerableImage{Reference: "node:18.18.0"}.matches(ref))
+	assert.False(t, VulnerableImage{Reference: "ghcr.io/acme/node"}.matches(ref))
+}
+
+// countingImageRegistry counts the lookups made of a registry.
+type countingImageRegistry struct {
+	ImageRegistry
+	lookups int
+}
+
+func (c *countingImageRegistry) Tags(ctx context.Context, ref registry.Reference) ([]string, error) {
+	c.lookups++
+	return c.ImageRegistry.Tags(ctx, ref)
+}
+
+func (c *countingImageRegistry) Digest(ctx context.Context, ref registry.Reference) (string, error) {
+	c.lookups++
+	return c.ImageRegistry.Digest(ctx, ref)
+}
+
+func TestCachedRegistry(t *testing.T) {
+	responses, err := cache.New(cache.Config{MaxEntries: 10, DefaultTTL: time.Hour})
+	require.NoError(t, err)
+	counting := &countingImageRegistry{ImageRegistry: &fakeImageRegistry{
+		tags:    map[string][]string{"library/alpine": {"3.19.0", "3.19.1"}},
+		digests: map[string]string{"library/alpine:3.19.1": "sha256:aaaa"},
+	}}
+	cached := cachedRegistry{registry: counting, cache: responses}
+
+	ref, err := registry.ParseReference("alpine:3.19.1")
+	require.NoError(t, err)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		tags, err := cached.Tags(ctx, ref)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"3.19.0", "3.19.1"}, tags)
+		digest, err := cached.Digest(ctx, ref)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:aaaa", digest)
+	}
+	assert.Equal(t, 2, counting.lookups)
+
+	// Failures are looked up again
+	missing, err := registry.ParseReference("alpine:2.0")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := cached.Digest(ctx, missing)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 4, counting.lookups)
+}
@@ -0,0 +1,414 @@
+// Package analysis provides an inventory of the cryptographic libraries in
+// an SBOM, with the algorithm, FIPS 140 and post-quantum concerns that
+// export-control and cryptographic readiness reviews ask about.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	packageurl "github.com/hueyexe/SBOM-Sentinel/internal/purl"
+	"github.com/hueyexe/SBOM-Sentinel/internal/versions"
+)
+
+// CryptoAlgorithm describes a cryptographic algorithm a library provides.
+type CryptoAlgorithm struct {
+	Name string `json:"name"`
+	// Primitive is the CycloneDX cryptographic primitive, e.g. "pke",
+	// "signature", "block-cipher" or "hash"
+	Primitive string `json:"primitive"`
+	// QuantumSafe reports whether the algorithm withstands a
+	// cryptographically relevant quantum computer
+	QuantumSafe bool `json:"quantum_safe"`
+	// Legacy reports whether the algorithm is broken or deprecated for security use
+	Legacy bool `json:"legacy,omitempty"`
+}
+
+// cryptoAlgorithms describes the algorithms named in the library catalog.
+var cryptoAlgorithms = map[string]CryptoAlgorithm{
+	"RSA":               {Primitive: "pke"},
+	"DSA":               {Primitive: "signature", Legacy: true},
+	"ECDSA":             {Primitive: "signature"},
+	"ECDH":              {Primitive: "key-agree"},
+	"Ed25519":           {Primitive: "signature"},
+	"X25519":            {Primitive: "key-agree"},
+	"AES":               {Primitive: "block-cipher", QuantumSafe: true},
+	"3DES":              {Primitive: "block-cipher", Legacy: true},
+	"RC4":               {Primitive: "stream-cipher", Legacy: true},
+	"ChaCha20-Poly1305": {Primitive: "ae", QuantumSafe: true},
+	"XSalsa20-Poly1305": {Primitive: "ae", QuantumSafe: true},
+	"SHA-256":           {Primitive: "hash", QuantumSafe: true},
+	"BLAKE2b":           {Primitive: "hash", QuantumSafe: true},
+	"SHA-1":             {Primitive: "hash", Legacy: true},
+	"MD5":               {Primitive: "hash", Legacy: true},
+	"ML-KEM":            {Primitive: "kem", QuantumSafe: true},
+	"ML-DSA":            {Primitive: "signature", QuantumSafe: true},
+	"SLH-DSA":           {Primitive: "signature", QuantumSafe: true},
+}
+
+// FIPS 140 status of a cryptographic library.
+const (
+	FIPSValidated = "validated"
+	// FIPSAvailable means a validated build, edition or provider of the
+	// library exists, but the component is not known to be one
+	FIPSAvailable    = "validated edition available"
+	FIPSNotValidated = "not validated"
+)
+
+// cryptoLibrary is a catalog entry identifying a cryptographic library.
+type cryptoLibrary struct {
+	name string
+	// names and prefixes match lower-cased package names
+	names      []string
+	prefixes   []string
+	algorithms []string
+	fips       string
+	// concern, if set, is a problem with the library itself
+	concern string
+	// endOfLife, if set, is the first version still supported upstream
+	endOfLife string
+}
+
+// cryptoLibraries is the catalog of known cryptographic libraries. Entries
+// are matched in order, so FIPS editions precede their general distributions.
+var cryptoLibraries = []cryptoLibrary{
+	{
+		name:       "OpenSSL",
+		names:      []string{"openssl", "openssl-libs", "openssl-devel", "libssl", "libssl-dev", "libssl3", "libssl3t64", "libssl1.1", "libssl1.0.0", "libcrypto", "libcrypto3"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5", "3DES"},
+		fips:       FIPSAvailable,
+		endOfLife:  "3.0.0",
+	},
+	{
+		name:       "LibreSSL",
+		names:      []string{"libressl"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "BoringSSL",
+		names:      []string{"boringssl"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "ML-KEM"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "AWS-LC",
+		names:      []string{"aws-lc", "aws-lc-rs", "aws-lc-sys", "aws-lc-fips-sys"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "ML-KEM"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "Bouncy Castle FIPS",
+		names:      []string{"bc-fips", "bcpkix-fips", "bctls-fips", "bcutil-fips"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "AES", "SHA-256", "SHA-1"},
+		fips:       FIPSValidated,
+	},
+	{
+		name:       "Bouncy Castle",
+		names:      []string{"bouncycastle", "bouncycastle.crypto", "bouncycastle.cryptography", "portable.bouncycastle"},
+		prefixes:   []string{"bcprov-", "bcpkix-", "bcpg-", "bctls-", "bcutil-"},
+		algorithms: []string{"RSA", "DSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5", "3DES", "ML-KEM", "ML-DSA"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "libsodium",
+		names:      []string{"libsodium", "libsodium23", "libsodium-dev", "sodium", "sodium-native", "libsodium-wrappers", "pynacl", "sodiumoxide", "lazysodium-java"},
+		algorithms: []string{"Ed25519", "X25519", "XSalsa20-Poly1305", "ChaCha20-Poly1305", "AES", "BLAKE2b", "SHA-256"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "TweetNaCl",
+		names:      []string{"tweetnacl", "tweetnacl-js"},
+		algorithms: []string{"Ed25519", "X25519", "XSalsa20-Poly1305"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "wolfSSL",
+		names:      []string{"wolfssl", "wolfcrypt"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "ML-KEM", "ML-DSA"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "Mbed TLS",
+		names:      []string{"mbedtls", "libmbedtls", "libmbedcrypto", "polarssl"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5", "3DES"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "GnuTLS",
+		names:      []string{"gnutls", "gnutls28", "libgnutls30", "libgnutls30t64"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "Nettle",
+		names:      []string{"nettle", "libnettle8", "libnettle8t64"},
+		algorithms: []string{"RSA", "DSA", "ECDSA", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "Libgcrypt",
+		names:      []string{"libgcrypt", "libgcrypt20"},
+		algorithms: []string{"RSA", "DSA", "ECDSA", "Ed25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5", "3DES"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "NSS",
+		names:      []string{"nss", "libnss3", "nss-softokn"},
+		algorithms: []string{"RSA", "DSA", "ECDSA", "ECDH", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5", "3DES"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "pyca/cryptography",
+		names:      []string{"cryptography"},
+		algorithms: []string{"RSA", "DSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5", "3DES"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "PyCryptodome",
+		names:      []string{"pycryptodome", "pycryptodomex"},
+		algorithms: []string{"RSA", "DSA", "ECDSA", "Ed25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5", "3DES"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "PyCrypto",
+		names:      []string{"pycrypto"},
+		algorithms: []string{"RSA", "DSA", "AES", "SHA-256", "SHA-1", "MD5", "3DES", "RC4"},
+		fips:       FIPSNotValidated,
+		concern:    "unmaintained since 2013 with known vulnerabilities; replace it with PyCryptodome or pyca/cryptography",
+	},
+	{
+		name:       "CryptoJS",
+		names:      []string{"crypto-js"},
+		algorithms: []string{"AES", "SHA-256", "SHA-1", "MD5", "3DES", "RC4"},
+		fips:       FIPSNotValidated,
+		concern:    "no longer maintained; use the Web Crypto API or Node.js crypto module instead",
+	},
+	{
+		name:       "Forge",
+		names:      []string{"node-forge"},
+		algorithms: []string{"RSA", "Ed25519", "AES", "SHA-256", "SHA-1", "MD5", "3DES", "RC4"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "jsrsasign",
+		names:      []string{"jsrsasign"},
+		algorithms: []string{"RSA", "DSA", "ECDSA", "SHA-256", "SHA-1", "MD5"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "Go extended cryptography",
+		names:      []string{"golang.org/x/crypto"},
+		algorithms: []string{"Ed25519", "X25519", "ChaCha20-Poly1305", "BLAKE2b", "SHA-256"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "ring",
+		names:      []string{"ring"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "Tink",
+		names:      []string{"tink", "tink-android", "tink-go", "tink-cc"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "Ed25519", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256"},
+		fips:       FIPSAvailable,
+	},
+	{
+		name:       "Conscrypt",
+		prefixes:   []string{"conscrypt-"},
+		algorithms: []string{"RSA", "ECDSA", "ECDH", "X25519", "AES", "ChaCha20-Poly1305", "SHA-256", "SHA-1", "MD5"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "Open Quantum Safe",
+		names:      []string{"liboqs", "liboqs-python", "liboqs-java", "oqs-provider", "oqs", "pqcrypto"},
+		algorithms: []string{"ML-KEM", "ML-DSA", "SLH-DSA"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "CIRCL",
+		names:      []string{"github.com/cloudflare/circl"},
+		algorithms: []string{"Ed25519", "X25519", "ML-KEM", "ML-DSA", "SLH-DSA"},
+		fips:       FIPSNotValidated,
+	},
+	{
+		name:       "MD5/SHA-1 helper",
+		names:      []string{"md5", "js-md5", "blueimp-md5", "md5.js", "sha1", "js-sha1"},
+		algorithms: []string{"MD5", "SHA-1"},
+		fips:       FIPSNotValidated,
+		concern:    "implements only algorithms that are broken for security use",
+	},
+}
+
+// CryptoAsset is a cryptographic library found in an SBOM, in the spirit of
+// a CycloneDX Cryptography Bill of Materials (CBOM).
+type CryptoAsset struct {
+	Component core.ComponentRef `json:"component"`
+	// Library is the name of the cryptographic library the component provides
+	Library    string            `json:"library"`
+	Algorithms []CryptoAlgorithm `json:"algorithms"`
+	// FIPS is the library's FIPS 140 status: "validated",
+	// "validated edition available" or "not validated"
+	FIPS string `json:"fips"`
+	// Concerns lists problems to review, such as end-of-life versions and
+	// quantum-vulnerable algorithms
+	Concerns []string `json:"concerns,omitempty"`
+	// Severe reports whether a concern is with the library itself, such as
+	// being unmaintained or end-of-life, rather than with its algorithms
+	Severe bool `json:"severe,omitempty"`
+}
+
+// CryptoInventory identifies the cryptographic libraries among the SBOM's
+// components, in component order.
+func CryptoInventory(sbom core.SBOM) []CryptoAsset {
+	var assets []CryptoAsset
+	for _, component := range sbom.Components {
+		library, ok := findCryptoLibrary(component)
+		if !ok {
+			continue
+		}
+		assets = append(assets, newCryptoAsset(component, library))
+	}
+	return assets
+}
+
+// findCryptoLibrary looks a component up in the library catalog by its name
+// and, for components named differently, by its PURL name.
+func findCryptoLibrary(component core.Component) (cryptoLibrary, bool) {
+	candidates := []string{strings.ToLower(strings.TrimSpace(component.Name))}
+	if purlName := purlPackageName(component.PURL); purlName != "" && purlName != candidates[0] {
+		candidates = append(candidates, purlName)
+	}
+
+	for _, library := range cryptoLibraries {
+		for _, candidate := range candidates {
+			if library.matches(candidate) {
+				return library, true
+			}
+		}
+	}
+	return cryptoLibrary{}, false
+}
+
+// matches reports whether a lower-cased package name belongs to the library.
+func (l cryptoLibrary) matches(name string) bool {
+	for _, known := range l.names {
+		if name == known {
+			return true
+		}
+	}
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// purlPackageName returns the lower-cased package name of a Package URL; for
+// Go modules it is the full module path.
+func purlPackageName(purl string) string {
+	parsed, err := packageurl.Parse(purl)
+	if err != nil {
+		return ""
+	}
+	if parsed.Type == "golang" {
+		return strings.ToLower(parsed.FullName())
+	}
+	return strings.ToLower(parsed.Name)
+}
+
+// newCryptoAsset describes a component providing a cataloged library.
+func newCryptoAsset(component core.Component, library cryptoLibrary) CryptoAsset {
+	asset := CryptoAsset{
+		Component: *component.Ref(),
+		Library:   library.name,
+		FIPS:      library.fips,
+	}
+
+	var legacy, quantumVulnerable []string
+	for _, name := range library.algorithms {
+		algorithm := cryptoAlgorithms[name]
+		algorithm.Name = name
+		asset.Algorithms = append(asset.Algorithms, algorithm)
+
+		switch {
+		case algorithm.Legacy:
+			legacy = append(legacy, name)
+		case !algorithm.QuantumSafe:
+			quantumVulnerable = append(quantumVulnerable, name)
+		}
+	}
+
+	if library.concern != "" {
+		asset.Concerns = append(asset.Concerns, library.concern)
+		asset.Severe = true
+	}
+	if library.endOfLife != "" && component.Version != "" && versions.CompareGeneric(component.Version, library.endOfLife) < 0 {
+		asset.Concerns = append(asset.Concerns, fmt.Sprintf("version %s is end-of-life; upgrade to %s or later", component.Version, library.endOfLife))
+		asset.Severe = true
+	}
+	if len(quantumVulnerable) > 0 {
+		asset.Concerns = append(asset.Concerns, "quantum-vulnerable public-key algorithms: "+strings.Join(quantumVulnerable, ", "))
+	}
+	if len(legacy) > 0 {
+		asset.Concerns = append(asset.Concerns, "legacy algorithms available: "+strings.Join(legacy, ", "))
+	}
+	return asset
+}
+
+// CryptoAgent inventories the cryptographic libraries in an SBOM.
+type CryptoAgent struct{}
+
+// NewCryptoAgent creates a new instance of CryptoAgent.
+func NewCryptoAgent() *CryptoAgent {
+	return &CryptoAgent{}
+}
+
+// Name returns the identifier for this analysis agent.
+func (ca *CryptoAgent) Name() string {
+	return "Cryptography Agent"
+}
+
+// Analyze reports one finding per cryptographic library in the SBOM,
+// describing its algorithms, FIPS 140 status and concerns. Libraries that
+// are unmaintained, end-of-life or offer only broken algorithms are rated
+// High; the rest are rated Low as an inventory record for review.
+func (ca *CryptoAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	var results []core.AnalysisResult
+
+	for _, asset := range CryptoInventory(sbom) {
+		names := make([]string, len(asset.Algorithms))
+		for i, algorithm := range asset.Algorithms {
+			names[i] = algorithm.Name
+		}
+
+		finding := fmt.Sprintf("Component '%s' (v%s) provides cryptography (%s): %s. FIPS 140: %s.",
+			asset.Component.Name,
+			asset.Component.Version,
+			asset.Library,
+			strings.Join(names, ", "),
+			asset.FIPS)
+		if len(asset.Concerns) > 0 {
+			finding += " Concerns: " + strings.Join(asset.Concerns, "; ") + "."
+		}
+
+		severity := core.SeverityLow
+		if asset.Severe {
+			severity = core.SeverityHigh
+		}
+
+		component := asset.Component
+		results = append(results, core.AnalysisResult{
+			AgentName: ca.Name(),
+			Finding:   finding,
+			Severity:  severity,
+			Component: &component,
+		})
+	}
+
+	return results, nil
+}
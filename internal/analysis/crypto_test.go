@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCryptoInventory(t *testing.T) {
+	sbom := core.SBOM{
+		Name: "payments-api",
+		Components: []core.Component{
+			{Name: "openssl", Version: "1.1.1k", PURL: "pkg:deb/debian/openssl@1.1.1k"},
+			{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+			{Name: "bcprov-jdk18on", Version: "1.78", PURL: "pkg:maven/org.bouncycastle/bcprov-jdk18on@1.78"},
+			{Name: "bc-fips", Version: "1.0.2.4", PURL: "pkg:maven/org.bouncycastle/bc-fips@1.0.2.4"},
+			{Name: "org.bouncycastle:bcpkix-jdk18on", Version: "1.78", PURL: "pkg:maven/org.bouncycastle/bcpkix-jdk18on@1.78"},
+			{Name: "crypto", Version: "0.21.0", PURL: "pkg:golang/golang.org/x/crypto@v0.21.0"},
+			{Name: "PyNaCl", Version: "1.5.0", PURL: "pkg:pypi/pynacl@1.5.0"},
+			{Name: "libssl3", Version: "3.0.11-1"},
+		},
+	}
+
+	assets := CryptoInventory(sbom)
+
+	libraries := make(map[string]string)
+	for _, asset := range assets {
+		libraries[asset.Component.Name] = asset.Library
+	}
+	assert.Equal(t, map[string]string{
+		"openssl":                         "OpenSSL",
+		"bcprov-jdk18on":                  "Bouncy Castle",
+		"bc-fips":                         "Bouncy Castle FIPS",
+		"org.bouncycastle:bcpkix-jdk18on": "Bouncy Castle",
+		"crypto":                          "Go extended cryptography",
+		"PyNaCl":                          "libsodium",
+		"libssl3":                         "OpenSSL",
+	}, libraries)
+
+	openssl := assets[0]
+	assert.Equal(t, FIPSAvailable, openssl.FIPS)
+	assert.True(t, openssl.Severe)
+	assert.Contains(t, openssl.Concerns, "version 1.1.1k is end-of-life; upgrade to 3.0.0 or later")
+	assert.Contains(t, openssl.Concerns, "legacy algorithms available: SHA-1, MD5, 3DES")
+	assert.Contains(t, openssl.Concerns, "quantum-vulnerable public-key algorithms: RSA, ECDSA, ECDH, Ed25519, X25519")
+
+	supported := assets[len(assets)-1]
+	assert.Equal(t, "libssl3", supported.Component.Name)
+	assert.False(t, supported.Severe)
+
+	fips := assets[2]
+	assert.Equal(t, FIPSValidated, fips.FIPS)
+}
+
+func TestCryptoAgent_Analyze(t *testing.T) {
+	agent := NewCryptoAgent()
+	assert.Equal(t, "Cryptography Agent", agent.Name())
+
+	sbom := core.SBOM{
+		Components: []core.Component{
+			{Name: "pycrypto", Version: "2.6.1"},
+			{Name: "libsodium", Version: "1.0.18"},
+			{Name: "requests", Version: "2.31.0"},
+		},
+	}
+
+	results, err := agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, core.SeverityHigh, results[0].Severity)
+	assert.Contains(t, results[0].Finding, "Component 'pycrypto' (v2.6.1) provides cryptography (PyCrypto)")
+	assert.Contains(t, results[0].Finding, "unmaintained since 2013")
+	assert.Equal(t, "pycrypto", results[0].Component.Name)
+
+	assert.Equal(t, core.SeverityLow, results[1].Severity)
+	assert.Contains(t, results[1].Finding, "FIPS 140: not validated.")
+}
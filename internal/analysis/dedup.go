@@ -0,0 +1,114 @@
+// Package analysis provides deduplication of component analyses across the
+// SBOMs of a bulk analysis.
+package analysis
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// ComponentDedup counts the components of the SBOMs in a bulk analysis.
+type ComponentDedup struct {
+	// Components is the number of components across all SBOMs
+	Components int `json:"components"`
+	// Unique is the number of distinct components, identified by PURL or by
+	// name and version, each analyzed once per component agent
+	Unique int `json:"unique_components"`
+}
+
+// Deduplicate prepares the orchestrator for analyzing sboms one after
+// another. It returns an orchestrator with the same agents in which every
+// ComponentAgent analyzes a component once, the first time an SBOM has it,
+// and reuses the findings for every later SBOM with the same component,
+// together with the counts of the unique component set. Components that
+// fail to be analyzed are retried by the next SBOM with them.
+func (o *Orchestrator) Deduplicate(sboms []core.SBOM) (*Orchestrator, ComponentDedup) {
+	var dedup ComponentDedup
+	unique := make(map[string]bool)
+	for _, sbom := range sboms {
+		for _, component := range sbom.Components {
+			dedup.Components++
+			key := component.PURL
+			if key == "" {
+				key = strings.ToLower(component.Name) + "@" + component.Version
+			}
+			unique[key] = true
+		}
+	}
+	dedup.Unique = len(unique)
+
+	deduplicated := &Orchestrator{timeouts: o.timeouts, pullModels: o.pullModels, models: o.models}
+	for _, registered := range o.agents {
+		if agent, ok := registered.agent.(ComponentAgent); ok {
+			registered.agent = &dedupAgent{ComponentAgent: agent, results: make(map[string][]core.AnalysisResult)}
+		}
+		deduplicated.agents = append(deduplicated.agents, registered)
+	}
+	return deduplicated, dedup
+}
+
+// dedupAgent is a ComponentAgent that remembers the findings of every
+// component it analyzed by component key.
+type dedupAgent struct {
+	ComponentAgent
+
+	mu      sync.Mutex
+	results map[string][]core.AnalysisResult
+}
+
+// Analyze analyzes the components of sbom not analyzed before and returns
+// the findings of every component, with those of earlier SBOMs attributed
+// to this SBOM's components.
+func (d *dedupAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	keys := d.ComponentKeys(sbom)
+
+	// The unique components of sbom not analyzed before
+	var pending []core.Component
+	var pendingKeys []string
+	queued := make(map[string]bool)
+	d.mu.Lock()
+	for i, key := range keys {
+		if key == "" || queued[key] {
+			continue
+		}
+		if _, analyzed := d.results[key]; !analyzed {
+			queued[key] = true
+			pending = append(pending, sbom.Components[i])
+			pendingKeys = append(pendingKeys, key)
+		}
+	}
+	d.mu.Unlock()
+
+	if len(pending) > 0 {
+		err := d.AnalyzeComponents(ctx, sbom, pending, func(i int, found []core.AnalysisResult) {
+			d.mu.Lock()
+			d.results[pendingKeys[i]] = found
+			d.mu.Unlock()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var results []core.AnalysisResult
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, key := range keys {
+		if key == "" {
+			continue
+		}
+		for _, result := range d.results[key] {
+			result.Component = sbom.Components[i].Ref()
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// componentKey identifies a component by the fields agents analyze it by.
+func componentKey(component core.Component) string {
+	return strings.Join([]string{component.Name, component.Version, component.PURL, component.CPE}, "\x00")
+}
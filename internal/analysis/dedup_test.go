@@ -0,0 +1,145 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingComponentAgent reports a finding for every component and counts
+// the components it analyzed.
+type countingComponentAgent struct {
+	analyzed []string
+	// fail lists components that cannot be analyzed
+	fail map[string]bool
+}
+
+func (a *countingComponentAgent) Name() string { return "Counting Agent" }
+
+func (a *countingComponentAgent) Parameters() map[string]string {
+	return map[string]string{"mode": "count"}
+}
+
+func (a *countingComponentAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	return analyzeEachComponent(ctx, a, sbom)
+}
+
+func (a *countingComponentAgent) ComponentKeys(sbom core.SBOM) []string {
+	keys := make([]string, len(sbom.Components))
+	for i, component := range sbom.Components {
+		if component.Version != "" {
+			keys[i] = componentKey(component)
+		}
+	}
+	return keys
+}
+
+func (a *countingComponentAgent) AnalyzeComponents(ctx context.Context, sbom core.SBOM, components []core.Component, found func(i int, results []core.AnalysisResult)) error {
+	for i, component := range components {
+		if component.Version == "" {
+			continue
+		}
+		a.analyzed = append(a.analyzed, component.Name)
+		if a.fail[component.Name] {
+			continue
+		}
+		found(i, []core.AnalysisResult{{
+			AgentName: a.Name(),
+			Finding:   fmt.Sprintf("%s %s is counted", component.Name, component.Version),
+			Severity:  core.SeverityLow,
+			Component: component.Ref(),
+		}})
+	}
+	return nil
+}
+
+func TestOrchestrator_Deduplicate(t *testing.T) {
+	agent := &countingComponentAgent{fail: map[string]bool{"flaky": true}}
+	orchestrator := NewOrchestrator(AgentTimeouts{})
+	orchestrator.Add(agent)
+	orchestrator.Add(&stubAgent{name: "Stub", results: []core.AnalysisResult{{AgentName: "Stub", Finding: "per SBOM", Severity: core.SeverityLow}}})
+
+	sboms := []core.SBOM{
+		{ID: "a", Components: []core.Component{
+			{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21"},
+			{Name: "flaky", Version: "1.0.0"},
+			{Name: "unversioned"},
+		}},
+		{ID: "b", Components: []core.Component{
+			{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", Scope: "optional"},
+			{Name: "flaky", Version: "1.0.0"},
+			{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+			{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+		}},
+	}
+
+	deduplicated, dedup := orchestrator.Deduplicate(sboms)
+	assert.Equal(t, ComponentDedup{Components: 7, Unique: 4}, dedup)
+	assert.Equal(t, map[string]map[string]string{"Counting Agent": {"mode": "count"}}, deduplicated.Parameters())
+	require.Len(t, deduplicated.Agents(), 2)
+	assert.Equal(t, "Counting Agent", deduplicated.Agents()[0].Name())
+
+	first, err := deduplicated.Run(context.Background(), sboms[0])
+	require.NoError(t, err)
+	second, err := deduplicated.Run(context.Background(), sboms[1])
+	require.NoError(t, err)
+
+	// Shared components are analyzed once; failed ones are retried
+	assert.Equal(t, []string{"lodash", "flaky", "flaky", "express"}, agent.analyzed)
+
+	require.Len(t, first.Results, 2)
+	assert.Equal(t, "lodash 4.17.21 is counted", first.Results[0].Finding)
+	assert.Equal(t, "per SBOM", first.Results[1].Finding)
+
+	// Findings are attributed to each SBOM's own components, in order
+	require.Len(t, second.Results, 4)
+	assert.Equal(t, "lodash 4.17.21 is counted", second.Results[0].Finding)
+	assert.Equal(t, "optional", second.Results[0].Component.Scope)
+	assert.Equal(t, "express 4.18.2 is counted", second.Results[1].Finding)
+	assert.Equal(t, "express 4.18.2 is counted", second.Results[2].Finding)
+	assert.Equal(t, "per SBOM", second.Results[3].Finding)
+
+	// The original orchestrator is unchanged
+	_, err = orchestrator.Run(context.Background(), sboms[0])
+	require.NoError(t, err)
+	assert.Len(t, agent.analyzed, 6)
+}
+
+func TestOrchestrator_Deduplicate_VulnerabilityScanner(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"vulns": [{"id": "GHSA-p6mc-m468-83gw", "summary": "Prototype Pollution in lodash"}]}`))
+	}))
+	defer mockServer.Close()
+
+	agent := NewVulnerabilityScanningAgent()
+	agent.apiBaseURL = mockServer.URL
+	agent.cache = nil
+	orchestrator := NewOrchestrator(AgentTimeouts{Default: time.Minute})
+	orchestrator.Add(agent)
+
+	var sboms []core.SBOM
+	for i := 0; i < 5; i++ {
+		sboms = append(sboms, core.SBOM{ID: fmt.Sprintf("sbom-%d", i), Components: []core.Component{
+			{Name: "lodash", Version: "4.17.15", PURL: "pkg:npm/lodash@4.17.15"},
+		}})
+	}
+
+	deduplicated, _ := orchestrator.Deduplicate(sboms)
+	for _, sbom := range sboms {
+		report, err := deduplicated.Run(context.Background(), sbom)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 1)
+		assert.Equal(t, "GHSA-p6mc-m468-83gw", report.Results[0].VulnerabilityID)
+	}
+	assert.Equal(t, 1, requests)
+}
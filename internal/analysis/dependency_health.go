@@ -9,9 +9,13 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/llmlimit"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
 )
 
 // DependencyHealthAgent analyzes SBOM components for health and maintenance status using AI.
@@ -19,37 +23,79 @@ type DependencyHealthAgent struct {
 	ollamaURL string
 	model     string
 	client    *http.Client
+	verifier  *FindingVerifier
+	prompt    *template.Template
+	limiter   *llmlimit.Limiter
 }
 
 // NewDependencyHealthAgent creates a new instance of DependencyHealthAgent.
+// Its prompt is the dependency-health.tmpl template in PROMPT_TEMPLATE_DIR,
+// if there is one.
 func NewDependencyHealthAgent() *DependencyHealthAgent {
 	return &DependencyHealthAgent{
-		ollamaURL: "http://localhost:11434/api/generate",
-		model:     "llama3",
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		ollamaURL: vectordb.OllamaURL("/api/generate"),
+		model:     generationModelFromEnv(),
+		client:    httpclient.New(30 * time.Second),
+		verifier:  NewFindingVerifier(NewVulnerabilityScanningAgent()),
+		prompt:    promptTemplateFromEnv(HealthPromptTemplate),
+		limiter:   llmlimit.Shared(),
 	}
 }
 
+// SetPromptTemplate sets the template of the prompt asking about a
+// component, executed with HealthPromptData.
+func (dha *DependencyHealthAgent) SetPromptTemplate(prompt *template.Template) {
+	dha.prompt = prompt
+}
+
 // Name returns the identifier for this analysis agent.
 func (dha *DependencyHealthAgent) Name() string {
 	return "Dependency Health Agent"
 }
 
+// OllamaModels returns the Ollama server and generation model the agent prompts.
+func (dha *DependencyHealthAgent) OllamaModels() (string, []OllamaModel) {
+	return strings.TrimSuffix(dha.ollamaURL, "/api/generate"), []OllamaModel{{Name: dha.model}}
+}
+
 // Analyze examines the SBOM components for health and maintenance status using AI.
 // It queries a local LLM via Ollama to assess each component's health.
 func (dha *DependencyHealthAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
-	var results []core.AnalysisResult
+	return analyzeEachComponent(ctx, dha, sbom)
+}
+
+// ComponentKeys identifies each component with a name and version, the
+// components the agent assesses.
+func (dha *DependencyHealthAgent) ComponentKeys(sbom core.SBOM) []string {
+	keys := make([]string, len(sbom.Components))
+	for i, component := range sbom.Components {
+		if component.Name != "" && component.Version != "" {
+			keys[i] = componentKey(component)
+		}
+	}
+	return keys
+}
+
+// AnalyzeComponents assesses the health of the given components of sbom.
+// Components the LLM cannot assess are logged and skipped.
+func (dha *DependencyHealthAgent) AnalyzeComponents(ctx context.Context, sbom core.SBOM, components []core.Component, found func(i int, results []core.AnalysisResult)) error {
+	for i, component := range components {
+		// Stop querying once the caller has gone away or the time limit is up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	for _, component := range sbom.Components {
 		// Skip components without name or version
 		if component.Name == "" || component.Version == "" {
 			continue
 		}
 
 		// Generate prompt for the LLM
-		prompt := dha.generatePrompt(component)
+		prompt, err := dha.generatePrompt(component)
+		if err != nil {
+			fmt.Printf("Warning: Failed to generate prompt for component '%s': %v\n", component.Name, err)
+			continue
+		}
 
 		// Query the LLM
 		response, err := dha.queryOllama(ctx, prompt)
@@ -60,23 +106,36 @@ func (dha *DependencyHealthAgent) Analyze(ctx context.Context, sbom core.SBOM) (
 		}
 
 		// Check if the response indicates potential risk
-		if dha.indicatesRisk(response) {
+		var results []core.AnalysisResult
+		assessment, confidence := parseLLMFinding(response)
+		if dha.indicatesRisk(assessment) {
 			result := core.AnalysisResult{
-				AgentName: dha.Name(),
-				Finding:   response,
-				Severity:  "Medium",
+				AgentName:  dha.Name(),
+				Finding:    assessment,
+				Severity:   "Medium",
+				Component:  component.Ref(),
+				Confidence: confidence,
+			}
+			// Link the finding to the repository it can be checked against
+			if repository := component.RepositoryURL(); repository != "" {
+				result.Citations = []core.Citation{{ID: repository, Title: "Source repository", URL: repository}}
+			}
+
+			// The answer is drawn from the model's own knowledge, so any
+			// vulnerability or version it names must be corroborated
+			if result, keep := dha.verifier.Verify(ctx, result, component, nil); keep {
+				results = append(results, result)
 			}
-			results = append(results, result)
 		}
+		found(i, results)
 	}
 
-	return results, nil
+	return nil
 }
 
 // generatePrompt creates a specific prompt for the LLM to assess component health.
-func (dha *DependencyHealthAgent) generatePrompt(component core.Component) string {
-	return fmt.Sprintf("Analyze the project health of the open-source component '%s' version '%s'. Based on public knowledge, is this project actively maintained, deprecated, or considered risky for other reasons? Answer in one sentence.",
-		component.Name, component.Version)
+func (dha *DependencyHealthAgent) generatePrompt(component core.Component) (string, error) {
+	return renderPrompt(dha.prompt, HealthPromptData{Component: component, Repository: component.RepositoryURL()})
 }
 
 // queryOllama sends a request to the Ollama API and returns the response.
@@ -86,6 +145,7 @@ func (dha *DependencyHealthAgent) queryOllama(ctx context.Context, prompt string
 		Model:  dha.model,
 		Prompt: prompt,
 		Stream: false,
+		Format: findingFormat,
 	}
 
 	reqBody, err := json.Marshal(reqPayload)
@@ -101,6 +161,13 @@ func (dha *DependencyHealthAgent) queryOllama(ctx context.Context, prompt string
 
 	req.Header.Set("Content-Type", "application/json")
 
+	// Wait for a turn at the LLM shared with other analyses
+	release, err := dha.limiter.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	// Send request
 	resp, err := dha.client.Do(req)
 	if err != nil {
@@ -119,6 +186,7 @@ func (dha *DependencyHealthAgent) queryOllama(ctx context.Context, prompt string
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
+	recordLLMUsage(ctx, ollamaResp)
 
 	return strings.TrimSpace(ollamaResp.Response), nil
 }
@@ -6,9 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/llmlimit"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDependencyHealthAgent_Name(t *testing.T) {
@@ -188,6 +191,69 @@ func TestDependencyHealthAgent_Analyze(t *testing.T) {
 	}
 }
 
+func TestDependencyHealthAgent_Analyze_Confidence(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"response": `{"finding": "This component is deprecated in favour of its successor.", "confidence": 0.8}`,
+		})
+	}))
+	defer mockServer.Close()
+
+	agent := NewDependencyHealthAgent()
+	agent.ollamaURL = mockServer.URL
+
+	results, err := agent.Analyze(context.Background(), core.SBOM{Components: []core.Component{{Name: "old-library", Version: "1.0.0"}}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "This component is deprecated in favour of its successor.", results[0].Finding)
+	require.NotNil(t, results[0].Confidence)
+	assert.Equal(t, 0.8, *results[0].Confidence)
+}
+
+func TestDependencyHealthAgent_Analyze_Repository(t *testing.T) {
+	var prompt string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request OllamaRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		prompt = request.Prompt
+		json.NewEncoder(w).Encode(map[string]string{"response": `{"finding": "The repository is archived and unmaintained."}`})
+	}))
+	defer mockServer.Close()
+
+	agent := NewDependencyHealthAgent()
+	agent.ollamaURL = mockServer.URL
+
+	component := core.Component{Name: "request", Version: "2.88.2", ExternalReferences: []core.ExternalReference{
+		{Type: core.ReferenceVCS, URL: "git+https://github.com/request/request.git"},
+	}}
+	results, err := agent.Analyze(context.Background(), core.SBOM{Components: []core.Component{component}})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "component 'request' version '2.88.2', developed at https://github.com/request/request.")
+
+	// The finding links to the repository
+	require.Len(t, results, 1)
+	assert.Equal(t, []core.Citation{{ID: "https://github.com/request/request", Title: "Source repository", URL: "https://github.com/request/request"}}, results[0].Citations)
+}
+
+func TestParseLLMFinding(t *testing.T) {
+	finding, confidence := parseLLMFinding(` {"finding": " Unmaintained since 2019. ", "confidence": 0.7}`)
+	assert.Equal(t, "Unmaintained since 2019.", finding)
+	require.NotNil(t, confidence)
+	assert.Equal(t, 0.7, *confidence)
+
+	// Out of range confidences are dropped
+	_, confidence = parseLLMFinding(`{"finding": "Unmaintained.", "confidence": 70}`)
+	assert.Nil(t, confidence)
+
+	// Plain text is the finding itself
+	finding, confidence = parseLLMFinding("This project is abandoned.\n")
+	assert.Equal(t, "This project is abandoned.", finding)
+	assert.Nil(t, confidence)
+
+	finding, _ = parseLLMFinding(`{"answer": "deprecated"}`)
+	assert.Equal(t, `{"answer": "deprecated"}`, finding)
+}
+
 func TestDependencyHealthAgent_generatePrompt(t *testing.T) {
 	agent := NewDependencyHealthAgent()
 	component := core.Component{
@@ -195,8 +261,10 @@ func TestDependencyHealthAgent_generatePrompt(t *testing.T) {
 		Version: "1.2.3",
 	}
 
-	prompt := agent.generatePrompt(component)
+	prompt, err := agent.generatePrompt(component)
+	require.NoError(t, err)
 
+	assert.Equal(t, "Analyze the project health of the open-source component 'test-library' version '1.2.3'. Based on public knowledge, is this project actively maintained, deprecated, or considered risky for other reasons? Answer in one sentence.\n\nRespond in JSON, with your answer as \"finding\" and your confidence in it, from 0 to 1, as \"confidence\".", prompt)
 	assert.Contains(t, prompt, "test-library")
 	assert.Contains(t, prompt, "1.2.3")
 	assert.Contains(t, prompt, "actively maintained")
@@ -372,3 +440,27 @@ func TestDependencyHealthAgent_NetworkError(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(results))
 }
+
+func TestDependencyHealthAgent_Limiter(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"response": "This is a healthy, well-maintained project."}`))
+	}))
+	defer mockServer.Close()
+
+	agent := NewDependencyHealthAgent()
+	agent.ollamaURL = mockServer.URL
+	agent.limiter = llmlimit.New(1)
+
+	// Another analysis holds the only slot until the time limit is up
+	release, err := agent.limiter.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = agent.Analyze(ctx, core.SBOM{Components: []core.Component{{Name: "a", Version: "1.0.0"}, {Name: "b", Version: "2.0.0"}}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, requests)
+}
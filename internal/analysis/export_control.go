@@ -0,0 +1,201 @@
+// Package analysis provides rules-based tagging of components with potential
+// export-control relevance, for review by trade-compliance teams.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// Export-control categories components are tagged with.
+const (
+	ExportCategoryEncryption = "encryption"
+	ExportCategoryNetworking = "networking"
+)
+
+// ExportRule tags the components whose package name matches with an
+// export-control category and candidate ECCN. Names match exactly and
+// prefixes as prefixes, both ignoring case.
+type ExportRule struct {
+	Category string   `yaml:"category"`
+	ECCN     string   `yaml:"eccn"`
+	Reason   string   `yaml:"reason"`
+	Names    []string `yaml:"names"`
+	Prefixes []string `yaml:"prefixes"`
+}
+
+// Validate checks that the rule has a category, an ECCN and something to match.
+func (r *ExportRule) Validate() error {
+	var errs []error
+	if strings.TrimSpace(r.Category) == "" {
+		errs = append(errs, errors.New("category is required"))
+	}
+	if strings.TrimSpace(r.ECCN) == "" {
+		errs = append(errs, errors.New("eccn is required"))
+	}
+	if len(r.Names) == 0 && len(r.Prefixes) == 0 {
+		errs = append(errs, errors.New("names or prefixes are required"))
+	}
+	return errors.Join(errs...)
+}
+
+// matches reports whether a lower-cased package name is covered by the rule.
+func (r ExportRule) matches(name string) bool {
+	for _, known := range r.Names {
+		if strings.EqualFold(name, known) {
+			return true
+		}
+	}
+	for _, prefix := range r.Prefixes {
+		if strings.HasPrefix(name, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cryptoECCN is the candidate ECCN of software implementing encryption for
+// data confidentiality. Most open-source and mass-market software is instead
+// eligible for 5D992 or License Exception ENC, which review decides.
+const cryptoECCN = "5D002"
+
+// DefaultExportRules returns the built-in rules applied on top of the
+// cryptographic library catalog: protocol implementations that embed
+// encryption, and networking stacks.
+func DefaultExportRules() []ExportRule {
+	return []ExportRule{
+		{
+			Category: ExportCategoryEncryption,
+			ECCN:     cryptoECCN,
+			Reason:   "implements an encrypted remote access or VPN protocol",
+			Names:    []string{"openssh", "openssh-client", "openssh-server", "libssh", "libssh2", "libssh-4", "libssh2-1", "jsch", "sshj", "paramiko", "asyncssh", "ssh2", "golang.org/x/crypto/ssh", "wireguard-go", "wireguard-tools", "openvpn", "strongswan", "libreswan", "tor"},
+			Prefixes: []string{"org.apache.sshd"},
+		},
+		{
+			Category: ExportCategoryEncryption,
+			ECCN:     cryptoECCN,
+			Reason:   "implements TLS",
+			Names:    []string{"rustls", "tokio-rustls", "native-tls", "openssl-sys", "pyopenssl"},
+		},
+		{
+			Category: ExportCategoryNetworking,
+			ECCN:     "5D991",
+			Reason:   "implements network transport or messaging; usually EAR99 or 5D991, unless it meets a Category 5 Part 1 control",
+			Names:    []string{"curl", "libcurl", "libcurl4", "nghttp2", "libnghttp2-14", "quic-go", "quiche", "msquic", "lwip", "dpdk", "zeromq", "libzmq", "libzmq5", "pyzmq", "grpc", "grpcio", "google.golang.org/grpc", "grpc-netty", "netty-all", "libp2p", "github.com/libp2p/go-libp2p", "libpcap", "libpcap0.8", "scapy", "nmap"},
+			Prefixes: []string{"netty-codec", "netty-transport", "netty-handler"},
+		},
+	}
+}
+
+// ExportTag is a potential export-control classification of a component.
+type ExportTag struct {
+	Category string `json:"category"`
+	// ECCN is the candidate Export Control Classification Number to review
+	ECCN   string `json:"eccn"`
+	Reason string `json:"reason"`
+}
+
+// ExportEntry is a component with potential export-control relevance.
+type ExportEntry struct {
+	Component core.ComponentRef `json:"component"`
+	Tags      []ExportTag       `json:"tags"`
+}
+
+// ExportControlInventory tags the SBOM's components with potential
+// export-control relevance: cryptographic libraries as encryption items, then
+// the components matched by rules, in component order. A component gets one
+// tag per category.
+func ExportControlInventory(sbom core.SBOM, rules []ExportRule) []ExportEntry {
+	var entries []ExportEntry
+	for _, component := range sbom.Components {
+		var tags []ExportTag
+		tagged := make(map[string]bool)
+
+		if library, ok := findCryptoLibrary(component); ok {
+			tags = append(tags, ExportTag{
+				Category: ExportCategoryEncryption,
+				ECCN:     cryptoECCN,
+				Reason:   fmt.Sprintf("provides cryptography (%s)", library.name),
+			})
+			tagged[ExportCategoryEncryption] = true
+		}
+
+		names := []string{strings.ToLower(strings.TrimSpace(component.Name))}
+		if purlName := purlPackageName(component.PURL); purlName != "" && purlName != names[0] {
+			names = append(names, purlName)
+		}
+		for _, rule := range rules {
+			if tagged[rule.Category] {
+				continue
+			}
+			for _, name := range names {
+				if rule.matches(name) {
+					tags = append(tags, ExportTag{Category: rule.Category, ECCN: rule.ECCN, Reason: rule.Reason})
+					tagged[rule.Category] = true
+					break
+				}
+			}
+		}
+
+		if len(tags) > 0 {
+			entries = append(entries, ExportEntry{Component: *component.Ref(), Tags: tags})
+		}
+	}
+	return entries
+}
+
+// ExportControlAgent tags components with potential export-control relevance.
+type ExportControlAgent struct {
+	rules []ExportRule
+}
+
+// NewExportControlAgent creates an ExportControlAgent applying the built-in
+// rules followed by the given additional rules.
+func NewExportControlAgent(rules ...ExportRule) *ExportControlAgent {
+	return &ExportControlAgent{rules: append(DefaultExportRules(), rules...)}
+}
+
+// Name returns the identifier for this analysis agent.
+func (ea *ExportControlAgent) Name() string {
+	return "Export Control Agent"
+}
+
+// Inventory returns the SBOM's components with potential export-control relevance.
+func (ea *ExportControlAgent) Inventory(sbom core.SBOM) []ExportEntry {
+	return ExportControlInventory(sbom, ea.rules)
+}
+
+// Analyze reports one finding per tagged component. Encryption items need a
+// classification decision before export and are rated Medium; other
+// categories are rated Low.
+func (ea *ExportControlAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	var results []core.AnalysisResult
+
+	for _, entry := range ea.Inventory(sbom) {
+		severity := core.SeverityLow
+		descriptions := make([]string, len(entry.Tags))
+		for i, tag := range entry.Tags {
+			descriptions[i] = fmt.Sprintf("%s (candidate ECCN %s): %s", tag.Category, tag.ECCN, tag.Reason)
+			if tag.Category == ExportCategoryEncryption {
+				severity = core.SeverityMedium
+			}
+		}
+
+		component := entry.Component
+		results = append(results, core.AnalysisResult{
+			AgentName: ea.Name(),
+			Finding: fmt.Sprintf("Component '%s' (v%s) has potential export-control relevance: %s. Confirm its classification with trade compliance.",
+				component.Name,
+				component.Version,
+				strings.Join(descriptions, "; ")),
+			Severity:  severity,
+			Component: &component,
+		})
+	}
+
+	return results, nil
+}
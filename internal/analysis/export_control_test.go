@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportControlInventory(t *testing.T) {
+	sbom := core.SBOM{
+		Components: []core.Component{
+			{Name: "openssl", Version: "3.0.11"},
+			{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+			{Name: "paramiko", Version: "3.4.0", PURL: "pkg:pypi/paramiko@3.4.0"},
+			{Name: "io.netty:netty-codec-http", Version: "4.1.100", PURL: "pkg:maven/io.netty/netty-codec-http@4.1.100"},
+			{Name: "grpc", Version: "1.62.0", PURL: "pkg:golang/google.golang.org/grpc@v1.62.0"},
+		},
+	}
+
+	entries := ExportControlInventory(sbom, DefaultExportRules())
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, "openssl", entries[0].Component.Name)
+	assert.Equal(t, []ExportTag{{Category: ExportCategoryEncryption, ECCN: "5D002", Reason: "provides cryptography (OpenSSL)"}}, entries[0].Tags)
+
+	assert.Equal(t, "paramiko", entries[1].Component.Name)
+	require.Len(t, entries[1].Tags, 1)
+	assert.Equal(t, ExportCategoryEncryption, entries[1].Tags[0].Category)
+
+	assert.Equal(t, "io.netty:netty-codec-http", entries[2].Component.Name)
+	assert.Equal(t, ExportCategoryNetworking, entries[2].Tags[0].Category)
+	assert.Equal(t, "5D991", entries[2].Tags[0].ECCN)
+
+	assert.Equal(t, "grpc", entries[3].Component.Name)
+	assert.Equal(t, ExportCategoryNetworking, entries[3].Tags[0].Category)
+}
+
+func TestExportControlAgent_Analyze(t *testing.T) {
+	agent := NewExportControlAgent(ExportRule{
+		Category: "satellite",
+		ECCN:     "9D515",
+		Reason:   "controls spacecraft",
+		Names:    []string{"flight-software"},
+	})
+	assert.Equal(t, "Export Control Agent", agent.Name())
+
+	sbom := core.SBOM{
+		Components: []core.Component{
+			{Name: "libcurl", Version: "8.5.0"},
+			{Name: "openssl", Version: "3.0.11"},
+			{Name: "flight-software", Version: "2.1.0"},
+			{Name: "lodash", Version: "4.17.21"},
+		},
+	}
+
+	results, err := agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, core.SeverityLow, results[0].Severity)
+	assert.Equal(t, "libcurl", results[0].Component.Name)
+	assert.Contains(t, results[0].Finding, "networking (candidate ECCN 5D991)")
+
+	assert.Equal(t, core.SeverityMedium, results[1].Severity)
+	assert.Contains(t, results[1].Finding, "Component 'openssl' (v3.0.11) has potential export-control relevance: encryption (candidate ECCN 5D002)")
+
+	assert.Equal(t, core.SeverityLow, results[2].Severity)
+	assert.Contains(t, results[2].Finding, "satellite (candidate ECCN 9D515): controls spacecraft")
+}
+
+func TestExportRule_Validate(t *testing.T) {
+	rule := ExportRule{Category: ExportCategoryNetworking, ECCN: "5D991", Prefixes: []string{"netty-"}}
+	assert.NoError(t, rule.Validate())
+
+	err := (&ExportRule{Category: " "}).Validate()
+	assert.ErrorContains(t, err, "category is required")
+	assert.ErrorContains(t, err, "eccn is required")
+	assert.ErrorContains(t, err, "names or prefixes are required")
+}
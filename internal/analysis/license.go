@@ -12,6 +12,9 @@ import (
 // LicenseAgent analyzes SBOM components for high-risk copyleft licenses.
 type LicenseAgent struct {
 	highRiskLicenses map[string]string
+	ignoredScopes    map[string]bool
+	projects         map[string]core.ProjectContext
+	override         core.ProjectContext
 }
 
 // NewLicenseAgent creates a new instance of LicenseAgent with predefined high-risk licenses.
@@ -43,6 +46,19 @@ func NewLicenseAgent() *LicenseAgent {
 
 	return &LicenseAgent{
 		highRiskLicenses: highRiskLicenses,
+		// Excluded components (e.g. dev and test dependencies) are not distributed
+		ignoredScopes: map[string]bool{
+			core.ScopeExcluded: true,
+		},
+	}
+}
+
+// SetIgnoredScopes replaces the set of component scopes skipped during analysis.
+// Passing no scopes evaluates every component regardless of scope.
+func (la *LicenseAgent) SetIgnoredScopes(scopes []string) {
+	la.ignoredScopes = make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		la.ignoredScopes[strings.ToLower(strings.TrimSpace(scope))] = true
 	}
 }
 
@@ -52,42 +68,161 @@ func (la *LicenseAgent) Name() string {
 }
 
 // Analyze examines the SBOM components for high-risk copyleft licenses.
+// Every license declared for a component is evaluated, so a component may
+// produce one finding per high-risk license it carries.
+// Severities are lowered when the SBOM's project context shows that a
+// license's obligations are already met or are not triggered.
 // It returns a slice of AnalysisResult containing findings for components
 // that use licenses identified as high-risk for compliance.
 func (la *LicenseAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
 	var results []core.AnalysisResult
+	project := la.projectContext(sbom)
 
 	for _, component := range sbom.Components {
-		// Skip components without license information
-		if component.License == "" {
+		// Skip components whose scope is not subject to license gating
+		if la.ignoredScopes[component.EffectiveScope()] {
 			continue
 		}
 
-		// Check if the license is in our high-risk list
-		if licenseDescription, isHighRisk := la.isHighRiskLicense(component.License); isHighRisk {
-			// Determine severity based on license type
-			severity := la.determineSeverity(component.License)
-
-			// Create finding message
-			finding := fmt.Sprintf("Component '%s' (v%s) uses high-risk copyleft license '%s' (%s). This may require source code disclosure or impose other compliance obligations.",
-				component.Name,
-				component.Version,
-				component.License,
-				licenseDescription)
-
-			result := core.AnalysisResult{
-				AgentName: la.Name(),
-				Finding:   finding,
-				Severity:  severity,
-			}
+		// Collect high-risk licenses across all declared licenses, reporting each only once
+		reported := make(map[string]bool)
+
+		for _, declared := range component.DeclaredLicenses() {
+			for _, license := range la.riskyLicenses(declared) {
+				if reported[license] {
+					continue
+				}
+				reported[license] = true
+
+				licenseDescription, _ := la.isHighRiskLicense(license)
 
-			results = append(results, result)
+				// Determine severity based on license type and the project context
+				severity, reason := contextSeverity(license, la.determineSeverity(license), project)
+
+				// Create finding message
+				finding := fmt.Sprintf("Component '%s' (v%s) uses high-risk copyleft license '%s' (%s). This may require source code disclosure or impose other compliance obligations.",
+					component.Name,
+					component.Version,
+					license,
+					licenseDescription)
+				if reason != "" {
+					finding += fmt.Sprintf(" Severity lowered to %s: %s.", severity, reason)
+				}
+
+				result := core.AnalysisResult{
+					AgentName: la.Name(),
+					Finding:   finding,
+					Severity:  severity,
+					Component: component.Ref(),
+				}
+
+				results = append(results, result)
+			}
 		}
 	}
 
 	return results, nil
 }
 
+// riskyLicenses returns the high-risk licenses that a declared license or
+// SPDX expression obliges the user to comply with.
+// All terms of an "AND" apply, while for "OR" the licensee may choose, so the
+// alternative carrying the fewest high-risk licenses is used. "WITH"
+// exceptions are ignored and the base license is evaluated.
+func (la *LicenseAgent) riskyLicenses(declared string) []string {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(declared))
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Plain license names may contain spaces (e.g. "GPL 3.0"), so anything
+	// without expression operators is evaluated as a single license
+	if !isLicenseExpression(tokens) {
+		if _, isHighRisk := la.isHighRiskLicense(declared); isHighRisk {
+			return []string{strings.TrimSpace(declared)}
+		}
+		return nil
+	}
+
+	parser := &licenseExpressionParser{agent: la, tokens: tokens}
+	return parser.parseOr()
+}
+
+// isLicenseExpression reports whether the tokens contain SPDX expression operators.
+func isLicenseExpression(tokens []string) bool {
+	for _, token := range tokens {
+		switch strings.ToUpper(token) {
+		case "AND", "OR", "WITH", "(", ")":
+			return true
+		}
+	}
+	return false
+}
+
+// licenseExpressionParser is a small recursive-descent parser for SPDX
+// license expressions that evaluates the high-risk licenses as it parses.
+// AND binds more tightly than OR, as defined by the SPDX specification.
+type licenseExpressionParser struct {
+	agent  *LicenseAgent
+	tokens []string
+	pos    int
+}
+
+// parseOr evaluates "a OR b", choosing the least risky alternative.
+func (p *licenseExpressionParser) parseOr() []string {
+	chosen := p.parseAnd()
+	for p.accept("OR") {
+		alternative := p.parseAnd()
+		if len(alternative) < len(chosen) {
+			chosen = alternative
+		}
+	}
+	return chosen
+}
+
+// parseAnd evaluates "a AND b", where every term applies.
+func (p *licenseExpressionParser) parseAnd() []string {
+	risky := p.parseTerm()
+	for p.accept("AND") {
+		risky = append(risky, p.parseTerm()...)
+	}
+	return risky
+}
+
+// parseTerm evaluates a parenthesized expression or a single license.
+func (p *licenseExpressionParser) parseTerm() []string {
+	if p.accept("(") {
+		risky := p.parseOr()
+		p.accept(")")
+		return risky
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	license := p.tokens[p.pos]
+	p.pos++
+
+	// Skip the exception identifier of "license WITH exception"
+	if p.accept("WITH") && p.pos < len(p.tokens) {
+		p.pos++
+	}
+
+	if _, isHighRisk := p.agent.isHighRiskLicense(license); isHighRisk {
+		return []string{license}
+	}
+	return nil
+}
+
+// accept consumes the next token if it matches, case-insensitively.
+func (p *licenseExpressionParser) accept(token string) bool {
+	if p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], token) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
 // isHighRiskLicense checks if a given license identifier is considered high-risk.
 // It returns the license description and a boolean indicating if it's high-risk.
 func (la *LicenseAgent) isHighRiskLicense(license string) (string, bool) {
@@ -0,0 +1,119 @@
+// Package analysis provides project-aware adjustment of license finding
+// severities, based on the project's own license and distribution model.
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// Copyleft families of the GNU licenses, from weakest to strongest. A
+// project under a stronger license can absorb components under a weaker one.
+const (
+	familyOther = iota
+	familyLGPL
+	familyGPL
+	familyAGPL
+)
+
+// SetProjects declares the context of projects by name. The context of the
+// project an SBOM belongs to, matched by SBOM name, adjusts the severity of
+// its license findings.
+func (la *LicenseAgent) SetProjects(projects map[string]core.ProjectContext) {
+	la.projects = projects
+}
+
+// SetProjectOverride sets context that applies to every SBOM analyzed,
+// taking precedence over the declared project's license and distribution
+// model where set.
+func (la *LicenseAgent) SetProjectOverride(project core.ProjectContext) {
+	la.override = project
+}
+
+// projectContext returns the context of the project an SBOM belongs to.
+func (la *LicenseAgent) projectContext(sbom core.SBOM) core.ProjectContext {
+	project := la.projects[sbom.Name]
+	if la.override.License != "" {
+		project.License = la.override.License
+	}
+	if la.override.Distribution != "" {
+		project.Distribution = la.override.Distribution
+	}
+	return project
+}
+
+// contextSeverity lowers the severity of a high-risk license when the project
+// context shows that its obligations are already met or are not triggered.
+// It returns the severity to report and the reason it was lowered, or an
+// empty reason if it was not.
+func contextSeverity(license, severity string, project core.ProjectContext) (string, string) {
+	if core.SeverityRank(severity) <= core.SeverityRank(core.SeverityLow) {
+		return severity, ""
+	}
+
+	if project.License != "" && licenseCovers(project.License, license) {
+		return core.SeverityLow, fmt.Sprintf("the project is itself released under %s", project.License)
+	}
+
+	switch project.Distribution {
+	case core.DistributionInternal:
+		return core.SeverityLow, "internal-only use does not trigger its obligations"
+	case core.DistributionSaaS:
+		if !isNetworkCopyleft(license) {
+			return core.SeverityLow, "running it as a network service is not distribution, so its obligations are not triggered"
+		}
+	}
+	return severity, ""
+}
+
+// isNetworkCopyleft reports whether a license's obligations extend to users
+// interacting with the software over a network, as with the AGPL and the
+// external deployment clause of the OSL.
+func isNetworkCopyleft(license string) bool {
+	lower := strings.ToLower(license)
+	return strings.Contains(lower, "agpl") || strings.HasPrefix(lower, "osl")
+}
+
+// licenseCovers reports whether releasing a project under projectLicense
+// already satisfies the copyleft obligations of a component's license: the
+// licenses are the same, or the component's GNU license may be combined into
+// the project's stronger one of a compatible version.
+func licenseCovers(projectLicense, componentLicense string) bool {
+	if strings.EqualFold(strings.TrimSpace(projectLicense), strings.TrimSpace(componentLicense)) {
+		return true
+	}
+
+	projectFamily, projectVersion, _ := gnuLicense(projectLicense)
+	componentFamily, componentVersion, orLater := gnuLicense(componentLicense)
+	if projectFamily == familyOther || componentFamily == familyOther || componentFamily > projectFamily {
+		return false
+	}
+
+	// LGPL-2.1 code may be relicensed under GPL-2.0 or later
+	if componentFamily == familyLGPL && projectFamily > familyLGPL && componentVersion == "2.1" {
+		componentVersion = "2.0"
+	}
+	if componentVersion == projectVersion {
+		return true
+	}
+	return orLater && componentVersion < projectVersion
+}
+
+// gnuLicense identifies the GNU license family and version of a license
+// identifier, and whether it allows later versions.
+func gnuLicense(license string) (family int, version string, orLater bool) {
+	lower := strings.ToLower(strings.TrimSpace(license))
+	switch {
+	case strings.Contains(lower, "agpl"):
+		family = familyAGPL
+	case strings.Contains(lower, "lgpl"):
+		family = familyLGPL
+	case strings.Contains(lower, "gpl"):
+		family = familyGPL
+	default:
+		return familyOther, "", false
+	}
+	return family, extractVersionNumber(lower), strings.HasSuffix(lower, "-or-later") || strings.HasSuffix(lower, "+")
+}
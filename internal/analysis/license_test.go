@@ -6,6 +6,7 @@ import (
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLicenseAgent_Name(t *testing.T) {
@@ -351,3 +352,221 @@ func TestLicenseAgent_extractVersionNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestLicenseAgent_Analyze_MultipleLicenses(t *testing.T) {
+	tests := []struct {
+		name             string
+		component        core.Component
+		expectedFindings []string
+	}{
+		{
+			name:             "Second declared license is high-risk",
+			component:        core.Component{Name: "multi", Version: "1.0.0", License: "MIT", Licenses: []string{"MIT", "GPL-3.0-only"}},
+			expectedFindings: []string{"GPL-3.0-only"},
+		},
+		{
+			name:             "Every high-risk license is reported",
+			component:        core.Component{Name: "multi", Version: "1.0.0", License: "AGPL-3.0-only", Licenses: []string{"AGPL-3.0-only", "MPL-2.0"}},
+			expectedFindings: []string{"AGPL-3.0-only", "MPL-2.0"},
+		},
+		{
+			name:             "OR expression with a permissive choice",
+			component:        core.Component{Name: "dual", Version: "1.0.0", Licenses: []string{"MIT OR GPL-3.0-only"}},
+			expectedFindings: nil,
+		},
+		{
+			name:             "OR expression with only copyleft choices",
+			component:        core.Component{Name: "dual", Version: "1.0.0", Licenses: []string{"GPL-2.0-only OR GPL-3.0-only"}},
+			expectedFindings: []string{"GPL-2.0-only"},
+		},
+		{
+			name:             "AND expression applies every license",
+			component:        core.Component{Name: "combined", Version: "1.0.0", Licenses: []string{"Apache-2.0 AND LGPL-2.1-only"}},
+			expectedFindings: []string{"LGPL-2.1-only"},
+		},
+		{
+			name:             "Parenthesized expression",
+			component:        core.Component{Name: "nested", Version: "1.0.0", Licenses: []string{"(MIT OR GPL-3.0-only) AND EPL-2.0"}},
+			expectedFindings: []string{"EPL-2.0"},
+		},
+		{
+			name:             "WITH exception evaluates the base license",
+			component:        core.Component{Name: "classpath", Version: "1.0.0", Licenses: []string{"GPL-2.0-only WITH Classpath-exception-2.0"}},
+			expectedFindings: []string{"GPL-2.0-only"},
+		},
+		{
+			name:             "Duplicate licenses reported once",
+			component:        core.Component{Name: "dupe", Version: "1.0.0", Licenses: []string{"GPL-3.0-only", "GPL-3.0-only"}},
+			expectedFindings: []string{"GPL-3.0-only"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := NewLicenseAgent()
+			sbom := core.SBOM{ID: "test", Components: []core.Component{tt.component}}
+
+			results, err := agent.Analyze(context.Background(), sbom)
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.expectedFindings), len(results))
+			for i, result := range results {
+				if i < len(tt.expectedFindings) {
+					assert.Contains(t, result.Finding, "'"+tt.expectedFindings[i]+"'")
+				}
+			}
+		})
+	}
+}
+
+func TestLicenseAgent_Analyze_Scopes(t *testing.T) {
+	sbom := core.SBOM{
+		ID: "test-scopes",
+		Components: []core.Component{
+			{Name: "runtime-lib", Version: "1.0.0", License: "GPL-3.0-only"},
+			{Name: "optional-lib", Version: "1.0.0", License: "GPL-3.0-only", Scope: core.ScopeOptional},
+			{Name: "test-lib", Version: "1.0.0", License: "GPL-3.0-only", Scope: core.ScopeExcluded},
+			{Name: "mock-lib", Version: "1.0.0", License: "GPL-3.0-only", Scope: "Excluded"},
+		},
+	}
+
+	t.Run("Excluded scope ignored by default", func(t *testing.T) {
+		results, err := NewLicenseAgent().Analyze(context.Background(), sbom)
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+		for _, result := range results {
+			assert.NotContains(t, result.Finding, "test-lib")
+			assert.NotContains(t, result.Finding, "mock-lib", "scopes are compared ignoring case")
+		}
+	})
+
+	t.Run("Custom ignored scopes", func(t *testing.T) {
+		agent := NewLicenseAgent()
+		agent.SetIgnoredScopes([]string{"optional", " Excluded "})
+
+		results, err := agent.Analyze(context.Background(), sbom)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Contains(t, results[0].Finding, "runtime-lib")
+	})
+
+	t.Run("No ignored scopes", func(t *testing.T) {
+		agent := NewLicenseAgent()
+		agent.SetIgnoredScopes(nil)
+
+		results, err := agent.Analyze(context.Background(), sbom)
+		assert.NoError(t, err)
+		assert.Len(t, results, 4)
+	})
+}
+
+func TestLicenseAgent_Analyze_ProjectContext(t *testing.T) {
+	sbom := core.SBOM{
+		ID:   "test-project",
+		Name: "admin-portal",
+		Components: []core.Component{
+			{Name: "agpl-lib", Version: "1.0.0", License: "AGPL-3.0-only"},
+			{Name: "gpl-lib", Version: "1.0.0", License: "GPL-2.0-or-later"},
+			{Name: "lgpl-lib", Version: "1.0.0", License: "LGPL-2.1-only"},
+			{Name: "mpl-lib", Version: "1.0.0", License: "MPL-2.0"},
+		},
+	}
+
+	severities := func(t *testing.T, agent *LicenseAgent) map[string]string {
+		results, err := agent.Analyze(context.Background(), sbom)
+		require.NoError(t, err)
+		bySeverity := make(map[string]string)
+		for _, result := range results {
+			bySeverity[result.Component.Name] = result.Severity
+		}
+		return bySeverity
+	}
+
+	tests := []struct {
+		name     string
+		projects map[string]core.ProjectContext
+		override core.ProjectContext
+		expected map[string]string
+	}{
+		{
+			name:     "No context",
+			expected: map[string]string{"agpl-lib": "Critical", "gpl-lib": "High", "lgpl-lib": "Medium", "mpl-lib": "Medium"},
+		},
+		{
+			name:     "Distributed binary",
+			projects: map[string]core.ProjectContext{"admin-portal": {Distribution: core.DistributionBinary}},
+			expected: map[string]string{"agpl-lib": "Critical", "gpl-lib": "High", "lgpl-lib": "Medium", "mpl-lib": "Medium"},
+		},
+		{
+			name:     "SaaS keeps network copyleft",
+			projects: map[string]core.ProjectContext{"admin-portal": {Distribution: core.DistributionSaaS}},
+			expected: map[string]string{"agpl-lib": "Critical", "gpl-lib": "Low", "lgpl-lib": "Low", "mpl-lib": "Low"},
+		},
+		{
+			name:     "Internal only",
+			projects: map[string]core.ProjectContext{"admin-portal": {Distribution: core.DistributionInternal}},
+			expected: map[string]string{"agpl-lib": "Low", "gpl-lib": "Low", "lgpl-lib": "Low", "mpl-lib": "Low"},
+		},
+		{
+			name:     "GPL-3.0 project covers compatible GNU licenses",
+			projects: map[string]core.ProjectContext{"admin-portal": {License: "GPL-3.0-only", Distribution: core.DistributionBinary}},
+			expected: map[string]string{"agpl-lib": "Critical", "gpl-lib": "Low", "lgpl-lib": "Medium", "mpl-lib": "Medium"},
+		},
+		{
+			name:     "Other projects are unaffected",
+			projects: map[string]core.ProjectContext{"build-tools": {Distribution: core.DistributionInternal}},
+			expected: map[string]string{"agpl-lib": "Critical", "gpl-lib": "High", "lgpl-lib": "Medium", "mpl-lib": "Medium"},
+		},
+		{
+			name:     "Override replaces the declared distribution",
+			projects: map[string]core.ProjectContext{"admin-portal": {License: "MPL-2.0", Distribution: core.DistributionBinary}},
+			override: core.ProjectContext{Distribution: core.DistributionSaaS},
+			expected: map[string]string{"agpl-lib": "Critical", "gpl-lib": "Low", "lgpl-lib": "Low", "mpl-lib": "Low"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := NewLicenseAgent()
+			agent.SetProjects(tt.projects)
+			agent.SetProjectOverride(tt.override)
+			assert.Equal(t, tt.expected, severities(t, agent))
+		})
+	}
+
+	t.Run("Finding explains the adjustment", func(t *testing.T) {
+		agent := NewLicenseAgent()
+		agent.SetProjectOverride(core.ProjectContext{Distribution: core.DistributionInternal})
+		results, err := agent.Analyze(context.Background(), sbom)
+		require.NoError(t, err)
+		assert.Contains(t, results[0].Finding, "Severity lowered to Low: internal-only use does not trigger its obligations.")
+	})
+}
+
+func TestLicenseCovers(t *testing.T) {
+	tests := []struct {
+		project   string
+		component string
+		expected  bool
+	}{
+		{"GPL-3.0-only", "GPL-3.0-only", true},
+		{"GPL-3.0-only", "gpl-3.0-only", true},
+		{"GPL-3.0-only", "GPL-3.0-or-later", true},
+		{"GPL-3.0-only", "GPL-2.0-or-later", true},
+		{"GPL-3.0-only", "GPL-2.0-only", false},
+		{"GPL-3.0-only", "LGPL-3.0-only", true},
+		{"GPL-2.0-only", "LGPL-2.1-only", true},
+		{"GPL-3.0-only", "AGPL-3.0-only", false},
+		{"AGPL-3.0-only", "GPL-3.0-only", true},
+		{"LGPL-2.1-only", "GPL-2.0-only", false},
+		{"MPL-2.0", "MPL-2.0", true},
+		{"MIT", "GPL-3.0-only", false},
+		{"Apache-2.0", "EPL-2.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.project+"/"+tt.component, func(t *testing.T) {
+			assert.Equal(t, tt.expected, licenseCovers(tt.project, tt.component))
+		})
+	}
+}
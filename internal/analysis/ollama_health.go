@@ -0,0 +1,156 @@
+// Package analysis provides the reachability check of the Ollama server
+// used by the AI-powered agents, and the preparation of their models.
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+)
+
+// ollamaTagsURL lists the models installed on the Ollama server of OLLAMA_HOST.
+var ollamaTagsURL = vectordb.OllamaURL("/api/tags")
+
+// CheckOllama verifies that the Ollama server answers and has the generation
+// model of OLLAMA_MODEL installed. It does not retry, so a probe reports the
+// server's current state.
+func CheckOllama(ctx context.Context) error {
+	installed, err := ollamaModels(ctx, ollamaTagsURL)
+	if err != nil {
+		return err
+	}
+
+	model := generationModelFromEnv()
+	if !modelInstalled(installed, model) {
+		return fmt.Errorf("model %q is not installed (run 'ollama pull %s')", model, model)
+	}
+	return nil
+}
+
+// OllamaModel is a model an AI-powered agent prompts.
+type OllamaModel struct {
+	// Name is the model as given to Ollama, such as "llama3"
+	Name string
+	// Embedding is set for models used for embeddings rather than generation
+	Embedding bool
+}
+
+// setting returns the environment variable that selects the model.
+func (m OllamaModel) setting() string {
+	if m.Embedding {
+		return "OLLAMA_EMBEDDING_MODEL"
+	}
+	return "OLLAMA_MODEL"
+}
+
+// PrepareOllamaModel makes sure the Ollama server at host can serve model.
+// A model that is not installed is pulled if pull is set and is an error
+// otherwise. Generation models are then loaded into memory, so the time the
+// server takes to load them is not spent by the first prompt. The errors say
+// how to fix the problem.
+func PrepareOllamaModel(ctx context.Context, host string, model OllamaModel, pull bool) error {
+	installed, err := ollamaModels(ctx, host+"/api/tags")
+	if err != nil {
+		return fmt.Errorf("Ollama is not reachable at %s: %w (start it with 'ollama serve' or set OLLAMA_HOST)", host, err)
+	}
+
+	if !modelInstalled(installed, model.Name) {
+		if !pull {
+			return fmt.Errorf("model %q is not installed on Ollama at %s (run 'ollama pull %s' or set %s to an installed model)", model.Name, host, model.Name, model.setting())
+		}
+		if err := ollamaRequest(ctx, host+"/api/pull", map[string]any{"model": model.Name, "stream": false}); err != nil {
+			return fmt.Errorf("failed to pull model %q: %w", model.Name, err)
+		}
+	}
+
+	if !model.Embedding {
+		// A generate request without a prompt only loads the model
+		if err := ollamaRequest(ctx, host+"/api/generate", map[string]any{"model": model.Name, "stream": false}); err != nil {
+			return fmt.Errorf("failed to load model %q: %w", model.Name, err)
+		}
+	}
+	return nil
+}
+
+// ollamaModels returns the names of the models installed on an Ollama server.
+func ollamaModels(ctx context.Context, tagsURL string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tagsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama API returned status %d", resp.StatusCode)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	names := make([]string, len(tags.Models))
+	for i, model := range tags.Models {
+		names[i] = model.Name
+	}
+	return names, nil
+}
+
+// modelInstalled reports whether model is among the installed models, which
+// are listed with their tag, such as "llama3:latest".
+func modelInstalled(installed []string, model string) bool {
+	for _, name := range installed {
+		if name == model || strings.TrimSuffix(name, ":latest") == model {
+			return true
+		}
+	}
+	return false
+}
+
+// ollamaRequest posts payload to an Ollama API endpoint that answers once
+// done, such as a pull or generate request without streaming.
+func ollamaRequest(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &failure) == nil && failure.Error != "" {
+			return fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, failure.Error)
+		}
+		return fmt.Errorf("Ollama API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package analysis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models": [{"name": "llama3:latest"}, {"name": "nomic-embed-text:v1.5"}]}`))
+	}))
+	defer server.Close()
+
+	original := ollamaTagsURL
+	ollamaTagsURL = server.URL + "/api/tags"
+	defer func() { ollamaTagsURL = original }()
+
+	t.Setenv("OLLAMA_MODEL", "")
+	assert.NoError(t, CheckOllama(context.Background()))
+
+	t.Setenv("OLLAMA_MODEL", "mistral")
+	assert.ErrorContains(t, CheckOllama(context.Background()), `model "mistral" is not installed`)
+
+	server.Close()
+	assert.ErrorContains(t, CheckOllama(context.Background()), "failed to reach Ollama")
+}
@@ -1,13 +1,69 @@
 // Package analysis provides shared types for Ollama API interactions.
 package analysis
 
-import "time"
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultOllamaModel is used for generation and embeddings unless configured otherwise.
+const defaultOllamaModel = "llama3"
+
+// generationModelFromEnv returns the Ollama model used for text generation,
+// configured through the OLLAMA_MODEL environment variable.
+func generationModelFromEnv() string {
+	if model := os.Getenv("OLLAMA_MODEL"); model != "" {
+		return model
+	}
+	return defaultOllamaModel
+}
+
+// embeddingModelFromEnv returns the Ollama model used for embeddings,
+// configured through the OLLAMA_EMBEDDING_MODEL environment variable.
+// A dedicated embedding model such as nomic-embed-text is much faster than
+// a generation model and produces better retrieval results.
+func embeddingModelFromEnv() string {
+	if model := os.Getenv("OLLAMA_EMBEDDING_MODEL"); model != "" {
+		return model
+	}
+	return defaultOllamaModel
+}
 
 // OllamaRequest represents the request structure for Ollama API.
 type OllamaRequest struct {
 	Model  string `json:"model"`
 	Prompt string `json:"prompt"`
 	Stream bool   `json:"stream"`
+	// Format is the JSON schema the response must conform to, if any
+	Format json.RawMessage `json:"format,omitempty"`
+}
+
+// findingFormat is the JSON schema of the structured output of the LLM
+// agents: a finding and the model's confidence in it, from 0 to 1.
+var findingFormat = json.RawMessage(`{"type":"object","properties":{"finding":{"type":"string"},"confidence":{"type":"number","minimum":0,"maximum":1}},"required":["finding","confidence"]}`)
+
+// llmFinding is the structured output of the LLM agents.
+type llmFinding struct {
+	Finding    string   `json:"finding"`
+	Confidence *float64 `json:"confidence"`
+}
+
+// parseLLMFinding returns the finding and confidence of an LLM response.
+// A response that is not structured output, such as from a model ignoring
+// the format, is the finding itself, without a confidence. Confidences
+// outside 0 to 1 are dropped.
+func parseLLMFinding(response string) (string, *float64) {
+	response = strings.TrimSpace(response)
+	var structured llmFinding
+	if err := json.Unmarshal([]byte(response), &structured); err != nil || strings.TrimSpace(structured.Finding) == "" {
+		return response, nil
+	}
+	if structured.Confidence != nil && (*structured.Confidence < 0 || *structured.Confidence > 1) {
+		structured.Confidence = nil
+	}
+	return strings.TrimSpace(structured.Finding), structured.Confidence
 }
 
 // OllamaResponse represents the response structure from Ollama API.
@@ -0,0 +1,236 @@
+// Package analysis provides concurrent orchestration of analysis agents.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/llmlimit"
+	"golang.org/x/sync/errgroup"
+)
+
+// Agent statuses reported by the Orchestrator.
+const (
+	AgentStatusOK      = "ok"
+	AgentStatusFailed  = "failed"
+	AgentStatusTimeout = "timeout"
+)
+
+// AgentRun describes how a single agent fared during an analysis.
+type AgentRun struct {
+	// Agent is the name of the agent
+	Agent string
+	// Status is AgentStatusOK, AgentStatusFailed or AgentStatusTimeout
+	Status string
+	// Err is the reason the agent failed, if it did
+	Err error
+	// Findings is the number of results the agent produced
+	Findings int
+	// Duration is how long the agent ran
+	Duration time.Duration
+	// LLMUsage is the LLM capacity the agent used, nil if it made no LLM
+	// requests
+	LLMUsage *LLMUsage
+}
+
+// OrchestratorReport is the outcome of running every agent against an SBOM.
+type OrchestratorReport struct {
+	// Results holds the findings of all agents, grouped in the order agents were added
+	Results []core.AnalysisResult
+	// Runs describes each agent in the order agents were added
+	Runs []AgentRun
+}
+
+// AgentsRun returns the names of the agents that were run.
+func (r *OrchestratorReport) AgentsRun() []string {
+	names := make([]string, len(r.Runs))
+	for i, run := range r.Runs {
+		names[i] = run.Agent
+	}
+	return names
+}
+
+// AgentStatus returns the status of each agent by name.
+func (r *OrchestratorReport) AgentStatus() map[string]string {
+	status := make(map[string]string, len(r.Runs))
+	for _, run := range r.Runs {
+		status[run.Agent] = run.Status
+	}
+	return status
+}
+
+// Failures returns the runs of the agents that failed or timed out, in the
+// order agents were added. Their findings are missing from Results.
+func (r *OrchestratorReport) Failures() []AgentRun {
+	var failures []AgentRun
+	for _, run := range r.Runs {
+		if run.Err != nil {
+			failures = append(failures, run)
+		}
+	}
+	return failures
+}
+
+// Orchestrator runs a set of analysis agents concurrently against an SBOM,
+// each within its time limit. A failing optional agent is recorded and the
+// analysis continues; a failing required agent cancels the others and fails
+// the analysis.
+type Orchestrator struct {
+	agents   []orchestratedAgent
+	timeouts AgentTimeouts
+	// concurrency bounds the agents running at once; 0 runs them all at once
+	concurrency int
+
+	// pullModels pulls missing Ollama models, and models holds the
+	// preparation of each model the agents use
+	pullModels bool
+	models     *modelProbes
+}
+
+// orchestratedAgent is an agent registered with the Orchestrator.
+type orchestratedAgent struct {
+	agent    AnalysisAgent
+	required bool
+}
+
+// NewOrchestrator creates an orchestrator enforcing the given agent time limits.
+func NewOrchestrator(timeouts AgentTimeouts) *Orchestrator {
+	return &Orchestrator{timeouts: timeouts, concurrency: agentConcurrencyFromEnv(), pullModels: pullModelsFromEnv(), models: newModelProbes()}
+}
+
+// agentConcurrencyFromEnv returns the limit on agents running at once set
+// by AGENT_CONCURRENCY, 0 (no limit) if unset or invalid.
+func agentConcurrencyFromEnv() int {
+	value := os.Getenv("AGENT_CONCURRENCY")
+	if value == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		fmt.Printf("Warning: Ignoring invalid AGENT_CONCURRENCY %q\n", value)
+		return 0
+	}
+	return limit
+}
+
+// SetConcurrency bounds the agents of an analysis that run at once; 0
+// removes the limit.
+func (o *Orchestrator) SetConcurrency(limit int) {
+	o.concurrency = max(limit, 0)
+}
+
+// AddRequired registers an agent whose failure fails the whole analysis.
+func (o *Orchestrator) AddRequired(agent AnalysisAgent) {
+	o.agents = append(o.agents, orchestratedAgent{agent: agent, required: true})
+}
+
+// Add registers an optional agent, whose failure is recorded but does not
+// stop the analysis.
+func (o *Orchestrator) Add(agent AnalysisAgent) {
+	o.agents = append(o.agents, orchestratedAgent{agent: agent})
+}
+
+// Agents returns the registered agents in the order they were added.
+func (o *Orchestrator) Agents() []AnalysisAgent {
+	agents := make([]AnalysisAgent, len(o.agents))
+	for i, registered := range o.agents {
+		agents[i] = registered.agent
+	}
+	return agents
+}
+
+// Parameters returns the effective settings of the registered parameterized
+// agents by agent name, or nil if there are none.
+func (o *Orchestrator) Parameters() map[string]map[string]string {
+	var parameters map[string]map[string]string
+	for _, registered := range o.agents {
+		agent := registered.agent
+		if deduplicated, ok := agent.(*dedupAgent); ok {
+			agent = deduplicated.ComponentAgent
+		}
+		if parameterized, ok := agent.(ParameterizedAgent); ok {
+			if parameters == nil {
+				parameters = make(map[string]map[string]string)
+			}
+			parameters[parameterized.Name()] = parameterized.Parameters()
+		}
+	}
+	return parameters
+}
+
+// Run executes every registered agent concurrently and waits for them all.
+// Agents prompting Ollama models start once their models are prepared, and
+// fail without running if the models are unavailable; see PrepareModels.
+// It returns an error if a required agent fails or ctx is cancelled, such as
+// when a client disconnects.
+func (o *Orchestrator) Run(ctx context.Context, sbom core.SBOM) (*OrchestratorReport, error) {
+	results := make([][]core.AnalysisResult, len(o.agents))
+	runs := make([]AgentRun, len(o.agents))
+
+	// The first failing required agent cancels the others through the
+	// group's context; optional agents never fail the group. The agents' LLM
+	// requests take turns with those of other analyses
+	group, groupCtx := errgroup.WithContext(llmlimit.NewJob(ctx))
+	if o.concurrency > 0 {
+		group.SetLimit(o.concurrency)
+	}
+	for i, registered := range o.agents {
+		group.Go(func() error {
+			agent := registered.agent
+			runnable := agent
+			if model, ok := modelAgent(agent); ok {
+				runnable = &preparedAgent{AnalysisAgent: agent, model: model, probes: o.models, pull: o.pullModels}
+			}
+			agentCtx, recorder := withUsageRecorder(groupCtx)
+			started := time.Now()
+			agentResults, err := RunAgent(agentCtx, runnable, o.timeouts.For(agent.Name()), sbom)
+
+			run := AgentRun{
+				Agent:    agent.Name(),
+				Status:   AgentStatusOK,
+				Findings: len(agentResults),
+				Duration: time.Since(started),
+				LLMUsage: recorder.Usage(),
+			}
+			if err != nil {
+				run.Status = AgentStatusFailed
+				if errors.Is(err, context.DeadlineExceeded) {
+					run.Status = AgentStatusTimeout
+				}
+				run.Err = err
+			}
+
+			recordRun(run)
+			results[i] = agentResults
+			runs[i] = run
+
+			if err != nil {
+				if registered.required {
+					return fmt.Errorf("%s failed: %w", agent.Name(), err)
+				}
+				// Log warning but don't fail the entire analysis
+				fmt.Printf("Warning: %s failed: %v\n", agent.Name(), err)
+			}
+			return nil
+		})
+	}
+	failure := group.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("analysis cancelled: %w", err)
+	}
+	if failure != nil {
+		return nil, failure
+	}
+
+	report := &OrchestratorReport{Runs: runs}
+	for _, agentResults := range results {
+		report.Results = append(report.Results, agentResults...)
+	}
+	return report, nil
+}
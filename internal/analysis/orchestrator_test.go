@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestrator_Run(t *testing.T) {
+	timeouts := NewAgentTimeouts()
+	timeouts.Set("Slow Agent", 20*time.Millisecond)
+
+	orchestrator := NewOrchestrator(timeouts)
+	orchestrator.AddRequired(&stubAgent{
+		name:    "License Agent",
+		delay:   50 * time.Millisecond,
+		results: []core.AnalysisResult{{AgentName: "License Agent", Finding: "first"}},
+	})
+	orchestrator.Add(&stubAgent{name: "Broken Agent", err: errors.New("boom")})
+	orchestrator.Add(&stubAgent{name: "Slow Agent", delay: time.Second})
+	orchestrator.Add(&stubAgent{
+		name:    "Quality Agent",
+		delay:   50 * time.Millisecond,
+		results: []core.AnalysisResult{{AgentName: "Quality Agent", Finding: "second"}},
+	})
+
+	started := time.Now()
+	report, err := orchestrator.Run(context.Background(), core.SBOM{})
+	require.NoError(t, err)
+
+	// Agents run concurrently, so the slowest completed agent bounds the run
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+
+	// Results keep the order agents were added in, whatever order they finish
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, "first", report.Results[0].Finding)
+	assert.Equal(t, "second", report.Results[1].Finding)
+
+	assert.Equal(t, []string{"License Agent", "Broken Agent", "Slow Agent", "Quality Agent"}, report.AgentsRun())
+	assert.Equal(t, map[string]string{
+		"License Agent": AgentStatusOK,
+		"Broken Agent":  AgentStatusFailed,
+		"Slow Agent":    AgentStatusTimeout,
+		"Quality Agent": AgentStatusOK,
+	}, report.AgentStatus())
+	assert.EqualError(t, report.Runs[1].Err, "boom")
+
+	failures := report.Failures()
+	require.Len(t, failures, 2)
+	assert.Equal(t, "Broken Agent", failures[0].Agent)
+	assert.Equal(t, "Slow Agent", failures[1].Agent)
+	assert.Equal(t, 1, report.Runs[0].Findings)
+}
+
+func TestOrchestrator_RequiredAgentFails(t *testing.T) {
+	orchestrator := NewOrchestrator(AgentTimeouts{})
+	orchestrator.AddRequired(&stubAgent{name: "License Agent", err: errors.New("boom")})
+	orchestrator.Add(&stubAgent{name: "Quality Agent"})
+
+	report, err := orchestrator.Run(context.Background(), core.SBOM{})
+	assert.Nil(t, report)
+	assert.EqualError(t, err, "License Agent failed: boom")
+}
+
+func TestOrchestrator_Cancelled(t *testing.T) {
+	orchestrator := NewOrchestrator(AgentTimeouts{})
+	orchestrator.Add(&stubAgent{name: "Slow Agent", delay: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	started := time.Now()
+	_, err := orchestrator.Run(ctx, core.SBOM{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+}
+
+func TestOrchestrator_Concurrency(t *testing.T) {
+	orchestrator := NewOrchestrator(AgentTimeouts{})
+	orchestrator.SetConcurrency(1)
+	orchestrator.Add(&stubAgent{name: "License Agent", delay: 50 * time.Millisecond})
+	orchestrator.Add(&stubAgent{name: "Quality Agent", delay: 50 * time.Millisecond})
+
+	started := time.Now()
+	report, err := orchestrator.Run(context.Background(), core.SBOM{})
+	require.NoError(t, err)
+
+	// One agent at a time, so the run takes as long as both agents
+	assert.GreaterOrEqual(t, time.Since(started), 100*time.Millisecond)
+	assert.Equal(t, []string{"License Agent", "Quality Agent"}, report.AgentsRun())
+}
+
+func TestAgentConcurrencyFromEnv(t *testing.T) {
+	t.Setenv("AGENT_CONCURRENCY", "3")
+	assert.Equal(t, 3, agentConcurrencyFromEnv())
+
+	t.Setenv("AGENT_CONCURRENCY", "-1")
+	assert.Equal(t, 0, agentConcurrencyFromEnv())
+}
@@ -0,0 +1,197 @@
+// Package analysis provides the mapping of operating system package PURLs
+// (deb, rpm and apk) to the OSV ecosystems of their distribution release.
+package analysis
+
+import (
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	packageurl "github.com/hueyexe/SBOM-Sentinel/internal/purl"
+)
+
+// distroPackage is an operating system package and the OSV ecosystems
+// holding the security advisories of its distribution release.
+type distroPackage struct {
+	ecosystems []string
+	name       string
+	version    string
+}
+
+// debianReleases maps Debian codenames to release numbers, for distro
+// qualifiers naming the release by codename.
+var debianReleases = map[string]string{
+	"stretch":  "9",
+	"buster":   "10",
+	"bullseye": "11",
+	"bookworm": "12",
+	"trixie":   "13",
+}
+
+// osNamespaces maps the names of operating-system components to the PURL
+// namespace of their packages.
+var osNamespaces = map[string]string{
+	"debian":    "debian",
+	"ubuntu":    "ubuntu",
+	"alpine":    "alpine",
+	"wolfi":     "wolfi",
+	"rhel":      "redhat",
+	"redhat":    "redhat",
+	"rocky":     "rocky",
+	"almalinux": "almalinux",
+}
+
+// distroPackageFromPURL derives the OSV ecosystems, name and version of an
+// operating system package from its PURL, e.g.
+// "pkg:deb/debian/libssl3@3.0.11-1~deb12u2?upstream=openssl&distro=debian-12".
+// Debian and Alpine advisories name source packages, so the upstream
+// qualifier is preferred for deb and apk packages. The release comes from the
+// distro qualifier. It reports false for other PURLs and for releases whose
+// advisories OSV does not hold.
+func distroPackageFromPURL(purl, version string) (distroPackage, bool) {
+	parsed, err := packageurl.Parse(purl)
+	if err != nil || parsed.Namespace == "" {
+		return distroPackage{}, false
+	}
+	qualifiers, namespace := parsed.Qualifiers, parsed.Namespace
+
+	pkg := distroPackage{name: parsed.Name, version: version}
+	if pkg.version == "" {
+		pkg.version = parsed.Version
+	}
+
+	// The distro qualifier is "<id>-<release>" or the bare release
+	release := qualifiers["distro"]
+	if i := strings.LastIndex(release, "-"); i >= 0 {
+		release = release[i+1:]
+	}
+	major, _, _ := strings.Cut(release, ".")
+
+	switch parsed.Type {
+	case "deb":
+		pkg.name = upstreamName(qualifiers["upstream"], parsed.Name)
+		switch namespace {
+		case "debian":
+			if number, ok := debianReleases[release]; ok {
+				major = number
+			}
+			if major != "" {
+				pkg.ecosystems = []string{"Debian:" + major}
+			}
+		case "ubuntu":
+			// Ubuntu advisories name long-term support releases (even years, April) as such
+			if release != "" {
+				ecosystem := "Ubuntu:" + release
+				if year, month, _ := strings.Cut(release, "."); month == "04" && len(year) == 2 && (year[1]-'0')%2 == 0 {
+					ecosystem += ":LTS"
+				}
+				pkg.ecosystems = []string{ecosystem}
+			}
+		}
+	case "apk":
+		pkg.name = upstreamName(qualifiers["upstream"], parsed.Name)
+		switch namespace {
+		case "alpine":
+			if parts := strings.Split(release, "."); len(parts) >= 2 {
+				pkg.ecosystems = []string{"Alpine:v" + parts[0] + "." + parts[1]}
+			}
+		case "wolfi":
+			pkg.ecosystems = []string{"Wolfi"}
+		case "chainguard":
+			pkg.ecosystems = []string{"Chainguard"}
+		}
+	case "rpm":
+		if epoch := qualifiers["epoch"]; epoch != "" && epoch != "0" && !strings.Contains(pkg.version, ":") {
+			pkg.version = epoch + ":" + pkg.version
+		}
+		if major == "" {
+			break
+		}
+		switch namespace {
+		case "redhat":
+			// Red Hat advisories are published per product stream
+			if major == "7" {
+				pkg.ecosystems = []string{"Red Hat:enterprise_linux:7::server"}
+			} else {
+				pkg.ecosystems = []string{"Red Hat:enterprise_linux:" + major + "::baseos", "Red Hat:enterprise_linux:" + major + "::appstream"}
+			}
+		case "rocky":
+			pkg.ecosystems = []string{"Rocky Linux:" + major}
+		case "almalinux":
+			pkg.ecosystems = []string{"AlmaLinux:" + major}
+		}
+	}
+
+	return pkg, len(pkg.ecosystems) > 0 && pkg.name != ""
+}
+
+// upstreamName returns the source package named by an upstream qualifier,
+// which may carry a version ("gcc-12@12.2.0-14"), or name if there is none.
+func upstreamName(upstream, name string) string {
+	upstream, _, _ = strings.Cut(upstream, "@")
+	upstream, _, _ = strings.Cut(upstream, " ")
+	if upstream = strings.TrimSpace(upstream); upstream != "" {
+		return upstream
+	}
+	return name
+}
+
+// distroMatcher accepts the OSV packages of a distro package in any of its ecosystems.
+func distroMatcher(pkg distroPackage) func(OSVPackage) bool {
+	return func(osvPackage OSVPackage) bool {
+		if !strings.EqualFold(osvPackage.Name, pkg.name) {
+			return false
+		}
+		for _, ecosystem := range pkg.ecosystems {
+			if strings.EqualFold(osvPackage.Ecosystem, ecosystem) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// osRelease is the distribution release of an SBOM's operating-system
+// component, by the PURL namespace of its packages.
+type osRelease struct {
+	namespace string
+	version   string
+}
+
+// sbomOSRelease returns the distribution release of the SBOM's
+// operating-system component, if it has one.
+func sbomOSRelease(sbom core.SBOM) (osRelease, bool) {
+	for _, component := range sbom.Components {
+		if component.Type != "operating-system" || component.Version == "" {
+			continue
+		}
+		if namespace, ok := osNamespaces[strings.ToLower(component.Name)]; ok {
+			return osRelease{namespace: namespace, version: component.Version}, true
+		}
+	}
+	return osRelease{}, false
+}
+
+// withOSRelease returns the component with the SBOM's distribution release
+// added to its PURL as the distro qualifier, if it is a package of that
+// distribution without one. Other components are returned unchanged.
+func withOSRelease(component core.Component, release osRelease) core.Component {
+	parsed, err := packageurl.Parse(component.PURL)
+	if err != nil || release.version == "" {
+		return component
+	}
+	switch parsed.Type {
+	case "deb", "rpm", "apk":
+	default:
+		return component
+	}
+	if !strings.EqualFold(parsed.Namespace, release.namespace) || parsed.Qualifiers["distro"] != "" {
+		return component
+	}
+
+	if parsed.Qualifiers == nil {
+		parsed.Qualifiers = make(map[string]string)
+	}
+	parsed.Qualifiers["distro"] = release.namespace + "-" + release.version
+	component.PURL = parsed.String()
+	return component
+}
@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistroPackageFromPURL(t *testing.T) {
+	tests := []struct {
+		purl       string
+		version    string
+		ecosystems []string
+		name       string
+		pkgVersion string
+	}{
+		{"pkg:deb/debian/libssl3@3.0.11-1~deb12u2?arch=amd64&upstream=openssl&distro=debian-12", "3.0.11-1~deb12u2", []string{"Debian:12"}, "openssl", "3.0.11-1~deb12u2"},
+		{"pkg:deb/debian/libgcc-s1@12.2.0-14?upstream=gcc-12%4012.2.0-14&distro=debian-12.4", "", []string{"Debian:12"}, "gcc-12", "12.2.0-14"},
+		{"pkg:deb/debian/bash@5.0-4?distro=debian-buster", "5.0-4", []string{"Debian:10"}, "bash", "5.0-4"},
+		{"pkg:deb/ubuntu/openssl@3.0.2-0ubuntu1.10?distro=ubuntu-22.04", "3.0.2-0ubuntu1.10", []string{"Ubuntu:22.04:LTS"}, "openssl", "3.0.2-0ubuntu1.10"},
+		{"pkg:deb/ubuntu/openssl@3.0.10-1ubuntu2?distro=ubuntu-23.10", "3.0.10-1ubuntu2", []string{"Ubuntu:23.10"}, "openssl", "3.0.10-1ubuntu2"},
+		{"pkg:apk/alpine/libcrypto3@3.1.4-r5?arch=x86_64&upstream=openssl&distro=alpine-3.19.1", "3.1.4-r5", []string{"Alpine:v3.19"}, "openssl", "3.1.4-r5"},
+		{"pkg:apk/alpine/busybox@1.36.1-r15?distro=3.19.1", "1.36.1-r15", []string{"Alpine:v3.19"}, "busybox", "1.36.1-r15"},
+		{"pkg:apk/wolfi/glibc@2.39-r1", "2.39-r1", []string{"Wolfi"}, "glibc", "2.39-r1"},
+		{"pkg:rpm/redhat/openssl-libs@3.0.7-25.el9_3?arch=x86_64&epoch=1&distro=rhel-9.3", "3.0.7-25.el9_3", []string{"Red Hat:enterprise_linux:9::baseos", "Red Hat:enterprise_linux:9::appstream"}, "openssl-libs", "1:3.0.7-25.el9_3"},
+		{"pkg:rpm/rocky/openssl@3.0.7-25.el9_3?distro=rocky-9.3", "3.0.7-25.el9_3", []string{"Rocky Linux:9"}, "openssl", "3.0.7-25.el9_3"},
+		{"pkg:rpm/almalinux/openssl@3.0.7-25.el9_3?distro=almalinux-9.3", "3.0.7-25.el9_3", []string{"AlmaLinux:9"}, "openssl", "3.0.7-25.el9_3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			pkg, ok := distroPackageFromPURL(tt.purl, tt.version)
+			require.True(t, ok)
+			assert.Equal(t, tt.ecosystems, pkg.ecosystems)
+			assert.Equal(t, tt.name, pkg.name)
+			assert.Equal(t, tt.pkgVersion, pkg.version)
+		})
+	}
+
+	for _, purl := range []string{
+		"pkg:npm/lodash@4.17.21",
+		"pkg:deb/debian/libssl3@3.0.11-1~deb12u2",
+		"pkg:rpm/fedora/openssl@3.1.1-4.fc39?distro=fedora-39",
+		"not a purl",
+	} {
+		_, ok := distroPackageFromPURL(purl, "")
+		assert.False(t, ok, purl)
+	}
+}
+
+func TestWithOSRelease(t *testing.T) {
+	release := osRelease{namespace: "debian", version: "12"}
+
+	component := withOSRelease(core.Component{PURL: "pkg:deb/debian/libssl3@3.0.11-1~deb12u2?arch=amd64&upstream=openssl"}, release)
+	assert.Equal(t, "pkg:deb/debian/libssl3@3.0.11-1~deb12u2?arch=amd64&distro=debian-12&upstream=openssl", component.PURL)
+
+	component = withOSRelease(core.Component{PURL: "pkg:deb/debian/bash@5.2.15-2+b2"}, release)
+	assert.Equal(t, "pkg:deb/debian/bash@5.2.15-2+b2?distro=debian-12", component.PURL)
+
+	// Declared releases, other distributions and other ecosystems are kept
+	for _, purl := range []string{
+		"pkg:deb/debian/bash@5.0-4?distro=debian-10",
+		"pkg:deb/ubuntu/bash@5.1-6ubuntu1",
+		"pkg:npm/lodash@4.17.21",
+	} {
+		assert.Equal(t, purl, withOSRelease(core.Component{PURL: purl}, release).PURL)
+	}
+}
+
+func TestVulnerabilityScanningAgent_Analyze_OSPackages(t *testing.T) {
+	mirror := &fakeOSVMirror{
+		ecosystems: map[string]bool{"Debian": true},
+		records: []string{`{
+			"id": "DSA-5532-1",
+			"summary": "openssl - security update",
+			"aliases": ["CVE-2023-5363"],
+			"affected": [{
+				"package": {"ecosystem": "Debian:12", "name": "openssl"},
+				"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.0.11-1~deb12u2"}]}]
+			}]
+		}`},
+	}
+
+	agent := NewVulnerabilityScanningAgent()
+	agent.SetMirror(mirror, true)
+
+	sbom := core.SBOM{
+		Components: []core.Component{
+			{Name: "debian", Version: "12", Type: "operating-system"},
+			{Name: "libssl3", Version: "3.0.11-1~deb12u1", PURL: "pkg:deb/debian/libssl3@3.0.11-1~deb12u1?arch=amd64&upstream=openssl"},
+			{Name: "openssl", Version: "3.0.11-1~deb12u2", PURL: "pkg:deb/debian/openssl@3.0.11-1~deb12u2?arch=amd64"},
+		},
+	}
+
+	results, err := agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "DSA-5532-1", results[0].VulnerabilityID)
+	assert.Equal(t, "libssl3", results[0].Component.Name)
+	assert.Equal(t, "pkg:deb/debian/libssl3@3.0.11-1~deb12u1?arch=amd64&upstream=openssl", results[0].Component.PURL)
+	assert.Equal(t, "3.0.11-1~deb12u2", results[0].FixedVersion)
+
+	// Matching uses the release declared by the PURL
+	vuln := OSVVulnerability{Affected: []OSVAffected{{
+		Package: OSVPackage{Ecosystem: "Debian:12", Name: "openssl"},
+		Ranges:  []OSVRange{{Type: "ECOSYSTEM", Events: []OSVEvent{{Introduced: "0"}, {Fixed: "3.0.11-1~deb12u2"}}}},
+	}}}
+	assert.True(t, agent.AffectsComponent(vuln, core.Component{Version: "3.0.9-1", PURL: "pkg:deb/debian/openssl@3.0.9-1?distro=debian-12"}))
+	assert.False(t, agent.AffectsComponent(vuln, core.Component{Version: "3.0.9-1", PURL: "pkg:deb/debian/openssl@3.0.9-1?distro=debian-11"}))
+}
+
+func TestVulnerabilityScanningAgent_determineSeverity_Ubuntu(t *testing.T) {
+	agent := NewVulnerabilityScanningAgent()
+	vuln := OSVVulnerability{Aliases: []string{"CVE-2024-0001"}}
+	vuln.Severity = append(vuln.Severity, struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	}{Type: "Ubuntu", Score: "negligible"})
+
+	assert.Equal(t, "Low", agent.determineSeverity(vuln))
+}
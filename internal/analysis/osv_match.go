@@ -0,0 +1,197 @@
+// Package analysis provides matching of components against OSV affected ranges.
+package analysis
+
+import (
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	packageurl "github.com/hueyexe/SBOM-Sentinel/internal/purl"
+	"github.com/hueyexe/SBOM-Sentinel/internal/versions"
+)
+
+// AffectsComponent reports whether the vulnerability's affected packages and
+// version ranges include the given component. Components are identified by
+// PURL, or failing that by CPE; components with neither cannot be matched
+// reliably and are never reported as affected.
+func (vsa *VulnerabilityScanningAgent) AffectsComponent(vuln OSVVulnerability, component core.Component) bool {
+	version, matches, ok := vsa.componentPackage(component)
+	if !ok {
+		return false
+	}
+	return affects(vuln, version, matches)
+}
+
+// componentPackage returns the version of a component and a predicate
+// accepting the OSV packages that identify it, by PURL or failing that by
+// CPE. It reports false for components with neither.
+func (vsa *VulnerabilityScanningAgent) componentPackage(component core.Component) (string, func(OSVPackage) bool, bool) {
+	if pkg, ok := distroPackageFromPURL(component.PURL, component.Version); ok {
+		return pkg.version, distroMatcher(pkg), true
+	}
+	if ecosystem, name := vsa.osvPackageFromPURL(component.PURL); ecosystem != "" && name != "" {
+		return component.Version, packageMatcher(ecosystem, name), true
+	}
+	if cpe, ok := component.ParsedCPE(); ok {
+		version := component.Version
+		if version == "" {
+			version = cpe.Version
+		}
+		return version, cpeMatcher(cpe), true
+	}
+	return "", nil, false
+}
+
+// affectsPackage reports whether the vulnerability affects the given version
+// of a package. An empty version cannot be ruled out and counts as affected.
+func affectsPackage(vuln OSVVulnerability, ecosystem, name, version string) bool {
+	return affects(vuln, version, packageMatcher(ecosystem, name))
+}
+
+// packageMatcher accepts the OSV package with the given ecosystem and name.
+func packageMatcher(ecosystem, name string) func(OSVPackage) bool {
+	return func(pkg OSVPackage) bool {
+		return strings.EqualFold(pkg.Ecosystem, ecosystem) && strings.EqualFold(pkg.Name, name)
+	}
+}
+
+// cpeMatcher accepts the OSV packages of the product a CPE names. CPEs carry
+// no package coordinates, so the product is compared with the OSV package
+// name, ignoring any Maven group or Go module path, within the ecosystem
+// implied by the CPE's target software.
+func cpeMatcher(cpe core.CPE) func(OSVPackage) bool {
+	ecosystem := cpeEcosystem(cpe)
+	product := normalizeCPEName(cpe.Product)
+
+	return func(pkg OSVPackage) bool {
+		if ecosystem != "" && !strings.EqualFold(pkg.Ecosystem, ecosystem) {
+			return false
+		}
+		name := normalizeCPEName(pkg.Name)
+		if idx := strings.LastIndexAny(name, ":/"); idx >= 0 {
+			return name == product || name[idx+1:] == product
+		}
+		return name == product
+	}
+}
+
+// affects reports whether the vulnerability affects the given version of any
+// package accepted by matches.
+func affects(vuln OSVVulnerability, version string, matches func(OSVPackage) bool) bool {
+	for _, affected := range vuln.Affected {
+		if !matches(affected.Package) {
+			continue
+		}
+
+		// Without a version we cannot rule the component out
+		if version == "" {
+			return true
+		}
+
+		ecosystem := affected.Package.Ecosystem
+		for _, affectedVersion := range affected.Versions {
+			if versions.Compare(ecosystem, affectedVersion, version) == 0 {
+				return true
+			}
+		}
+
+		for _, r := range affected.Ranges {
+			if versionInRange(ecosystem, version, r) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cpeEcosystem maps a CPE's target software to an OSV ecosystem, or returns
+// an empty string if the CPE does not name a package ecosystem.
+func cpeEcosystem(cpe core.CPE) string {
+	switch cpe.TargetSW {
+	case "node.js", "nodejs", "npm":
+		return "npm"
+	case "python", "pypi":
+		return "PyPI"
+	case "java", "maven":
+		return "Maven"
+	case "go", "golang":
+		return "Go"
+	case "rust", "cargo":
+		return "crates.io"
+	case ".net", "nuget":
+		return "NuGet"
+	case "php", "composer":
+		return "Packagist"
+	case "ruby", "rails":
+		return "RubyGems"
+	default:
+		return ""
+	}
+}
+
+// normalizeCPEName lower-cases a product or package name and treats
+// underscores, which CPE uses in place of dashes, as dashes.
+func normalizeCPEName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// osvPackageFromPURL derives the OSV ecosystem and package name from a PURL.
+// OSV names include the namespace in an ecosystem-specific way, e.g.
+// "group:artifact" for Maven and "@scope/name" for npm.
+func (vsa *VulnerabilityScanningAgent) osvPackageFromPURL(purl string) (string, string) {
+	ecosystem := vsa.extractEcosystemFromPURL(purl)
+	if ecosystem == "" {
+		return "", ""
+	}
+
+	parsed, _ := packageurl.Parse(purl)
+	switch ecosystem {
+	case "Maven":
+		return ecosystem, parsed.Namespace + ":" + parsed.Name
+	case "Go", "npm", "Packagist":
+		return ecosystem, parsed.FullName()
+	}
+
+	// Other ecosystems identify packages by name alone
+	return ecosystem, parsed.Name
+}
+
+// versionInRange evaluates an OSV SEMVER or ECOSYSTEM range against a version
+// of a package in ecosystem. SEMVER ranges use Semantic Versioning order and
+// ECOSYSTEM ranges the ecosystem's own order (e.g. PEP 440 for PyPI).
+// Events are processed in order: an "introduced" event opens the affected
+// interval and a "fixed" or "last_affected" event closes it.
+// GIT ranges refer to commits and cannot be evaluated against versions.
+func versionInRange(ecosystem, version string, r OSVRange) bool {
+	compare := func(a, b string) int { return versions.Compare(ecosystem, a, b) }
+	switch r.Type {
+	case "GIT":
+		return false
+	case "SEMVER":
+		compare = versions.CompareSemver
+	}
+
+	affected := false
+	for _, event := range r.Events {
+		switch {
+		case event.Introduced != "":
+			if event.Introduced == "0" || compare(version, event.Introduced) >= 0 {
+				affected = true
+			}
+		case event.Fixed != "":
+			if affected && compare(version, event.Fixed) >= 0 {
+				affected = false
+			}
+		case event.LastAffected != "":
+			if affected && compare(version, event.LastAffected) > 0 {
+				affected = false
+			}
+		case event.Limit != "":
+			if affected && compare(version, event.Limit) >= 0 {
+				affected = false
+			}
+		}
+	}
+
+	return affected
+}
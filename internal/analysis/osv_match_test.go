@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionInRange(t *testing.T) {
+	semver := OSVRange{
+		Type: "SEMVER",
+		Events: []OSVEvent{
+			{Introduced: "0"},
+			{Fixed: "1.2.0"},
+			{Introduced: "2.0.0"},
+			{LastAffected: "2.1.0"},
+		},
+	}
+
+	assert.True(t, versionInRange("npm", "1.0.0", semver))
+	assert.False(t, versionInRange("npm", "1.2.0", semver))
+	assert.False(t, versionInRange("npm", "1.5.0", semver))
+	assert.True(t, versionInRange("npm", "2.0.0", semver))
+	assert.True(t, versionInRange("npm", "2.1.0", semver))
+	assert.False(t, versionInRange("npm", "2.1.1", semver))
+
+	// ECOSYSTEM ranges follow the ecosystem's ordering
+	pypi := OSVRange{Type: "ECOSYSTEM", Events: []OSVEvent{{Introduced: "0"}, {Fixed: "2.0rc1"}}}
+	assert.True(t, versionInRange("PyPI", "2.0b3", pypi))
+	assert.False(t, versionInRange("PyPI", "2.0", pypi))
+	maven := OSVRange{Type: "ECOSYSTEM", Events: []OSVEvent{{Introduced: "2.0-beta9"}, {Fixed: "2.15.0"}}}
+	assert.True(t, versionInRange("Maven", "2.0-rc1", maven))
+	assert.False(t, versionInRange("Maven", "2.0-beta8", maven))
+
+	git := OSVRange{Type: "GIT", Events: []OSVEvent{{Introduced: "0"}}}
+	assert.False(t, versionInRange("npm", "1.0.0", git))
+}
+
+func TestVulnerabilityScanningAgent_osvPackageFromPURL(t *testing.T) {
+	agent := NewVulnerabilityScanningAgent()
+
+	tests := []struct {
+		purl              string
+		expectedEcosystem string
+		expectedName      string
+	}{
+		{"pkg:npm/lodash@4.17.20", "npm", "lodash"},
+		{"pkg:npm/%40babel/core@7.0.0", "npm", "@babel/core"},
+		{"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", "Maven", "org.apache.logging.log4j:log4j-core"},
+		{"pkg:golang/github.com/gin-gonic/gin@v1.9.0", "Go", "github.com/gin-gonic/gin"},
+		{"pkg:pypi/django@3.2.0?extra=x", "PyPI", "django"},
+		{"pkg:unknown/thing@1.0.0", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			ecosystem, name := agent.osvPackageFromPURL(tt.purl)
+			assert.Equal(t, tt.expectedEcosystem, ecosystem)
+			assert.Equal(t, tt.expectedName, name)
+		})
+	}
+}
+
+func TestVulnerabilityScanningAgent_AffectsComponent(t *testing.T) {
+	agent := NewVulnerabilityScanningAgent()
+	vuln := OSVVulnerability{
+		ID: "GHSA-jfh8-c2jp-5v3q",
+		Affected: []OSVAffected{
+			{
+				Package: OSVPackage{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"},
+				Ranges: []OSVRange{
+					{Type: "ECOSYSTEM", Events: []OSVEvent{{Introduced: "2.0-beta9"}, {Fixed: "2.15.0"}}},
+				},
+			},
+			{
+				Package:  OSVPackage{Ecosystem: "npm", Name: "lodash"},
+				Versions: []string{"4.17.20"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		component core.Component
+		expected  bool
+	}{
+		{"Version inside range", core.Component{Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"}, true},
+		{"Fixed version", core.Component{Version: "2.15.0", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.15.0"}, false},
+		{"Explicitly listed version", core.Component{Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20"}, true},
+		{"Unlisted version", core.Component{Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21"}, false},
+		{"Missing version", core.Component{PURL: "pkg:npm/lodash"}, true},
+		{"Different package", core.Component{Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-api@2.14.1"}, false},
+		{"No PURL", core.Component{Name: "lodash", Version: "4.17.20"}, false},
+		{"CPE inside range", core.Component{CPE: "cpe:2.3:a:apache:log4j-core:2.14.1:*:*:*:*:*:*:*"}, true},
+		{"CPE fixed version", core.Component{CPE: "cpe:2.3:a:apache:log4j-core:2.15.0:*:*:*:*:*:*:*"}, false},
+		{"CPE with target software", core.Component{Version: "4.17.20", CPE: "cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:node.js:*:*"}, true},
+		{"CPE for another ecosystem", core.Component{Version: "4.17.20", CPE: "cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:python:*:*"}, false},
+		{"CPE 2.2 URI", core.Component{CPE: "cpe:/a:apache:log4j_core:2.14.1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, agent.AffectsComponent(vuln, tt.component))
+		})
+	}
+}
+
+func TestVulnerabilityScanningAgent_FetchVulnerability(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vulns/CVE-2021-44228" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"GHSA-jfh8-c2jp-5v3q","aliases":["CVE-2021-44228"],"affected":[{"package":{"ecosystem":"Maven","name":"org.apache.logging.log4j:log4j-core"},"ranges":[{"type":"ECOSYSTEM","events":[{"introduced":"2.0-beta9"},{"fixed":"2.15.0"}]}]}]}`))
+	}))
+	defer server.Close()
+
+	agent := NewVulnerabilityScanningAgent()
+	agent.apiBaseURL = server.URL
+
+	vuln, err := agent.FetchVulnerability(context.Background(), "CVE-2021-44228")
+	require.NoError(t, err)
+	require.NotNil(t, vuln)
+	assert.Equal(t, "GHSA-jfh8-c2jp-5v3q", vuln.ID)
+	require.Len(t, vuln.Affected, 1)
+	assert.Equal(t, "2.15.0", vuln.Affected[0].Ranges[0].Events[1].Fixed)
+
+	missing, err := agent.FetchVulnerability(context.Background(), "CVE-0000-0000")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
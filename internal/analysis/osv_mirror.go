@@ -0,0 +1,113 @@
+// Package analysis provides lookups against a local OSV mirror.
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/osvdb"
+)
+
+// OSVMirror is a local copy of the OSV database, such as one maintained by
+// `sentinel-cli db sync`. Records are raw OSV JSON.
+type OSVMirror interface {
+	// HasEcosystem reports whether the mirror holds the given ecosystem
+	HasEcosystem(ctx context.Context, ecosystem string) (bool, error)
+
+	// Query returns the records affecting the named package
+	Query(ctx context.Context, ecosystem, name string) ([]json.RawMessage, error)
+
+	// Get returns the record with the given ID or alias, or nil if there is none
+	Get(ctx context.Context, id string) (json.RawMessage, error)
+}
+
+var (
+	defaultMirror     OSVMirror
+	defaultMirrorOnce sync.Once
+)
+
+// osvMirrorFromEnv returns the mirror at OSV_MIRROR_PATH, opened once and
+// shared by every agent in the process, and whether OSV_OFFLINE forbids
+// falling back to the OSV.dev API. The mirror is nil if none is configured
+// or it cannot be opened.
+func osvMirrorFromEnv() (OSVMirror, bool) {
+	defaultMirrorOnce.Do(func() {
+		path := os.Getenv("OSV_MIRROR_PATH")
+		if path == "" {
+			return
+		}
+
+		mirror, err := osvdb.Open(path)
+		if err != nil {
+			fmt.Printf("Warning: Failed to open OSV mirror '%s': %v\n", path, err)
+			return
+		}
+		defaultMirror = mirror
+	})
+
+	offline, _ := strconv.ParseBool(os.Getenv("OSV_OFFLINE"))
+	return defaultMirror, offline
+}
+
+// SetMirror makes the agent consult mirror before the OSV.dev API. Ecosystems
+// the mirror holds are answered from it alone; with offline set the API is
+// never used and components from other ecosystems cannot be scanned.
+// A nil mirror restores API-only scanning.
+func (vsa *VulnerabilityScanningAgent) SetMirror(mirror OSVMirror, offline bool) {
+	vsa.mirror = mirror
+	vsa.offline = offline
+}
+
+// queryMirror looks up the vulnerabilities affecting component, known in
+// ecosystem as name, in the mirror. The package name derived from the PURL
+// takes precedence. It reports false if the mirror does not hold the ecosystem.
+func (vsa *VulnerabilityScanningAgent) queryMirror(ctx context.Context, ecosystem, name string, component core.Component) ([]OSVVulnerability, bool, error) {
+	// Distribution ecosystems are synchronized as a whole, e.g. "Debian" for "Debian:12"
+	base, _, _ := strings.Cut(ecosystem, ":")
+	has, err := vsa.mirror.HasEcosystem(ctx, base)
+	if err != nil || !has {
+		return nil, false, err
+	}
+
+	if purlEcosystem, purlName := vsa.osvPackageFromPURL(component.PURL); purlEcosystem == ecosystem && purlName != "" {
+		name = purlName
+	}
+
+	records, err := vsa.mirror.Query(ctx, ecosystem, name)
+	if err != nil {
+		return nil, true, err
+	}
+
+	var vulns []OSVVulnerability
+	for _, record := range records {
+		var vuln OSVVulnerability
+		if err := json.Unmarshal(record, &vuln); err != nil {
+			return nil, true, fmt.Errorf("failed to decode OSV record: %w", err)
+		}
+		if affectsPackage(vuln, ecosystem, name, component.Version) {
+			vulns = append(vulns, vuln)
+		}
+	}
+	return vulns, true, nil
+}
+
+// fetchFromMirror looks up a vulnerability by ID or alias in the mirror,
+// returning nil if it holds no such record.
+func (vsa *VulnerabilityScanningAgent) fetchFromMirror(ctx context.Context, id string) (*OSVVulnerability, error) {
+	record, err := vsa.mirror.Get(ctx, id)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	var vuln OSVVulnerability
+	if err := json.Unmarshal(record, &vuln); err != nil {
+		return nil, fmt.Errorf("failed to decode OSV record: %w", err)
+	}
+	return &vuln, nil
+}
@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOSVMirror serves records for a fixed set of ecosystems.
+type fakeOSVMirror struct {
+	ecosystems map[string]bool
+	records    []string
+}
+
+func (f *fakeOSVMirror) HasEcosystem(ctx context.Context, ecosystem string) (bool, error) {
+	return f.ecosystems[ecosystem], nil
+}
+
+func (f *fakeOSVMirror) Query(ctx context.Context, ecosystem, name string) ([]json.RawMessage, error) {
+	var matches []json.RawMessage
+	for _, record := range f.records {
+		if strings.Contains(strings.ToLower(record), `"name": "`+strings.ToLower(name)+`"`) {
+			matches = append(matches, json.RawMessage(record))
+		}
+	}
+	return matches, nil
+}
+
+func (f *fakeOSVMirror) Get(ctx context.Context, id string) (json.RawMessage, error) {
+	for _, record := range f.records {
+		if strings.Contains(record, `"`+id+`"`) {
+			return json.RawMessage(record), nil
+		}
+	}
+	return nil, nil
+}
+
+func TestVulnerabilityScanningAgent_Mirror(t *testing.T) {
+	mirror := &fakeOSVMirror{
+		ecosystems: map[string]bool{"npm": true},
+		records: []string{`{
+			"id": "GHSA-jf85-cpcp-j695",
+			"summary": "Prototype Pollution in lodash",
+			"aliases": ["CVE-2019-10744"],
+			"affected": [{
+				"package": {"ecosystem": "npm", "name": "lodash"},
+				"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.12"}]}]
+			}]
+		}`},
+	}
+
+	var apiCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiCalls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"vulns": []}`))
+	}))
+	defer server.Close()
+
+	sbom := core.SBOM{Components: []core.Component{
+		{Name: "lodash", Version: "4.17.11", PURL: "pkg:npm/lodash@4.17.11"},
+		{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21"},
+		{Name: "requests", Version: "2.19.0", PURL: "pkg:pypi/requests@2.19.0"},
+	}}
+
+	t.Run("Mirrored ecosystems are answered locally", func(t *testing.T) {
+		apiCalls = 0
+		agent := NewVulnerabilityScanningAgent()
+		agent.apiBaseURL = server.URL
+		agent.SetMirror(mirror, false)
+
+		results, err := agent.Analyze(context.Background(), sbom)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Contains(t, results[0].Finding, "GHSA-jf85-cpcp-j695")
+		assert.Contains(t, results[0].Finding, "4.17.11")
+
+		// Only the unmirrored PyPI component reached the API
+		assert.Equal(t, 1, apiCalls)
+	})
+
+	t.Run("Offline mode never calls the API", func(t *testing.T) {
+		apiCalls = 0
+		agent := NewVulnerabilityScanningAgent()
+		agent.apiBaseURL = server.URL
+		agent.SetMirror(mirror, true)
+
+		results, err := agent.Analyze(context.Background(), sbom)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, 0, apiCalls)
+
+		vuln, err := agent.FetchVulnerability(context.Background(), "CVE-2019-10744")
+		require.NoError(t, err)
+		require.NotNil(t, vuln)
+		assert.Equal(t, "GHSA-jf85-cpcp-j695", vuln.ID)
+
+		vuln, err = agent.FetchVulnerability(context.Background(), "CVE-2099-0001")
+		require.NoError(t, err)
+		assert.Nil(t, vuln)
+		assert.Equal(t, 0, apiCalls)
+	})
+}
@@ -7,35 +7,134 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/llmlimit"
 	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
 )
 
 // ProactiveVulnerabilityAgent analyzes SBOM components for potential vulnerabilities using RAG.
 type ProactiveVulnerabilityAgent struct {
-	vectorDB    *vectordb.MemoryVectorDB
-	harvester   *vectordb.Harvester
-	ollamaURL   string
-	client      *http.Client
-	initialized bool
+	intelligence *vectordb.IntelligenceStore
+	ollamaURL    string
+	model        string
+	client       *http.Client
+	verifier     *FindingVerifier
+	prompt       *template.Template
+	// limiter queues the agent's LLM requests with those of other analyses
+	limiter *llmlimit.Limiter
+
+	// topK is the number of documents retrieved per component and
+	// similarityThreshold the minimum similarity for a document to be used
+	topK                int
+	similarityThreshold float64
 }
 
-// NewProactiveVulnerabilityAgent creates a new instance of ProactiveVulnerabilityAgent.
+// Default retrieval settings of the ProactiveVulnerabilityAgent.
+const (
+	DefaultRAGTopK                = 3
+	DefaultRAGSimilarityThreshold = 0.3
+)
+
+// NewProactiveVulnerabilityAgent creates a new instance of ProactiveVulnerabilityAgent
+// with its own intelligence corpus, configured by NewIntelligenceStoreFromEnv.
+// Long-running processes should share one corpus across analyses with
+// NewProactiveVulnerabilityAgentWithStore instead.
 func NewProactiveVulnerabilityAgent() *ProactiveVulnerabilityAgent {
-	vectorDB := vectordb.NewMemoryVectorDB()
+	return NewProactiveVulnerabilityAgentWithStore(NewIntelligenceStoreFromEnv())
+}
+
+// NewProactiveVulnerabilityAgentWithStore creates a ProactiveVulnerabilityAgent
+// that searches the given shared intelligence corpus.
+// The generation model defaults to OLLAMA_MODEL, retrieval to RAG_TOP_K
+// documents with a similarity above RAG_SIMILARITY_THRESHOLD, and the prompt
+// to the proactive-vulnerability.tmpl template in PROMPT_TEMPLATE_DIR.
+func NewProactiveVulnerabilityAgentWithStore(intelligence *vectordb.IntelligenceStore) *ProactiveVulnerabilityAgent {
+	agent := &ProactiveVulnerabilityAgent{
+		intelligence:        intelligence,
+		ollamaURL:           vectordb.OllamaURL("/api/generate"),
+		model:               generationModelFromEnv(),
+		client:              httpclient.New(60 * time.Second), // Longer timeout for RAG queries
+		verifier:            NewFindingVerifier(NewVulnerabilityScanningAgent()),
+		prompt:              promptTemplateFromEnv(ProactivePromptTemplate),
+		limiter:             llmlimit.Shared(),
+		topK:                DefaultRAGTopK,
+		similarityThreshold: DefaultRAGSimilarityThreshold,
+	}
+
+	if value := os.Getenv("RAG_TOP_K"); value != "" {
+		topK, err := strconv.Atoi(value)
+		if err != nil || topK < 1 {
+			fmt.Printf("Warning: Ignoring invalid RAG_TOP_K %q\n", value)
+		} else {
+			agent.SetTopK(topK)
+		}
+	}
+	if value := os.Getenv("RAG_SIMILARITY_THRESHOLD"); value != "" {
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			fmt.Printf("Warning: Ignoring invalid RAG_SIMILARITY_THRESHOLD %q\n", value)
+		} else {
+			agent.SetSimilarityThreshold(threshold)
+		}
+	}
+
+	return agent
+}
+
+// NewIntelligenceStoreFromEnv creates an intelligence corpus configured from the environment.
+// The vector store is selected by the VECTOR_DB environment variables
+// (see vectordb.ConfigFromEnv) and falls back to memory if it cannot be opened.
+// Intelligence sources and harvesting are configured as described in
+// vectordb.Harvester.ConfigureFromEnv; without any sources, built-in sample
+// data is used. Documents are embedded with OLLAMA_EMBEDDING_MODEL.
+func NewIntelligenceStoreFromEnv() *vectordb.IntelligenceStore {
+	vectorDB, err := vectordb.New(vectordb.ConfigFromEnv())
+	if err != nil {
+		fmt.Printf("Warning: Falling back to in-memory vector store: %v\n", err)
+		vectorDB = vectordb.NewMemoryVectorDB()
+	}
+
 	harvester := vectordb.NewHarvester(vectorDB)
+	harvester.SetEmbeddingModel(embeddingModelFromEnv())
+	if err := harvester.ConfigureFromEnv(); err != nil {
+		fmt.Printf("Warning: Invalid harvester configuration: %v\n", err)
+	}
+
+	return vectordb.NewIntelligenceStore(vectorDB, harvester)
+}
+
+// SetModel sets the Ollama model used to analyze retrieved intelligence.
+func (pva *ProactiveVulnerabilityAgent) SetModel(model string) {
+	pva.model = model
+}
+
+// SetPromptTemplate sets the template of the prompt asking about a component
+// and its retrieved documents, executed with ProactivePromptData.
+func (pva *ProactiveVulnerabilityAgent) SetPromptTemplate(prompt *template.Template) {
+	pva.prompt = prompt
+}
 
-	return &ProactiveVulnerabilityAgent{
-		vectorDB:  vectorDB,
-		harvester: harvester,
-		ollamaURL: "http://localhost:11434/api/generate",
-		client: &http.Client{
-			Timeout: 60 * time.Second, // Longer timeout for RAG queries
-		},
-		initialized: false,
+// SetTopK sets how many intelligence documents are retrieved per component.
+// Values below one are ignored.
+func (pva *ProactiveVulnerabilityAgent) SetTopK(topK int) {
+	if topK > 0 {
+		pva.topK = topK
+	}
+}
+
+// SetSimilarityThreshold sets the minimum similarity, between 0 and 1, a
+// retrieved document needs to be passed to the LLM. Values outside that
+// range are ignored.
+func (pva *ProactiveVulnerabilityAgent) SetSimilarityThreshold(threshold float64) {
+	if threshold >= 0 && threshold <= 1 {
+		pva.similarityThreshold = threshold
 	}
 }
 
@@ -44,19 +143,57 @@ func (pva *ProactiveVulnerabilityAgent) Name() string {
 	return "Proactive Vulnerability Agent"
 }
 
+// OllamaModels returns the Ollama server the agent prompts, its generation
+// model and the model embedding the intelligence corpus and its queries.
+func (pva *ProactiveVulnerabilityAgent) OllamaModels() (string, []OllamaModel) {
+	return strings.TrimSuffix(pva.ollamaURL, "/api/generate"), []OllamaModel{
+		{Name: pva.model},
+		{Name: pva.intelligence.EmbeddingModel(), Embedding: true},
+	}
+}
+
+// Parameters returns the models and retrieval settings the agent runs with.
+func (pva *ProactiveVulnerabilityAgent) Parameters() map[string]string {
+	return map[string]string{
+		"model":                pva.model,
+		"embedding_model":      pva.intelligence.EmbeddingModel(),
+		"top_k":                strconv.Itoa(pva.topK),
+		"similarity_threshold": strconv.FormatFloat(pva.similarityThreshold, 'f', -1, 64),
+	}
+}
+
 // Analyze examines the SBOM components for potential vulnerabilities using RAG pipeline.
 func (pva *ProactiveVulnerabilityAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
-	// Initialize the vector database with mock security data if not already done
-	if !pva.initialized {
-		if err := pva.initializeSecurityIntelligence(ctx); err != nil {
-			return nil, fmt.Errorf("failed to initialize security intelligence: %w", err)
+	return analyzeEachComponent(ctx, pva, sbom)
+}
+
+// ComponentKeys identifies each component with a name and version, the
+// components the agent searches intelligence for.
+func (pva *ProactiveVulnerabilityAgent) ComponentKeys(sbom core.SBOM) []string {
+	keys := make([]string, len(sbom.Components))
+	for i, component := range sbom.Components {
+		if component.Name != "" && component.Version != "" {
+			keys[i] = componentKey(component)
 		}
-		pva.initialized = true
 	}
+	return keys
+}
 
-	var results []core.AnalysisResult
+// AnalyzeComponents searches the intelligence corpus for each of the given
+// components of sbom and asks the LLM about the relevant documents.
+// Components whose search or LLM query fails are logged and skipped.
+func (pva *ProactiveVulnerabilityAgent) AnalyzeComponents(ctx context.Context, sbom core.SBOM, components []core.Component, found func(i int, results []core.AnalysisResult)) error {
+	// Harvest the shared corpus on first use
+	if err := pva.intelligence.EnsureInitialized(ctx); err != nil {
+		return fmt.Errorf("failed to initialize security intelligence: %w", err)
+	}
+
+	for i, component := range components {
+		// Stop querying once the caller has gone away or the time limit is up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	for _, component := range sbom.Components {
 		// Skip components without name or version
 		if component.Name == "" || component.Version == "" {
 			continue
@@ -71,88 +208,115 @@ func (pva *ProactiveVulnerabilityAgent) Analyze(ctx context.Context, sbom core.S
 		}
 
 		// Search for relevant security documents
-		searchResults, err := pva.vectorDB.Search(queryEmbedding, 3) // Top 3 most relevant
+		searchResults, err := pva.intelligence.Search(queryEmbedding, pva.topK)
 		if err != nil {
 			fmt.Printf("Warning: Failed to search vector DB for component '%s': %v\n", component.Name, err)
 			continue
 		}
 
 		// Filter for relevant results with sufficient similarity
+		var relevantResults []vectordb.SearchResult
 		var relevantDocs []vectordb.Document
 		for _, result := range searchResults {
-			if result.Similarity > 0.3 { // Only consider documents with >30% similarity
+			if result.Similarity > pva.similarityThreshold {
+				relevantResults = append(relevantResults, result)
 				relevantDocs = append(relevantDocs, result.Document)
 			}
 		}
 
 		// If relevant documents found, query LLM for analysis
-		if len(relevantDocs) > 0 {
-			finding, err := pva.analyzeWithLLM(ctx, component, relevantDocs)
-			if err != nil {
-				fmt.Printf("Warning: Failed LLM analysis for component '%s': %v\n", component.Name, err)
-				continue
-			}
-
-			if finding != "" {
-				result := core.AnalysisResult{
-					AgentName: pva.Name(),
-					Finding:   finding,
-					Severity:  "Medium", // RAG-discovered vulnerabilities are typically medium severity
-				}
-				results = append(results, result)
-			}
+		if len(relevantDocs) == 0 {
+			found(i, nil)
+			continue
+		}
+		finding, confidence, err := pva.analyzeWithLLM(ctx, component, relevantDocs)
+		if err != nil {
+			fmt.Printf("Warning: Failed LLM analysis for component '%s': %v\n", component.Name, err)
+			continue
+		}
+		if finding == "" {
+			found(i, nil)
+			continue
 		}
-	}
 
-	return results, nil
-}
+		// A finding must cite the retrieved documents it is based on,
+		// otherwise it cannot be verified and is discarded
+		citations := citationsFor(finding, relevantResults)
+		if len(citations) == 0 {
+			fmt.Printf("Warning: Discarding uncited finding for component '%s'\n", component.Name)
+			found(i, nil)
+			continue
+		}
 
-// initializeSecurityIntelligence populates the vector database with security intelligence data.
-func (pva *ProactiveVulnerabilityAgent) initializeSecurityIntelligence(ctx context.Context) error {
-	fmt.Println("🔍 Initializing security intelligence database...")
+		result := core.AnalysisResult{
+			AgentName:  pva.Name(),
+			Finding:    finding,
+			Severity:   "Medium", // RAG-discovered vulnerabilities are typically medium severity
+			Citations:  citations,
+			Component:  component.Ref(),
+			Confidence: confidence,
+		}
 
-	if err := pva.harvester.HarvestMockData(ctx); err != nil {
-		return fmt.Errorf("failed to harvest security data: %w", err)
+		// Cross-check the claims against the retrieved documents and OSV.dev
+		evidence := make([]string, len(relevantDocs))
+		for j, doc := range relevantDocs {
+			evidence[j] = doc.ID + " " + doc.Text
+		}
+		var results []core.AnalysisResult
+		if result, keep := pva.verifier.Verify(ctx, result, component, evidence); keep {
+			results = append(results, result)
+		}
+		found(i, results)
 	}
 
-	fmt.Printf("✅ Security intelligence database initialized with %d documents\n", pva.vectorDB.Size())
 	return nil
 }
 
-// analyzeWithLLM uses the LLM to analyze component against relevant security documents.
-func (pva *ProactiveVulnerabilityAgent) analyzeWithLLM(ctx context.Context, component core.Component, docs []vectordb.Document) (string, error) {
-	// Build context from relevant documents
-	var contextBuilder strings.Builder
-	contextBuilder.WriteString("Security Intelligence Context:\n")
-
-	for i, doc := range docs {
-		contextBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, doc.Text))
+// analyzeWithLLM uses the LLM to analyze component against relevant security
+// documents, returning its finding, if any, and its confidence in it.
+func (pva *ProactiveVulnerabilityAgent) analyzeWithLLM(ctx context.Context, component core.Component, docs []vectordb.Document) (string, *float64, error) {
+	prompt, err := renderPrompt(pva.prompt, ProactivePromptData{Component: component, Documents: docs})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate prompt: %w", err)
 	}
 
-	// Create prompt for LLM
-	prompt := fmt.Sprintf(`Based on the security intelligence context provided, analyze if the component '%s' version '%s' has any potential security vulnerabilities or risks.
-
-%s
-
-Component to analyze: %s (version %s)
-
-Instructions:
-1. Look for any mentions of this specific component or similar components
-2. Consider version compatibility and potential security issues
-3. If you find relevant security concerns, summarize them in one sentence
-4. If no relevant security issues are found, respond with "No relevant security concerns identified"
+	response, err := pva.queryLLM(ctx, prompt)
+	if err != nil || response == "" {
+		return "", nil, err
+	}
+	finding, confidence := parseLLMFinding(response)
+	return finding, confidence, nil
+}
 
-Response:`, component.Name, component.Version, contextBuilder.String(), component.Name, component.Version)
+// citationsFor returns citations for the retrieved documents referenced by
+// ID in an LLM finding, in retrieval order.
+func citationsFor(finding string, results []vectordb.SearchResult) []core.Citation {
+	var citations []core.Citation
+	for _, result := range results {
+		doc := result.Document
+		if doc.ID == "" || !strings.Contains(finding, doc.ID) {
+			continue
+		}
 
-	return pva.queryLLM(ctx, prompt)
+		citation := core.Citation{
+			ID:         doc.ID,
+			Similarity: result.Similarity,
+		}
+		citation.Title, _ = doc.Metadata["title"].(string)
+		citation.Source, _ = doc.Metadata["source"].(string)
+		citation.URL, _ = doc.Metadata["url"].(string)
+		citations = append(citations, citation)
+	}
+	return citations
 }
 
 // queryLLM sends a query to the LLM and returns the response.
 func (pva *ProactiveVulnerabilityAgent) queryLLM(ctx context.Context, prompt string) (string, error) {
 	reqPayload := OllamaRequest{
-		Model:  "llama3",
+		Model:  pva.model,
 		Prompt: prompt,
 		Stream: false,
+		Format: findingFormat,
 	}
 
 	reqBody, err := json.Marshal(reqPayload)
@@ -167,6 +331,13 @@ func (pva *ProactiveVulnerabilityAgent) queryLLM(ctx context.Context, prompt str
 
 	req.Header.Set("Content-Type", "application/json")
 
+	// Wait for a turn at the LLM shared with other analyses
+	release, err := pva.limiter.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	resp, err := pva.client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request to Ollama: %w", err)
@@ -181,6 +352,7 @@ func (pva *ProactiveVulnerabilityAgent) queryLLM(ctx context.Context, prompt str
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
+	recordLLMUsage(ctx, ollamaResp)
 
 	response := strings.TrimSpace(ollamaResp.Response)
 
@@ -197,7 +369,7 @@ func (pva *ProactiveVulnerabilityAgent) queryLLM(ctx context.Context, prompt str
 // generateEmbedding generates an embedding for the given text using Ollama.
 func (pva *ProactiveVulnerabilityAgent) generateEmbedding(ctx context.Context, text string) ([]float64, error) {
 	reqPayload := OllamaEmbeddingRequest{
-		Model:  "llama3",
+		Model:  pva.intelligence.EmbeddingModel(),
 		Prompt: text,
 	}
 
@@ -206,13 +378,20 @@ func (pva *ProactiveVulnerabilityAgent) generateEmbedding(ctx context.Context, t
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "http://localhost:11434/api/embeddings", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", vectordb.OllamaURL("/api/embeddings"), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
+	// Wait for a turn at the LLM shared with other analyses
+	release, err := pva.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	resp, err := pva.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
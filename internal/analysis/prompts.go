@@ -0,0 +1,117 @@
+// Package analysis provides the prompt templates of the LLM-backed agents,
+// which can be replaced to tune their wording and output constraints.
+package analysis
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+)
+
+// File names of the prompt templates, both built in and in
+// PROMPT_TEMPLATE_DIR.
+const (
+	HealthPromptTemplate    = "dependency-health.tmpl"
+	ProactivePromptTemplate = "proactive-vulnerability.tmpl"
+)
+
+// builtinPrompts holds the default prompt templates.
+//
+//go:embed prompts/*.tmpl
+var builtinPrompts embed.FS
+
+// HealthPromptData is the data of the dependency health prompt template.
+type HealthPromptData struct {
+	Component core.Component
+	// Repository is the web address of the component's source repository,
+	// from its vcs external reference, or "" if the SBOM does not give one
+	Repository string
+}
+
+// ProactivePromptData is the data of the proactive vulnerability prompt
+// template. Documents holds at least one retrieved document.
+type ProactivePromptData struct {
+	Component core.Component
+	Documents []vectordb.Document
+}
+
+// samplePromptData is the data a template is tried with when it is loaded,
+// so that references to unknown fields are reported up front.
+var samplePromptData = map[string]interface{}{
+	HealthPromptTemplate: HealthPromptData{
+		Component:  core.Component{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20"},
+		Repository: "https://github.com/lodash/lodash",
+	},
+	ProactivePromptTemplate: ProactivePromptData{
+		Component: core.Component{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20"},
+		Documents: []vectordb.Document{{ID: "GHSA-p6mc-m468-83gw", Text: "Prototype Pollution in lodash"}},
+	},
+}
+
+// BuiltinPromptTemplate returns the source of the named built-in prompt
+// template, as a starting point for a replacement.
+func BuiltinPromptTemplate(name string) ([]byte, error) {
+	return builtinPrompts.ReadFile("prompts/" + name)
+}
+
+// LoadPromptTemplate parses the named prompt template from dir, or the
+// built-in one if dir is empty or has no file of that name. Templates use
+// Go text/template syntax; see HealthPromptData and ProactivePromptData for
+// the fields available.
+func LoadPromptTemplate(dir, name string) (*template.Template, error) {
+	sample, ok := samplePromptData[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt template %q", name)
+	}
+
+	source, err := BuiltinPromptTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	path := "built-in " + name
+	if dir != "" {
+		custom, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			source, path = custom, filepath.Join(dir, name)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+	}
+
+	tmpl, err := template.New(name).Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template '%s': %w", path, err)
+	}
+	if _, err := renderPrompt(tmpl, sample); err != nil {
+		return nil, fmt.Errorf("invalid prompt template '%s': %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// promptTemplateFromEnv returns the named prompt template from the directory
+// PROMPT_TEMPLATE_DIR, falling back to the built-in one if it is invalid.
+func promptTemplateFromEnv(name string) *template.Template {
+	tmpl, err := LoadPromptTemplate(os.Getenv("PROMPT_TEMPLATE_DIR"), name)
+	if err != nil {
+		fmt.Printf("Warning: Using the built-in prompt: %v\n", err)
+		tmpl, _ = LoadPromptTemplate("", name)
+	}
+	return tmpl
+}
+
+// renderPrompt executes a prompt template, trimming surrounding whitespace.
+func renderPrompt(tmpl *template.Template, data interface{}) (string, error) {
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(prompt.String()), nil
+}
@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPromptTemplate_Builtin(t *testing.T) {
+	tmpl, err := LoadPromptTemplate("", ProactivePromptTemplate)
+	require.NoError(t, err)
+
+	prompt, err := renderPrompt(tmpl, ProactivePromptData{
+		Component: core.Component{Name: "lodash", Version: "4.17.20"},
+		Documents: []vectordb.Document{{ID: "doc1", Text: "Prototype pollution"}, {ID: "doc2", Text: "ReDoS"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Security Intelligence Context:\n[doc1] Prototype pollution\n[doc2] ReDoS\n\n\nComponent to analyze: lodash (version 4.17.20)")
+	assert.Contains(t, prompt, "for example [doc1]")
+	assert.True(t, len(prompt) > 0 && prompt[len(prompt)-1] == ':')
+
+	// A directory without the template falls back to the built-in one
+	fallback, err := LoadPromptTemplate(t.TempDir(), HealthPromptTemplate)
+	require.NoError(t, err)
+	prompt, err = renderPrompt(fallback, HealthPromptData{Component: core.Component{Name: "lodash", Version: "4.17.20"}})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "component 'lodash' version '4.17.20'")
+
+	_, err = LoadPromptTemplate("", "unknown.tmpl")
+	assert.Error(t, err)
+}
+
+func TestLoadPromptTemplate_Custom(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, HealthPromptTemplate), []byte(content), 0o644))
+	}
+
+	write("Is {{.Component.Name}} ({{.Component.PURL}}) abandoned? Answer yes or no.\n")
+	tmpl, err := LoadPromptTemplate(dir, HealthPromptTemplate)
+	require.NoError(t, err)
+
+	agent := NewDependencyHealthAgent()
+	agent.SetPromptTemplate(tmpl)
+	prompt, err := agent.generatePrompt(core.Component{Name: "left-pad", PURL: "pkg:npm/left-pad@1.3.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "Is left-pad (pkg:npm/left-pad@1.3.0) abandoned? Answer yes or no.", prompt)
+
+	// Syntax errors and unknown fields are reported when loading
+	write("Is {{.Component.Name abandoned?")
+	_, err = LoadPromptTemplate(dir, HealthPromptTemplate)
+	assert.ErrorContains(t, err, "failed to parse prompt template")
+
+	write("Is {{.Component.Homepage}} abandoned?")
+	_, err = LoadPromptTemplate(dir, HealthPromptTemplate)
+	assert.ErrorContains(t, err, "invalid prompt template")
+}
+
+func TestPromptTemplateFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, HealthPromptTemplate), []byte("Assess {{.Component.Name}}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ProactivePromptTemplate), []byte("{{.Unknown}}"), 0o644))
+	t.Setenv("PROMPT_TEMPLATE_DIR", dir)
+
+	prompt, err := renderPrompt(promptTemplateFromEnv(HealthPromptTemplate), HealthPromptData{Component: core.Component{Name: "lodash"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Assess lodash", prompt)
+
+	// An invalid template is replaced by the built-in one
+	prompt, err = renderPrompt(promptTemplateFromEnv(ProactivePromptTemplate), samplePromptData[ProactivePromptTemplate])
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Security Intelligence Context")
+}
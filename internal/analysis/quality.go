@@ -0,0 +1,161 @@
+// Package analysis provides SBOM quality scoring functionality.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// QualityReport contains the quality score of an SBOM and the gaps found.
+type QualityReport struct {
+	// Score is the overall quality score from 0 to 100
+	Score int `json:"score"`
+
+	// Gaps lists each missing NTIA minimum element or completeness issue
+	Gaps []string `json:"gaps"`
+}
+
+// qualityCheck is a single weighted check contributing to the quality score.
+// Coverage is the fraction (0.0 to 1.0) of the check that is satisfied.
+type qualityCheck struct {
+	weight   float64
+	coverage float64
+	gap      string
+}
+
+// QualityAgent scores SBOMs against the NTIA minimum elements and
+// component completeness metrics.
+type QualityAgent struct{}
+
+// NewQualityAgent creates a new instance of QualityAgent.
+func NewQualityAgent() *QualityAgent {
+	return &QualityAgent{}
+}
+
+// Name returns the identifier for this analysis agent.
+func (qa *QualityAgent) Name() string {
+	return "SBOM Quality Agent"
+}
+
+// Analyze scores the SBOM and returns one finding with the overall score
+// followed by one finding per gap identified.
+func (qa *QualityAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	report := qa.Score(sbom)
+
+	results := []core.AnalysisResult{
+		{
+			AgentName: qa.Name(),
+			Finding:   fmt.Sprintf("SBOM quality score: %d/100 (%d gaps identified)", report.Score, len(report.Gaps)),
+			Severity:  qa.determineSeverity(report.Score),
+		},
+	}
+
+	for _, gap := range report.Gaps {
+		results = append(results, core.AnalysisResult{
+			AgentName: qa.Name(),
+			Finding:   gap,
+			Severity:  "Low",
+		})
+	}
+
+	return results, nil
+}
+
+// Score computes the quality report for the given SBOM.
+// Document-level elements (serial number, timestamp, author) and
+// component-level elements (name, version, supplier, PURL, license)
+// are weighted to a total of 100 points.
+func (qa *QualityAgent) Score(sbom core.SBOM) QualityReport {
+	total := len(sbom.Components)
+
+	var withName, withVersion, withSupplier, withLicense int
+	purls := make(map[string]int)
+	for _, component := range sbom.Components {
+		if component.Name != "" {
+			withName++
+		}
+		if component.Version != "" {
+			withVersion++
+		}
+		if component.Supplier != "" {
+			withSupplier++
+		}
+		if component.PURL != "" {
+			purls[component.PURL]++
+		}
+		if len(component.DeclaredLicenses()) > 0 {
+			withLicense++
+		}
+	}
+
+	// Components sharing a PURL are not uniquely identified
+	uniquePURLs := 0
+	for _, count := range purls {
+		if count == 1 {
+			uniquePURLs++
+		}
+	}
+
+	checks := []qualityCheck{
+		{weight: 5, coverage: boolCoverage(sbom.Metadata["serialNumber"] != ""), gap: "SBOM has no unique serial number"},
+		{weight: 10, coverage: boolCoverage(sbom.Metadata["timestamp"] != ""), gap: "SBOM has no creation timestamp"},
+		{weight: 10, coverage: boolCoverage(sbom.Metadata["authors"] != ""), gap: "SBOM has no author information"},
+		{weight: 10, coverage: fractionCoverage(withName, total), gap: componentGap(total-withName, total, "a name")},
+		{weight: 15, coverage: fractionCoverage(withVersion, total), gap: componentGap(total-withVersion, total, "a version")},
+		{weight: 15, coverage: fractionCoverage(withSupplier, total), gap: componentGap(total-withSupplier, total, "supplier information")},
+		{weight: 20, coverage: fractionCoverage(uniquePURLs, total), gap: componentGap(total-uniquePURLs, total, "a unique PURL")},
+		{weight: 15, coverage: fractionCoverage(withLicense, total), gap: componentGap(total-withLicense, total, "license information")},
+	}
+
+	var report QualityReport
+	var score float64
+	for _, check := range checks {
+		score += check.weight * check.coverage
+		if check.coverage < 1.0 {
+			report.Gaps = append(report.Gaps, check.gap)
+		}
+	}
+
+	report.Score = int(math.Round(score))
+	return report
+}
+
+// determineSeverity maps a quality score to a severity level.
+func (qa *QualityAgent) determineSeverity(score int) string {
+	switch {
+	case score >= 80:
+		return "Low"
+	case score >= 50:
+		return "Medium"
+	default:
+		return "High"
+	}
+}
+
+// boolCoverage converts a satisfied/unsatisfied check into a coverage fraction.
+func boolCoverage(satisfied bool) float64 {
+	if satisfied {
+		return 1.0
+	}
+	return 0.0
+}
+
+// fractionCoverage returns count/total. An SBOM without components cannot
+// satisfy any component-level element, so an empty total is no coverage.
+func fractionCoverage(count, total int) float64 {
+	if total == 0 {
+		return 0.0
+	}
+	return float64(count) / float64(total)
+}
+
+// componentGap describes how many components are missing the given element.
+func componentGap(missing, total int, element string) string {
+	if total == 0 {
+		return fmt.Sprintf("SBOM has no components, so none have %s", element)
+	}
+	return fmt.Sprintf("%d of %d components (%.0f%%) are missing %s", missing, total, float64(missing)*100/float64(total), element)
+}
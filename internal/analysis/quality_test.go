@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualityAgent_Name(t *testing.T) {
+	agent := NewQualityAgent()
+	assert.Equal(t, "SBOM Quality Agent", agent.Name())
+}
+
+func TestQualityAgent_Score(t *testing.T) {
+	tests := []struct {
+		name          string
+		sbom          core.SBOM
+		expectedScore int
+		expectedGaps  []string
+	}{
+		{
+			name: "Complete SBOM",
+			sbom: core.SBOM{
+				Components: []core.Component{
+					{Name: "lib-a", Version: "1.0.0", PURL: "pkg:npm/lib-a@1.0.0", License: "MIT", Supplier: "Acme"},
+					{Name: "lib-b", Version: "2.0.0", PURL: "pkg:npm/lib-b@2.0.0", License: "MIT", Supplier: "Acme"},
+				},
+				Metadata: map[string]string{"serialNumber": "urn:uuid:complete", "timestamp": "2024-01-01T00:00:00Z", "authors": "Acme"},
+			},
+			expectedScore: 100,
+			expectedGaps:  nil,
+		},
+		{
+			name: "Half of components incomplete",
+			sbom: core.SBOM{
+				Components: []core.Component{
+					{Name: "lib-a", Version: "1.0.0", PURL: "pkg:npm/lib-a@1.0.0", License: "MIT", Supplier: "Acme"},
+					{Name: "lib-b", Version: "2.0.0"},
+				},
+				Metadata: map[string]string{"serialNumber": "urn:uuid:partial", "timestamp": "2024-01-01T00:00:00Z"},
+			},
+			expectedScore: 65,
+			expectedGaps: []string{
+				"SBOM has no author information",
+				"1 of 2 components (50%) are missing supplier information",
+				"1 of 2 components (50%) are missing a unique PURL",
+				"1 of 2 components (50%) are missing license information",
+			},
+		},
+		{
+			name: "Duplicate PURLs are not unique identifiers",
+			sbom: core.SBOM{
+				Components: []core.Component{
+					{Name: "lib-a", Version: "1.0.0", PURL: "pkg:npm/lib-a@1.0.0", License: "MIT", Supplier: "Acme"},
+					{Name: "lib-a", Version: "1.0.0", PURL: "pkg:npm/lib-a@1.0.0", License: "MIT", Supplier: "Acme"},
+				},
+				Metadata: map[string]string{"serialNumber": "urn:uuid:dupes", "timestamp": "2024-01-01T00:00:00Z", "authors": "Acme"},
+			},
+			expectedScore: 80,
+			expectedGaps:  []string{"2 of 2 components (100%) are missing a unique PURL"},
+		},
+		{
+			name:          "Empty SBOM",
+			sbom:          core.SBOM{},
+			expectedScore: 0,
+			expectedGaps: []string{
+				"SBOM has no unique serial number",
+				"SBOM has no creation timestamp",
+				"SBOM has no author information",
+				"SBOM has no components, so none have a name",
+				"SBOM has no components, so none have a version",
+				"SBOM has no components, so none have supplier information",
+				"SBOM has no components, so none have a unique PURL",
+				"SBOM has no components, so none have license information",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := NewQualityAgent()
+			report := agent.Score(tt.sbom)
+
+			assert.Equal(t, tt.expectedScore, report.Score)
+			assert.Equal(t, tt.expectedGaps, report.Gaps)
+		})
+	}
+}
+
+func TestQualityAgent_Analyze(t *testing.T) {
+	agent := NewQualityAgent()
+	sbom := core.SBOM{
+		Components: []core.Component{
+			{Name: "lib-a", Version: "1.0.0"},
+		},
+		Metadata: map[string]string{"serialNumber": "urn:uuid:test"},
+	}
+
+	results, err := agent.Analyze(context.Background(), sbom)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 6)
+	assert.Equal(t, "SBOM quality score: 30/100 (5 gaps identified)", results[0].Finding)
+	assert.Equal(t, "High", results[0].Severity)
+
+	for _, result := range results {
+		assert.Equal(t, "SBOM Quality Agent", result.AgentName)
+	}
+	for _, result := range results[1:] {
+		assert.Equal(t, "Low", result.Severity)
+	}
+}
+
+func TestQualityAgent_determineSeverity(t *testing.T) {
+	agent := NewQualityAgent()
+
+	assert.Equal(t, "Low", agent.determineSeverity(100))
+	assert.Equal(t, "Low", agent.determineSeverity(80))
+	assert.Equal(t, "Medium", agent.determineSeverity(79))
+	assert.Equal(t, "Medium", agent.determineSeverity(50))
+	assert.Equal(t, "High", agent.determineSeverity(49))
+}
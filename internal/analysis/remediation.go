@@ -0,0 +1,97 @@
+// Package analysis provides remediation advice for known vulnerabilities,
+// derived from the fixed versions in OSV affected ranges.
+package analysis
+
+import (
+	"sort"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/versions"
+)
+
+// remediation describes how to fix the known vulnerabilities of one component.
+type remediation struct {
+	// fixed maps vulnerability IDs to the nearest version that fixes them
+	fixed map[string]string
+	// recommended is the nearest version fixing all of the component's known
+	// vulnerabilities, or "" if there is none
+	recommended string
+}
+
+// remediate computes the fixed versions of the vulnerabilities affecting
+// version of the package accepted by matches, and the nearest version that
+// fixes them all. Vulnerabilities introduced after the recommended version
+// are not known here, so it is a suggestion rather than a guarantee.
+func remediate(vulns []OSVVulnerability, version string, matches func(OSVPackage) bool) remediation {
+	result := remediation{fixed: make(map[string]string)}
+	if version == "" {
+		return result
+	}
+
+	ecosystem := ""
+	var candidates []string
+	for _, vuln := range vulns {
+		fixed, fixEcosystem := nearestFix(vuln, version, matches)
+		if fixed == "" {
+			continue
+		}
+		result.fixed[vuln.ID] = fixed
+		candidates = append(candidates, fixed)
+		if ecosystem == "" {
+			ecosystem = fixEcosystem
+		}
+	}
+
+	// The nearest candidate that none of the vulnerabilities affect fixes them all
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return versions.Compare(ecosystem, candidates[i], candidates[j]) < 0
+	})
+	for _, candidate := range candidates {
+		safe := true
+		for _, vuln := range vulns {
+			if affects(vuln, candidate, matches) {
+				safe = false
+				break
+			}
+		}
+		if safe {
+			result.recommended = candidate
+			break
+		}
+	}
+
+	return result
+}
+
+// nearestFix returns the lowest version above version in which the
+// vulnerability is fixed, and the ecosystem of the package it was found for,
+// or empty strings if no fix is known. GIT ranges are skipped, as their
+// events are commits rather than versions.
+func nearestFix(vuln OSVVulnerability, version string, matches func(OSVPackage) bool) (string, string) {
+	nearest, nearestEcosystem := "", ""
+	for _, affected := range vuln.Affected {
+		if !matches(affected.Package) {
+			continue
+		}
+
+		ecosystem := affected.Package.Ecosystem
+		for _, r := range affected.Ranges {
+			compare := func(a, b string) int { return versions.Compare(ecosystem, a, b) }
+			switch r.Type {
+			case "GIT":
+				continue
+			case "SEMVER":
+				compare = versions.CompareSemver
+			}
+
+			for _, event := range r.Events {
+				if event.Fixed == "" || compare(event.Fixed, version) <= 0 {
+					continue
+				}
+				if nearest == "" || compare(event.Fixed, nearest) < 0 {
+					nearest, nearestEcosystem = event.Fixed, ecosystem
+				}
+			}
+		}
+	}
+	return nearest, nearestEcosystem
+}
@@ -0,0 +1,118 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// log4jVulns are simplified records of the Log4Shell series, each fixed in
+// the 2.x line and, for the first two, backported to 2.12.
+var log4jVulns = []OSVVulnerability{
+	{
+		ID: "GHSA-jfh8-c2jp-5v3q",
+		Affected: []OSVAffected{{
+			Package: OSVPackage{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"},
+			Ranges: []OSVRange{{Type: "ECOSYSTEM", Events: []OSVEvent{
+				{Introduced: "2.0-beta9"}, {Fixed: "2.3.1"},
+				{Introduced: "2.4"}, {Fixed: "2.12.2"},
+				{Introduced: "2.13.0"}, {Fixed: "2.15.0"},
+			}}},
+		}},
+	},
+	{
+		ID: "GHSA-7rjr-3q55-vv33",
+		Affected: []OSVAffected{{
+			Package: OSVPackage{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"},
+			Ranges: []OSVRange{{Type: "ECOSYSTEM", Events: []OSVEvent{
+				{Introduced: "2.13.0"}, {Fixed: "2.16.0"},
+			}}},
+		}},
+	},
+	{
+		ID: "GHSA-p6xc-xr62-6r2g",
+		Affected: []OSVAffected{{
+			Package: OSVPackage{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"},
+			Ranges: []OSVRange{{Type: "ECOSYSTEM", Events: []OSVEvent{
+				{Introduced: "2.13.0"}, {Fixed: "2.17.0"},
+			}}},
+		}},
+	},
+}
+
+func TestRemediate(t *testing.T) {
+	matches := packageMatcher("Maven", "org.apache.logging.log4j:log4j-core")
+
+	fixes := remediate(log4jVulns, "2.14.1", matches)
+	assert.Equal(t, map[string]string{
+		"GHSA-jfh8-c2jp-5v3q": "2.15.0",
+		"GHSA-7rjr-3q55-vv33": "2.16.0",
+		"GHSA-p6xc-xr62-6r2g": "2.17.0",
+	}, fixes.fixed)
+	assert.Equal(t, "2.17.0", fixes.recommended)
+
+	// The nearest fix is on the component's own release line
+	fixes = remediate(log4jVulns[:1], "2.10.0", matches)
+	assert.Equal(t, "2.12.2", fixes.fixed["GHSA-jfh8-c2jp-5v3q"])
+	assert.Equal(t, "2.12.2", fixes.recommended)
+
+	// Nothing can be suggested without a version or a fix
+	assert.Empty(t, remediate(log4jVulns, "", matches).fixed)
+	unfixed := OSVVulnerability{ID: "OSV-1", Affected: []OSVAffected{{
+		Package: OSVPackage{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"},
+		Ranges:  []OSVRange{{Type: "ECOSYSTEM", Events: []OSVEvent{{Introduced: "0"}}}},
+	}}}
+	fixes = remediate(append([]OSVVulnerability{unfixed}, log4jVulns...), "2.14.1", matches)
+	assert.NotContains(t, fixes.fixed, "OSV-1")
+	assert.Equal(t, "2.17.0", fixes.fixed["GHSA-p6xc-xr62-6r2g"])
+	assert.Empty(t, fixes.recommended)
+}
+
+func TestNearestFix_Semver(t *testing.T) {
+	vuln := OSVVulnerability{Affected: []OSVAffected{
+		{
+			Package: OSVPackage{Ecosystem: "npm", Name: "lodash"},
+			Ranges: []OSVRange{
+				{Type: "GIT", Events: []OSVEvent{{Introduced: "0"}, {Fixed: "abc123"}}},
+				{Type: "SEMVER", Events: []OSVEvent{{Introduced: "0"}, {Fixed: "4.17.21"}}},
+			},
+		},
+		{
+			Package: OSVPackage{Ecosystem: "npm", Name: "lodash-es"},
+			Ranges:  []OSVRange{{Type: "SEMVER", Events: []OSVEvent{{Introduced: "0"}, {Fixed: "4.17.20"}}}},
+		},
+	}}
+
+	fixed, ecosystem := nearestFix(vuln, "4.17.15", packageMatcher("npm", "lodash"))
+	assert.Equal(t, "4.17.21", fixed)
+	assert.Equal(t, "npm", ecosystem)
+}
+
+func TestVulnerabilityScanningAgent_AnalyzeRemediation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OSVQueryResponse{Vulns: log4jVulns[:2]})
+	}))
+	defer server.Close()
+
+	agent := NewVulnerabilityScanningAgent()
+	agent.apiBaseURL = server.URL
+
+	sbom := core.SBOM{Components: []core.Component{
+		{Name: "log4j-core", Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+	}}
+	results, err := agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "2.15.0", results[0].FixedVersion)
+	assert.Equal(t, "2.16.0", results[0].UpgradeTo)
+	assert.Equal(t, "Upgrade to 2.16.0 (this vulnerability is fixed in 2.15.0)", results[0].Remediation())
+	assert.Equal(t, "2.16.0", results[1].FixedVersion)
+	assert.Equal(t, "Upgrade to 2.16.0", results[1].Remediation())
+}
@@ -0,0 +1,234 @@
+// Package analysis provides a deterministic health check of components
+// from the activity of their source repositories on GitHub and GitLab.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/cache"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/scm"
+)
+
+// Thresholds of the repository activity check.
+const (
+	// AbandonedAfter is how long a repository without commits is
+	// considered abandoned
+	AbandonedAfter = 2 * 365 * 24 * time.Hour
+	// InactiveAfter is how long a repository without commits is
+	// considered inactive, which together with a backlog of open issues or
+	// a single contributor suggests it is no longer maintained
+	InactiveAfter = 365 * 24 * time.Hour
+	// BacklogRatio is the share of open issues from which a repository is
+	// considered to have a backlog, once it has BacklogMinIssues issues
+	BacklogRatio     = 0.5
+	BacklogMinIssues = 10
+)
+
+// RepositoryHost looks up the activity of a source repository by its web
+// address.
+type RepositoryHost interface {
+	Activity(ctx context.Context, repository string) (*scm.Activity, error)
+}
+
+// cachedRepositoryHost is a RepositoryHost keeping the lookups of another
+// in a cache, so that components from the same repository query its host
+// once. Failed lookups are not cached.
+type cachedRepositoryHost struct {
+	host  RepositoryHost
+	cache *cache.Cache
+}
+
+// Activity returns the cached activity of repository or looks it up.
+func (c cachedRepositoryHost) Activity(ctx context.Context, repository string) (*scm.Activity, error) {
+	var activity scm.Activity
+	if c.cache.GetJSON(ctx, cache.SourceRepository, repository, &activity) {
+		return &activity, nil
+	}
+	found, err := c.host.Activity(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.SetJSON(ctx, cache.SourceRepository, repository, found)
+	return found, nil
+}
+
+// RepositoryActivityAgent flags components whose source repository on
+// GitHub or GitLab is archived or shows no recent development, from the
+// repository's last commit, open issues and contributors. Unlike the
+// Dependency Health Agent it needs no LLM, and every finding states the
+// facts it is based on.
+type RepositoryActivityAgent struct {
+	host RepositoryHost
+	now  func() time.Time
+}
+
+// NewRepositoryActivityAgent creates a RepositoryActivityAgent querying the
+// public GitHub and GitLab APIs, authenticated by GITHUB_TOKEN and
+// GITLAB_TOKEN if set. Lookups are kept in the shared cache configured by
+// the CACHE_* variables.
+func NewRepositoryActivityAgent() *RepositoryActivityAgent {
+	var host RepositoryHost = scm.ActivityClientFromEnv()
+	if shared := cache.Shared(); shared != nil {
+		host = cachedRepositoryHost{host: host, cache: shared}
+	}
+	return NewRepositoryActivityAgentWithHost(host)
+}
+
+// NewRepositoryActivityAgentWithHost creates a RepositoryActivityAgent that
+// looks up repositories in the given host.
+func NewRepositoryActivityAgentWithHost(host RepositoryHost) *RepositoryActivityAgent {
+	return &RepositoryActivityAgent{host: host, now: core.Now}
+}
+
+// Name returns the identifier for this analysis agent.
+func (ra *RepositoryActivityAgent) Name() string {
+	return "Repository Activity Agent"
+}
+
+// Analyze checks the source repositories of the SBOM's components.
+func (ra *RepositoryActivityAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	return analyzeEachComponent(ctx, ra, sbom)
+}
+
+// ComponentKeys identifies each component with a source repository by the
+// component and its repository. Components without one are skipped.
+func (ra *RepositoryActivityAgent) ComponentKeys(sbom core.SBOM) []string {
+	keys := make([]string, len(sbom.Components))
+	for i, component := range sbom.Components {
+		if repository := component.RepositoryURL(); repository != "" {
+			keys[i] = componentKey(component) + "\x00" + repository
+		}
+	}
+	return keys
+}
+
+// AnalyzeComponents checks the source repositories of the given components
+// of sbom. An archived repository is rated High, one without commits for
+// AbandonedAfter Medium, and one without commits for InactiveAfter that has
+// a backlog of open issues or a single contributor Low. Repositories that
+// are not on GitHub or GitLab are skipped, and failed lookups are logged
+// and skipped.
+func (ra *RepositoryActivityAgent) AnalyzeComponents(ctx context.Context, sbom core.SBOM, components []core.Component, found func(i int, results []core.AnalysisResult)) error {
+	// Components built from the same repository are assessed from one lookup
+	activities := make(map[string]*scm.Activity)
+	failed := make(map[string]bool)
+
+	for i, component := range components {
+		// Stop querying once the caller has gone away or the time limit is up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		repository := component.RepositoryURL()
+		if repository == "" || failed[repository] {
+			continue
+		}
+
+		activity, ok := activities[repository]
+		if !ok {
+			var err error
+			activity, err = ra.host.Activity(ctx, repository)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				if !errors.Is(err, scm.ErrUnsupportedHost) {
+					fmt.Printf("Warning: Failed to look up the activity of repository %s: %v\n", repository, err)
+				}
+				failed[repository] = true
+				continue
+			}
+			activities[repository] = activity
+		}
+
+		var results []core.AnalysisResult
+		if rating, ok := ra.assess(*activity); ok {
+			results = append(results, core.AnalysisResult{
+				AgentName: ra.Name(),
+				Finding:   fmt.Sprintf("Repository %s %s (%s). %s", activity.Repository, rating.assessment, activityEvidence(*activity), rating.advice),
+				Severity:  rating.severity,
+				Component: component.Ref(),
+				Citations: []core.Citation{{ID: activity.Repository, Title: "Source repository", URL: activity.Repository}},
+			})
+		}
+		found(i, results)
+	}
+
+	return nil
+}
+
+// repositoryAssessment is how a repository's activity is rated.
+type repositoryAssessment struct {
+	severity   string
+	assessment string
+	advice     string
+}
+
+// assess rates the activity of a repository, returning false if it is
+// actively maintained.
+func (ra *RepositoryActivityAgent) assess(activity scm.Activity) (repositoryAssessment, bool) {
+	if activity.Archived {
+		return repositoryAssessment{core.SeverityHigh, "is archived and no longer receives fixes", "Replace the component with a maintained alternative."}, true
+	}
+
+	if activity.LastCommit.IsZero() {
+		return repositoryAssessment{}, false
+	}
+	idle := ra.now().Sub(activity.LastCommit)
+	if idle >= AbandonedAfter {
+		return repositoryAssessment{core.SeverityMedium, fmt.Sprintf("appears abandoned: no commits for %s", idleFor(idle)),
+			"Plan a move to a maintained alternative or fork."}, true
+	}
+
+	if idle >= InactiveAfter {
+		backlog := activity.OpenIssues+activity.ClosedIssues >= BacklogMinIssues && activity.OpenIssueRatio() >= BacklogRatio
+		if backlog || activity.Contributors == 1 {
+			var signs []string
+			if backlog {
+				signs = append(signs, "most issues left open")
+			}
+			if activity.Contributors == 1 {
+				signs = append(signs, "a single contributor")
+			}
+			return repositoryAssessment{core.SeverityLow,
+				fmt.Sprintf("may be unmaintained: no commits for %s, with %s", idleFor(idle), strings.Join(signs, " and ")),
+				"Check whether the project is still maintained."}, true
+		}
+	}
+	return repositoryAssessment{}, false
+}
+
+// activityEvidence lists the facts a finding on the repository is based on.
+func activityEvidence(activity scm.Activity) string {
+	var evidence []string
+	if !activity.LastCommit.IsZero() {
+		evidence = append(evidence, "last commit "+activity.LastCommit.UTC().Format("2006-01-02"))
+	}
+	if total := activity.OpenIssues + activity.ClosedIssues; total > 0 {
+		evidence = append(evidence, fmt.Sprintf("%d of %d issues open (%d%%)", activity.OpenIssues, total, int(math.Round(activity.OpenIssueRatio()*100))))
+	} else {
+		evidence = append(evidence, "no issues")
+	}
+	if activity.Contributors == 1 {
+		evidence = append(evidence, "1 contributor")
+	} else {
+		evidence = append(evidence, fmt.Sprintf("%d contributors", activity.Contributors))
+	}
+	return strings.Join(evidence, ", ")
+}
+
+// idleFor describes how long a repository has had no commits, in months
+// or, from two years, in years.
+func idleFor(idle time.Duration) string {
+	months := int(idle.Hours() / 24 / 30)
+	if months >= 24 {
+		return fmt.Sprintf("%d years", months/12)
+	}
+	return fmt.Sprintf("%d months", months)
+}
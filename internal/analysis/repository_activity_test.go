@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/cache"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/scm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepositoryHost serves the activity of repositories by web address
+// and counts the lookups.
+type fakeRepositoryHost struct {
+	activities map[string]scm.Activity
+	lookups    int
+}
+
+func (f *fakeRepositoryHost) Activity(ctx context.Context, repository string) (*scm.Activity, error) {
+	f.lookups++
+	activity, ok := f.activities[repository]
+	if !ok {
+		return nil, scm.ErrUnsupportedHost
+	}
+	activity.Repository = repository
+	return &activity, nil
+}
+
+func repositoryComponent(name, repository string) core.Component {
+	return core.Component{Name: name, Version: "1.0.0", ExternalReferences: []core.ExternalReference{{Type: core.ReferenceVCS, URL: repository}}}
+}
+
+func TestRepositoryActivityAgent_Analyze(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	host := &fakeRepositoryHost{activities: map[string]scm.Activity{
+		"https://github.com/acme/archived":  {Archived: true, LastCommit: now.AddDate(0, -3, 0), OpenIssues: 4, ClosedIssues: 12, Contributors: 9},
+		"https://github.com/acme/abandoned": {LastCommit: now.AddDate(-3, 0, 0), Contributors: 2},
+		"https://gitlab.com/acme/idle":      {LastCommit: now.AddDate(0, -15, 0), OpenIssues: 30, ClosedIssues: 10, Contributors: 1},
+		"https://github.com/acme/quiet":     {LastCommit: now.AddDate(0, -15, 0), OpenIssues: 2, ClosedIssues: 40, Contributors: 6},
+		"https://github.com/acme/active":    {LastCommit: now.AddDate(0, 0, -2), OpenIssues: 300, ClosedIssues: 10, Contributors: 1},
+	}}
+	agent := NewRepositoryActivityAgentWithHost(host)
+	agent.now = func() time.Time { return now }
+
+	sbom := core.SBOM{Components: []core.Component{
+		repositoryComponent("archived", "git+https://github.com/acme/archived.git"),
+		repositoryComponent("abandoned", "git@github.com:acme/abandoned.git"),
+		repositoryComponent("abandoned-cli", "https://github.com/acme/abandoned"),
+		repositoryComponent("idle", "https://gitlab.com/acme/idle"),
+		repositoryComponent("quiet", "https://github.com/acme/quiet"),
+		repositoryComponent("active", "https://github.com/acme/active"),
+		repositoryComponent("elsewhere", "https://bitbucket.org/acme/elsewhere"),
+		{Name: "unlinked", Version: "1.0.0"},
+	}}
+
+	results, err := agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, 6, host.lookups, "components from the same repository share a lookup")
+
+	archived := results[0]
+	assert.Equal(t, "Repository Activity Agent", archived.AgentName)
+	assert.Equal(t, core.SeverityHigh, archived.Severity)
+	assert.Equal(t, "archived", archived.Component.Name)
+	assert.Equal(t, "Repository https://github.com/acme/archived is archived and no longer receives fixes "+
+		"(last commit 2025-03-01, 4 of 16 issues open (25%), 9 contributors). Replace the component with a maintained alternative.", archived.Finding)
+	assert.Equal(t, []core.Citation{{ID: "https://github.com/acme/archived", Title: "Source repository", URL: "https://github.com/acme/archived"}}, archived.Citations)
+
+	assert.Equal(t, core.SeverityMedium, results[1].Severity)
+	assert.Equal(t, "abandoned", results[1].Component.Name)
+	assert.Equal(t, "Repository https://github.com/acme/abandoned appears abandoned: no commits for 3 years "+
+		"(last commit 2022-06-01, no issues, 2 contributors). Plan a move to a maintained alternative or fork.", results[1].Finding)
+	assert.Equal(t, "abandoned-cli", results[2].Component.Name)
+
+	assert.Equal(t, core.SeverityLow, results[3].Severity)
+	assert.Equal(t, "Repository https://gitlab.com/acme/idle may be unmaintained: no commits for 15 months, "+
+		"with most issues left open and a single contributor (last commit 2024-03-01, 30 of 40 issues open (75%), 1 contributor). "+
+		"Check whether the project is still maintained.", results[3].Finding)
+}
+
+func TestRepositoryActivityAgent_ComponentKeys(t *testing.T) {
+	agent := NewRepositoryActivityAgentWithHost(&fakeRepositoryHost{})
+	keys := agent.ComponentKeys(core.SBOM{Components: []core.Component{
+		repositoryComponent("a", "https://github.com/acme/shared"),
+		repositoryComponent("b", "https://github.com/acme/shared"),
+		{Name: "unlinked", Version: "1.0.0"},
+	}})
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.NotEqual(t, keys[0], keys[1], "findings name their component")
+	assert.Empty(t, keys[2])
+}
+
+// failingRepositoryHost fails every lookup.
+type failingRepositoryHost struct{}
+
+func (failingRepositoryHost) Activity(ctx context.Context, repository string) (*scm.Activity, error) {
+	return nil, errors.New("rate limited")
+}
+
+func TestRepositoryActivityAgent_LookupFailure(t *testing.T) {
+	agent := NewRepositoryActivityAgentWithHost(failingRepositoryHost{})
+	results, err := agent.Analyze(context.Background(), core.SBOM{Components: []core.Component{
+		repositoryComponent("a", "https://github.com/acme/a"),
+	}})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = agent.Analyze(ctx, core.SBOM{Components: []core.Component{
+		repositoryComponent("a", "https://github.com/acme/a"),
+	}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCachedRepositoryHost(t *testing.T) {
+	responses, err := cache.New(cache.Config{MaxEntries: 10, DefaultTTL: time.Hour})
+	require.NoError(t, err)
+	lastCommit := time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)
+	host := &fakeRepositoryHost{activities: map[string]scm.Activity{
+		"https://github.com/acme/a": {Archived: true, LastCommit: lastCommit, Contributors: 3},
+	}}
+	cached := cachedRepositoryHost{host: host, cache: responses}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		activity, err := cached.Activity(ctx, "https://github.com/acme/a")
+		require.NoError(t, err)
+		assert.True(t, activity.Archived)
+		assert.True(t, lastCommit.Equal(activity.LastCommit))
+		assert.Equal(t, 3, activity.Contributors)
+	}
+	assert.Equal(t, 1, host.lookups)
+
+	// Failures are looked up again
+	for i := 0; i < 2; i++ {
+		_, err := cached.Activity(ctx, "https://bitbucket.org/acme/a")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 3, host.lookups)
+}
@@ -0,0 +1,123 @@
+// Package analysis provides a composite risk score that ranks SBOMs and
+// projects by how urgently their findings need attention.
+package analysis
+
+import (
+	"math"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// Risk categories that findings are weighted by, derived from the reporting agent.
+const (
+	RiskVulnerabilities = "vulnerabilities"
+	RiskLicense         = "license"
+	RiskHealth          = "health"
+	RiskQuality         = "quality"
+)
+
+// Risk levels derived from a risk score.
+const (
+	RiskLevelNone     = "None"
+	RiskLevelLow      = "Low"
+	RiskLevelMedium   = "Medium"
+	RiskLevelHigh     = "High"
+	RiskLevelCritical = "Critical"
+)
+
+// riskHalfPoints is the weighted point total that scores 50; the score
+// approaches 100 as points grow beyond it.
+const riskHalfPoints = 25.0
+
+// riskCategories maps agent names to risk categories. Findings of other
+// agents count as health findings.
+var riskCategories = map[string]string{
+	"Vulnerability Scanner":         RiskVulnerabilities,
+	"Proactive Vulnerability Agent": RiskVulnerabilities,
+	"License Agent":                 RiskLicense,
+	"Dependency Health Agent":       RiskHealth,
+	"SBOM Quality Agent":            RiskQuality,
+}
+
+// riskWeights scales the severity points of each category: known and
+// suspected vulnerabilities count fully, the other categories less.
+var riskWeights = map[string]float64{
+	RiskVulnerabilities: 1.0,
+	RiskLicense:         0.6,
+	RiskHealth:          0.4,
+	RiskQuality:         0.2,
+}
+
+// RiskScore is the composite risk of a set of findings.
+//
+// Each finding earns severity points (Critical 10, High 5, Medium 2, Low 1)
+// multiplied by the weight of its category (vulnerabilities 1.0, license 0.6,
+// health 0.4, quality 0.2). The weighted total P is mapped onto 0-100 as
+// round(100 * P / (P + 25)), so a score of 50 takes 25 points, such as two
+// and a half critical vulnerabilities, and no amount of findings reaches 100.
+type RiskScore struct {
+	// Score ranges from 0 (no findings) towards 100
+	Score int `json:"score"`
+	// Level is "None", "Low" (below 25), "Medium" (25-49), "High" (50-74) or "Critical" (75 and above)
+	Level string `json:"level"`
+	// Points holds the weighted severity points of each category with findings
+	Points map[string]float64 `json:"points,omitempty"`
+}
+
+// ScoreRisk computes the composite risk score of analysis results.
+func ScoreRisk(results []core.AnalysisResult) RiskScore {
+	points := make(map[string]float64)
+	total := 0.0
+	for _, result := range results {
+		category, ok := riskCategories[result.AgentName]
+		if !ok {
+			category = RiskHealth
+		}
+		value := severityPoints(result.Severity) * riskWeights[category]
+		if value == 0 {
+			continue
+		}
+		points[category] += value
+		total += value
+	}
+
+	// Round the breakdown for display; the score uses the exact total
+	for category, value := range points {
+		points[category] = math.Round(value*10) / 10
+	}
+
+	score := int(math.Round(100 * total / (total + riskHalfPoints)))
+	return RiskScore{Score: score, Level: riskLevel(score), Points: points}
+}
+
+// severityPoints returns the points a finding of the given severity earns.
+func severityPoints(severity string) float64 {
+	switch core.SeverityRank(severity) {
+	case 4:
+		return 10
+	case 3:
+		return 5
+	case 2:
+		return 2
+	case 1:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// riskLevel buckets a risk score.
+func riskLevel(score int) string {
+	switch {
+	case score >= 75:
+		return RiskLevelCritical
+	case score >= 50:
+		return RiskLevelHigh
+	case score >= 25:
+		return RiskLevelMedium
+	case score > 0:
+		return RiskLevelLow
+	default:
+		return RiskLevelNone
+	}
+}
@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreRisk(t *testing.T) {
+	tests := []struct {
+		name           string
+		results        []core.AnalysisResult
+		expectedScore  int
+		expectedLevel  string
+		expectedPoints map[string]float64
+	}{
+		{
+			name:           "No findings",
+			expectedScore:  0,
+			expectedLevel:  RiskLevelNone,
+			expectedPoints: map[string]float64{},
+		},
+		{
+			name: "Single critical vulnerability",
+			results: []core.AnalysisResult{
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+			},
+			// 10 / (10 + 25)
+			expectedScore:  29,
+			expectedLevel:  RiskLevelMedium,
+			expectedPoints: map[string]float64{RiskVulnerabilities: 10},
+		},
+		{
+			name: "Mixed categories",
+			results: []core.AnalysisResult{
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Proactive Vulnerability Agent", Severity: core.SeverityHigh},
+				{AgentName: "License Agent", Severity: core.SeverityHigh},
+				{AgentName: "License Agent", Severity: core.SeverityHigh},
+				{AgentName: "Dependency Health Agent", Severity: core.SeverityMedium},
+				{AgentName: "SBOM Quality Agent", Severity: core.SeverityLow},
+			},
+			// 15 + 6 + 0.8 + 0.2 = 22 points
+			expectedScore:  47,
+			expectedLevel:  RiskLevelMedium,
+			expectedPoints: map[string]float64{RiskVulnerabilities: 15, RiskLicense: 6, RiskHealth: 0.8, RiskQuality: 0.2},
+		},
+		{
+			name: "Many critical vulnerabilities",
+			results: []core.AnalysisResult{
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+				{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical},
+			},
+			// 80 / (80 + 25)
+			expectedScore:  76,
+			expectedLevel:  RiskLevelCritical,
+			expectedPoints: map[string]float64{RiskVulnerabilities: 80},
+		},
+		{
+			name: "Unknown agents and severities",
+			results: []core.AnalysisResult{
+				{AgentName: "Custom Agent", Severity: core.SeverityHigh},
+				{AgentName: "License Agent", Severity: "Informational"},
+			},
+			// 5 * 0.4 health points
+			expectedScore:  7,
+			expectedLevel:  RiskLevelLow,
+			expectedPoints: map[string]float64{RiskHealth: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk := ScoreRisk(tt.results)
+			assert.Equal(t, tt.expectedScore, risk.Score)
+			assert.Equal(t, tt.expectedLevel, risk.Level)
+			assert.Equal(t, tt.expectedPoints, risk.Points)
+		})
+	}
+}
+
+func TestRollup_AddProject(t *testing.T) {
+	rollup := NewRollup()
+	critical := core.AnalysisResult{AgentName: "Vulnerability Scanner", Severity: core.SeverityCritical}
+	gpl := core.AnalysisResult{AgentName: "License Agent", Severity: core.SeverityHigh}
+
+	rollup.AddProject("web-app", "web-v1", []core.AnalysisResult{critical, critical})
+	rollup.AddProject("api", "api-v1", []core.AnalysisResult{gpl})
+	rollup.AddProject("billing", "billing-v1", nil)
+	// A later version replaces the earlier one
+	rollup.AddProject("web-app", "web-v2", nil)
+	rollup.AddProject("api", "api-v2", []core.AnalysisResult{gpl, critical})
+	rollup.SortFindings()
+
+	var order []string
+	for _, project := range rollup.Projects {
+		order = append(order, project.Project+"@"+project.SBOMID)
+	}
+	assert.Equal(t, []string{"api@api-v2", "web-app@web-v2", "billing@billing-v1"}, order)
+	assert.Equal(t, ScoreRisk([]core.AnalysisResult{gpl, critical}), rollup.Projects[0].RiskScore)
+}
@@ -0,0 +1,123 @@
+// Package analysis provides aggregation of findings across many SBOMs.
+package analysis
+
+import (
+	"sort"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// RollupFinding is a distinct finding together with every SBOM it was reported for.
+type RollupFinding struct {
+	AgentName string   `json:"agent_name"`
+	Finding   string   `json:"finding"`
+	Severity  string   `json:"severity"`
+	SBOMIDs   []string `json:"sbom_ids"`
+}
+
+// ProjectRisk is the risk score of a project, taken from its most recently
+// added SBOM version.
+type ProjectRisk struct {
+	Project string `json:"project"`
+	SBOMID  string `json:"sbom_id"`
+	RiskScore
+}
+
+// Rollup aggregates analysis results from multiple SBOMs into an
+// organization-wide view of findings.
+type Rollup struct {
+	SBOMsAnalyzed      int             `json:"sboms_analyzed"`
+	SBOMsFailed        int             `json:"sboms_failed"`
+	TotalFindings      int             `json:"total_findings"`
+	FindingsBySeverity map[string]int  `json:"findings_by_severity"`
+	FindingsByAgent    map[string]int  `json:"findings_by_agent"`
+	Findings           []RollupFinding `json:"findings"`
+	// Projects ranks projects by risk score, riskiest first, once SortFindings has run
+	Projects []ProjectRisk `json:"projects"`
+
+	index    map[string]int
+	projects map[string]int
+}
+
+// NewRollup creates an empty Rollup.
+func NewRollup() *Rollup {
+	return &Rollup{
+		FindingsBySeverity: make(map[string]int),
+		FindingsByAgent:    make(map[string]int),
+		Findings:           make([]RollupFinding, 0),
+		Projects:           make([]ProjectRisk, 0),
+		index:              make(map[string]int),
+		projects:           make(map[string]int),
+	}
+}
+
+// Add records the results of analyzing a single SBOM.
+// Identical findings reported for several SBOMs are merged into one entry.
+func (r *Rollup) Add(sbomID string, results []core.AnalysisResult) {
+	r.SBOMsAnalyzed++
+
+	for _, result := range results {
+		r.TotalFindings++
+		r.FindingsBySeverity[result.Severity]++
+		r.FindingsByAgent[result.AgentName]++
+
+		key := result.AgentName + "\x00" + result.Severity + "\x00" + result.Finding
+		if idx, exists := r.index[key]; exists {
+			finding := &r.Findings[idx]
+			if finding.SBOMIDs[len(finding.SBOMIDs)-1] != sbomID {
+				finding.SBOMIDs = append(finding.SBOMIDs, sbomID)
+			}
+			continue
+		}
+
+		r.index[key] = len(r.Findings)
+		r.Findings = append(r.Findings, RollupFinding{
+			AgentName: result.AgentName,
+			Finding:   result.Finding,
+			Severity:  result.Severity,
+			SBOMIDs:   []string{sbomID},
+		})
+	}
+}
+
+// AddProject records the risk of a project version. A later version of the
+// same project replaces the earlier one, so SBOMs should be added oldest first.
+func (r *Rollup) AddProject(project, sbomID string, results []core.AnalysisResult) {
+	risk := ProjectRisk{Project: project, SBOMID: sbomID, RiskScore: ScoreRisk(results)}
+	if idx, exists := r.projects[project]; exists {
+		r.Projects[idx] = risk
+		return
+	}
+	r.projects[project] = len(r.Projects)
+	r.Projects = append(r.Projects, risk)
+}
+
+// AddFailure records an SBOM that could not be analyzed.
+func (r *Rollup) AddFailure() {
+	r.SBOMsFailed++
+}
+
+// SortFindings orders findings by severity (most severe first), then by the
+// number of affected SBOMs, so the most widespread critical issues lead.
+// Projects are ranked by risk score so that the riskiest lead.
+func (r *Rollup) SortFindings() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		ri, rj := core.SeverityRank(r.Findings[i].Severity), core.SeverityRank(r.Findings[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return len(r.Findings[i].SBOMIDs) > len(r.Findings[j].SBOMIDs)
+	})
+
+	// Positions changed, so rebuild the lookup index
+	for i, finding := range r.Findings {
+		r.index[finding.AgentName+"\x00"+finding.Severity+"\x00"+finding.Finding] = i
+	}
+
+	sort.SliceStable(r.Projects, func(i, j int) bool {
+		return r.Projects[i].Score > r.Projects[j].Score
+	})
+	for i, project := range r.Projects {
+		r.projects[project.Project] = i
+	}
+}
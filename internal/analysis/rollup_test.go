@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollup_Add(t *testing.T) {
+	rollup := NewRollup()
+
+	shared := core.AnalysisResult{AgentName: "License Agent", Finding: "GPL component", Severity: "High"}
+	rollup.Add("sbom-1", []core.AnalysisResult{
+		shared,
+		{AgentName: "License Agent", Finding: "LGPL component", Severity: "Medium"},
+	})
+	rollup.Add("sbom-2", []core.AnalysisResult{shared})
+	rollup.Add("sbom-3", nil)
+	rollup.AddFailure()
+
+	assert.Equal(t, 3, rollup.SBOMsAnalyzed)
+	assert.Equal(t, 1, rollup.SBOMsFailed)
+	assert.Equal(t, 3, rollup.TotalFindings)
+	assert.Equal(t, map[string]int{"High": 2, "Medium": 1}, rollup.FindingsBySeverity)
+	assert.Equal(t, map[string]int{"License Agent": 3}, rollup.FindingsByAgent)
+
+	assert.Len(t, rollup.Findings, 2)
+	assert.Equal(t, []string{"sbom-1", "sbom-2"}, rollup.Findings[0].SBOMIDs)
+	assert.Equal(t, []string{"sbom-1"}, rollup.Findings[1].SBOMIDs)
+}
+
+func TestRollup_Add_DuplicateWithinSBOM(t *testing.T) {
+	rollup := NewRollup()
+	result := core.AnalysisResult{AgentName: "License Agent", Finding: "GPL component", Severity: "High"}
+
+	rollup.Add("sbom-1", []core.AnalysisResult{result, result})
+
+	assert.Equal(t, 2, rollup.TotalFindings)
+	assert.Len(t, rollup.Findings, 1)
+	assert.Equal(t, []string{"sbom-1"}, rollup.Findings[0].SBOMIDs)
+}
+
+func TestRollup_SortFindings(t *testing.T) {
+	rollup := NewRollup()
+	rollup.Add("sbom-1", []core.AnalysisResult{
+		{AgentName: "A", Finding: "low", Severity: "Low"},
+		{AgentName: "A", Finding: "high-narrow", Severity: "High"},
+		{AgentName: "A", Finding: "critical", Severity: "Critical"},
+		{AgentName: "A", Finding: "high-wide", Severity: "High"},
+	})
+	rollup.Add("sbom-2", []core.AnalysisResult{
+		{AgentName: "A", Finding: "high-wide", Severity: "High"},
+	})
+
+	rollup.SortFindings()
+
+	var order []string
+	for _, finding := range rollup.Findings {
+		order = append(order, finding.Finding)
+	}
+	assert.Equal(t, []string{"critical", "high-wide", "high-narrow", "low"}, order)
+
+	// The index must still merge into the right entry after sorting
+	rollup.Add("sbom-3", []core.AnalysisResult{{AgentName: "A", Finding: "low", Severity: "Low"}})
+	assert.Equal(t, []string{"sbom-1", "sbom-3"}, rollup.Findings[3].SBOMIDs)
+}
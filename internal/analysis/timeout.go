@@ -0,0 +1,135 @@
+// Package analysis provides time limits for running analysis agents.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// DefaultAgentTimeout is how long an agent may run unless configured otherwise.
+const DefaultAgentTimeout = 10 * time.Minute
+
+// AgentTimeouts holds the time limit for each analysis agent.
+type AgentTimeouts struct {
+	// Default applies to agents without a specific limit; zero means no limit
+	Default time.Duration
+	// PerAgent holds specific limits keyed by normalized agent name
+	PerAgent map[string]time.Duration
+}
+
+// NewAgentTimeouts returns timeouts limiting every agent to DefaultAgentTimeout.
+func NewAgentTimeouts() AgentTimeouts {
+	return AgentTimeouts{
+		Default:  DefaultAgentTimeout,
+		PerAgent: make(map[string]time.Duration),
+	}
+}
+
+// AgentTimeoutsFromEnv returns agent timeouts configured by AGENT_TIMEOUT,
+// the limit for every agent, and AGENT_TIMEOUTS, a comma-separated list of
+// per-agent limits such as "proactive-vulnerability-agent=20m". Agent names
+// are matched ignoring case, spaces and punctuation, and durations use Go
+// syntax; "0" disables the limit. Invalid entries are reported and skipped.
+func AgentTimeoutsFromEnv() (AgentTimeouts, error) {
+	timeouts := NewAgentTimeouts()
+	var errs []error
+
+	if value := os.Getenv("AGENT_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			errs = append(errs, fmt.Errorf("invalid AGENT_TIMEOUT %q", value))
+		} else {
+			timeouts.Default = timeout
+		}
+	}
+
+	for _, entry := range strings.Split(os.Getenv("AGENT_TIMEOUTS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(entry, "=")
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if !found || err != nil || timeout < 0 {
+			errs = append(errs, fmt.Errorf("invalid AGENT_TIMEOUTS entry %q", entry))
+			continue
+		}
+		timeouts.Set(name, timeout)
+	}
+
+	return timeouts, errors.Join(errs...)
+}
+
+// Set sets the time limit for the named agent; zero means no limit.
+func (t *AgentTimeouts) Set(agentName string, timeout time.Duration) {
+	if t.PerAgent == nil {
+		t.PerAgent = make(map[string]time.Duration)
+	}
+	t.PerAgent[normalizeAgentName(agentName)] = timeout
+}
+
+// For returns the time limit for the named agent; zero means no limit.
+func (t AgentTimeouts) For(agentName string) time.Duration {
+	if timeout, ok := t.PerAgent[normalizeAgentName(agentName)]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+// normalizeAgentName lower-cases a name and drops everything but letters and digits,
+// so "Proactive Vulnerability Agent" and "proactive-vulnerability-agent" match.
+func normalizeAgentName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// RunAgent runs an agent with a time limit; zero means no limit. It returns
+// as soon as ctx is cancelled or the limit is reached, even if the agent has
+// not stopped yet, with an error wrapping context.Canceled or
+// context.DeadlineExceeded respectively.
+func RunAgent(ctx context.Context, agent AnalysisAgent, timeout time.Duration, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		results []core.AnalysisResult
+		err     error
+	}
+	done := make(chan outcome, 1)
+
+	go func() {
+		results, err := agent.Analyze(ctx, sbom)
+		done <- outcome{results: results, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.err == nil {
+			return result.results, nil
+		}
+		if ctx.Err() == nil {
+			return nil, result.err
+		}
+	case <-ctx.Done():
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s: %w", timeout, context.DeadlineExceeded)
+	}
+	return nil, fmt.Errorf("cancelled: %w", ctx.Err())
+}
@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAgent returns fixed results after an optional delay, ignoring cancellation.
+type stubAgent struct {
+	name    string
+	delay   time.Duration
+	results []core.AnalysisResult
+	err     error
+}
+
+func (a *stubAgent) Name() string { return a.name }
+
+func (a *stubAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	time.Sleep(a.delay)
+	return a.results, a.err
+}
+
+func TestAgentTimeoutsFromEnv(t *testing.T) {
+	t.Setenv("AGENT_TIMEOUT", "2m")
+	t.Setenv("AGENT_TIMEOUTS", "proactive-vulnerability-agent=20m, Vulnerability Scanner=0, quality-agent")
+
+	timeouts, err := AgentTimeoutsFromEnv()
+	assert.Error(t, err)
+
+	assert.Equal(t, 20*time.Minute, timeouts.For("Proactive Vulnerability Agent"))
+	assert.Equal(t, time.Duration(0), timeouts.For("Vulnerability Scanner"))
+	assert.Equal(t, 2*time.Minute, timeouts.For("Quality Agent"))
+	assert.Equal(t, 2*time.Minute, timeouts.For("License Agent"))
+}
+
+func TestAgentTimeouts_Defaults(t *testing.T) {
+	timeouts, err := AgentTimeoutsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAgentTimeout, timeouts.For("License Agent"))
+
+	var zero AgentTimeouts
+	assert.Equal(t, time.Duration(0), zero.For("License Agent"))
+	zero.Set("License Agent", time.Second)
+	assert.Equal(t, time.Second, zero.For("license-agent"))
+}
+
+func TestRunAgent(t *testing.T) {
+	expected := []core.AnalysisResult{{AgentName: "Stub", Finding: "finding", Severity: "Low"}}
+
+	t.Run("Completes within the limit", func(t *testing.T) {
+		results, err := RunAgent(context.Background(), &stubAgent{results: expected}, time.Second, core.SBOM{})
+		require.NoError(t, err)
+		assert.Equal(t, expected, results)
+	})
+
+	t.Run("Agent error", func(t *testing.T) {
+		_, err := RunAgent(context.Background(), &stubAgent{err: errors.New("boom")}, time.Second, core.SBOM{})
+		assert.EqualError(t, err, "boom")
+	})
+
+	t.Run("Times out without waiting for the agent", func(t *testing.T) {
+		started := time.Now()
+		_, err := RunAgent(context.Background(), &stubAgent{delay: time.Second}, 20*time.Millisecond, core.SBOM{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(started), 500*time.Millisecond)
+	})
+
+	t.Run("Cancelled by the caller", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := RunAgent(ctx, &stubAgent{delay: time.Second}, 0, core.SBOM{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestAgents_StopWhenCancelled(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"response": "deprecated"}`))
+	}))
+	defer mockServer.Close()
+
+	healthAgent := NewDependencyHealthAgent()
+	healthAgent.ollamaURL = mockServer.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sbom := core.SBOM{Components: []core.Component{{Name: "left-pad", Version: "1.3.0"}}}
+	_, err := healthAgent.Analyze(ctx, sbom)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	scanner := NewVulnerabilityScanningAgent()
+	scanner.apiBaseURL = mockServer.URL
+	_, err = scanner.Analyze(ctx, sbom)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Zero(t, requests)
+}
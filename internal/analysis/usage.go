@@ -0,0 +1,153 @@
+// Package analysis provides accounting of the LLM capacity and wall time used
+// by agents, per analysis run and in total since the process started.
+package analysis
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LLMUsage is the LLM capacity used by an agent, as reported by Ollama.
+type LLMUsage struct {
+	// Requests is the number of generate requests answered
+	Requests int
+	// PromptTokens is the number of prompt tokens evaluated
+	PromptTokens int
+	// CompletionTokens is the number of tokens generated
+	CompletionTokens int
+	// Duration is the time Ollama spent answering the requests
+	Duration time.Duration
+}
+
+// Tokens returns the prompt and completion tokens together.
+func (u LLMUsage) Tokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// add accumulates the usage reported by one Ollama response.
+func (u *LLMUsage) add(resp OllamaResponse) {
+	u.Requests++
+	u.PromptTokens += resp.PromptEvalCount
+	u.CompletionTokens += resp.EvalCount
+	u.Duration += time.Duration(resp.TotalDuration)
+}
+
+// usageRecorder accumulates the LLM usage of one agent run. Agents may query
+// the LLM from several goroutines.
+type usageRecorder struct {
+	mu    sync.Mutex
+	usage LLMUsage
+}
+
+// usageRecorderKey is the context key of an agent run's usageRecorder.
+type usageRecorderKey struct{}
+
+// withUsageRecorder returns a context recording the LLM usage of the agent
+// run it is passed to.
+func withUsageRecorder(ctx context.Context) (context.Context, *usageRecorder) {
+	recorder := &usageRecorder{}
+	return context.WithValue(ctx, usageRecorderKey{}, recorder), recorder
+}
+
+// recordLLMUsage adds the usage reported by an Ollama response to the agent
+// run of ctx, if it is recorded.
+func recordLLMUsage(ctx context.Context, resp OllamaResponse) {
+	recorder, ok := ctx.Value(usageRecorderKey{}).(*usageRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	recorder.usage.add(resp)
+	recorder.mu.Unlock()
+}
+
+// Usage returns the usage recorded so far, or nil if the LLM was not queried.
+func (r *usageRecorder) Usage() *LLMUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.usage.Requests == 0 {
+		return nil
+	}
+	usage := r.usage
+	return &usage
+}
+
+// AgentTotals are the cumulative runs and usage of an agent since the
+// process started.
+type AgentTotals struct {
+	Agent string
+	// Runs counts the agent's runs by status
+	Runs map[string]int
+	// Duration is the wall time of all runs
+	Duration time.Duration
+	LLM      LLMUsage
+}
+
+// totals holds the AgentTotals of every agent that has run, by name, and the
+// functions observing the runs.
+var totals = struct {
+	mu        sync.Mutex
+	byAgent   map[string]*AgentTotals
+	observers []func(AgentRun)
+}{byAgent: make(map[string]*AgentTotals)}
+
+// ObserveRuns registers fn to be called with every agent run that finishes
+// in this process from then on, such as to export metrics.
+func ObserveRuns(fn func(AgentRun)) {
+	totals.mu.Lock()
+	defer totals.mu.Unlock()
+	totals.observers = append(totals.observers, fn)
+}
+
+// recordRun adds a finished agent run to the process totals and passes it
+// to the observers.
+func recordRun(run AgentRun) {
+	for _, observe := range addRun(run) {
+		observe(run)
+	}
+}
+
+// addRun adds a run to the process totals and returns the observers.
+func addRun(run AgentRun) []func(AgentRun) {
+	totals.mu.Lock()
+	defer totals.mu.Unlock()
+
+	agent := totals.byAgent[run.Agent]
+	if agent == nil {
+		agent = &AgentTotals{Agent: run.Agent, Runs: make(map[string]int)}
+		totals.byAgent[run.Agent] = agent
+	}
+	agent.Runs[run.Status]++
+	agent.Duration += run.Duration
+	if run.LLMUsage != nil {
+		agent.LLM.Requests += run.LLMUsage.Requests
+		agent.LLM.PromptTokens += run.LLMUsage.PromptTokens
+		agent.LLM.CompletionTokens += run.LLMUsage.CompletionTokens
+		agent.LLM.Duration += run.LLMUsage.Duration
+	}
+	return totals.observers
+}
+
+// UsageTotals returns the cumulative runs and usage of every agent that has
+// run in this process, ordered by agent name.
+func UsageTotals() []AgentTotals {
+	totals.mu.Lock()
+	defer totals.mu.Unlock()
+
+	snapshot := make([]AgentTotals, 0, len(totals.byAgent))
+	for _, agent := range totals.byAgent {
+		runs := make(map[string]int, len(agent.Runs))
+		for status, count := range agent.Runs {
+			runs[status] = count
+		}
+		copied := *agent
+		copied.Runs = runs
+		snapshot = append(snapshot, copied)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Agent < snapshot[j].Agent
+	})
+	return snapshot
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// agentTotals returns the process totals of the named agent.
+func agentTotals(name string) AgentTotals {
+	for _, agent := range UsageTotals() {
+		if agent.Agent == name {
+			return agent
+		}
+	}
+	return AgentTotals{Agent: name}
+}
+
+func TestOrchestrator_Run_LLMUsage(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/tags" {
+			w.Write([]byte(`{"models": [{"name": "llama3:latest"}]}`))
+			return
+		}
+		w.Write([]byte(`{"response": "This is a healthy, well-maintained project.", "done": true, "prompt_eval_count": 120, "eval_count": 30, "total_duration": 1500000000}`))
+	}))
+	defer mockServer.Close()
+
+	agent := NewDependencyHealthAgent()
+	agent.ollamaURL = mockServer.URL
+
+	before := agentTotals(agent.Name())
+	var observed []AgentRun
+	ObserveRuns(func(run AgentRun) {
+		if run.Agent == agent.Name() {
+			observed = append(observed, run)
+		}
+	})
+
+	orchestrator := NewOrchestrator(NewAgentTimeouts())
+	orchestrator.Add(agent)
+	orchestrator.Add(&stubAgent{name: "Quality Agent"})
+
+	sbom := core.SBOM{Components: []core.Component{{Name: "a", Version: "1.0.0"}, {Name: "b", Version: "2.0.0"}}}
+	report, err := orchestrator.Run(context.Background(), sbom)
+	require.NoError(t, err)
+
+	// Usage is summed over the agent's requests
+	require.NotNil(t, report.Runs[0].LLMUsage)
+	assert.Equal(t, LLMUsage{Requests: 2, PromptTokens: 240, CompletionTokens: 60, Duration: 3 * time.Second}, *report.Runs[0].LLMUsage)
+	assert.Equal(t, 300, report.Runs[0].LLMUsage.Tokens())
+
+	// Agents that make no LLM requests report none
+	assert.Nil(t, report.Runs[1].LLMUsage)
+
+	// The process totals accumulate every run
+	after := agentTotals(agent.Name())
+	assert.Equal(t, 1, after.Runs[AgentStatusOK]-before.Runs[AgentStatusOK])
+	assert.Equal(t, 2, after.LLM.Requests-before.LLM.Requests)
+	assert.Equal(t, 240, after.LLM.PromptTokens-before.LLM.PromptTokens)
+	assert.Equal(t, 60, after.LLM.CompletionTokens-before.LLM.CompletionTokens)
+	assert.Equal(t, 3*time.Second, after.LLM.Duration-before.LLM.Duration)
+	assert.GreaterOrEqual(t, after.Duration-before.Duration, report.Runs[0].Duration)
+
+	// Observers see each run as the orchestrator reports it
+	assert.Equal(t, []AgentRun{report.Runs[0]}, observed)
+}
+
+func TestRecordLLMUsage_WithoutRecorder(t *testing.T) {
+	// Agents called outside an orchestrator record nothing
+	assert.NotPanics(t, func() {
+		recordLLMUsage(context.Background(), OllamaResponse{EvalCount: 10})
+	})
+}
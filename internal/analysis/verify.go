@@ -0,0 +1,140 @@
+// Package analysis provides verification of LLM-generated findings against deterministic sources.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// VulnerabilityLookup resolves vulnerability IDs against a deterministic
+// vulnerability database such as OSV.dev.
+type VulnerabilityLookup interface {
+	// FetchVulnerability returns the record for a vulnerability ID, or nil if it does not exist
+	FetchVulnerability(ctx context.Context, id string) (*OSVVulnerability, error)
+
+	// AffectsComponent reports whether a vulnerability affects the given component
+	AffectsComponent(vuln OSVVulnerability, component core.Component) bool
+}
+
+var (
+	// vulnerabilityIDPattern matches CVE and GHSA identifiers
+	vulnerabilityIDPattern = regexp.MustCompile(`\b(?:CVE-\d{4}-\d{4,}|GHSA(?:-[23456789cfghjmpqrvwx]{4}){3})\b`)
+
+	// versionPattern matches dotted version numbers such as 1.2 or v2.3.4-beta.1
+	versionPattern = regexp.MustCompile(`\bv?\d+(?:\.\d+)+(?:-[0-9A-Za-z.]+)?\b`)
+)
+
+// FindingVerifier cross-checks the vulnerability IDs and versions named in an
+// LLM-generated finding against the analyzed component, the evidence the LLM
+// was given and a vulnerability database. Lookups are cached, so a verifier
+// is best shared across the components of an analysis. It is safe for concurrent use.
+type FindingVerifier struct {
+	lookup VulnerabilityLookup
+
+	mu    sync.Mutex
+	cache map[string]*OSVVulnerability
+}
+
+// NewFindingVerifier creates a verifier that checks vulnerability IDs with lookup.
+func NewFindingVerifier(lookup VulnerabilityLookup) *FindingVerifier {
+	return &FindingVerifier{
+		lookup: lookup,
+		cache:  make(map[string]*OSVVulnerability),
+	}
+}
+
+// Verify checks the claims in an LLM-generated finding about component.
+// Evidence holds the text the LLM based the finding on, if any.
+//
+// A vulnerability ID that the database does not know and the evidence does
+// not mention is treated as fabricated, and the finding is dropped (keep is
+// false). IDs that exist but do not affect the component, IDs that cannot be
+// looked up, and versions matching neither the component nor the evidence
+// are uncorroborated: the finding is kept, one severity level lower and
+// annotated with what could not be verified.
+func (fv *FindingVerifier) Verify(ctx context.Context, result core.AnalysisResult, component core.Component, evidence []string) (core.AnalysisResult, bool) {
+	corpus := strings.Join(evidence, "\n")
+	var unverified []string
+
+	for _, id := range uniqueMatches(vulnerabilityIDPattern, result.Finding) {
+		if strings.Contains(corpus, id) {
+			continue
+		}
+
+		vuln, err := fv.fetch(ctx, id)
+		switch {
+		case err != nil:
+			unverified = append(unverified, fmt.Sprintf("%s could not be looked up", id))
+		case vuln == nil:
+			fmt.Printf("Warning: Dropping finding for component '%s' citing unknown vulnerability %s\n", component.Name, id)
+			return result, false
+		case !fv.lookup.AffectsComponent(*vuln, component):
+			unverified = append(unverified, fmt.Sprintf("%s does not affect %s %s", id, component.Name, component.Version))
+		}
+	}
+
+	componentVersion := strings.TrimPrefix(component.Version, "v")
+	for _, version := range uniqueMatches(versionPattern, result.Finding) {
+		if strings.TrimPrefix(version, "v") == componentVersion || strings.Contains(corpus, strings.TrimPrefix(version, "v")) {
+			continue
+		}
+		unverified = append(unverified, fmt.Sprintf("version %s is not corroborated", version))
+	}
+
+	if len(unverified) > 0 {
+		result.Severity = downgradeSeverity(result.Severity)
+		result.Finding = fmt.Sprintf("%s (Unverified: %s)", result.Finding, strings.Join(unverified, "; "))
+	}
+	return result, true
+}
+
+// fetch looks up a vulnerability, caching the outcome.
+func (fv *FindingVerifier) fetch(ctx context.Context, id string) (*OSVVulnerability, error) {
+	fv.mu.Lock()
+	cached, ok := fv.cache[id]
+	fv.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	vuln, err := fv.lookup.FetchVulnerability(ctx, id)
+	if err != nil {
+		// Failures are not cached so a later finding can retry
+		return nil, err
+	}
+
+	fv.mu.Lock()
+	fv.cache[id] = vuln
+	fv.mu.Unlock()
+	return vuln, nil
+}
+
+// uniqueMatches returns the distinct matches of pattern in text, in order of appearance.
+func uniqueMatches(pattern *regexp.Regexp, text string) []string {
+	seen := make(map[string]bool)
+	var matches []string
+	for _, match := range pattern.FindAllString(text, -1) {
+		if !seen[match] {
+			seen[match] = true
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// downgradeSeverity lowers a severity by one level, bottoming out at Low.
+func downgradeSeverity(severity string) string {
+	switch severity {
+	case "Critical":
+		return "High"
+	case "High":
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVulnerabilityLookup serves vulnerabilities from a map, counting lookups.
+type fakeVulnerabilityLookup struct {
+	vulns    map[string]OSVVulnerability
+	affected map[string]bool
+	err      error
+	calls    int
+}
+
+func (f *fakeVulnerabilityLookup) FetchVulnerability(ctx context.Context, id string) (*OSVVulnerability, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	vuln, ok := f.vulns[id]
+	if !ok {
+		return nil, nil
+	}
+	return &vuln, nil
+}
+
+func (f *fakeVulnerabilityLookup) AffectsComponent(vuln OSVVulnerability, component core.Component) bool {
+	return f.affected[vuln.ID]
+}
+
+func TestFindingVerifier_Verify(t *testing.T) {
+	component := core.Component{Name: "log4j-core", Version: "2.14.1"}
+	lookup := &fakeVulnerabilityLookup{
+		vulns: map[string]OSVVulnerability{
+			"CVE-2021-44228": {ID: "CVE-2021-44228"},
+			"CVE-2017-5645":  {ID: "CVE-2017-5645"},
+		},
+		affected: map[string]bool{"CVE-2021-44228": true},
+	}
+
+	tests := []struct {
+		name             string
+		finding          string
+		evidence         []string
+		expectedKeep     bool
+		expectedSeverity string
+		expectedNote     string
+	}{
+		{
+			name:             "Claims about the component are kept",
+			finding:          "log4j-core 2.14.1 is affected by CVE-2021-44228.",
+			expectedKeep:     true,
+			expectedSeverity: "High",
+		},
+		{
+			name:             "Claims backed by evidence are kept",
+			finding:          "Unsafe deserialization fixed in 2.17.1 [vuln-001].",
+			evidence:         []string{"vuln-001 Title: Deserialization in log4j-core, fixed in 2.17.1"},
+			expectedKeep:     true,
+			expectedSeverity: "High",
+		},
+		{
+			name:         "Unknown vulnerability is dropped",
+			finding:      "log4j-core is affected by CVE-2099-12345.",
+			expectedKeep: false,
+		},
+		{
+			name:             "Vulnerability not affecting the component is downgraded",
+			finding:          "log4j-core is affected by CVE-2017-5645.",
+			expectedKeep:     true,
+			expectedSeverity: "Medium",
+			expectedNote:     "CVE-2017-5645 does not affect log4j-core 2.14.1",
+		},
+		{
+			name:             "Uncorroborated version is downgraded",
+			finding:          "Versions before 3.0.0 are deprecated.",
+			expectedKeep:     true,
+			expectedSeverity: "Medium",
+			expectedNote:     "version 3.0.0 is not corroborated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewFindingVerifier(lookup)
+			result := core.AnalysisResult{AgentName: "Test Agent", Finding: tt.finding, Severity: "High"}
+
+			verified, keep := verifier.Verify(context.Background(), result, component, tt.evidence)
+
+			assert.Equal(t, tt.expectedKeep, keep)
+			if !keep {
+				return
+			}
+			assert.Equal(t, tt.expectedSeverity, verified.Severity)
+			if tt.expectedNote == "" {
+				assert.Equal(t, tt.finding, verified.Finding)
+			} else {
+				assert.Contains(t, verified.Finding, "(Unverified: "+tt.expectedNote)
+			}
+		})
+	}
+}
+
+func TestFindingVerifier_LookupFailure(t *testing.T) {
+	lookup := &fakeVulnerabilityLookup{err: errors.New("OSV unavailable")}
+	verifier := NewFindingVerifier(lookup)
+	component := core.Component{Name: "lodash", Version: "4.17.20"}
+	result := core.AnalysisResult{Finding: "Affected by GHSA-jf85-cpcp-j695.", Severity: "Low"}
+
+	verified, keep := verifier.Verify(context.Background(), result, component, nil)
+	assert.True(t, keep)
+	assert.Equal(t, "Low", verified.Severity)
+	assert.Contains(t, verified.Finding, "GHSA-jf85-cpcp-j695 could not be looked up")
+
+	// Failed lookups are retried, successful ones cached
+	verifier.Verify(context.Background(), result, component, nil)
+	assert.Equal(t, 2, lookup.calls)
+
+	lookup.err = nil
+	verifier.Verify(context.Background(), result, component, nil)
+	verifier.Verify(context.Background(), result, component, nil)
+	assert.Equal(t, 3, lookup.calls)
+}
+
+func TestDependencyHealthAgent_VerifiesFindings(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.Prompt, "left-pad") {
+			w.Write([]byte(`{"response": "This project is unmaintained and vulnerable to CVE-2024-99999."}`))
+			return
+		}
+		w.Write([]byte(`{"response": "This project is deprecated since 2.0.0."}`))
+	}))
+	defer mockServer.Close()
+
+	agent := NewDependencyHealthAgent()
+	agent.ollamaURL = mockServer.URL
+	agent.verifier = NewFindingVerifier(&fakeVulnerabilityLookup{})
+
+	results, err := agent.Analyze(context.Background(), core.SBOM{
+		Components: []core.Component{
+			{Name: "left-pad", Version: "1.3.0"},
+			{Name: "request", Version: "2.88.2"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Low", results[0].Severity)
+	assert.Contains(t, results[0].Finding, "version 2.0.0 is not corroborated")
+}
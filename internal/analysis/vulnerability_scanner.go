@@ -6,16 +6,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/cache"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+	packageurl "github.com/hueyexe/SBOM-Sentinel/internal/purl"
 )
 
 // VulnerabilityScanningAgent analyzes SBOM components for known vulnerabilities using OSV.dev API.
 type VulnerabilityScanningAgent struct {
 	httpClient *http.Client
 	apiBaseURL string
+	mirror     OSVMirror
+	offline    bool
+	// cache holds API responses shared by the agents of the process; nil
+	// disables caching
+	cache *cache.Cache
 }
 
 // OSVVulnerability represents a vulnerability record from OSV.dev API.
@@ -31,6 +40,36 @@ type OSVVulnerability struct {
 	DatabaseSpecific struct {
 		Severity string `json:"severity"`
 	} `json:"database_specific"`
+	Affected []OSVAffected `json:"affected,omitempty"`
+}
+
+// OSVAffected describes a package and the versions of it affected by a vulnerability.
+type OSVAffected struct {
+	Package  OSVPackage `json:"package"`
+	Ranges   []OSVRange `json:"ranges,omitempty"`
+	Versions []string   `json:"versions,omitempty"`
+}
+
+// OSVPackage identifies a package within an OSV ecosystem.
+type OSVPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+// OSVRange is a range of affected versions expressed as ordered events.
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Events []OSVEvent `json:"events"`
+}
+
+// OSVEvent marks a point in a version range where a vulnerability was
+// introduced, fixed, or last seen.
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
 }
 
 // OSVQueryRequest represents the request format for OSV.dev API queries.
@@ -48,12 +87,17 @@ type OSVQueryResponse struct {
 }
 
 // NewVulnerabilityScanningAgent creates a new instance of VulnerabilityScanningAgent.
+// It consults the local OSV mirror at OSV_MIRROR_PATH, if set, before the
+// OSV.dev API, and never calls the API if OSV_OFFLINE is true. API responses
+// are kept in the shared cache configured by the CACHE_* variables.
 func NewVulnerabilityScanningAgent() *VulnerabilityScanningAgent {
+	mirror, offline := osvMirrorFromEnv()
 	return &VulnerabilityScanningAgent{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient: httpclient.New(30 * time.Second),
 		apiBaseURL: "https://api.osv.dev/v1",
+		mirror:     mirror,
+		offline:    offline,
+		cache:      cache.Shared(),
 	}
 }
 
@@ -66,43 +110,121 @@ func (vsa *VulnerabilityScanningAgent) Name() string {
 // It returns a slice of AnalysisResult containing findings for components
 // that have known vulnerabilities in the OSV database.
 func (vsa *VulnerabilityScanningAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
-	var results []core.AnalysisResult
+	return analyzeEachComponent(ctx, vsa, sbom)
+}
+
+// ComponentKeys identifies the OSV lookup of each component, which depends
+// on the distribution release of the SBOM for operating system packages.
+func (vsa *VulnerabilityScanningAgent) ComponentKeys(sbom core.SBOM) []string {
+	release, hasRelease := sbomOSRelease(sbom)
+	keys := make([]string, len(sbom.Components))
+	for i, component := range sbom.Components {
+		if component.Name == "" {
+			continue
+		}
+		if hasRelease {
+			component = withOSRelease(component, release)
+		}
+		keys[i] = componentKey(component)
+	}
+	return keys
+}
+
+// AnalyzeComponents looks up the known vulnerabilities of the given
+// components of sbom. Components whose lookup fails are logged and skipped.
+func (vsa *VulnerabilityScanningAgent) AnalyzeComponents(ctx context.Context, sbom core.SBOM, components []core.Component, found func(i int, results []core.AnalysisResult)) error {
+	// OS packages without a distro qualifier belong to the SBOM's operating system
+	release, hasRelease := sbomOSRelease(sbom)
+
+	for i, component := range components {
+		// Stop querying once the caller has gone away or the time limit is up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	for _, component := range sbom.Components {
 		// Skip components without sufficient information for vulnerability lookup
 		if component.Name == "" {
 			continue
 		}
 
+		lookup := component
+		if hasRelease {
+			lookup = withOSRelease(component, release)
+		}
+
 		// Query OSV.dev for vulnerabilities
-		vulns, err := vsa.queryOSVForComponent(ctx, component)
+		vulns, err := vsa.queryOSVForComponent(ctx, lookup)
 		if err != nil {
 			// Log the error but continue with other components
 			fmt.Printf("Warning: Failed to query OSV for component %s: %v\n", component.Name, err)
 			continue
 		}
 
+		// Suggest upgrades from the fixed versions in the affected ranges
+		var fixes remediation
+		if version, matches, ok := vsa.componentPackage(lookup); ok {
+			fixes = remediate(vulns, version, matches)
+		}
+
 		// Create analysis results for each vulnerability found
+		var results []core.AnalysisResult
 		for _, vuln := range vulns {
 			severity := vsa.determineSeverity(vuln)
 			finding := vsa.createFindingMessage(component, vuln)
 
 			result := core.AnalysisResult{
-				AgentName: vsa.Name(),
-				Finding:   finding,
-				Severity:  severity,
+				AgentName:       vsa.Name(),
+				Finding:         finding,
+				Severity:        severity,
+				Component:       component.Ref(),
+				VulnerabilityID: vuln.ID,
+				FixedVersion:    fixes.fixed[vuln.ID],
+				UpgradeTo:       fixes.recommended,
 			}
 
 			results = append(results, result)
 		}
+		found(i, results)
 	}
 
-	return results, nil
+	return nil
 }
 
 // queryOSVForComponent queries the OSV.dev API for vulnerabilities affecting the given component.
+// Operating system packages are looked up in the advisories of their
+// distribution release, e.g. the "Debian:12" or "Alpine:v3.19" ecosystem.
 func (vsa *VulnerabilityScanningAgent) queryOSVForComponent(ctx context.Context, component core.Component) ([]OSVVulnerability, error) {
+	if pkg, ok := distroPackageFromPURL(component.PURL, component.Version); ok {
+		lookup := component
+		lookup.Version = pkg.version
+
+		var vulns []OSVVulnerability
+		seen := make(map[string]bool)
+		for _, ecosystem := range pkg.ecosystems {
+			found, err := vsa.queryOSVPackage(ctx, ecosystem, pkg.name, lookup)
+			if err != nil {
+				return nil, err
+			}
+			for _, vuln := range found {
+				if !seen[vuln.ID] {
+					seen[vuln.ID] = true
+					vulns = append(vulns, vuln)
+				}
+			}
+		}
+		return vulns, nil
+	}
+
+	name := component.Name
 	ecosystem := vsa.extractEcosystemFromPURL(component.PURL)
+	if ecosystem == "" {
+		// Components identified only by CPE are looked up by product within
+		// the ecosystem of the CPE's target software
+		if cpe, ok := component.ParsedCPE(); ok && cpeEcosystem(cpe) != "" {
+			ecosystem = cpeEcosystem(cpe)
+			name = cpe.Product
+		}
+	}
 	if ecosystem == "" {
 		// If we can't determine the ecosystem, try to infer it from the component name
 		ecosystem = vsa.inferEcosystem(component.Name)
@@ -113,9 +235,36 @@ func (vsa *VulnerabilityScanningAgent) queryOSVForComponent(ctx context.Context,
 		return nil, nil
 	}
 
+	return vsa.queryOSVPackage(ctx, ecosystem, name, component)
+}
+
+// queryOSVPackage looks up the vulnerabilities affecting component, known in
+// ecosystem as name, in the mirror or the OSV.dev API.
+func (vsa *VulnerabilityScanningAgent) queryOSVPackage(ctx context.Context, ecosystem, name string, component core.Component) ([]OSVVulnerability, error) {
+	// Prefer the local mirror, which avoids API rate limits
+	if vsa.mirror != nil {
+		vulns, mirrored, err := vsa.queryMirror(ctx, ecosystem, name, component)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query OSV mirror: %w", err)
+		}
+		if mirrored {
+			return vulns, nil
+		}
+	}
+	if vsa.offline {
+		return nil, fmt.Errorf("ecosystem %s is not in the offline OSV mirror", ecosystem)
+	}
+
+	// Components shared by many SBOMs are only looked up once per TTL
+	cacheKey := vsa.apiBaseURL + "/query " + ecosystem + "/" + name + "@" + component.Version
+	var cached []OSVVulnerability
+	if vsa.cache.GetJSON(ctx, cache.SourceOSV, cacheKey, &cached) {
+		return cached, nil
+	}
+
 	// Prepare the query request
 	queryReq := OSVQueryRequest{}
-	queryReq.Package.Name = component.Name
+	queryReq.Package.Name = name
 	queryReq.Package.Ecosystem = ecosystem
 	if component.Version != "" {
 		queryReq.Version = component.Version
@@ -147,6 +296,7 @@ func (vsa *VulnerabilityScanningAgent) queryOSVForComponent(ctx context.Context,
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == 404 {
 			// No vulnerabilities found for this component
+			vsa.cache.SetJSON(ctx, cache.SourceOSV, cacheKey, []OSVVulnerability(nil))
 			return nil, nil
 		}
 		return nil, fmt.Errorf("OSV API returned status code %d", resp.StatusCode)
@@ -158,33 +308,60 @@ func (vsa *VulnerabilityScanningAgent) queryOSVForComponent(ctx context.Context,
 		return nil, fmt.Errorf("failed to decode OSV API response: %w", err)
 	}
 
+	vsa.cache.SetJSON(ctx, cache.SourceOSV, cacheKey, queryResp.Vulns)
 	return queryResp.Vulns, nil
 }
 
-// extractEcosystemFromPURL extracts the ecosystem from a Package URL (PURL).
-func (vsa *VulnerabilityScanningAgent) extractEcosystemFromPURL(purl string) string {
-	if purl == "" {
-		return ""
+// FetchVulnerability retrieves a single vulnerability record from OSV.dev by its
+// ID or alias (e.g. "GHSA-xxxx-xxxx-xxxx" or "CVE-2021-44228").
+// The mirror, if any, is consulted first, falling back to the API unless offline.
+// Returns nil and no error if the vulnerability is not known to OSV.
+func (vsa *VulnerabilityScanningAgent) FetchVulnerability(ctx context.Context, id string) (*OSVVulnerability, error) {
+	if vsa.mirror != nil {
+		vuln, err := vsa.fetchFromMirror(ctx, id)
+		if err != nil || vuln != nil || vsa.offline {
+			return vuln, err
+		}
+	}
+	if vsa.offline {
+		return nil, fmt.Errorf("vulnerability %s cannot be looked up offline without an OSV mirror", id)
 	}
 
-	// PURL format: pkg:type/namespace/name@version
-	if !strings.HasPrefix(purl, "pkg:") {
-		return ""
+	req, err := http.NewRequestWithContext(ctx, "GET", vsa.apiBaseURL+"/vulns/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	parts := strings.Split(purl, "/")
-	if len(parts) < 2 {
-		return ""
+	req.Header.Set("User-Agent", "SBOM-Sentinel/1.0")
+
+	resp, err := vsa.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute OSV API request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSV API returned status code %d", resp.StatusCode)
 	}
 
-	// Extract the type (ecosystem) part
-	typePart := parts[0]
-	if strings.HasPrefix(typePart, "pkg:") {
-		ecosystem := strings.TrimPrefix(typePart, "pkg:")
-		return vsa.mapPURLTypeToOSVEcosystem(ecosystem)
+	var vuln OSVVulnerability
+	if err := json.NewDecoder(resp.Body).Decode(&vuln); err != nil {
+		return nil, fmt.Errorf("failed to decode OSV API response: %w", err)
 	}
 
-	return ""
+	return &vuln, nil
+}
+
+// extractEcosystemFromPURL extracts the ecosystem from a Package URL (PURL).
+func (vsa *VulnerabilityScanningAgent) extractEcosystemFromPURL(purl string) string {
+	parsed, err := packageurl.Parse(purl)
+	if err != nil {
+		return ""
+	}
+	return vsa.mapPURLTypeToOSVEcosystem(parsed.Type)
 }
 
 // mapPURLTypeToOSVEcosystem maps PURL types to OSV ecosystem names.
@@ -254,6 +431,22 @@ func (vsa *VulnerabilityScanningAgent) determineSeverity(vuln OSVVulnerability)
 		}
 	}
 
+	// Ubuntu rates vulnerabilities by its own priority
+	for _, sev := range vuln.Severity {
+		if sev.Type == "Ubuntu" {
+			switch strings.ToLower(sev.Score) {
+			case "critical":
+				return "Critical"
+			case "high":
+				return "High"
+			case "medium":
+				return "Medium"
+			case "low", "negligible":
+				return "Low"
+			}
+		}
+	}
+
 	// Check database-specific severity
 	if vuln.DatabaseSpecific.Severity != "" {
 		switch strings.ToUpper(vuln.DatabaseSpecific.Severity) {
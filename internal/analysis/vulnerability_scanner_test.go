@@ -6,9 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/cache"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVulnerabilityScanningAgent_Name(t *testing.T) {
@@ -511,3 +514,67 @@ func TestVulnerabilityScanningAgent_ServerError(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(results))
 }
+
+func TestVulnerabilityScanningAgent_CPEOnlyComponent(t *testing.T) {
+	var queries []OSVQueryRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query OSVQueryRequest
+		json.NewDecoder(r.Body).Decode(&query)
+		queries = append(queries, query)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"vulns": [{"id": "GHSA-p6mc-m468-83gw", "summary": "Prototype Pollution in lodash"}]}`))
+	}))
+	defer mockServer.Close()
+
+	agent := NewVulnerabilityScanningAgent()
+	agent.apiBaseURL = mockServer.URL
+
+	results, err := agent.Analyze(context.Background(), core.SBOM{
+		Components: []core.Component{
+			{Name: "lodash-bundle", Version: "4.17.15", CPE: "cpe:2.3:a:lodash:lodash:4.17.15:*:*:*:*:node.js:*:*"},
+			// Without target software the CPE names no ecosystem to query
+			{Name: "openssl", Version: "1.1.1k", CPE: "cpe:2.3:a:openssl:openssl:1.1.1k:*:*:*:*:*:*:*"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, "npm", queries[0].Package.Ecosystem)
+		assert.Equal(t, "lodash", queries[0].Package.Name)
+		assert.Equal(t, "4.17.15", queries[0].Version)
+	}
+}
+
+func TestVulnerabilityScanningAgent_Cache(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"vulns": [{"id": "GHSA-p6mc-m468-83gw", "summary": "Prototype Pollution in lodash"}]}`))
+	}))
+	defer mockServer.Close()
+
+	responses, err := cache.New(cache.Config{MaxEntries: 10, DefaultTTL: time.Hour})
+	require.NoError(t, err)
+
+	agent := NewVulnerabilityScanningAgent()
+	agent.apiBaseURL = mockServer.URL
+	agent.cache = responses
+
+	sbom := core.SBOM{Components: []core.Component{{Name: "lodash", Version: "4.17.15", PURL: "pkg:npm/lodash@4.17.15"}}}
+	for i := 0; i < 3; i++ {
+		results, err := agent.Analyze(context.Background(), sbom)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	}
+	assert.Equal(t, 1, requests)
+
+	// Another version is another lookup
+	sbom.Components[0].Version = "4.17.21"
+	sbom.Components[0].PURL = "pkg:npm/lodash@4.17.21"
+	_, err = agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
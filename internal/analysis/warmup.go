@@ -0,0 +1,149 @@
+// Package analysis provides the preparation of the Ollama models of the
+// AI-powered agents before an analysis runs them.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// pullModelsFromEnv reports whether missing Ollama models are pulled rather
+// than failing the agents that use them, configured through the
+// OLLAMA_PULL_MODELS environment variable.
+func pullModelsFromEnv() bool {
+	value := os.Getenv("OLLAMA_PULL_MODELS")
+	if value == "" {
+		return false
+	}
+	pull, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Printf("Warning: Ignoring invalid OLLAMA_PULL_MODELS %q\n", value)
+		return false
+	}
+	return pull
+}
+
+// modelProbes prepares each Ollama model once. Agents needing a model that
+// is being prepared wait for that preparation rather than starting another;
+// a failed preparation is retried by the next analysis.
+type modelProbes struct {
+	mu     sync.Mutex
+	probes map[string]*modelProbe
+}
+
+// modelProbe is the preparation of one model on one Ollama server.
+type modelProbe struct {
+	done chan struct{}
+	err  error
+}
+
+// newModelProbes creates an empty set of model preparations.
+func newModelProbes() *modelProbes {
+	return &modelProbes{probes: make(map[string]*modelProbe)}
+}
+
+// prepare makes sure model is available on the Ollama server at host; see
+// PrepareOllamaModel.
+func (p *modelProbes) prepare(ctx context.Context, host string, model OllamaModel, pull bool) error {
+	key := host + " " + model.Name
+
+	p.mu.Lock()
+	probe, started := p.probes[key]
+	if !started {
+		probe = &modelProbe{done: make(chan struct{})}
+		p.probes[key] = probe
+	}
+	p.mu.Unlock()
+
+	if started {
+		select {
+		case <-probe.done:
+			return probe.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	probe.err = PrepareOllamaModel(ctx, host, model, pull)
+	if probe.err != nil {
+		p.mu.Lock()
+		delete(p.probes, key)
+		p.mu.Unlock()
+	}
+	close(probe.done)
+	return probe.err
+}
+
+// prepareAgent prepares every model of agent.
+func (p *modelProbes) prepareAgent(ctx context.Context, agent ModelAgent, pull bool) error {
+	host, models := agent.OllamaModels()
+	for _, model := range models {
+		if err := p.prepare(ctx, host, model, pull); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetPullModels sets whether Ollama models the agents need but the server
+// lacks are pulled, instead of failing those agents. It defaults to
+// OLLAMA_PULL_MODELS.
+func (o *Orchestrator) SetPullModels(pull bool) {
+	o.pullModels = pull
+}
+
+// PrepareModels makes sure the Ollama models of every registered ModelAgent
+// are available and loaded, pulling missing ones if enabled. Run does the
+// same before starting each of these agents, within its time limit; calling
+// PrepareModels first fails fast on a missing model, before any agent runs,
+// and takes pulling and loading out of the agents' time limits. Models are
+// prepared once per orchestrator.
+func (o *Orchestrator) PrepareModels(ctx context.Context) error {
+	var errs []error
+	failed := make(map[string]bool)
+	for _, registered := range o.agents {
+		agent, ok := modelAgent(registered.agent)
+		if !ok {
+			continue
+		}
+		if err := o.models.prepareAgent(ctx, agent, o.pullModels); err != nil && !failed[err.Error()] {
+			// Agents sharing a model report its problem once
+			failed[err.Error()] = true
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// modelAgent returns the agent as a ModelAgent, looking through the wrapper
+// of a deduplicated agent, if it prompts Ollama models.
+func modelAgent(agent AnalysisAgent) (ModelAgent, bool) {
+	if deduplicated, ok := agent.(*dedupAgent); ok {
+		agent = deduplicated.ComponentAgent
+	}
+	model, ok := agent.(ModelAgent)
+	return model, ok
+}
+
+// preparedAgent runs a ModelAgent once its models are prepared, failing
+// without running it if they cannot be.
+type preparedAgent struct {
+	AnalysisAgent
+	model  ModelAgent
+	probes *modelProbes
+	pull   bool
+}
+
+// Analyze prepares the agent's models and then runs it.
+func (a *preparedAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	if err := a.probes.prepareAgent(ctx, a.model, a.pull); err != nil {
+		return nil, err
+	}
+	return a.AnalysisAgent.Analyze(ctx, sbom)
+}
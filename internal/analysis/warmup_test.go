@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOllama serves an Ollama API with the given models installed, counting
+// the requests to each path.
+func mockOllama(t *testing.T, installed ...string) (*httptest.Server, map[string]*atomic.Int32) {
+	requests := map[string]*atomic.Int32{"/api/tags": {}, "/api/pull": {}, "/api/generate": {}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path].Add(1)
+		switch r.URL.Path {
+		case "/api/tags":
+			body := `{"models": [`
+			for i, name := range installed {
+				if i > 0 {
+					body += ","
+				}
+				body += `{"name": "` + name + `"}`
+			}
+			w.Write([]byte(body + `]}`))
+		case "/api/pull":
+			w.Write([]byte(`{"status": "success"}`))
+		default:
+			w.Write([]byte(`{"response": "This is a healthy, well-maintained project.", "done": true}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestPrepareOllamaModel(t *testing.T) {
+	server, requests := mockOllama(t, "llama3:latest", "nomic-embed-text:latest")
+	ctx := context.Background()
+
+	// Installed generation models are loaded, embedding models only checked
+	require.NoError(t, PrepareOllamaModel(ctx, server.URL, OllamaModel{Name: "llama3"}, false))
+	require.NoError(t, PrepareOllamaModel(ctx, server.URL, OllamaModel{Name: "nomic-embed-text", Embedding: true}, false))
+	assert.Equal(t, int32(1), requests["/api/generate"].Load())
+
+	// Missing models fail with how to install them, unless pulled
+	err := PrepareOllamaModel(ctx, server.URL, OllamaModel{Name: "mistral"}, false)
+	assert.ErrorContains(t, err, `model "mistral" is not installed on Ollama at `+server.URL)
+	assert.ErrorContains(t, err, "run 'ollama pull mistral' or set OLLAMA_MODEL")
+	err = PrepareOllamaModel(ctx, server.URL, OllamaModel{Name: "all-minilm", Embedding: true}, false)
+	assert.ErrorContains(t, err, "set OLLAMA_EMBEDDING_MODEL")
+	assert.Zero(t, requests["/api/pull"].Load())
+
+	require.NoError(t, PrepareOllamaModel(ctx, server.URL, OllamaModel{Name: "mistral"}, true))
+	assert.Equal(t, int32(1), requests["/api/pull"].Load())
+
+	server.Close()
+	err = PrepareOllamaModel(ctx, server.URL, OllamaModel{Name: "llama3"}, false)
+	assert.ErrorContains(t, err, "Ollama is not reachable at "+server.URL)
+	assert.ErrorContains(t, err, "set OLLAMA_HOST")
+}
+
+func TestOrchestrator_MissingModelFailsFast(t *testing.T) {
+	t.Setenv("OLLAMA_PULL_MODELS", "")
+	server, requests := mockOllama(t, "nomic-embed-text:latest")
+
+	agent := NewDependencyHealthAgent()
+	agent.ollamaURL = server.URL + "/api/generate"
+	agent.model = "llama3"
+
+	orchestrator := NewOrchestrator(NewAgentTimeouts())
+	orchestrator.Add(agent)
+	orchestrator.Add(&stubAgent{name: "Quality Agent"})
+
+	// The missing model is reported up front
+	err := orchestrator.PrepareModels(context.Background())
+	assert.ErrorContains(t, err, `model "llama3" is not installed`)
+
+	// Without it the agent fails without prompting, and the others still run
+	sbom := core.SBOM{Components: []core.Component{{Name: "a", Version: "1.0.0"}, {Name: "b", Version: "2.0.0"}}}
+	report, err := orchestrator.Run(context.Background(), sbom)
+	require.NoError(t, err)
+	assert.Equal(t, AgentStatusFailed, report.Runs[0].Status)
+	assert.ErrorContains(t, report.Runs[0].Err, `model "llama3" is not installed`)
+	assert.Equal(t, AgentStatusOK, report.Runs[1].Status)
+	assert.Zero(t, requests["/api/generate"].Load())
+}
+
+func TestOrchestrator_PrepareModels_Once(t *testing.T) {
+	t.Setenv("OLLAMA_PULL_MODELS", "true")
+	server, requests := mockOllama(t)
+
+	agent := NewDependencyHealthAgent()
+	agent.ollamaURL = server.URL + "/api/generate"
+	agent.model = "llama3"
+
+	orchestrator := NewOrchestrator(NewAgentTimeouts())
+	orchestrator.Add(agent)
+
+	// The missing model is pulled and loaded once for every analysis
+	require.NoError(t, orchestrator.PrepareModels(context.Background()))
+	sbom := core.SBOM{Components: []core.Component{{Name: "a", Version: "1.0.0"}}}
+	for range 2 {
+		report, err := orchestrator.Run(context.Background(), sbom)
+		require.NoError(t, err)
+		assert.Equal(t, AgentStatusOK, report.Runs[0].Status)
+	}
+	assert.Equal(t, int32(1), requests["/api/tags"].Load())
+	assert.Equal(t, int32(1), requests["/api/pull"].Load())
+	// One request loads the model, one per analysis assesses the component
+	assert.Equal(t, int32(3), requests["/api/generate"].Load())
+}
@@ -0,0 +1,348 @@
+// Package apply provides the reconciliation of an SBOM Sentinel server with
+// a declarative file, as run by `sentinel-cli apply`. The file is the
+// server's configuration file (projects, policies, waivers, notification
+// channels, API keys and the other settings) with an optional watchlist
+// section:
+//
+//	projects:
+//	  storefront:
+//	    distribution: saas
+//	waivers:
+//	  - match: vuln:CVE-2021-44228
+//	    reason: JNDI lookups are disabled (SEC-142)
+//	watchlist:
+//	  - purl: pkg:npm/lodash
+//	    note: Used by every frontend
+//
+// The configuration file is replaced as a whole, while the watchlist is
+// reconciled through the watchlist API. A file with only a watchlist
+// section leaves the configuration file alone, and one without it leaves
+// the watches alone.
+package apply
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/watchlist"
+	"github.com/hueyexe/SBOM-Sentinel/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+// WatchlistSection is the section of the file declaring the watchlist.
+const WatchlistSection = "watchlist"
+
+// Actions of a change.
+const (
+	ActionAdd    = "add"
+	ActionChange = "change"
+	ActionRemove = "remove"
+)
+
+// Server is the part of the API a file is applied through; *client.Client
+// implements it.
+type Server interface {
+	Config(ctx context.Context) ([]byte, error)
+	ApplyConfig(ctx context.Context, document []byte) (*client.ReloadResponse, error)
+	ListWatches(ctx context.Context) ([]client.Watch, error)
+	AddWatch(ctx context.Context, purl, note string) (*client.Watch, error)
+	DeleteWatch(ctx context.Context, id string) (bool, error)
+}
+
+// Change is an entry of a section that applying the file adds, changes or
+// removes, such as the project "storefront" or the waiver
+// "vuln:CVE-2021-44228". Key is empty for settings changed as a whole.
+type Change struct {
+	Action  string `json:"action"`
+	Section string `json:"section"`
+	Key     string `json:"key,omitempty"`
+}
+
+// String returns the change as listed in a plan, e.g. "+ projects.storefront".
+func (c Change) String() string {
+	symbol := map[string]string{ActionAdd: "+", ActionChange: "~", ActionRemove: "-"}[c.Action]
+	if c.Key == "" {
+		return symbol + " " + c.Section
+	}
+	return symbol + " " + c.Section + "." + c.Key
+}
+
+// Watch is an entry of the watchlist section.
+type Watch struct {
+	PURL string `yaml:"purl"`
+	Note string `yaml:"note"`
+}
+
+// Plan is what applying a file changes on a server.
+type Plan struct {
+	Changes []Change
+
+	// config is the configuration file to apply, nil if it is unchanged
+	config []byte
+	// addWatches and removeWatches reconcile the watchlist; a changed
+	// note replaces the watch
+	addWatches    []Watch
+	removeWatches []string
+}
+
+// Empty reports whether the server already matches the file.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Counts returns the number of additions, changes and removals.
+func (p *Plan) Counts() (add, change, remove int) {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionAdd:
+			add++
+		case ActionChange:
+			change++
+		case ActionRemove:
+			remove++
+		}
+	}
+	return add, change, remove
+}
+
+// NewPlan compares document with the configuration file and watchlist of
+// server and returns what applying it changes.
+func NewPlan(ctx context.Context, server Server, document []byte) (*Plan, error) {
+	config, watches, managesWatchlist, err := split(document)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	if config != nil {
+		current, err := server.Config(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the server's configuration: %w", err)
+		}
+		changes, err := diffConfig(current, config)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			plan.Changes = changes
+			plan.config = config
+		}
+	}
+
+	if managesWatchlist {
+		if err := plan.diffWatchlist(ctx, server, watches); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// Apply makes the changes of the plan on server. It returns the
+// configuration in effect if the configuration file was replaced, and nil
+// otherwise. The configuration is applied first, so that a file the server
+// rejects changes nothing.
+func (p *Plan) Apply(ctx context.Context, server Server) (*client.ReloadResponse, error) {
+	var response *client.ReloadResponse
+	if p.config != nil {
+		var err error
+		if response, err = server.ApplyConfig(ctx, p.config); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range p.removeWatches {
+		if _, err := server.DeleteWatch(ctx, id); err != nil {
+			return response, fmt.Errorf("failed to remove watch %s: %w", id, err)
+		}
+	}
+	for _, watch := range p.addWatches {
+		if _, err := server.AddWatch(ctx, watch.PURL, watch.Note); err != nil {
+			return response, fmt.Errorf("failed to watch %s: %w", watch.PURL, err)
+		}
+	}
+	return response, nil
+}
+
+// split separates the watchlist section from the configuration file in
+// document, which is returned unchanged if it has no watchlist section and
+// nil if it has no other section.
+func split(document []byte) (config []byte, watches []Watch, managesWatchlist bool, err error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(document, &root); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to parse file: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil, false, nil
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, nil, false, errors.New("failed to parse file: expected a mapping of sections")
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != WatchlistSection {
+			continue
+		}
+		if err := mapping.Content[i+1].Decode(&watches); err != nil {
+			return nil, nil, false, fmt.Errorf("invalid %s section: %w", WatchlistSection, err)
+		}
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		if len(mapping.Content) == 0 {
+			return nil, watches, true, nil
+		}
+
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&root); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		return buffer.Bytes(), watches, true, nil
+	}
+	return document, nil, false, nil
+}
+
+// diffConfig lists the changes from the current to the desired
+// configuration file, by section and entry.
+func diffConfig(current, desired []byte) ([]Change, error) {
+	var before, after map[string]interface{}
+	if err := yaml.Unmarshal(current, &before); err != nil {
+		return nil, fmt.Errorf("failed to parse the server's configuration: %w", err)
+	}
+	if err := yaml.Unmarshal(desired, &after); err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	var changes []Change
+	for _, section := range sortedKeys(before, after) {
+		changes = append(changes, diffSection(section, before[section], after[section])...)
+	}
+	return changes, nil
+}
+
+// diffSection lists the changes of a section. The entries of mappings are
+// compared by key, and those of lists by their name, match, path or purl,
+// so that a plan names what changes; other values are compared whole.
+func diffSection(section string, before, after interface{}) []Change {
+	beforeEntries, beforeKeyed := entries(before)
+	afterEntries, afterKeyed := entries(after)
+	if !(beforeKeyed || before == nil) || !(afterKeyed || after == nil) {
+		switch {
+		case before == nil:
+			return []Change{{Action: ActionAdd, Section: section}}
+		case after == nil:
+			return []Change{{Action: ActionRemove, Section: section}}
+		case !reflect.DeepEqual(before, after):
+			return []Change{{Action: ActionChange, Section: section}}
+		}
+		return nil
+	}
+
+	var changes []Change
+	for _, key := range sortedKeys(beforeEntries, afterEntries) {
+		b, inBefore := beforeEntries[key]
+		a, inAfter := afterEntries[key]
+		switch {
+		case !inBefore:
+			changes = append(changes, Change{Action: ActionAdd, Section: section, Key: key})
+		case !inAfter:
+			changes = append(changes, Change{Action: ActionRemove, Section: section, Key: key})
+		case !reflect.DeepEqual(b, a):
+			changes = append(changes, Change{Action: ActionChange, Section: section, Key: key})
+		}
+	}
+	return changes
+}
+
+// entries returns the entries of a mapping or list section by key, and
+// whether value is one.
+func entries(value interface{}) (map[string]interface{}, bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return value, true
+	case []interface{}:
+		keyed := make(map[string]interface{}, len(value))
+		for i, item := range value {
+			keyed[entryKey(item, i)] = item
+		}
+		return keyed, true
+	}
+	return nil, false
+}
+
+// entryKey names an entry of a list section.
+func entryKey(item interface{}, index int) string {
+	if fields, ok := item.(map[string]interface{}); ok {
+		for _, field := range []string{"name", "match", "path", "purl"} {
+			if value, ok := fields[field].(string); ok && value != "" {
+				return value
+			}
+		}
+	}
+	if value, ok := item.(string); ok {
+		return value
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
+
+// diffWatchlist plans the additions and removals making the server's
+// watchlist match watches.
+func (p *Plan) diffWatchlist(ctx context.Context, server Server, watches []Watch) error {
+	// Watches are compared as the server stores them, with a canonical PURL
+	desired := make(map[string]Watch, len(watches))
+	for _, watch := range watches {
+		canonical, err := watchlist.NewWatch(watch.PURL, watch.Note)
+		if err != nil {
+			return fmt.Errorf("invalid %s entry %q: %w", WatchlistSection, watch.PURL, err)
+		}
+		desired[canonical.PURL] = Watch{PURL: canonical.PURL, Note: canonical.Note}
+	}
+
+	current, err := server.ListWatches(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the server's watches: %w", err)
+	}
+	existing := make(map[string]client.Watch, len(current))
+	for _, watch := range current {
+		existing[watch.PURL] = watch
+	}
+
+	for _, purl := range sortedKeys(existing, desired) {
+		want, declared := desired[purl]
+		have, watched := existing[purl]
+		switch {
+		case !watched:
+			p.Changes = append(p.Changes, Change{Action: ActionAdd, Section: WatchlistSection, Key: purl})
+			p.addWatches = append(p.addWatches, want)
+		case !declared:
+			p.Changes = append(p.Changes, Change{Action: ActionRemove, Section: WatchlistSection, Key: purl})
+			p.removeWatches = append(p.removeWatches, have.ID)
+		case have.Note != want.Note:
+			p.Changes = append(p.Changes, Change{Action: ActionChange, Section: WatchlistSection, Key: purl})
+			p.removeWatches = append(p.removeWatches, have.ID)
+			p.addWatches = append(p.addWatches, want)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of both maps in sorted order.
+func sortedKeys[V1, V2 any](a map[string]V1, b map[string]V2) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for key := range a {
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,146 @@
+package apply
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/hueyexe/SBOM-Sentinel/internal/transport/rest"
+	"github.com/hueyexe/SBOM-Sentinel/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServer serves the configuration and watchlist endpoints, with the
+// configuration file in a temporary directory.
+func newServer(t *testing.T) *client.Client {
+	configtest.SetFile(t, filepath.Join(t.TempDir(), "sentinel.yaml"))
+
+	repo := memory.NewMemoryRepository()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/admin/config", rest.ConfigHandler())
+	mux.HandleFunc("/api/v1/watchlist", rest.WatchlistHandler(repo))
+	mux.HandleFunc("/api/v1/watchlist/{id}", rest.WatchlistHandler(repo))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return client.New(server.URL)
+}
+
+const file = `projects:
+  storefront:
+    distribution: saas
+waivers:
+  - match: vuln:CVE-2021-44228
+    reason: JNDI lookups are disabled (SEC-142)
+watchlist:
+  - purl: pkg:npm/lodash
+    note: Used by every frontend
+  - purl: pkg:npm/left-pad
+`
+
+func changes(plan *Plan) []string {
+	var listed []string
+	for _, change := range plan.Changes {
+		listed = append(listed, change.String())
+	}
+	return listed
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+
+	plan, err := NewPlan(ctx, server, []byte(file))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"+ projects.storefront",
+		"+ waivers.vuln:CVE-2021-44228",
+		"+ watchlist.pkg:npm/left-pad",
+		"+ watchlist.pkg:npm/lodash",
+	}, changes(plan))
+	add, change, remove := plan.Counts()
+	assert.Equal(t, []int{4, 0, 0}, []int{add, change, remove})
+
+	response, err := plan.Apply(ctx, server)
+	require.NoError(t, err)
+	require.NotNil(t, response)
+	assert.Equal(t, 1, response.Projects)
+	assert.Len(t, config.Default().Waivers, 1)
+	watches, err := server.ListWatches(ctx)
+	require.NoError(t, err)
+	assert.Len(t, watches, 2)
+	document, err := server.Config(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, string(document), "watchlist", "the watchlist is not part of the configuration file")
+
+	// Applying the file again changes nothing
+	plan, err = NewPlan(ctx, server, []byte(file))
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), changes(plan))
+}
+
+func TestPlan_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+	plan, err := NewPlan(ctx, server, []byte(file))
+	require.NoError(t, err)
+	_, err = plan.Apply(ctx, server)
+	require.NoError(t, err)
+
+	changed := `projects:
+  storefront:
+    distribution: distributed
+  desktop-client:
+    license: GPL-3.0-only
+waivers:
+  - match: vuln:CVE-2021-44228
+    reason: JNDI lookups are disabled (SEC-142)
+watchlist:
+  - purl: pkg:npm/lodash
+    note: Only in the storefront now
+`
+	plan, err = NewPlan(ctx, server, []byte(changed))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"+ projects.desktop-client",
+		"~ projects.storefront",
+		"- watchlist.pkg:npm/left-pad",
+		"~ watchlist.pkg:npm/lodash",
+	}, changes(plan))
+
+	_, err = plan.Apply(ctx, server)
+	require.NoError(t, err)
+	watches, err := server.ListWatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, watches, 1)
+	assert.Equal(t, "Only in the storefront now", watches[0].Note)
+
+	// A file with only a watchlist leaves the configuration file alone
+	plan, err = NewPlan(ctx, server, []byte("watchlist: []\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"- watchlist.pkg:npm/lodash"}, changes(plan))
+}
+
+func TestPlan_Invalid(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+
+	_, err := NewPlan(ctx, server, []byte("watchlist:\n  - purl: lodash\n"))
+	assert.ErrorContains(t, err, `invalid watchlist entry "lodash"`)
+	_, err = NewPlan(ctx, server, []byte("- projects\n"))
+	assert.ErrorContains(t, err, "expected a mapping of sections")
+
+	// The server rejects an invalid configuration before the watchlist is changed
+	plan, err := NewPlan(ctx, server, []byte("waivers:\n  - match: vuln:CVE-2021-44228\nwatchlist:\n  - purl: pkg:npm/lodash\n"))
+	require.NoError(t, err)
+	_, err = plan.Apply(ctx, server)
+	assert.ErrorContains(t, err, "invalid waiver 1: reason is required")
+	watches, err := server.ListWatches(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, watches)
+}
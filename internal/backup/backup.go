@@ -0,0 +1,342 @@
+// Package backup provides export and import of the SBOM Sentinel database
+// and policies as a single archive, for backups and migrations between
+// instances.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// FormatVersion is the archive format written by Export. Import rejects
+// archives with a different version.
+const FormatVersion = 1
+
+const (
+	manifestName   = "manifest.json"
+	sbomPrefix     = "sboms/"
+	policiesPrefix = "policies/"
+)
+
+// Store is a repository whose SBOMs can be exported and restored with
+// their submission times.
+type Store interface {
+	storage.Repository
+	storage.Pruner
+	storage.Restorer
+}
+
+// Manifest describes the contents of an archive. It is the first entry of
+// the archive.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// SBOMs is the number of SBOMs in the archive
+	SBOMs int `json:"sboms"`
+	// Policies lists the policy and data files in the archive, relative to
+	// the policy directory
+	Policies []string `json:"policies"`
+}
+
+// sbomEntry is an archived SBOM with its submission time.
+type sbomEntry struct {
+	CreatedAt time.Time `json:"created_at"`
+	SBOM      core.SBOM `json:"sbom"`
+}
+
+// Export writes every SBOM in store and the files in policyPaths to w as a
+// gzip-compressed tar archive. Policy paths are Rego policy files, data
+// files such as waivers, or directories of them; a directory's files are
+// archived relative to it.
+func Export(ctx context.Context, w io.Writer, store Store, policyPaths []string) (*Manifest, error) {
+	records, err := store.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := collectPolicies(policyPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		CreatedAt: core.Now().UTC(),
+		SBOMs:     len(records),
+		Policies:  make([]string, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		manifest.Policies = append(manifest.Policies, policy.name)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeJSON(tw, manifestName, manifest); err != nil {
+		return nil, err
+	}
+
+	for i, record := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		sbom, err := store.FindByID(ctx, record.ID)
+		if err != nil {
+			return nil, err
+		}
+		if sbom == nil {
+			return nil, fmt.Errorf("SBOM %s was deleted during the export", record.ID)
+		}
+
+		// Entries are numbered because IDs are not necessarily valid file names
+		name := fmt.Sprintf("%s%06d.json", sbomPrefix, i+1)
+		if err := writeJSON(tw, name, sbomEntry{CreatedAt: record.CreatedAt, SBOM: *sbom}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, policy := range policies {
+		data, err := os.ReadFile(policy.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
+		}
+		if err := writeFile(tw, policiesPrefix+policy.name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// policyFile is a policy or data file to archive under name.
+type policyFile struct {
+	path string
+	name string
+}
+
+// collectPolicies lists the files in paths and the names they are archived
+// under. Two files with the same name are an error.
+func collectPolicies(paths []string) ([]policyFile, error) {
+	var policies []policyFile
+	seen := make(map[string]string)
+
+	add := func(filePath, name string) error {
+		name = filepath.ToSlash(name)
+		if previous, ok := seen[name]; ok {
+			return fmt.Errorf("policies %s and %s would both be archived as %s", previous, filePath, name)
+		}
+		seen[name] = filePath
+		policies = append(policies, policyFile{path: filePath, name: name})
+		return nil
+	}
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
+		}
+		if !info.IsDir() {
+			if err := add(root, filepath.Base(root)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.Type().IsRegular() {
+				return err
+			}
+			name, err := filepath.Rel(root, filePath)
+			if err != nil {
+				return err
+			}
+			return add(filePath, name)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policies in %s: %w", root, err)
+		}
+	}
+	return policies, nil
+}
+
+// writeJSON adds value to the archive as a JSON file.
+func writeJSON(tw *tar.Writer, name string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return writeFile(tw, name, data)
+}
+
+// writeFile adds a regular file to the archive.
+func writeFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// ImportResult describes what an import restored.
+type ImportResult struct {
+	Manifest Manifest
+	// SBOMs is the number of SBOMs restored
+	SBOMs int
+	// Policies lists the paths of the policy files written
+	Policies []string
+}
+
+// Import restores the SBOMs in an archive written by Export into store,
+// replacing SBOMs with the same IDs, and writes its policy files into
+// policyDir. Policy files are skipped if policyDir is empty, and existing
+// files are only overwritten if overwritePolicies is set. If store is a
+// storage.Transactor the SBOMs are restored in one transaction, so a failed
+// import restores none of them.
+func Import(ctx context.Context, r io.Reader, store Store, policyDir string, overwritePolicies bool) (*ImportResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != manifestName {
+		return nil, errors.New("not a backup archive: missing manifest")
+	}
+	result := &ImportResult{Policies: []string{}}
+	if err := json.NewDecoder(tr).Decode(&result.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if result.Manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d (expected %d)", result.Manifest.Version, FormatVersion)
+	}
+
+	transactor, ok := store.(storage.Transactor)
+	if !ok {
+		return result, restoreEntries(ctx, tr, store, policyDir, overwritePolicies, result)
+	}
+	err = transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return restoreEntries(ctx, tr, store, policyDir, overwritePolicies, result)
+	})
+	if err != nil {
+		// The restored SBOMs were rolled back
+		result.SBOMs = 0
+	}
+	return result, err
+}
+
+// restoreEntries restores the SBOMs and policy files following the manifest
+// of an archive, counting them in result.
+func restoreEntries(ctx context.Context, tr *tar.Reader, store Store, policyDir string, overwritePolicies bool, result *ImportResult) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(header.Name, sbomPrefix):
+			var entry sbomEntry
+			if err := json.NewDecoder(tr).Decode(&entry); err != nil {
+				return fmt.Errorf("invalid SBOM %s: %w", header.Name, err)
+			}
+			if entry.SBOM.ID == "" {
+				return fmt.Errorf("invalid SBOM %s: missing ID", header.Name)
+			}
+			if err := store.Restore(ctx, entry.SBOM, entry.CreatedAt); err != nil {
+				return err
+			}
+			result.SBOMs++
+
+		case strings.HasPrefix(header.Name, policiesPrefix):
+			if policyDir == "" {
+				continue
+			}
+			written, err := restorePolicy(tr, policyDir, strings.TrimPrefix(header.Name, policiesPrefix), overwritePolicies)
+			if err != nil {
+				return err
+			}
+			if written != "" {
+				result.Policies = append(result.Policies, written)
+			}
+		}
+	}
+
+	if result.SBOMs != result.Manifest.SBOMs {
+		return fmt.Errorf("archive is incomplete: restored %d of %d SBOMs", result.SBOMs, result.Manifest.SBOMs)
+	}
+	return nil
+}
+
+// restorePolicy writes a policy file from the archive into dir, returning
+// its path, or "" if it exists and overwrite is not set.
+func restorePolicy(r io.Reader, dir, name string, overwrite bool) (string, error) {
+	// Archived names must stay inside the policy directory
+	name = path.Clean(name)
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("invalid policy name %q in archive", name)
+	}
+	target := filepath.Join(dir, filepath.FromSlash(name))
+
+	if !overwrite {
+		if _, err := os.Stat(target); err == nil {
+			return "", nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create policy directory: %w", err)
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to write policy: %w", err)
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write policy: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write policy: %w", err)
+	}
+	return target, nil
+}
@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRepository(t *testing.T) *database.SQLiteRepository {
+	t.Helper()
+	repo, err := database.NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func writePolicy(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := newRepository(t)
+
+	submitted := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	sbom := core.SBOM{
+		ID:         "sbom-1",
+		Name:       "api",
+		Components: []core.Component{{Name: "lodash", Version: "4.17.21", License: "MIT", PURL: "pkg:npm/lodash@4.17.21"}},
+		Metadata:   map[string]string{"format": "CycloneDX"},
+	}
+	require.NoError(t, source.Restore(ctx, sbom, submitted))
+	require.NoError(t, source.Store(ctx, core.SBOM{ID: "sbom-2", Name: "web", Metadata: map[string]string{}}))
+
+	policyDir := filepath.Join(t.TempDir(), "policies")
+	writePolicy(t, filepath.Join(policyDir, "gate.rego"), "package sentinel\n")
+	writePolicy(t, filepath.Join(policyDir, "data", "waivers.json"), `{"waivers": {}}`)
+
+	var archive bytes.Buffer
+	manifest, err := Export(ctx, &archive, source, []string{policyDir})
+	require.NoError(t, err)
+	assert.Equal(t, 2, manifest.SBOMs)
+	assert.ElementsMatch(t, []string{"gate.rego", "data/waivers.json"}, manifest.Policies)
+
+	target := newRepository(t)
+	restoreDir := filepath.Join(t.TempDir(), "restored")
+	result, err := Import(ctx, bytes.NewReader(archive.Bytes()), target, restoreDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.SBOMs)
+	assert.Len(t, result.Policies, 2)
+
+	restored, err := target.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+	assert.Equal(t, sbom.Components, restored.Components)
+	assert.Equal(t, sbom.Metadata, restored.Metadata)
+
+	// Submission times survive so that retention keeps working after a migration
+	records, err := target.ListRecords(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "sbom-1", records[0].ID)
+	assert.True(t, submitted.Equal(records[0].CreatedAt))
+
+	waivers, err := os.ReadFile(filepath.Join(restoreDir, "data", "waivers.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"waivers": {}}`, string(waivers))
+
+	// Existing policies are kept unless overwriting is requested
+	writePolicy(t, filepath.Join(restoreDir, "gate.rego"), "package local\n")
+	result, err = Import(ctx, bytes.NewReader(archive.Bytes()), target, restoreDir, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Policies)
+	gate, err := os.ReadFile(filepath.Join(restoreDir, "gate.rego"))
+	require.NoError(t, err)
+	assert.Equal(t, "package local\n", string(gate))
+
+	_, err = Import(ctx, bytes.NewReader(archive.Bytes()), target, restoreDir, true)
+	require.NoError(t, err)
+	gate, err = os.ReadFile(filepath.Join(restoreDir, "gate.rego"))
+	require.NoError(t, err)
+	assert.Equal(t, "package sentinel\n", string(gate))
+}
+
+func TestExport_DuplicatePolicyNames(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, filepath.Join(dir, "a", "gate.rego"), "package sentinel\n")
+	writePolicy(t, filepath.Join(dir, "b", "gate.rego"), "package sentinel\n")
+
+	var archive bytes.Buffer
+	_, err := Export(context.Background(), &archive, newRepository(t),
+		[]string{filepath.Join(dir, "a", "gate.rego"), filepath.Join(dir, "b", "gate.rego")})
+	assert.ErrorContains(t, err, "would both be archived as gate.rego")
+}
+
+// archiveOf builds a gzip-compressed tar archive of the given files.
+func archiveOf(t *testing.T, files map[string]string, order ...string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range order {
+		require.NoError(t, writeFile(tw, name, []byte(files[name])))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return &buf
+}
+
+func TestImport_InvalidArchives(t *testing.T) {
+	ctx := context.Background()
+	manifest := `{"version": 1, "sboms": 1, "policies": ["../escape.rego"]}`
+
+	tests := []struct {
+		name     string
+		archive  *bytes.Buffer
+		expected string
+	}{
+		{
+			"not gzip",
+			bytes.NewBufferString("SQLite format 3"),
+			"not a backup archive",
+		},
+		{
+			"missing manifest",
+			archiveOf(t, map[string]string{"sboms/000001.json": "{}"}, "sboms/000001.json"),
+			"missing manifest",
+		},
+		{
+			"unsupported version",
+			archiveOf(t, map[string]string{manifestName: `{"version": 99}`}, manifestName),
+			"unsupported backup format version 99",
+		},
+		{
+			"policy outside the directory",
+			archiveOf(t, map[string]string{manifestName: manifest, "policies/../escape.rego": "package x"}, manifestName, "policies/../escape.rego"),
+			"invalid policy name",
+		},
+		{
+			"truncated",
+			archiveOf(t, map[string]string{manifestName: manifest}, manifestName),
+			"restored 0 of 1 SBOMs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Import(ctx, tt.archive, newRepository(t), t.TempDir(), false)
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestImport_RollsBackIncompleteArchive(t *testing.T) {
+	ctx := context.Background()
+	archive := archiveOf(t, map[string]string{
+		manifestName:        `{"version": 1, "sboms": 2}`,
+		"sboms/000001.json": `{"sbom": {"id": "sbom-1", "name": "api"}, "created_at": "2025-01-01T00:00:00Z"}`,
+	}, manifestName, "sboms/000001.json")
+
+	target := newRepository(t)
+	result, err := Import(ctx, archive, target, "", false)
+	assert.ErrorContains(t, err, "restored 1 of 2 SBOMs")
+	assert.Zero(t, result.SBOMs)
+
+	// The SBOM restored before the archive ended was rolled back
+	stored, err := target.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
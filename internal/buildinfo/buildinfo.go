@@ -0,0 +1,69 @@
+// Package buildinfo provides the version metadata of the running binary.
+// Release builds embed it with the linker:
+//
+//	go build -ldflags "-X github.com/hueyexe/SBOM-Sentinel/internal/buildinfo.Version=1.2.0 \
+//	  -X github.com/hueyexe/SBOM-Sentinel/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/hueyexe/SBOM-Sentinel/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Other builds report the version "dev" and the commit recorded by the Go
+// toolchain, if any.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// DevVersion is the version of builds without embedded version metadata.
+const DevVersion = "dev"
+
+// Set with -ldflags "-X" by release builds.
+var (
+	// Version is the release version, without a leading "v"
+	Version = DevVersion
+	// Commit is the Git commit the binary was built from
+	Commit = ""
+	// Date is the build time in RFC 3339 format
+	Date = ""
+	// ReleaseKey is the base64-encoded Ed25519 public key that release
+	// checksums are signed with, verified by self-update
+	ReleaseKey = ""
+)
+
+// IsRelease reports whether the binary is a release build.
+func IsRelease() bool {
+	return Version != DevVersion
+}
+
+// Revision returns the commit the binary was built from: Commit or, for
+// other builds, the revision recorded by the Go toolchain. It returns an
+// empty string if neither is known.
+func Revision() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return ""
+}
+
+// String describes the build, e.g.
+// "1.2.0 (commit 4b9296b, built 2026-03-01T12:00:00Z, go1.24.0 linux/amd64)".
+func String() string {
+	details := make([]string, 0, 3)
+	if revision := Revision(); revision != "" {
+		details = append(details, "commit "+revision[:min(len(revision), 7)])
+	}
+	if Date != "" {
+		details = append(details, "built "+Date)
+	}
+	details = append(details, fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH))
+	return fmt.Sprintf("%s (%s)", Version, strings.Join(details, ", "))
+}
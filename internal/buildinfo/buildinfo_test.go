@@ -0,0 +1,20 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	assert.False(t, IsRelease())
+	assert.Contains(t, String(), DevVersion+" (")
+
+	version, commit, date := Version, Commit, Date
+	t.Cleanup(func() { Version, Commit, Date = version, commit, date })
+	Version, Commit, Date = "1.2.0", "4b9296b8c1e2", "2026-03-01T12:00:00Z"
+
+	assert.True(t, IsRelease())
+	assert.Equal(t, "1.2.0 (commit 4b9296b, built 2026-03-01T12:00:00Z, "+runtime.Version()+" "+runtime.GOOS+"/"+runtime.GOARCH+")", String())
+}
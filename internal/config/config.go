@@ -0,0 +1,362 @@
+// Package config provides loading of the SBOM Sentinel configuration file,
+// which defines named analysis profiles, project license contexts,
+// export-control rules, component owners, remediation SLAs, notification
+// channels, custom rules, gate policies, waivers, API keys and database
+// settings shared by the server and CLI.
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ignore"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ownership"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/internal/plugin"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/hueyexe/SBOM-Sentinel/internal/sla"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the configuration file read when $SENTINEL_CONFIG is not set.
+const DefaultPath = "./sentinel.yaml"
+
+// EnvYAML is the environment variable holding the whole configuration
+// inline, for deployments configured entirely through the environment. It
+// takes precedence over any configuration file.
+const EnvYAML = "SENTINEL_CONFIG_YAML"
+
+// DefaultFile is the commented configuration file written by
+// `sentinel-server --init`.
+//
+//go:embed default.yaml
+var DefaultFile []byte
+
+// Profile is a named bundle of analysis agents. The license agent always
+// runs; a profile enables the optional agents on top of it.
+type Profile struct {
+	AIHealthCheck bool `yaml:"ai_health_check"`
+	ProactiveScan bool `yaml:"proactive_scan"`
+	VulnScan      bool `yaml:"vuln_scan"`
+	QualityCheck  bool `yaml:"quality_check"`
+	CryptoCheck   bool `yaml:"crypto_check"`
+	ExportCheck   bool `yaml:"export_check"`
+	// BaseImageCheck checks the base image of container image SBOMs
+	BaseImageCheck bool `yaml:"base_image_check"`
+	// RepoActivityCheck checks the activity of the source repositories of
+	// components on GitHub and GitLab
+	RepoActivityCheck bool `yaml:"repo_activity_check"`
+}
+
+// Config is the SBOM Sentinel configuration file.
+type Config struct {
+	// Profiles maps profile names to agent bundles; they are merged over the
+	// built-in profiles, so a profile of the same name replaces a built-in one
+	Profiles map[string]Profile `yaml:"profiles"`
+	// Projects declares the license and distribution model of projects,
+	// by SBOM name, so that license findings are rated in context
+	Projects map[string]core.ProjectContext `yaml:"projects"`
+	// ExportControlRules tag further components with export-control
+	// categories, in addition to the built-in rules
+	ExportControlRules []analysis.ExportRule `yaml:"export_control_rules"`
+	// VulnerableBaseImages are base images reported by the base image agent
+	// as known vulnerable
+	VulnerableBaseImages []analysis.VulnerableImage `yaml:"vulnerable_base_images"`
+	// Owners maps teams to the components they own, so that findings name
+	// their owner and can be routed to the owner's notification channel
+	Owners ownership.Map `yaml:"owners"`
+	// SLA maps severities to the days within which open findings of that
+	// severity must be remediated
+	SLA sla.Policy `yaml:"sla"`
+	// Notifications are the channels that receive analysis findings
+	Notifications []notify.Channel `yaml:"notifications"`
+	// Rules are custom analysis rules compiled to WebAssembly; relative
+	// module paths are resolved against the config file's directory
+	Rules []plugin.Rule `yaml:"rules"`
+	// Policies are gate policies evaluated with those in POLICY_PATH;
+	// relative paths are resolved against the config file's directory
+	Policies []policy.Source `yaml:"policies"`
+	// Waivers exclude accepted findings from server analyses
+	Waivers []ignore.Waiver `yaml:"waivers"`
+	// APIKeys are API keys accepted in addition to those in API_KEYS
+	APIKeys []APIKey `yaml:"api_keys"`
+	// Database holds the connection settings of the SQLite database
+	Database database.SQLiteOptions `yaml:"database"`
+}
+
+// APIKey is an API key of the configuration file and the role it grants.
+type APIKey struct {
+	// Name identifies the key, as the key itself is secret
+	Name string `yaml:"name"`
+	// Key is expanded from the environment like notification URLs, so that
+	// it need not be kept in the config file
+	Key string `yaml:"key"`
+	// Role is viewer, analyst or admin
+	Role string `yaml:"role"`
+}
+
+// Validate checks that the key is set and grants a known role.
+func (k APIKey) Validate() error {
+	var errs []error
+	if strings.TrimSpace(os.ExpandEnv(k.Key)) == "" {
+		errs = append(errs, errors.New("key is required"))
+	}
+	switch strings.ToLower(strings.TrimSpace(k.Role)) {
+	case "viewer", "analyst", "admin":
+	default:
+		errs = append(errs, fmt.Errorf("invalid role %q (expected viewer, analyst or admin)", k.Role))
+	}
+	return errors.Join(errs...)
+}
+
+// BuiltinProfiles returns the profiles available without a configuration
+// file: "quick" adds known vulnerability scanning, "compliance-only" adds
+// quality scoring, and "full" runs every agent.
+func BuiltinProfiles() map[string]Profile {
+	return map[string]Profile{
+		"quick":           {VulnScan: true},
+		"compliance-only": {QualityCheck: true},
+		"full":            {AIHealthCheck: true, ProactiveScan: true, VulnScan: true, QualityCheck: true, CryptoCheck: true, ExportCheck: true, BaseImageCheck: true, RepoActivityCheck: true},
+	}
+}
+
+// New returns a configuration with only the built-in profiles.
+func New() *Config {
+	return &Config{Profiles: BuiltinProfiles()}
+}
+
+// Load reads the configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file '%s': %w", path, err)
+	}
+	return Parse(data, path)
+}
+
+// Parse parses a configuration read from path, which names it in errors
+// and against whose directory relative rule module paths are resolved.
+func Parse(data []byte, path string) (*Config, error) {
+	var file Config
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file '%s': %w", path, err)
+	}
+
+	config := New()
+	for name, profile := range file.Profiles {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("config file '%s' defines a profile without a name", path)
+		}
+		config.Profiles[name] = profile
+	}
+
+	for name, project := range file.Projects {
+		if err := project.Validate(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid project '%s': %w", path, name, err)
+		}
+		if config.Projects == nil {
+			config.Projects = make(map[string]core.ProjectContext)
+		}
+		config.Projects[name] = project
+	}
+
+	for i, rule := range file.ExportControlRules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid export control rule %d: %w", path, i+1, err)
+		}
+		config.ExportControlRules = append(config.ExportControlRules, rule)
+	}
+
+	for i, image := range file.VulnerableBaseImages {
+		if err := image.Validate(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid vulnerable base image %d: %w", path, i+1, err)
+		}
+		config.VulnerableBaseImages = append(config.VulnerableBaseImages, image)
+	}
+
+	if err := file.Owners.Validate(); err != nil {
+		return nil, fmt.Errorf("config file '%s' defines invalid owners: %w", path, err)
+	}
+	config.Owners = file.Owners
+
+	if err := file.SLA.Validate(); err != nil {
+		return nil, fmt.Errorf("config file '%s' defines an invalid sla: %w", path, err)
+	}
+	config.SLA = file.SLA
+
+	for i, channel := range file.Notifications {
+		if channel.Name == "" {
+			channel.Name = fmt.Sprintf("%s-%d", channel.Type, i+1)
+		}
+		if err := channel.Validate(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid notification channel '%s': %w", path, channel.Name, err)
+		}
+		for _, owner := range channel.Owners {
+			if _, ok := file.Owners[owner]; !ok {
+				return nil, fmt.Errorf("config file '%s' routes notification channel '%s' to team '%s', which is not in owners", path, channel.Name, owner)
+			}
+		}
+		config.Notifications = append(config.Notifications, channel)
+	}
+
+	for i, rule := range file.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid rule %d: %w", path, i+1, err)
+		}
+		if !filepath.IsAbs(rule.Module) {
+			rule.Module = filepath.Join(filepath.Dir(path), rule.Module)
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+
+	for i, source := range file.Policies {
+		if err := source.Validate(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid policy %d: %w", path, i+1, err)
+		}
+		if source.Path != "" && !filepath.IsAbs(source.Path) {
+			source.Path = filepath.Join(filepath.Dir(path), source.Path)
+		}
+		config.Policies = append(config.Policies, source)
+	}
+
+	for i, waiver := range file.Waivers {
+		if _, err := waiver.Entry(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid waiver %d: %w", path, i+1, err)
+		}
+		config.Waivers = append(config.Waivers, waiver)
+	}
+
+	for i, key := range file.APIKeys {
+		if key.Name == "" {
+			key.Name = fmt.Sprintf("key-%d", i+1)
+		}
+		if err := key.Validate(); err != nil {
+			return nil, fmt.Errorf("config file '%s' defines an invalid API key '%s': %w", path, key.Name, err)
+		}
+		config.APIKeys = append(config.APIKeys, key)
+	}
+
+	if err := file.Database.Validate(); err != nil {
+		return nil, fmt.Errorf("config file '%s' defines invalid database settings: %w", path, err)
+	}
+	config.Database = file.Database
+	return config, nil
+}
+
+var (
+	defaultConfig     atomic.Pointer[Config]
+	defaultConfigOnce sync.Once
+)
+
+func init() {
+	policy.ConfiguredSources = func() []policy.Source { return Default().Policies }
+}
+
+// Default returns the configuration loaded once from $SENTINEL_CONFIG_YAML,
+// $SENTINEL_CONFIG or DefaultPath, or by the last successful Reload. A missing DefaultPath is
+// not an error; any other problem is reported as a warning and the built-in
+// profiles are used.
+func Default() *Config {
+	defaultConfigOnce.Do(func() {
+		config, err := loadDefault()
+		if err != nil {
+			fmt.Printf("Warning: %v; using built-in profiles\n", err)
+			config = New()
+		}
+		defaultConfig.Store(config)
+	})
+	return defaultConfig.Load()
+}
+
+// Reload re-reads the configuration returned by Default. If it cannot be
+// loaded, the error is returned and the current configuration kept, so that
+// a mistake in the file does not fall back to the built-in profiles. Callers
+// holding the previous configuration keep using it.
+func Reload() (*Config, error) {
+	// Load the initial configuration first, so that it does not replace
+	// the reloaded one
+	current := Default()
+
+	config, err := loadDefault()
+	if err != nil {
+		return current, err
+	}
+	defaultConfig.Store(config)
+	return config, nil
+}
+
+// loadDefault loads the configuration of $SENTINEL_CONFIG_YAML, or the file
+// of $SENTINEL_CONFIG or DefaultPath, returning the built-in profiles if
+// DefaultPath is missing.
+func loadDefault() (*Config, error) {
+	if data := os.Getenv(EnvYAML); data != "" {
+		// Relative rule module paths are resolved against the working directory
+		return Parse([]byte(data), "$"+EnvYAML)
+	}
+
+	path := os.Getenv("SENTINEL_CONFIG")
+	if path == "" {
+		if _, err := os.Stat(DefaultPath); errors.Is(err, os.ErrNotExist) {
+			return New(), nil
+		}
+		path = DefaultPath
+	}
+	return Load(path)
+}
+
+// WriteDefault writes DefaultFile to path, creating its directory, unless a
+// file already exists there. It reports whether the file was written.
+func WriteDefault(path string) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to check config file '%s': %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, DefaultFile, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write config file '%s': %w", path, err)
+	}
+	return true, nil
+}
+
+// Path returns the configuration file read by Default: $SENTINEL_CONFIG or
+// DefaultPath.
+func Path() string {
+	if path := os.Getenv("SENTINEL_CONFIG"); path != "" {
+		return path
+	}
+	return DefaultPath
+}
+
+// Profile returns the named profile, or an error listing the available ones.
+func (c *Config) Profile(name string) (Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the names of the available profiles in sorted order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
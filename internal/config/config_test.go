@@ -0,0 +1,365 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_BuiltinProfiles(t *testing.T) {
+	config := New()
+	assert.Equal(t, []string{"compliance-only", "full", "quick"}, config.ProfileNames())
+
+	quick, err := config.Profile("quick")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{VulnScan: true}, quick)
+
+	_, err = config.Profile("thorough")
+	assert.ErrorContains(t, err, `unknown profile "thorough" (available: compliance-only, full, quick)`)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel.yaml")
+	data := `profiles:
+  nightly:
+    vuln_scan: true
+    proactive_scan: true
+  quick:
+    quality_check: true
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	config, err := Load(path)
+	require.NoError(t, err)
+
+	nightly, err := config.Profile("nightly")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{VulnScan: true, ProactiveScan: true}, nightly)
+
+	// Profiles in the file replace built-in profiles of the same name
+	quick, err := config.Profile("quick")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{QualityCheck: true}, quick)
+
+	// The remaining built-in profiles stay available
+	_, err = config.Profile("full")
+	assert.NoError(t, err)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Load(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config file")
+
+	path := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profiles: [quick]\n"), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "failed to parse config file")
+}
+
+func TestLoad_Notifications(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "sentinel.yaml")
+	data := `notifications:
+  - name: security-alerts
+    type: slack
+    url: ${SLACK_WEBHOOK_URL}
+    min_severity: high
+  - type: teams
+    url: https://example.webhook.office.com/webhookb2/abc
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/T/B/X")
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, config.Notifications, 2)
+	assert.Equal(t, "security-alerts", config.Notifications[0].Name)
+	assert.Equal(t, "high", config.Notifications[0].MinSeverity)
+	assert.Equal(t, "teams-2", config.Notifications[1].Name)
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("notifications:\n  - name: pager\n    type: pagerduty\n    url: https://example.com\n"), 0o644))
+	_, err = Load(invalid)
+	assert.ErrorContains(t, err, "invalid notification channel 'pager'")
+}
+
+func TestLoad_Projects(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "sentinel.yaml")
+	data := `projects:
+  admin-portal:
+    distribution: Internal
+  storefront:
+    license: Apache-2.0
+    distribution: saas
+    tags:
+      Team: web
+      env: prod
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]core.ProjectContext{
+		"admin-portal": {Distribution: core.DistributionInternal},
+		"storefront": {License: "Apache-2.0", Distribution: core.DistributionSaaS,
+			Tags: map[string]string{"team": "web", "env": "prod"}},
+	}, config.Projects)
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("projects:\n  admin-portal:\n    distribution: on-prem\n"), 0o644))
+	_, err = Load(invalid)
+	assert.ErrorContains(t, err, "invalid project 'admin-portal'")
+
+	_, err = Parse([]byte("projects:\n  storefront:\n    tags:\n      env: \"\"\n"), path)
+	assert.ErrorContains(t, err, `invalid project 'storefront': tag "env" has no value`)
+}
+
+func TestLoad_ExportControlRules(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "sentinel.yaml")
+	data := `export_control_rules:
+  - category: satellite
+    eccn: 9D515
+    reason: controls spacecraft
+    names: [flight-software]
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, config.ExportControlRules, 1)
+	assert.Equal(t, "9D515", config.ExportControlRules[0].ECCN)
+	assert.Equal(t, []string{"flight-software"}, config.ExportControlRules[0].Names)
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("export_control_rules:\n  - category: satellite\n    names: [flight-software]\n"), 0o644))
+	_, err = Load(invalid)
+	assert.ErrorContains(t, err, "invalid export control rule 1: eccn is required")
+}
+
+func TestLoad_VulnerableBaseImages(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "sentinel.yaml")
+	data := `vulnerable_base_images:
+  - reference: node:18.17.0
+    reason: CVE-2023-38552 in its OpenSSL
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []analysis.VulnerableImage{{Reference: "node:18.17.0", Reason: "CVE-2023-38552 in its OpenSSL"}}, config.VulnerableBaseImages)
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("vulnerable_base_images:\n  - reason: unnamed\n"), 0o644))
+	_, err = Load(invalid)
+	assert.ErrorContains(t, err, "invalid vulnerable base image 1: reference is required")
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  nightly:\n    vuln_scan: true\n"), 0o644))
+	t.Setenv("SENTINEL_CONFIG", path)
+
+	config, err := Reload()
+	require.NoError(t, err)
+	assert.Same(t, config, Default())
+	assert.Contains(t, config.ProfileNames(), "nightly")
+
+	// An edited file replaces the configuration
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  weekly:\n    quality_check: true\n"), 0o644))
+	reloaded, err := Reload()
+	require.NoError(t, err)
+	assert.Same(t, reloaded, Default())
+	assert.Contains(t, reloaded.ProfileNames(), "weekly")
+	assert.NotContains(t, reloaded.ProfileNames(), "nightly")
+
+	// A broken file keeps the current configuration
+	require.NoError(t, os.WriteFile(path, []byte("profiles: [\n"), 0o644))
+	current, err := Reload()
+	assert.ErrorContains(t, err, "failed to parse config file")
+	assert.Same(t, reloaded, current)
+	assert.Same(t, reloaded, Default())
+}
+
+func TestReload_EnvYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  nightly:\n    vuln_scan: true\n"), 0o644))
+	t.Setenv("SENTINEL_CONFIG", path)
+	t.Setenv(EnvYAML, "profiles:\n  weekly:\n    quality_check: true\nrules:\n  - name: Local\n    module: rules/local.wasm\n")
+
+	// The inline configuration takes precedence over the file
+	config, err := Reload()
+	require.NoError(t, err)
+	assert.Contains(t, config.ProfileNames(), "weekly")
+	assert.NotContains(t, config.ProfileNames(), "nightly")
+	require.Len(t, config.Rules, 1)
+	assert.Equal(t, filepath.Join("rules", "local.wasm"), config.Rules[0].Module, "relative to the working directory")
+
+	t.Setenv(EnvYAML, "profiles: [\n")
+	_, err = Reload()
+	assert.ErrorContains(t, err, "failed to parse config file '$SENTINEL_CONFIG_YAML'")
+}
+
+func TestWriteDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "sentinel.yaml")
+
+	written, err := WriteDefault(path)
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	// The default file is a valid configuration
+	config, err := Load(path)
+	require.NoError(t, err)
+	assert.Contains(t, config.ProfileNames(), "pr-check")
+
+	// An existing file is kept
+	require.NoError(t, os.WriteFile(path, []byte("profiles: {}\n"), 0o644))
+	written, err = WriteDefault(path)
+	require.NoError(t, err)
+	assert.False(t, written)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "profiles: {}\n", string(data))
+}
+
+func TestLoad_Rules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sentinel.yaml")
+	data := `rules:
+  - name: Registry Allowlist
+    module: rules/registry.wasm
+    projects: [payments-api]
+  - name: Absolute
+    module: /opt/rules/absolute.wasm
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, config.Rules, 2)
+	assert.Equal(t, filepath.Join(dir, "rules", "registry.wasm"), config.Rules[0].Module, "relative to the config file")
+	assert.Equal(t, []string{"payments-api"}, config.Rules[0].Projects)
+	assert.Equal(t, "/opt/rules/absolute.wasm", config.Rules[1].Module)
+
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: Unnamed module\n"), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "invalid rule 1: module is required")
+}
+
+func TestParse_Database(t *testing.T) {
+	config, err := Parse([]byte("database:\n  journal_mode: delete\n  busy_timeout: 10s\n  max_open_conns: 4\n"), "sentinel.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "delete", config.Database.JournalMode)
+	assert.Equal(t, 10*time.Second, config.Database.BusyTimeout)
+	assert.Equal(t, 4, config.Database.MaxOpenConns)
+
+	_, err = Parse([]byte("database:\n  journal_mode: fast\n"), "sentinel.yaml")
+	assert.ErrorContains(t, err, `invalid database settings: invalid journal mode "fast"`)
+}
+
+func TestParse_Policies(t *testing.T) {
+	data := `policies:
+  - path: policies/gate.rego
+  - name: no-critical
+    rego: |
+      package sentinel
+`
+	config, err := Parse([]byte(data), filepath.Join("etc", "sentinel.yaml"))
+	require.NoError(t, err)
+	require.Len(t, config.Policies, 2)
+	assert.Equal(t, filepath.Join("etc", "policies", "gate.rego"), config.Policies[0].Path, "relative to the config file")
+	assert.Equal(t, "package sentinel\n", config.Policies[1].Rego)
+
+	_, err = Parse([]byte("policies:\n  - name: empty\n"), "sentinel.yaml")
+	assert.ErrorContains(t, err, "invalid policy 1: path or rego is required")
+}
+
+func TestParse_APIKeys(t *testing.T) {
+	data := `api_keys:
+  - name: ci
+    key: ${CI_API_KEY}
+    role: analyst
+  - key: literal-key
+    role: viewer
+`
+	t.Setenv("CI_API_KEY", "k3y-for-ci")
+	config, err := Parse([]byte(data), "sentinel.yaml")
+	require.NoError(t, err)
+	require.Len(t, config.APIKeys, 2)
+	assert.Equal(t, APIKey{Name: "ci", Key: "${CI_API_KEY}", Role: "analyst"}, config.APIKeys[0], "expanded when used")
+	assert.Equal(t, "key-2", config.APIKeys[1].Name)
+
+	t.Setenv("CI_API_KEY", "")
+	_, err = Parse([]byte(data), "sentinel.yaml")
+	assert.ErrorContains(t, err, "invalid API key 'ci': key is required")
+	_, err = Parse([]byte("api_keys:\n  - key: k\n    role: owner\n"), "sentinel.yaml")
+	assert.ErrorContains(t, err, `invalid role "owner"`)
+}
+
+func TestParse_Waivers(t *testing.T) {
+	data := `waivers:
+  - match: vuln:CVE-2021-44228
+    reason: JNDI lookups are disabled (SEC-142)
+  - match: component:left-pad
+    reason: Vendored
+    projects: [storefront]
+`
+	config, err := Parse([]byte(data), "sentinel.yaml")
+	require.NoError(t, err)
+	require.Len(t, config.Waivers, 2)
+	assert.Equal(t, []string{"storefront"}, config.Waivers[1].Projects)
+
+	_, err = Parse([]byte("waivers:\n  - match: vuln:CVE-2021-44228\n"), "sentinel.yaml")
+	assert.ErrorContains(t, err, "invalid waiver 1: reason is required")
+}
+
+func TestParse_Owners(t *testing.T) {
+	data := `owners:
+  payments:
+    match: [pkg:maven/com.acme.payments/, component:stripe-*]
+  web:
+    match: [pkg:npm/@acme/]
+notifications:
+  - name: payments-alerts
+    type: slack
+    url: https://hooks.slack.com/services/T/B/X
+    owners: [payments]
+`
+	config, err := Parse([]byte(data), "sentinel.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payments", "web"}, config.Owners.Teams())
+	assert.Equal(t, []string{"payments"}, config.Notifications[0].Owners)
+
+	_, err = Parse([]byte("owners:\n  web:\n    match: [lodash]\n"), "sentinel.yaml")
+	assert.ErrorContains(t, err, "invalid owners: team 'web'")
+
+	_, err = Parse([]byte("notifications:\n  - name: team\n    type: webhook\n    url: https://example.com\n    owners: [mobile]\n"), "sentinel.yaml")
+	assert.ErrorContains(t, err, "routes notification channel 'team' to team 'mobile', which is not in owners")
+}
+
+func TestParse_SLA(t *testing.T) {
+	config, err := Parse([]byte("sla:\n  critical: 7\n  High: 30\n"), "sentinel.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, 7, config.SLA.Days("Critical"))
+	assert.Equal(t, 30, config.SLA.Days("high"))
+	assert.Zero(t, config.SLA.Days("Medium"))
+
+	_, err = Parse([]byte("sla:\n  urgent: 1\n  low: 0\n"), "sentinel.yaml")
+	assert.ErrorContains(t, err, `"urgent" is not a severity`)
+	assert.ErrorContains(t, err, "invalid sla: low: days must be positive, got 0")
+}
@@ -0,0 +1,40 @@
+// Package configtest replaces the configuration in effect for the duration
+// of a test.
+package configtest
+
+import (
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// Set loads yaml as the configuration given in SENTINEL_CONFIG_YAML. Once
+// the test ends, the configuration of the restored environment is loaded
+// again.
+func Set(t testing.TB, yaml string) {
+	t.Helper()
+	reloadOnCleanup(t)
+	t.Setenv(config.EnvYAML, yaml)
+	_, err := config.Reload()
+	require.NoError(t, err)
+}
+
+// SetFile makes path the config file, without SENTINEL_CONFIG_YAML or
+// POLICY_PATH, for a test that writes it, for example through the config
+// endpoint. Once the test ends, the configuration of the restored
+// environment is loaded again.
+func SetFile(t testing.TB, path string) {
+	t.Helper()
+	reloadOnCleanup(t)
+	t.Setenv("SENTINEL_CONFIG", path)
+	t.Setenv(config.EnvYAML, "")
+	t.Setenv("POLICY_PATH", "")
+}
+
+// reloadOnCleanup reloads the configuration when the test ends. It must be
+// registered before the environment is changed, so that it runs after the
+// environment is restored.
+func reloadOnCleanup(t testing.TB) {
+	t.Cleanup(func() { config.Reload() })
+}
@@ -0,0 +1,96 @@
+// Package core provides the hierarchy of components nested in assemblies,
+// such as the packages of a firmware image.
+package core
+
+import "strings"
+
+// ComponentNode is a component together with the components nested in it.
+type ComponentNode struct {
+	Component
+	// Children are the components nested in the component, in SBOM order
+	Children []ComponentNode `json:"children,omitempty"`
+}
+
+// Key identifies the component within an SBOM: its PURL when present,
+// otherwise its lower-cased name and version. Nested components name their
+// assembly by its key in Parent.
+func (c Component) Key() string {
+	if c.PURL != "" {
+		return c.PURL
+	}
+	return strings.ToLower(c.Name) + "@" + c.Version
+}
+
+// HasAssemblies reports whether any component is nested in another.
+func HasAssemblies(components []Component) bool {
+	for _, component := range components {
+		if component.Parent != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ComponentTree arranges components by the assemblies they are nested in.
+// Top-level components, and components whose assembly is not listed, are the
+// roots; order is kept at every level. A component nested in a component
+// listed twice belongs to its first occurrence, and components nested in
+// each other in a cycle become roots.
+func ComponentTree(components []Component) []ComponentNode {
+	first := make(map[string]int, len(components))
+	for i, component := range components {
+		if _, exists := first[component.Key()]; !exists {
+			first[component.Key()] = i
+		}
+	}
+
+	children := make(map[int][]int)
+	var roots []int
+	for i, component := range components {
+		parent, exists := first[component.Parent]
+		if component.Parent == "" || !exists || parent == i {
+			roots = append(roots, i)
+			continue
+		}
+		children[parent] = append(children[parent], i)
+	}
+
+	visited := make([]bool, len(components))
+	var build func(i int) ComponentNode
+	build = func(i int) ComponentNode {
+		visited[i] = true
+		node := ComponentNode{Component: components[i]}
+		for _, child := range children[i] {
+			if !visited[child] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
+	}
+
+	tree := make([]ComponentNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	// Components in a cycle are reachable from no root
+	for i := range components {
+		if !visited[i] {
+			tree = append(tree, build(i))
+		}
+	}
+	return tree
+}
+
+// Assembly returns the label, "name version", of the assembly a component
+// is nested in, or "" if it is top-level or its assembly is not listed.
+func Assembly(components []Component, component Component) string {
+	if component.Parent == "" {
+		return ""
+	}
+	for _, candidate := range components {
+		if candidate.Key() == component.Parent {
+			return strings.TrimSpace(candidate.Name + " " + candidate.Version)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentTree(t *testing.T) {
+	components := []Component{
+		{Name: "router-firmware", Version: "2.1", Type: "firmware"},
+		{Name: "busybox", Version: "1.36.1", PURL: "pkg:generic/busybox@1.36.1", Parent: "router-firmware@2.1"},
+		{Name: "openssl", Version: "3.0.13", Parent: "router-firmware@2.1"},
+		{Name: "libcrypto", Version: "3.0.13", Parent: "openssl@3.0.13"},
+		{Name: "lodash", Version: "4.17.21"},
+		// Its assembly is not listed
+		{Name: "orphan", Version: "1.0", Parent: "missing@1.0"},
+	}
+	assert.True(t, HasAssemblies(components))
+	assert.False(t, HasAssemblies(components[4:5]))
+
+	tree := ComponentTree(components)
+	require.Len(t, tree, 3)
+	assert.Equal(t, "router-firmware", tree[0].Name)
+	require.Len(t, tree[0].Children, 2)
+	assert.Equal(t, "busybox", tree[0].Children[0].Name)
+	openssl := tree[0].Children[1]
+	assert.Equal(t, "openssl", openssl.Name)
+	require.Len(t, openssl.Children, 1)
+	assert.Equal(t, "libcrypto", openssl.Children[0].Name)
+	assert.Equal(t, "lodash", tree[1].Name)
+	assert.Empty(t, tree[1].Children)
+	assert.Equal(t, "orphan", tree[2].Name)
+
+	assert.Equal(t, "openssl 3.0.13", Assembly(components, components[3]))
+	assert.Equal(t, "router-firmware 2.1", Assembly(components, components[1]))
+	assert.Empty(t, Assembly(components, components[0]))
+	assert.Empty(t, Assembly(components, components[5]))
+}
+
+func TestComponentTree_Cycle(t *testing.T) {
+	tree := ComponentTree([]Component{
+		{Name: "a", Version: "1", Parent: "b@1"},
+		{Name: "b", Version: "1", Parent: "a@1"},
+		{Name: "self", Version: "1", Parent: "self@1"},
+	})
+	require.Len(t, tree, 2)
+	assert.Equal(t, "self", tree[0].Name)
+	assert.Equal(t, "a", tree[1].Name)
+	require.Len(t, tree[1].Children, 1)
+	assert.Equal(t, "b", tree[1].Children[0].Name)
+}
+
+func TestComponent_Key(t *testing.T) {
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", Component{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21"}.Key())
+	assert.Equal(t, "busybox@1.36.1", Component{Name: "BusyBox", Version: "1.36.1"}.Key())
+}
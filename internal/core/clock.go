@@ -0,0 +1,117 @@
+// Package core provides the clock and ID generator behind the timestamps and
+// identifiers of stored records, which tests can replace to make them
+// deterministic.
+package core
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock of the operating system.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock is a Clock that stands still until advanced, for tests.
+type FixedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFixedClock creates a clock reading now.
+func NewFixedClock(now time.Time) *FixedClock {
+	return &FixedClock{now: now}
+}
+
+// Now returns the time the clock was set or advanced to.
+func (c *FixedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FixedClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// IDGenerator generates the identifiers of stored records such as SBOMs.
+type IDGenerator interface {
+	NewID() string
+}
+
+// RandomIDs generates random (version 4) UUIDs.
+type RandomIDs struct{}
+
+// NewID returns a new random UUID.
+func (RandomIDs) NewID() string {
+	return newRandomUUID()
+}
+
+// SequentialIDs generates UUID-shaped IDs counting up from 1, such as
+// "00000000-0000-4000-8000-000000000001", so that tests can predict them.
+type SequentialIDs struct {
+	last atomic.Uint64
+}
+
+// NewID returns the next ID of the sequence.
+func (s *SequentialIDs) NewID() string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.last.Add(1))
+}
+
+// The process-wide clock and ID generator, replaced by tests.
+var (
+	defaultsMu sync.RWMutex
+	clock      Clock       = SystemClock{}
+	ids        IDGenerator = RandomIDs{}
+)
+
+// Now returns the current time of the process clock, the system clock unless
+// a test replaced it with SetClock.
+func Now() time.Time {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	return clock.Now()
+}
+
+// SetClock replaces the process clock and returns a function restoring the
+// previous one. It is meant for tests, which must not run in parallel with
+// others depending on the clock.
+func SetClock(c Clock) (restore func()) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	previous := clock
+	clock = c
+	return func() {
+		defaultsMu.Lock()
+		defer defaultsMu.Unlock()
+		clock = previous
+	}
+}
+
+// SetIDGenerator replaces the generator of NewSBOMID and returns a function
+// restoring the previous one. Like SetClock, it is meant for tests.
+func SetIDGenerator(g IDGenerator) (restore func()) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	previous := ids
+	ids = g
+	return func() {
+		defaultsMu.Lock()
+		defer defaultsMu.Unlock()
+		ids = previous
+	}
+}
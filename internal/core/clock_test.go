@@ -0,0 +1,21 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetClock(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fixed := NewFixedClock(start)
+
+	restore := SetClock(fixed)
+	assert.Equal(t, start, Now())
+	fixed.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), Now())
+
+	restore()
+	assert.WithinDuration(t, time.Now(), Now(), time.Minute)
+}
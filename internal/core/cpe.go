@@ -0,0 +1,156 @@
+// Package core provides parsing of Common Platform Enumeration (CPE) identifiers.
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CPE is a parsed Common Platform Enumeration name. Attributes that are
+// unspecified ("*" or "-" in a formatted string, empty in a URI) are empty.
+type CPE struct {
+	// Part is "a" for applications, "o" for operating systems and "h" for hardware
+	Part      string `json:"part"`
+	Vendor    string `json:"vendor"`
+	Product   string `json:"product"`
+	Version   string `json:"version,omitempty"`
+	Update    string `json:"update,omitempty"`
+	Edition   string `json:"edition,omitempty"`
+	Language  string `json:"language,omitempty"`
+	SWEdition string `json:"sw_edition,omitempty"`
+	// TargetSW is the software environment of the product, e.g. "node.js" or "python"
+	TargetSW string `json:"target_sw,omitempty"`
+	TargetHW string `json:"target_hw,omitempty"`
+	Other    string `json:"other,omitempty"`
+}
+
+// ParseCPE parses a CPE 2.3 formatted string ("cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*")
+// or a CPE 2.2 URI ("cpe:/a:apache:log4j:2.14.1"). Vendor and product are required.
+func ParseCPE(s string) (CPE, error) {
+	var (
+		fields []string
+		err    error
+	)
+	switch lower := strings.ToLower(s); {
+	case strings.HasPrefix(lower, "cpe:2.3:"):
+		fields = splitFormattedCPE(s[len("cpe:2.3:"):])
+	case strings.HasPrefix(lower, "cpe:/"):
+		fields, err = splitURICPE(s[len("cpe:/"):])
+	default:
+		return CPE{}, fmt.Errorf("invalid CPE %q: missing cpe:2.3: or cpe:/ prefix", s)
+	}
+	if err != nil {
+		return CPE{}, fmt.Errorf("invalid CPE %q: %w", s, err)
+	}
+
+	for len(fields) < 11 {
+		fields = append(fields, "")
+	}
+	cpe := CPE{
+		Part:      fields[0],
+		Vendor:    fields[1],
+		Product:   fields[2],
+		Version:   fields[3],
+		Update:    fields[4],
+		Edition:   fields[5],
+		Language:  fields[6],
+		SWEdition: fields[7],
+		TargetSW:  fields[8],
+		TargetHW:  fields[9],
+		Other:     fields[10],
+	}
+	if cpe.Vendor == "" || cpe.Product == "" {
+		return CPE{}, fmt.Errorf("invalid CPE %q: vendor and product are required", s)
+	}
+	return cpe, nil
+}
+
+// splitFormattedCPE splits the attributes of a CPE 2.3 formatted string,
+// honouring backslash escapes and dropping the "*" and "-" wildcards.
+func splitFormattedCPE(s string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+	)
+	flush := func() {
+		value := current.String()
+		if value == "*" || value == "-" {
+			value = ""
+		}
+		fields = append(fields, strings.ToLower(value))
+		current.Reset()
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			current.WriteByte(s[i])
+		case s[i] == ':':
+			flush()
+		default:
+			current.WriteByte(s[i])
+		}
+	}
+	flush()
+	return fields
+}
+
+// splitURICPE splits the attributes of a CPE 2.2 URI, decoding percent
+// escapes and unpacking the "~"-separated extended attributes of the edition.
+func splitURICPE(s string) ([]string, error) {
+	raw := strings.Split(s, ":")
+	fields := make([]string, 0, 11)
+	for _, field := range raw {
+		value, err := url.PathUnescape(field)
+		if err != nil {
+			return nil, err
+		}
+		if value == "-" {
+			value = ""
+		}
+		fields = append(fields, strings.ToLower(value))
+	}
+
+	// A packed edition looks like "~edition~sw_edition~target_sw~target_hw~other"
+	if len(fields) > 5 && strings.HasPrefix(fields[5], "~") {
+		packed := strings.Split(fields[5][1:], "~")
+		for len(packed) < 5 {
+			packed = append(packed, "")
+		}
+		language := ""
+		if len(fields) > 6 {
+			language = fields[6]
+		}
+		fields = append(fields[:5], packed[0], language, packed[1], packed[2], packed[3], packed[4])
+	}
+	return fields, nil
+}
+
+// String returns the CPE 2.3 formatted string form of the CPE.
+func (c CPE) String() string {
+	attributes := []string{c.Part, c.Vendor, c.Product, c.Version, c.Update, c.Edition,
+		c.Language, c.SWEdition, c.TargetSW, c.TargetHW, c.Other}
+	for i, value := range attributes {
+		if value == "" {
+			attributes[i] = "*"
+			continue
+		}
+		attributes[i] = strings.NewReplacer(":", `\:`, "*", `\*`, "?", `\?`).Replace(value)
+	}
+	return "cpe:2.3:" + strings.Join(attributes, ":")
+}
+
+// ParsedCPE parses the component's CPE. It reports false if the component
+// has no CPE or it is invalid.
+func (c Component) ParsedCPE() (CPE, bool) {
+	if c.CPE == "" {
+		return CPE{}, false
+	}
+	cpe, err := ParseCPE(c.CPE)
+	if err != nil {
+		return CPE{}, false
+	}
+	return cpe, true
+}
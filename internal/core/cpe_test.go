@@ -0,0 +1,75 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPE(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected CPE
+	}{
+		{
+			name:     "Formatted string",
+			input:    "cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*",
+			expected: CPE{Part: "a", Vendor: "apache", Product: "log4j", Version: "2.14.1"},
+		},
+		{
+			name:     "Formatted string with escapes and target software",
+			input:    `cpe:2.3:a:vendor:product\:name:1.0:-:*:*:*:node.js:*:*`,
+			expected: CPE{Part: "a", Vendor: "vendor", Product: "product:name", Version: "1.0", TargetSW: "node.js"},
+		},
+		{
+			name:     "URI",
+			input:    "cpe:/o:Linux:linux_kernel:5.10",
+			expected: CPE{Part: "o", Vendor: "linux", Product: "linux_kernel", Version: "5.10"},
+		},
+		{
+			name:     "URI with packed edition",
+			input:    "cpe:/a:lodash:lodash:4.17.20::~~~node.js~~",
+			expected: CPE{Part: "a", Vendor: "lodash", Product: "lodash", Version: "4.17.20", TargetSW: "node.js"},
+		},
+		{
+			name:     "URI with percent encoding",
+			input:    "cpe:/a:acme:web%21server:1.0",
+			expected: CPE{Part: "a", Vendor: "acme", Product: "web!server", Version: "1.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpe, err := ParseCPE(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cpe)
+		})
+	}
+}
+
+func TestParseCPE_Invalid(t *testing.T) {
+	for _, input := range []string{"", "pkg:npm/lodash@4.17.21", "cpe:2.3:a:*:*:1.0", "cpe:/a"} {
+		_, err := ParseCPE(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestCPE_String(t *testing.T) {
+	cpe := CPE{Part: "a", Vendor: "vendor", Product: "product:name", Version: "1.0"}
+	assert.Equal(t, `cpe:2.3:a:vendor:product\:name:1.0:*:*:*:*:*:*:*`, cpe.String())
+
+	parsed, err := ParseCPE(cpe.String())
+	require.NoError(t, err)
+	assert.Equal(t, cpe, parsed)
+}
+
+func TestComponent_ParsedCPE(t *testing.T) {
+	_, ok := Component{Name: "lodash"}.ParsedCPE()
+	assert.False(t, ok)
+
+	cpe, ok := Component{CPE: "cpe:2.3:a:lodash:lodash:4.17.21:*:*:*:*:*:*:*"}.ParsedCPE()
+	assert.True(t, ok)
+	assert.Equal(t, "lodash", cpe.Product)
+}
@@ -0,0 +1,30 @@
+// Package core provides generation of the internal identifiers of stored
+// SBOMs.
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// NewSBOMID returns a new ID identifying a stored SBOM or other record: a
+// random (version 4) UUID, unless a test replaced the generator with
+// SetIDGenerator. Internal IDs are independent of the document's serial
+// number, which producers do not always make unique.
+func NewSBOMID() string {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	return ids.NewID()
+}
+
+// newRandomUUID returns a new random (version 4) UUID.
+func newRandomUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(fmt.Sprintf("failed to generate SBOM ID: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSBOMID(t *testing.T) {
+	uuidV4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	id := NewSBOMID()
+	assert.Regexp(t, uuidV4, id)
+	assert.NotEqual(t, id, NewSBOMID())
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	restore := SetIDGenerator(&SequentialIDs{})
+	assert.Equal(t, "00000000-0000-4000-8000-000000000001", NewSBOMID())
+	assert.Equal(t, "00000000-0000-4000-8000-000000000002", NewSBOMID())
+
+	// Restoring brings back random IDs
+	restore()
+	assert.NotEqual(t, "00000000-0000-4000-8000-000000000003", NewSBOMID())
+}
@@ -0,0 +1,118 @@
+// Package core provides aggregation of components across SBOMs.
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// InventoryItem is a unique component together with the SBOMs that reference it.
+type InventoryItem struct {
+	// Name is the human-readable name of the component
+	Name string `json:"name"`
+
+	// Version is the version identifier of the component
+	Version string `json:"version"`
+
+	// PURL is the Package URL of the component, if known
+	PURL string `json:"purl,omitempty"`
+
+	// Ecosystem is the package ecosystem derived from the PURL type (e.g., "npm", "pypi")
+	Ecosystem string `json:"ecosystem,omitempty"`
+
+	// Licenses is the union of licenses declared for the component across SBOMs
+	Licenses []string `json:"licenses,omitempty"`
+
+	// SBOMCount is the number of SBOMs that reference the component
+	SBOMCount int `json:"sbom_count"`
+
+	// SBOMIDs lists the SBOMs that reference the component
+	SBOMIDs []string `json:"sbom_ids"`
+
+	// Owner is the team owning the component, if the caller knows it
+	Owner string `json:"owner,omitempty"`
+
+	// Assemblies lists the components, as "name version", that the component
+	// is nested in across SBOMs, such as the firmware images shipping it
+	Assemblies []string `json:"assemblies,omitempty"`
+}
+
+// BuildInventory aggregates the components of the given SBOMs into a list of
+// unique components. Components are identified by PURL when present,
+// otherwise by name and version. The result is ordered by the number of
+// referencing SBOMs (most first), then by name and version. Components
+// nested in assemblies are listed like any other, naming their assemblies.
+func BuildInventory(sboms []SBOM) []InventoryItem {
+	items := make([]InventoryItem, 0)
+	index := make(map[string]int)
+
+	for _, sbom := range sboms {
+		var assemblies map[string]string
+		if HasAssemblies(sbom.Components) {
+			assemblies = make(map[string]string, len(sbom.Components))
+			for _, component := range sbom.Components {
+				if _, exists := assemblies[component.Key()]; !exists {
+					assemblies[component.Key()] = strings.TrimSpace(component.Name + " " + component.Version)
+				}
+			}
+		}
+
+		for _, component := range sbom.Components {
+			key := component.PURL
+			if key == "" {
+				key = strings.ToLower(component.Name) + "@" + component.Version
+			}
+
+			idx, exists := index[key]
+			if !exists {
+				idx = len(items)
+				index[key] = idx
+				items = append(items, InventoryItem{
+					Name:      component.Name,
+					Version:   component.Version,
+					PURL:      component.PURL,
+					Ecosystem: component.PURLType(),
+				})
+			}
+
+			item := &items[idx]
+			for _, license := range component.DeclaredLicenses() {
+				if !containsString(item.Licenses, license) {
+					item.Licenses = append(item.Licenses, license)
+				}
+			}
+
+			if assembly := assemblies[component.Parent]; assembly != "" && !containsString(item.Assemblies, assembly) {
+				item.Assemblies = append(item.Assemblies, assembly)
+			}
+
+			// A component listed twice in one SBOM only counts once
+			if !containsString(item.SBOMIDs, sbom.ID) {
+				item.SBOMIDs = append(item.SBOMIDs, sbom.ID)
+				item.SBOMCount++
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].SBOMCount != items[j].SBOMCount {
+			return items[i].SBOMCount > items[j].SBOMCount
+		}
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].Version < items[j].Version
+	})
+
+	return items
+}
+
+// containsString reports whether the slice contains the value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildInventory(t *testing.T) {
+	sboms := []SBOM{
+		{
+			ID: "sbom-1",
+			Components: []Component{
+				{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", License: "MIT"},
+				{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", License: "MIT"},
+				{Name: "internal-lib", Version: "1.0.0"},
+			},
+		},
+		{
+			ID: "sbom-2",
+			Components: []Component{
+				{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", Licenses: []string{"MIT", "CC0-1.0"}},
+				{Name: "requests", Version: "2.31.0", PURL: "pkg:pypi/requests@2.31.0", License: "Apache-2.0"},
+			},
+		},
+	}
+
+	items := BuildInventory(sboms)
+
+	assert.Len(t, items, 3)
+
+	assert.Equal(t, "lodash", items[0].Name)
+	assert.Equal(t, "npm", items[0].Ecosystem)
+	assert.Equal(t, 2, items[0].SBOMCount)
+	assert.Equal(t, []string{"sbom-1", "sbom-2"}, items[0].SBOMIDs)
+	assert.Equal(t, []string{"MIT", "CC0-1.0"}, items[0].Licenses)
+
+	assert.Equal(t, "internal-lib", items[1].Name)
+	assert.Empty(t, items[1].Ecosystem)
+	assert.Equal(t, "requests", items[2].Name)
+	assert.Equal(t, "pypi", items[2].Ecosystem)
+}
+
+func TestBuildInventory_Assemblies(t *testing.T) {
+	items := BuildInventory([]SBOM{
+		{ID: "router", Components: []Component{
+			{Name: "router-firmware", Version: "2.1", Type: "firmware"},
+			{Name: "busybox", Version: "1.36.1", Parent: "router-firmware@2.1"},
+		}},
+		{ID: "camera", Components: []Component{
+			{Name: "camera-firmware", Version: "5.0", PURL: "pkg:generic/camera-firmware@5.0", Type: "firmware"},
+			{Name: "busybox", Version: "1.36.1", Parent: "pkg:generic/camera-firmware@5.0"},
+		}},
+		{ID: "container", Components: []Component{
+			{Name: "busybox", Version: "1.36.1"},
+		}},
+	})
+
+	assert.Equal(t, "busybox", items[0].Name)
+	assert.Equal(t, 3, items[0].SBOMCount)
+	assert.Equal(t, []string{"router-firmware 2.1", "camera-firmware 5.0"}, items[0].Assemblies)
+	assert.Empty(t, items[1].Assemblies)
+}
+
+func TestBuildInventory_Empty(t *testing.T) {
+	items := BuildInventory(nil)
+	assert.NotNil(t, items)
+	assert.Empty(t, items)
+}
+
+func TestComponent_PURLType(t *testing.T) {
+	assert.Equal(t, "npm", Component{PURL: "pkg:npm/lodash@4.17.21"}.PURLType())
+	assert.Equal(t, "maven", Component{PURL: "pkg:MAVEN/org.apache/commons@1.0"}.PURLType())
+	assert.Empty(t, Component{PURL: "lodash"}.PURLType())
+	assert.Empty(t, Component{PURL: "pkg:npm"}.PURLType())
+	assert.Empty(t, Component{}.PURLType())
+}
@@ -2,6 +2,22 @@
 // This package has no external dependencies and represents the core of our hexagonal architecture.
 package core
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/purl"
+)
+
+// Component scopes as defined by CycloneDX.
+// A component without an explicit scope is treated as required.
+const (
+	ScopeRequired = "required"
+	ScopeOptional = "optional"
+	ScopeExcluded = "excluded"
+)
+
 // Component represents a software component within an SBOM.
 // It contains essential metadata about a software package or library.
 type Component struct {
@@ -14,14 +30,138 @@ type Component struct {
 	// PURL (Package URL) is a standardized way to identify and locate software packages
 	PURL string `json:"purl"`
 	
-	// License is the license identifier or expression for the component
+	// CPE is the Common Platform Enumeration name of the component, in CPE 2.3
+	// formatted string or CPE 2.2 URI form. Some producers give only a CPE.
+	CPE string `json:"cpe,omitempty"`
+	
+	// License is the first declared license identifier or expression for the component.
+	// It is kept for compatibility; Licenses holds every declared license.
 	License string `json:"license"`
+	
+	// Licenses holds every license identifier or expression declared for the component
+	Licenses []string `json:"licenses,omitempty"`
+	
+	// Supplier is the organization that supplied the component
+	Supplier string `json:"supplier,omitempty"`
+	
+	// Type is the kind of component (e.g., "library", "application", "container", "operating-system")
+	Type string `json:"type,omitempty"`
+	
+	// Scope indicates whether the component is required at runtime ("required", "optional", "excluded")
+	Scope string `json:"scope,omitempty"`
+	
+	// Evidence records how the component was identified, if the producer supplied it
+	Evidence *Evidence `json:"evidence,omitempty"`
+	
+	// ExternalReferences links to resources related to the component (VCS, website, advisories)
+	ExternalReferences []ExternalReference `json:"external_references,omitempty"`
+	
+	// Parent is the Key of the assembly the component is nested in, empty for
+	// top-level components. Nested components are listed in the SBOM's
+	// Components like any other, after their assembly.
+	Parent string `json:"parent,omitempty"`
+}
+
+// Evidence captures the supporting evidence for a component's identity.
+type Evidence struct {
+	// Confidence is the highest identity confidence reported (0.0 to 1.0)
+	Confidence float64 `json:"confidence,omitempty"`
+	
+	// Occurrences lists the locations where the component was found
+	Occurrences []string `json:"occurrences,omitempty"`
+	
+	// Licenses lists licenses observed in the component's files
+	Licenses []string `json:"licenses,omitempty"`
+	
+	// Copyright lists copyright statements observed in the component's files
+	Copyright []string `json:"copyright,omitempty"`
+}
+
+// ExternalReference is a link to a resource related to a component or service.
+type ExternalReference struct {
+	// Type is the kind of reference (e.g., "vcs", "website", "advisories")
+	Type string `json:"type"`
+	
+	// URL is the location of the referenced resource
+	URL string `json:"url"`
+	
+	// Comment is an optional description of the reference
+	Comment string `json:"comment,omitempty"`
+}
+
+// Service represents an external API or service the software depends on.
+type Service struct {
+	// Name is the name of the service
+	Name string `json:"name"`
+	
+	// Version is the version of the service, if known
+	Version string `json:"version,omitempty"`
+	
+	// Provider is the organization that provides the service
+	Provider string `json:"provider,omitempty"`
+	
+	// Endpoints lists the URLs at which the service can be reached
+	Endpoints []string `json:"endpoints,omitempty"`
+	
+	// Authenticated indicates whether the service requires authentication, if known
+	Authenticated *bool `json:"authenticated,omitempty"`
+	
+	// CrossesTrustBoundary indicates whether calling the service crosses a trust boundary
+	CrossesTrustBoundary bool `json:"crosses_trust_boundary,omitempty"`
+	
+	// ExternalReferences links to resources related to the service
+	ExternalReferences []ExternalReference `json:"external_references,omitempty"`
+}
+
+// DeclaredLicenses returns every license declared for the component.
+// It falls back to License for components created without Licenses.
+func (c Component) DeclaredLicenses() []string {
+	if len(c.Licenses) > 0 {
+		return c.Licenses
+	}
+	if c.License != "" {
+		return []string{c.License}
+	}
+	return nil
+}
+
+// PURLType returns the lower-cased package type of the component's PURL
+// (e.g., "npm" for "pkg:npm/lodash@4.17.21"), or an empty string if the
+// component has no valid PURL.
+func (c Component) PURLType() string {
+	parsed, err := purl.Parse(c.PURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Type
+}
+
+// EffectiveScope returns the component's scope in lower case, defaulting to
+// required when unset, so that "Optional" and "optional" are the same scope.
+func (c Component) EffectiveScope() string {
+	scope := strings.ToLower(strings.TrimSpace(c.Scope))
+	if scope == "" {
+		return ScopeRequired
+	}
+	return scope
+}
+
+// Ref returns a reference to the component for use in analysis findings.
+// The scope is the effective scope, so unscoped components are "required".
+func (c Component) Ref() *ComponentRef {
+	return &ComponentRef{
+		Name:    c.Name,
+		Version: c.Version,
+		PURL:    c.PURL,
+		Scope:   c.EffectiveScope(),
+	}
 }
 
 // SBOM represents a Software Bill of Materials document.
 // It contains a collection of components and associated metadata.
 type SBOM struct {
-	// ID is a unique identifier for this SBOM
+	// ID is a unique identifier for this SBOM, assigned when it is stored.
+	// The document's own serial number is kept in Metadata["serialNumber"]
 	ID string `json:"id"`
 	
 	// Name is a human-readable name for this SBOM
@@ -30,8 +170,15 @@ type SBOM struct {
 	// Components is a slice of all software components included in this SBOM
 	Components []Component `json:"components"`
 	
+	// Services is a slice of all external services the software depends on
+	Services []Service `json:"services,omitempty"`
+	
 	// Metadata contains additional key-value pairs of information about the SBOM
 	Metadata map[string]string `json:"metadata"`
+	
+	// Tags label the SBOM for filtering and policies, such as team, environment
+	// or criticality; they are set on submission or later, not read from the document
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // AnalysisResult represents the outcome of running an analysis agent on an SBOM.
@@ -45,4 +192,161 @@ type AnalysisResult struct {
 	
 	// Severity indicates the severity level of the finding (e.g., "low", "medium", "high", "critical")
 	Severity string `json:"severity"`
+	
+	// Citations lists the evidence supporting the finding, such as the
+	// security intelligence documents an LLM-generated finding is based on
+	Citations []Citation `json:"citations,omitempty"`
+	
+	// Component identifies the component the finding is about, if any
+	Component *ComponentRef `json:"component,omitempty"`
+	
+	// VulnerabilityID is the advisory identifier (e.g. a CVE, GHSA or OSV ID)
+	// of a known-vulnerability finding
+	VulnerabilityID string `json:"vulnerability_id,omitempty"`
+	
+	// FixedVersion is the nearest version of the component that fixes the
+	// vulnerability, if one is known
+	FixedVersion string `json:"fixed_version,omitempty"`
+	
+	// UpgradeTo is the nearest version of the component that fixes all of its
+	// known vulnerabilities, if one is known
+	UpgradeTo string `json:"upgrade_to,omitempty"`
+	
+	// RuleID is the name of the custom rule that reported the finding, if any
+	RuleID string `json:"rule_id,omitempty"`
+	
+	// Confidence is how confident the LLM behind an AI-derived finding is in
+	// it, from 0 to 1; it is nil for findings of deterministic agents
+	Confidence *float64 `json:"confidence,omitempty"`
+	
+	// Owner is the team owning the component, by the owners section of the
+	// configuration file, so that the finding reaches that team
+	Owner string `json:"owner,omitempty"`
+}
+
+// Remediation describes how to fix the finding, such as "Upgrade to 2.17.1",
+// or returns an empty string if no fix is known.
+func (r AnalysisResult) Remediation() string {
+	switch {
+	case r.UpgradeTo != "" && (r.FixedVersion == "" || r.FixedVersion == r.UpgradeTo):
+		return "Upgrade to " + r.UpgradeTo
+	case r.UpgradeTo != "":
+		return "Upgrade to " + r.UpgradeTo + " (this vulnerability is fixed in " + r.FixedVersion + ")"
+	case r.FixedVersion != "":
+		return "Fixed in " + r.FixedVersion
+	default:
+		return ""
+	}
+}
+
+// Severity levels assigned to analysis results, from least to most severe.
+const (
+	SeverityLow      = "Low"
+	SeverityMedium   = "Medium"
+	SeverityHigh     = "High"
+	SeverityCritical = "Critical"
+)
+
+// SeverityRank converts a severity label into a comparable rank, from 1 for
+// Low to 4 for Critical, ignoring case. Unknown severities rank 0.
+func SeverityRank(severity string) int {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ValidateSeverityThreshold checks that minimum is a severity results can be
+// filtered by: Critical, High, Medium, Low or Info, ignoring case.
+func ValidateSeverityThreshold(minimum string) error {
+	if SeverityRank(minimum) == 0 && !strings.EqualFold(minimum, "info") {
+		return fmt.Errorf("unknown severity %q (critical, high, medium, low or info)", minimum)
+	}
+	return nil
+}
+
+// FilterBySeverity returns the results whose severity is at least minimum.
+// An empty minimum or Info keeps every result, including those of unknown
+// severity.
+func FilterBySeverity(results []AnalysisResult, minimum string) []AnalysisResult {
+	threshold := SeverityRank(minimum)
+	if threshold == 0 {
+		return results
+	}
+
+	filtered := make([]AnalysisResult, 0, len(results))
+	for _, result := range results {
+		if SeverityRank(result.Severity) >= threshold {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// ParseConfidenceThreshold parses a minimum confidence between 0 and 1.
+func ParseConfidenceThreshold(value string) (float64, error) {
+	minimum, err := strconv.ParseFloat(value, 64)
+	if err != nil || minimum < 0 || minimum > 1 {
+		return 0, fmt.Errorf("invalid confidence %q (expected a number from 0 to 1)", value)
+	}
+	return minimum, nil
+}
+
+// FilterByConfidence returns the results whose confidence is at least
+// minimum. Results without a confidence, those of deterministic agents, are
+// always kept.
+func FilterByConfidence(results []AnalysisResult, minimum float64) []AnalysisResult {
+	if minimum <= 0 {
+		return results
+	}
+
+	filtered := make([]AnalysisResult, 0, len(results))
+	for _, result := range results {
+		if result.Confidence == nil || *result.Confidence >= minimum {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// ComponentRef identifies the component an analysis finding is about, so
+// that findings can be filtered and gated without parsing their text.
+type ComponentRef struct {
+	// Name is the name of the component
+	Name string `json:"name"`
+	
+	// Version is the version of the component
+	Version string `json:"version,omitempty"`
+	
+	// PURL is the Package URL of the component, if known
+	PURL string `json:"purl,omitempty"`
+	
+	// Scope is the CycloneDX scope of the component ("required", "optional", "excluded")
+	Scope string `json:"scope,omitempty"`
+}
+
+// Citation references a source document supporting an analysis finding.
+type Citation struct {
+	// ID is the identifier of the cited document, such as an advisory ID
+	ID string `json:"id"`
+	
+	// Title is the title of the cited document
+	Title string `json:"title,omitempty"`
+	
+	// Source names where the document was harvested from
+	Source string `json:"source,omitempty"`
+	
+	// URL links to the original document, if known
+	URL string `json:"url,omitempty"`
+	
+	// Similarity is how closely the document matched the component query, from 0 to 1
+	Similarity float64 `json:"similarity"`
 }
\ No newline at end of file
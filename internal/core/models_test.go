@@ -0,0 +1,67 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterBySeverity(t *testing.T) {
+	results := []AnalysisResult{
+		{Finding: "critical", Severity: "Critical"},
+		{Finding: "high", Severity: "High"},
+		{Finding: "medium", Severity: "Medium"},
+		{Finding: "low", Severity: "Low"},
+		{Finding: "info", Severity: "Info"},
+	}
+
+	findings := func(results []AnalysisResult) []string {
+		var names []string
+		for _, result := range results {
+			names = append(names, result.Finding)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"critical", "high", "medium"}, findings(FilterBySeverity(results, "medium")))
+	assert.Equal(t, []string{"critical", "high"}, findings(FilterBySeverity(results, "HIGH")))
+	assert.Len(t, FilterBySeverity(results, "low"), 4)
+	assert.Len(t, FilterBySeverity(results, "Info"), 5)
+	assert.Len(t, FilterBySeverity(results, ""), 5)
+}
+
+func TestFilterByConfidence(t *testing.T) {
+	confidence := func(value float64) *float64 { return &value }
+	results := []AnalysisResult{
+		{Finding: "sure", Confidence: confidence(0.9)},
+		{Finding: "unsure", Confidence: confidence(0.4)},
+		{Finding: "deterministic"},
+	}
+
+	var kept []string
+	for _, result := range FilterByConfidence(results, 0.5) {
+		kept = append(kept, result.Finding)
+	}
+	assert.Equal(t, []string{"sure", "deterministic"}, kept)
+	assert.Len(t, FilterByConfidence(results, 0.4), 3)
+	assert.Len(t, FilterByConfidence(results, 0), 3)
+}
+
+func TestParseConfidenceThreshold(t *testing.T) {
+	minimum, err := ParseConfidenceThreshold("0.75")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, minimum)
+
+	for _, value := range []string{"", "high", "-0.1", "1.5", "75%"} {
+		_, err := ParseConfidenceThreshold(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestValidateSeverityThreshold(t *testing.T) {
+	for _, minimum := range []string{"critical", "High", "MEDIUM", "low", "info"} {
+		assert.NoError(t, ValidateSeverityThreshold(minimum), minimum)
+	}
+	assert.Error(t, ValidateSeverityThreshold("severe"))
+	assert.Error(t, ValidateSeverityThreshold(""))
+}
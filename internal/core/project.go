@@ -0,0 +1,59 @@
+// Package core provides the project context that determines which copyleft
+// obligations apply to an SBOM's components and how its SBOMs are tagged.
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Distribution models of a project, which decide whether the obligations of
+// a copyleft license are triggered.
+const (
+	// DistributionSaaS is software run as a network service; only network
+	// copyleft licenses such as the AGPL apply
+	DistributionSaaS = "saas"
+	// DistributionBinary is software shipped to others, which triggers every
+	// copyleft license
+	DistributionBinary = "distributed"
+	// DistributionInternal is software used only within the organization,
+	// which triggers no copyleft obligations
+	DistributionInternal = "internal"
+)
+
+// ProjectContext declares a project's own license and distribution model.
+// An empty field means unknown, and license analysis then assumes the
+// strictest case.
+type ProjectContext struct {
+	// License is the SPDX identifier the project itself is released under
+	License string `json:"license,omitempty" yaml:"license"`
+
+	// Distribution is "saas", "distributed" or "internal"
+	Distribution string `json:"distribution,omitempty" yaml:"distribution"`
+
+	// Tags apply to every SBOM of the project, unless an SBOM has its own
+	// tag of the same key
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+}
+
+// Validate checks the distribution model and tags and normalizes their case.
+func (p *ProjectContext) Validate() error {
+	p.License = strings.TrimSpace(p.License)
+	p.Distribution = strings.ToLower(strings.TrimSpace(p.Distribution))
+	tags, err := NormalizeTags(p.Tags)
+	if err != nil {
+		return err
+	}
+	p.Tags = tags
+	switch p.Distribution {
+	case "", DistributionSaaS, DistributionBinary, DistributionInternal:
+		return nil
+	default:
+		return fmt.Errorf("invalid distribution %q (expected %s, %s or %s)", p.Distribution, DistributionSaaS, DistributionBinary, DistributionInternal)
+	}
+}
+
+// IsZero reports whether nothing is declared about the project.
+func (p ProjectContext) IsZero() bool {
+	return p.License == "" && p.Distribution == "" && len(p.Tags) == 0
+}
@@ -0,0 +1,80 @@
+// Package core provides the links of components to their source
+// repository, website and issue tracker, from their external references.
+package core
+
+import (
+	"net/url"
+	"strings"
+)
+
+// External reference types as defined by CycloneDX.
+const (
+	ReferenceVCS          = "vcs"
+	ReferenceWebsite      = "website"
+	ReferenceIssueTracker = "issue-tracker"
+)
+
+// ExternalURL returns the URL of the first external reference of the given
+// type, or "" if the component has none.
+func (c Component) ExternalURL(refType string) string {
+	for _, ref := range c.ExternalReferences {
+		if strings.EqualFold(ref.Type, refType) && strings.TrimSpace(ref.URL) != "" {
+			return strings.TrimSpace(ref.URL)
+		}
+	}
+	return ""
+}
+
+// RepositoryURL returns the web address of the component's source
+// repository, from its vcs reference. Clone URLs are rewritten to the
+// repository page, so "git+https://github.com/lodash/lodash.git" and
+// "git@github.com:lodash/lodash.git" both become
+// "https://github.com/lodash/lodash". It returns "" if the component has no
+// vcs reference with a host.
+func (c Component) RepositoryURL() string {
+	repository := c.ExternalURL(ReferenceVCS)
+	if repository == "" {
+		return ""
+	}
+
+	repository = strings.TrimPrefix(repository, "git+")
+	if rest, ok := strings.CutPrefix(repository, "git@"); ok && !strings.Contains(rest, "://") {
+		// scp-like syntax: git@github.com:owner/name.git
+		repository = "https://" + strings.Replace(rest, ":", "/", 1)
+	}
+
+	parsed, err := url.Parse(repository)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+	case "git", "ssh":
+		parsed.Scheme = "https"
+	default:
+		return ""
+	}
+	parsed.User = nil
+	parsed.Fragment = ""
+	parsed.RawQuery = ""
+	if parsed.Port() == "22" {
+		parsed.Host = parsed.Hostname()
+	}
+	parsed.Path = strings.TrimSuffix(strings.TrimSuffix(parsed.Path, "/"), ".git")
+	parsed.RawPath = ""
+	return parsed.String()
+}
+
+// SourceURL returns where to link the component for people looking into
+// it: its source repository, else its website. It returns "" if the
+// component has neither as an http or https URL.
+func (c Component) SourceURL() string {
+	if repository := c.RepositoryURL(); repository != "" {
+		return repository
+	}
+	website := c.ExternalURL(ReferenceWebsite)
+	if parsed, err := url.Parse(website); err == nil && parsed.Host != "" && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+		return website
+	}
+	return ""
+}
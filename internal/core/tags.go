@@ -0,0 +1,96 @@
+// Package core provides the tags labelling SBOMs and projects, such as their
+// team, environment or criticality.
+package core
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Limits of the tags of an SBOM or project.
+const (
+	MaxTags           = 64
+	MaxTagValueLength = 256
+)
+
+// tagKeyPattern matches tag keys: lowercase letters, digits and ".", "_",
+// "-" or "/", starting with a letter or digit.
+var tagKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]{0,62}$`)
+
+// NormalizeTags checks tags and returns them with lowercase keys and
+// trimmed values, or nil if there are none.
+func NormalizeTags(tags map[string]string) (map[string]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	if len(tags) > MaxTags {
+		return nil, fmt.Errorf("%d tags given, more than the maximum of %d", len(tags), MaxTags)
+	}
+
+	normalized := make(map[string]string, len(tags))
+	for key, value := range tags {
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !tagKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid tag key %q (expected up to 63 letters, digits, '.', '_', '-' or '/')", key)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("tag %q has no value", key)
+		}
+		if len(value) > MaxTagValueLength {
+			return nil, fmt.Errorf("tag %q has a value longer than %d bytes", key, MaxTagValueLength)
+		}
+		if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+			return nil, fmt.Errorf("tag %q has a value with control characters", key)
+		}
+		if _, duplicate := normalized[key]; duplicate {
+			return nil, fmt.Errorf("tag %q is given twice", key)
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+// ParseTags parses tags given as "key=value" pairs, such as on the command
+// line or in a ?tag= query parameter, and normalizes them.
+func ParseTags(pairs []string) (map[string]string, error) {
+	tags := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tag %q (expected key=value)", pair)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, duplicate := tags[key]; duplicate {
+			return nil, fmt.Errorf("tag %q is given twice", key)
+		}
+		tags[key] = value
+	}
+	return NormalizeTags(tags)
+}
+
+// MatchesTags reports whether tags include every tag of filter with the
+// same value. Every set of tags matches an empty filter.
+func MatchesTags(tags, filter map[string]string) bool {
+	for key, value := range filter {
+		if tags[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// EffectiveTags returns the tags of the SBOM together with those declared
+// for its project, matched by SBOM name. The SBOM's own tags take
+// precedence.
+func (s SBOM) EffectiveTags(projects map[string]ProjectContext) map[string]string {
+	tags := maps.Clone(projects[s.Name].Tags)
+	if tags == nil {
+		tags = make(map[string]string, len(s.Tags))
+	}
+	maps.Copy(tags, s.Tags)
+	return tags
+}
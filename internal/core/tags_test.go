@@ -0,0 +1,55 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags([]string{"Env=prod", " team = payments ", "service.tier=1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "payments", "service.tier": "1"}, tags)
+
+	tags, err = ParseTags(nil)
+	require.NoError(t, err)
+	assert.Nil(t, tags)
+
+	_, err = ParseTags([]string{"prod"})
+	assert.ErrorContains(t, err, "expected key=value")
+	_, err = ParseTags([]string{"env=prod", "ENV=staging"})
+	assert.ErrorContains(t, err, `tag "env" is given twice`)
+	_, err = ParseTags([]string{"env="})
+	assert.ErrorContains(t, err, "has no value")
+	_, err = ParseTags([]string{"-env=prod"})
+	assert.ErrorContains(t, err, "invalid tag key")
+	_, err = ParseTags([]string{"env=" + strings.Repeat("x", MaxTagValueLength+1)})
+	assert.ErrorContains(t, err, "longer than")
+}
+
+func TestMatchesTags(t *testing.T) {
+	tags := map[string]string{"env": "prod", "team": "payments"}
+
+	assert.True(t, MatchesTags(tags, nil))
+	assert.True(t, MatchesTags(tags, map[string]string{"env": "prod"}))
+	assert.True(t, MatchesTags(tags, map[string]string{"env": "prod", "team": "payments"}))
+	assert.False(t, MatchesTags(tags, map[string]string{"env": "staging"}))
+	assert.False(t, MatchesTags(tags, map[string]string{"env": "prod", "tier": "1"}))
+	assert.False(t, MatchesTags(nil, map[string]string{"env": "prod"}))
+}
+
+func TestSBOM_EffectiveTags(t *testing.T) {
+	projects := map[string]ProjectContext{
+		"storefront": {Tags: map[string]string{"team": "web", "env": "prod"}},
+	}
+
+	sbom := SBOM{Name: "storefront", Tags: map[string]string{"env": "staging"}}
+	assert.Equal(t, map[string]string{"team": "web", "env": "staging"}, sbom.EffectiveTags(projects))
+	assert.Equal(t, map[string]string{"team": "web", "env": "prod"}, projects["storefront"].Tags, "project tags are not modified")
+
+	other := SBOM{Name: "desktop-client"}
+	assert.Empty(t, other.EffectiveTags(projects))
+	assert.NotNil(t, other.EffectiveTags(nil))
+}
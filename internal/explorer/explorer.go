@@ -0,0 +1,608 @@
+// Package explorer provides an interactive terminal explorer of analysis
+// results, built on Bubble Tea. A list pane shows the findings or the
+// components of an SBOM beside a detail pane for the selected row; findings
+// can be filtered by severity, agent and component name.
+package explorer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// Panes of the explorer.
+const (
+	PaneComponents = "components"
+	PaneFindings   = "findings"
+)
+
+// severities are the severity filters, cycled through with s; "" shows
+// every finding.
+var severities = []string{"", "Low", "Medium", "High", "Critical"}
+
+// The size of the terminal until it is reported.
+const (
+	defaultWidth  = 100
+	defaultHeight = 30
+)
+
+var (
+	titleStyle     = lipgloss.NewStyle().Bold(true)
+	activeTabStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	tabStyle       = lipgloss.NewStyle().Faint(true)
+	hintStyle      = lipgloss.NewStyle().Faint(true)
+	selectedStyle  = lipgloss.NewStyle().Reverse(true)
+	paneStyle      = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8"))
+	focusedStyle   = paneStyle.BorderForeground(lipgloss.Color("12"))
+
+	severityStyles = map[int]lipgloss.Style{
+		4: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+		3: lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+		2: lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		1: lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+	}
+)
+
+// Explorer is the Bubble Tea model of an interactive session.
+type Explorer struct {
+	sbom    core.SBOM
+	results []core.AnalysisResult
+	risk    analysis.RiskScore
+	// agents are the names of the agents with findings, for the agent filter
+	agents []string
+
+	pane     string
+	severity string
+	agent    string
+	search   string
+	// cursor and offset are the selected and first shown row of each pane
+	cursor map[string]int
+	offset map[string]int
+
+	// detailFocused sends the movement keys to the detail pane, to scroll it
+	detailFocused bool
+	searching     bool
+	showHelp      bool
+	input         textinput.Model
+	detail        viewport.Model
+
+	width, height int
+}
+
+// New creates an explorer of an SBOM and its analysis results, starting on
+// the findings pane, most severe first.
+func New(sbom core.SBOM, results []core.AnalysisResult) *Explorer {
+	sorted := make([]core.AnalysisResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return core.SeverityRank(sorted[i].Severity) > core.SeverityRank(sorted[j].Severity)
+	})
+
+	seen := make(map[string]bool)
+	var agents []string
+	for _, result := range sorted {
+		if !seen[result.AgentName] {
+			seen[result.AgentName] = true
+			agents = append(agents, result.AgentName)
+		}
+	}
+	sort.Strings(agents)
+
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "component name"
+
+	e := &Explorer{
+		sbom:    sbom,
+		results: sorted,
+		risk:    analysis.ScoreRisk(sorted),
+		agents:  agents,
+		pane:    PaneFindings,
+		cursor:  make(map[string]int),
+		offset:  make(map[string]int),
+		input:   input,
+		width:   defaultWidth,
+		height:  defaultHeight,
+	}
+	e.layout()
+	return e
+}
+
+// Init implements tea.Model.
+func (e *Explorer) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (e *Explorer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.width, e.height = msg.Width, msg.Height
+		e.layout()
+		return e, nil
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return e, tea.Quit
+		}
+		if e.searching {
+			return e, e.updateSearch(msg)
+		}
+		if e.detailFocused {
+			return e, e.updateDetail(msg)
+		}
+		return e, e.updateList(msg)
+	}
+	return e, nil
+}
+
+// updateSearch edits the component name filter, applied as it is typed.
+func (e *Explorer) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		e.searching = false
+		e.input.Blur()
+	case tea.KeyEsc:
+		e.searching = false
+		e.input.Blur()
+		e.input.SetValue("")
+		e.setSearch("")
+	default:
+		var cmd tea.Cmd
+		e.input, cmd = e.input.Update(msg)
+		e.setSearch(e.input.Value())
+		return cmd
+	}
+	e.layout()
+	return nil
+}
+
+// updateDetail scrolls the detail pane until it is left.
+func (e *Explorer) updateDetail(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "esc", "left", "h", "enter":
+		e.detailFocused = false
+		return nil
+	}
+	var cmd tea.Cmd
+	e.detail, cmd = e.detail.Update(msg)
+	return cmd
+}
+
+// updateList moves through the list pane and changes its filters.
+func (e *Explorer) updateList(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "tab":
+		if e.pane == PaneFindings {
+			e.showPane(PaneComponents)
+		} else {
+			e.showPane(PaneFindings)
+		}
+	case "f":
+		e.showPane(PaneFindings)
+	case "c":
+		e.showPane(PaneComponents)
+	case "up", "k":
+		e.move(-1)
+	case "down", "j":
+		e.move(1)
+	case "pgup":
+		e.move(-e.listHeight())
+	case "pgdown", " ":
+		e.move(e.listHeight())
+	case "home", "g":
+		e.move(-e.rows())
+	case "end", "G":
+		e.move(e.rows())
+	case "enter", "right", "l":
+		if e.rows() > 0 {
+			e.detailFocused = true
+		}
+	case "s":
+		e.severity = next(severities, e.severity)
+		e.showPane(PaneFindings)
+	case "a":
+		e.agent = next(append([]string{""}, e.agents...), e.agent)
+		e.showPane(PaneFindings)
+	case "/":
+		e.searching = true
+		e.layout()
+		return e.input.Focus()
+	case "x":
+		e.severity, e.agent = "", ""
+		e.input.SetValue("")
+		e.setSearch("")
+	case "?":
+		e.showHelp = !e.showHelp
+		e.layout()
+	}
+	return nil
+}
+
+// next returns the value after current in values, wrapping around.
+func next(values []string, current string) string {
+	for i, value := range values {
+		if value == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
+// showPane switches the list to pane, keeping its selection.
+func (e *Explorer) showPane(pane string) {
+	e.pane = pane
+	e.detailFocused = false
+	e.move(0)
+}
+
+// setSearch filters both panes by component name, starting at their top.
+func (e *Explorer) setSearch(search string) {
+	e.search = search
+	for _, pane := range []string{PaneFindings, PaneComponents} {
+		e.cursor[pane], e.offset[pane] = 0, 0
+	}
+	e.move(0)
+}
+
+// move moves the selection of the current pane by delta rows, keeping it
+// within the rows and in view, and shows the selected row in the detail
+// pane.
+func (e *Explorer) move(delta int) {
+	cursor := max(min(e.cursor[e.pane]+delta, e.rows()-1), 0)
+	offset := e.offset[e.pane]
+	height := e.listHeight()
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+height {
+		offset = cursor - height + 1
+	}
+	e.cursor[e.pane], e.offset[e.pane] = cursor, max(min(offset, e.rows()-height), 0)
+
+	e.detail.SetContent(lipgloss.NewStyle().Width(e.detail.Width).Render(e.detailContent()))
+	e.detail.GotoTop()
+}
+
+// Selected returns the row selected in the current pane, counted from 0.
+func (e *Explorer) Selected() int {
+	return e.cursor[e.pane]
+}
+
+// Findings returns the findings passing the severity, agent and search filters.
+func (e *Explorer) Findings() []core.AnalysisResult {
+	var findings []core.AnalysisResult
+	for _, result := range e.results {
+		if e.severity != "" && core.SeverityRank(result.Severity) < core.SeverityRank(e.severity) {
+			continue
+		}
+		if e.agent != "" && result.AgentName != e.agent {
+			continue
+		}
+		if e.search != "" && (result.Component == nil || !containsFold(result.Component.Name, e.search)) {
+			continue
+		}
+		findings = append(findings, result)
+	}
+	return findings
+}
+
+// Components returns the components passing the search filter.
+func (e *Explorer) Components() []core.Component {
+	var components []core.Component
+	for _, component := range e.sbom.Components {
+		if e.search == "" || containsFold(component.Name, e.search) {
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// rows returns the number of rows of the current pane.
+func (e *Explorer) rows() int {
+	if e.pane == PaneComponents {
+		return len(e.Components())
+	}
+	return len(e.Findings())
+}
+
+// Layout of the view: two header lines, the panes with their borders, and
+// a footer line, or the help lines.
+const (
+	headerLines = 2
+	borderLines = 2
+)
+
+// listWidth and detailWidth are the inner widths of the panes.
+func (e *Explorer) listWidth() int {
+	return max(e.width*3/5-2, 10)
+}
+
+func (e *Explorer) detailWidth() int {
+	return max(e.width-e.listWidth()-4, 10)
+}
+
+// paneHeight is the inner height of the panes.
+func (e *Explorer) paneHeight() int {
+	return max(e.height-headerLines-borderLines-lipgloss.Height(e.footer()), 3)
+}
+
+// listHeight is the number of rows shown in the list pane, below its
+// column headings.
+func (e *Explorer) listHeight() int {
+	return e.paneHeight() - 1
+}
+
+// layout sizes the panes to the terminal.
+func (e *Explorer) layout() {
+	e.detail.Width, e.detail.Height = e.detailWidth(), e.paneHeight()
+	e.input.Width = max(e.width-4, 10)
+	e.move(0)
+}
+
+// View implements tea.Model.
+func (e *Explorer) View() string {
+	title := e.sbom.Name
+	if e.sbom.ID != "" {
+		title += " (" + e.sbom.ID + ")"
+	}
+	header := titleStyle.Render(fmt.Sprintf("SBOM %s: %d components, %d findings, risk score %d/100 (%s)",
+		title, len(e.sbom.Components), len(e.results), e.risk.Score, e.risk.Level))
+	tabs := e.tab(PaneFindings, len(e.Findings())) + "  " + e.tab(PaneComponents, len(e.Components())) + e.filters()
+
+	list, detail := paneStyle, focusedStyle
+	if !e.detailFocused {
+		list, detail = focusedStyle, paneStyle
+	}
+	height := e.paneHeight()
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		list.Width(e.listWidth()).Height(height).Render(e.listView()),
+		detail.Width(e.detailWidth()).Height(height).Render(e.detail.View()),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, header, tabs, panes, e.footer())
+}
+
+// tab labels a pane with its number of rows, marking the current one.
+func (e *Explorer) tab(pane string, rows int) string {
+	label := fmt.Sprintf("%s%s (%d)", strings.ToUpper(pane[:1]), pane[1:], rows)
+	if pane == e.pane {
+		return activeTabStyle.Render(label)
+	}
+	return tabStyle.Render(label)
+}
+
+// filters describes the active filters.
+func (e *Explorer) filters() string {
+	var active []string
+	if e.severity != "" {
+		active = append(active, "severity >= "+e.severity)
+	}
+	if e.agent != "" {
+		active = append(active, "agent: "+e.agent)
+	}
+	if e.search != "" {
+		active = append(active, "component ~ "+e.search)
+	}
+	if len(active) == 0 {
+		return ""
+	}
+	return "   filters: " + strings.Join(active, ", ")
+}
+
+// footer shows the search input, the help or the main keys.
+func (e *Explorer) footer() string {
+	switch {
+	case e.searching:
+		return e.input.View()
+	case e.showHelp:
+		return hintStyle.Width(e.width).Render(help)
+	case e.detailFocused:
+		return hintStyle.Width(e.width).Render("↑/↓ scroll  esc back  q quit")
+	default:
+		return hintStyle.Width(e.width).Render("↑/↓ select  enter details  tab panes  s severity  a agent  / search  x clear  ? help  q quit")
+	}
+}
+
+// help lists the keys of the explorer.
+const help = `tab       switch between findings and components   f, c   findings, components
+↑/↓, j/k  select a row        pgup/pgdn  page      home/end, g/G  first or last row
+enter, →  scroll the details, esc or ← to return to the list
+s         cycle the severity filter: findings at or above Low, Medium, High, Critical
+a         cycle the agent filter through the agents with findings
+/         filter by component name as you type; enter keeps it, esc clears it
+x         clear all filters   ? hide this help   q, ctrl+c quit`
+
+// listView renders the column headings and the rows of the list pane in
+// view.
+func (e *Explorer) listView() string {
+	width := e.listWidth()
+	var heading string
+	var rows []string
+	var styles []lipgloss.Style
+	if e.pane == PaneComponents {
+		// The name takes the width left by the other columns
+		widths := []int{max(width-37, 12), 12, 14}
+		heading = row(width, widths, "Name", "Version", "License", "Findings")
+		counts := e.findingCounts()
+		for _, component := range e.Components() {
+			rows = append(rows, row(width, widths, component.Name, component.Version, component.License,
+				fmt.Sprint(counts[componentKey(component.Name, component.Version)])))
+			styles = append(styles, lipgloss.NewStyle())
+		}
+		if len(rows) == 0 {
+			return hintStyle.Render(heading) + "\nNo components"
+		}
+	} else {
+		heading = row(width, []int{9, 24, 20}, "Severity", "Agent", "Component", "Finding")
+		for _, result := range e.Findings() {
+			component := ""
+			if result.Component != nil {
+				component = result.Component.Name
+			}
+			rows = append(rows, row(width, []int{9, 24, 20}, result.Severity, result.AgentName, component, firstLine(result.Finding)))
+			styles = append(styles, severityStyles[core.SeverityRank(result.Severity)])
+		}
+		if len(rows) == 0 {
+			return hintStyle.Render(heading) + "\nNo findings"
+		}
+	}
+
+	shown := []string{hintStyle.Render(heading)}
+	start := e.offset[e.pane]
+	for i := start; i < min(start+e.listHeight(), len(rows)); i++ {
+		if i == e.Selected() {
+			shown = append(shown, selectedStyle.Render(rows[i]))
+		} else {
+			shown = append(shown, styles[i].Render(rows[i]))
+		}
+	}
+	return strings.Join(shown, "\n")
+}
+
+// row lays out cells in columns of the given widths, the last cell taking
+// the rest of the row, padded to width.
+func row(width int, widths []int, cells ...string) string {
+	var out strings.Builder
+	for i, column := range widths {
+		out.WriteString(pad(truncate(cells[i], column), column) + " ")
+	}
+	out.WriteString(cells[len(widths)])
+	return pad(truncate(out.String(), width), width)
+}
+
+// pad pads s with spaces to width runes.
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
+
+// detailContent describes the selected row in full.
+func (e *Explorer) detailContent() string {
+	if e.pane == PaneComponents {
+		components := e.Components()
+		if len(components) == 0 {
+			return ""
+		}
+		return e.componentDetail(components[e.Selected()])
+	}
+	findings := e.Findings()
+	if len(findings) == 0 {
+		return ""
+	}
+	return findingDetail(findings[e.Selected()])
+}
+
+func findingDetail(result core.AnalysisResult) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s finding of %s\n\n", severityLabel(result.Severity), result.AgentName)
+	fmt.Fprintf(&out, "%s\n", result.Finding)
+	if result.Component != nil {
+		fmt.Fprintf(&out, "\nComponent:     %s %s\n", result.Component.Name, result.Component.Version)
+	}
+	if result.VulnerabilityID != "" {
+		fmt.Fprintf(&out, "Vulnerability: %s\n", result.VulnerabilityID)
+	}
+	if remediation := result.Remediation(); remediation != "" {
+		fmt.Fprintf(&out, "Remediation:   %s\n", remediation)
+	}
+	for _, citation := range result.Citations {
+		fmt.Fprintf(&out, "Citation:      [%s] %s (%.0f%% match)\n", citation.ID, citation.Title, citation.Similarity*100)
+	}
+	return out.String()
+}
+
+func (e *Explorer) componentDetail(component core.Component) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s\n\n", titleStyle.Render(strings.TrimSpace(component.Name+" "+component.Version)))
+	for _, field := range [][2]string{
+		{"PURL", component.PURL},
+		{"CPE", component.CPE},
+		{"Licenses", strings.Join(component.DeclaredLicenses(), ", ")},
+		{"Supplier", component.Supplier},
+		{"Type", component.Type},
+		{"Scope", component.EffectiveScope()},
+		{"Assembly", core.Assembly(e.sbom.Components, component)},
+		{"Source", component.RepositoryURL()},
+		{"Website", component.ExternalURL(core.ReferenceWebsite)},
+		{"Issues", component.ExternalURL(core.ReferenceIssueTracker)},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(&out, "%-10s %s\n", field[0]+":", field[1])
+		}
+	}
+	var nested []string
+	for _, candidate := range e.sbom.Components {
+		if candidate.Parent == component.Key() {
+			nested = append(nested, strings.TrimSpace(candidate.Name+" "+candidate.Version))
+		}
+	}
+	if len(nested) > 0 {
+		fmt.Fprintf(&out, "%-10s %s\n", "Contains:", strings.Join(nested, ", "))
+	}
+
+	fmt.Fprintln(&out)
+	found := false
+	for _, result := range e.results {
+		if result.Component == nil || componentKey(result.Component.Name, result.Component.Version) != componentKey(component.Name, component.Version) {
+			continue
+		}
+		found = true
+		fmt.Fprintf(&out, "%s %s: %s\n", severityLabel(result.Severity), result.AgentName, firstLine(result.Finding))
+	}
+	if !found {
+		fmt.Fprintln(&out, "No findings")
+	}
+	return out.String()
+}
+
+// findingCounts counts the findings of each component.
+func (e *Explorer) findingCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, result := range e.results {
+		if result.Component != nil {
+			counts[componentKey(result.Component.Name, result.Component.Version)]++
+		}
+	}
+	return counts
+}
+
+func componentKey(name, version string) string {
+	return name + "@" + version
+}
+
+// truncate shortens s to at most width runes.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// severityLabel renders a severity in its color.
+func severityLabel(severity string) string {
+	if style, ok := severityStyles[core.SeverityRank(severity)]; ok {
+		return style.Render(severity)
+	}
+	return severity
+}
@@ -0,0 +1,171 @@
+package explorer
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExplorer() *Explorer {
+	log4j := core.Component{Name: "log4j-core", Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", License: "Apache-2.0"}
+	lodash := core.Component{Name: "lodash", Version: "4.17.20", License: "MIT", ExternalReferences: []core.ExternalReference{
+		{Type: core.ReferenceVCS, URL: "git+https://github.com/lodash/lodash.git"},
+		{Type: core.ReferenceIssueTracker, URL: "https://github.com/lodash/lodash/issues"},
+	}}
+	sbom := core.SBOM{ID: "sbom-1", Name: "payments-api", Components: []core.Component{log4j, lodash}}
+
+	results := []core.AnalysisResult{
+		{AgentName: "License Agent", Finding: "Permissive license", Severity: "Low", Component: lodash.Ref()},
+		{AgentName: "Vulnerability Scanner", Finding: "Log4Shell\nRemote code execution", Severity: "Critical", Component: log4j.Ref(), VulnerabilityID: "CVE-2021-44228"},
+		{AgentName: "Vulnerability Scanner", Finding: "Prototype pollution", Severity: "High", Component: lodash.Ref()},
+	}
+	return sized(New(sbom, results))
+}
+
+// sized gives an explorer a terminal wide enough for the details to fit on
+// their lines.
+func sized(e *Explorer) *Explorer {
+	e.Update(tea.WindowSizeMsg{Width: 180, Height: 40})
+	return e
+}
+
+// press sends keys to an explorer, each either a named key such as "enter"
+// or the runes typed.
+func press(e *Explorer, keys ...string) tea.Cmd {
+	named := map[string]tea.KeyType{
+		"enter": tea.KeyEnter, "esc": tea.KeyEsc, "tab": tea.KeyTab,
+		"down": tea.KeyDown, "up": tea.KeyUp, "pgdown": tea.KeyPgDown, "end": tea.KeyEnd,
+		"ctrl+c": tea.KeyCtrlC,
+	}
+	var cmd tea.Cmd
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if keyType, ok := named[key]; ok {
+			msg = tea.KeyMsg{Type: keyType}
+		}
+		_, cmd = e.Update(msg)
+	}
+	return cmd
+}
+
+func TestExplorer_Findings(t *testing.T) {
+	e := testExplorer()
+
+	view := e.View()
+	assert.Contains(t, view, "SBOM payments-api (sbom-1): 2 components, 3 findings")
+	assert.Contains(t, view, "Findings (3)  Components (2)")
+	// Most severe first, showing only the first line of each finding
+	assert.Regexp(t, `(?s)Critical +Vulnerability Scanner +log4j-core +Log4Shell .*High .*Low `, view)
+
+	press(e, "s", "s", "s")
+	assert.Len(t, e.Findings(), 2)
+	assert.Contains(t, e.View(), "filters: severity >= High")
+
+	press(e, "a")
+	assert.Empty(t, e.Findings(), "filters combine")
+	assert.Contains(t, e.View(), "No findings")
+
+	press(e, "x", "/", "l", "o", "d", "enter")
+	require.Len(t, e.Findings(), 2)
+	assert.Equal(t, "Prototype pollution", e.Findings()[0].Finding)
+	assert.Contains(t, e.View(), "filters: component ~ lod")
+
+	// Escape clears the search being typed
+	press(e, "/", "esc")
+	assert.Len(t, e.Findings(), 3)
+}
+
+func TestExplorer_Details(t *testing.T) {
+	e := testExplorer()
+
+	view := e.View()
+	assert.Contains(t, view, "Log4Shell")
+	assert.Contains(t, view, "Remote code execution")
+	assert.Contains(t, view, "Vulnerability: CVE-2021-44228")
+
+	press(e, "down")
+	assert.Contains(t, e.View(), "High finding of Vulnerability Scanner")
+
+	press(e, "c", "down")
+	view = e.View()
+	assert.Contains(t, view, "lodash 4.17.20")
+	assert.Contains(t, view, "Licenses:  MIT")
+	assert.Contains(t, view, "Source:    https://github.com/lodash/lodash ")
+	assert.Contains(t, view, "Issues:    https://github.com/lodash/lodash/issues")
+	assert.NotContains(t, view, "Website:")
+	assert.Contains(t, view, "Prototype pollution")
+	assert.Contains(t, view, "Permissive license")
+
+	// The selection stays within the rows, and with each pane
+	press(e, "down", "down", "tab")
+	assert.Equal(t, 1, e.Selected())
+	press(e, "tab")
+	assert.Equal(t, 1, e.Selected())
+}
+
+func TestExplorer_Assemblies(t *testing.T) {
+	e := sized(New(core.SBOM{ID: "router-1", Name: "router", Components: []core.Component{
+		{Name: "router-firmware", Version: "2.1", Type: "firmware"},
+		{Name: "busybox", Version: "1.36.1", Parent: "router-firmware@2.1"},
+		{Name: "openssl", Version: "3.0.13", Parent: "router-firmware@2.1"},
+	}}, nil))
+
+	assert.Contains(t, e.View(), "No findings")
+	press(e, "c")
+	assert.Contains(t, e.View(), "Contains:  busybox 1.36.1, openssl 3.0.13")
+
+	press(e, "down")
+	assert.Contains(t, e.View(), "Assembly:  router-firmware 2.1")
+}
+
+func TestExplorer_Scrolling(t *testing.T) {
+	var components []core.Component
+	for i := 0; i < 45; i++ {
+		components = append(components, core.Component{Name: fmt.Sprintf("lib-%02d", i), Version: "1.0.0"})
+	}
+	e := sized(New(core.SBOM{Name: "big", Components: components}, nil))
+	press(e, "c")
+
+	view := e.View()
+	assert.Contains(t, view, "lib-00")
+	assert.NotContains(t, view, "lib-44")
+
+	press(e, "end")
+	assert.Equal(t, 44, e.Selected())
+	view = e.View()
+	assert.Contains(t, view, "lib-44")
+	assert.NotContains(t, view, "lib-00")
+
+	// The list follows the selection back up
+	press(e, "g")
+	assert.Equal(t, 0, e.Selected())
+	assert.Contains(t, e.View(), "lib-00")
+
+	press(e, "pgdown")
+	assert.Equal(t, e.listHeight(), e.Selected())
+}
+
+func TestExplorer_Keys(t *testing.T) {
+	e := testExplorer()
+
+	// Entering the details sends the movement keys to them
+	press(e, "enter", "down")
+	assert.Equal(t, 0, e.Selected())
+	press(e, "esc", "down")
+	assert.Equal(t, 1, e.Selected())
+
+	press(e, "?")
+	assert.Contains(t, e.View(), "cycle the severity filter")
+
+	// Keys typed in the search are not commands
+	press(e, "/", "q")
+	assert.Equal(t, "q", e.search)
+
+	assert.NotNil(t, press(e, "ctrl+c"))
+	assert.Nil(t, press(testExplorer(), "c"))
+	assert.NotNil(t, press(testExplorer(), "q"))
+}
@@ -0,0 +1,233 @@
+// Package ignore provides the .sentinelignore file, which excludes accepted
+// findings from analysis results. Each line names what to ignore, followed
+// by the reason it is ignored:
+//
+//	# Accepted risks, reviewed quarterly
+//	component:left-pad@1.3.0   Vendored, patched in our fork
+//	pkg:npm/@acme/             Internal packages are reviewed separately
+//	vuln:CVE-2021-44228        JNDI lookups are disabled (SEC-142)
+//	rule:npm-unlicensed        Legacy packages tracked in LEGAL-7
+//
+// A component entry matches the component's name and, if given, version; a
+// PURL prefix matches the components whose Package URL starts with it; a
+// vulnerability entry matches the finding's advisory ID or an alias such as
+// a CVE mentioned in it; and a rule entry matches the findings of a custom
+// rule by name.
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// FileName is the name of the ignore file looked for in the working directory.
+const FileName = ".sentinelignore"
+
+// Kinds of ignore entries.
+const (
+	KindComponent     = "component"
+	KindPURL          = "purl"
+	KindVulnerability = "vuln"
+	KindRule          = "rule"
+)
+
+// Entry is a line of an ignore file.
+type Entry struct {
+	// Kind is KindComponent, KindPURL, KindVulnerability or KindRule
+	Kind string
+	// Value is the component, PURL prefix, advisory ID or rule name
+	Value string
+	// Reason explains why the findings are ignored
+	Reason string
+	// Line is the line number of the entry in its file
+	Line int
+}
+
+// String returns the entry as written in the ignore file, without the reason.
+func (e Entry) String() string {
+	if e.Kind == KindPURL {
+		return e.Value
+	}
+	return e.Kind + ":" + e.Value
+}
+
+// Matches reports whether the entry ignores result.
+func (e Entry) Matches(result core.AnalysisResult) bool {
+	switch e.Kind {
+	case KindComponent:
+		if result.Component == nil {
+			return false
+		}
+		name, version, hasVersion := strings.Cut(e.Value, "@")
+		return result.Component.Name == name && (!hasVersion || result.Component.Version == version)
+	case KindPURL:
+		if result.Component == nil || !strings.HasPrefix(result.Component.PURL, e.Value) {
+			return false
+		}
+		// pkg:npm/lodash must not match pkg:npm/lodash-es
+		rest := strings.TrimPrefix(result.Component.PURL, e.Value)
+		return rest == "" || strings.HasSuffix(e.Value, "/") || strings.ContainsRune("@/?#", rune(rest[0]))
+	case KindVulnerability:
+		return strings.EqualFold(result.VulnerabilityID, e.Value) || strings.Contains(result.Finding, e.Value)
+	case KindRule:
+		return result.RuleID == e.Value
+	default:
+		return false
+	}
+}
+
+// List is the parsed content of an ignore file.
+type List struct {
+	Entries []Entry
+}
+
+// Parse reads an ignore file. Every entry must give a reason.
+func Parse(r io.Reader) (*List, error) {
+	list := &List{}
+	var errs []error
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern, reason := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			pattern, reason = line[:i], strings.TrimSpace(line[i:])
+		}
+		entry, err := parseEntry(pattern, reason, number)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", number, err))
+			continue
+		}
+		list.Entries = append(list.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return list, nil
+}
+
+// parseEntry parses the pattern and reason of a line.
+func parseEntry(pattern, reason string, line int) (Entry, error) {
+	entry := Entry{Reason: reason, Line: line}
+
+	if strings.HasPrefix(pattern, "pkg:") {
+		entry.Kind, entry.Value = KindPURL, pattern
+	} else {
+		kind, value, found := strings.Cut(pattern, ":")
+		if !found || value == "" {
+			return Entry{}, fmt.Errorf("%q is not component:NAME[@VERSION], a pkg: PURL prefix, vuln:ID or rule:NAME", pattern)
+		}
+		switch kind {
+		case KindComponent, KindVulnerability, KindRule:
+			entry.Kind, entry.Value = kind, value
+		default:
+			return Entry{}, fmt.Errorf("unknown entry kind %q (component, pkg, vuln or rule)", kind)
+		}
+	}
+
+	if entry.Reason == "" {
+		return Entry{}, fmt.Errorf("%s has no reason; give the reason after the entry", pattern)
+	}
+	return entry, nil
+}
+
+// LoadFile reads the ignore file at path.
+func LoadFile(path string) (*List, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	list, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignore file '%s': %w", path, err)
+	}
+	return list, nil
+}
+
+// Ignored is a finding excluded by an ignore entry.
+type Ignored struct {
+	core.AnalysisResult
+	// Entry is the first entry matching the finding
+	Entry Entry `json:"-"`
+	// Reason is the reason of the entry
+	Reason string `json:"reason"`
+}
+
+// Apply splits results into the findings no entry matches and the ignored
+// ones. A nil list ignores nothing.
+func (l *List) Apply(results []core.AnalysisResult) ([]core.AnalysisResult, []Ignored) {
+	if l == nil || len(l.Entries) == 0 {
+		return results, nil
+	}
+
+	kept := make([]core.AnalysisResult, 0, len(results))
+	var ignored []Ignored
+	for _, result := range results {
+		if entry, ok := l.match(result); ok {
+			ignored = append(ignored, Ignored{AnalysisResult: result, Entry: entry, Reason: entry.Reason})
+			continue
+		}
+		kept = append(kept, result)
+	}
+	return kept, ignored
+}
+
+// match returns the first entry matching result.
+func (l *List) match(result core.AnalysisResult) (Entry, bool) {
+	for _, entry := range l.Entries {
+		if entry.Matches(result) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Waiver is an ignore entry declared in the configuration file, so that the
+// server excludes accepted findings from its analyses as the CLI does with
+// an ignore file. Match is written like the entries of an ignore file.
+type Waiver struct {
+	Match  string `yaml:"match" json:"match"`
+	Reason string `yaml:"reason" json:"reason"`
+	// Projects limits the waiver to SBOMs of these names; empty waives the
+	// findings of every project
+	Projects []string `yaml:"projects,omitempty" json:"projects,omitempty"`
+}
+
+// Entry parses the waiver into an ignore entry.
+func (w Waiver) Entry() (Entry, error) {
+	if strings.TrimSpace(w.Reason) == "" {
+		return Entry{}, errors.New("reason is required")
+	}
+	return parseEntry(strings.TrimSpace(w.Match), strings.TrimSpace(w.Reason), 0)
+}
+
+// Waivers returns the list of the waivers that apply to project. Invalid
+// waivers, which the configuration file rejects, are skipped.
+func Waivers(waivers []Waiver, project string) *List {
+	list := &List{}
+	for _, waiver := range waivers {
+		if len(waiver.Projects) > 0 && !slices.Contains(waiver.Projects, project) {
+			continue
+		}
+		if entry, err := waiver.Entry(); err == nil {
+			list.Entries = append(list.Entries, entry)
+		}
+	}
+	return list
+}
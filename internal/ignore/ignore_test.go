@@ -0,0 +1,95 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIgnoreFile = `# Accepted risks
+component:left-pad@1.3.0   Vendored, patched in our fork
+pkg:npm/lodash	Only used in build scripts
+vuln:CVE-2021-44228        JNDI lookups are disabled (SEC-142)
+rule:npm-unlicensed        Tracked in LEGAL-7
+`
+
+func TestParse(t *testing.T) {
+	list, err := Parse(strings.NewReader(testIgnoreFile))
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 4)
+
+	assert.Equal(t, Entry{Kind: KindComponent, Value: "left-pad@1.3.0", Reason: "Vendored, patched in our fork", Line: 2}, list.Entries[0])
+	assert.Equal(t, Entry{Kind: KindPURL, Value: "pkg:npm/lodash", Reason: "Only used in build scripts", Line: 3}, list.Entries[1])
+	assert.Equal(t, "vuln:CVE-2021-44228", list.Entries[2].String())
+	assert.Equal(t, "pkg:npm/lodash", list.Entries[1].String())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("component:left-pad\nlicense:GPL-3.0-only  Allowed\nleft-pad  no kind\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1: component:left-pad has no reason")
+	assert.Contains(t, err.Error(), `line 2: unknown entry kind "license"`)
+	assert.Contains(t, err.Error(), "line 3:")
+}
+
+func TestList_Apply(t *testing.T) {
+	list, err := Parse(strings.NewReader(testIgnoreFile))
+	require.NoError(t, err)
+
+	results := []core.AnalysisResult{
+		{Finding: "left-pad 1.3.0", Component: &core.ComponentRef{Name: "left-pad", Version: "1.3.0"}},
+		{Finding: "left-pad 1.4.0", Component: &core.ComponentRef{Name: "left-pad", Version: "1.4.0"}},
+		{Finding: "lodash", Component: &core.ComponentRef{Name: "lodash", PURL: "pkg:npm/lodash@4.17.15"}},
+		{Finding: "lodash-es", Component: &core.ComponentRef{Name: "lodash-es", PURL: "pkg:npm/lodash-es@4.17.15"}},
+		{Finding: "log4j has a known vulnerability [CVE-2021-44228]", VulnerabilityID: "GHSA-jfh8-c2jp-5v3q"},
+		{Finding: "unlicensed", RuleID: "npm-unlicensed"},
+		{Finding: "quality gap"},
+	}
+
+	kept, ignored := list.Apply(results)
+	assert.Equal(t, []string{"left-pad 1.4.0", "lodash-es", "quality gap"}, []string{kept[0].Finding, kept[1].Finding, kept[2].Finding})
+	require.Len(t, ignored, 4)
+	assert.Equal(t, "Vendored, patched in our fork", ignored[0].Reason)
+	assert.Equal(t, "JNDI lookups are disabled (SEC-142)", ignored[2].Reason)
+	assert.Equal(t, KindRule, ignored[3].Entry.Kind)
+
+	var none *List
+	kept, ignored = none.Apply(results)
+	assert.Len(t, kept, len(results))
+	assert.Empty(t, ignored)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte(testIgnoreFile), 0o644))
+
+	list, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, list.Entries, 4)
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), FileName))
+	assert.Error(t, err)
+}
+
+func TestWaivers(t *testing.T) {
+	waivers := []Waiver{
+		{Match: "vuln:CVE-2021-44228", Reason: "JNDI lookups are disabled"},
+		{Match: "component:left-pad", Reason: "Vendored", Projects: []string{"storefront"}},
+		{Match: "license:GPL-3.0-only", Reason: "Skipped, as the config file rejects it"},
+	}
+
+	list := Waivers(waivers, "storefront")
+	require.Len(t, list.Entries, 2)
+	assert.Equal(t, Entry{Kind: KindComponent, Value: "left-pad", Reason: "Vendored"}, list.Entries[1])
+	assert.Len(t, Waivers(waivers, "desktop-client").Entries, 1)
+
+	_, err := Waiver{Match: "vuln:CVE-2021-44228"}.Entry()
+	assert.EqualError(t, err, "reason is required")
+	_, err = waivers[2].Entry()
+	assert.ErrorContains(t, err, `unknown entry kind "license"`)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
 )
@@ -21,17 +22,18 @@ func NewCycloneDXParser() *CycloneDXParser {
 type cycloneDXDocument struct {
 	BOMFormat    string               `json:"bomFormat"`
 	SpecVersion  string               `json:"specVersion"`
-	SerialNumber string               `json:"serialNumber"`
+	SerialNumber string               `json:"serialNumber,omitempty"`
 	Version      int                  `json:"version"`
 	Metadata     *cycloneDXMetadata   `json:"metadata,omitempty"`
 	Components   []cycloneDXComponent `json:"components,omitempty"`
+	Services     []cycloneDXService   `json:"services,omitempty"`
 	Properties   []cycloneDXProperty  `json:"properties,omitempty"`
 }
 
 // cycloneDXMetadata represents the metadata section of a CycloneDX document.
 type cycloneDXMetadata struct {
 	Timestamp  string                  `json:"timestamp,omitempty"`
-	Tools      []cycloneDXTool         `json:"tools,omitempty"`
+	Tools      json.RawMessage         `json:"tools,omitempty"`
 	Authors    []cycloneDXOrganization `json:"authors,omitempty"`
 	Component  *cycloneDXComponent     `json:"component,omitempty"`
 	Supplier   *cycloneDXOrganization  `json:"supplier,omitempty"`
@@ -48,14 +50,67 @@ type cycloneDXComponent struct {
 	Group      string                 `json:"group,omitempty"`
 	Name       string                 `json:"name"`
 	Version    string                 `json:"version"`
+	Scope      string                 `json:"scope,omitempty"`
 	PURL       string                 `json:"purl,omitempty"`
+	CPE        string                 `json:"cpe,omitempty"`
 	Licenses   []cycloneDXLicense     `json:"licenses,omitempty"`
 	Properties []cycloneDXProperty    `json:"properties,omitempty"`
+	Evidence   *cycloneDXEvidence     `json:"evidence,omitempty"`
+	ExtRefs    []cycloneDXExternalRef `json:"externalReferences,omitempty"`
+	Components []cycloneDXComponent   `json:"components,omitempty"`
+}
+
+// cycloneDXService represents a service in a CycloneDX document.
+type cycloneDXService struct {
+	BOMRef               string                 `json:"bom-ref,omitempty"`
+	Provider             *cycloneDXOrganization `json:"provider,omitempty"`
+	Group                string                 `json:"group,omitempty"`
+	Name                 string                 `json:"name"`
+	Version              string                 `json:"version,omitempty"`
+	Endpoints            []string               `json:"endpoints,omitempty"`
+	Authenticated        *bool                  `json:"authenticated,omitempty"`
+	CrossesTrustBoundary bool                   `json:"x-trust-boundary,omitempty"`
+	ExtRefs              []cycloneDXExternalRef `json:"externalReferences,omitempty"`
+	Services             []cycloneDXService     `json:"services,omitempty"`
+}
+
+// cycloneDXEvidence represents the evidence section of a CycloneDX component.
+// Identity is an object in CycloneDX 1.5 and an array in 1.6, so it is decoded lazily.
+type cycloneDXEvidence struct {
+	Identity    json.RawMessage       `json:"identity,omitempty"`
+	Occurrences []cycloneDXOccurrence `json:"occurrences,omitempty"`
+	Licenses    []cycloneDXLicense    `json:"licenses,omitempty"`
+	Copyright   []cycloneDXCopyright  `json:"copyright,omitempty"`
+}
+
+// cycloneDXIdentity represents a single identity evidence entry.
+type cycloneDXIdentity struct {
+	Field      string  `json:"field,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// cycloneDXOccurrence represents a location where a component was observed.
+type cycloneDXOccurrence struct {
+	Location string `json:"location"`
+}
+
+// cycloneDXCopyright represents a copyright statement in component evidence.
+type cycloneDXCopyright struct {
+	Text string `json:"text"`
+}
+
+// cycloneDXExternalRef represents an external reference in a CycloneDX document.
+type cycloneDXExternalRef struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
 }
 
 // cycloneDXLicense represents a license in a CycloneDX document.
+// Each entry holds either a single license or an SPDX license expression.
 type cycloneDXLicense struct {
-	License *cycloneDXLicenseChoice `json:"license,omitempty"`
+	License    *cycloneDXLicenseChoice `json:"license,omitempty"`
+	Expression string                  `json:"expression,omitempty"`
 }
 
 // cycloneDXLicenseChoice represents the license choice structure.
@@ -73,6 +128,13 @@ type cycloneDXTool struct {
 	Version string `json:"version,omitempty"`
 }
 
+// cycloneDXTools represents the CycloneDX 1.5+ tools object, which replaced
+// the legacy array of tools with separate component and service lists.
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components,omitempty"`
+	Services   []cycloneDXService   `json:"services,omitempty"`
+}
+
 // cycloneDXOrganization represents an organization in a CycloneDX document.
 type cycloneDXOrganization struct {
 	Name string `json:"name,omitempty"`
@@ -102,7 +164,6 @@ func (p *CycloneDXParser) Parse(r io.Reader) (*core.SBOM, error) {
 
 	// Convert to our core SBOM model
 	sbom := &core.SBOM{
-		ID:         doc.SerialNumber,
 		Components: make([]core.Component, 0, len(doc.Components)),
 		Metadata:   make(map[string]string),
 	}
@@ -118,35 +179,249 @@ func (p *CycloneDXParser) Parse(r io.Reader) (*core.SBOM, error) {
 	// Add metadata
 	sbom.Metadata["bomFormat"] = doc.BOMFormat
 	sbom.Metadata["specVersion"] = doc.SpecVersion
+	if doc.SerialNumber != "" {
+		sbom.Metadata["serialNumber"] = doc.SerialNumber
+	}
 	if doc.Metadata != nil && doc.Metadata.Timestamp != "" {
 		sbom.Metadata["timestamp"] = doc.Metadata.Timestamp
 	}
+	if doc.Metadata != nil {
+		if tools := toolNames(doc.Metadata.Tools); len(tools) > 0 {
+			sbom.Metadata["tools"] = strings.Join(tools, ", ")
+		}
+
+		var authors []string
+		for _, author := range doc.Metadata.Authors {
+			if author.Name != "" {
+				authors = append(authors, author.Name)
+			}
+		}
+		if len(authors) > 0 {
+			sbom.Metadata["authors"] = strings.Join(authors, ", ")
+		}
+		if doc.Metadata.Supplier != nil && doc.Metadata.Supplier.Name != "" {
+			sbom.Metadata["supplier"] = doc.Metadata.Supplier.Name
+		}
+
+		// Describe the subject of the SBOM, such as the container image it was generated from
+		if subject := doc.Metadata.Component; subject != nil {
+			if subject.Type != "" {
+				sbom.Metadata["componentType"] = subject.Type
+			}
+			if subject.Version != "" {
+				sbom.Metadata["componentVersion"] = subject.Version
+			}
+			if subject.PURL != "" {
+				sbom.Metadata["componentPurl"] = subject.PURL
+			}
+			for _, prop := range subject.Properties {
+				sbom.Metadata[prop.Name] = prop.Value
+			}
+		}
+		for _, prop := range doc.Metadata.Properties {
+			sbom.Metadata[prop.Name] = prop.Value
+		}
+	}
 
 	// Add properties as metadata
 	for _, prop := range doc.Properties {
 		sbom.Metadata[prop.Name] = prop.Value
 	}
 
-	// Convert components
-	for _, comp := range doc.Components {
+	// Convert components, keeping the assemblies nested components belong to.
+	// Components nested in the subject of the SBOM are top-level components.
+	sbom.Components = appendComponents(sbom.Components, doc.Components, "")
+	if doc.Metadata != nil && doc.Metadata.Component != nil {
+		sbom.Components = appendComponents(sbom.Components, doc.Metadata.Component.Components, "")
+	}
+
+	// Convert services, flattening any nested services
+	sbom.Services = appendServices(nil, doc.Services)
+
+	return sbom, nil
+}
+
+// licenseName returns the identifier, name, or expression of a license entry.
+func licenseName(entry cycloneDXLicense) string {
+	if entry.Expression != "" {
+		return entry.Expression
+	}
+	if entry.License != nil {
+		if entry.License.ID != "" {
+			return entry.License.ID
+		}
+		return entry.License.Name
+	}
+	return ""
+}
+
+// toolNames extracts tool names from either the legacy tools array or the
+// CycloneDX 1.5+ tools object.
+func toolNames(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var names []string
+
+	var legacy []cycloneDXTool
+	if err := json.Unmarshal(raw, &legacy); err == nil {
+		for _, tool := range legacy {
+			if tool.Name != "" {
+				names = append(names, tool.Name)
+			}
+		}
+		return names
+	}
+
+	var tools cycloneDXTools
+	if err := json.Unmarshal(raw, &tools); err == nil {
+		for _, tool := range tools.Components {
+			if tool.Name != "" {
+				names = append(names, tool.Name)
+			}
+		}
+		for _, tool := range tools.Services {
+			if tool.Name != "" {
+				names = append(names, tool.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// convertEvidence converts CycloneDX component evidence into the core model.
+func convertEvidence(evidence *cycloneDXEvidence) *core.Evidence {
+	if evidence == nil {
+		return nil
+	}
+
+	result := &core.Evidence{}
+
+	// Identity is a single object in CycloneDX 1.5 and an array in 1.6
+	var identities []cycloneDXIdentity
+	if len(evidence.Identity) > 0 {
+		if err := json.Unmarshal(evidence.Identity, &identities); err != nil {
+			var identity cycloneDXIdentity
+			if err := json.Unmarshal(evidence.Identity, &identity); err == nil {
+				identities = append(identities, identity)
+			}
+		}
+	}
+	for _, identity := range identities {
+		if identity.Confidence > result.Confidence {
+			result.Confidence = identity.Confidence
+		}
+	}
+
+	for _, occurrence := range evidence.Occurrences {
+		if occurrence.Location != "" {
+			result.Occurrences = append(result.Occurrences, occurrence.Location)
+		}
+	}
+	for _, license := range evidence.Licenses {
+		if name := licenseName(license); name != "" {
+			result.Licenses = append(result.Licenses, name)
+		}
+	}
+	for _, copyright := range evidence.Copyright {
+		if copyright.Text != "" {
+			result.Copyright = append(result.Copyright, copyright.Text)
+		}
+	}
+
+	return result
+}
+
+// convertExternalRefs converts CycloneDX external references into the core model.
+func convertExternalRefs(refs []cycloneDXExternalRef) []core.ExternalReference {
+	var result []core.ExternalReference
+	for _, ref := range refs {
+		if ref.URL == "" {
+			continue
+		}
+		result = append(result, core.ExternalReference{
+			Type:    ref.Type,
+			URL:     ref.URL,
+			Comment: ref.Comment,
+		})
+	}
+	return result
+}
+
+// appendComponents converts CycloneDX components into the core model and
+// appends them to dst, each followed by the components nested in it, which
+// name it as their Parent.
+func appendComponents(dst []core.Component, components []cycloneDXComponent, parent string) []core.Component {
+	for _, comp := range components {
 		component := core.Component{
 			Name:    comp.Name,
 			Version: comp.Version,
 			PURL:    comp.PURL,
+			CPE:     comp.CPE,
+			Type:    comp.Type,
+			Scope:   comp.Scope,
+			Parent:  parent,
 		}
 
-		// Extract license information
-		if len(comp.Licenses) > 0 && comp.Licenses[0].License != nil {
-			license := comp.Licenses[0].License
-			if license.ID != "" {
-				component.License = license.ID
-			} else if license.Name != "" {
-				component.License = license.Name
+		// Hardware and firmware SBOMs may identify components by CPE alone
+		if cpe, ok := component.ParsedCPE(); ok {
+			if component.Name == "" {
+				component.Name = cpe.Product
+			}
+			if component.Version == "" {
+				component.Version = cpe.Version
 			}
 		}
 
-		sbom.Components = append(sbom.Components, component)
+		// Prefer the explicit supplier, falling back to the publisher
+		if comp.Supplier != nil && comp.Supplier.Name != "" {
+			component.Supplier = comp.Supplier.Name
+		} else if comp.Publisher != "" {
+			component.Supplier = comp.Publisher
+		}
+
+		// Extract every declared license; the first is also kept as the primary license
+		for _, entry := range comp.Licenses {
+			if name := licenseName(entry); name != "" {
+				component.Licenses = append(component.Licenses, name)
+			}
+		}
+		if len(component.Licenses) > 0 {
+			component.License = component.Licenses[0]
+		}
+
+		component.Evidence = convertEvidence(comp.Evidence)
+		component.ExternalReferences = convertExternalRefs(comp.ExtRefs)
+
+		dst = append(dst, component)
+		dst = appendComponents(dst, comp.Components, component.Key())
 	}
+	return dst
+}
 
-	return sbom, nil
+// appendServices converts CycloneDX services into the core model and appends
+// them to dst. Nested services are flattened into the same list.
+func appendServices(dst []core.Service, services []cycloneDXService) []core.Service {
+	for _, svc := range services {
+		service := core.Service{
+			Name:                 svc.Name,
+			Version:              svc.Version,
+			Endpoints:            svc.Endpoints,
+			Authenticated:        svc.Authenticated,
+			CrossesTrustBoundary: svc.CrossesTrustBoundary,
+			ExternalReferences:   convertExternalRefs(svc.ExtRefs),
+		}
+		if svc.Group != "" {
+			service.Name = svc.Group + "/" + svc.Name
+		}
+		if svc.Provider != nil {
+			service.Provider = svc.Provider.Name
+		}
+
+		dst = append(dst, service)
+		dst = appendServices(dst, svc.Services)
+	}
+	return dst
 }
@@ -0,0 +1,153 @@
+// Package ingestion provides CycloneDX JSON encoding of stored SBOMs.
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// EncodedSpecVersion is the CycloneDX specification version written by EncodeCycloneDX.
+const EncodedSpecVersion = "1.5"
+
+// encodedMetadataKeys are SBOM metadata entries written to dedicated
+// CycloneDX fields, or describing the source document, rather than written
+// as properties.
+var encodedMetadataKeys = map[string]bool{
+	"bomFormat":        true,
+	"specVersion":      true,
+	"timestamp":        true,
+	"supplier":         true,
+	"tools":            true,
+	"authors":          true,
+	"componentType":    true,
+	"componentVersion": true,
+	"componentPurl":    true,
+}
+
+// EncodeCycloneDX writes an SBOM as a CycloneDX JSON document, so that SBOMs
+// can be handed to tools that consume CycloneDX. Parsing the result yields
+// the same components, services and metadata; evidence is not written.
+// Components nested in assemblies are written inside their assembly.
+func EncodeCycloneDX(w io.Writer, sbom core.SBOM) error {
+	doc := cycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: EncodedSpecVersion,
+		Version:     1,
+		Metadata: &cycloneDXMetadata{
+			Timestamp: sbom.Metadata["timestamp"],
+			Component: &cycloneDXComponent{
+				Type:    sbom.Metadata["componentType"],
+				Name:    sbom.Name,
+				Version: sbom.Metadata["componentVersion"],
+				PURL:    sbom.Metadata["componentPurl"],
+			},
+		},
+		Components: make([]cycloneDXComponent, 0, len(sbom.Components)),
+	}
+
+	if doc.Metadata.Component.Type == "" {
+		doc.Metadata.Component.Type = "application"
+	}
+
+	// CycloneDX requires serial numbers to be UUID URNs
+	if serialNumber := sbom.Metadata["serialNumber"]; strings.HasPrefix(serialNumber, "urn:uuid:") {
+		doc.SerialNumber = serialNumber
+	}
+	if supplier := sbom.Metadata["supplier"]; supplier != "" {
+		doc.Metadata.Supplier = &cycloneDXOrganization{Name: supplier}
+	}
+
+	// Remaining metadata is written as properties, in a stable order
+	keys := make([]string, 0, len(sbom.Metadata))
+	for key := range sbom.Metadata {
+		if !encodedMetadataKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		doc.Properties = append(doc.Properties, cycloneDXProperty{Name: key, Value: sbom.Metadata[key]})
+	}
+
+	for _, node := range core.ComponentTree(sbom.Components) {
+		doc.Components = append(doc.Components, encodeComponent(node))
+	}
+
+	for _, service := range sbom.Services {
+		svc := cycloneDXService{
+			Name:                 service.Name,
+			Version:              service.Version,
+			Endpoints:            service.Endpoints,
+			Authenticated:        service.Authenticated,
+			CrossesTrustBoundary: service.CrossesTrustBoundary,
+			ExtRefs:              encodeExternalRefs(service.ExternalReferences),
+		}
+		if service.Provider != "" {
+			svc.Provider = &cycloneDXOrganization{Name: service.Provider}
+		}
+		doc.Services = append(doc.Services, svc)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode CycloneDX JSON: %w", err)
+	}
+	return nil
+}
+
+// encodeComponent converts a component and the components nested in it
+// into CycloneDX.
+func encodeComponent(node core.ComponentNode) cycloneDXComponent {
+	component := node.Component
+	comp := cycloneDXComponent{
+		Type:    component.Type,
+		Name:    component.Name,
+		Version: component.Version,
+		Scope:   component.Scope,
+		PURL:    component.PURL,
+		CPE:     component.CPE,
+		ExtRefs: encodeExternalRefs(component.ExternalReferences),
+	}
+	if comp.Type == "" {
+		comp.Type = "library"
+	}
+	if component.Supplier != "" {
+		comp.Supplier = &cycloneDXOrganization{Name: component.Supplier}
+	}
+	for _, license := range component.DeclaredLicenses() {
+		comp.Licenses = append(comp.Licenses, encodeLicense(license))
+	}
+	for _, child := range node.Children {
+		comp.Components = append(comp.Components, encodeComponent(child))
+	}
+	return comp
+}
+
+// encodeLicense converts a declared license into a CycloneDX license entry.
+// Compound SPDX expressions are written as expressions, single identifiers
+// as IDs and anything else as a license name.
+func encodeLicense(license string) cycloneDXLicense {
+	switch {
+	case strings.Contains(license, " AND ") || strings.Contains(license, " OR ") || strings.Contains(license, " WITH "):
+		return cycloneDXLicense{Expression: license}
+	case !strings.ContainsAny(license, " \t"):
+		return cycloneDXLicense{License: &cycloneDXLicenseChoice{ID: license}}
+	default:
+		return cycloneDXLicense{License: &cycloneDXLicenseChoice{Name: license}}
+	}
+}
+
+// encodeExternalRefs converts external references into CycloneDX.
+func encodeExternalRefs(refs []core.ExternalReference) []cycloneDXExternalRef {
+	var result []cycloneDXExternalRef
+	for _, ref := range refs {
+		result = append(result, cycloneDXExternalRef{Type: ref.Type, URL: ref.URL, Comment: ref.Comment})
+	}
+	return result
+}
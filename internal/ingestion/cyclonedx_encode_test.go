@@ -0,0 +1,101 @@
+package ingestion
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCycloneDX_RoundTrip(t *testing.T) {
+	authenticated := true
+	sbom := core.SBOM{
+		Name: "payments-api",
+		Components: []core.Component{
+			{
+				Name:     "lodash",
+				Version:  "4.17.21",
+				PURL:     "pkg:npm/lodash@4.17.21",
+				License:  "MIT",
+				Licenses: []string{"MIT", "Apache-2.0 OR GPL-2.0-only", "Custom License"},
+				Supplier: "OpenJS Foundation",
+				Type:     "library",
+				Scope:    "optional",
+				ExternalReferences: []core.ExternalReference{
+					{Type: "vcs", URL: "https://github.com/lodash/lodash"},
+				},
+			},
+		},
+		Services: []core.Service{
+			{Name: "billing", Provider: "Acme", Endpoints: []string{"https://billing.example.com"}, Authenticated: &authenticated},
+		},
+		Metadata: map[string]string{
+			"bomFormat":    "CycloneDX",
+			"specVersion":  "1.4",
+			"serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+			"timestamp":    "2024-01-01T00:00:00Z",
+			"supplier":     "Acme",
+			"team":         "payments",
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeCycloneDX(&buf, sbom))
+
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &raw))
+	assert.Equal(t, EncodedSpecVersion, raw["specVersion"])
+
+	parsed, err := NewCycloneDXParser().Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, sbom.Metadata["serialNumber"], parsed.Metadata["serialNumber"])
+	assert.Equal(t, sbom.Name, parsed.Name)
+	assert.Equal(t, sbom.Components, parsed.Components)
+	assert.Equal(t, sbom.Services, parsed.Services)
+	assert.Equal(t, "payments", parsed.Metadata["team"])
+	assert.Equal(t, "Acme", parsed.Metadata["supplier"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", parsed.Metadata["timestamp"])
+}
+
+func TestEncodeCycloneDX_NestedComponents(t *testing.T) {
+	sbom := core.SBOM{
+		Name: "router",
+		Components: []core.Component{
+			{Name: "router-firmware", Version: "2.1", Type: "firmware"},
+			{Name: "busybox", Version: "1.36.1", PURL: "pkg:generic/busybox@1.36.1", Type: "library", Parent: "router-firmware@2.1"},
+			{Name: "libcrypto", Version: "3.0.13", Type: "library", Parent: "pkg:generic/busybox@1.36.1"},
+			{Name: "zlib", Version: "1.3", Type: "library"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeCycloneDX(&buf, sbom))
+
+	var doc cycloneDXDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Components, 2)
+	require.Len(t, doc.Components[0].Components, 1)
+	assert.Equal(t, "busybox", doc.Components[0].Components[0].Name)
+	require.Len(t, doc.Components[0].Components[0].Components, 1)
+	assert.Equal(t, "libcrypto", doc.Components[0].Components[0].Components[0].Name)
+
+	parsed, err := NewCycloneDXParser().Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, sbom.Components, parsed.Components)
+}
+
+func TestEncodeCycloneDX_NonUUIDID(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeCycloneDX(&buf, core.SBOM{ID: "sbom-123", Name: "app", Components: []core.Component{{Name: "x"}}}))
+
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &raw))
+	assert.NotContains(t, raw, "serialNumber")
+
+	// Untyped components are written as libraries, as the schema requires a type
+	components := raw["components"].([]interface{})
+	assert.Equal(t, "library", components[0].(map[string]interface{})["type"])
+}
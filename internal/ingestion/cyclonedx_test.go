@@ -0,0 +1,226 @@
+package ingestion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycloneDXParser_Parse(t *testing.T) {
+	sbomData := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.4",
+		"serialNumber": "urn:uuid:test-12345",
+		"version": 1,
+		"metadata": {
+			"timestamp": "2024-01-01T00:00:00Z",
+			"tools": [{"vendor": "Acme", "name": "acme-sbom", "version": "1.0.0"}],
+			"component": {"type": "application", "name": "test-app", "version": "1.0.0"}
+		},
+		"components": [
+			{
+				"type": "library",
+				"name": "test-library",
+				"version": "1.0.0",
+				"purl": "pkg:npm/test-library@1.0.0",
+				"licenses": [{"license": {"id": "MIT"}}, {"license": {"name": "Custom License"}}]
+			}
+		]
+	}`
+
+	sbom, err := NewCycloneDXParser().Parse(strings.NewReader(sbomData))
+	require.NoError(t, err)
+
+	assert.Empty(t, sbom.ID, "IDs are assigned when the SBOM is stored")
+	assert.Equal(t, "urn:uuid:test-12345", sbom.Metadata["serialNumber"])
+	assert.Equal(t, "test-app", sbom.Name)
+	assert.Equal(t, "1.4", sbom.Metadata["specVersion"])
+	assert.Equal(t, "acme-sbom", sbom.Metadata["tools"])
+	require.Len(t, sbom.Components, 1)
+	assert.Equal(t, "MIT", sbom.Components[0].License)
+	assert.Equal(t, []string{"MIT", "Custom License"}, sbom.Components[0].Licenses)
+	assert.Empty(t, sbom.Services)
+}
+
+func TestCycloneDXParser_Parse_ContainerSubject(t *testing.T) {
+	sbomData := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"version": 1,
+		"metadata": {
+			"component": {
+				"type": "container",
+				"name": "ghcr.io/acme/payments-api:1.4.0",
+				"version": "sha256:9b2a28eb47540823042a2ba401386845089bb7b62a9637d55816132c4c3c36eb",
+				"purl": "pkg:oci/payments-api@sha256%3A9b2a28eb47540823042a2ba401386845089bb7b62a9637d55816132c4c3c36eb?repository_url=ghcr.io/acme/payments-api",
+				"properties": [{"name": "org.opencontainers.image.base.name", "value": "docker.io/library/alpine:3.19.1"}]
+			},
+			"properties": [{"name": "aquasecurity:trivy:SchemaVersion", "value": "2"}]
+		},
+		"components": []
+	}`
+
+	sbom, err := NewCycloneDXParser().Parse(strings.NewReader(sbomData))
+	require.NoError(t, err)
+
+	assert.Equal(t, "container", sbom.Metadata["componentType"])
+	assert.Equal(t, "sha256:9b2a28eb47540823042a2ba401386845089bb7b62a9637d55816132c4c3c36eb", sbom.Metadata["componentVersion"])
+	assert.Contains(t, sbom.Metadata["componentPurl"], "pkg:oci/payments-api@")
+	assert.Equal(t, "docker.io/library/alpine:3.19.1", sbom.Metadata["org.opencontainers.image.base.name"])
+	assert.Equal(t, "2", sbom.Metadata["aquasecurity:trivy:SchemaVersion"])
+}
+
+func TestCycloneDXParser_Parse_InvalidFormat(t *testing.T) {
+	_, err := NewCycloneDXParser().Parse(strings.NewReader(`{"bomFormat": "SPDX"}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid BOM format")
+}
+
+func TestCycloneDXParser_Parse_CPEOnlyComponent(t *testing.T) {
+	sbomData := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"serialNumber": "urn:uuid:test-cpe",
+		"version": 1,
+		"metadata": {"component": {"type": "firmware", "name": "router-firmware"}},
+		"components": [
+			{"type": "library", "name": "", "cpe": "cpe:2.3:a:openssl:openssl:1.1.1k:*:*:*:*:*:*:*"},
+			{"type": "library", "name": "busybox", "version": "1.36.0", "cpe": "cpe:/a:busybox:busybox:1.35.0"}
+		]
+	}`
+
+	sbom, err := NewCycloneDXParser().Parse(strings.NewReader(sbomData))
+	require.NoError(t, err)
+	require.Len(t, sbom.Components, 2)
+
+	// Missing name and version are taken from the CPE
+	assert.Equal(t, "openssl", sbom.Components[0].Name)
+	assert.Equal(t, "1.1.1k", sbom.Components[0].Version)
+	assert.Equal(t, "cpe:2.3:a:openssl:openssl:1.1.1k:*:*:*:*:*:*:*", sbom.Components[0].CPE)
+
+	// Declared values take precedence
+	assert.Equal(t, "1.36.0", sbom.Components[1].Version)
+}
+
+func TestCycloneDXParser_Parse_NestedComponents(t *testing.T) {
+	sbomData := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"version": 1,
+		"metadata": {"component": {"type": "device", "name": "router", "components": [
+			{"type": "firmware", "name": "bootloader", "version": "1.2"}
+		]}},
+		"components": [
+			{"type": "firmware", "name": "router-firmware", "version": "2.1", "components": [
+				{"type": "library", "name": "busybox", "version": "1.36.1", "purl": "pkg:generic/busybox@1.36.1"},
+				{"type": "library", "name": "openssl", "version": "3.0.13", "components": [
+					{"type": "library", "name": "libcrypto", "version": "3.0.13"}
+				]}
+			]},
+			{"type": "library", "name": "zlib", "version": "1.3"}
+		]
+	}`
+
+	sbom, err := NewCycloneDXParser().Parse(strings.NewReader(sbomData))
+	require.NoError(t, err)
+
+	// Nested components are listed after their assembly, naming it as parent
+	var components [][2]string
+	for _, component := range sbom.Components {
+		components = append(components, [2]string{component.Name, component.Parent})
+	}
+	assert.Equal(t, [][2]string{
+		{"router-firmware", ""},
+		{"busybox", "router-firmware@2.1"},
+		{"openssl", "router-firmware@2.1"},
+		{"libcrypto", "openssl@3.0.13"},
+		{"zlib", ""},
+		{"bootloader", ""},
+	}, components)
+}
+
+func TestCycloneDXParser_Parse_Spec16Features(t *testing.T) {
+	sbomData := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.6",
+		"serialNumber": "urn:uuid:test-16",
+		"version": 1,
+		"metadata": {
+			"tools": {"components": [{"type": "application", "name": "cdxgen"}]}
+		},
+		"components": [
+			{
+				"type": "library",
+				"name": "dual-licensed",
+				"version": "2.0.0",
+				"scope": "optional",
+				"licenses": [{"expression": "MIT OR Apache-2.0"}],
+				"evidence": {
+					"identity": [
+						{"field": "purl", "confidence": 0.8},
+						{"field": "name", "confidence": 1.0}
+					],
+					"occurrences": [{"location": "/app/node_modules/dual-licensed"}],
+					"licenses": [{"license": {"id": "MIT"}}],
+					"copyright": [{"text": "Copyright 2024 Acme"}]
+				},
+				"externalReferences": [
+					{"type": "vcs", "url": "https://github.com/acme/dual-licensed"},
+					{"type": "website", "url": ""}
+				]
+			},
+			{
+				"type": "library",
+				"name": "legacy-evidence",
+				"version": "1.0.0",
+				"evidence": {"identity": {"field": "purl", "confidence": 0.5}}
+			}
+		],
+		"services": [
+			{
+				"name": "payments-api",
+				"version": "v2",
+				"provider": {"name": "Acme Payments"},
+				"endpoints": ["https://api.acme.example/v2"],
+				"authenticated": true,
+				"x-trust-boundary": true,
+				"services": [{"name": "refunds-api"}]
+			}
+		]
+	}`
+
+	sbom, err := NewCycloneDXParser().Parse(strings.NewReader(sbomData))
+	require.NoError(t, err)
+
+	assert.Equal(t, "cdxgen", sbom.Metadata["tools"])
+
+	require.Len(t, sbom.Components, 2)
+	component := sbom.Components[0]
+	assert.Equal(t, "MIT OR Apache-2.0", component.License)
+	assert.Equal(t, "library", component.Type)
+	assert.Equal(t, core.ScopeOptional, component.Scope)
+	assert.Equal(t, core.ScopeRequired, sbom.Components[1].EffectiveScope())
+	require.NotNil(t, component.Evidence)
+	assert.Equal(t, 1.0, component.Evidence.Confidence)
+	assert.Equal(t, []string{"/app/node_modules/dual-licensed"}, component.Evidence.Occurrences)
+	assert.Equal(t, []string{"MIT"}, component.Evidence.Licenses)
+	assert.Equal(t, []string{"Copyright 2024 Acme"}, component.Evidence.Copyright)
+	require.Len(t, component.ExternalReferences, 1)
+	assert.Equal(t, "vcs", component.ExternalReferences[0].Type)
+
+	require.NotNil(t, sbom.Components[1].Evidence)
+	assert.Equal(t, 0.5, sbom.Components[1].Evidence.Confidence)
+
+	require.Len(t, sbom.Services, 2)
+	service := sbom.Services[0]
+	assert.Equal(t, "payments-api", service.Name)
+	assert.Equal(t, "Acme Payments", service.Provider)
+	assert.Equal(t, []string{"https://api.acme.example/v2"}, service.Endpoints)
+	require.NotNil(t, service.Authenticated)
+	assert.True(t, *service.Authenticated)
+	assert.True(t, service.CrossesTrustBoundary)
+	assert.Equal(t, "refunds-api", sbom.Services[1].Name)
+}
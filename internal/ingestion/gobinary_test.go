@@ -0,0 +1,105 @@
+package ingestion
+
+import (
+	"bytes"
+	"os"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoBinaryParser_Parse(t *testing.T) {
+	// The running test binary is itself a Go binary with embedded build info
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	file, err := os.Open(exe)
+	require.NoError(t, err)
+	defer file.Close()
+
+	sbom, err := NewGoBinaryParser().Parse(file)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(sbom.ID, "urn:sha256:"))
+	assert.NotEmpty(t, sbom.Name)
+	assert.Equal(t, "GoBuildInfo", sbom.Metadata["bomFormat"])
+	assert.True(t, strings.HasPrefix(sbom.Metadata["goVersion"], "go"))
+
+	// testify is a dependency of this package's tests and must be listed
+	found := false
+	for _, component := range sbom.Components {
+		assert.True(t, strings.HasPrefix(component.PURL, "pkg:golang/"))
+		if component.Name == "github.com/stretchr/testify" {
+			found = true
+			assert.NotEmpty(t, component.Version)
+			assert.Equal(t, "pkg:golang/github.com/stretchr/testify@"+component.Version, component.PURL)
+			assert.Equal(t, "https://github.com/stretchr/testify", component.RepositoryURL())
+		}
+	}
+	assert.True(t, found, "expected testify module in components")
+}
+
+func TestGoModuleToComponent_Replace(t *testing.T) {
+	// A replacement by another module is what was compiled in
+	component := goModuleToComponent(&debug.Module{
+		Path:    "github.com/acme/lib",
+		Version: "v1.2.0",
+		Replace: &debug.Module{Path: "github.com/fork/lib", Version: "v1.2.1"},
+	})
+	assert.Equal(t, "github.com/fork/lib", component.Name)
+	assert.Equal(t, "pkg:golang/github.com/fork/lib@v1.2.1", component.PURL)
+
+	// A local directory has no module path or version to report
+	for _, dir := range []string{"../lib", "./vendor/lib", "/src/lib", `C:\src\lib`} {
+		component := goModuleToComponent(&debug.Module{
+			Path:    "github.com/acme/lib",
+			Version: "v1.2.0",
+			Replace: &debug.Module{Path: dir},
+		})
+		assert.Equal(t, "github.com/acme/lib", component.Name, dir)
+		assert.Equal(t, "v1.2.0", component.Version, dir)
+		assert.Equal(t, "pkg:golang/github.com/acme/lib@v1.2.0", component.PURL, dir)
+	}
+}
+
+func TestGoBinaryParser_Parse_NotABinary(t *testing.T) {
+	_, err := NewGoBinaryParser().Parse(bytes.NewReader([]byte(`{"bomFormat":"CycloneDX"}`)))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read Go build info")
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []byte
+		expected string
+	}{
+		{"ELF binary", []byte("\x7fELF"), FormatGoBinary},
+		{"PE binary", []byte("MZ\x90\x00"), FormatGoBinary},
+		{"Mach-O 64-bit binary", []byte{0xcf, 0xfa, 0xed, 0xfe}, FormatGoBinary},
+		{"JSON document", []byte(`{"bo`), FormatCycloneDX},
+		{"Empty header", []byte{}, FormatCycloneDX},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectFormat(tt.header))
+		})
+	}
+}
+
+func TestNewParser(t *testing.T) {
+	parser, err := NewParser(FormatCycloneDX)
+	assert.NoError(t, err)
+	assert.IsType(t, &CycloneDXParser{}, parser)
+
+	parser, err = NewParser(FormatGoBinary)
+	assert.NoError(t, err)
+	assert.IsType(t, &GoBinaryParser{}, parser)
+
+	_, err = NewParser("spdx")
+	assert.Error(t, err)
+}
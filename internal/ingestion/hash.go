@@ -0,0 +1,34 @@
+// Package ingestion provides content hashing of SBOM documents for
+// detecting duplicate submissions.
+package ingestion
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ContentHash returns the hex-encoded SHA-256 hash of the canonical form of
+// a JSON document. The canonical form has sorted object keys and no
+// insignificant whitespace, so documents that differ only in formatting or
+// key order have the same hash. Numbers are kept as written.
+func ContentHash(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	// encoding/json writes map keys in sorted order
+	canonical, err := json.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize document: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,27 @@
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentHash(t *testing.T) {
+	hash, err := ContentHash([]byte(`{"bomFormat": "CycloneDX", "version": 1, "components": [{"name": "lodash", "version": "4.17.21"}]}`))
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	// Formatting and key order do not change the hash
+	reformatted, err := ContentHash([]byte("{\n  \"components\": [\n    {\"version\": \"4.17.21\", \"name\": \"lodash\"}\n  ],\n  \"version\": 1,\n  \"bomFormat\": \"CycloneDX\"\n}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, hash, reformatted)
+
+	// Content and component order do
+	changed, err := ContentHash([]byte(`{"bomFormat": "CycloneDX", "version": 2, "components": [{"name": "lodash", "version": "4.17.21"}]}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+
+	_, err = ContentHash([]byte(`{"bomFormat": `))
+	assert.ErrorContains(t, err, "invalid JSON")
+}
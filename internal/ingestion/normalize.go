@@ -0,0 +1,198 @@
+// Package ingestion provides post-processing of parsed SBOMs prior to storage.
+package ingestion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	packageurl "github.com/hueyexe/SBOM-Sentinel/internal/purl"
+)
+
+// NormalizationReport describes the changes made by the Normalizer.
+type NormalizationReport struct {
+	PURLsNormalized       int      `json:"purls_normalized"`
+	LicensesCanonicalized int      `json:"licenses_canonicalized"`
+	DuplicatesRemoved     int      `json:"duplicates_removed"`
+	Changes               []string `json:"changes,omitempty"`
+
+	// InvalidPURLs counts PURLs removed because they could not be parsed
+	InvalidPURLs int `json:"invalid_purls"`
+}
+
+// HasChanges reports whether normalization modified the SBOM.
+func (r NormalizationReport) HasChanges() bool {
+	return r.PURLsNormalized > 0 || r.LicensesCanonicalized > 0 || r.DuplicatesRemoved > 0 || r.InvalidPURLs > 0
+}
+
+// Normalizer canonicalizes component data so that equivalent components
+// compare equal regardless of how the producing tool formatted them.
+type Normalizer struct {
+	licenseAliases map[string]string
+}
+
+// NewNormalizer creates a new Normalizer with the default license alias table.
+func NewNormalizer() *Normalizer {
+	// Keys are lower-cased; values are canonical SPDX identifiers
+	licenseAliases := map[string]string{
+		"mit":                         "MIT",
+		"mit license":                 "MIT",
+		"the mit license":             "MIT",
+		"isc":                         "ISC",
+		"isc license":                 "ISC",
+		"apache-2.0":                  "Apache-2.0",
+		"apache 2.0":                  "Apache-2.0",
+		"apache-2":                    "Apache-2.0",
+		"apache 2":                    "Apache-2.0",
+		"apache license 2.0":          "Apache-2.0",
+		"apache license, version 2.0": "Apache-2.0",
+		"the apache software license, version 2.0": "Apache-2.0",
+		"bsd-2-clause":               "BSD-2-Clause",
+		"bsd-3-clause":               "BSD-3-Clause",
+		"new bsd license":            "BSD-3-Clause",
+		"simplified bsd license":     "BSD-2-Clause",
+		"0bsd":                       "0BSD",
+		"unlicense":                  "Unlicense",
+		"cc0-1.0":                    "CC0-1.0",
+		"mpl-2.0":                    "MPL-2.0",
+		"mozilla public license 2.0": "MPL-2.0",
+		"epl-1.0":                    "EPL-1.0",
+		"epl-2.0":                    "EPL-2.0",
+		"gpl-2.0":                    "GPL-2.0-only",
+		"gpl-2.0+":                   "GPL-2.0-or-later",
+		"gplv2":                      "GPL-2.0-only",
+		"gpl-2.0-only":               "GPL-2.0-only",
+		"gpl-2.0-or-later":           "GPL-2.0-or-later",
+		"gpl-3.0":                    "GPL-3.0-only",
+		"gpl-3.0+":                   "GPL-3.0-or-later",
+		"gplv3":                      "GPL-3.0-only",
+		"gpl-3.0-only":               "GPL-3.0-only",
+		"gpl-3.0-or-later":           "GPL-3.0-or-later",
+		"lgpl-2.1":                   "LGPL-2.1-only",
+		"lgpl-2.1+":                  "LGPL-2.1-or-later",
+		"lgpl-2.1-only":              "LGPL-2.1-only",
+		"lgpl-2.1-or-later":          "LGPL-2.1-or-later",
+		"lgpl-3.0":                   "LGPL-3.0-only",
+		"lgpl-3.0+":                  "LGPL-3.0-or-later",
+		"lgpl-3.0-only":              "LGPL-3.0-only",
+		"lgpl-3.0-or-later":          "LGPL-3.0-or-later",
+		"agpl-3.0":                   "AGPL-3.0-only",
+		"agpl-3.0+":                  "AGPL-3.0-or-later",
+		"agplv3":                     "AGPL-3.0-only",
+		"agpl-3.0-only":              "AGPL-3.0-only",
+		"agpl-3.0-or-later":          "AGPL-3.0-or-later",
+	}
+
+	return &Normalizer{
+		licenseAliases: licenseAliases,
+	}
+}
+
+// Normalize canonicalizes PURLs and license identifiers in place. It removes
+// duplicate components. It also removes PURLs that are not valid Package
+// URLs, so that agents never match a component by a malformed identifier.
+// Nested components keep naming their assembly when its PURL changes. The
+// returned report describes every change made.
+func (n *Normalizer) Normalize(sbom *core.SBOM) NormalizationReport {
+	var report NormalizationReport
+
+	deduped := make([]core.Component, 0, len(sbom.Components))
+	seen := make(map[string]int)
+	// renamed maps the keys of components whose PURL changed to their new keys
+	renamed := make(map[string]string)
+
+	for _, component := range sbom.Components {
+		original := component.Key()
+		if component.PURL != "" {
+			if _, err := packageurl.Parse(component.PURL); err != nil {
+				report.InvalidPURLs++
+				report.Changes = append(report.Changes, fmt.Sprintf("PURL '%s' of component '%s' is invalid and was removed: %v", component.PURL, component.Name, err))
+				component.PURL = ""
+			} else if normalized := NormalizePURL(component.PURL); normalized != component.PURL {
+				report.PURLsNormalized++
+				report.Changes = append(report.Changes, fmt.Sprintf("PURL '%s' normalized to '%s'", component.PURL, normalized))
+				component.PURL = normalized
+			}
+			if component.Key() != original {
+				renamed[original] = component.Key()
+			}
+		}
+
+		if len(component.Licenses) > 0 {
+			// Copy so the caller's slice is not modified through the shared backing array
+			licenses := make([]string, len(component.Licenses))
+			for i, license := range component.Licenses {
+				licenses[i] = n.canonicalizeLicense(&report, component.Name, license)
+			}
+			component.Licenses = licenses
+			component.License = licenses[0]
+		} else if component.License != "" {
+			component.License = n.canonicalizeLicense(&report, component.Name, component.License)
+		}
+
+		key := componentKey(component)
+		if idx, exists := seen[key]; exists {
+			// Keep the first occurrence but fill in anything it was missing
+			if deduped[idx].License == "" {
+				deduped[idx].License = component.License
+				deduped[idx].Licenses = component.Licenses
+			}
+			report.DuplicatesRemoved++
+			report.Changes = append(report.Changes, fmt.Sprintf("Duplicate component '%s' (v%s) removed", component.Name, component.Version))
+			continue
+		}
+
+		seen[key] = len(deduped)
+		deduped = append(deduped, component)
+	}
+
+	for i := range deduped {
+		if parent, exists := renamed[deduped[i].Parent]; exists {
+			deduped[i].Parent = parent
+		}
+	}
+
+	sbom.Components = deduped
+	return report
+}
+
+// CanonicalLicense maps a license identifier to its canonical SPDX form.
+// Unknown identifiers are returned trimmed but otherwise unchanged.
+func (n *Normalizer) CanonicalLicense(license string) string {
+	trimmed := strings.TrimSpace(license)
+	if canonical, exists := n.licenseAliases[strings.ToLower(trimmed)]; exists {
+		return canonical
+	}
+	return trimmed
+}
+
+// canonicalizeLicense canonicalizes a single license and records the change in the report.
+func (n *Normalizer) canonicalizeLicense(report *NormalizationReport, componentName, license string) string {
+	canonical := n.CanonicalLicense(license)
+	if canonical != license {
+		report.LicensesCanonicalized++
+		report.Changes = append(report.Changes, fmt.Sprintf("License '%s' of component '%s' canonicalized to '%s'", license, componentName, canonical))
+	}
+	return canonical
+}
+
+// componentKey returns the identity used to detect duplicate components.
+// Components are identified by PURL when present, otherwise by name and version.
+func componentKey(component core.Component) string {
+	if component.PURL != "" {
+		return "purl:" + component.PURL
+	}
+	return "name:" + strings.ToLower(component.Name) + "@" + component.Version
+}
+
+// NormalizePURL returns a canonical form of a Package URL.
+// The type is lower-cased, names are lower-cased for case-insensitive
+// ecosystems, and qualifiers are sorted with empty values dropped.
+// Strings that are not valid PURLs are returned unchanged.
+func NormalizePURL(purl string) string {
+	parsed, err := packageurl.Parse(purl)
+	if err != nil {
+		return purl
+	}
+	return parsed.String()
+}
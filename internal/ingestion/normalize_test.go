@@ -0,0 +1,132 @@
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		purl     string
+		expected string
+	}{
+		{"Already canonical", "pkg:npm/lodash@4.17.21", "pkg:npm/lodash@4.17.21"},
+		{"Upper-case type", "pkg:NPM/lodash@4.17.21", "pkg:npm/lodash@4.17.21"},
+		{"Case-insensitive npm name", "pkg:npm/@Babel/Core@7.0.0", "pkg:npm/@babel/core@7.0.0"},
+		{"PyPI name folding", "pkg:pypi/Django_Rest@3.0.0", "pkg:pypi/django-rest@3.0.0"},
+		{"Case-sensitive Maven name kept", "pkg:maven/org.Apache/Commons@1.0", "pkg:maven/org.Apache/Commons@1.0"},
+		{"Version case kept", "pkg:npm/foo@1.0.0-RC1", "pkg:npm/foo@1.0.0-RC1"},
+		{"Qualifiers sorted", "pkg:deb/debian/curl@7.50?distro=jessie&arch=i386", "pkg:deb/debian/curl@7.50?arch=i386&distro=jessie"},
+		{"Empty qualifier dropped", "pkg:deb/debian/curl@7.50?arch=&distro=jessie", "pkg:deb/debian/curl@7.50?distro=jessie"},
+		{"Subpath kept", "pkg:GOLANG/github.com/foo/bar@v1.0.0#cmd/baz", "pkg:golang/github.com/foo/bar@v1.0.0#cmd/baz"},
+		{"Not a PURL", "lodash", "lodash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizePURL(tt.purl))
+		})
+	}
+}
+
+func TestNormalizer_CanonicalLicense(t *testing.T) {
+	normalizer := NewNormalizer()
+
+	assert.Equal(t, "Apache-2.0", normalizer.CanonicalLicense("Apache License, Version 2.0"))
+	assert.Equal(t, "MIT", normalizer.CanonicalLicense(" mit "))
+	assert.Equal(t, "GPL-3.0-only", normalizer.CanonicalLicense("GPL-3.0"))
+	assert.Equal(t, "Custom-License", normalizer.CanonicalLicense("Custom-License"))
+}
+
+func TestNormalizer_Normalize(t *testing.T) {
+	sbom := &core.SBOM{
+		Components: []core.Component{
+			{Name: "lodash", Version: "4.17.21", PURL: "pkg:NPM/lodash@4.17.21"},
+			{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", License: "MIT License"},
+			{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2", License: "MIT"},
+			{Name: "no-purl", Version: "1.0.0", License: "Apache 2.0"},
+			{Name: "No-Purl", Version: "1.0.0"},
+		},
+	}
+
+	report := NewNormalizer().Normalize(sbom)
+
+	assert.True(t, report.HasChanges())
+	assert.Equal(t, 1, report.PURLsNormalized)
+	assert.Equal(t, 2, report.LicensesCanonicalized)
+	assert.Equal(t, 2, report.DuplicatesRemoved)
+	assert.Len(t, report.Changes, 5)
+
+	assert.Len(t, sbom.Components, 3)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", sbom.Components[0].PURL)
+	assert.Equal(t, "MIT", sbom.Components[0].License, "license should be merged from the duplicate")
+	assert.Equal(t, "Apache-2.0", sbom.Components[2].License)
+}
+
+func TestNormalizer_Normalize_MultipleLicenses(t *testing.T) {
+	licenses := []string{"Apache 2.0", "GPLv3"}
+	sbom := &core.SBOM{
+		Components: []core.Component{
+			{Name: "dual", Version: "1.0.0", License: "Apache 2.0", Licenses: licenses},
+		},
+	}
+
+	report := NewNormalizer().Normalize(sbom)
+
+	assert.Equal(t, 2, report.LicensesCanonicalized)
+	assert.Equal(t, []string{"Apache-2.0", "GPL-3.0-only"}, sbom.Components[0].Licenses)
+	assert.Equal(t, "Apache-2.0", sbom.Components[0].License)
+	assert.Equal(t, []string{"Apache 2.0", "GPLv3"}, licenses, "input slice should not be modified")
+}
+
+func TestNormalizer_Normalize_NoChanges(t *testing.T) {
+	sbom := &core.SBOM{
+		Components: []core.Component{
+			{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2", License: "MIT"},
+		},
+	}
+
+	report := NewNormalizer().Normalize(sbom)
+
+	assert.False(t, report.HasChanges())
+	assert.Empty(t, report.Changes)
+	assert.Len(t, sbom.Components, 1)
+}
+
+func TestNormalizer_Normalize_InvalidPURL(t *testing.T) {
+	sbom := &core.SBOM{
+		Components: []core.Component{
+			{Name: "commons-lang", Version: "2.6", PURL: "pkg:maven/commons-lang@2.6"},
+			{Name: "lodash", Version: "4.17.21", PURL: "lodash@4.17.21"},
+		},
+	}
+
+	report := NewNormalizer().Normalize(sbom)
+
+	assert.True(t, report.HasChanges())
+	assert.Equal(t, 2, report.InvalidPURLs)
+	assert.Contains(t, report.Changes[0], "PURL 'pkg:maven/commons-lang@2.6' of component 'commons-lang' is invalid and was removed")
+	assert.Empty(t, sbom.Components[0].PURL)
+	assert.Empty(t, sbom.Components[1].PURL)
+}
+
+func TestNormalizer_Normalize_NestedComponents(t *testing.T) {
+	sbom := &core.SBOM{
+		Components: []core.Component{
+			{Name: "router-firmware", Version: "2.1", PURL: "pkg:GENERIC/router-firmware@2.1"},
+			{Name: "busybox", Version: "1.36.1", PURL: "busybox", Parent: "pkg:GENERIC/router-firmware@2.1"},
+			{Name: "libcrypto", Version: "3.0.13", Parent: "busybox"},
+		},
+	}
+
+	NewNormalizer().Normalize(sbom)
+
+	// Nested components follow the changed PURLs of their assemblies
+	assert.Equal(t, "pkg:generic/router-firmware@2.1", sbom.Components[0].PURL)
+	assert.Equal(t, "pkg:generic/router-firmware@2.1", sbom.Components[1].Parent)
+	assert.Empty(t, sbom.Components[1].PURL)
+	assert.Equal(t, "busybox@1.36.1", sbom.Components[2].Parent)
+}
@@ -3,6 +3,8 @@
 package ingestion
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
@@ -16,3 +18,45 @@ type Parser interface {
 	// Returns an error if the document cannot be parsed or is invalid.
 	Parse(r io.Reader) (*core.SBOM, error)
 }
+
+// Supported SBOM source formats.
+const (
+	FormatAuto      = "auto"
+	FormatCycloneDX = "cyclonedx"
+	FormatGoBinary  = "gobinary"
+)
+
+// NewParser returns the Parser for the given format name.
+// Returns an error if the format is unknown.
+func NewParser(format string) (Parser, error) {
+	switch format {
+	case FormatCycloneDX:
+		return NewCycloneDXParser(), nil
+	case FormatGoBinary:
+		return NewGoBinaryParser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format '%s'", format)
+	}
+}
+
+// DetectFormat inspects the leading bytes of a document and returns the
+// format it most likely uses. Executable headers (ELF, PE, Mach-O) are
+// treated as Go binaries; everything else is assumed to be CycloneDX JSON.
+func DetectFormat(header []byte) string {
+	executableMagics := [][]byte{
+		[]byte("\x7fELF"),        // ELF
+		[]byte("MZ"),             // PE
+		{0xfe, 0xed, 0xfa, 0xce}, // Mach-O 32-bit
+		{0xfe, 0xed, 0xfa, 0xcf}, // Mach-O 64-bit
+		{0xce, 0xfa, 0xed, 0xfe}, // Mach-O 32-bit, little endian
+		{0xcf, 0xfa, 0xed, 0xfe}, // Mach-O 64-bit, little endian
+	}
+
+	for _, magic := range executableMagics {
+		if bytes.HasPrefix(header, magic) {
+			return FormatGoBinary
+		}
+	}
+
+	return FormatCycloneDX
+}
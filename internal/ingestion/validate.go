@@ -0,0 +1,434 @@
+// Package ingestion provides structural validation of CycloneDX JSON
+// documents, reporting every violation of the CycloneDX schema's types,
+// required fields and enumerations by its JSON Pointer path.
+package ingestion
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation is a single way in which a document does not follow its schema.
+type Violation struct {
+	// Path is the JSON Pointer of the offending value, e.g. "/components/3/type"
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String returns the violation as "path: message".
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + v.Message
+}
+
+// cycloneDXSpecVersions are the CycloneDX specification versions accepted.
+var cycloneDXSpecVersions = []string{"1.0", "1.1", "1.2", "1.3", "1.4", "1.5", "1.6"}
+
+// cycloneDXComponentTypes are the values of a component's type.
+var cycloneDXComponentTypes = []string{
+	"application", "framework", "library", "container", "platform", "operating-system",
+	"device", "device-driver", "firmware", "file", "machine-learning-model", "data",
+	"cryptographic-asset",
+}
+
+// cycloneDXScopes are the values of a component's scope.
+var cycloneDXScopes = []string{"required", "optional", "excluded"}
+
+// cycloneDXHashAlgorithms are the values of a hash's alg.
+var cycloneDXHashAlgorithms = []string{
+	"MD5", "SHA-1", "SHA-256", "SHA-384", "SHA-512", "SHA3-256", "SHA3-384", "SHA3-512",
+	"BLAKE2b-256", "BLAKE2b-384", "BLAKE2b-512", "BLAKE3",
+}
+
+// ValidateCycloneDX checks a CycloneDX JSON document against the structure
+// of the CycloneDX schema that ingestion relies on: the BOM format and
+// specification version, and the types, required fields and enumerated
+// values of metadata, components (including nested and tool components),
+// licenses, hashes, properties, external references, services and
+// dependencies. Unknown fields are allowed. It returns every violation
+// found, in document order, or an error if the document is not JSON.
+func ValidateCycloneDX(data []byte) ([]Violation, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("invalid JSON at offset %d: %w", syntaxErr.Offset, err)
+		}
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	v := &cycloneDXValidator{refs: make(map[string]string)}
+	v.document(doc)
+	return v.violations, nil
+}
+
+// cycloneDXValidator accumulates the violations of a decoded document.
+type cycloneDXValidator struct {
+	violations []Violation
+	// refs maps each bom-ref to the path that declared it first
+	refs map[string]string
+}
+
+func (v *cycloneDXValidator) report(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *cycloneDXValidator) document(doc interface{}) {
+	root, ok := v.object("", doc)
+	if !ok {
+		return
+	}
+
+	if format, ok := v.requiredString(root, "", "bomFormat"); ok && format != "CycloneDX" {
+		v.report("/bomFormat", "must be \"CycloneDX\", got %q", format)
+	}
+	if spec, ok := v.requiredString(root, "", "specVersion"); ok {
+		v.enum("/specVersion", spec, cycloneDXSpecVersions)
+	}
+	v.optionalString(root, "", "serialNumber")
+	if version, ok := root["version"]; ok {
+		if n, isInt := integer(version); !isInt || n < 1 {
+			v.report("/version", "must be an integer of at least 1")
+		}
+	}
+
+	if metadata, ok := root["metadata"]; ok {
+		v.metadata("/metadata", metadata)
+	}
+	v.each(root, "", "components", v.component)
+	v.each(root, "", "services", v.service)
+	v.each(root, "", "externalReferences", v.externalReference)
+	v.each(root, "", "properties", v.property)
+	v.each(root, "", "dependencies", v.dependency)
+}
+
+func (v *cycloneDXValidator) metadata(path string, value interface{}) {
+	metadata, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	if timestamp, ok := v.optionalString(metadata, path, "timestamp"); ok {
+		if _, err := time.Parse(time.RFC3339, timestamp); err != nil {
+			v.report(path+"/timestamp", "must be an RFC 3339 date-time, got %q", timestamp)
+		}
+	}
+
+	// Tools are a legacy array of tools, or components and services since 1.5
+	if tools, ok := metadata["tools"]; ok {
+		switch tools := tools.(type) {
+		case []interface{}:
+			for i, tool := range tools {
+				v.object(path+"/tools/"+strconv.Itoa(i), tool)
+			}
+		case map[string]interface{}:
+			v.each(tools, path+"/tools", "components", v.component)
+			v.each(tools, path+"/tools", "services", v.service)
+		default:
+			v.report(path+"/tools", "must be an array or an object")
+		}
+	}
+
+	v.each(metadata, path, "authors", v.organization)
+	if component, ok := metadata["component"]; ok {
+		v.component(path+"/component", component)
+	}
+	for _, field := range []string{"supplier", "manufacture"} {
+		if organization, ok := metadata[field]; ok {
+			v.organization(path+"/"+field, organization)
+		}
+	}
+	v.each(metadata, path, "properties", v.property)
+}
+
+func (v *cycloneDXValidator) component(path string, value interface{}) {
+	component, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	if componentType, ok := v.requiredString(component, path, "type"); ok {
+		v.enum(path+"/type", componentType, cycloneDXComponentTypes)
+	}
+	// Hardware and firmware components may be named by their CPE alone
+	if _, hasCPE := component["cpe"]; hasCPE {
+		v.optionalString(component, path, "name")
+	} else if name, ok := v.requiredString(component, path, "name"); ok && strings.TrimSpace(name) == "" {
+		v.report(path+"/name", "must not be empty")
+	}
+	for _, field := range []string{"version", "group", "publisher", "author", "purl", "cpe", "description"} {
+		v.optionalString(component, path, field)
+	}
+	if scope, ok := v.optionalString(component, path, "scope"); ok {
+		v.enum(path+"/scope", scope, cycloneDXScopes)
+	}
+	v.bomRef(component, path)
+
+	if supplier, ok := component["supplier"]; ok {
+		v.organization(path+"/supplier", supplier)
+	}
+	v.each(component, path, "licenses", v.license)
+	v.each(component, path, "hashes", v.hash)
+	v.each(component, path, "externalReferences", v.externalReference)
+	v.each(component, path, "properties", v.property)
+	v.each(component, path, "components", v.component)
+}
+
+func (v *cycloneDXValidator) service(path string, value interface{}) {
+	service, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	if name, ok := v.requiredString(service, path, "name"); ok && strings.TrimSpace(name) == "" {
+		v.report(path+"/name", "must not be empty")
+	}
+	v.optionalString(service, path, "version")
+	v.bomRef(service, path)
+	if endpoints, ok := service["endpoints"]; ok {
+		if items, ok := v.array(path+"/endpoints", endpoints); ok {
+			for i, endpoint := range items {
+				v.string(path+"/endpoints/"+strconv.Itoa(i), endpoint)
+			}
+		}
+	}
+	if authenticated, ok := service["authenticated"]; ok {
+		if _, isBool := authenticated.(bool); !isBool {
+			v.report(path+"/authenticated", "must be a boolean")
+		}
+	}
+	v.each(service, path, "externalReferences", v.externalReference)
+	v.each(service, path, "properties", v.property)
+	v.each(service, path, "services", v.service)
+}
+
+// license checks a license choice: exactly one of a license or an SPDX
+// expression, where a license is identified by id or name.
+func (v *cycloneDXValidator) license(path string, value interface{}) {
+	entry, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	license, hasLicense := entry["license"]
+	_, hasExpression := entry["expression"]
+	switch {
+	case hasLicense && hasExpression:
+		v.report(path, "must have either a license or an expression, not both")
+	case hasExpression:
+		v.optionalString(entry, path, "expression")
+	case hasLicense:
+		choice, ok := v.object(path+"/license", license)
+		if !ok {
+			return
+		}
+		_, hasID := choice["id"]
+		_, hasName := choice["name"]
+		switch {
+		case hasID && hasName:
+			v.report(path+"/license", "must have either an id or a name, not both")
+		case !hasID && !hasName:
+			v.report(path+"/license", "must have an id or a name")
+		}
+		v.optionalString(choice, path+"/license", "id")
+		v.optionalString(choice, path+"/license", "name")
+		v.optionalString(choice, path+"/license", "url")
+	default:
+		v.report(path, "must have a license or an expression")
+	}
+}
+
+func (v *cycloneDXValidator) hash(path string, value interface{}) {
+	hash, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	if alg, ok := v.requiredString(hash, path, "alg"); ok {
+		v.enum(path+"/alg", alg, cycloneDXHashAlgorithms)
+	}
+	if content, ok := v.requiredString(hash, path, "content"); ok && !isHex(content) {
+		v.report(path+"/content", "must be a hexadecimal digest")
+	}
+}
+
+func (v *cycloneDXValidator) externalReference(path string, value interface{}) {
+	ref, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	v.requiredString(ref, path, "type")
+	v.requiredString(ref, path, "url")
+	v.optionalString(ref, path, "comment")
+}
+
+func (v *cycloneDXValidator) property(path string, value interface{}) {
+	property, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	v.requiredString(property, path, "name")
+	v.optionalString(property, path, "value")
+}
+
+func (v *cycloneDXValidator) organization(path string, value interface{}) {
+	organization, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	v.optionalString(organization, path, "name")
+}
+
+func (v *cycloneDXValidator) dependency(path string, value interface{}) {
+	dependency, ok := v.object(path, value)
+	if !ok {
+		return
+	}
+
+	v.requiredString(dependency, path, "ref")
+	if dependsOn, ok := dependency["dependsOn"]; ok {
+		if items, ok := v.array(path+"/dependsOn", dependsOn); ok {
+			for i, ref := range items {
+				v.string(path+"/dependsOn/"+strconv.Itoa(i), ref)
+			}
+		}
+	}
+}
+
+// bomRef checks that an element's bom-ref, if any, is unique within the document.
+func (v *cycloneDXValidator) bomRef(element map[string]interface{}, path string) {
+	ref, ok := v.optionalString(element, path, "bom-ref")
+	if !ok {
+		return
+	}
+	if first, duplicate := v.refs[ref]; duplicate {
+		v.report(path+"/bom-ref", "duplicates the bom-ref %q of %s", ref, first)
+		return
+	}
+	v.refs[ref] = path
+}
+
+// each checks every element of the array in field of parent, if present, with check.
+func (v *cycloneDXValidator) each(parent map[string]interface{}, path, field string, check func(string, interface{})) {
+	value, ok := parent[field]
+	if !ok {
+		return
+	}
+	items, ok := v.array(path+"/"+field, value)
+	if !ok {
+		return
+	}
+	for i, item := range items {
+		check(path+"/"+field+"/"+strconv.Itoa(i), item)
+	}
+}
+
+func (v *cycloneDXValidator) object(path string, value interface{}) (map[string]interface{}, bool) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		v.report(path, "must be an object, got %s", jsonType(value))
+	}
+	return object, ok
+}
+
+func (v *cycloneDXValidator) array(path string, value interface{}) ([]interface{}, bool) {
+	array, ok := value.([]interface{})
+	if !ok {
+		v.report(path, "must be an array, got %s", jsonType(value))
+	}
+	return array, ok
+}
+
+func (v *cycloneDXValidator) string(path string, value interface{}) (string, bool) {
+	s, ok := value.(string)
+	if !ok {
+		v.report(path, "must be a string, got %s", jsonType(value))
+	}
+	return s, ok
+}
+
+// requiredString returns the string in field of parent, reporting it if it
+// is missing or not a string.
+func (v *cycloneDXValidator) requiredString(parent map[string]interface{}, path, field string) (string, bool) {
+	value, ok := parent[field]
+	if !ok {
+		v.report(path, "missing required property %q", field)
+		return "", false
+	}
+	return v.string(path+"/"+field, value)
+}
+
+// optionalString returns the string in field of parent, if present,
+// reporting it if it is not a string.
+func (v *cycloneDXValidator) optionalString(parent map[string]interface{}, path, field string) (string, bool) {
+	value, ok := parent[field]
+	if !ok {
+		return "", false
+	}
+	return v.string(path+"/"+field, value)
+}
+
+func (v *cycloneDXValidator) enum(path, value string, allowed []string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.report(path, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// integer returns the value of a decoded JSON integer.
+func integer(value interface{}) (int64, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	n, err := number.Int64()
+	return n, err == nil
+}
+
+// isHex reports whether s is a non-empty string of hexadecimal digits.
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCycloneDX(t *testing.T) {
+	violations, err := ValidateCycloneDX([]byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.6",
+		"serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		"version": 1,
+		"metadata": {
+			"timestamp": "2024-01-01T00:00:00Z",
+			"tools": {"components": [{"type": "application", "name": "syft", "version": "1.0.0"}]},
+			"component": {"type": "container", "name": "alpine", "version": "3.19"}
+		},
+		"components": [
+			{
+				"type": "library",
+				"bom-ref": "pkg:npm/lodash@4.17.21",
+				"name": "lodash",
+				"version": "4.17.21",
+				"purl": "pkg:npm/lodash@4.17.21",
+				"licenses": [{"license": {"id": "MIT"}}, {"expression": "MIT OR Apache-2.0"}],
+				"hashes": [{"alg": "SHA-256", "content": "3e671687395b41f5"}],
+				"components": [{"type": "file", "name": "lodash.js"}]
+			},
+			{"type": "firmware", "cpe": "cpe:2.3:o:acme:router_firmware:2.1:*:*:*:*:*:*:*"}
+		],
+		"dependencies": [{"ref": "pkg:npm/lodash@4.17.21", "dependsOn": []}],
+		"x-vendor-extension": true
+	}`))
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestValidateCycloneDX_Violations(t *testing.T) {
+	violations, err := ValidateCycloneDX([]byte(`{
+		"bomFormat": "SPDX",
+		"specVersion": "2.0",
+		"version": 0,
+		"metadata": {"timestamp": "yesterday", "tools": "syft"},
+		"components": [
+			{"type": "library", "name": "", "bom-ref": "a"},
+			{"name": "no-type", "scope": "sometimes", "bom-ref": "a"},
+			{"type": "library", "name": "licenses", "licenses": [{}, {"license": {"id": "MIT", "name": "MIT"}, "expression": "MIT"}, {"license": {}}]},
+			{"type": "library", "name": "hashes", "hashes": [{"alg": "CRC32", "content": "xyz"}]},
+			"lodash"
+		],
+		"dependencies": {"ref": "a"}
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Violation{
+		{Path: "/bomFormat", Message: `must be "CycloneDX", got "SPDX"`},
+		{Path: "/specVersion", Message: `must be one of 1.0, 1.1, 1.2, 1.3, 1.4, 1.5, 1.6, got "2.0"`},
+		{Path: "/version", Message: "must be an integer of at least 1"},
+		{Path: "/metadata/timestamp", Message: `must be an RFC 3339 date-time, got "yesterday"`},
+		{Path: "/metadata/tools", Message: "must be an array or an object"},
+		{Path: "/components/0/name", Message: "must not be empty"},
+		{Path: "/components/1", Message: `missing required property "type"`},
+		{Path: "/components/1/scope", Message: `must be one of required, optional, excluded, got "sometimes"`},
+		{Path: "/components/1/bom-ref", Message: `duplicates the bom-ref "a" of /components/0`},
+		{Path: "/components/2/licenses/0", Message: "must have a license or an expression"},
+		{Path: "/components/2/licenses/1", Message: "must have either a license or an expression, not both"},
+		{Path: "/components/2/licenses/2/license", Message: "must have an id or a name"},
+		{Path: "/components/3/hashes/0/alg", Message: `must be one of MD5, SHA-1, SHA-256, SHA-384, SHA-512, SHA3-256, SHA3-384, SHA3-512, BLAKE2b-256, BLAKE2b-384, BLAKE2b-512, BLAKE3, got "CRC32"`},
+		{Path: "/components/3/hashes/0/content", Message: "must be a hexadecimal digest"},
+		{Path: "/components/4", Message: "must be an object, got string"},
+		{Path: "/dependencies", Message: "must be an array, got object"},
+	}, violations)
+}
+
+func TestValidateCycloneDX_NotJSON(t *testing.T) {
+	_, err := ValidateCycloneDX([]byte(`{"bomFormat": `))
+	assert.ErrorContains(t, err, "invalid JSON")
+
+	violations, err := ValidateCycloneDX([]byte(`[]`))
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{{Path: "", Message: "must be an object, got array"}}, violations)
+}
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -49,7 +50,8 @@ func SetupTestServer(t *testing.T) *TestServer {
 	// API v1 routes
 	mux.HandleFunc("/api/v1/sboms", rest.SubmitSBOMHandler(repo))
 	mux.HandleFunc("/api/v1/sboms/get", rest.GetSBOMHandler(repo))
-	mux.HandleFunc("/api/v1/sboms/", rest.AnalyzeSBOMHandler(repo))
+	mux.HandleFunc("/api/v1/sboms/{id}", rest.GetSBOMHandler(repo))
+	mux.HandleFunc("/api/v1/sboms/{id}/analyze", rest.AnalyzeSBOMHandler(repo, nil))
 
 	// Create test server
 	server := httptest.NewServer(mux)
@@ -161,7 +163,7 @@ func TestCompleteAPIWorkflow(t *testing.T) {
 	// Step 2: Retrieve the submitted SBOM
 	t.Log("Step 2: Retrieving submitted SBOM...")
 
-	getURL := fmt.Sprintf("%s/api/v1/sboms/get?id=%s", ts.Server.URL, sbomID)
+	getURL := fmt.Sprintf("%s/api/v1/sboms/%s", ts.Server.URL, sbomID)
 	resp, err = http.Get(getURL)
 	require.NoError(t, err)
 	defer resp.Body.Close()
@@ -178,6 +180,12 @@ func TestCompleteAPIWorkflow(t *testing.T) {
 	assert.Equal(t, sbomID, retrievedSBOM["id"])
 	assert.Equal(t, "Test Application", retrievedSBOM["name"])
 
+	// The legacy query form remains available
+	legacyResp, err := http.Get(fmt.Sprintf("%s/api/v1/sboms/get?id=%s", ts.Server.URL, sbomID))
+	require.NoError(t, err)
+	legacyResp.Body.Close()
+	assert.Equal(t, http.StatusOK, legacyResp.StatusCode, "Legacy SBOM retrieval should return 200 OK")
+
 	t.Logf("✓ SBOM retrieved successfully")
 
 	// Step 3: Analyze SBOM with license agent only (default)
@@ -297,11 +305,24 @@ func TestErrorHandling(t *testing.T) {
 			url:            "/api/v1/sboms/get?id=nonexistent",
 			expectedStatus: http.StatusNotFound,
 		},
+		{
+			name:           "Invalid SBOM ID for path-style retrieval",
+			method:         "GET",
+			url:            "/api/v1/sboms/nonexistent",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Wrong method for path-style retrieval",
+			method:         "DELETE",
+			url:            "/api/v1/sboms/nonexistent",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		// The cleaned path /api/v1/sboms/analyze retrieves an SBOM with ID "analyze"
 		{
 			name:           "Missing SBOM ID for analysis",
 			method:         "GET",
 			url:            "/api/v1/sboms//analyze",
-			expectedStatus: http.StatusMethodNotAllowed,
+			expectedStatus: http.StatusNotFound,
 		},
 	}
 
@@ -339,6 +360,7 @@ func TestConcurrentRequests(t *testing.T) {
 	const numConcurrentRequests = 5
 	done := make(chan bool, numConcurrentRequests)
 	errors := make(chan error, numConcurrentRequests)
+	responses := make(chan rest.SubmitSBOMResponse, numConcurrentRequests)
 
 	// Submit the same SBOM concurrently
 	testSBOM := createTestSBOM()
@@ -386,10 +408,18 @@ func TestConcurrentRequests(t *testing.T) {
 			}
 			defer resp.Body.Close()
 
-			if resp.StatusCode != http.StatusCreated {
-				errors <- fmt.Errorf("request %d: expected status 201, got %d", requestID, resp.StatusCode)
+			// Identical submissions after the first are reported as duplicates,
+			// even when they race the first to storage
+			if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+				errors <- fmt.Errorf("request %d: expected status 201 or 200, got %d", requestID, resp.StatusCode)
 				return
 			}
+			var response rest.SubmitSBOMResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				errors <- fmt.Errorf("request %d: failed to decode response: %w", requestID, err)
+				return
+			}
+			responses <- response
 
 			t.Logf("✓ Concurrent request %d completed successfully", requestID)
 		}(i)
@@ -409,6 +439,25 @@ func TestConcurrentRequests(t *testing.T) {
 		}
 	}
 
+	// Exactly one submission stored the SBOM; the others returned it
+	created := 0
+	var ids []string
+	for len(responses) > 0 {
+		response := <-responses
+		if !response.Duplicate {
+			created++
+		}
+		ids = append(ids, response.ID)
+	}
+	require.Len(t, ids, numConcurrentRequests)
+	assert.Equal(t, 1, created)
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+	stored, err := ts.Database.FindAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+
 	t.Logf("✓ All %d concurrent requests completed", numConcurrentRequests)
 }
 
@@ -0,0 +1,181 @@
+// Package jobs provides the queue of asynchronous analysis jobs and the
+// workers that process them. The queue is kept in process or, so that jobs
+// survive restarts and are shared by replicas, in Redis.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// Job statuses.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Job priorities. Interactive jobs are processed before any scheduled job.
+const (
+	PriorityInteractive = "interactive"
+	PriorityScheduled   = "scheduled"
+)
+
+// ErrFinished is returned when canceling a job that already finished.
+var ErrFinished = errors.New("job already finished")
+
+// Retention is how long a job is kept after it was last updated.
+const Retention = 24 * time.Hour
+
+// Job is an analysis of a stored SBOM, run asynchronously.
+type Job struct {
+	ID     string `json:"id"`
+	SBOMID string `json:"sbom_id"`
+	// Parameters are the query parameters of the analysis, such as
+	// "profile=quick&min_severity=high"
+	Parameters string `json:"parameters,omitempty"`
+	// Priority is PriorityInteractive or PriorityScheduled
+	Priority string `json:"priority"`
+	Status   string `json:"status"`
+	// Error describes why a failed job failed
+	Error string `json:"error,omitempty"`
+	// Result is the analysis response of a succeeded job
+	Result     json.RawMessage `json:"result,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// New returns a queued interactive job analyzing the SBOM sbomID with the
+// given query parameters.
+func New(sbomID string, parameters url.Values) Job {
+	return Job{
+		ID:         core.NewSBOMID(),
+		SBOMID:     sbomID,
+		Parameters: parameters.Encode(),
+		Priority:   PriorityInteractive,
+		Status:     StatusQueued,
+		CreatedAt:  core.Now(),
+	}
+}
+
+// ValidatePriority reports whether priority is a known job priority.
+func ValidatePriority(priority string) error {
+	if priority != PriorityInteractive && priority != PriorityScheduled {
+		return fmt.Errorf("priority must be %s or %s, got %q", PriorityInteractive, PriorityScheduled, priority)
+	}
+	return nil
+}
+
+// Query returns the query parameters of the analysis.
+func (j Job) Query() url.Values {
+	// Parameters are encoded by New, so they always parse
+	query, _ := url.ParseQuery(j.Parameters)
+	return query
+}
+
+// queuePriority returns the priority the job is queued with; jobs queued
+// before priorities existed are interactive.
+func (j Job) queuePriority() string {
+	if j.Priority == PriorityScheduled {
+		return PriorityScheduled
+	}
+	return PriorityInteractive
+}
+
+// Finished reports whether the job succeeded, failed or was canceled.
+func (j Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCanceled
+}
+
+// Cancel cancels the job with the given ID: a queued job is skipped, and the
+// worker running a running job stops it. It returns the canceled job, nil if
+// there is none, or ErrFinished if it already finished.
+func Cancel(ctx context.Context, queue Queue, id string) (*Job, error) {
+	for {
+		job, err := queue.Get(ctx, id)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		if job.Finished() {
+			return job, ErrFinished
+		}
+		finished := core.Now()
+		job.Status = StatusCanceled
+		job.FinishedAt = &finished
+		canceled, err := queue.Transition(ctx, *job, StatusQueued, StatusRunning)
+		if err != nil {
+			return nil, err
+		}
+		if canceled {
+			return job, nil
+		}
+		// The job finished or expired since it was read
+	}
+}
+
+// Queue holds the jobs waiting to be processed and the status of every job.
+type Queue interface {
+	// Enqueue stores a job and queues it for processing.
+	Enqueue(ctx context.Context, job Job) error
+
+	// Dequeue waits for the next queued job until ctx is done: the oldest
+	// interactive job, or else the oldest scheduled job. Canceled jobs are
+	// skipped.
+	Dequeue(ctx context.Context) (*Job, error)
+
+	// Get returns the job with the given ID, or nil if there is none.
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// Transition stores the status of a job only if its stored status is
+	// one of from, checked and stored atomically, and reports whether it
+	// did. A job moved to StatusQueued is queued for processing again.
+	Transition(ctx context.Context, job Job, from ...string) (bool, error)
+
+	// Close releases the queue's connections.
+	Close() error
+}
+
+// DefaultWorkers is how many jobs the server processes at once when
+// JOB_WORKERS is not set.
+const DefaultWorkers = 2
+
+// QueueFromEnv returns the queue configured by JOB_QUEUE_URL: a
+// redis://[user:password@]host[:port][/db] URL, or rediss:// for TLS, keeps
+// jobs in Redis; without it jobs are kept in process and lost on restart.
+func QueueFromEnv() (Queue, error) {
+	value := os.Getenv("JOB_QUEUE_URL")
+	if value == "" {
+		return NewMemoryQueue(), nil
+	}
+	queue, err := NewRedisQueue(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JOB_QUEUE_URL: %w", err)
+	}
+	return queue, nil
+}
+
+// WorkersFromEnv returns how many jobs are processed at once, configured by
+// JOB_WORKERS (default DefaultWorkers); 0 leaves the jobs to sentinel-worker
+// processes. An invalid value is reported and the default used.
+func WorkersFromEnv() (int, error) {
+	value := os.Getenv("JOB_WORKERS")
+	if value == "" {
+		return DefaultWorkers, nil
+	}
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 0 {
+		return DefaultWorkers, fmt.Errorf("invalid JOB_WORKERS %q", value)
+	}
+	return workers, nil
+}
@@ -0,0 +1,275 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForStatus polls the queue until the job has the given status.
+func waitForStatus(t *testing.T, queue Queue, id, status string) *Job {
+	t.Helper()
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = queue.Get(context.Background(), id)
+		return err == nil && job != nil && job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestNew(t *testing.T) {
+	job := New("sbom-1", url.Values{"profile": {"quick"}})
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, StatusQueued, job.Status)
+	assert.Equal(t, "quick", job.Query().Get("profile"))
+	assert.False(t, job.Finished())
+}
+
+func TestWork(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := NewMemoryQueue()
+
+	done := make(chan struct{})
+	go func() {
+		Work(ctx, queue, 2, func(ctx context.Context, job Job) (json.RawMessage, error) {
+			if job.SBOMID == "broken" {
+				return nil, errors.New("analysis failed")
+			}
+			return json.RawMessage(`{"sbom_id":"` + job.SBOMID + `"}`), nil
+		})
+		close(done)
+	}()
+
+	succeeded := New("sbom-1", nil)
+	failed := New("broken", nil)
+	require.NoError(t, queue.Enqueue(ctx, succeeded))
+	require.NoError(t, queue.Enqueue(ctx, failed))
+
+	job := waitForStatus(t, queue, succeeded.ID, StatusSucceeded)
+	assert.JSONEq(t, `{"sbom_id":"sbom-1"}`, string(job.Result))
+	assert.NotNil(t, job.StartedAt)
+	assert.NotNil(t, job.FinishedAt)
+	job = waitForStatus(t, queue, failed.ID, StatusFailed)
+	assert.Equal(t, "analysis failed", job.Error)
+
+	cancel()
+	<-done
+}
+
+func TestWork_RequeuesInterruptedJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := NewMemoryQueue()
+	job := New("sbom-1", nil)
+	require.NoError(t, queue.Enqueue(ctx, job))
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Work(ctx, queue, 1, func(ctx context.Context, job Job) (json.RawMessage, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		close(done)
+	}()
+	<-started
+	cancel()
+	<-done
+
+	// The job is waiting for the next worker
+	interrupted, err := queue.Get(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, interrupted.Status)
+	dequeueCtx, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	next, err := queue.Dequeue(dequeueCtx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, next.ID)
+}
+
+func TestMemoryQueue_Dequeue(t *testing.T) {
+	queue := NewMemoryQueue()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := queue.Dequeue(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	missing, err := queue.Get(context.Background(), "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestQueueFromEnv(t *testing.T) {
+	t.Setenv("JOB_QUEUE_URL", "")
+	queue, err := QueueFromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryQueue{}, queue)
+
+	t.Setenv("JOB_QUEUE_URL", "redis://:secret@localhost/2")
+	queue, err = QueueFromEnv()
+	require.NoError(t, err)
+	redisQueue := queue.(*RedisQueue)
+	options := redisQueue.client.Options()
+	assert.Equal(t, "localhost:6379", options.Addr)
+	assert.Equal(t, "secret", options.Password)
+	assert.Equal(t, 2, options.DB)
+
+	t.Setenv("JOB_QUEUE_URL", "nats://localhost:4222")
+	_, err = QueueFromEnv()
+	assert.ErrorContains(t, err, "invalid JOB_QUEUE_URL")
+}
+
+func TestWorkersFromEnv(t *testing.T) {
+	os.Unsetenv("JOB_WORKERS")
+	workers, err := WorkersFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWorkers, workers)
+
+	t.Setenv("JOB_WORKERS", "4")
+	workers, err = WorkersFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 4, workers)
+
+	t.Setenv("JOB_WORKERS", "0")
+	workers, err = WorkersFromEnv()
+	require.NoError(t, err)
+	assert.Zero(t, workers)
+
+	t.Setenv("JOB_WORKERS", "-1")
+	workers, err = WorkersFromEnv()
+	assert.Error(t, err)
+	assert.Equal(t, DefaultWorkers, workers)
+}
+
+func TestMemoryQueue_Priority(t *testing.T) {
+	ctx := context.Background()
+	queue := NewMemoryQueue()
+	rescan := New("sbom-1", nil)
+	rescan.Priority = PriorityScheduled
+	interactive := New("sbom-2", nil)
+	canceledJob := New("sbom-3", nil)
+	require.NoError(t, queue.Enqueue(ctx, rescan))
+	require.NoError(t, queue.Enqueue(ctx, canceledJob))
+	require.NoError(t, queue.Enqueue(ctx, interactive))
+	_, err := Cancel(ctx, queue, canceledJob.ID)
+	require.NoError(t, err)
+
+	// Interactive jobs go first and canceled jobs are skipped
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, interactive.ID, job.ID)
+	job, err = queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, rescan.ID, job.ID)
+}
+
+func TestCancel(t *testing.T) {
+	ctx := context.Background()
+	queue := NewMemoryQueue()
+	job := New("sbom-1", nil)
+	require.NoError(t, queue.Enqueue(ctx, job))
+
+	canceledJob, err := Cancel(ctx, queue, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, canceledJob.Status)
+	assert.NotNil(t, canceledJob.FinishedAt)
+
+	_, err = Cancel(ctx, queue, job.ID)
+	assert.ErrorIs(t, err, ErrFinished)
+	missing, err := Cancel(ctx, queue, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	assert.NoError(t, ValidatePriority(PriorityScheduled))
+	assert.Error(t, ValidatePriority("urgent"))
+}
+
+func TestWork_CancelsRunningJobs(t *testing.T) {
+	defer func(interval time.Duration) { cancelPollInterval = interval }(cancelPollInterval)
+	cancelPollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := NewMemoryQueue()
+	job := New("sbom-1", nil)
+	require.NoError(t, queue.Enqueue(ctx, job))
+
+	stopped := make(chan error, 1)
+	go Work(ctx, queue, 1, func(ctx context.Context, job Job) (json.RawMessage, error) {
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	waitForStatus(t, queue, job.ID, StatusRunning)
+
+	_, err := Cancel(ctx, queue, job.ID)
+	require.NoError(t, err)
+	select {
+	case err := <-stopped:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("running job was not stopped")
+	}
+
+	// The job stays canceled rather than failed
+	time.Sleep(20 * time.Millisecond)
+	canceledJob, err := queue.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, canceledJob.Status)
+}
+
+// cancelingQueue cancels a job just before a worker moves it to the status
+// before.
+type cancelingQueue struct {
+	*MemoryQueue
+	before string
+}
+
+func (q *cancelingQueue) Transition(ctx context.Context, job Job, from ...string) (bool, error) {
+	if job.Status == q.before {
+		if _, err := Cancel(ctx, q.MemoryQueue, job.ID); err != nil {
+			return false, err
+		}
+	}
+	return q.MemoryQueue.Transition(ctx, job, from...)
+}
+
+func TestProcess_InterleavedCancel(t *testing.T) {
+	for _, before := range []string{StatusRunning, StatusSucceeded, StatusQueued} {
+		t.Run(before, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			queue := &cancelingQueue{MemoryQueue: NewMemoryQueue(), before: before}
+			job := New("sbom-1", nil)
+			require.NoError(t, queue.Enqueue(ctx, job))
+
+			ran := false
+			process(ctx, queue, job, func(context.Context, Job) (json.RawMessage, error) {
+				ran = true
+				if before == StatusQueued {
+					// Interrupted, so the job would be queued again
+					cancel()
+				}
+				return json.RawMessage(`{}`), nil
+			})
+
+			// The worker neither runs, completes nor requeues a canceled job
+			assert.Equal(t, before != StatusRunning, ran)
+			stored, err := queue.Get(context.Background(), job.ID)
+			require.NoError(t, err)
+			assert.Equal(t, StatusCanceled, stored.Status)
+			assert.Nil(t, stored.Result)
+			queued, _ := queue.pop()
+			assert.Nil(t, queued)
+		})
+	}
+}
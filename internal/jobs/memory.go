@@ -0,0 +1,131 @@
+// Package jobs provides the in-process job queue.
+package jobs
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// MemoryQueue keeps jobs in process. Queued jobs are lost when the process
+// exits, and only its own workers can process them.
+type MemoryQueue struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+	// queued holds the IDs of the queued jobs of each priority, oldest first
+	queued map[string][]string
+	// ready is signalled when a job is queued
+	ready chan struct{}
+}
+
+// NewMemoryQueue creates an empty in-process queue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{jobs: make(map[string]Job), queued: make(map[string][]string), ready: make(chan struct{}, 1)}
+}
+
+// Enqueue stores a job and queues it for processing.
+func (q *MemoryQueue) Enqueue(ctx context.Context, job Job) error {
+	q.mu.Lock()
+	q.prune()
+	q.jobs[job.ID] = job
+	priority := job.queuePriority()
+	q.queued[priority] = append(q.queued[priority], job.ID)
+	q.mu.Unlock()
+
+	q.signal()
+	return nil
+}
+
+// signal wakes a waiting Dequeue, if any.
+func (q *MemoryQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Dequeue waits for the next queued job until ctx is done.
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*Job, error) {
+	for {
+		if job, more := q.pop(); job != nil {
+			// Let another waiting worker take the next job
+			if more {
+				q.signal()
+			}
+			return job, nil
+		}
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// pop removes the next queued job that was not canceled, reporting whether
+// more jobs are queued.
+func (q *MemoryQueue) pop() (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, priority := range []string{PriorityInteractive, PriorityScheduled} {
+		for len(q.queued[priority]) > 0 {
+			id := q.queued[priority][0]
+			q.queued[priority] = q.queued[priority][1:]
+			job, ok := q.jobs[id]
+			if ok && job.Status != StatusCanceled {
+				return &job, len(q.queued[PriorityInteractive])+len(q.queued[PriorityScheduled]) > 0
+			}
+		}
+	}
+	return nil, false
+}
+
+// Get returns the job with the given ID, or nil if there is none.
+func (q *MemoryQueue) Get(ctx context.Context, id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// Transition stores the status of a job if its stored status is one of from.
+func (q *MemoryQueue) Transition(ctx context.Context, job Job, from ...string) (bool, error) {
+	q.mu.Lock()
+	stored, ok := q.jobs[job.ID]
+	if !ok || !slices.Contains(from, stored.Status) {
+		q.mu.Unlock()
+		return false, nil
+	}
+	q.jobs[job.ID] = job
+	if job.Status != StatusQueued {
+		q.mu.Unlock()
+		return true, nil
+	}
+	priority := job.queuePriority()
+	q.queued[priority] = append(q.queued[priority], job.ID)
+	q.mu.Unlock()
+
+	q.signal()
+	return true, nil
+}
+
+// prune forgets the jobs that finished longer than Retention ago. The caller
+// must hold mu.
+func (q *MemoryQueue) prune() {
+	for id, job := range q.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Add(Retention).Before(core.Now()) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// Close does nothing; the jobs stay readable.
+func (q *MemoryQueue) Close() error {
+	return nil
+}
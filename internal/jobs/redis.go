@@ -0,0 +1,339 @@
+// Package jobs provides the Redis-backed job queue.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keys of the queue.
+const (
+	// redisQueueKey lists the queued interactive jobs, and
+	// redisScheduledKey the scheduled ones
+	redisQueueKey     = "sentinel:jobs:queued"
+	redisScheduledKey = "sentinel:jobs:queued:scheduled"
+	redisJobKey       = "sentinel:jobs:job:"
+	// redisConsumersKey is the set of the queues that dequeued jobs. Each
+	// keeps its dequeued jobs in its processing list until they leave
+	// StatusRunning, and holds its lease key while it is alive.
+	redisConsumersKey  = "sentinel:jobs:consumers"
+	redisProcessingKey = "sentinel:jobs:processing:"
+	redisLeaseKey      = "sentinel:jobs:lease:"
+)
+
+// redisTransitionScript stores the job ARGV[1] with the TTL ARGV[2] in
+// KEYS[1] if its stored status is one of ARGV[3:], queueing it in KEYS[2]
+// if it is queued again and removing it from the processing list KEYS[3]
+// unless it is running, and returns 1 if it did.
+var redisTransitionScript = redis.NewScript(`
+local stored = redis.call('GET', KEYS[1])
+if not stored then
+	return 0
+end
+local status = cjson.decode(stored).status
+for i = 3, #ARGV do
+	if ARGV[i] == status then
+		redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+		local job = cjson.decode(ARGV[1])
+		if job.status == 'queued' then
+			redis.call('LPUSH', KEYS[2], job.id)
+		end
+		if job.status ~= 'running' then
+			redis.call('LREM', KEYS[3], 0, job.id)
+		end
+		return 1
+	end
+end
+return 0
+`)
+
+// redisPollInterval is how long a BLMOVE blocks before Dequeue checks its
+// context again.
+var redisPollInterval = time.Second
+
+// redisLeaseTimeout is how long the jobs dequeued by a queue stay in its
+// processing list after it stopped renewing its lease, before another
+// queue queues them again.
+var redisLeaseTimeout = 30 * time.Second
+
+// RedisQueue keeps jobs in Redis, so that queued jobs survive restarts and
+// workers in other processes can process them. Jobs are stored as JSON and
+// expire Retention after their last update.
+//
+// Dequeued jobs are moved to a processing list of the queue rather than
+// removed, and the queue renews a lease while it is open. Once the lease of
+// a queue expires, because its process died, the first queue dequeuing
+// after that queues its unfinished jobs again.
+type RedisQueue struct {
+	client *redis.Client
+	// consumer identifies the queue's processing list and lease
+	consumer string
+	mu       sync.Mutex
+	// stopRenewing stops renewing the lease, once Dequeue started it
+	stopRenewing context.CancelFunc
+	renewing     sync.WaitGroup
+}
+
+// NewRedisQueue creates a queue in the Redis server of the given URL,
+// redis://[user:password@]host[:port][/db] or rediss:// for TLS.
+func NewRedisQueue(rawURL string) (*RedisQueue, error) {
+	// ParseURL defaults a missing host to localhost, which would hide a
+	// misconfigured URL
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() == "" {
+		return nil, errors.New("missing host")
+	}
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	// Commands end with the context of their caller, such as Dequeue's
+	options.ContextTimeoutEnabled = true
+	return &RedisQueue{client: redis.NewClient(options), consumer: core.NewSBOMID()}, nil
+}
+
+// Ping verifies that the Redis server is reachable.
+func (q *RedisQueue) Ping(ctx context.Context) error {
+	if err := q.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to reach redis: %w", err)
+	}
+	return nil
+}
+
+// Enqueue stores a job and queues it for processing.
+func (q *RedisQueue) Enqueue(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	if err := q.client.Set(ctx, redisJobKey+job.ID, data, Retention).Err(); err != nil {
+		return fmt.Errorf("failed to store job: %w", err)
+	}
+	if err := q.client.LPush(ctx, job.queueKey(), job.ID).Err(); err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	return nil
+}
+
+// Dequeue waits for the next queued job until ctx is done, and moves it to
+// the queue's processing list.
+func (q *RedisQueue) Dequeue(ctx context.Context) (*Job, error) {
+	if err := q.lease(ctx); err != nil {
+		return nil, err
+	}
+	processing := redisProcessingKey + q.consumer
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		id, err := q.move(ctx, processing)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, fmt.Errorf("failed to dequeue job: %w", err)
+		}
+		if id == "" {
+			continue // Timed out
+		}
+		job, err := q.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// Jobs that expired, were canceled while queued or were queued twice
+		// by a lease expiry are skipped
+		if job != nil && job.Status == StatusQueued {
+			return job, nil
+		}
+		if err := q.client.LRem(ctx, processing, 0, id).Err(); err != nil {
+			return nil, fmt.Errorf("failed to dequeue job: %w", err)
+		}
+	}
+}
+
+// move moves the next queued job to the processing list and returns its
+// ID, or "" if none was queued within redisPollInterval. Interactive jobs
+// go first; BLMOVE only waits for interactive jobs, so that a scheduled job
+// queued meanwhile waits until the poll ends.
+func (q *RedisQueue) move(ctx context.Context, processing string) (string, error) {
+	for _, key := range []string{redisQueueKey, redisScheduledKey} {
+		id, err := q.client.LMove(ctx, key, processing, "RIGHT", "LEFT").Result()
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return "", err
+		}
+	}
+	id, err := q.client.BLMove(ctx, redisQueueKey, processing, "RIGHT", "LEFT", redisPollInterval).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return id, err
+}
+
+// lease takes the queue's lease and queues the jobs of expired leases
+// again, then keeps renewing it in the background until Close. It does
+// nothing once the lease was taken.
+func (q *RedisQueue) lease(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.stopRenewing != nil {
+		return nil
+	}
+	if err := q.renew(ctx); err != nil {
+		return err
+	}
+	if err := q.client.SAdd(ctx, redisConsumersKey, q.consumer).Err(); err != nil {
+		return fmt.Errorf("failed to register job consumer: %w", err)
+	}
+	if err := q.reap(ctx); err != nil {
+		return err
+	}
+
+	renewCtx, stop := context.WithCancel(context.Background())
+	q.stopRenewing = stop
+	q.renewing.Add(1)
+	go func() {
+		defer q.renewing.Done()
+		ticker := time.NewTicker(redisLeaseTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				if err := q.renew(renewCtx); err != nil && renewCtx.Err() == nil {
+					fmt.Printf("Warning: Failed to renew job queue lease: %v\n", err)
+				}
+				if err := q.reap(renewCtx); err != nil && renewCtx.Err() == nil {
+					fmt.Printf("Warning: Failed to requeue abandoned jobs: %v\n", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// renew extends the queue's lease by redisLeaseTimeout.
+func (q *RedisQueue) renew(ctx context.Context) error {
+	if err := q.client.Set(ctx, redisLeaseKey+q.consumer, "1", redisLeaseTimeout).Err(); err != nil {
+		return fmt.Errorf("failed to renew job queue lease: %w", err)
+	}
+	return nil
+}
+
+// reap queues again the unfinished jobs in the processing lists of the
+// queues whose lease expired, and forgets those queues.
+func (q *RedisQueue) reap(ctx context.Context) error {
+	consumers, err := q.client.SMembers(ctx, redisConsumersKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list job consumers: %w", err)
+	}
+	for _, consumer := range consumers {
+		if consumer == q.consumer {
+			continue
+		}
+		alive, err := q.client.Exists(ctx, redisLeaseKey+consumer).Result()
+		if err != nil {
+			return fmt.Errorf("failed to check job consumer: %w", err)
+		}
+		if alive != 0 {
+			continue
+		}
+		processing := redisProcessingKey + consumer
+		ids, err := q.client.LRange(ctx, processing, 0, -1).Result()
+		if err != nil {
+			return fmt.Errorf("failed to list abandoned jobs: %w", err)
+		}
+		for _, id := range ids {
+			job, err := q.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			if job == nil || job.Finished() {
+				continue
+			}
+			// A job the consumer finished meanwhile is not queued again
+			job.Status = StatusQueued
+			job.StartedAt = nil
+			if _, err := q.transition(ctx, *job, processing, StatusQueued, StatusRunning); err != nil {
+				return err
+			}
+		}
+		if err := q.client.Del(ctx, processing).Err(); err != nil {
+			return fmt.Errorf("failed to remove abandoned jobs: %w", err)
+		}
+		if err := q.client.SRem(ctx, redisConsumersKey, consumer).Err(); err != nil {
+			return fmt.Errorf("failed to remove job consumer: %w", err)
+		}
+	}
+	return nil
+}
+
+// Get returns the job with the given ID, or nil if there is none.
+func (q *RedisQueue) Get(ctx context.Context, id string) (*Job, error) {
+	data, err := q.client.Get(ctx, redisJobKey+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// Transition stores the status of a job if its stored status is one of
+// from, in a Lua script so that no other update interleaves.
+func (q *RedisQueue) Transition(ctx context.Context, job Job, from ...string) (bool, error) {
+	return q.transition(ctx, job, redisProcessingKey+q.consumer, from...)
+}
+
+// transition is Transition for a job in the given processing list.
+func (q *RedisQueue) transition(ctx context.Context, job Job, processing string, from ...string) (bool, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode job: %w", err)
+	}
+	keys := []string{redisJobKey + job.ID, job.queueKey(), processing}
+	args := []any{data, int(Retention.Seconds())}
+	for _, status := range from {
+		args = append(args, status)
+	}
+	stored, err := redisTransitionScript.Run(ctx, q.client, keys, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to store job: %w", err)
+	}
+	return stored == 1, nil
+}
+
+// queueKey returns the key of the list the job is queued in.
+func (j Job) queueKey() string {
+	if j.queuePriority() == PriorityScheduled {
+		return redisScheduledKey
+	}
+	return redisQueueKey
+}
+
+// Close stops renewing the lease and closes the connections to Redis. Jobs
+// left in the processing list are queued again once the lease expires.
+func (q *RedisQueue) Close() error {
+	q.mu.Lock()
+	if q.stopRenewing != nil {
+		q.stopRenewing()
+	}
+	q.mu.Unlock()
+	q.renewing.Wait()
+	return q.client.Close()
+}
@@ -0,0 +1,155 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRedis starts an in-memory Redis server, requiring password if set, and
+// returns it with its URL.
+func newRedis(t *testing.T, password string) (*miniredis.Miniredis, string) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	if password == "" {
+		return server, "redis://" + server.Addr()
+	}
+	server.RequireAuth(password)
+	return server, "redis://:" + password + "@" + server.Addr()
+}
+
+func TestRedisQueue(t *testing.T) {
+	defer func(interval time.Duration) { redisPollInterval = interval }(redisPollInterval)
+	redisPollInterval = 50 * time.Millisecond
+
+	_, serverURL := newRedis(t, "secret")
+	queue, err := NewRedisQueue(serverURL)
+	require.NoError(t, err)
+	defer queue.Close()
+	ctx := context.Background()
+
+	require.NoError(t, queue.Ping(ctx))
+
+	rescan := New("sbom-0", nil)
+	rescan.Priority = PriorityScheduled
+	canceledJob := New("sbom-3", nil)
+	first := New("sbom-1", nil)
+	second := New("sbom-2", nil)
+	require.NoError(t, queue.Enqueue(ctx, rescan))
+	require.NoError(t, queue.Enqueue(ctx, canceledJob))
+	require.NoError(t, queue.Enqueue(ctx, first))
+	require.NoError(t, queue.Enqueue(ctx, second))
+	_, err = Cancel(ctx, queue, canceledJob.ID)
+	require.NoError(t, err)
+
+	// Interactive jobs are processed oldest first, before scheduled ones,
+	// and canceled jobs are skipped
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, job.ID)
+	assert.Equal(t, "sbom-1", job.SBOMID)
+
+	// A transition only applies to a job in one of the given statuses
+	job.Status = StatusRunning
+	stored, err := queue.Transition(ctx, *job, StatusQueued)
+	require.NoError(t, err)
+	assert.True(t, stored)
+	finished := time.Now().UTC()
+	job.Status, job.FinishedAt = StatusSucceeded, &finished
+	stored, err = queue.Transition(ctx, *job, StatusRunning)
+	require.NoError(t, err)
+	assert.True(t, stored)
+	job.Status = StatusFailed
+	stored, err = queue.Transition(ctx, *job, StatusRunning)
+	require.NoError(t, err)
+	assert.False(t, stored)
+	_, err = Cancel(ctx, queue, first.ID)
+	assert.ErrorIs(t, err, ErrFinished)
+	storedJob, err := queue.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, storedJob.Status)
+
+	job, err = queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, job.ID)
+	job, err = queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, rescan.ID, job.ID)
+
+	// Waiting for an empty queue ends with the context
+	waitCtx, cancel := context.WithTimeout(ctx, 120*time.Millisecond)
+	defer cancel()
+	_, err = queue.Dequeue(waitCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	missing, err := queue.Get(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestRedisQueue_Errors(t *testing.T) {
+	server, _ := newRedis(t, "secret")
+	queue, err := NewRedisQueue("redis://:wrong@" + server.Addr())
+	require.NoError(t, err)
+	defer queue.Close()
+	assert.ErrorContains(t, queue.Ping(context.Background()), "WRONGPASS")
+
+	for _, invalid := range []string{"http://localhost", "redis://", "redis://localhost/db"} {
+		_, err := NewRedisQueue(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRedisQueue_RequeuesAbandonedJobs(t *testing.T) {
+	defer func(interval, timeout time.Duration) {
+		redisPollInterval, redisLeaseTimeout = interval, timeout
+	}(redisPollInterval, redisLeaseTimeout)
+	redisPollInterval = 50 * time.Millisecond
+	redisLeaseTimeout = 150 * time.Millisecond
+
+	server, serverURL := newRedis(t, "")
+	ctx := context.Background()
+	abandoned, err := NewRedisQueue(serverURL)
+	require.NoError(t, err)
+	job := New("sbom-1", nil)
+	require.NoError(t, abandoned.Enqueue(ctx, job))
+
+	// The worker dies while running the job, so it stops renewing its lease
+	dequeued, err := abandoned.Dequeue(ctx)
+	require.NoError(t, err)
+	dequeued.Status = StatusRunning
+	started, err := abandoned.Transition(ctx, *dequeued, StatusQueued)
+	require.NoError(t, err)
+	require.True(t, started)
+	require.NoError(t, abandoned.Close())
+	processing, err := server.List(redisProcessingKey + abandoned.consumer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{job.ID}, processing)
+	server.FastForward(redisLeaseTimeout)
+
+	queue, err := NewRedisQueue(serverURL)
+	require.NoError(t, err)
+	defer queue.Close()
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	requeued, err := queue.Dequeue(waitCtx)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, requeued.ID)
+	assert.Equal(t, StatusQueued, requeued.Status)
+	assert.Nil(t, requeued.StartedAt)
+
+	// Finishing the job removes it from the processing list
+	requeued.Status = StatusSucceeded
+	finished, err := queue.Transition(ctx, *requeued, StatusQueued)
+	require.NoError(t, err)
+	assert.True(t, finished)
+	assert.False(t, server.Exists(redisProcessingKey+queue.consumer))
+	assert.False(t, server.Exists(redisProcessingKey+abandoned.consumer))
+	consumers, err := server.Members(redisConsumersKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{queue.consumer}, consumers)
+}
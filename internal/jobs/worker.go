@@ -0,0 +1,145 @@
+// Package jobs provides the workers processing queued jobs.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// Handler runs the analysis of a job and returns its result.
+type Handler func(ctx context.Context, job Job) (json.RawMessage, error)
+
+// retryDelay is how long a worker waits after the queue failed.
+var retryDelay = time.Second
+
+// cancelPollInterval is how often a worker checks whether its running job
+// was canceled.
+var cancelPollInterval = time.Second
+
+// errCanceled is the cause of the context of a canceled job.
+var errCanceled = errors.New("job canceled")
+
+// Work processes jobs from queue with handle, up to workers at a time,
+// until ctx is done. A job interrupted by ctx is queued again, so that
+// another worker picks it up after a restart.
+func Work(ctx context.Context, queue Queue, workers int, handle Handler) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				job, err := queue.Dequeue(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						fmt.Printf("Warning: Failed to dequeue analysis job: %v\n", err)
+						sleep(ctx, retryDelay)
+					}
+					continue
+				}
+				process(ctx, queue, *job, handle)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// process runs a job and records its outcome. Every status change is a
+// transition from the status the worker left the job in, so that a
+// concurrent Cancel is never overwritten.
+func process(ctx context.Context, queue Queue, job Job, handle Handler) {
+	// The outcome is recorded even though ctx is done
+	record := context.WithoutCancel(ctx)
+
+	started := core.Now()
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	// The job may have been canceled since it was dequeued
+	if !transition(record, queue, job, StatusQueued) {
+		return
+	}
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	var watching sync.WaitGroup
+	watching.Add(1)
+	go func() {
+		defer watching.Done()
+		watchCancellation(jobCtx, queue, job.ID, cancel)
+	}()
+	defer watching.Wait()
+	defer cancel(nil)
+
+	result, err := handle(jobCtx, job)
+	if context.Cause(jobCtx) == errCanceled {
+		// Cancel already recorded the outcome
+		return
+	}
+	if ctx.Err() != nil {
+		job.Status = StatusQueued
+		job.StartedAt = nil
+		transition(record, queue, job, StatusRunning)
+		return
+	}
+
+	finished := core.Now()
+	job.FinishedAt = &finished
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = StatusSucceeded
+		job.Result = result
+	}
+	transition(record, queue, job, StatusRunning)
+}
+
+// transition stores the status of a job if its stored status is one of
+// from, reporting whether it did. A job that was canceled meanwhile keeps
+// its status.
+func transition(ctx context.Context, queue Queue, job Job, from ...string) bool {
+	stored, err := queue.Transition(ctx, job, from...)
+	if err != nil {
+		fmt.Printf("Warning: Failed to update analysis job %s: %v\n", job.ID, err)
+	}
+	return stored
+}
+
+// watchCancellation cancels the context of a running job once the job is
+// canceled, until ctx is done.
+func watchCancellation(ctx context.Context, queue Queue, id string, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if canceled(ctx, queue, id) {
+				cancel(errCanceled)
+				return
+			}
+		}
+	}
+}
+
+// canceled reports whether the job with the given ID was canceled.
+func canceled(ctx context.Context, queue Queue, id string) bool {
+	job, err := queue.Get(ctx, id)
+	return err == nil && job != nil && job.Status == StatusCanceled
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
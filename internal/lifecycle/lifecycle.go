@@ -0,0 +1,126 @@
+// Package lifecycle provides tracking of findings across the analyses of a
+// project's SBOM versions, so that new, recurring and resolved findings can
+// be told apart and the time taken to remediate them measured.
+package lifecycle
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/report"
+)
+
+// Track records an analysis of sbom in the finding history of its project,
+// the SBOMs sharing its name, and returns the run with its lifecycle counts.
+// Only agents that completed resolve findings, so a failed or deselected
+// agent leaves its findings open. It returns nil if a newer version of the
+// project has already been analyzed.
+func Track(ctx context.Context, history storage.FindingHistory, sbom core.SBOM, result *analysis.OrchestratorReport, now time.Time) (*storage.AnalysisRun, error) {
+	var completed []string
+	for _, run := range result.Runs {
+		if run.Status == analysis.AgentStatusOK {
+			completed = append(completed, run.Agent)
+		}
+	}
+
+	run := storage.AnalysisRun{SBOMID: sbom.ID, Project: sbom.Name, AnalyzedAt: now.UTC()}
+	return history.RecordAnalysis(ctx, run, completed, Records(result.Results))
+}
+
+// Records converts analysis results into tracked findings, identified by
+// their baseline fingerprint.
+func Records(results []core.AnalysisResult) []storage.FindingRecord {
+	records := make([]storage.FindingRecord, 0, len(results))
+	for _, result := range results {
+		record := storage.FindingRecord{
+			Fingerprint: report.Fingerprint(result),
+			AgentName:   result.AgentName,
+			Severity:    result.Severity,
+			Finding:     result.Finding,
+		}
+		if result.Component != nil {
+			record.Component = result.Component.Name
+			if result.Component.Version != "" {
+				record.Component += " " + result.Component.Version
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Timeline describes the finding lifecycle of a project.
+type Timeline struct {
+	Project string `json:"project"`
+	// Runs are the recorded analyses, oldest first
+	Runs     []storage.AnalysisRun `json:"runs"`
+	Open     int                   `json:"open"`
+	Resolved int                   `json:"resolved"`
+	// Overdue counts the open findings past their SLA due date, set by the
+	// sla package
+	Overdue int `json:"overdue"`
+	// Triage counts the open findings by triage state
+	Triage map[string]int `json:"triage,omitempty"`
+	// MeanTimeToRemediateHours is the mean time from a finding being first
+	// seen to being resolved, over resolved findings; nil if none are
+	MeanTimeToRemediateHours *float64 `json:"mean_time_to_remediate_hours,omitempty"`
+	// MeanTimeToRemediateBySeverity breaks the mean down by severity
+	MeanTimeToRemediateBySeverity map[string]float64 `json:"mean_time_to_remediate_by_severity,omitempty"`
+	// Findings are the tracked findings, open ones first, each group ordered
+	// by when they were first seen
+	Findings []storage.FindingRecord `json:"findings"`
+}
+
+// NewTimeline summarizes the recorded analyses and findings of a project.
+func NewTimeline(project string, runs []storage.AnalysisRun, findings []storage.FindingRecord) *Timeline {
+	timeline := &Timeline{Project: project, Runs: runs, Findings: findings}
+
+	var total time.Duration
+	bySeverity := make(map[string]time.Duration)
+	countBySeverity := make(map[string]int)
+	for _, finding := range findings {
+		if finding.ResolvedAt == nil {
+			timeline.Open++
+			if timeline.Triage == nil {
+				timeline.Triage = make(map[string]int)
+			}
+			timeline.Triage[triageState(finding)]++
+			if finding.Overdue {
+				timeline.Overdue++
+			}
+			continue
+		}
+		timeline.Resolved++
+		elapsed := finding.ResolvedAt.Sub(finding.FirstSeen)
+		total += elapsed
+		bySeverity[finding.Severity] += elapsed
+		countBySeverity[finding.Severity]++
+	}
+
+	if timeline.Resolved > 0 {
+		mean := total.Hours() / float64(timeline.Resolved)
+		timeline.MeanTimeToRemediateHours = &mean
+		timeline.MeanTimeToRemediateBySeverity = make(map[string]float64, len(bySeverity))
+		for severity, elapsed := range bySeverity {
+			timeline.MeanTimeToRemediateBySeverity[severity] = elapsed.Hours() / float64(countBySeverity[severity])
+		}
+	}
+
+	sort.SliceStable(timeline.Findings, func(i, j int) bool {
+		return timeline.Findings[i].ResolvedAt == nil && timeline.Findings[j].ResolvedAt != nil
+	})
+	return timeline
+}
+
+// triageState returns the triage state of finding, which is TriageNew for
+// findings recorded without one.
+func triageState(finding storage.FindingRecord) string {
+	if finding.Triage == "" {
+		return storage.TriageNew
+	}
+	return finding.Triage
+}
@@ -0,0 +1,209 @@
+package lifecycle
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vulnerability(id, component, version string) core.AnalysisResult {
+	return core.AnalysisResult{
+		AgentName:       "Vulnerability Scanner",
+		Finding:         id + " in " + component + " " + version,
+		Severity:        core.SeverityHigh,
+		Component:       &core.ComponentRef{Name: component, Version: version},
+		VulnerabilityID: id,
+	}
+}
+
+func licenseFinding(component string) core.AnalysisResult {
+	return core.AnalysisResult{
+		AgentName: "License Agent",
+		Finding:   "Copyleft license in " + component,
+		Severity:  core.SeverityCritical,
+		Component: &core.ComponentRef{Name: component},
+	}
+}
+
+func orchestratorReport(licenseStatus string, results ...core.AnalysisResult) *analysis.OrchestratorReport {
+	return &analysis.OrchestratorReport{
+		Results: results,
+		Runs: []analysis.AgentRun{
+			{Agent: "Vulnerability Scanner", Status: analysis.AgentStatusOK},
+			{Agent: "License Agent", Status: licenseStatus},
+		},
+	}
+}
+
+func TestTrack(t *testing.T) {
+	ctx := context.Background()
+	repo, err := database.NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	v1 := core.SBOM{ID: "api-1", Name: "api"}
+	v2 := core.SBOM{ID: "api-2", Name: "api"}
+	require.NoError(t, repo.Store(ctx, v1))
+	// Creation times must differ to order the versions
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, repo.Store(ctx, v2))
+
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	run, err := Track(ctx, repo, v1, orchestratorReport(analysis.AgentStatusOK,
+		vulnerability("CVE-2021-44228", "log4j-core", "2.14.1"),
+		vulnerability("CVE-2022-0001", "jackson-databind", "2.9.0"),
+		licenseFinding("gpl-lib"),
+	), start)
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, 3, run.New)
+	assert.Equal(t, 3, run.Open)
+
+	// log4j is upgraded but still vulnerable, jackson is fixed, and the
+	// failed license agent leaves its finding open
+	run, err = Track(ctx, repo, v2, orchestratorReport(analysis.AgentStatusFailed,
+		vulnerability("CVE-2021-44228", "log4j-core", "2.15.0"),
+	), start.Add(48*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, 0, run.New)
+	assert.Equal(t, 1, run.Recurring)
+	assert.Equal(t, 1, run.Resolved)
+	assert.Equal(t, 2, run.Open)
+
+	// Re-analyzing the older version does not reopen the fixed finding
+	run, err = Track(ctx, repo, v1, orchestratorReport(analysis.AgentStatusOK,
+		vulnerability("CVE-2022-0001", "jackson-databind", "2.9.0"),
+	), start.Add(72*time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, run)
+
+	runs, err := repo.AnalysisRuns(ctx, "api")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "api-2", runs[1].SBOMID)
+
+	findings, err := repo.ProjectFindings(ctx, "api")
+	require.NoError(t, err)
+	timeline := NewTimeline("api", runs, findings)
+	assert.Equal(t, 2, timeline.Open)
+	assert.Equal(t, 1, timeline.Resolved)
+	require.NotNil(t, timeline.MeanTimeToRemediateHours)
+	assert.Equal(t, 48.0, *timeline.MeanTimeToRemediateHours)
+	assert.Equal(t, map[string]float64{core.SeverityHigh: 48}, timeline.MeanTimeToRemediateBySeverity)
+
+	// Open findings are listed first
+	require.Len(t, timeline.Findings, 3)
+	assert.Equal(t, storage.FindingResolved, timeline.Findings[2].Status)
+	assert.Equal(t, "jackson-databind 2.9.0", timeline.Findings[2].Component)
+	assert.Equal(t, "api-2", timeline.Findings[2].ResolvedSBOMID)
+	for _, finding := range timeline.Findings[:2] {
+		assert.Equal(t, storage.FindingOpen, finding.Status)
+	}
+}
+
+func TestTrack_ReopensResolvedFindings(t *testing.T) {
+	ctx := context.Background()
+	repo, err := database.NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	sbom := core.SBOM{ID: "web-1", Name: "web"}
+	require.NoError(t, repo.Store(ctx, sbom))
+	finding := vulnerability("CVE-2024-0001", "lodash", "4.17.20")
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err = Track(ctx, repo, sbom, orchestratorReport(analysis.AgentStatusOK, finding), start)
+	require.NoError(t, err)
+	_, err = Track(ctx, repo, sbom, orchestratorReport(analysis.AgentStatusOK), start.Add(time.Hour))
+	require.NoError(t, err)
+	run, err := Track(ctx, repo, sbom, orchestratorReport(analysis.AgentStatusOK, finding), start.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.New)
+	assert.Equal(t, 1, run.Open)
+
+	findings, err := repo.ProjectFindings(ctx, "web")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, storage.FindingOpen, findings[0].Status)
+	assert.Equal(t, start.Add(2*time.Hour), findings[0].FirstSeen.UTC())
+}
+
+func TestTrack_KeepsTriage(t *testing.T) {
+	ctx := context.Background()
+	repo, err := database.NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	sbom := core.SBOM{ID: "web-1", Name: "web"}
+	require.NoError(t, repo.Store(ctx, sbom))
+	finding := vulnerability("CVE-2024-0001", "lodash", "4.17.20")
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	fingerprint := Records([]core.AnalysisResult{finding})[0].Fingerprint
+
+	_, err = Track(ctx, repo, sbom, orchestratorReport(analysis.AgentStatusOK, finding), start)
+	require.NoError(t, err)
+	state, assignee := storage.TriageAcknowledged, "alice"
+	triaged, err := repo.TriageFinding(ctx, "web", fingerprint, storage.TriageUpdate{State: &state, Assignee: &assignee})
+	require.NoError(t, err)
+	require.NotNil(t, triaged)
+	assert.Equal(t, storage.TriageAcknowledged, triaged.Triage)
+	require.NotNil(t, triaged.TriagedAt)
+
+	// A recurring finding keeps its triage
+	_, err = Track(ctx, repo, sbom, orchestratorReport(analysis.AgentStatusOK, finding), start.Add(time.Hour))
+	require.NoError(t, err)
+	findings, err := repo.ProjectFindings(ctx, "web")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, storage.TriageAcknowledged, findings[0].Triage)
+	assert.Equal(t, "alice", findings[0].Assignee)
+	assert.Equal(t, map[string]int{storage.TriageAcknowledged: 1}, NewTimeline("web", nil, findings).Triage)
+
+	marked, err := repo.MarkSLANotified(ctx, "web", fingerprint, start)
+	require.NoError(t, err)
+	assert.True(t, marked)
+
+	// A finding reported again after being resolved starts over, unless it
+	// is a false positive, and its SLA breach can be notified again
+	for _, tt := range []struct{ state, want string }{
+		{storage.TriageInProgress, storage.TriageNew},
+		{storage.TriageFalsePositive, storage.TriageFalsePositive},
+	} {
+		state := tt.state
+		_, err = repo.TriageFinding(ctx, "web", fingerprint, storage.TriageUpdate{State: &state})
+		require.NoError(t, err)
+		start = start.Add(2 * time.Hour)
+		_, err = Track(ctx, repo, sbom, orchestratorReport(analysis.AgentStatusOK), start)
+		require.NoError(t, err)
+		_, err = Track(ctx, repo, sbom, orchestratorReport(analysis.AgentStatusOK, finding), start.Add(time.Hour))
+		require.NoError(t, err)
+
+		findings, err = repo.ProjectFindings(ctx, "web")
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, findings[0].Triage)
+		assert.Equal(t, "alice", findings[0].Assignee)
+		assert.Nil(t, findings[0].SLANotifiedAt)
+	}
+
+	triaged, err = repo.TriageFinding(ctx, "web", "missing", storage.TriageUpdate{State: &state})
+	require.NoError(t, err)
+	assert.Nil(t, triaged)
+}
+
+func TestNewTimeline_NothingResolved(t *testing.T) {
+	timeline := NewTimeline("api", nil, []storage.FindingRecord{{Fingerprint: "a", Status: storage.FindingOpen}})
+	assert.Equal(t, 1, timeline.Open)
+	assert.Nil(t, timeline.MeanTimeToRemediateHours)
+	assert.Nil(t, timeline.MeanTimeToRemediateBySeverity)
+	assert.Equal(t, map[string]int{storage.TriageNew: 1}, timeline.Triage)
+}
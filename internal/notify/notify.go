@@ -0,0 +1,292 @@
+// Package notify provides delivery of analysis findings to chat and webhook
+// channels, each receiving only the findings at or above its severity and,
+// if it belongs to teams, the findings those teams own.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+)
+
+// Channel types.
+const (
+	// TypeWebhook posts the findings as plain JSON
+	TypeWebhook = "webhook"
+	// TypeSlack posts a Block Kit message to a Slack incoming webhook
+	TypeSlack = "slack"
+	// TypeTeams posts an Adaptive Card to a Microsoft Teams incoming webhook
+	// or workflow
+	TypeTeams = "teams"
+)
+
+// Notification events.
+const (
+	// EventFindings reports the findings of an analysis
+	EventFindings = "analysis.findings"
+	// EventSLABreached reports tracked findings still open past the
+	// remediation SLA of their severity
+	EventSLABreached = "sla.breached"
+)
+
+// maxListedFindings caps the findings listed in chat messages, which are
+// limited in size; the rest are summarized by count.
+const maxListedFindings = 20
+
+// maxFindingText caps the text of a finding in chat messages, well inside
+// Slack's 3000 character limit for a section.
+const maxFindingText = 1000
+
+// Channel is a destination for finding notifications.
+type Channel struct {
+	// Name identifies the channel in logs
+	Name string `yaml:"name"`
+	// Type is TypeWebhook, TypeSlack or TypeTeams
+	Type string `yaml:"type"`
+	// URL is the webhook URL; $VAR and ${VAR} are expanded from the
+	// environment so that secrets need not be kept in the config file
+	URL string `yaml:"url"`
+	// MinSeverity is the lowest severity sent to the channel; empty sends all findings
+	MinSeverity string `yaml:"min_severity"`
+	// MinConfidence is the lowest LLM confidence of AI-derived findings sent
+	// to the channel; zero sends all findings
+	MinConfidence float64 `yaml:"min_confidence"`
+	// Owners limits the channel to the findings owned by these teams, by the
+	// owners section of the configuration file; empty sends all findings
+	Owners []string `yaml:"owners"`
+}
+
+// Validate checks that the channel can be used.
+func (c Channel) Validate() error {
+	var errs []error
+	switch c.Type {
+	case TypeWebhook, TypeSlack, TypeTeams:
+	default:
+		errs = append(errs, fmt.Errorf("invalid type %q (expected webhook, slack or teams)", c.Type))
+	}
+	if strings.TrimSpace(os.ExpandEnv(c.URL)) == "" {
+		errs = append(errs, errors.New("url is required"))
+	}
+	if c.MinSeverity != "" && core.SeverityRank(c.MinSeverity) == 0 {
+		errs = append(errs, fmt.Errorf("invalid min_severity %q (expected critical, high, medium or low)", c.MinSeverity))
+	}
+	if c.MinConfidence < 0 || c.MinConfidence > 1 {
+		errs = append(errs, fmt.Errorf("invalid min_confidence %v (expected a number from 0 to 1)", c.MinConfidence))
+	}
+	return errors.Join(errs...)
+}
+
+// accepts reports whether a finding is routed to the channel.
+func (c Channel) accepts(result core.AnalysisResult) bool {
+	if result.Confidence != nil && *result.Confidence < c.MinConfidence {
+		return false
+	}
+	if len(c.Owners) > 0 && !slices.Contains(c.Owners, result.Owner) {
+		return false
+	}
+	return c.MinSeverity == "" || core.SeverityRank(result.Severity) >= core.SeverityRank(c.MinSeverity)
+}
+
+// Notification reports the findings of an analysis of one SBOM.
+type Notification struct {
+	// Event is EventFindings if empty
+	Event    string
+	SBOMID   string
+	SBOMName string
+	Results  []core.AnalysisResult
+}
+
+// Notifier sends finding notifications.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Dispatcher sends each notification to every channel with findings at or
+// above the channel's severity, owned by the channel's teams if it has any.
+type Dispatcher struct {
+	channels []Channel
+	client   *http.Client
+}
+
+// NewDispatcher creates a dispatcher for the given channels, which should
+// have been validated.
+func NewDispatcher(channels []Channel) *Dispatcher {
+	return &Dispatcher{channels: channels, client: httpclient.New(10 * time.Second)}
+}
+
+// Notify implements the Notifier interface. Every channel is tried; the
+// failures are returned together.
+func (d *Dispatcher) Notify(ctx context.Context, notification Notification) error {
+	var errs []error
+	for _, channel := range d.channels {
+		routed := notification
+		routed.Results = nil
+		for _, result := range notification.Results {
+			if channel.accepts(result) {
+				routed.Results = append(routed.Results, result)
+			}
+		}
+		if len(routed.Results) == 0 {
+			continue
+		}
+
+		if err := d.send(ctx, channel, routed); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", channel.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send posts a notification to one channel in the channel's format.
+func (d *Dispatcher) send(ctx context.Context, channel Channel, notification Notification) error {
+	var payload any
+	switch channel.Type {
+	case TypeSlack:
+		payload = slackMessage(notification)
+	case TypeTeams:
+		payload = teamsMessage(notification)
+	default:
+		payload = webhookMessage(notification)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, os.ExpandEnv(channel.URL), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if text := strings.TrimSpace(string(message)); text != "" {
+			return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, text)
+		}
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WebhookPayload is the body posted to generic webhook channels.
+type WebhookPayload struct {
+	Event    string                `json:"event"`
+	SBOMID   string                `json:"sbom_id"`
+	SBOMName string                `json:"sbom_name"`
+	Total    int                   `json:"total"`
+	Severity map[string]int        `json:"by_severity"`
+	Findings []core.AnalysisResult `json:"findings"`
+}
+
+// webhookMessage formats a notification for a generic webhook.
+func webhookMessage(notification Notification) WebhookPayload {
+	return WebhookPayload{
+		Event:    notification.event(),
+		SBOMID:   notification.SBOMID,
+		SBOMName: notification.SBOMName,
+		Total:    len(notification.Results),
+		Severity: severityCounts(notification.Results),
+		Findings: notification.Results,
+	}
+}
+
+// event returns the event the notification reports.
+func (n Notification) event() string {
+	if n.Event == "" {
+		return EventFindings
+	}
+	return n.Event
+}
+
+// title heads chat messages: the SBOM name, or the project whose findings
+// breached their SLA.
+func (n Notification) title() string {
+	if n.event() == EventSLABreached {
+		return "⏰ SBOM Sentinel: SLA breached in " + n.SBOMName
+	}
+	return "🛡️ SBOM Sentinel: " + n.SBOMName
+}
+
+// severityCounts returns the number of findings of each severity.
+func severityCounts(results []core.AnalysisResult) map[string]int {
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Severity]++
+	}
+	return counts
+}
+
+// severityOrder lists the severities from most to least severe.
+var severityOrder = []string{core.SeverityCritical, core.SeverityHigh, core.SeverityMedium, core.SeverityLow}
+
+// summaryLine describes the findings per severity, such as "🚨 1 Critical · 🔴 2 High".
+func summaryLine(results []core.AnalysisResult) string {
+	counts := severityCounts(results)
+	var parts []string
+	for _, severity := range severityOrder {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d %s", severityIcon(severity), counts[severity], severity))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// mostSevereFirst returns the findings sorted from most to least severe,
+// keeping the order of findings of the same severity.
+func mostSevereFirst(results []core.AnalysisResult) []core.AnalysisResult {
+	sorted := make([]core.AnalysisResult, 0, len(results))
+	for rank := 4; rank >= 0; rank-- {
+		for _, result := range results {
+			if core.SeverityRank(result.Severity) == rank {
+				sorted = append(sorted, result)
+			}
+		}
+	}
+	return sorted
+}
+
+// componentLabel describes the component a finding is about, or "" if none.
+func componentLabel(result core.AnalysisResult) string {
+	if result.Component == nil {
+		return ""
+	}
+	if result.Component.Version == "" {
+		return result.Component.Name
+	}
+	return result.Component.Name + " " + result.Component.Version
+}
+
+// severityIcon returns an emoji for the given severity level.
+func severityIcon(severity string) string {
+	switch core.SeverityRank(severity) {
+	case 4:
+		return "🚨"
+	case 3:
+		return "🔴"
+	case 2:
+		return "🟡"
+	case 1:
+		return "🟢"
+	default:
+		return "⚠️"
+	}
+}
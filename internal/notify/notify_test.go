@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWebhooks records the bodies posted to each path.
+type fakeWebhooks struct {
+	mu     sync.Mutex
+	bodies map[string][]byte
+}
+
+func (f *fakeWebhooks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/broken" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("invalid_token"))
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bodies[r.URL.Path] = body
+}
+
+var testNotification = Notification{
+	SBOMID:   "sbom-1",
+	SBOMName: "payments-api",
+	Results: []core.AnalysisResult{
+		{AgentName: "License Agent", Severity: "Medium", Finding: "Component 'x' uses LGPL-2.1"},
+		{
+			AgentName: "Vulnerability Scanner Agent",
+			Severity:  "Critical",
+			Finding:   "CVE-2021-44228 in log4j-core <remote code execution>",
+			Component: &core.ComponentRef{Name: "log4j-core", Version: "2.14.1"},
+		},
+		{AgentName: "SBOM Quality Agent", Severity: "Low", Finding: "Missing supplier"},
+	},
+}
+
+func TestChannel_Validate(t *testing.T) {
+	assert.NoError(t, Channel{Type: TypeSlack, URL: "https://hooks.slack.com/services/x", MinSeverity: "high"}.Validate())
+
+	err := Channel{Type: "email", MinSeverity: "severe"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid type "email"`)
+	assert.Contains(t, err.Error(), "url is required")
+	assert.Contains(t, err.Error(), `invalid min_severity "severe"`)
+
+	err = Channel{Type: TypeWebhook, URL: "https://example.com", MinConfidence: 1.5}.Validate()
+	assert.ErrorContains(t, err, "invalid min_confidence 1.5")
+
+	// An unset variable leaves the URL empty
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+	assert.Error(t, Channel{Type: TypeSlack, URL: "${SLACK_WEBHOOK_URL}"}.Validate())
+}
+
+func TestChannel_accepts(t *testing.T) {
+	confidence := 0.6
+	aiFinding := core.AnalysisResult{AgentName: "Dependency Health Agent", Severity: "Medium", Confidence: &confidence}
+	deterministic := core.AnalysisResult{AgentName: "License Agent", Severity: "Medium"}
+
+	channel := Channel{MinConfidence: 0.7}
+	assert.False(t, channel.accepts(aiFinding))
+	assert.True(t, channel.accepts(deterministic))
+
+	channel.MinConfidence = 0.5
+	assert.True(t, channel.accepts(aiFinding))
+	channel.MinSeverity = "high"
+	assert.False(t, channel.accepts(aiFinding))
+}
+
+func TestDispatcher_NotifyOwners(t *testing.T) {
+	fake := &fakeWebhooks{bodies: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	dispatcher := NewDispatcher([]Channel{
+		{Name: "security", Type: TypeWebhook, URL: server.URL + "/security"},
+		{Name: "payments", Type: TypeWebhook, URL: server.URL + "/payments", Owners: []string{"payments"}},
+		{Name: "web", Type: TypeWebhook, URL: server.URL + "/web", Owners: []string{"web", "design"}},
+	})
+
+	notification := testNotification
+	notification.Results = append([]core.AnalysisResult(nil), testNotification.Results...)
+	notification.Results[1].Owner = "payments"
+	require.NoError(t, dispatcher.Notify(context.Background(), notification))
+
+	// Team channels receive only the findings their teams own
+	var security, payments WebhookPayload
+	require.NoError(t, json.Unmarshal(fake.bodies["/security"], &security))
+	assert.Equal(t, 3, security.Total)
+	require.NoError(t, json.Unmarshal(fake.bodies["/payments"], &payments))
+	require.Len(t, payments.Findings, 1)
+	assert.Equal(t, "payments", payments.Findings[0].Owner)
+	assert.NotContains(t, fake.bodies, "/web")
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	fake := &fakeWebhooks{bodies: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	t.Setenv("TEAMS_WEBHOOK_URL", server.URL+"/teams")
+	dispatcher := NewDispatcher([]Channel{
+		{Name: "all", Type: TypeWebhook, URL: server.URL + "/webhook"},
+		{Name: "security", Type: TypeSlack, URL: server.URL + "/slack", MinSeverity: "high"},
+		{Name: "compliance", Type: TypeTeams, URL: "${TEAMS_WEBHOOK_URL}", MinSeverity: "medium"},
+		{Name: "pager", Type: TypeWebhook, URL: server.URL + "/pager", MinSeverity: "critical"},
+	})
+
+	require.NoError(t, dispatcher.Notify(context.Background(), testNotification))
+
+	var webhook WebhookPayload
+	require.NoError(t, json.Unmarshal(fake.bodies["/webhook"], &webhook))
+	assert.Equal(t, "analysis.findings", webhook.Event)
+	assert.Equal(t, 3, webhook.Total)
+	assert.Equal(t, map[string]int{"Critical": 1, "Medium": 1, "Low": 1}, webhook.Severity)
+
+	// Each channel receives only the findings at or above its severity
+	var slack slackPayload
+	require.NoError(t, json.Unmarshal(fake.bodies["/slack"], &slack))
+	assert.Equal(t, "1 findings in payments-api", slack.Text)
+
+	var teams teamsPayload
+	require.NoError(t, json.Unmarshal(fake.bodies["/teams"], &teams))
+	assert.Equal(t, "2 findings in SBOM sbom-1", teams.Attachments[0].Content.Body[1].Text)
+
+	var pager WebhookPayload
+	require.NoError(t, json.Unmarshal(fake.bodies["/pager"], &pager))
+	assert.Equal(t, 1, pager.Total)
+
+	// A channel without matching findings is not notified
+	delete(fake.bodies, "/pager")
+	low := Notification{SBOMID: "sbom-2", SBOMName: "web", Results: testNotification.Results[2:]}
+	require.NoError(t, dispatcher.Notify(context.Background(), low))
+	assert.NotContains(t, fake.bodies, "/pager")
+}
+
+func TestDispatcher_NotifyErrors(t *testing.T) {
+	fake := &fakeWebhooks{bodies: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	dispatcher := NewDispatcher([]Channel{
+		{Name: "broken", Type: TypeSlack, URL: server.URL + "/broken"},
+		{Name: "working", Type: TypeWebhook, URL: server.URL + "/webhook"},
+	})
+
+	err := dispatcher.Notify(context.Background(), testNotification)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "channel broken: webhook returned status 403: invalid_token")
+
+	// A failing channel does not keep the others from being notified
+	assert.Contains(t, fake.bodies, "/webhook")
+}
+
+func TestSlackMessage(t *testing.T) {
+	message := slackMessage(testNotification)
+
+	require.Len(t, message.Blocks, 6)
+	assert.Equal(t, "header", message.Blocks[0].Type)
+	assert.Equal(t, "🛡️ SBOM Sentinel: payments-api", message.Blocks[0].Text.Text)
+	assert.Equal(t, "*3 findings in payments-api* (`sbom-1`)\n🚨 1 Critical · 🟡 1 Medium · 🟢 1 Low", message.Blocks[1].Text.Text)
+
+	// Findings are listed most severe first, with Slack's control characters escaped
+	assert.Equal(t, "🚨 *Critical* · Vulnerability Scanner Agent · `log4j-core 2.14.1`\nCVE-2021-44228 in log4j-core &lt;remote code execution&gt;", message.Blocks[3].Text.Text)
+	assert.Equal(t, "🟢 *Low* · SBOM Quality Agent\nMissing supplier", message.Blocks[5].Text.Text)
+}
+
+func TestSlackMessage_Owner(t *testing.T) {
+	message := slackMessage(Notification{SBOMID: "sbom-1", SBOMName: "payments-api", Results: []core.AnalysisResult{
+		{AgentName: "License Agent", Severity: "High", Finding: "GPL-3.0", Component: &core.ComponentRef{Name: "ledger"}, Owner: "payments"},
+	}})
+	assert.Equal(t, "🔴 *High* · License Agent · `ledger` · 👥 payments\nGPL-3.0", message.Blocks[3].Text.Text)
+}
+
+func TestNotification_SLABreached(t *testing.T) {
+	notification := Notification{Event: EventSLABreached, SBOMID: "sbom-1", SBOMName: "payments-api", Results: []core.AnalysisResult{
+		{AgentName: "SLA Monitor", Severity: "Critical", Finding: "SLA breached 2d ago (due 2024-06-08): CVE-2021-44228"},
+	}}
+	assert.Equal(t, EventSLABreached, webhookMessage(notification).Event)
+	assert.Equal(t, "⏰ SBOM Sentinel: SLA breached in payments-api", slackMessage(notification).Blocks[0].Text.Text)
+	assert.Equal(t, "⏰ SBOM Sentinel: SLA breached in payments-api", teamsMessage(notification).Attachments[0].Content.Body[0].Text)
+}
+
+func TestSlackMessage_ManyFindings(t *testing.T) {
+	notification := Notification{SBOMID: "sbom-1", SBOMName: "monolith"}
+	for i := 0; i < maxListedFindings+5; i++ {
+		notification.Results = append(notification.Results, core.AnalysisResult{AgentName: "License Agent", Severity: "Low", Finding: fmt.Sprintf("finding %d", i)})
+	}
+
+	message := slackMessage(notification)
+	require.Len(t, message.Blocks, 3+maxListedFindings+1)
+	last := message.Blocks[len(message.Blocks)-1]
+	assert.Equal(t, "context", last.Type)
+	assert.Equal(t, "… and 5 more findings", last.Elements[0].Text)
+}
+
+func TestTeamsMessage(t *testing.T) {
+	message := teamsMessage(testNotification)
+
+	require.Len(t, message.Attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", message.Attachments[0].ContentType)
+
+	card := message.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	assert.Equal(t, []teamsFact{{Title: "🚨 Critical", Value: "1"}, {Title: "🟡 Medium", Value: "1"}, {Title: "🟢 Low", Value: "1"}}, card.Body[2].Facts)
+
+	critical := card.Body[3].Items[0]
+	assert.Equal(t, "🚨 Critical · Vulnerability Scanner Agent · log4j-core 2.14.1", critical.Text)
+	assert.Equal(t, "Attention", critical.Color)
+	assert.Equal(t, "Warning", card.Body[4].Items[0].Color)
+}
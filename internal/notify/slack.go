@@ -0,0 +1,86 @@
+// Package notify provides the Slack Block Kit format of finding notifications.
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// slackPayload is a Slack incoming webhook message.
+type slackPayload struct {
+	// Text is the fallback shown in notifications
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlock is a Block Kit layout block.
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a Block Kit text object.
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackMessage formats a notification as a Block Kit message: a header,
+// the count per severity and a section per finding, most severe first.
+func slackMessage(notification Notification) slackPayload {
+	title := notification.title()
+	summary := fmt.Sprintf("%d findings in %s", len(notification.Results), notification.SBOMName)
+
+	message := slackPayload{
+		Text: summary,
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: title}},
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s* (`%s`)\n%s",
+				escapeSlack(summary), escapeSlack(notification.SBOMID), summaryLine(notification.Results))}},
+			{Type: "divider"},
+		},
+	}
+
+	results := mostSevereFirst(notification.Results)
+	for i, result := range results {
+		if i == maxListedFindings {
+			message.Blocks = append(message.Blocks, slackBlock{Type: "context", Elements: []slackText{
+				{Type: "mrkdwn", Text: fmt.Sprintf("… and %d more findings", len(results)-maxListedFindings)},
+			}})
+			break
+		}
+
+		heading := fmt.Sprintf("%s *%s* · %s", severityIcon(result.Severity), escapeSlack(result.Severity), escapeSlack(result.AgentName))
+		if component := componentLabel(result); component != "" {
+			heading += " · `" + escapeSlack(component) + "`"
+		}
+		if result.Owner != "" {
+			heading += " · 👥 " + escapeSlack(result.Owner)
+		}
+		text := heading + "\n" + escapeSlack(truncate(result.Finding, maxFindingText))
+		if remediation := result.Remediation(); remediation != "" {
+			text += "\n🔧 " + escapeSlack(remediation)
+		}
+		message.Blocks = append(message.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+	return message
+}
+
+// slackEscaper escapes the characters Slack reserves for links and mentions.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeSlack makes text safe to place in Slack mrkdwn.
+func escapeSlack(text string) string {
+	return slackEscaper.Replace(text)
+}
+
+// truncate shortens text to at most limit runes, marking the cut with an ellipsis.
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + "…"
+}
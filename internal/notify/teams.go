@@ -0,0 +1,116 @@
+// Package notify provides the Microsoft Teams Adaptive Card format of
+// finding notifications.
+package notify
+
+import (
+	"fmt"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// teamsPayload is a Teams webhook message carrying an Adaptive Card.
+type teamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+// teamsAttachment wraps an Adaptive Card.
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+// teamsCard is an Adaptive Card.
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+}
+
+// teamsElement is an Adaptive Card element: a TextBlock, FactSet or Container.
+type teamsElement struct {
+	Type      string         `json:"type"`
+	Text      string         `json:"text,omitempty"`
+	Size      string         `json:"size,omitempty"`
+	Weight    string         `json:"weight,omitempty"`
+	Color     string         `json:"color,omitempty"`
+	Wrap      bool           `json:"wrap,omitempty"`
+	IsSubtle  bool           `json:"isSubtle,omitempty"`
+	Separator bool           `json:"separator,omitempty"`
+	Facts     []teamsFact    `json:"facts,omitempty"`
+	Items     []teamsElement `json:"items,omitempty"`
+}
+
+// teamsFact is a name and value pair of a FactSet.
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// teamsMessage formats a notification as an Adaptive Card: a title, a fact
+// per severity and a container per finding, most severe first.
+func teamsMessage(notification Notification) teamsPayload {
+	counts := severityCounts(notification.Results)
+	var facts []teamsFact
+	for _, severity := range severityOrder {
+		if counts[severity] > 0 {
+			facts = append(facts, teamsFact{Title: severityIcon(severity) + " " + severity, Value: fmt.Sprint(counts[severity])})
+		}
+	}
+
+	body := []teamsElement{
+		{Type: "TextBlock", Text: notification.title(), Size: "Large", Weight: "Bolder", Wrap: true},
+		{Type: "TextBlock", Text: fmt.Sprintf("%d findings in SBOM %s", len(notification.Results), notification.SBOMID), IsSubtle: true, Wrap: true},
+		{Type: "FactSet", Facts: facts},
+	}
+
+	results := mostSevereFirst(notification.Results)
+	for i, result := range results {
+		if i == maxListedFindings {
+			body = append(body, teamsElement{Type: "TextBlock", Text: fmt.Sprintf("… and %d more findings", len(results)-maxListedFindings), IsSubtle: true, Separator: true})
+			break
+		}
+
+		heading := fmt.Sprintf("%s %s · %s", severityIcon(result.Severity), result.Severity, result.AgentName)
+		if component := componentLabel(result); component != "" {
+			heading += " · " + component
+		}
+		if result.Owner != "" {
+			heading += " · 👥 " + result.Owner
+		}
+		items := []teamsElement{
+			{Type: "TextBlock", Text: heading, Weight: "Bolder", Color: teamsColor(result.Severity), Wrap: true},
+			{Type: "TextBlock", Text: truncate(result.Finding, maxFindingText), Wrap: true},
+		}
+		if remediation := result.Remediation(); remediation != "" {
+			items = append(items, teamsElement{Type: "TextBlock", Text: "🔧 " + remediation, Wrap: true})
+		}
+		body = append(body, teamsElement{Type: "Container", Separator: true, Items: items})
+	}
+
+	return teamsPayload{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	}
+}
+
+// teamsColor returns the Adaptive Card text color for a severity.
+func teamsColor(severity string) string {
+	switch rank := core.SeverityRank(severity); {
+	case rank >= 3:
+		return "Attention"
+	case rank == 2:
+		return "Warning"
+	default:
+		return "Default"
+	}
+}
@@ -0,0 +1,133 @@
+// Package ownership provides the mapping of components to the teams owning
+// them, declared in the owners section of the configuration file:
+//
+//	owners:
+//	  payments:
+//	    match:
+//	      - pkg:maven/com.acme.payments/
+//	      - component:stripe-*
+//	  web:
+//	    match: [pkg:npm/@acme/]
+//
+// A "pkg:" pattern is a Package URL prefix, matching a namespace such as
+// pkg:npm/@acme/ or a single package such as pkg:npm/lodash. A "component:"
+// pattern matches component names, where * matches any run of characters
+// other than "/". When patterns of several teams match a component, the
+// longest pattern wins, so a team can own a package inside another team's
+// namespace.
+package ownership
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// componentPrefix starts the patterns matching component names.
+const componentPrefix = "component:"
+
+// Team is an entry of the owners section: the components a team owns.
+type Team struct {
+	// Match lists "pkg:" Package URL prefixes and "component:" name patterns
+	Match []string `yaml:"match" json:"match"`
+}
+
+// Map maps team names to the components they own.
+type Map map[string]Team
+
+// Validate checks the team names and their patterns.
+func (m Map) Validate() error {
+	var errs []error
+	for _, name := range m.Teams() {
+		if err := validateTeam(name, m[name]); err != nil {
+			errs = append(errs, fmt.Errorf("team '%s': %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateTeam checks the name and patterns of a team.
+func validateTeam(name string, team Team) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " \t\n") {
+		return errors.New("name must not be empty or contain spaces")
+	}
+	if len(team.Match) == 0 {
+		return errors.New("match lists no components")
+	}
+	var errs []error
+	for _, pattern := range team.Match {
+		switch {
+		case strings.HasPrefix(pattern, "pkg:"):
+			if !strings.Contains(pattern, "/") {
+				errs = append(errs, fmt.Errorf("%q has no package type (expected e.g. pkg:npm/@acme/)", pattern))
+			}
+		case strings.HasPrefix(pattern, componentPrefix):
+			name := strings.TrimPrefix(pattern, componentPrefix)
+			if _, err := path.Match(name, ""); name == "" || err != nil {
+				errs = append(errs, fmt.Errorf("%q is not a valid component name pattern", pattern))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%q is neither a pkg: Package URL prefix nor component:NAME", pattern))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Teams returns the team names in sorted order.
+func (m Map) Teams() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Owner returns the team owning component, or "" if no team does.
+func (m Map) Owner(component core.ComponentRef) string {
+	owner, longest := "", 0
+	// Teams are visited in name order, so that ties are broken the same way
+	// every time
+	for _, name := range m.Teams() {
+		for _, pattern := range m[name].Match {
+			if len(pattern) > longest && matches(pattern, component) {
+				owner, longest = name, len(pattern)
+			}
+		}
+	}
+	return owner
+}
+
+// Assign returns a copy of results in which the findings about a component
+// carry the team owning it. results itself is not modified.
+func (m Map) Assign(results []core.AnalysisResult) []core.AnalysisResult {
+	if len(m) == 0 || results == nil {
+		return results
+	}
+	assigned := make([]core.AnalysisResult, len(results))
+	for i, result := range results {
+		if result.Component != nil {
+			result.Owner = m.Owner(*result.Component)
+		}
+		assigned[i] = result
+	}
+	return assigned
+}
+
+// matches reports whether pattern matches component.
+func matches(pattern string, component core.ComponentRef) bool {
+	if name, ok := strings.CutPrefix(pattern, componentPrefix); ok {
+		matched, _ := path.Match(name, component.Name)
+		return matched
+	}
+	if component.PURL == "" || !strings.HasPrefix(component.PURL, pattern) {
+		return false
+	}
+	// pkg:npm/lodash must not match pkg:npm/lodash-es
+	rest := strings.TrimPrefix(component.PURL, pattern)
+	return rest == "" || strings.HasSuffix(pattern, "/") || strings.ContainsRune("@/?#", rune(rest[0]))
+}
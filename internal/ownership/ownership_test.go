@@ -0,0 +1,77 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOwners() Map {
+	return Map{
+		"payments": {Match: []string{"pkg:maven/com.acme.payments/", "component:stripe-*"}},
+		"web":      {Match: []string{"pkg:npm/@acme/", "pkg:npm/lodash"}},
+	}
+}
+
+func TestMap_Owner(t *testing.T) {
+	owners := testOwners()
+
+	tests := []struct {
+		name      string
+		component core.ComponentRef
+		want      string
+	}{
+		{"namespace prefix", core.ComponentRef{Name: "ledger", PURL: "pkg:maven/com.acme.payments/ledger@1.2.0"}, "payments"},
+		{"name pattern", core.ComponentRef{Name: "stripe-go"}, "payments"},
+		{"package", core.ComponentRef{Name: "lodash", PURL: "pkg:npm/lodash@4.17.21"}, "web"},
+		{"package boundary", core.ComponentRef{Name: "lodash-es", PURL: "pkg:npm/lodash-es@4.17.21"}, ""},
+		{"scoped package", core.ComponentRef{Name: "@acme/checkout", PURL: "pkg:npm/@acme/checkout@2.0.0"}, "web"},
+		{"unowned", core.ComponentRef{Name: "react", PURL: "pkg:npm/react@18.2.0"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, owners.Owner(tt.component))
+		})
+	}
+
+	// The longest pattern wins, so a team can own a package inside another
+	// team's namespace
+	owners["checkout"] = Team{Match: []string{"pkg:npm/@acme/checkout"}}
+	assert.Equal(t, "checkout", owners.Owner(core.ComponentRef{Name: "@acme/checkout", PURL: "pkg:npm/@acme/checkout@2.0.0"}))
+	assert.Equal(t, "web", owners.Owner(core.ComponentRef{Name: "@acme/ui", PURL: "pkg:npm/@acme/ui@1.0.0"}))
+}
+
+func TestMap_Assign(t *testing.T) {
+	results := []core.AnalysisResult{
+		{AgentName: "License Agent", Finding: "GPL", Component: &core.ComponentRef{Name: "stripe-java"}},
+		{AgentName: "Quality Agent", Finding: "No supplier"},
+		{AgentName: "License Agent", Finding: "MIT", Component: &core.ComponentRef{Name: "react"}},
+	}
+
+	assigned := testOwners().Assign(results)
+	require.Len(t, assigned, 3)
+	assert.Equal(t, "payments", assigned[0].Owner)
+	assert.Empty(t, assigned[1].Owner)
+	assert.Empty(t, assigned[2].Owner)
+	assert.Empty(t, results[0].Owner, "results are not modified")
+
+	assert.Equal(t, results, Map(nil).Assign(results))
+}
+
+func TestMap_Validate(t *testing.T) {
+	require.NoError(t, testOwners().Validate())
+
+	err := Map{
+		"":         {Match: []string{"pkg:npm/@acme/"}},
+		"empty":    {},
+		"patterns": {Match: []string{"lodash", "pkg:npm", "component:[a-"}},
+	}.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "name must not be empty")
+	assert.ErrorContains(t, err, "team 'empty': match lists no components")
+	assert.ErrorContains(t, err, `"lodash" is neither`)
+	assert.ErrorContains(t, err, `"pkg:npm" has no package type`)
+	assert.ErrorContains(t, err, `"component:[a-" is not a valid component name pattern`)
+}
@@ -0,0 +1,334 @@
+// Package cache provides a shared cache of external API responses, such as
+// OSV.dev queries and container registry lookups. Entries are kept in a
+// size-bounded LRU in memory and, optionally, in a SQLite file so that they
+// survive restarts. Each source has its own time to live.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Sources of cached responses.
+const (
+	SourceOSV        = "osv"
+	SourceRegistry   = "registry"
+	SourceRepository = "repository"
+)
+
+// Defaults for the cache size and times to live.
+const (
+	DefaultMaxEntries = 10000
+	DefaultTTL        = 24 * time.Hour
+)
+
+// defaultSourceTTLs are the times to live of sources whose responses change
+// more often than DefaultTTL allows for.
+var defaultSourceTTLs = map[string]time.Duration{
+	SourceOSV:      6 * time.Hour,
+	SourceRegistry: time.Hour,
+}
+
+// Config controls the size, persistence and times to live of a Cache.
+type Config struct {
+	// MaxEntries bounds the entries held in memory; zero disables caching
+	MaxEntries int
+	// Path is the SQLite file entries are persisted to; empty keeps them in
+	// memory only
+	Path string
+	// DefaultTTL is the time to live of sources without their own
+	DefaultTTL time.Duration
+	// TTLs are the times to live by source; zero disables caching a source
+	TTLs map[string]time.Duration
+}
+
+// DefaultConfig returns an in-memory cache configuration.
+func DefaultConfig() Config {
+	ttls := make(map[string]time.Duration, len(defaultSourceTTLs))
+	for source, ttl := range defaultSourceTTLs {
+		ttls[source] = ttl
+	}
+	return Config{MaxEntries: DefaultMaxEntries, DefaultTTL: DefaultTTL, TTLs: ttls}
+}
+
+// ConfigFromEnv returns the default configuration with the environment
+// applied: CACHE_MAX_ENTRIES, CACHE_PATH, CACHE_TTL for every source and
+// CACHE_TTL_OSV, CACHE_TTL_REGISTRY and CACHE_TTL_REPOSITORY for one, as Go durations such as
+// "12h". Invalid values are reported and the defaults kept.
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+	var errs []error
+
+	if value := os.Getenv("CACHE_MAX_ENTRIES"); value != "" {
+		entries, err := strconv.Atoi(value)
+		if err != nil || entries < 0 {
+			errs = append(errs, fmt.Errorf("invalid CACHE_MAX_ENTRIES %q", value))
+		} else {
+			config.MaxEntries = entries
+		}
+	}
+
+	config.Path = os.Getenv("CACHE_PATH")
+
+	parseTTL := func(name string) (time.Duration, bool) {
+		value := os.Getenv(name)
+		if value == "" {
+			return 0, false
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %q", name, value))
+			return 0, false
+		}
+		return ttl, true
+	}
+	if ttl, ok := parseTTL("CACHE_TTL"); ok {
+		// A global setting overrides the shorter source defaults too
+		config.DefaultTTL = ttl
+		config.TTLs = make(map[string]time.Duration)
+	}
+	for _, source := range []string{SourceOSV, SourceRegistry, SourceRepository} {
+		if ttl, ok := parseTTL("CACHE_TTL_" + strings.ToUpper(source)); ok {
+			config.TTLs[source] = ttl
+		}
+	}
+
+	return config, errors.Join(errs...)
+}
+
+// Cache is a size-bounded LRU cache of responses by source and key, such as
+// a package URL. It is safe for concurrent use. A nil *Cache caches nothing,
+// so callers need not check whether caching is enabled.
+//
+// Failures of the persistent store are logged and treated as misses: a
+// cache never fails a lookup that could be made without it.
+type Cache struct {
+	config Config
+	db     *sql.DB
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds the entries, most recently used first
+	order *list.List
+}
+
+// entry is a cached response.
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// New creates a cache with the given configuration, opening or creating its
+// persistent store if config.Path is set. Expired persisted entries are
+// removed. It returns nil, caching nothing, if config.MaxEntries is zero.
+func New(config Config) (*Cache, error) {
+	if config.MaxEntries <= 0 {
+		return nil, nil
+	}
+
+	cache := &Cache{
+		config:  config,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+
+	if config.Path != "" {
+		db, err := sql.Open("sqlite3", config.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open cache: %w", err)
+		}
+		schema := `
+		CREATE TABLE IF NOT EXISTS responses (
+			source TEXT NOT NULL,
+			key TEXT NOT NULL,
+			value BLOB NOT NULL,
+			expires_at INTEGER NOT NULL, -- Unix nanoseconds
+			PRIMARY KEY (source, key)
+		);
+		`
+		if _, err := db.Exec(schema); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize cache schema: %w", err)
+		}
+		if _, err := db.Exec("DELETE FROM responses WHERE expires_at <= ?", cache.now().UnixNano()); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to remove expired cache entries: %w", err)
+		}
+		cache.db = db
+	}
+
+	return cache, nil
+}
+
+// shared is the process-wide cache configured by the environment.
+var shared = sync.OnceValue(func() *Cache {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: Invalid cache configuration: %v\n", err)
+	}
+	cache, err := New(config)
+	if err != nil {
+		fmt.Printf("Warning: Persistent cache disabled: %v\n", err)
+		config.Path = ""
+		cache, _ = New(config)
+	}
+	return cache
+})
+
+// Shared returns the cache configured by the environment, created on first
+// use and shared by every agent in the process, or nil if caching is
+// disabled.
+func Shared() *Cache {
+	return shared()
+}
+
+// ttl returns the time to live of a source's responses.
+func (c *Cache) ttl(source string) time.Duration {
+	if ttl, ok := c.config.TTLs[source]; ok {
+		return ttl
+	}
+	return c.config.DefaultTTL
+}
+
+// Get returns the cached response of source for key, if there is an
+// unexpired one.
+func (c *Cache) Get(ctx context.Context, source, key string) ([]byte, bool) {
+	if c == nil || c.ttl(source) <= 0 {
+		return nil, false
+	}
+	now := c.now()
+	id := source + "\x00" + key
+
+	c.mu.Lock()
+	if element, ok := c.entries[id]; ok {
+		cached := element.Value.(*entry)
+		if now.Before(cached.expires) {
+			c.order.MoveToFront(element)
+			c.mu.Unlock()
+			return cached.value, true
+		}
+		c.remove(element)
+	}
+	c.mu.Unlock()
+
+	if c.db == nil {
+		return nil, false
+	}
+	var value []byte
+	var expiresAt int64
+	err := c.db.QueryRowContext(ctx, "SELECT value, expires_at FROM responses WHERE source = ? AND key = ?", source, key).Scan(&value, &expiresAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			fmt.Printf("Warning: Failed to read cache: %v\n", err)
+		}
+		return nil, false
+	}
+	expires := time.Unix(0, expiresAt)
+	if !now.Before(expires) {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.add(id, value, expires)
+	c.mu.Unlock()
+	return value, true
+}
+
+// Set caches a response of source for key for the source's time to live.
+func (c *Cache) Set(ctx context.Context, source, key string, value []byte) {
+	if c == nil {
+		return
+	}
+	ttl := c.ttl(source)
+	if ttl <= 0 {
+		return
+	}
+	expires := c.now().Add(ttl)
+
+	c.mu.Lock()
+	c.add(source+"\x00"+key, value, expires)
+	c.mu.Unlock()
+
+	if c.db == nil {
+		return
+	}
+	_, err := c.db.ExecContext(ctx, `
+		INSERT INTO responses (source, key, value, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (source, key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		source, key, value, expires.UnixNano())
+	if err != nil {
+		fmt.Printf("Warning: Failed to write cache: %v\n", err)
+	}
+}
+
+// GetJSON decodes the cached response of source for key into v, reporting
+// whether there was one.
+func (c *Cache) GetJSON(ctx context.Context, source, key string, v interface{}) bool {
+	data, ok := c.Get(ctx, source, key)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// SetJSON caches v, encoded as JSON, as the response of source for key.
+func (c *Cache) SetJSON(ctx context.Context, source, key string, v interface{}) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.Set(ctx, source, key, data)
+}
+
+// Len returns the number of entries held in memory.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Close closes the persistent store, if any.
+func (c *Cache) Close() error {
+	if c == nil || c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// add inserts or replaces an entry as the most recently used, evicting the
+// least recently used beyond MaxEntries. The caller must hold mu.
+func (c *Cache) add(id string, value []byte, expires time.Time) {
+	if element, ok := c.entries[id]; ok {
+		c.remove(element)
+	}
+	c.entries[id] = c.order.PushFront(&entry{key: id, value: value, expires: expires})
+	for c.order.Len() > c.config.MaxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops an entry from memory. The caller must hold mu.
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+}
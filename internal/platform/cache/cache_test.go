@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_LRU(t *testing.T) {
+	ctx := context.Background()
+	cache, err := New(Config{MaxEntries: 2, DefaultTTL: time.Hour})
+	require.NoError(t, err)
+
+	cache.Set(ctx, SourceOSV, "pkg:npm/a@1", []byte("a"))
+	cache.Set(ctx, SourceOSV, "pkg:npm/b@1", []byte("b"))
+
+	// Reading a makes b the least recently used
+	value, ok := cache.Get(ctx, SourceOSV, "pkg:npm/a@1")
+	require.True(t, ok)
+	assert.Equal(t, "a", string(value))
+
+	cache.Set(ctx, SourceOSV, "pkg:npm/c@1", []byte("c"))
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get(ctx, SourceOSV, "pkg:npm/b@1")
+	assert.False(t, ok)
+	_, ok = cache.Get(ctx, SourceOSV, "pkg:npm/a@1")
+	assert.True(t, ok)
+
+	// Keys are per source
+	_, ok = cache.Get(ctx, SourceRegistry, "pkg:npm/a@1")
+	assert.False(t, ok)
+}
+
+func TestCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	cache, err := New(Config{
+		MaxEntries: 10,
+		DefaultTTL: time.Hour,
+		TTLs:       map[string]time.Duration{SourceRegistry: time.Minute, "disabled": 0},
+	})
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cache.Set(ctx, SourceOSV, "key", []byte("osv"))
+	cache.Set(ctx, SourceRegistry, "key", []byte("registry"))
+	cache.Set(ctx, "disabled", "key", []byte("never"))
+	_, ok := cache.Get(ctx, "disabled", "key")
+	assert.False(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.Get(ctx, SourceRegistry, "key")
+	assert.False(t, ok)
+	_, ok = cache.Get(ctx, SourceOSV, "key")
+	assert.True(t, ok)
+}
+
+func TestCache_Persistent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.db")
+	config := Config{MaxEntries: 10, Path: path, DefaultTTL: time.Hour}
+
+	cache, err := New(config)
+	require.NoError(t, err)
+	cache.SetJSON(ctx, SourceOSV, "pkg:npm/lodash@4.17.20", []string{"GHSA-1"})
+	require.NoError(t, cache.Close())
+
+	reopened, err := New(config)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Zero(t, reopened.Len())
+
+	var ids []string
+	require.True(t, reopened.GetJSON(ctx, SourceOSV, "pkg:npm/lodash@4.17.20", &ids))
+	assert.Equal(t, []string{"GHSA-1"}, ids)
+	assert.Equal(t, 1, reopened.Len())
+}
+
+func TestCache_Nil(t *testing.T) {
+	cache, err := New(Config{MaxEntries: 0})
+	require.NoError(t, err)
+	assert.Nil(t, cache)
+
+	cache.Set(context.Background(), SourceOSV, "key", []byte("value"))
+	_, ok := cache.Get(context.Background(), SourceOSV, "key")
+	assert.False(t, ok)
+	assert.NoError(t, cache.Close())
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("CACHE_MAX_ENTRIES", "500")
+	t.Setenv("CACHE_PATH", "/data/cache.db")
+	t.Setenv("CACHE_TTL_REGISTRY", "10m")
+	t.Setenv("CACHE_TTL_REPOSITORY", "0")
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 500, config.MaxEntries)
+	assert.Equal(t, "/data/cache.db", config.Path)
+	assert.Equal(t, 10*time.Minute, config.TTLs[SourceRegistry])
+	assert.Equal(t, 6*time.Hour, config.TTLs[SourceOSV])
+	ttl, ok := config.TTLs[SourceRepository]
+	assert.True(t, ok)
+	assert.Zero(t, ttl)
+
+	t.Setenv("CACHE_TTL", "48h")
+	t.Setenv("CACHE_MAX_ENTRIES", "-1")
+	config, err = ConfigFromEnv()
+	assert.Error(t, err)
+	assert.Equal(t, DefaultMaxEntries, config.MaxEntries)
+	assert.Equal(t, 48*time.Hour, config.DefaultTTL)
+	_, ok = config.TTLs[SourceOSV]
+	assert.False(t, ok)
+	assert.Equal(t, 10*time.Minute, config.TTLs[SourceRegistry])
+}
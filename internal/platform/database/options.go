@@ -0,0 +1,86 @@
+// Package database provides the connection settings of the SQLite repository.
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default connection settings of the SQLite repository.
+const (
+	DefaultJournalMode = "wal"
+	DefaultBusyTimeout = 5 * time.Second
+)
+
+// journalModes are the SQLite journal modes accepted by SQLiteOptions.
+var journalModes = []string{"wal", "delete", "truncate", "persist", "memory", "off"}
+
+// SQLiteOptions are the connection settings of the SQLite repository.
+// Zero values select the defaults.
+type SQLiteOptions struct {
+	// JournalMode is the SQLite journal mode; the default WAL mode lets
+	// readers proceed while a submission is being written
+	JournalMode string `yaml:"journal_mode"`
+	// BusyTimeout is how long a connection waits for another's write lock
+	// before failing with "database is locked"
+	BusyTimeout time.Duration `yaml:"busy_timeout"`
+	// MaxOpenConns limits the open connections, unlimited by default
+	MaxOpenConns int `yaml:"max_open_conns"`
+	// MaxIdleConns limits the idle connections kept open, 2 by default
+	MaxIdleConns int `yaml:"max_idle_conns"`
+}
+
+// Validate reports an unknown journal mode or a negative setting.
+func (o SQLiteOptions) Validate() error {
+	if o.JournalMode != "" && !containsFold(journalModes, o.JournalMode) {
+		return fmt.Errorf("invalid journal mode %q (expected one of %s)", o.JournalMode, strings.Join(journalModes, ", "))
+	}
+	if o.BusyTimeout < 0 {
+		return fmt.Errorf("invalid busy timeout %s", o.BusyTimeout)
+	}
+	if o.MaxOpenConns < 0 || o.MaxIdleConns < 0 {
+		return fmt.Errorf("connection limits must not be negative")
+	}
+	return nil
+}
+
+// withDefaults returns the options with zero values replaced by the defaults.
+func (o SQLiteOptions) withDefaults() SQLiteOptions {
+	if o.JournalMode == "" {
+		o.JournalMode = DefaultJournalMode
+	}
+	if o.BusyTimeout == 0 {
+		o.BusyTimeout = DefaultBusyTimeout
+	}
+	return o
+}
+
+// dataSourceName returns the go-sqlite3 data source name opening the
+// database at path with the options. The settings are applied to every
+// connection of the pool. Transactions take the write lock when they begin,
+// so that two of them cannot both read and then fail to upgrade to writing.
+func (o SQLiteOptions) dataSourceName(path string) string {
+	params := url.Values{}
+	params.Set("_journal_mode", strings.ToUpper(o.JournalMode))
+	params.Set("_busy_timeout", strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10))
+	params.Set("_txlock", "immediate")
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return "file:" + strings.TrimPrefix(path, "file:") + separator + params.Encode()
+}
+
+// containsFold reports whether values contains value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,12 +5,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
 	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 // SQLiteRepository implements the storage.Repository interface using SQLite.
@@ -18,13 +19,31 @@ type SQLiteRepository struct {
 	db *sql.DB
 }
 
-// NewSQLiteRepository creates a new SQLite repository instance.
+// NewSQLiteRepository creates a new SQLite repository instance with the
+// default connection settings.
 // It initializes the database connection and creates the necessary tables.
 func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	return NewSQLiteRepositoryWithOptions(dbPath, SQLiteOptions{})
+}
+
+// NewSQLiteRepositoryWithOptions creates a SQLite repository with the given
+// connection settings, such as the journal mode and busy timeout.
+func NewSQLiteRepositoryWithOptions(dbPath string, options SQLiteOptions) (*SQLiteRepository, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	options = options.withDefaults()
+
+	db, err := sql.Open("sqlite3", options.dataSourceName(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	if options.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(options.MaxOpenConns)
+	}
+	if options.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(options.MaxIdleConns)
+	}
 
 	repo := &SQLiteRepository{db: db}
 
@@ -43,7 +62,9 @@ func (r *SQLiteRepository) initSchema() error {
 		id TEXT PRIMARY KEY,
 		name TEXT NOT NULL,
 		components TEXT NOT NULL, -- JSON-encoded components
+		services TEXT NOT NULL DEFAULT '[]', -- JSON-encoded services
 		metadata TEXT NOT NULL,   -- JSON-encoded metadata
+		tags TEXT NOT NULL DEFAULT '{}', -- JSON-encoded tags
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
@@ -57,80 +78,926 @@ func (r *SQLiteRepository) initSchema() error {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	// Upgrade databases created before services were captured
+	if err := r.ensureColumn("sboms", "services", "TEXT NOT NULL DEFAULT '[]'"); err != nil {
+		return err
+	}
+
+	// Upgrade databases created before duplicate submissions were detected
+	if err := r.ensureColumn("sboms", "content_hash", "TEXT"); err != nil {
+		return err
+	}
+	// Upgrade databases where forced resubmissions recorded the content hash
+	// of a stored SBOM again, keeping it on the earliest, so that each hash
+	// identifies one SBOM
+	contentHashes := `
+	UPDATE sboms SET content_hash = NULL
+	WHERE content_hash IS NOT NULL AND EXISTS (
+		SELECT 1 FROM sboms earlier
+		WHERE earlier.content_hash = sboms.content_hash
+		AND (earlier.created_at < sboms.created_at OR (earlier.created_at = sboms.created_at AND earlier.id < sboms.id))
+	);
+
+	DROP INDEX IF EXISTS idx_sboms_content_hash;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_sboms_content_hash_unique ON sboms(content_hash) WHERE content_hash IS NOT NULL;
+	`
+	if _, err := r.db.Exec(contentHashes); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	// Upgrade databases created when SBOM IDs were the serial numbers of
+	// their documents, recording the serial number where later SBOMs keep it
+	if err := r.ensureColumn("sboms", "serial_number", "TEXT"); err != nil {
+		return err
+	}
+	// Upgrade databases created before SBOMs were tagged
+	if err := r.ensureColumn("sboms", "tags", "TEXT NOT NULL DEFAULT '{}'"); err != nil {
+		return err
+	}
+
+	// Serial numbers identify one SBOM each, and documents without one have
+	// none; those of SBOMs stored twice by concurrent submissions before
+	// they were unique stay with the earliest
+	backfill := `
+	UPDATE sboms SET serial_number = NULL WHERE serial_number = '';
+
+	UPDATE sboms
+	SET serial_number = id, metadata = json_set(metadata, '$.serialNumber', id)
+	WHERE serial_number IS NULL AND id LIKE 'urn:uuid:%'
+	AND NOT EXISTS (SELECT 1 FROM sboms other WHERE other.serial_number = sboms.id);
+
+	UPDATE sboms SET serial_number = NULL
+	WHERE serial_number IS NOT NULL AND EXISTS (
+		SELECT 1 FROM sboms earlier
+		WHERE earlier.serial_number = sboms.serial_number
+		AND (earlier.created_at < sboms.created_at OR (earlier.created_at = sboms.created_at AND earlier.id < sboms.id))
+	);
+
+	DROP INDEX IF EXISTS idx_sboms_serial_number;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_sboms_serial_number_unique ON sboms(serial_number) WHERE serial_number IS NOT NULL;
+	`
+	if _, err := r.db.Exec(backfill); err != nil {
+		return fmt.Errorf("failed to record serial numbers: %w", err)
+	}
+
+	watches := `
+	CREATE TABLE IF NOT EXISTS watches (
+		id TEXT PRIMARY KEY,
+		purl TEXT NOT NULL UNIQUE,
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+	`
+	if _, err := r.db.Exec(watches); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	lifecycle := `
+	CREATE TABLE IF NOT EXISTS findings (
+		project TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		agent_name TEXT NOT NULL,
+		severity TEXT NOT NULL,
+		finding TEXT NOT NULL,
+		component TEXT NOT NULL DEFAULT '',
+		first_seen DATETIME NOT NULL,
+		first_sbom_id TEXT NOT NULL,
+		last_seen DATETIME NOT NULL,
+		last_sbom_id TEXT NOT NULL,
+		resolved_at DATETIME,
+		resolved_sbom_id TEXT NOT NULL DEFAULT '',
+		triage TEXT NOT NULL DEFAULT 'new',
+		assignee TEXT NOT NULL DEFAULT '',
+		triage_note TEXT NOT NULL DEFAULT '',
+		triaged_at DATETIME,
+		sla_notified_at DATETIME,
+		PRIMARY KEY (project, fingerprint)
+	);
+
+	CREATE TABLE IF NOT EXISTS analysis_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sbom_id TEXT NOT NULL,
+		project TEXT NOT NULL,
+		analyzed_at DATETIME NOT NULL,
+		new_findings INTEGER NOT NULL,
+		recurring_findings INTEGER NOT NULL,
+		resolved_findings INTEGER NOT NULL,
+		open_findings INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_analysis_runs_project ON analysis_runs(project);
+	`
+	if _, err := r.db.Exec(lifecycle); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	// Upgrade databases created before findings were triaged
+	for _, column := range [][2]string{
+		{"triage", "TEXT NOT NULL DEFAULT 'new'"},
+		{"assignee", "TEXT NOT NULL DEFAULT ''"},
+		{"triage_note", "TEXT NOT NULL DEFAULT ''"},
+		{"triaged_at", "DATETIME"},
+		{"sla_notified_at", "DATETIME"},
+	} {
+		if err := r.ensureColumn("findings", column[0], column[1]); err != nil {
+			return err
+		}
+	}
+
+	idempotency := `
+	CREATE TABLE IF NOT EXISTS idempotent_responses (
+		key TEXT PRIMARY KEY,
+		fingerprint TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		header TEXT NOT NULL,
+		body BLOB NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_idempotent_responses_created_at ON idempotent_responses(created_at);
+	`
+	if _, err := r.db.Exec(idempotency); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
 	return nil
 }
 
-// Store persists an SBOM document to the SQLite database.
-func (r *SQLiteRepository) Store(ctx context.Context, sbom core.SBOM) error {
-	// Serialize components to JSON
-	componentsJSON, err := json.Marshal(sbom.Components)
+// ensureColumn adds a column to an existing table if it is not already present.
+func (r *SQLiteRepository) ensureColumn(table, column, definition string) error {
+	rows, err := r.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
-		return fmt.Errorf("failed to marshal components: %w", err)
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
 	}
+	defer rows.Close()
 
-	// Serialize metadata to JSON
-	metadataJSON, err := json.Marshal(sbom.Metadata)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+
+	if _, err := r.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+
+	return nil
+}
+
+// queryer runs statements against the database or within a transaction.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// txKey is the context key of the transaction started by InTransaction.
+type txKey struct{}
+
+// conn returns the transaction of ctx, if InTransaction started one, or
+// the database.
+func (r *SQLiteRepository) conn(ctx context.Context) queryer {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return r.db
+}
+
+// InTransaction calls fn with a context in which the repository's reads and
+// writes take place in one transaction, committed if fn returns nil and
+// rolled back otherwise. Calls within fn join its transaction.
+func (r *SQLiteRepository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Store persists an SBOM document to the SQLite database. It fails with
+// storage.ErrConflict if another SBOM has its serial number.
+func (r *SQLiteRepository) Store(ctx context.Context, sbom core.SBOM) error {
+	_, err := r.storeAll(ctx, []core.SBOM{sbom})
+	return err
+}
 
-	now := time.Now()
+// StoreBatch persists several SBOM documents in one transaction, so that
+// either all of them are stored or, if any cannot be, none.
+func (r *SQLiteRepository) StoreBatch(ctx context.Context, sboms []core.SBOM) error {
+	return r.InTransaction(ctx, func(ctx context.Context) error {
+		failed, err := r.storeAll(ctx, sboms)
+		if err != nil {
+			return fmt.Errorf("SBOM %d of %d (%s): %w", failed+1, len(sboms), sboms[failed].Name, err)
+		}
+		return nil
+	})
+}
 
-	// Check if SBOM already exists
-	var existingID string
-	err = r.db.QueryRowContext(ctx, "SELECT id FROM sboms WHERE id = ?", sbom.ID).Scan(&existingID)
+// storeAll inserts or updates sboms with one prepared statement, returning
+// the index of the SBOM that failed, if one did.
+func (r *SQLiteRepository) storeAll(ctx context.Context, sboms []core.SBOM) (int, error) {
+	// Updates keep the creation time and content hash of the stored SBOM
+	stmt, err := r.conn(ctx).PrepareContext(ctx, `
+		INSERT INTO sboms (id, name, components, services, metadata, tags, serial_number, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name, components = excluded.components, services = excluded.services,
+			metadata = excluded.metadata, tags = excluded.tags, serial_number = excluded.serial_number, updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
 
-	if err == sql.ErrNoRows {
-		// Insert new SBOM
-		query := `
-			INSERT INTO sboms (id, name, components, metadata, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?)
-		`
-		_, err = r.db.ExecContext(ctx, query, sbom.ID, sbom.Name, string(componentsJSON), string(metadataJSON), now, now)
+	now := core.Now()
+	for i, sbom := range sboms {
+		// Serialize components to JSON
+		componentsJSON, err := json.Marshal(sbom.Components)
+		if err != nil {
+			return i, fmt.Errorf("failed to marshal components: %w", err)
+		}
+
+		// Serialize services to JSON
+		servicesJSON, err := json.Marshal(sbom.Services)
 		if err != nil {
-			return fmt.Errorf("failed to insert SBOM: %w", err)
+			return i, fmt.Errorf("failed to marshal services: %w", err)
 		}
-	} else if err != nil {
-		return fmt.Errorf("failed to check existing SBOM: %w", err)
-	} else {
-		// Update existing SBOM
-		query := `
-			UPDATE sboms 
-			SET name = ?, components = ?, metadata = ?, updated_at = ?
-			WHERE id = ?
-		`
-		_, err = r.db.ExecContext(ctx, query, sbom.Name, string(componentsJSON), string(metadataJSON), now, sbom.ID)
+
+		// Serialize metadata to JSON
+		metadataJSON, err := json.Marshal(sbom.Metadata)
+		if err != nil {
+			return i, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+
+		tagsJSON, err := marshalTags(sbom.Tags)
 		if err != nil {
-			return fmt.Errorf("failed to update SBOM: %w", err)
+			return i, err
+		}
+
+		_, err = stmt.ExecContext(ctx, sbom.ID, sbom.Name, string(componentsJSON), string(servicesJSON), string(metadataJSON), tagsJSON, sbom.Metadata["serialNumber"], now, now)
+		if err != nil {
+			return i, fmt.Errorf("failed to store SBOM: %w", conflictError(err))
 		}
 	}
 
-	return nil
+	return 0, nil
 }
 
 // FindByID retrieves an SBOM document by its unique identifier.
 func (r *SQLiteRepository) FindByID(ctx context.Context, id string) (*core.SBOM, error) {
 	query := `
-		SELECT id, name, components, metadata, created_at, updated_at
+		SELECT id, name, components, services, metadata, tags, created_at, updated_at
 		FROM sboms
 		WHERE id = ?
 	`
 
+	sbom, err := scanSBOM(r.conn(ctx).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil // SBOM not found
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sbom, nil
+}
+
+// FindAll retrieves every stored SBOM document, ordered by creation time.
+func (r *SQLiteRepository) FindAll(ctx context.Context) ([]core.SBOM, error) {
+	query := `
+		SELECT id, name, components, services, metadata, tags, created_at, updated_at
+		FROM sboms
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query SBOMs: %w", err)
+	}
+	defer rows.Close()
+
+	sboms := make([]core.SBOM, 0)
+	for rows.Next() {
+		sbom, err := scanSBOM(rows)
+		if err != nil {
+			return nil, err
+		}
+		sboms = append(sboms, *sbom)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate SBOMs: %w", err)
+	}
+
+	return sboms, nil
+}
+
+// ListRecords returns the ID, name, creation time and tags of every stored
+// SBOM, ordered by creation time.
+func (r *SQLiteRepository) ListRecords(ctx context.Context) ([]storage.SBOMRecord, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, "SELECT id, name, created_at, tags FROM sboms ORDER BY created_at, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query SBOMs: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]storage.SBOMRecord, 0)
+	for rows.Next() {
+		var record storage.SBOMRecord
+		var tagsJSON string
+		if err := rows.Scan(&record.ID, &record.Name, &record.CreatedAt, &tagsJSON); err != nil {
+			return nil, fmt.Errorf("failed to query SBOM: %w", err)
+		}
+		if err := unmarshalTags(tagsJSON, &record.Tags); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate SBOMs: %w", err)
+	}
+
+	return records, nil
+}
+
+// Delete removes the SBOM with the given ID.
+func (r *SQLiteRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM sboms WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete SBOM: %w", err)
+	}
+	return nil
+}
+
+// FindByContentHash returns the ID of the earliest stored SBOM with the given
+// content hash, or an empty string if there is none.
+func (r *SQLiteRepository) FindByContentHash(ctx context.Context, hash string) (string, error) {
+	var id string
+	err := r.conn(ctx).QueryRowContext(ctx, "SELECT id FROM sboms WHERE content_hash = ? ORDER BY created_at LIMIT 1", hash).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find SBOM by content hash: %w", err)
+	}
+	return id, nil
+}
+
+// FindBySerialNumber returns the ID of the earliest stored SBOM whose document
+// has the given serial number, or an empty string if there is none.
+func (r *SQLiteRepository) FindBySerialNumber(ctx context.Context, serialNumber string) (string, error) {
+	var id string
+	err := r.conn(ctx).QueryRowContext(ctx, "SELECT id FROM sboms WHERE serial_number = ? ORDER BY created_at LIMIT 1", serialNumber).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find SBOM by serial number: %w", err)
+	}
+	return id, nil
+}
+
+// SetContentHash records the content hash of the SBOM with the given ID, or
+// clears it if hash is empty.
+func (r *SQLiteRepository) SetContentHash(ctx context.Context, id, hash string) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, "UPDATE sboms SET content_hash = NULLIF(?, '') WHERE id = ?", hash, id); err != nil {
+		return fmt.Errorf("failed to set content hash: %w", conflictError(err))
+	}
+	return nil
+}
+
+// conflictError returns err wrapping storage.ErrConflict if it is the
+// violation of a unique index, and err otherwise.
+func conflictError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return fmt.Errorf("%w: %w", storage.ErrConflict, err)
+	}
+	return err
+}
+
+// SetTags replaces the tags of the SBOM with the given ID, reporting whether
+// it is stored.
+func (r *SQLiteRepository) SetTags(ctx context.Context, id string, tags map[string]string) (bool, error) {
+	tagsJSON, err := marshalTags(tags)
+	if err != nil {
+		return false, err
+	}
+	result, err := r.conn(ctx).ExecContext(ctx, "UPDATE sboms SET tags = ?, updated_at = ? WHERE id = ?", tagsJSON, core.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to set tags: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to set tags: %w", err)
+	}
+	return updated > 0, nil
+}
+
+// Restore stores an SBOM with the given creation time, replacing any SBOM
+// with the same ID. It fails with storage.ErrConflict if another SBOM has
+// its serial number.
+func (r *SQLiteRepository) Restore(ctx context.Context, sbom core.SBOM, createdAt time.Time) error {
+	componentsJSON, err := json.Marshal(sbom.Components)
+	if err != nil {
+		return fmt.Errorf("failed to marshal components: %w", err)
+	}
+	servicesJSON, err := json.Marshal(sbom.Services)
+	if err != nil {
+		return fmt.Errorf("failed to marshal services: %w", err)
+	}
+	metadataJSON, err := json.Marshal(sbom.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	tagsJSON, err := marshalTags(sbom.Tags)
+	if err != nil {
+		return err
+	}
+
+	// The restored document may differ from the one whose hash was recorded
+	query := `
+		INSERT INTO sboms (id, name, components, services, metadata, tags, serial_number, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name, components = excluded.components, services = excluded.services,
+			metadata = excluded.metadata, tags = excluded.tags, serial_number = excluded.serial_number,
+			created_at = excluded.created_at, updated_at = excluded.updated_at, content_hash = NULL
+	`
+	_, err = r.conn(ctx).ExecContext(ctx, query, sbom.ID, sbom.Name, string(componentsJSON), string(servicesJSON), string(metadataJSON), tagsJSON, sbom.Metadata["serialNumber"], createdAt, core.Now())
+	if err != nil {
+		return fmt.Errorf("failed to restore SBOM: %w", conflictError(err))
+	}
+	return nil
+}
+
+// AddWatch stores a watch, or returns the existing watch of the same package.
+func (r *SQLiteRepository) AddWatch(ctx context.Context, watch storage.Watch) (storage.Watch, error) {
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO watches (id, purl, note, created_at) VALUES (?, ?, ?, ?) ON CONFLICT(purl) DO NOTHING",
+		watch.ID, watch.PURL, watch.Note, watch.CreatedAt)
+	if err != nil {
+		return storage.Watch{}, fmt.Errorf("failed to store watch: %w", err)
+	}
+
+	var stored storage.Watch
+	err = r.conn(ctx).QueryRowContext(ctx, "SELECT id, purl, note, created_at FROM watches WHERE purl = ?", watch.PURL).
+		Scan(&stored.ID, &stored.PURL, &stored.Note, &stored.CreatedAt)
+	if err != nil {
+		return storage.Watch{}, fmt.Errorf("failed to query watch: %w", err)
+	}
+	return stored, nil
+}
+
+// ListWatches returns every watch, ordered by creation time.
+func (r *SQLiteRepository) ListWatches(ctx context.Context) ([]storage.Watch, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, "SELECT id, purl, note, created_at FROM watches ORDER BY created_at, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watches: %w", err)
+	}
+	defer rows.Close()
+
+	watches := make([]storage.Watch, 0)
+	for rows.Next() {
+		var watch storage.Watch
+		if err := rows.Scan(&watch.ID, &watch.PURL, &watch.Note, &watch.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to query watch: %w", err)
+		}
+		watches = append(watches, watch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate watches: %w", err)
+	}
+
+	return watches, nil
+}
+
+// DeleteWatch removes the watch with the given ID, reporting whether it existed.
+func (r *SQLiteRepository) DeleteWatch(ctx context.Context, id string) (bool, error) {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM watches WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete watch: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete watch: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// SaveIdempotentResponse stores the response to an idempotent request,
+// replacing any stored with the same key.
+func (r *SQLiteRepository) SaveIdempotentResponse(ctx context.Context, response storage.IdempotentResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response header: %w", err)
+	}
+	_, err = r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO idempotent_responses (key, fingerprint, status_code, header, body, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET fingerprint = excluded.fingerprint, status_code = excluded.status_code,
+			header = excluded.header, body = excluded.body, created_at = excluded.created_at`,
+		response.Key, response.Fingerprint, response.StatusCode, string(header), response.Body, response.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// FindIdempotentResponse returns the response stored with the given key
+// since the given time, or nil if there is none.
+func (r *SQLiteRepository) FindIdempotentResponse(ctx context.Context, key string, since time.Time) (*storage.IdempotentResponse, error) {
+	var response storage.IdempotentResponse
+	var header string
+	err := r.conn(ctx).QueryRowContext(ctx,
+		"SELECT key, fingerprint, status_code, header, body, created_at FROM idempotent_responses WHERE key = ?",
+		key).Scan(&response.Key, &response.Fingerprint, &response.StatusCode, &header, &response.Body, &response.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query idempotent response: %w", err)
+	}
+	if response.CreatedAt.Before(since) {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(header), &response.Header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response header: %w", err)
+	}
+	return &response, nil
+}
+
+// ClaimIdempotencyKey stores claim while the request with its key is
+// handled, unless a response stored since the given time or a claim made
+// since claimedSince is stored with the key, which is returned instead.
+func (r *SQLiteRepository) ClaimIdempotencyKey(ctx context.Context, claim storage.IdempotentResponse, since, claimedSince time.Time) (*storage.IdempotentResponse, error) {
+	var stored *storage.IdempotentResponse
+	err := r.InTransaction(ctx, func(ctx context.Context) error {
+		// Expired responses and abandoned claims are replaced
+		result, err := r.conn(ctx).ExecContext(ctx,
+			`INSERT INTO idempotent_responses (key, fingerprint, status_code, header, body, created_at) VALUES (?, ?, 0, '{}', X'', ?)
+			ON CONFLICT(key) DO UPDATE SET fingerprint = excluded.fingerprint, status_code = 0,
+				header = excluded.header, body = excluded.body, created_at = excluded.created_at
+			WHERE idempotent_responses.created_at < CASE WHEN idempotent_responses.status_code = 0 THEN ? ELSE ? END`,
+			claim.Key, claim.Fingerprint, claim.CreatedAt.UTC(), claimedSince.UTC(), since.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		if claimed == 0 {
+			stored, err = r.FindIdempotentResponse(ctx, claim.Key, time.Time{})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// ReleaseIdempotencyKey removes the claim stored with the given key, unless
+// a response has replaced it.
+func (r *SQLiteRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM idempotent_responses WHERE key = ? AND status_code = 0", key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteIdempotentResponses removes the responses stored before the given
+// time. Times are stored in UTC, so that their text compares in order.
+func (r *SQLiteRepository) DeleteIdempotentResponses(ctx context.Context, before time.Time) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM idempotent_responses WHERE created_at < ?", before.UTC()); err != nil {
+		return fmt.Errorf("failed to delete idempotent responses: %w", err)
+	}
+	return nil
+}
+
+// RecordAnalysis updates the lifecycle of the project's findings with the
+// findings of an analysis and records the run, in a single transaction.
+func (r *SQLiteRepository) RecordAnalysis(ctx context.Context, run storage.AnalysisRun, agents []string, findings []storage.FindingRecord) (*storage.AnalysisRun, error) {
+	var recorded *storage.AnalysisRun
+	err := r.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		recorded, err = recordAnalysis(ctx, r.conn(ctx), run, agents, findings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// recordAnalysis records an analysis within the transaction tx.
+func recordAnalysis(ctx context.Context, tx queryer, run storage.AnalysisRun, agents []string, findings []storage.FindingRecord) (*storage.AnalysisRun, error) {
+	// Re-analyzing an older version would reopen the findings fixed since
+	var analyzedAt, newestAt time.Time
+	err := tx.QueryRowContext(ctx, "SELECT created_at FROM sboms WHERE id = ?", run.SBOMID).Scan(&analyzedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to record analysis: %w", err)
+	}
+	err = tx.QueryRowContext(ctx, `
+		SELECT s.created_at FROM analysis_runs r JOIN sboms s ON s.id = r.sbom_id
+		WHERE r.project = ? ORDER BY s.created_at DESC LIMIT 1`, run.Project).Scan(&newestAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to record analysis: %w", err)
+	}
+	if !analyzedAt.IsZero() && analyzedAt.Before(newestAt) {
+		return nil, nil
+	}
+
+	open := make(map[string]string)
+	rows, err := tx.QueryContext(ctx, "SELECT fingerprint, agent_name FROM findings WHERE project = ? AND resolved_at IS NULL", run.Project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query findings: %w", err)
+	}
+	for rows.Next() {
+		var fingerprint, agent string
+		if err := rows.Scan(&fingerprint, &agent); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to query findings: %w", err)
+		}
+		open[fingerprint] = agent
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate findings: %w", err)
+	}
+
+	reported := make(map[string]bool, len(findings))
+	for _, finding := range findings {
+		if reported[finding.Fingerprint] {
+			continue
+		}
+		reported[finding.Fingerprint] = true
+
+		if _, ok := open[finding.Fingerprint]; ok {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE findings SET severity = ?, finding = ?, component = ?, last_seen = ?, last_sbom_id = ?
+				WHERE project = ? AND fingerprint = ?`,
+				finding.Severity, finding.Finding, finding.Component, run.AnalyzedAt, run.SBOMID, run.Project, finding.Fingerprint)
+			run.Recurring++
+		} else {
+			// A resolved finding that is reported again starts a new lifecycle,
+			// in which only a false positive keeps its triage state
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO findings (project, fingerprint, agent_name, severity, finding, component, first_seen, first_sbom_id, last_seen, last_sbom_id)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (project, fingerprint) DO UPDATE SET
+					agent_name = excluded.agent_name, severity = excluded.severity, finding = excluded.finding,
+					component = excluded.component, first_seen = excluded.first_seen, first_sbom_id = excluded.first_sbom_id,
+					last_seen = excluded.last_seen, last_sbom_id = excluded.last_sbom_id,
+					resolved_at = NULL, resolved_sbom_id = '',
+					triage = CASE WHEN findings.triage = 'false-positive' THEN findings.triage ELSE 'new' END,
+					sla_notified_at = NULL`,
+				run.Project, finding.Fingerprint, finding.AgentName, finding.Severity, finding.Finding, finding.Component,
+				run.AnalyzedAt, run.SBOMID, run.AnalyzedAt, run.SBOMID)
+			run.New++
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to store finding: %w", err)
+		}
+	}
+
+	// Only agents that completed can show that a finding is gone
+	completed := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		completed[agent] = true
+	}
+	for fingerprint, agent := range open {
+		if reported[fingerprint] || !completed[agent] {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE findings SET resolved_at = ?, resolved_sbom_id = ? WHERE project = ? AND fingerprint = ?",
+			run.AnalyzedAt, run.SBOMID, run.Project, fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve finding: %w", err)
+		}
+		run.Resolved++
+	}
+	run.Open = len(open) - run.Resolved + run.New
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO analysis_runs (sbom_id, project, analyzed_at, new_findings, recurring_findings, resolved_findings, open_findings)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		run.SBOMID, run.Project, run.AnalyzedAt, run.New, run.Recurring, run.Resolved, run.Open)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record analysis: %w", err)
+	}
+	return &run, nil
+}
+
+// PruneAnalyses deletes the analysis runs recorded before the given time and
+// the findings resolved before it, in a single transaction.
+func (r *SQLiteRepository) PruneAnalyses(ctx context.Context, before time.Time) (int, int, error) {
+	var runs, findings int64
+	err := r.InTransaction(ctx, func(ctx context.Context) error {
+		result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM analysis_runs WHERE analyzed_at < ?", before.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to delete analysis runs: %w", err)
+		}
+		runs, _ = result.RowsAffected()
+
+		result, err = r.conn(ctx).ExecContext(ctx, "DELETE FROM findings WHERE resolved_at IS NOT NULL AND resolved_at < ?", before.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to delete findings: %w", err)
+		}
+		findings, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(runs), int(findings), nil
+}
+
+// AnalysisRuns returns the recorded analyses of a project, oldest first.
+func (r *SQLiteRepository) AnalysisRuns(ctx context.Context, project string) ([]storage.AnalysisRun, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, `
+		SELECT sbom_id, project, analyzed_at, new_findings, recurring_findings, resolved_findings, open_findings
+		FROM analysis_runs WHERE project = ? ORDER BY id`, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analyses: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]storage.AnalysisRun, 0)
+	for rows.Next() {
+		var run storage.AnalysisRun
+		if err := rows.Scan(&run.SBOMID, &run.Project, &run.AnalyzedAt, &run.New, &run.Recurring, &run.Resolved, &run.Open); err != nil {
+			return nil, fmt.Errorf("failed to query analysis: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate analyses: %w", err)
+	}
+
+	return runs, nil
+}
+
+// ProjectFindings returns the tracked findings of a project, ordered by when
+// they were first seen.
+func (r *SQLiteRepository) ProjectFindings(ctx context.Context, project string) ([]storage.FindingRecord, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, `
+		SELECT fingerprint, agent_name, severity, finding, component, first_seen, first_sbom_id,
+			last_seen, last_sbom_id, resolved_at, resolved_sbom_id, triage, assignee, triage_note, triaged_at, sla_notified_at
+		FROM findings WHERE project = ? ORDER BY first_seen, fingerprint`, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query findings: %w", err)
+	}
+	defer rows.Close()
+
+	findings := make([]storage.FindingRecord, 0)
+	for rows.Next() {
+		finding, err := scanFinding(rows)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, *finding)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate findings: %w", err)
+	}
+
+	return findings, nil
+}
+
+// TriageFinding changes the triage state, assignee or note of a tracked
+// finding.
+func (r *SQLiteRepository) TriageFinding(ctx context.Context, project, fingerprint string, update storage.TriageUpdate) (*storage.FindingRecord, error) {
+	var triaged *storage.FindingRecord
+	err := r.InTransaction(ctx, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		finding, err := scanFinding(tx.QueryRowContext(ctx, `
+			SELECT fingerprint, agent_name, severity, finding, component, first_seen, first_sbom_id,
+				last_seen, last_sbom_id, resolved_at, resolved_sbom_id, triage, assignee, triage_note, triaged_at, sla_notified_at
+			FROM findings WHERE project = ? AND fingerprint = ?`, project, fingerprint))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		applyTriage(finding, update)
+		_, err = tx.ExecContext(ctx, "UPDATE findings SET triage = ?, assignee = ?, triage_note = ?, triaged_at = ? WHERE project = ? AND fingerprint = ?",
+			finding.Triage, finding.Assignee, finding.TriageNote, *finding.TriagedAt, project, fingerprint)
+		if err != nil {
+			return fmt.Errorf("failed to triage finding: %w", err)
+		}
+		triaged = finding
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return triaged, nil
+}
+
+// applyTriage applies update to finding, recording when it was triaged.
+func applyTriage(finding *storage.FindingRecord, update storage.TriageUpdate) {
+	if update.State != nil {
+		finding.Triage = *update.State
+	}
+	if update.Assignee != nil {
+		finding.Assignee = *update.Assignee
+	}
+	if update.Note != nil {
+		finding.TriageNote = *update.Note
+	}
+	triagedAt := core.Now().UTC()
+	finding.TriagedAt = &triagedAt
+}
+
+// scanFinding scans a tracked finding from a row of the findings table.
+func scanFinding(row rowScanner) (*storage.FindingRecord, error) {
+	var finding storage.FindingRecord
+	var resolvedAt, triagedAt, notifiedAt sql.NullTime
+	err := row.Scan(&finding.Fingerprint, &finding.AgentName, &finding.Severity, &finding.Finding, &finding.Component,
+		&finding.FirstSeen, &finding.FirstSBOMID, &finding.LastSeen, &finding.LastSBOMID, &resolvedAt, &finding.ResolvedSBOMID,
+		&finding.Triage, &finding.Assignee, &finding.TriageNote, &triagedAt, &notifiedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query finding: %w", err)
+	}
+	finding.Status = storage.FindingOpen
+	if resolvedAt.Valid {
+		finding.Status = storage.FindingResolved
+		finding.ResolvedAt = &resolvedAt.Time
+	}
+	if triagedAt.Valid {
+		finding.TriagedAt = &triagedAt.Time
+	}
+	if notifiedAt.Valid {
+		finding.SLANotifiedAt = &notifiedAt.Time
+	}
+	return &finding, nil
+}
+
+// MarkSLANotified records when the SLA breach of a tracked finding was
+// notified.
+func (r *SQLiteRepository) MarkSLANotified(ctx context.Context, project, fingerprint string, at time.Time) (bool, error) {
+	result, err := r.conn(ctx).ExecContext(ctx, "UPDATE findings SET sla_notified_at = ? WHERE project = ? AND fingerprint = ?", at, project, fingerprint)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark SLA breach: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark SLA breach: %w", err)
+	}
+	return updated > 0, nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSBOM reads a single SBOM row and deserializes its JSON columns.
+// sql.ErrNoRows is returned unwrapped so callers can detect a missing SBOM.
+func scanSBOM(row rowScanner) (*core.SBOM, error) {
 	var sbom core.SBOM
-	var componentsJSON, metadataJSON string
+	var componentsJSON, servicesJSON, metadataJSON, tagsJSON string
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := row.Scan(
 		&sbom.ID,
 		&sbom.Name,
 		&componentsJSON,
+		&servicesJSON,
 		&metadataJSON,
+		&tagsJSON,
 		&createdAt,
 		&updatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, nil // SBOM not found
+		return nil, err
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query SBOM: %w", err)
@@ -141,14 +1008,56 @@ func (r *SQLiteRepository) FindByID(ctx context.Context, id string) (*core.SBOM,
 		return nil, fmt.Errorf("failed to unmarshal components: %w", err)
 	}
 
+	// Deserialize services from JSON
+	if err := json.Unmarshal([]byte(servicesJSON), &sbom.Services); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal services: %w", err)
+	}
+
 	// Deserialize metadata from JSON
 	if err := json.Unmarshal([]byte(metadataJSON), &sbom.Metadata); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
 
+	if err := unmarshalTags(tagsJSON, &sbom.Tags); err != nil {
+		return nil, err
+	}
+
 	return &sbom, nil
 }
 
+// marshalTags encodes tags for the tags column, as {} if there are none.
+func marshalTags(tags map[string]string) (string, error) {
+	if len(tags) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tags: %w", err)
+	}
+	return string(data), nil
+}
+
+// unmarshalTags decodes the tags column, leaving tags nil if there are none.
+func unmarshalTags(data string, tags *map[string]string) error {
+	if data == "{}" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(data), tags); err != nil {
+		return fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+	return nil
+}
+
+// Ping verifies that the database is reachable and its SBOMs can be read.
+func (r *SQLiteRepository) Ping(ctx context.Context) error {
+	var one int
+	err := r.conn(ctx).QueryRowContext(ctx, "SELECT 1 FROM sboms LIMIT 1").Scan(&one)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to query database: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection.
 func (r *SQLiteRepository) Close() error {
 	return r.db.Close()
@@ -156,3 +1065,30 @@ func (r *SQLiteRepository) Close() error {
 
 // Verify that SQLiteRepository implements the storage.Repository interface.
 var _ storage.Repository = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.Pruner interface.
+var _ storage.Pruner = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.Restorer interface.
+var _ storage.Restorer = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.ContentIndex interface.
+var _ storage.ContentIndex = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.SerialIndex interface.
+var _ storage.SerialIndex = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.Watchlist interface.
+var _ storage.Watchlist = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.FindingHistory interface.
+var _ storage.FindingHistory = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.AnalysisPruner interface.
+var _ storage.AnalysisPruner = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.FindingTriage interface.
+var _ storage.FindingTriage = (*SQLiteRepository)(nil)
+
+// Verify that SQLiteRepository implements the storage.SLABreaches interface.
+var _ storage.SLABreaches = (*SQLiteRepository)(nil)
@@ -0,0 +1,323 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRepository(t *testing.T) *SQLiteRepository {
+	t.Helper()
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestSQLiteRepository_Store_KeepsCreationTime(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+	clock := core.NewFixedClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer core.SetClock(clock)()
+
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+	clock.Advance(time.Hour)
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api-v2"}))
+
+	records, err := repo.ListRecords(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "api-v2", records[0].Name)
+	assert.True(t, records[0].CreatedAt.Equal(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestSQLiteRepository_StoreBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+
+	require.NoError(t, repo.StoreBatch(ctx, []core.SBOM{{ID: "sbom-1", Name: "api"}, {ID: "sbom-2", Name: "web"}}))
+	stored, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, stored, 2)
+
+	// A batch that fails part way stores none of its SBOMs
+	unencodable := core.SBOM{ID: "sbom-4", Name: "bad", Components: []core.Component{{Name: "x", Evidence: &core.Evidence{Confidence: math.NaN()}}}}
+	err = repo.StoreBatch(ctx, []core.SBOM{{ID: "sbom-3", Name: "cli"}, unencodable})
+	assert.ErrorContains(t, err, "SBOM 2 of 2 (bad)")
+	stored, err = repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, stored, 2)
+}
+
+func TestSQLiteRepository_SetTags(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Tags: map[string]string{"env": "prod"}}))
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-2", Name: "web"}))
+	found, err := repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod"}, found.Tags)
+
+	ok, err := repo.SetTags(ctx, "sbom-2", map[string]string{"team": "web", "env": "staging"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	records, err := repo.ListRecords(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, map[string]string{"env": "prod"}, records[0].Tags)
+	assert.Equal(t, map[string]string{"team": "web", "env": "staging"}, records[1].Tags)
+
+	ok, err = repo.SetTags(ctx, "sbom-1", nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	found, err = repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Nil(t, found.Tags)
+
+	ok, err = repo.SetTags(ctx, "missing", map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSQLiteRepository_InTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+	failure := errors.New("boom")
+
+	err := repo.InTransaction(ctx, func(ctx context.Context) error {
+		require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+		// Reads within the transaction see its writes, and nested calls join it
+		return repo.InTransaction(ctx, func(ctx context.Context) error {
+			sbom, err := repo.FindByID(ctx, "sbom-1")
+			require.NoError(t, err)
+			require.NotNil(t, sbom)
+			return failure
+		})
+	})
+	assert.ErrorIs(t, err, failure)
+
+	sbom, err := repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Nil(t, sbom)
+}
+
+func TestSQLiteRepository_IdempotentResponses(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	response := storage.IdempotentResponse{
+		Key:         "retry-1",
+		Fingerprint: "abc",
+		StatusCode:  201,
+		Header:      map[string]string{"Content-Type": "application/json"},
+		Body:        []byte(`{"id":"sbom-1"}`),
+		CreatedAt:   created,
+	}
+	require.NoError(t, repo.SaveIdempotentResponse(ctx, response))
+	found, err := repo.FindIdempotentResponse(ctx, "retry-1", created.Add(-time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, response.Header, found.Header)
+	assert.Equal(t, response.Body, found.Body)
+	assert.True(t, found.CreatedAt.Equal(created))
+
+	// Expired responses are not found, and are deleted
+	found, err = repo.FindIdempotentResponse(ctx, "retry-1", created.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	require.NoError(t, repo.DeleteIdempotentResponses(ctx, created.Add(time.Hour)))
+	found, err = repo.FindIdempotentResponse(ctx, "retry-1", created.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestSQLiteRepository_ClaimIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	claim := storage.IdempotentResponse{Key: "retry-1", Fingerprint: "abc", CreatedAt: created}
+
+	stored, err := repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// A second claim finds the first in flight
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(-time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "abc", stored.Fingerprint)
+	assert.Zero(t, stored.StatusCode)
+
+	// An abandoned claim is taken over
+	claim.Fingerprint = "def"
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Released claims are removed, responses are not
+	require.NoError(t, repo.ReleaseIdempotencyKey(ctx, "retry-1"))
+	stored, err = repo.FindIdempotentResponse(ctx, "retry-1", time.Time{})
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+	require.NoError(t, repo.SaveIdempotentResponse(ctx, storage.IdempotentResponse{Key: "retry-1", Fingerprint: "abc", StatusCode: 201, Body: []byte(`{"id":"sbom-1"}`), CreatedAt: created}))
+	require.NoError(t, repo.ReleaseIdempotencyKey(ctx, "retry-1"))
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, 201, stored.StatusCode)
+	assert.Equal(t, []byte(`{"id":"sbom-1"}`), stored.Body)
+
+	// An expired response is replaced by the claim
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(time.Hour), created.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestNewSQLiteRepositoryWithOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel.db")
+
+	// Connections default to WAL mode and wait for each other's locks
+	repo := newRepository(t)
+	var mode string
+	var timeout int
+	require.NoError(t, repo.db.QueryRow("PRAGMA journal_mode").Scan(&mode))
+	require.NoError(t, repo.db.QueryRow("PRAGMA busy_timeout").Scan(&timeout))
+	assert.Equal(t, "wal", mode)
+	assert.Equal(t, 5000, timeout)
+
+	repo, err := NewSQLiteRepositoryWithOptions(path, SQLiteOptions{JournalMode: "delete", BusyTimeout: 250 * time.Millisecond})
+	require.NoError(t, err)
+	defer repo.Close()
+	require.NoError(t, repo.db.QueryRow("PRAGMA journal_mode").Scan(&mode))
+	require.NoError(t, repo.db.QueryRow("PRAGMA busy_timeout").Scan(&timeout))
+	assert.Equal(t, "delete", mode)
+	assert.Equal(t, 250, timeout)
+
+	_, err = NewSQLiteRepositoryWithOptions(path, SQLiteOptions{BusyTimeout: -time.Second})
+	assert.ErrorContains(t, err, "invalid busy timeout")
+}
+
+func TestSQLiteRepository_ConcurrentStores(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+
+	// Concurrent submissions wait for each other rather than failing
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.StoreBatch(ctx, []core.SBOM{{ID: fmt.Sprintf("sbom-%d", i), Name: "api"}})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	stored, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, stored, 20)
+}
+
+func TestSQLiteRepository_ContentHashIsUnique(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+	require.NoError(t, repo.StoreBatch(ctx, []core.SBOM{{ID: "sbom-1", Name: "api"}, {ID: "sbom-2", Name: "api"}}))
+
+	require.NoError(t, repo.SetContentHash(ctx, "sbom-1", "hash-1"))
+	err := repo.SetContentHash(ctx, "sbom-2", "hash-1")
+	assert.ErrorIs(t, err, storage.ErrConflict)
+
+	// Cleared hashes do not conflict
+	require.NoError(t, repo.SetContentHash(ctx, "sbom-2", ""))
+	require.NoError(t, repo.SetContentHash(ctx, "sbom-1", ""))
+	require.NoError(t, repo.SetContentHash(ctx, "sbom-2", "hash-1"))
+	id, err := repo.FindByContentHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sbom-2", id)
+}
+
+func TestSQLiteRepository_UpgradesDuplicateContentHashes(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sentinel.db")
+	repo, err := NewSQLiteRepository(path)
+	require.NoError(t, err)
+	clock := core.NewFixedClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer core.SetClock(clock)()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-2", Name: "api"}))
+	clock.Advance(time.Hour)
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+
+	// Forced resubmissions used to record the hash of the stored copy again
+	_, err = repo.db.Exec("DROP INDEX idx_sboms_content_hash_unique")
+	require.NoError(t, err)
+	_, err = repo.db.Exec("UPDATE sboms SET content_hash = 'hash-1'")
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	repo, err = NewSQLiteRepository(path)
+	require.NoError(t, err)
+	defer repo.Close()
+	id, err := repo.FindByContentHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sbom-2", id)
+	assert.ErrorIs(t, repo.SetContentHash(ctx, "sbom-1", "hash-1"), storage.ErrConflict)
+}
+
+func TestSQLiteRepository_SerialNumberIsUnique(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepository(t)
+	serial := map[string]string{"serialNumber": "urn:uuid:1"}
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Metadata: serial}))
+
+	// The SBOM itself can be stored again, but no other with its serial number
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api-v2", Metadata: serial}))
+	assert.ErrorIs(t, repo.Store(ctx, core.SBOM{ID: "sbom-2", Name: "api", Metadata: serial}), storage.ErrConflict)
+	assert.ErrorIs(t, repo.Restore(ctx, core.SBOM{ID: "sbom-2", Name: "api", Metadata: serial}, core.Now()), storage.ErrConflict)
+
+	// Documents without serial numbers do not conflict
+	require.NoError(t, repo.StoreBatch(ctx, []core.SBOM{{ID: "sbom-3", Name: "web"}, {ID: "sbom-4", Name: "web"}}))
+	records, err := repo.ListRecords(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+
+func TestSQLiteRepository_UpgradesDuplicateSerialNumbers(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sentinel.db")
+	repo, err := NewSQLiteRepository(path)
+	require.NoError(t, err)
+	clock := core.NewFixedClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer core.SetClock(clock)()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-2", Name: "api", Metadata: map[string]string{"serialNumber": "urn:uuid:1"}}))
+	clock.Advance(time.Hour)
+
+	// Racing submissions used to store a document twice
+	_, err = repo.db.Exec("DROP INDEX idx_sboms_serial_number_unique")
+	require.NoError(t, err)
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Metadata: map[string]string{"serialNumber": "urn:uuid:1"}}))
+	require.NoError(t, repo.Close())
+
+	repo, err = NewSQLiteRepository(path)
+	require.NoError(t, err)
+	defer repo.Close()
+	id, err := repo.FindBySerialNumber(ctx, "urn:uuid:1")
+	require.NoError(t, err)
+	assert.Equal(t, "sbom-2", id)
+	assert.ErrorIs(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Metadata: map[string]string{"serialNumber": "urn:uuid:1"}}), storage.ErrConflict)
+}
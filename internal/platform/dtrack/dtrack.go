@@ -0,0 +1,278 @@
+// Package dtrack provides an exporter that pushes SBOMs to a Dependency-Track
+// instance through its REST API.
+package dtrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// Mapping decides which Dependency-Track project an SBOM is uploaded to.
+// Name and Version are templates in which {name} is replaced by the SBOM
+// name, {id} by its ID and any other {key} by the SBOM metadata entry key
+// (empty if missing).
+type Mapping struct {
+	Name    string
+	Version string
+	// ParentUUID optionally nests automatically created projects under an
+	// existing project
+	ParentUUID string
+}
+
+// DefaultMapping uploads each SBOM to the project named after it, without a version.
+func DefaultMapping() Mapping {
+	return Mapping{Name: "{name}"}
+}
+
+// Project identifies a Dependency-Track project by name and version.
+type Project struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// placeholder matches a template placeholder such as {name}.
+var placeholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Project returns the project the mapping assigns to an SBOM.
+func (m Mapping) Project(sbom core.SBOM) Project {
+	expand := func(template string) string {
+		return strings.TrimSpace(placeholder.ReplaceAllStringFunc(template, func(match string) string {
+			switch key := match[1 : len(match)-1]; key {
+			case "name":
+				return sbom.Name
+			case "id":
+				return sbom.ID
+			default:
+				return sbom.Metadata[key]
+			}
+		}))
+	}
+	return Project{Name: expand(m.Name), Version: expand(m.Version)}
+}
+
+// Upload describes an SBOM accepted by Dependency-Track.
+type Upload struct {
+	SBOMID  string  `json:"sbom_id"`
+	Project Project `json:"project"`
+	// Token identifies Dependency-Track's asynchronous processing of the upload
+	Token string `json:"token"`
+}
+
+// Exporter uploads SBOMs to Dependency-Track as CycloneDX documents.
+// Projects that do not exist are created.
+type Exporter struct {
+	baseURL string
+	apiKey  string
+	mapping Mapping
+	client  *http.Client
+}
+
+// NewExporter creates an exporter for the Dependency-Track API server at
+// baseURL, authenticating with an API key that has the BOM_UPLOAD and
+// PROJECT_CREATION_UPLOAD permissions.
+func NewExporter(baseURL, apiKey string, mapping Mapping) *Exporter {
+	return &Exporter{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		mapping: mapping,
+		client:  httpclient.New(60 * time.Second),
+	}
+}
+
+// ExporterFromEnv creates an exporter configured by DTRACK_URL,
+// DTRACK_API_KEY, DTRACK_PROJECT_NAME, DTRACK_PROJECT_VERSION and
+// DTRACK_PARENT_UUID. It returns nil if DTRACK_URL is not set.
+func ExporterFromEnv() (*Exporter, error) {
+	baseURL := os.Getenv("DTRACK_URL")
+	if baseURL == "" {
+		return nil, nil
+	}
+	apiKey := os.Getenv("DTRACK_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("DTRACK_API_KEY is required with DTRACK_URL")
+	}
+
+	mapping := DefaultMapping()
+	if name := os.Getenv("DTRACK_PROJECT_NAME"); name != "" {
+		mapping.Name = name
+	}
+	mapping.Version = os.Getenv("DTRACK_PROJECT_VERSION")
+	mapping.ParentUUID = os.Getenv("DTRACK_PARENT_UUID")
+
+	return NewExporter(baseURL, apiKey, mapping), nil
+}
+
+// WithMapping returns a copy of the exporter using a different project mapping.
+func (e *Exporter) WithMapping(mapping Mapping) *Exporter {
+	copied := *e
+	copied.mapping = mapping
+	return &copied
+}
+
+// Mapping returns the exporter's project mapping.
+func (e *Exporter) Mapping() Mapping {
+	return e.mapping
+}
+
+// bomUploadRequest is the body of PUT /api/v1/bom.
+type bomUploadRequest struct {
+	ProjectName    string `json:"projectName"`
+	ProjectVersion string `json:"projectVersion,omitempty"`
+	ParentUUID     string `json:"parentUUID,omitempty"`
+	AutoCreate     bool   `json:"autoCreate"`
+	BOM            string `json:"bom"`
+}
+
+// Push uploads an SBOM to its mapped project.
+func (e *Exporter) Push(ctx context.Context, sbom core.SBOM) (*Upload, error) {
+	project := e.mapping.Project(sbom)
+	if project.Name == "" {
+		return nil, fmt.Errorf("SBOM %s maps to an empty Dependency-Track project name", sbom.ID)
+	}
+
+	var bom bytes.Buffer
+	if err := ingestion.EncodeCycloneDX(&bom, sbom); err != nil {
+		return nil, err
+	}
+
+	reqBody, err := json.Marshal(bomUploadRequest{
+		ProjectName:    project.Name,
+		ProjectVersion: project.Version,
+		ParentUUID:     e.mapping.ParentUUID,
+		AutoCreate:     true,
+		BOM:            base64.StdEncoding.EncodeToString(bom.Bytes()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, e.baseURL+"/api/v1/bom", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Dependency-Track: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Dependency-Track explains rejections in a short plain text body
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if text := strings.TrimSpace(string(message)); text != "" {
+			return nil, fmt.Errorf("Dependency-Track API returned status %d: %s", resp.StatusCode, text)
+		}
+		return nil, fmt.Errorf("Dependency-Track API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &Upload{SBOMID: sbom.ID, Project: project, Token: result.Token}, nil
+}
+
+// syncingRepository pushes every SBOM stored through it to Dependency-Track.
+type syncingRepository struct {
+	storage.Repository
+	exporter *Exporter
+}
+
+// Syncing wraps repo so that every SBOM stored through it is also pushed to
+// Dependency-Track. A failed push is logged and does not fail the store, as
+// the SBOM can be pushed again with 'sentinel-cli dtrack push'.
+func (e *Exporter) Syncing(repo storage.Repository) storage.Repository {
+	return &syncingRepository{Repository: repo, exporter: e}
+}
+
+// Store implements the storage.Repository interface. Within a transaction
+// the SBOM is pushed once the transaction is committed.
+func (r *syncingRepository) Store(ctx context.Context, sbom core.SBOM) error {
+	if err := r.Repository.Store(ctx, sbom); err != nil {
+		return err
+	}
+
+	if pending, ok := ctx.Value(pendingKey{}).(*[]core.SBOM); ok {
+		*pending = append(*pending, sbom)
+		return nil
+	}
+	r.push(ctx, sbom)
+	return nil
+}
+
+// push pushes a stored SBOM, logging a failure.
+func (r *syncingRepository) push(ctx context.Context, sbom core.SBOM) {
+	// The SBOM is stored, so finish the push even if the client goes away
+	if _, err := r.exporter.Push(context.WithoutCancel(ctx), sbom); err != nil {
+		fmt.Printf("Warning: Failed to push SBOM %s to Dependency-Track: %v\n", sbom.ID, err)
+	}
+}
+
+// pendingKey is the context key of the SBOMs stored within InTransaction.
+type pendingKey struct{}
+
+// InTransaction implements the storage.Transactor interface for
+// repositories that support transactions; others run fn directly. The SBOMs
+// stored within fn are pushed only if it succeeds, so that none rolled back
+// reach Dependency-Track.
+func (r *syncingRepository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pendingKey{}).(*[]core.SBOM); ok {
+		return storage.InTransaction(ctx, r.Repository, fn)
+	}
+
+	var pending []core.SBOM
+	if err := storage.InTransaction(context.WithValue(ctx, pendingKey{}, &pending), r.Repository, fn); err != nil {
+		return err
+	}
+	for _, sbom := range pending {
+		r.push(ctx, sbom)
+	}
+	return nil
+}
+
+// FindByContentHash implements the storage.ContentIndex interface for
+// repositories that record content hashes; others have no duplicates.
+func (r *syncingRepository) FindByContentHash(ctx context.Context, hash string) (string, error) {
+	if index, ok := r.Repository.(storage.ContentIndex); ok {
+		return index.FindByContentHash(ctx, hash)
+	}
+	return "", nil
+}
+
+// SetContentHash implements the storage.ContentIndex interface.
+func (r *syncingRepository) SetContentHash(ctx context.Context, id, hash string) error {
+	if index, ok := r.Repository.(storage.ContentIndex); ok {
+		return index.SetContentHash(ctx, id, hash)
+	}
+	return nil
+}
+
+// FindBySerialNumber implements the storage.SerialIndex interface for
+// repositories that index serial numbers; others find none.
+func (r *syncingRepository) FindBySerialNumber(ctx context.Context, serialNumber string) (string, error) {
+	if index, ok := r.Repository.(storage.SerialIndex); ok {
+		return index.FindBySerialNumber(ctx, serialNumber)
+	}
+	return "", nil
+}
@@ -0,0 +1,211 @@
+package dtrack
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDependencyTrack implements the BOM upload endpoint of the Dependency-Track API.
+type fakeDependencyTrack struct {
+	mu      sync.Mutex
+	uploads []bomUploadRequest
+}
+
+func (f *fakeDependencyTrack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut || r.URL.Path != "/api/v1/bom" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("X-Api-Key") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("The API key is invalid"))
+		return
+	}
+
+	var upload bomUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&upload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.uploads = append(f.uploads, upload)
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]string{"token": "token-1"})
+}
+
+var testSBOM = core.SBOM{
+	ID:         "sbom-1",
+	Name:       "payments-api",
+	Components: []core.Component{{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", License: "MIT"}},
+	Metadata:   map[string]string{"release": "2.3.0"},
+}
+
+func TestMapping_Project(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  Mapping
+		expected Project
+	}{
+		{"default", DefaultMapping(), Project{Name: "payments-api"}},
+		{"metadata version", Mapping{Name: "acme-{name}", Version: "{release}"}, Project{Name: "acme-payments-api", Version: "2.3.0"}},
+		{"missing metadata", Mapping{Name: "{name}", Version: "{commit}"}, Project{Name: "payments-api"}},
+		{"by ID", Mapping{Name: "{name}", Version: "{id}"}, Project{Name: "payments-api", Version: "sbom-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.mapping.Project(testSBOM))
+		})
+	}
+}
+
+func TestExporter_Push(t *testing.T) {
+	fake := &fakeDependencyTrack{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	exporter := NewExporter(server.URL+"/", "secret", Mapping{Name: "{name}", Version: "{release}", ParentUUID: "b3f5c2a0-0000-4000-8000-000000000001"})
+	upload, err := exporter.Push(context.Background(), testSBOM)
+	require.NoError(t, err)
+	assert.Equal(t, &Upload{SBOMID: "sbom-1", Project: Project{Name: "payments-api", Version: "2.3.0"}, Token: "token-1"}, upload)
+
+	require.Len(t, fake.uploads, 1)
+	sent := fake.uploads[0]
+	assert.Equal(t, "payments-api", sent.ProjectName)
+	assert.Equal(t, "2.3.0", sent.ProjectVersion)
+	assert.Equal(t, "b3f5c2a0-0000-4000-8000-000000000001", sent.ParentUUID)
+	assert.True(t, sent.AutoCreate)
+
+	// The BOM is the SBOM as base64-encoded CycloneDX
+	bom, err := base64.StdEncoding.DecodeString(sent.BOM)
+	require.NoError(t, err)
+	parsed, err := ingestion.NewCycloneDXParser().Parse(strings.NewReader(string(bom)))
+	require.NoError(t, err)
+	require.Len(t, parsed.Components, 1)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", parsed.Components[0].PURL)
+	assert.Equal(t, "MIT", parsed.Components[0].License)
+}
+
+func TestExporter_PushErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeDependencyTrack{})
+	defer server.Close()
+
+	_, err := NewExporter(server.URL, "wrong", DefaultMapping()).Push(context.Background(), testSBOM)
+	assert.ErrorContains(t, err, "status 401: The API key is invalid")
+
+	_, err = NewExporter(server.URL, "secret", Mapping{Name: "{team}"}).Push(context.Background(), testSBOM)
+	assert.ErrorContains(t, err, "empty Dependency-Track project name")
+}
+
+// memoryRepository is a minimal storage.Repository for testing.
+type memoryRepository struct {
+	sboms map[string]core.SBOM
+}
+
+func (r *memoryRepository) Store(ctx context.Context, sbom core.SBOM) error {
+	r.sboms[sbom.ID] = sbom
+	return nil
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id string) (*core.SBOM, error) {
+	sbom, ok := r.sboms[id]
+	if !ok {
+		return nil, nil
+	}
+	return &sbom, nil
+}
+
+func (r *memoryRepository) FindAll(ctx context.Context) ([]core.SBOM, error) {
+	var sboms []core.SBOM
+	for _, sbom := range r.sboms {
+		sboms = append(sboms, sbom)
+	}
+	return sboms, nil
+}
+
+func TestExporter_Syncing(t *testing.T) {
+	fake := &fakeDependencyTrack{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	repo := &memoryRepository{sboms: make(map[string]core.SBOM)}
+	syncing := NewExporter(server.URL, "secret", DefaultMapping()).Syncing(repo)
+
+	require.NoError(t, syncing.Store(context.Background(), testSBOM))
+	assert.Contains(t, repo.sboms, "sbom-1")
+	require.Len(t, fake.uploads, 1)
+	assert.Equal(t, "payments-api", fake.uploads[0].ProjectName)
+
+	// A Dependency-Track outage does not fail submissions
+	server.Close()
+	second := testSBOM
+	second.ID = "sbom-2"
+	require.NoError(t, syncing.Store(context.Background(), second))
+	assert.Contains(t, repo.sboms, "sbom-2")
+}
+
+func TestExporter_SyncingTransaction(t *testing.T) {
+	fake := &fakeDependencyTrack{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	repo := memory.NewMemoryRepository()
+	syncing := NewExporter(server.URL, "secret", DefaultMapping()).Syncing(repo)
+	transactor, ok := syncing.(storage.Transactor)
+	require.True(t, ok)
+
+	// SBOMs of a rolled back transaction are not pushed
+	err := transactor.InTransaction(context.Background(), func(ctx context.Context) error {
+		require.NoError(t, syncing.Store(ctx, testSBOM))
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+	assert.Empty(t, fake.uploads)
+	stored, err := repo.FindByID(context.Background(), "sbom-1")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Those of a committed one are, once it is committed
+	err = transactor.InTransaction(context.Background(), func(ctx context.Context) error {
+		require.NoError(t, syncing.Store(ctx, testSBOM))
+		assert.Empty(t, fake.uploads)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, fake.uploads, 1)
+}
+
+func TestExporterFromEnv(t *testing.T) {
+	t.Setenv("DTRACK_URL", "")
+	exporter, err := ExporterFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, exporter)
+
+	t.Setenv("DTRACK_URL", "https://dtrack.example.com")
+	t.Setenv("DTRACK_API_KEY", "")
+	_, err = ExporterFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("DTRACK_API_KEY", "secret")
+	t.Setenv("DTRACK_PROJECT_NAME", "")
+	t.Setenv("DTRACK_PROJECT_VERSION", "{release}")
+	exporter, err = ExporterFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Mapping{Name: "{name}", Version: "{release}"}, exporter.Mapping())
+}
@@ -0,0 +1,260 @@
+// Package httpclient provides an HTTP client that retries transient failures
+// of external services such as Ollama, OSV.dev and intelligence feeds.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for the retry policy and circuit breaker.
+const (
+	DefaultMaxRetries       = 2
+	DefaultBaseDelay        = 200 * time.Millisecond
+	DefaultMaxDelay         = 5 * time.Second
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without contacting a host that has failed
+// repeatedly, until its cooldown has elapsed.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Config controls how a Transport retries requests and trips its circuit breaker.
+type Config struct {
+	// MaxRetries is how many times a failed request is retried; zero disables retries
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled for each further retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff, including delays requested with Retry-After
+	MaxDelay time.Duration
+	// FailureThreshold is how many consecutive failed requests to a host open
+	// its circuit; zero disables circuit breaking
+	FailureThreshold int
+	// Cooldown is how long an open circuit rejects requests before letting one through
+	Cooldown time.Duration
+}
+
+// DefaultConfig returns the default retry policy.
+func DefaultConfig() Config {
+	return Config{
+		MaxRetries:       DefaultMaxRetries,
+		BaseDelay:        DefaultBaseDelay,
+		MaxDelay:         DefaultMaxDelay,
+		FailureThreshold: DefaultFailureThreshold,
+		Cooldown:         DefaultCooldown,
+	}
+}
+
+// ConfigFromEnv returns the default retry policy with HTTP_MAX_RETRIES
+// applied. An invalid value is reported and the default kept.
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	if value := os.Getenv("HTTP_MAX_RETRIES"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			return config, fmt.Errorf("invalid HTTP_MAX_RETRIES %q", value)
+		}
+		config.MaxRetries = retries
+	}
+
+	return config, nil
+}
+
+// Transport is an http.RoundTripper that retries network errors and
+// retryable status codes (429, 502, 503 and 504) with exponential backoff
+// and jitter, honouring Retry-After. Requests with a body are only retried
+// if the body can be replayed, which is the case for bodies created by
+// http.NewRequest from a buffer or reader of bytes or strings.
+//
+// Each host has a circuit breaker: after FailureThreshold consecutive failed
+// requests, further requests fail fast with ErrCircuitOpen until Cooldown
+// has elapsed. It is safe for concurrent use.
+type Transport struct {
+	base   http.RoundTripper
+	config Config
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+
+	// sleep waits between attempts; replaced in tests
+	sleep func(req *http.Request, delay time.Duration) error
+}
+
+// breaker tracks the recent failures of a single host.
+type breaker struct {
+	failures  int
+	openUntil time.Time
+}
+
+// NewTransport wraps base, or http.DefaultTransport if base is nil, with the given retry policy.
+func NewTransport(base http.RoundTripper, config Config) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:     base,
+		config:   config,
+		breakers: make(map[string]*breaker),
+		sleep:    sleepContext,
+	}
+}
+
+var (
+	defaultTransport     *Transport
+	defaultTransportOnce sync.Once
+)
+
+// DefaultTransport returns the Transport shared by every client created with
+// New, so circuit breaker state for a host is shared across agents and the
+// harvester. Its policy comes from ConfigFromEnv.
+func DefaultTransport() *Transport {
+	defaultTransportOnce.Do(func() {
+		config, err := ConfigFromEnv()
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		defaultTransport = NewTransport(http.DefaultTransport, config)
+	})
+	return defaultTransport
+}
+
+// New creates an HTTP client using the shared retrying transport.
+// The timeout bounds a whole request, including its retries.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: DefaultTransport(),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if err := t.allow(host); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		retryable := t.isRetryable(req, resp, err)
+		if !retryable || attempt >= t.config.MaxRetries || !replayable(req) {
+			t.record(host, retryable)
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := t.sleep(req, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryable reports whether an attempt failed in a way worth retrying.
+// Errors caused by the request's own cancellation are not.
+func (t *Transport) isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before the retry following attempt: the
+// server's Retry-After if given, otherwise exponential backoff with full
+// jitter, capped at MaxDelay.
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, t.config.MaxDelay)
+		}
+	}
+
+	delay := t.config.BaseDelay << attempt
+	if delay <= 0 || delay > t.config.MaxDelay {
+		delay = t.config.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// allow rejects requests to a host whose circuit is open.
+func (t *Transport) allow(host string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.breakers[host]
+	if !ok || time.Now().After(b.openUntil) {
+		return nil
+	}
+	return fmt.Errorf("%w for %s until %s", ErrCircuitOpen, host, b.openUntil.Format(time.RFC3339))
+}
+
+// record updates the circuit breaker for host with the outcome of a request.
+// After the cooldown a single failure reopens the circuit.
+func (t *Transport) record(host string, failed bool) {
+	if t.config.FailureThreshold <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !failed {
+		delete(t.breakers, host)
+		return
+	}
+
+	b, ok := t.breakers[host]
+	if !ok {
+		b = &breaker{}
+		t.breakers[host] = b
+	}
+	b.failures++
+	if b.failures >= t.config.FailureThreshold {
+		b.openUntil = time.Now().Add(t.config.Cooldown)
+	}
+}
+
+// replayable reports whether the request body can be sent again.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// sleepContext waits for delay or until the request is cancelled.
+func sleepContext(req *http.Request, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client whose transport records backoff delays instead of sleeping.
+func newTestClient(config Config) (*http.Client, *[]time.Duration) {
+	var delays []time.Duration
+	transport := NewTransport(nil, config)
+	transport.sleep = func(req *http.Request, delay time.Duration) error {
+		delays = append(delays, delay)
+		return nil
+	}
+	return &http.Client{Transport: transport}, &delays
+}
+
+func TestTransport_RetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"package":"lodash"}`, string(body))
+
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	client, delays := newTestClient(DefaultConfig())
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"package":"lodash"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	require.Len(t, *delays, 2)
+	assert.LessOrEqual(t, (*delays)[0], DefaultBaseDelay)
+	assert.Equal(t, time.Second, (*delays)[1])
+}
+
+func TestTransport_GivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(DefaultConfig())
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(DefaultMaxRetries+1), calls.Load())
+}
+
+func TestTransport_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(DefaultConfig())
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTransport_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.MaxRetries = 0
+	config.FailureThreshold = 2
+	client, _ := newTestClient(config)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestTransport_StopsWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseDelay = time.Minute
+	config.MaxDelay = time.Minute
+	client := &http.Client{Transport: NewTransport(nil, config)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	require.NoError(t, err)
+
+	started := time.Now()
+	_, err = client.Do(req)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("HTTP_MAX_RETRIES", "5")
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 5, config.MaxRetries)
+
+	t.Setenv("HTTP_MAX_RETRIES", "-1")
+	config, err = ConfigFromEnv()
+	assert.Error(t, err)
+	assert.Equal(t, DefaultMaxRetries, config.MaxRetries)
+}
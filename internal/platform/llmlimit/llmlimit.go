@@ -0,0 +1,221 @@
+// Package llmlimit provides a process-wide limit on concurrent LLM requests,
+// so that simultaneous analyses queue for a single Ollama instance instead of
+// overwhelming it. Waiting requests are granted round-robin across analysis
+// jobs, so a large SBOM cannot starve the analyses queued behind it.
+package llmlimit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultMaxConcurrency is the number of LLM requests in flight at once
+// unless LLM_MAX_CONCURRENCY says otherwise.
+const DefaultMaxConcurrency = 4
+
+// Limiter bounds the number of LLM requests in flight. It is safe for
+// concurrent use. A nil *Limiter imposes no limit, so callers need not check
+// whether limiting is enabled.
+type Limiter struct {
+	max int
+
+	mu     sync.Mutex
+	active int
+	// queues holds the waiting requests of each job, oldest first
+	queues map[string][]*waiter
+	// jobs holds the jobs with waiting requests in the order they are served
+	jobs []string
+}
+
+// waiter is a request waiting for a slot.
+type waiter struct {
+	ready chan struct{}
+	// granted is set, under mu, once the waiter holds a slot
+	granted bool
+}
+
+// Stats describes the requests of a Limiter.
+type Stats struct {
+	// MaxConcurrency is the configured limit
+	MaxConcurrency int
+	// Active is the number of requests in flight
+	Active int
+	// Waiting is the number of requests queued for a slot
+	Waiting int
+}
+
+// New creates a limiter allowing max requests in flight at once. It returns
+// nil, imposing no limit, if max is zero or less.
+func New(max int) *Limiter {
+	if max <= 0 {
+		return nil
+	}
+	return &Limiter{max: max, queues: make(map[string][]*waiter)}
+}
+
+// MaxConcurrencyFromEnv returns the limit set by LLM_MAX_CONCURRENCY, where 0
+// disables limiting, or DefaultMaxConcurrency. An invalid value is reported
+// and the default kept.
+func MaxConcurrencyFromEnv() (int, error) {
+	value := os.Getenv("LLM_MAX_CONCURRENCY")
+	if value == "" {
+		return DefaultMaxConcurrency, nil
+	}
+	max, err := strconv.Atoi(value)
+	if err != nil || max < 0 {
+		return DefaultMaxConcurrency, fmt.Errorf("invalid LLM_MAX_CONCURRENCY %q", value)
+	}
+	return max, nil
+}
+
+// shared is the process-wide limiter configured by the environment.
+var shared = sync.OnceValue(func() *Limiter {
+	max, err := MaxConcurrencyFromEnv()
+	if err != nil {
+		fmt.Printf("Warning: %v, using %d\n", err, max)
+	}
+	return New(max)
+})
+
+// Shared returns the limiter configured by the environment, created on first
+// use and shared by every agent in the process, or nil if limiting is
+// disabled.
+func Shared() *Limiter {
+	return shared()
+}
+
+// Acquire waits for a slot for a request of the job of ctx and returns a
+// function releasing it, which must be called once the request is done. It
+// returns the context's error if ctx ends first.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.active < l.max && len(l.jobs) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return l.releaser(), nil
+	}
+	job := jobFromContext(ctx)
+	w := &waiter{ready: make(chan struct{})}
+	if len(l.queues[job]) == 0 {
+		l.jobs = append(l.jobs, job)
+	}
+	l.queues[job] = append(l.queues[job], w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaser(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.granted {
+			// The slot was granted as ctx ended; pass it on
+			l.active--
+			l.grant()
+		} else {
+			l.dequeue(job, w)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Stats returns the current number of active and waiting requests.
+func (l *Limiter) Stats() Stats {
+	if l == nil {
+		return Stats{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := Stats{MaxConcurrency: l.max, Active: l.active}
+	for _, queue := range l.queues {
+		stats.Waiting += len(queue)
+	}
+	return stats
+}
+
+// releaser returns a function releasing a slot once, however often it is
+// called.
+func (l *Limiter) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.active--
+			l.grant()
+		})
+	}
+}
+
+// grant hands free slots to waiting requests, taking the oldest request of
+// each job in turn. The caller must hold mu.
+func (l *Limiter) grant() {
+	for l.active < l.max && len(l.jobs) > 0 {
+		job := l.jobs[0]
+		l.jobs = l.jobs[1:]
+
+		queue := l.queues[job]
+		w := queue[0]
+		if len(queue) == 1 {
+			delete(l.queues, job)
+		} else {
+			l.queues[job] = queue[1:]
+			// The job goes to the back of the line for its next request
+			l.jobs = append(l.jobs, job)
+		}
+
+		w.granted = true
+		l.active++
+		close(w.ready)
+	}
+}
+
+// dequeue removes a waiting request that gave up. The caller must hold mu.
+func (l *Limiter) dequeue(job string, w *waiter) {
+	queue := l.queues[job]
+	for i, queued := range queue {
+		if queued == w {
+			queue = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) > 0 {
+		l.queues[job] = queue
+		return
+	}
+	delete(l.queues, job)
+	for i, queued := range l.jobs {
+		if queued == job {
+			l.jobs = append(l.jobs[:i:i], l.jobs[i+1:]...)
+			break
+		}
+	}
+}
+
+// jobKey is the context key of the job LLM requests are made for.
+type jobKey struct{}
+
+// jobCounter numbers the jobs started by NewJob.
+var jobCounter atomic.Uint64
+
+// NewJob returns a context whose LLM requests are scheduled as a job of
+// their own, taking turns with the requests of other jobs. Requests made
+// without a job share one.
+func NewJob(ctx context.Context) context.Context {
+	return context.WithValue(ctx, jobKey{}, strconv.FormatUint(jobCounter.Add(1), 10))
+}
+
+// jobFromContext returns the job of ctx, or "" if it has none.
+func jobFromContext(ctx context.Context) string {
+	job, _ := ctx.Value(jobKey{}).(string)
+	return job
+}
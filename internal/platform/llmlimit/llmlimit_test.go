@@ -0,0 +1,134 @@
+package llmlimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForWaiting waits until n requests are queued.
+func waitForWaiting(t *testing.T, limiter *Limiter, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return limiter.Stats().Waiting == n }, time.Second, time.Millisecond)
+}
+
+func TestLimiter_MaxConcurrency(t *testing.T) {
+	limiter := New(2)
+	ctx := context.Background()
+
+	first, err := limiter.Acquire(ctx)
+	require.NoError(t, err)
+	second, err := limiter.Acquire(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{MaxConcurrency: 2, Active: 2}, limiter.Stats())
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := limiter.Acquire(ctx)
+		if err == nil {
+			close(acquired)
+			release()
+		}
+	}()
+	waitForWaiting(t, limiter, 1)
+
+	first()
+	first() // Releasing twice frees one slot
+	<-acquired
+	second()
+	assert.Eventually(t, func() bool { return limiter.Stats() == Stats{MaxConcurrency: 2} }, time.Second, time.Millisecond)
+}
+
+func TestLimiter_FairAcrossJobs(t *testing.T) {
+	limiter := New(1)
+	hold, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	request := func(ctx context.Context, name string) {
+		defer wg.Done()
+		release, err := limiter.Acquire(ctx)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+		release()
+	}
+
+	// A large job queues first, then a small one
+	large, small := NewJob(context.Background()), NewJob(context.Background())
+	for i, name := range []string{"large-1", "large-2", "large-3"} {
+		wg.Add(1)
+		go request(large, name)
+		waitForWaiting(t, limiter, i+1)
+	}
+	wg.Add(1)
+	go request(small, "small-1")
+	waitForWaiting(t, limiter, 4)
+
+	hold()
+	wg.Wait()
+	assert.Equal(t, []string{"large-1", "small-1", "large-2", "large-3"}, order)
+}
+
+func TestLimiter_Cancelled(t *testing.T) {
+	limiter := New(1)
+	hold, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := limiter.Acquire(NewJob(ctx))
+		done <- err
+	}()
+	waitForWaiting(t, limiter, 1)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 0, limiter.Stats().Waiting)
+
+	// The slot goes to the next request, not the one that gave up
+	hold()
+	release, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, 0, limiter.Stats().Active)
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	limiter := New(0)
+	assert.Nil(t, limiter)
+
+	release, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, Stats{}, limiter.Stats())
+}
+
+func TestMaxConcurrencyFromEnv(t *testing.T) {
+	t.Setenv("LLM_MAX_CONCURRENCY", "")
+	max, err := MaxConcurrencyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxConcurrency, max)
+
+	t.Setenv("LLM_MAX_CONCURRENCY", "0")
+	max, err = MaxConcurrencyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 0, max)
+
+	t.Setenv("LLM_MAX_CONCURRENCY", "-1")
+	max, err = MaxConcurrencyFromEnv()
+	assert.Error(t, err)
+	assert.Equal(t, DefaultMaxConcurrency, max)
+}
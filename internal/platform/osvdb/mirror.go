@@ -0,0 +1,346 @@
+// Package osvdb provides a local SQLite mirror of the OSV vulnerability database.
+package osvdb
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DefaultDumpURL is where OSV.dev publishes its per-ecosystem exports;
+// the ecosystem name is substituted for %s.
+const DefaultDumpURL = "https://osv-vulnerabilities.storage.googleapis.com/%s/all.zip"
+
+// Mirror is a local copy of OSV records, synchronized one ecosystem at a time
+// from the OSV.dev bulk exports. Records are stored as raw OSV JSON and
+// indexed by affected package, ID and alias.
+type Mirror struct {
+	db      *sql.DB
+	client  *http.Client
+	dumpURL string
+}
+
+// EcosystemStatus describes a synchronized ecosystem.
+type EcosystemStatus struct {
+	Ecosystem       string    `json:"ecosystem"`
+	SyncedAt        time.Time `json:"synced_at"`
+	Vulnerabilities int       `json:"vulnerabilities"`
+}
+
+// SyncReport summarizes a synchronization of one ecosystem.
+type SyncReport struct {
+	Ecosystem string `json:"ecosystem"`
+	Imported  int    `json:"imported"`
+	Withdrawn int    `json:"withdrawn"`
+}
+
+// osvRecord is the subset of the OSV schema needed to index a record.
+type osvRecord struct {
+	ID        string    `json:"id"`
+	Aliases   []string  `json:"aliases"`
+	Modified  time.Time `json:"modified"`
+	Withdrawn time.Time `json:"withdrawn"`
+	Affected  []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+	} `json:"affected"`
+}
+
+// Open opens the mirror at path, creating it if it does not exist.
+func Open(path string) (*Mirror, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OSV mirror: %w", err)
+	}
+
+	mirror := &Mirror{
+		db:      db,
+		client:  httpclient.New(10 * time.Minute), // Ecosystem dumps can be hundreds of megabytes
+		dumpURL: DefaultDumpURL,
+	}
+
+	if err := mirror.initSchema(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize OSV mirror schema: %w", err)
+	}
+
+	return mirror, nil
+}
+
+// initSchema creates the tables holding the mirrored records.
+func (m *Mirror) initSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS vulnerabilities (
+		id TEXT PRIMARY KEY,
+		modified DATETIME NOT NULL,
+		data TEXT NOT NULL -- raw OSV JSON record
+	);
+
+	CREATE TABLE IF NOT EXISTS affected_packages (
+		vuln_id TEXT NOT NULL,
+		ecosystem TEXT NOT NULL,
+		name TEXT NOT NULL, -- lower-cased package name
+		PRIMARY KEY (vuln_id, ecosystem, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS aliases (
+		alias TEXT NOT NULL,
+		vuln_id TEXT NOT NULL,
+		PRIMARY KEY (alias, vuln_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS ecosystems (
+		ecosystem TEXT PRIMARY KEY,
+		synced_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_affected_packages_name ON affected_packages(ecosystem, name);
+	`
+
+	_, err := m.db.Exec(schema)
+	return err
+}
+
+// Close closes the underlying database.
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+// Sync downloads the OSV.dev export of ecosystem and imports it, replacing
+// records that changed and removing withdrawn ones.
+func (m *Mirror) Sync(ctx context.Context, ecosystem string) (SyncReport, error) {
+	url := fmt.Sprintf(m.dumpURL, ecosystem)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return SyncReport{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "SBOM-Sentinel/1.0")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return SyncReport{}, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return SyncReport{}, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	// Spool the archive to disk rather than holding it in memory
+	tmp, err := os.CreateTemp("", "osv-"+strings.ReplaceAll(ecosystem, "/", "_")+"-*.zip")
+	if err != nil {
+		return SyncReport{}, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, resp.Body)
+	if err != nil {
+		return SyncReport{}, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	return m.Import(ctx, ecosystem, tmp, size)
+}
+
+// Import loads an OSV export archive of ecosystem, as published at
+// DefaultDumpURL, into the mirror in a single transaction.
+func (m *Mirror) Import(ctx context.Context, ecosystem string, archive io.ReaderAt, size int64) (SyncReport, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return SyncReport{}, fmt.Errorf("failed to open OSV dump: %w", err)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return SyncReport{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report := SyncReport{Ecosystem: ecosystem}
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return SyncReport{}, err
+		}
+		if !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+
+		data, err := readFile(file)
+		if err != nil {
+			fmt.Printf("Warning: Skipping OSV record %s: %v\n", file.Name, err)
+			continue
+		}
+
+		var record osvRecord
+		if err := json.Unmarshal(data, &record); err != nil || record.ID == "" {
+			fmt.Printf("Warning: Skipping OSV record %s: invalid record\n", file.Name)
+			continue
+		}
+
+		if err := deleteRecord(ctx, tx, record.ID); err != nil {
+			return SyncReport{}, err
+		}
+		if !record.Withdrawn.IsZero() {
+			report.Withdrawn++
+			continue
+		}
+		if err := insertRecord(ctx, tx, record, data); err != nil {
+			return SyncReport{}, err
+		}
+		report.Imported++
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO ecosystems (ecosystem, synced_at) VALUES (?, ?)`,
+		ecosystem, time.Now().UTC()); err != nil {
+		return SyncReport{}, fmt.Errorf("failed to record sync: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return SyncReport{}, fmt.Errorf("failed to commit OSV records: %w", err)
+	}
+	return report, nil
+}
+
+// readFile reads a single file from the archive.
+func readFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// deleteRecord removes a record and its index entries.
+func deleteRecord(ctx context.Context, tx *sql.Tx, id string) error {
+	for _, query := range []string{
+		`DELETE FROM vulnerabilities WHERE id = ?`,
+		`DELETE FROM affected_packages WHERE vuln_id = ?`,
+		`DELETE FROM aliases WHERE vuln_id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("failed to remove OSV record %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// insertRecord stores a record and indexes its affected packages and aliases.
+func insertRecord(ctx context.Context, tx *sql.Tx, record osvRecord, data []byte) error {
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO vulnerabilities (id, modified, data) VALUES (?, ?, ?)`,
+		record.ID, record.Modified.UTC(), string(data)); err != nil {
+		return fmt.Errorf("failed to store OSV record %s: %w", record.ID, err)
+	}
+
+	for _, affected := range record.Affected {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO affected_packages (vuln_id, ecosystem, name) VALUES (?, ?, ?)`,
+			record.ID, affected.Package.Ecosystem, strings.ToLower(affected.Package.Name)); err != nil {
+			return fmt.Errorf("failed to index OSV record %s: %w", record.ID, err)
+		}
+	}
+
+	for _, alias := range record.Aliases {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO aliases (alias, vuln_id) VALUES (?, ?)`,
+			alias, record.ID); err != nil {
+			return fmt.Errorf("failed to index OSV record %s: %w", record.ID, err)
+		}
+	}
+	return nil
+}
+
+// HasEcosystem reports whether ecosystem has been synchronized.
+func (m *Mirror) HasEcosystem(ctx context.Context, ecosystem string) (bool, error) {
+	var count int
+	err := m.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM ecosystems WHERE ecosystem = ?`, ecosystem).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to query OSV mirror: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Query returns the raw OSV records affecting the named package.
+// Package names are matched case-insensitively.
+func (m *Mirror) Query(ctx context.Context, ecosystem, name string) ([]json.RawMessage, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT v.data FROM vulnerabilities v
+		JOIN affected_packages a ON a.vuln_id = v.id
+		WHERE a.ecosystem = ? AND a.name = ?
+		ORDER BY v.id`,
+		ecosystem, strings.ToLower(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV mirror: %w", err)
+	}
+	defer rows.Close()
+
+	var records []json.RawMessage
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read OSV record: %w", err)
+		}
+		records = append(records, json.RawMessage(data))
+	}
+	return records, rows.Err()
+}
+
+// Get returns the raw OSV record with the given ID or alias, or nil if the
+// mirror has no such record.
+func (m *Mirror) Get(ctx context.Context, id string) (json.RawMessage, error) {
+	var data string
+	err := m.db.QueryRowContext(ctx, `
+		SELECT data FROM vulnerabilities
+		WHERE id = ? OR id IN (SELECT vuln_id FROM aliases WHERE alias = ?)
+		ORDER BY id = ? DESC
+		LIMIT 1`,
+		id, id, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV mirror: %w", err)
+	}
+	return json.RawMessage(data), nil
+}
+
+// Ecosystems lists the synchronized ecosystems with their record counts.
+func (m *Mirror) Ecosystems(ctx context.Context) ([]EcosystemStatus, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT e.ecosystem, e.synced_at, COUNT(DISTINCT a.vuln_id)
+		FROM ecosystems e
+		LEFT JOIN affected_packages a ON a.ecosystem = e.ecosystem
+		GROUP BY e.ecosystem, e.synced_at
+		ORDER BY e.ecosystem`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV mirror: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []EcosystemStatus
+	for rows.Next() {
+		var status EcosystemStatus
+		if err := rows.Scan(&status.Ecosystem, &status.SyncedAt, &status.Vulnerabilities); err != nil {
+			return nil, fmt.Errorf("failed to read OSV mirror status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
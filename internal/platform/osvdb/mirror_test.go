@@ -0,0 +1,141 @@
+package osvdb
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildDump creates an OSV export archive from the given records, keyed by file name.
+func buildDump(t *testing.T, records map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, record := range records {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(record))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func openTestMirror(t *testing.T) *Mirror {
+	t.Helper()
+
+	mirror, err := Open(filepath.Join(t.TempDir(), "osv.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { mirror.Close() })
+	return mirror
+}
+
+func TestMirror_Sync(t *testing.T) {
+	dump := buildDump(t, map[string]string{
+		"GHSA-jf85-cpcp-j695.json": `{
+			"id": "GHSA-jf85-cpcp-j695",
+			"aliases": ["CVE-2019-10744"],
+			"modified": "2024-01-01T00:00:00Z",
+			"affected": [{"package": {"ecosystem": "npm", "name": "Lodash"}}]
+		}`,
+		"GHSA-withdrawn.json": `{
+			"id": "GHSA-withdrawn",
+			"modified": "2024-01-01T00:00:00Z",
+			"withdrawn": "2024-02-01T00:00:00Z",
+			"affected": [{"package": {"ecosystem": "npm", "name": "lodash"}}]
+		}`,
+		"README.txt": "not a record",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/npm/all.zip", r.URL.Path)
+		w.Write(dump)
+	}))
+	defer server.Close()
+
+	mirror := openTestMirror(t)
+	mirror.dumpURL = server.URL + "/%s/all.zip"
+	ctx := context.Background()
+
+	report, err := mirror.Sync(ctx, "npm")
+	require.NoError(t, err)
+	assert.Equal(t, SyncReport{Ecosystem: "npm", Imported: 1, Withdrawn: 1}, report)
+
+	has, err := mirror.HasEcosystem(ctx, "npm")
+	require.NoError(t, err)
+	assert.True(t, has)
+	has, err = mirror.HasEcosystem(ctx, "PyPI")
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	records, err := mirror.Query(ctx, "npm", "lodash")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	var record osvRecord
+	require.NoError(t, json.Unmarshal(records[0], &record))
+	assert.Equal(t, "GHSA-jf85-cpcp-j695", record.ID)
+
+	// Records can be fetched by ID or alias
+	byAlias, err := mirror.Get(ctx, "CVE-2019-10744")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(records[0]), string(byAlias))
+
+	missing, err := mirror.Get(ctx, "GHSA-withdrawn")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	statuses, err := mirror.Ecosystems(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "npm", statuses[0].Ecosystem)
+	assert.Equal(t, 1, statuses[0].Vulnerabilities)
+}
+
+func TestMirror_ImportRemovesWithdrawn(t *testing.T) {
+	mirror := openTestMirror(t)
+	ctx := context.Background()
+
+	first := buildDump(t, map[string]string{
+		"PYSEC-1.json": `{"id": "PYSEC-1", "modified": "2024-01-01T00:00:00Z", "affected": [{"package": {"ecosystem": "PyPI", "name": "requests"}}]}`,
+	})
+	_, err := mirror.Import(ctx, "PyPI", bytes.NewReader(first), int64(len(first)))
+	require.NoError(t, err)
+
+	second := buildDump(t, map[string]string{
+		"PYSEC-1.json": `{"id": "PYSEC-1", "modified": "2024-03-01T00:00:00Z", "withdrawn": "2024-03-01T00:00:00Z"}`,
+	})
+	report, err := mirror.Import(ctx, "PyPI", bytes.NewReader(second), int64(len(second)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Withdrawn)
+
+	records, err := mirror.Query(ctx, "PyPI", "Requests")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestMirror_SyncFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	mirror := openTestMirror(t)
+	mirror.dumpURL = server.URL + "/%s/all.zip"
+
+	_, err := mirror.Sync(context.Background(), "NotAnEcosystem")
+	assert.ErrorContains(t, err, "status 404")
+
+	has, err := mirror.HasEcosystem(context.Background(), "NotAnEcosystem")
+	require.NoError(t, err)
+	assert.False(t, has)
+}
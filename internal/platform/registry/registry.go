@@ -0,0 +1,519 @@
+// Package registry provides a read-only client for container registries
+// implementing the OCI distribution API (Docker Registry HTTP API v2), used
+// to list an image's tags, resolve a tag to its current digest and download
+// artifacts such as SBOMs attached to an image as OCI referrers.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+	packageurl "github.com/hueyexe/SBOM-Sentinel/internal/purl"
+)
+
+// DockerHub is the registry of references without an explicit registry host.
+const DockerHub = "docker.io"
+
+// dockerHubHost serves the registry API of Docker Hub.
+const dockerHubHost = "registry-1.docker.io"
+
+// maxTagPages bounds how many pages of tags are fetched for one repository.
+const maxTagPages = 20
+
+// maxArtifactSize caps the size of a downloaded artifact.
+const maxArtifactSize = 64 << 20
+
+// Media types of the OCI image index and manifest.
+const (
+	IndexMediaType    = "application/vnd.oci.image.index.v1+json"
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// OCIScheme prefixes image references given in place of an SBOM file, as in
+// "oci://ghcr.io/acme/app@sha256:...".
+const OCIScheme = "oci://"
+
+// Sources of an attached SBOM.
+const (
+	SourceReferrer = "referrer"
+	SourceCosign   = "cosign"
+)
+
+// StatusError is returned when a registry answers a request with a status
+// other than 200 OK.
+type StatusError struct {
+	Registry   string
+	Name       string
+	Status     string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("registry %s returned %s for %s", e.Registry, e.Status, e.Name)
+}
+
+// isNotFound reports whether err is a registry's 404 Not Found.
+func isNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// CycloneDXArtifactType is the artifact type of CycloneDX JSON SBOMs
+// attached to images, as by "oras attach" or "cosign attach sbom".
+const CycloneDXArtifactType = "application/vnd.cyclonedx+json"
+
+// Descriptor describes content in a registry, such as an artifact referring
+// to an image.
+type Descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// manifestMediaTypes are accepted when resolving a tag, so that the digest
+// of a multi-platform index is returned rather than that of one platform.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Reference identifies an image in a registry by tag, digest or both.
+type Reference struct {
+	// Registry is the registry host, DockerHub if the reference names none
+	Registry string
+	// Repository is the image path within the registry, e.g. "library/alpine"
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// repositoryPattern matches valid repository paths.
+var repositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+
+// ParseReference parses an image reference such as "alpine:3.19",
+// "ghcr.io/acme/app@sha256:..." or "docker.io/library/node:20-slim".
+// Official Docker Hub images are placed in the "library" namespace.
+func ParseReference(s string) (Reference, error) {
+	var ref Reference
+	name := strings.TrimSpace(s)
+
+	if before, digest, ok := strings.Cut(name, "@"); ok {
+		name, ref.Digest = before, digest
+		if !strings.Contains(digest, ":") {
+			return Reference{}, fmt.Errorf("invalid image reference %q: digest must be algorithm:hex", s)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+		if ref.Tag == "" {
+			return Reference{}, fmt.Errorf("invalid image reference %q: empty tag", s)
+		}
+	}
+
+	// The first path element is a registry host if it looks like one
+	ref.Registry = DockerHub
+	if host, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Registry, name = host, rest
+	}
+	if ref.Registry == "index.docker.io" || ref.Registry == dockerHubHost {
+		ref.Registry = DockerHub
+	}
+	if ref.Registry == DockerHub && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+
+	if !repositoryPattern.MatchString(name) {
+		return Reference{}, fmt.Errorf("invalid image reference %q: invalid repository %q", s, name)
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+// ReferenceFromPURL returns the image reference of an OCI package URL such
+// as "pkg:oci/alpine@sha256%3A...?repository_url=docker.io/library/alpine&tag=3.19".
+// It reports false for other package URLs.
+func ReferenceFromPURL(purl string) (Reference, bool) {
+	parsed, err := packageurl.Parse(purl)
+	if err != nil || parsed.Type != "oci" {
+		return Reference{}, false
+	}
+
+	// repository_url gives the full location; the name alone is on Docker Hub
+	location := parsed.Qualifiers["repository_url"]
+	if location == "" {
+		location = parsed.Name
+	}
+	ref, err := ParseReference(location)
+	if err != nil {
+		return Reference{}, false
+	}
+	if parsed.Version != "" {
+		ref.Digest = parsed.Version
+	}
+	if tag := parsed.Qualifiers["tag"]; tag != "" {
+		ref.Tag = tag
+	}
+	return ref, true
+}
+
+// Name returns the repository in its familiar form, without the Docker Hub
+// registry and "library" namespace, e.g. "alpine" or "ghcr.io/acme/app".
+func (r Reference) Name() string {
+	if r.Registry == DockerHub {
+		return strings.TrimPrefix(r.Repository, "library/")
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the reference in its familiar form, e.g. "alpine:3.19".
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Client reads tags and manifests from container registries, fetching
+// anonymous pull tokens from registries that require them. It is safe for
+// concurrent use.
+type Client struct {
+	httpClient *http.Client
+	scheme     string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewClient creates a registry client.
+func NewClient() *Client {
+	return &Client{
+		httpClient: httpclient.New(30 * time.Second),
+		scheme:     "https",
+		tokens:     make(map[string]string),
+	}
+}
+
+// Tags lists the tags of the referenced repository.
+func (c *Client) Tags(ctx context.Context, ref Reference) ([]string, error) {
+	var tags []string
+
+	next := "/v2/" + ref.Repository + "/tags/list?n=1000"
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		resp, err := c.do(ctx, http.MethodGet, ref, next, "application/json")
+		if err != nil {
+			return nil, err
+		}
+
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tags of %s: %w", ref.Name(), err)
+		}
+		tags = append(tags, list.Tags...)
+		next = nextPage(resp.Header.Get("Link"))
+	}
+
+	return tags, nil
+}
+
+// Digest resolves the reference's tag to the digest it currently points to.
+func (c *Client) Digest(ctx context.Context, ref Reference) (string, error) {
+	if ref.Tag == "" {
+		return "", fmt.Errorf("image reference %s has no tag", ref)
+	}
+
+	resp, err := c.do(ctx, http.MethodHead, ref, "/v2/"+ref.Repository+"/manifests/"+ref.Tag, strings.Join(manifestMediaTypes, ", "))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s returned no digest for %s", ref.Registry, ref)
+	}
+	return digest, nil
+}
+
+// Referrers lists the artifacts, such as SBOMs and signatures, attached to
+// the reference's digest through the OCI referrers API. If artifactType is
+// set, only artifacts of that type are returned.
+func (c *Client) Referrers(ctx context.Context, ref Reference, artifactType string) ([]Descriptor, error) {
+	if ref.Digest == "" {
+		return nil, fmt.Errorf("image reference %s has no digest", ref)
+	}
+
+	path := "/v2/" + ref.Repository + "/referrers/" + ref.Digest
+	if artifactType != "" {
+		path += "?artifactType=" + url.QueryEscape(artifactType)
+	}
+	resp, err := c.do(ctx, http.MethodGet, ref, path, IndexMediaType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var index struct {
+		Manifests []Descriptor `json:"manifests"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxArtifactSize)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to decode referrers of %s: %w", ref, err)
+	}
+
+	// Registries may ignore the filter
+	referrers := make([]Descriptor, 0, len(index.Manifests))
+	for _, descriptor := range index.Manifests {
+		if artifactType == "" || descriptor.ArtifactType == artifactType {
+			referrers = append(referrers, descriptor)
+		}
+	}
+	return referrers, nil
+}
+
+// Artifact downloads the content of the artifact whose manifest has the
+// given digest in the reference's repository: the first layer of the
+// manifest, verified against its digest.
+func (c *Client) Artifact(ctx context.Context, ref Reference, digest string) ([]byte, error) {
+	_, data, err := c.artifact(ctx, ref, digest)
+	return data, err
+}
+
+// AttachedSBOM is a CycloneDX SBOM attached to an image.
+type AttachedSBOM struct {
+	// Digest is the digest of the image the SBOM is attached to
+	Digest string
+	// Source is SourceReferrer or SourceCosign
+	Source string
+	Data   []byte
+}
+
+// AttachedSBOM downloads the CycloneDX SBOM attached to the referenced
+// image: the first OCI referrer of CycloneDXArtifactType or, on registries
+// without the referrers API, an SBOM attached with "cosign attach sbom"
+// under the tag "sha256-<hex>.sbom". Images referenced by tag are resolved
+// to their digest first. It returns nil if no SBOM is attached.
+func (c *Client) AttachedSBOM(ctx context.Context, ref Reference) (*AttachedSBOM, error) {
+	if ref.Digest == "" {
+		digest, err := c.Digest(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		ref.Digest = digest
+	}
+
+	referrers, err := c.Referrers(ctx, ref, CycloneDXArtifactType)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if len(referrers) > 0 {
+		data, err := c.Artifact(ctx, ref, referrers[0].Digest)
+		if err != nil {
+			return nil, err
+		}
+		return &AttachedSBOM{Digest: ref.Digest, Source: SourceReferrer, Data: data}, nil
+	}
+
+	layer, data, err := c.artifact(ctx, ref, strings.Replace(ref.Digest, ":", "-", 1)+".sbom")
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(layer.MediaType, "cyclonedx") {
+		return nil, fmt.Errorf("the SBOM attached to %s with cosign is %s; only CycloneDX is supported", ref, layer.MediaType)
+	}
+	return &AttachedSBOM{Digest: ref.Digest, Source: SourceCosign, Data: data}, nil
+}
+
+// artifact downloads the first layer of the manifest with the given digest
+// or tag, returning its descriptor and content.
+func (c *Client) artifact(ctx context.Context, ref Reference, manifestRef string) (Descriptor, []byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, ref, "/v2/"+ref.Repository+"/manifests/"+manifestRef, ManifestMediaType)
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	var manifest struct {
+		Layers []Descriptor `json:"layers"`
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxArtifactSize)).Decode(&manifest)
+	resp.Body.Close()
+	if err != nil {
+		return Descriptor{}, nil, fmt.Errorf("failed to decode manifest %s of %s: %w", manifestRef, ref.Name(), err)
+	}
+	if len(manifest.Layers) == 0 {
+		return Descriptor{}, nil, fmt.Errorf("artifact %s of %s has no content", manifestRef, ref.Name())
+	}
+	layer := manifest.Layers[0]
+
+	resp, err = c.do(ctx, http.MethodGet, ref, "/v2/"+ref.Repository+"/blobs/"+layer.Digest, "*/*")
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArtifactSize+1))
+	if err != nil {
+		return Descriptor{}, nil, fmt.Errorf("failed to download %s of %s: %w", layer.Digest, ref.Name(), err)
+	}
+	if len(data) > maxArtifactSize {
+		return Descriptor{}, nil, fmt.Errorf("artifact %s of %s exceeds %d bytes", manifestRef, ref.Name(), maxArtifactSize)
+	}
+	if algorithm, want, _ := strings.Cut(layer.Digest, ":"); algorithm == "sha256" {
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != want {
+			return Descriptor{}, nil, fmt.Errorf("digest mismatch for %s of %s", layer.Digest, ref.Name())
+		}
+	}
+	return layer, data, nil
+}
+
+// do sends a request for path on the reference's registry. If the registry
+// challenges for a bearer token, an anonymous pull token is fetched and the
+// request sent again.
+func (c *Client) do(ctx context.Context, method string, ref Reference, path, accept string) (*http.Response, error) {
+	host := ref.Registry
+	if host == DockerHub {
+		host = dockerHubHost
+	}
+	target := c.scheme + "://" + host + path
+	scope := "repository:" + ref.Repository + ":pull"
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create registry request: %w", err)
+		}
+		req.Header.Set("Accept", accept)
+		if token := c.token(host, scope); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reach registry %s: %w", ref.Registry, err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		challenge := resp.Header.Get("WWW-Authenticate")
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 || challenge == "" {
+			return nil, &StatusError{Registry: ref.Registry, Name: ref.Name(), Status: resp.Status, StatusCode: resp.StatusCode}
+		}
+		if err := c.authorize(ctx, host, scope, challenge); err != nil {
+			return nil, fmt.Errorf("failed to authorize with registry %s: %w", ref.Registry, err)
+		}
+	}
+}
+
+// token returns the cached pull token for scope on host.
+func (c *Client) token(host, scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[host+" "+scope]
+}
+
+// authorize fetches an anonymous token answering a Bearer challenge.
+func (c *Client) authorize(ctx context.Context, host, scope, challenge string) error {
+	params, ok := parseBearerChallenge(challenge)
+	if !ok || params["realm"] == "" {
+		return errors.New("registry requires credentials")
+	}
+
+	query := url.Values{"scope": {scope}}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode token: %w", err)
+	}
+	token := body.Token
+	if token == "" {
+		token = body.AccessToken
+	}
+	if token == "" {
+		return errors.New("token endpoint returned no token")
+	}
+
+	c.mu.Lock()
+	c.tokens[host+" "+scope] = token
+	c.mu.Unlock()
+	return nil
+}
+
+// challengeParam matches a key="value" parameter of an authentication challenge.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseBearerChallenge returns the parameters of a Bearer WWW-Authenticate challenge.
+func parseBearerChallenge(challenge string) (map[string]string, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for _, match := range challengeParam.FindAllStringSubmatch(rest, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	return params, true
+}
+
+// nextLink matches the next page in a Link header.
+var nextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="?next"?`)
+
+// nextPage returns the path and query of the next page named by a Link
+// header, or an empty string on the last page.
+func nextPage(link string) string {
+	match := nextLink.FindStringSubmatch(link)
+	if match == nil {
+		return ""
+	}
+	next, err := url.Parse(match[1])
+	if err != nil {
+		return ""
+	}
+	return next.RequestURI()
+}
@@ -0,0 +1,216 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Reference
+		name  string
+	}{
+		{"alpine", Reference{Registry: DockerHub, Repository: "library/alpine"}, "alpine"},
+		{"alpine:3.19.1", Reference{Registry: DockerHub, Repository: "library/alpine", Tag: "3.19.1"}, "alpine"},
+		{"docker.io/library/node:20-slim", Reference{Registry: DockerHub, Repository: "library/node", Tag: "20-slim"}, "node"},
+		{"bitnami/redis:7.2", Reference{Registry: DockerHub, Repository: "bitnami/redis", Tag: "7.2"}, "bitnami/redis"},
+		{"localhost:5000/app@sha256:abc", Reference{Registry: "localhost:5000", Repository: "app", Digest: "sha256:abc"}, "localhost:5000/app"},
+		{"ghcr.io/acme/app:1.4.0@sha256:abc", Reference{Registry: "ghcr.io", Repository: "acme/app", Tag: "1.4.0", Digest: "sha256:abc"}, "ghcr.io/acme/app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ref, err := ParseReference(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ref)
+			assert.Equal(t, tt.name, ref.Name())
+		})
+	}
+
+	for _, invalid := range []string{"", "Alpine:3.19", "alpine:", "alpine@abc"} {
+		_, err := ParseReference(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestReferenceFromPURL(t *testing.T) {
+	ref, ok := ReferenceFromPURL("pkg:oci/alpine@sha256%3A4bcff6?repository_url=index.docker.io/library/alpine&tag=3.19.1&arch=amd64")
+	require.True(t, ok)
+	assert.Equal(t, Reference{Registry: DockerHub, Repository: "library/alpine", Tag: "3.19.1", Digest: "sha256:4bcff6"}, ref)
+	assert.Equal(t, "alpine:3.19.1@sha256:4bcff6", ref.String())
+
+	ref, ok = ReferenceFromPURL("pkg:oci/debian@sha256%3Aabc")
+	require.True(t, ok)
+	assert.Equal(t, "library/debian", ref.Repository)
+
+	_, ok = ReferenceFromPURL("pkg:npm/lodash@4.17.21")
+	assert.False(t, ok)
+}
+
+// fakeRegistry serves the tags of acme/app in two pages and resolves its
+// tags, requiring a bearer token from its token endpoint.
+type fakeRegistry struct {
+	url          string
+	tokenFetches int
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		f.tokenFetches++
+		if r.URL.Query().Get("scope") != "repository:acme/app:pull" || r.URL.Query().Get("service") != "fake" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+f.url+`/token",service="fake",scope="repository:acme/app:pull"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/v2/acme/app/tags/list" && r.URL.Query().Get("last") == "":
+		w.Header().Set("Link", `</v2/acme/app/tags/list?last=1.1.0&n=1000>; rel="next"`)
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "acme/app", "tags": []string{"1.0.0", "1.1.0"}})
+	case r.URL.Path == "/v2/acme/app/tags/list":
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "acme/app", "tags": []string{"1.1.1", "latest"}})
+	case r.Method == http.MethodHead && r.URL.Path == "/v2/acme/app/manifests/1.1.1":
+		if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:111")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestClient(t *testing.T) {
+	fake := &fakeRegistry{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	fake.url = server.URL
+
+	client := NewClient()
+	client.scheme = "http"
+
+	ref, err := ParseReference(strings.TrimPrefix(server.URL, "http://") + "/acme/app:1.1.1")
+	require.NoError(t, err)
+
+	tags, err := client.Tags(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "1.1.1", "latest"}, tags)
+
+	digest, err := client.Digest(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:111", digest)
+
+	// The token is reused for later requests to the repository
+	assert.Equal(t, 1, fake.tokenFetches)
+
+	ref.Tag = "2.0.0"
+	_, err = client.Digest(context.Background(), ref)
+	assert.ErrorContains(t, err, "404 Not Found")
+}
+
+func TestClient_Referrers(t *testing.T) {
+	sbom := []byte(`{"bomFormat":"CycloneDX"}`)
+	sum := sha256.Sum256(sbom)
+	blobDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/acme/app/referrers/sha256:111":
+			// The registry ignores the artifactType filter
+			assert.Equal(t, CycloneDXArtifactType, r.URL.Query().Get("artifactType"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"manifests": []Descriptor{
+				{MediaType: ManifestMediaType, Digest: "sha256:sig", ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json"},
+				{MediaType: ManifestMediaType, Digest: "sha256:sbom", ArtifactType: CycloneDXArtifactType},
+			}})
+		case "/v2/acme/app/manifests/sha256:sbom":
+			json.NewEncoder(w).Encode(map[string]interface{}{"layers": []Descriptor{{Digest: blobDigest}}})
+		case "/v2/acme/app/manifests/sha256:tampered":
+			json.NewEncoder(w).Encode(map[string]interface{}{"layers": []Descriptor{{Digest: "sha256:000"}}})
+		case "/v2/acme/app/blobs/" + blobDigest, "/v2/acme/app/blobs/sha256:000":
+			w.Write(sbom)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.scheme = "http"
+	ref, err := ParseReference(strings.TrimPrefix(server.URL, "http://") + "/acme/app@sha256:111")
+	require.NoError(t, err)
+
+	referrers, err := client.Referrers(context.Background(), ref, CycloneDXArtifactType)
+	require.NoError(t, err)
+	require.Len(t, referrers, 1)
+	assert.Equal(t, "sha256:sbom", referrers[0].Digest)
+
+	data, err := client.Artifact(context.Background(), ref, referrers[0].Digest)
+	require.NoError(t, err)
+	assert.Equal(t, sbom, data)
+
+	_, err = client.Artifact(context.Background(), ref, "sha256:tampered")
+	assert.ErrorContains(t, err, "digest mismatch")
+
+	ref.Digest = ""
+	_, err = client.Referrers(context.Background(), ref, CycloneDXArtifactType)
+	assert.ErrorContains(t, err, "has no digest")
+}
+
+func TestClient_AttachedSBOM_Cosign(t *testing.T) {
+	sbom := []byte(`{"bomFormat":"CycloneDX"}`)
+	sum := sha256.Sum256(sbom)
+	blobDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	// A registry without the referrers API, with an SBOM attached by cosign
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/acme/app/manifests/1.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:111")
+		case "/v2/acme/app/manifests/sha256-111.sbom":
+			json.NewEncoder(w).Encode(map[string]interface{}{"layers": []Descriptor{{MediaType: "application/vnd.cyclonedx+json", Digest: blobDigest}}})
+		case "/v2/acme/app/manifests/sha256-222.sbom":
+			json.NewEncoder(w).Encode(map[string]interface{}{"layers": []Descriptor{{MediaType: "text/spdx+json", Digest: blobDigest}}})
+		case "/v2/acme/app/blobs/" + blobDigest:
+			w.Write(sbom)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.scheme = "http"
+	ref, err := ParseReference(strings.TrimPrefix(server.URL, "http://") + "/acme/app:1.0")
+	require.NoError(t, err)
+
+	attached, err := client.AttachedSBOM(context.Background(), ref)
+	require.NoError(t, err)
+	require.NotNil(t, attached)
+	assert.Equal(t, AttachedSBOM{Digest: "sha256:111", Source: SourceCosign, Data: sbom}, *attached)
+
+	ref.Tag, ref.Digest = "", "sha256:222"
+	_, err = client.AttachedSBOM(context.Background(), ref)
+	assert.ErrorContains(t, err, "only CycloneDX is supported")
+
+	ref.Digest = "sha256:333"
+	attached, err = client.AttachedSBOM(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Nil(t, attached)
+}
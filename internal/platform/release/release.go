@@ -0,0 +1,280 @@
+// Package release provides the download of SBOM Sentinel releases from
+// GitHub, verified against the release's signed checksums, and the
+// replacement of the running binary with the download.
+//
+// Every release publishes a binary per platform, named
+// "sentinel-cli_<os>_<arch>" (with ".exe" on Windows), a checksums.txt file
+// listing the SHA-256 digest of each in sha256sum format, and
+// checksums.txt.sig, the Ed25519 signature of checksums.txt.
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+)
+
+// Defaults of the release source.
+const (
+	DefaultBaseURL    = "https://api.github.com"
+	DefaultRepository = "hueyexe/SBOM-Sentinel"
+)
+
+// Names of the assets verifying a release.
+const (
+	ChecksumsAsset = "checksums.txt"
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// maxAssetSize caps the size of a downloaded asset.
+const maxAssetSize = 256 << 20
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a published GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Version returns the release version, its tag without a leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the asset with the given name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// BinaryName returns the asset name of a program's binary for a platform,
+// e.g. "sentinel-cli_linux_arm64".
+func BinaryName(program, goos, goarch string) string {
+	name := program + "_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// ParsePublicKey decodes a base64-encoded Ed25519 public key.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release key: %d bytes, expected %d", len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Client downloads releases of a GitHub repository.
+type Client struct {
+	baseURL string
+	repo    string
+	client  *http.Client
+	// publicKey verifies the checksums' signature; nil skips the check
+	publicKey ed25519.PublicKey
+}
+
+// NewClient creates a client for the releases of repo ("owner/name") on the
+// GitHub API at baseURL. Downloads are verified against the checksums
+// signed with publicKey or, if it is nil, against the checksums alone.
+func NewClient(baseURL, repo string, publicKey ed25519.PublicKey) *Client {
+	return &Client{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		repo:      repo,
+		client:    httpclient.New(5 * time.Minute),
+		publicKey: publicKey,
+	}
+}
+
+// Latest returns the latest published release.
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	body, err := c.get(ctx, c.baseURL+"/repos/"+c.repo+"/releases/latest", "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the latest release: %w", err)
+	}
+
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, errors.New("the latest release has no tag")
+	}
+	return &release, nil
+}
+
+// Download downloads the named asset of a release and verifies it against
+// the release's checksums and, if the client has a public key, their
+// signature.
+func (c *Client) Download(ctx context.Context, release *Release, name string) ([]byte, error) {
+	checksums, err := c.asset(ctx, release, ChecksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+	if c.publicKey != nil {
+		signature, err := c.asset(ctx, release, SignatureAsset)
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifySignature(c.publicKey, checksums, signature); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := c.asset(ctx, release, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyChecksum(checksums, name, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// asset downloads the named asset of a release.
+func (c *Client) asset(ctx context.Context, release *Release, name string) ([]byte, error) {
+	asset, ok := release.Asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no asset %s", release.TagName, name)
+	}
+	data, err := c.get(ctx, asset.URL, "application/octet-stream")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// get fetches url, returning at most maxAssetSize bytes of the response.
+func (c *Client) get(ctx context.Context, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAssetSize {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxAssetSize)
+	}
+	return data, nil
+}
+
+// VerifySignature checks the Ed25519 signature of the checksums file, given
+// raw or base64-encoded.
+func VerifySignature(publicKey ed25519.PublicKey, checksums, signature []byte) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil || len(decoded) != ed25519.SignatureSize {
+			return fmt.Errorf("%s is not an Ed25519 signature", SignatureAsset)
+		}
+		signature = decoded
+	}
+	if !ed25519.Verify(publicKey, checksums, signature) {
+		return fmt.Errorf("the signature of %s does not match the release key", ChecksumsAsset)
+	}
+	return nil
+}
+
+// VerifyChecksum checks data against the SHA-256 digest that checksums, in
+// sha256sum format, lists for name.
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks binary mode with a "*" before the file name
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s lists no checksum for %s", ChecksumsAsset, name)
+}
+
+// Replace atomically replaces the executable at path with data, keeping
+// its permissions. On Windows, where a running executable cannot be
+// replaced, it is renamed to path + ".old" first.
+func Replace(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	// The new binary is written next to the old one so that the rename
+	// does not cross file systems
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".update-*")
+	if err != nil {
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
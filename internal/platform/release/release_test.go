@@ -0,0 +1,145 @@
+package release
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReleases serves a GitHub release with the given assets.
+func fakeReleases(t *testing.T, assets map[string][]byte) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/acme/sentinel/releases/latest" {
+			release := Release{TagName: "v1.2.0"}
+			for name := range assets {
+				release.Assets = append(release.Assets, Asset{Name: name, URL: server.URL + "/download/" + name})
+			}
+			json.NewEncoder(w).Encode(release)
+			return
+		}
+		data, ok := assets[filepath.Base(r.URL.Path)]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// signedAssets returns release assets for binary, signed with privateKey.
+func signedAssets(binary []byte, privateKey ed25519.PrivateKey) map[string][]byte {
+	sum := sha256.Sum256(binary)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  sentinel-cli_linux_amd64\n")
+	return map[string][]byte{
+		"sentinel-cli_linux_amd64": binary,
+		ChecksumsAsset:             checksums,
+		SignatureAsset:             ed25519.Sign(privateKey, checksums),
+	}
+}
+
+func TestClient_Download(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	server := fakeReleases(t, signedAssets([]byte("new binary"), privateKey))
+
+	client := NewClient(server.URL, "acme/sentinel", publicKey)
+	release, err := client.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", release.Version())
+
+	data, err := client.Download(context.Background(), release, "sentinel-cli_linux_amd64")
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(data))
+
+	_, err = client.Download(context.Background(), release, "sentinel-cli_darwin_arm64")
+	assert.ErrorContains(t, err, "has no asset sentinel-cli_darwin_arm64")
+}
+
+func TestClient_Download_Tampered(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	// A binary that does not match the checksums
+	assets := signedAssets([]byte("new binary"), privateKey)
+	assets["sentinel-cli_linux_amd64"] = []byte("tampered")
+	server := fakeReleases(t, assets)
+	client := NewClient(server.URL, "acme/sentinel", publicKey)
+	release, err := client.Latest(context.Background())
+	require.NoError(t, err)
+	_, err = client.Download(context.Background(), release, "sentinel-cli_linux_amd64")
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	// Checksums signed with another key
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	server = fakeReleases(t, signedAssets([]byte("new binary"), otherKey))
+	client = NewClient(server.URL, "acme/sentinel", publicKey)
+	release, err = client.Latest(context.Background())
+	require.NoError(t, err)
+	_, err = client.Download(context.Background(), release, "sentinel-cli_linux_amd64")
+	assert.ErrorContains(t, err, "does not match the release key")
+
+	// Without a key only the checksums are verified
+	client = NewClient(server.URL, "acme/sentinel", nil)
+	_, err = client.Download(context.Background(), release, "sentinel-cli_linux_amd64")
+	assert.NoError(t, err)
+}
+
+func TestVerifySignature_Base64(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte("checksums")))
+
+	assert.NoError(t, VerifySignature(publicKey, []byte("checksums"), []byte(signature+"\n")))
+	assert.Error(t, VerifySignature(publicKey, []byte("checksums"), []byte("not a signature")))
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	parsed, err := ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey))
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, parsed)
+
+	_, err = ParsePublicKey("c2hvcnQ=")
+	assert.Error(t, err)
+}
+
+func TestBinaryName(t *testing.T) {
+	assert.Equal(t, "sentinel-cli_linux_arm64", BinaryName("sentinel-cli", "linux", "arm64"))
+	assert.Equal(t, "sentinel-cli_windows_amd64.exe", BinaryName("sentinel-cli", "windows", "amd64"))
+}
+
+func TestReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel-cli")
+	require.NoError(t, os.WriteFile(path, []byte("old binary"), 0o750))
+
+	require.NoError(t, Replace(path, []byte("new binary")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o751), info.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
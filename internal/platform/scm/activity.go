@@ -0,0 +1,243 @@
+// Package scm provides a client looking up how actively the source
+// repositories of components on GitHub and GitLab are maintained.
+package scm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Public API endpoints of the code hosts whose repositories are looked up.
+const (
+	GitHubAPIURL = "https://api.github.com"
+	GitLabAPIURL = "https://gitlab.com/api/v4"
+)
+
+// ErrUnsupportedHost is returned for repositories that are not hosted on
+// GitHub or GitLab.
+var ErrUnsupportedHost = errors.New("repository is not hosted on GitHub or GitLab")
+
+// Activity summarizes how actively a repository is maintained.
+type Activity struct {
+	// Repository is the web address of the repository
+	Repository string `json:"repository"`
+	// Archived reports whether the owners marked the repository read-only
+	Archived bool `json:"archived"`
+	// LastCommit is the date of the latest commit on the default branch;
+	// zero if the repository has no commits
+	LastCommit time.Time `json:"last_commit"`
+	// OpenIssues counts the issues still open, leaving out pull requests
+	OpenIssues int `json:"open_issues"`
+	// ClosedIssues counts the issues closed by the maintainers
+	ClosedIssues int `json:"closed_issues"`
+	// Contributors counts the people who committed to the repository
+	Contributors int `json:"contributors"`
+}
+
+// OpenIssueRatio returns the share of the repository's issues that are
+// open, or 0 if it has none.
+func (a Activity) OpenIssueRatio() float64 {
+	total := a.OpenIssues + a.ClosedIssues
+	if total == 0 {
+		return 0
+	}
+	return float64(a.OpenIssues) / float64(total)
+}
+
+// ActivityClient looks up the activity of repositories on github.com and
+// gitlab.com using their REST APIs.
+type ActivityClient struct {
+	githubURL string
+	github    apiClient
+	gitlabURL string
+	gitlab    apiClient
+}
+
+// NewActivityClient creates an ActivityClient querying the GitHub API at
+// githubURL and the GitLab v4 API at gitlabURL. Empty tokens send
+// unauthenticated requests, which the code hosts rate limit more strictly.
+func NewActivityClient(githubURL, githubToken, gitlabURL, gitlabToken string) *ActivityClient {
+	return &ActivityClient{
+		githubURL: strings.TrimRight(githubURL, "/"),
+		github: newAPIClient("GitHub", func(req *http.Request) {
+			if githubToken != "" {
+				req.Header.Set("Authorization", "Bearer "+githubToken)
+			}
+			req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		}),
+		gitlabURL: strings.TrimRight(gitlabURL, "/"),
+		gitlab: newAPIClient("GitLab", func(req *http.Request) {
+			if gitlabToken != "" {
+				req.Header.Set("PRIVATE-TOKEN", gitlabToken)
+			}
+		}),
+	}
+}
+
+// ActivityClientFromEnv creates an ActivityClient for the public GitHub and
+// GitLab APIs, authenticating with GITHUB_TOKEN and GITLAB_TOKEN if set.
+func ActivityClientFromEnv() *ActivityClient {
+	return NewActivityClient(GitHubAPIURL, os.Getenv("GITHUB_TOKEN"), GitLabAPIURL, os.Getenv("GITLAB_TOKEN"))
+}
+
+// Activity looks up the activity of the repository at the given web
+// address, such as "https://github.com/lodash/lodash". It returns
+// ErrUnsupportedHost for repositories on other hosts.
+func (c *ActivityClient) Activity(ctx context.Context, repository string) (*Activity, error) {
+	parsed, err := url.Parse(repository)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URL %q: %w", repository, err)
+	}
+	// Links into a repository, such as to a directory of a monorepo, name
+	// the repository before "/-/" on GitLab and in the first two segments
+	// on GitHub
+	path, _, _ := strings.Cut(strings.Trim(parsed.Path, "/"), "/-/")
+	path = strings.TrimSuffix(path, ".git")
+
+	switch strings.ToLower(parsed.Hostname()) {
+	case "github.com", "www.github.com":
+		segments := strings.Split(path, "/")
+		if len(segments) < 2 || segments[0] == "" || segments[1] == "" {
+			return nil, fmt.Errorf("invalid GitHub repository URL %q", repository)
+		}
+		return c.gitHubActivity(ctx, segments[0]+"/"+strings.TrimSuffix(segments[1], ".git"))
+	case "gitlab.com", "www.gitlab.com":
+		if !strings.Contains(path, "/") {
+			return nil, fmt.Errorf("invalid GitLab repository URL %q", repository)
+		}
+		return c.gitLabActivity(ctx, path)
+	default:
+		return nil, ErrUnsupportedHost
+	}
+}
+
+// gitHubActivity looks up the activity of repo ("owner/name") on GitHub.
+func (c *ActivityClient) gitHubActivity(ctx context.Context, repo string) (*Activity, error) {
+	var repository struct {
+		HTMLURL  string `json:"html_url"`
+		Archived bool   `json:"archived"`
+	}
+	if _, err := c.github.get(ctx, fmt.Sprintf("%s/repos/%s", c.githubURL, repo), &repository); err != nil {
+		return nil, err
+	}
+	activity := &Activity{Repository: repository.HTMLURL, Archived: repository.Archived}
+	if activity.Repository == "" {
+		activity.Repository = "https://github.com/" + repo
+	}
+
+	var commits []struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	// An empty repository has no commits to list
+	if _, err := c.github.get(ctx, fmt.Sprintf("%s/repos/%s/commits?per_page=1", c.githubURL, repo), &commits); err != nil && !hasStatus(err, http.StatusConflict) {
+		return nil, err
+	}
+	if len(commits) > 0 {
+		activity.LastCommit = commits[0].Commit.Committer.Date
+	}
+
+	// Issues are counted with the search API, which unlike the repository's
+	// open_issues_count leaves out pull requests
+	for _, count := range []struct {
+		state string
+		into  *int
+	}{{"open", &activity.OpenIssues}, {"closed", &activity.ClosedIssues}} {
+		var result struct {
+			TotalCount int `json:"total_count"`
+		}
+		query := url.QueryEscape(fmt.Sprintf("repo:%s is:issue is:%s", repo, count.state))
+		if _, err := c.github.get(ctx, fmt.Sprintf("%s/search/issues?q=%s&per_page=1", c.githubURL, query), &result); err != nil {
+			return nil, err
+		}
+		*count.into = result.TotalCount
+	}
+
+	var contributors []struct{}
+	header, err := c.github.get(ctx, fmt.Sprintf("%s/repos/%s/contributors?per_page=1&anon=true", c.githubURL, repo), &contributors)
+	if err != nil {
+		return nil, err
+	}
+	activity.Contributors = len(contributors)
+	if last, ok := lastPage(header.Get("Link")); ok {
+		activity.Contributors = last
+	}
+	return activity, nil
+}
+
+// lastPagePattern matches the link to the last page in a Link header.
+var lastPagePattern = regexp.MustCompile(`<[^>]*[?&]page=(\d+)[^>]*>;\s*rel="last"`)
+
+// lastPage returns the number of the last page named by a Link header, which
+// is the number of items listed one per page.
+func lastPage(link string) (int, bool) {
+	match := lastPagePattern.FindStringSubmatch(link)
+	if match == nil {
+		return 0, false
+	}
+	page, err := strconv.Atoi(match[1])
+	return page, err == nil
+}
+
+// gitLabActivity looks up the activity of the project at path
+// ("group/name", possibly in subgroups) on GitLab.
+func (c *ActivityClient) gitLabActivity(ctx context.Context, path string) (*Activity, error) {
+	projectURL := fmt.Sprintf("%s/projects/%s", c.gitlabURL, url.PathEscape(path))
+	var project struct {
+		WebURL   string `json:"web_url"`
+		Archived bool   `json:"archived"`
+	}
+	if _, err := c.gitlab.get(ctx, projectURL, &project); err != nil {
+		return nil, err
+	}
+	activity := &Activity{Repository: project.WebURL, Archived: project.Archived}
+	if activity.Repository == "" {
+		activity.Repository = "https://gitlab.com/" + path
+	}
+
+	var commits []struct {
+		CommittedDate time.Time `json:"committed_date"`
+	}
+	if _, err := c.gitlab.get(ctx, projectURL+"/repository/commits?per_page=1", &commits); err != nil {
+		return nil, err
+	}
+	if len(commits) > 0 {
+		activity.LastCommit = commits[0].CommittedDate
+	}
+
+	var statistics struct {
+		Statistics struct {
+			Counts struct {
+				Closed int `json:"closed"`
+				Opened int `json:"opened"`
+			} `json:"counts"`
+		} `json:"statistics"`
+	}
+	if _, err := c.gitlab.get(ctx, projectURL+"/issues_statistics", &statistics); err != nil {
+		return nil, err
+	}
+	activity.OpenIssues = statistics.Statistics.Counts.Opened
+	activity.ClosedIssues = statistics.Statistics.Counts.Closed
+
+	var contributors []struct{}
+	header, err := c.gitlab.get(ctx, projectURL+"/repository/contributors?per_page=1", &contributors)
+	if err != nil {
+		return nil, err
+	}
+	activity.Contributors = len(contributors)
+	if total, err := strconv.Atoi(header.Get("X-Total")); err == nil {
+		activity.Contributors = total
+	}
+	return activity, nil
+}
@@ -0,0 +1,128 @@
+package scm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// activityServer serves the endpoints of the GitHub and GitLab APIs the
+// activity client uses, for github.com/acme/left-pad and
+// gitlab.com/acme/tools/lint.
+func activityServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	write := func(w http.ResponseWriter, v any) {
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+
+	mux.HandleFunc("GET /github/repos/acme/left-pad", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gh-secret", r.Header.Get("Authorization"))
+		write(w, map[string]any{"html_url": "https://github.com/acme/left-pad", "archived": true})
+	})
+	mux.HandleFunc("GET /github/repos/acme/left-pad/commits", func(w http.ResponseWriter, r *http.Request) {
+		write(w, []any{map[string]any{"commit": map[string]any{"committer": map[string]any{"date": "2021-03-04T05:06:07Z"}}}})
+	})
+	mux.HandleFunc("GET /github/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("q") {
+		case "repo:acme/left-pad is:issue is:open":
+			write(w, map[string]any{"total_count": 30})
+		case "repo:acme/left-pad is:issue is:closed":
+			write(w, map[string]any{"total_count": 10})
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	})
+	mux.HandleFunc("GET /github/repos/acme/left-pad/contributors", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<https://api.github.com/repositories/1/contributors?per_page=1&anon=true&page=2>; rel="next", `+
+			`<https://api.github.com/repositories/1/contributors?per_page=1&anon=true&page=3>; rel="last"`)
+		write(w, []any{map[string]any{"login": "alice"}})
+	})
+
+	mux.HandleFunc("GET /gitlab/projects/{path}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gl-secret", r.Header.Get("PRIVATE-TOKEN"))
+		assert.Equal(t, "acme/tools/lint", r.PathValue("path"))
+		write(w, map[string]any{"web_url": "https://gitlab.com/acme/tools/lint", "archived": false})
+	})
+	mux.HandleFunc("GET /gitlab/projects/{path}/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		write(w, []any{map[string]any{"committed_date": "2024-05-06T07:08:09Z"}})
+	})
+	mux.HandleFunc("GET /gitlab/projects/{path}/issues_statistics", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]any{"statistics": map[string]any{"counts": map[string]any{"all": 12, "closed": 9, "opened": 3}}})
+	})
+	mux.HandleFunc("GET /gitlab/projects/{path}/repository/contributors", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total", "14")
+		write(w, []any{map[string]any{"name": "bob"}})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestActivityClient_Activity(t *testing.T) {
+	server := activityServer(t)
+	client := NewActivityClient(server.URL+"/github", "gh-secret", server.URL+"/gitlab", "gl-secret")
+	ctx := context.Background()
+
+	activity, err := client.Activity(ctx, "https://github.com/acme/left-pad/tree/main/lib")
+	require.NoError(t, err)
+	assert.Equal(t, &Activity{
+		Repository:   "https://github.com/acme/left-pad",
+		Archived:     true,
+		LastCommit:   time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		OpenIssues:   30,
+		ClosedIssues: 10,
+		Contributors: 3,
+	}, activity)
+	assert.InDelta(t, 0.75, activity.OpenIssueRatio(), 0.001)
+
+	activity, err = client.Activity(ctx, "https://gitlab.com/acme/tools/lint/-/tree/main")
+	require.NoError(t, err)
+	assert.Equal(t, &Activity{
+		Repository:   "https://gitlab.com/acme/tools/lint",
+		LastCommit:   time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		OpenIssues:   3,
+		ClosedIssues: 9,
+		Contributors: 14,
+	}, activity)
+
+	_, err = client.Activity(ctx, "https://bitbucket.org/acme/left-pad")
+	assert.True(t, errors.Is(err, ErrUnsupportedHost))
+	_, err = client.Activity(ctx, "https://github.com/acme")
+	assert.Error(t, err)
+	_, err = client.Activity(ctx, "https://github.com/acme/missing")
+	assert.ErrorContains(t, err, "GitHub API returned status 404")
+}
+
+func TestActivityClient_EmptyRepository(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/empty", func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"), "no token is sent without one")
+		w.Write([]byte(`{"html_url":"https://github.com/acme/empty"}`))
+	})
+	mux.HandleFunc("GET /repos/acme/empty/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"Git Repository is empty."}`))
+	})
+	mux.HandleFunc("GET /search/issues", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total_count":0}`))
+	})
+	mux.HandleFunc("GET /repos/acme/empty/contributors", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	activity, err := NewActivityClient(server.URL, "", server.URL, "").Activity(context.Background(), "https://github.com/acme/empty")
+	require.NoError(t, err)
+	assert.True(t, activity.LastCommit.IsZero())
+	assert.Zero(t, activity.Contributors)
+	assert.Zero(t, activity.OpenIssueRatio())
+}
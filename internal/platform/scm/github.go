@@ -0,0 +1,160 @@
+// Package scm provides a Commenter for GitHub pull requests, using the
+// issue comments API.
+package scm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// perPage is the page size used when listing comments.
+const perPage = 100
+
+// GitHub posts comments on a GitHub pull request.
+type GitHub struct {
+	baseURL string
+	repo    string
+	number  int
+	api     apiClient
+}
+
+// NewGitHub creates a Commenter for pull request number of repo ("owner/name")
+// on the GitHub API at baseURL, authenticating with token, which needs write
+// access to pull requests.
+func NewGitHub(baseURL, repo string, number int, token string) *GitHub {
+	return &GitHub{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		repo:    repo,
+		number:  number,
+		api: newAPIClient("GitHub", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		}),
+	}
+}
+
+// pullRefPattern matches the ref GitHub Actions checks out for a pull request.
+var pullRefPattern = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+// gitHubFromEnv creates a GitHub commenter from the GitHub Actions
+// environment: GITHUB_API_URL, GITHUB_REPOSITORY, GITHUB_TOKEN and the pull
+// request number from the event payload or GITHUB_REF.
+func gitHubFromEnv(number int, token string) (*GitHub, error) {
+	var errs []error
+
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		errs = append(errs, errors.New("a token is required: set --pr-token, SENTINEL_PR_TOKEN or GITHUB_TOKEN"))
+	}
+
+	repo := os.Getenv("GITHUB_REPOSITORY")
+	if repo == "" {
+		errs = append(errs, errors.New("GITHUB_REPOSITORY is not set"))
+	}
+
+	if number == 0 {
+		number = gitHubPullNumber()
+	}
+	if number == 0 {
+		errs = append(errs, errors.New("cannot find the pull request number: not a pull_request event, set --pr"))
+	}
+
+	baseURL := os.Getenv("GITHUB_API_URL")
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return NewGitHub(baseURL, repo, number, token), nil
+}
+
+// gitHubPullNumber finds the number of the pull request that triggered the
+// workflow, or returns 0.
+func gitHubPullNumber() int {
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var event struct {
+				Number      int `json:"number"`
+				PullRequest struct {
+					Number int `json:"number"`
+				} `json:"pull_request"`
+			}
+			if json.Unmarshal(data, &event) == nil {
+				if event.PullRequest.Number > 0 {
+					return event.PullRequest.Number
+				}
+				if event.Number > 0 {
+					return event.Number
+				}
+			}
+		}
+	}
+
+	if match := pullRefPattern.FindStringSubmatch(os.Getenv("GITHUB_REF")); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number
+	}
+	return 0
+}
+
+// gitHubComment is an issue comment in the GitHub API.
+type gitHubComment struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+	Body    string `json:"body"`
+}
+
+func (c gitHubComment) note() *note {
+	return &note{ID: c.ID, URL: c.HTMLURL, Body: c.Body}
+}
+
+// Upsert implements the Commenter interface.
+func (g *GitHub) Upsert(ctx context.Context, key, body string) (*Comment, error) {
+	return upsert(ctx, g, key, body)
+}
+
+func (g *GitHub) list(ctx context.Context) ([]note, error) {
+	var notes []note
+	for page := 1; ; page++ {
+		var comments []gitHubComment
+		url := fmt.Sprintf("%s/repos/%s/issues/%d/comments?per_page=%d&page=%d", g.baseURL, g.repo, g.number, perPage, page)
+		if err := g.api.do(ctx, http.MethodGet, url, nil, &comments); err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			notes = append(notes, *comment.note())
+		}
+		if len(comments) < perPage {
+			return notes, nil
+		}
+	}
+}
+
+func (g *GitHub) create(ctx context.Context, body string) (*note, error) {
+	var comment gitHubComment
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", g.baseURL, g.repo, g.number)
+	if err := g.api.do(ctx, http.MethodPost, url, map[string]string{"body": body}, &comment); err != nil {
+		return nil, err
+	}
+	return comment.note(), nil
+}
+
+func (g *GitHub) update(ctx context.Context, id int64, body string) (*note, error) {
+	var comment gitHubComment
+	url := fmt.Sprintf("%s/repos/%s/issues/comments/%d", g.baseURL, g.repo, id)
+	if err := g.api.do(ctx, http.MethodPatch, url, map[string]string{"body": body}, &comment); err != nil {
+		return nil, err
+	}
+	return comment.note(), nil
+}
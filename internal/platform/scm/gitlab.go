@@ -0,0 +1,139 @@
+// Package scm provides a Commenter for GitLab merge requests, using the
+// merge request notes API.
+package scm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// GitLab posts comments on a GitLab merge request.
+type GitLab struct {
+	baseURL string
+	project string
+	iid     int
+	// webURL is the merge request page, used to link to notes
+	webURL string
+	api    apiClient
+}
+
+// NewGitLab creates a Commenter for merge request iid of project (an ID or
+// a "group/name" path) on the GitLab v4 API at baseURL, authenticating with
+// a token that has the api scope.
+func NewGitLab(baseURL, project string, iid int, token string) *GitLab {
+	return &GitLab{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		project: project,
+		iid:     iid,
+		api: newAPIClient("GitLab", func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}),
+	}
+}
+
+// gitLabFromEnv creates a GitLab commenter from the GitLab CI environment:
+// CI_API_V4_URL, CI_PROJECT_ID, CI_MERGE_REQUEST_IID and GITLAB_TOKEN. The
+// CI job token cannot post notes, so a project or personal access token is needed.
+func gitLabFromEnv(iid int, token string) (*GitLab, error) {
+	var errs []error
+
+	if token == "" {
+		token = os.Getenv("GITLAB_TOKEN")
+	}
+	if token == "" {
+		errs = append(errs, errors.New("a token is required: set --pr-token, SENTINEL_PR_TOKEN or GITLAB_TOKEN"))
+	}
+
+	project := os.Getenv("CI_PROJECT_ID")
+	if project == "" {
+		errs = append(errs, errors.New("CI_PROJECT_ID is not set"))
+	}
+
+	if iid == 0 {
+		number, err := envNumber("CI_MERGE_REQUEST_IID")
+		if err != nil {
+			errs = append(errs, err)
+		}
+		iid = number
+	}
+	if iid == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("cannot find the merge request: not a merge request pipeline, set --pr"))
+	}
+
+	baseURL := os.Getenv("CI_API_V4_URL")
+	if baseURL == "" {
+		baseURL = "https://gitlab.com/api/v4"
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	gitlab := NewGitLab(baseURL, project, iid, token)
+	if projectURL := os.Getenv("CI_PROJECT_URL"); projectURL != "" {
+		gitlab.webURL = fmt.Sprintf("%s/-/merge_requests/%d", projectURL, iid)
+	}
+	return gitlab, nil
+}
+
+// gitLabNote is a merge request note in the GitLab API.
+type gitLabNote struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+func (g *GitLab) note(n gitLabNote) *note {
+	result := &note{ID: n.ID, Body: n.Body}
+	if g.webURL != "" {
+		result.URL = fmt.Sprintf("%s#note_%d", g.webURL, n.ID)
+	}
+	return result
+}
+
+// notesURL returns the URL of the merge request's notes.
+func (g *GitLab) notesURL() string {
+	return fmt.Sprintf("%s/projects/%s/merge_requests/%d/notes", g.baseURL, url.PathEscape(g.project), g.iid)
+}
+
+// Upsert implements the Commenter interface.
+func (g *GitLab) Upsert(ctx context.Context, key, body string) (*Comment, error) {
+	return upsert(ctx, g, key, body)
+}
+
+func (g *GitLab) list(ctx context.Context) ([]note, error) {
+	var notes []note
+	for page := 1; ; page++ {
+		var listed []gitLabNote
+		url := fmt.Sprintf("%s?per_page=%d&page=%d", g.notesURL(), perPage, page)
+		if err := g.api.do(ctx, http.MethodGet, url, nil, &listed); err != nil {
+			return nil, err
+		}
+		for _, n := range listed {
+			notes = append(notes, *g.note(n))
+		}
+		if len(listed) < perPage {
+			return notes, nil
+		}
+	}
+}
+
+func (g *GitLab) create(ctx context.Context, body string) (*note, error) {
+	var created gitLabNote
+	if err := g.api.do(ctx, http.MethodPost, g.notesURL(), map[string]string{"body": body}, &created); err != nil {
+		return nil, err
+	}
+	return g.note(created), nil
+}
+
+func (g *GitLab) update(ctx context.Context, id int64, body string) (*note, error) {
+	var updated gitLabNote
+	url := fmt.Sprintf("%s/%d", g.notesURL(), id)
+	if err := g.api.do(ctx, http.MethodPut, url, map[string]string{"body": body}, &updated); err != nil {
+		return nil, err
+	}
+	return g.note(updated), nil
+}
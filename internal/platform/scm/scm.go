@@ -0,0 +1,237 @@
+// Package scm provides clients that post analysis summaries as comments on
+// GitHub pull requests and GitLab merge requests.
+package scm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+)
+
+// Providers accepted by FromEnv.
+const (
+	ProviderAuto   = "auto"
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Outcomes of Commenter.Upsert.
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+)
+
+// Comment is a comment posted by a Commenter.
+type Comment struct {
+	ID  int64
+	URL string
+	// Action is ActionCreated, ActionUpdated or ActionUnchanged
+	Action string
+}
+
+// Commenter posts a comment on one pull or merge request.
+type Commenter interface {
+	// Upsert posts body as a comment identified by key. A comment posted
+	// earlier with the same key is edited instead of adding another one,
+	// and left alone if its body is unchanged.
+	Upsert(ctx context.Context, key, body string) (*Comment, error)
+}
+
+// Marker returns the hidden HTML comment that identifies the comments
+// posted for key, so that later runs find and update them.
+func Marker(key string) string {
+	// "--" would end the HTML comment early
+	for strings.Contains(key, "--") {
+		key = strings.ReplaceAll(key, "--", "-")
+	}
+	return "<!-- sbom-sentinel:" + key + " -->"
+}
+
+// note is a comment as listed by a provider.
+type note struct {
+	ID   int64
+	URL  string
+	Body string
+}
+
+// provider is the provider-specific part of a Commenter.
+type provider interface {
+	list(ctx context.Context) ([]note, error)
+	create(ctx context.Context, body string) (*note, error)
+	update(ctx context.Context, id int64, body string) (*note, error)
+}
+
+// upsert implements Commenter.Upsert on top of a provider.
+func upsert(ctx context.Context, p provider, key, body string) (*Comment, error) {
+	marker := Marker(key)
+	body = marker + "\n" + body
+
+	notes, err := p.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range notes {
+		if !strings.Contains(existing.Body, marker) {
+			continue
+		}
+		if existing.Body == body {
+			return &Comment{ID: existing.ID, URL: existing.URL, Action: ActionUnchanged}, nil
+		}
+		updated, err := p.update(ctx, existing.ID, body)
+		if err != nil {
+			return nil, err
+		}
+		return &Comment{ID: updated.ID, URL: updated.URL, Action: ActionUpdated}, nil
+	}
+
+	created, err := p.create(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return &Comment{ID: created.ID, URL: created.URL, Action: ActionCreated}, nil
+}
+
+// FromEnv creates a Commenter for the pull or merge request of the current
+// CI job. provider is ProviderGitHub, ProviderGitLab or ProviderAuto, which
+// picks GitHub under GitHub Actions and GitLab under GitLab CI. number
+// overrides the pull or merge request number found in the environment. An
+// empty token falls back to SENTINEL_PR_TOKEN and then to GITHUB_TOKEN or
+// GITLAB_TOKEN.
+func FromEnv(provider string, number int, token string) (Commenter, error) {
+	if provider == "" || provider == ProviderAuto {
+		switch {
+		case os.Getenv("GITHUB_ACTIONS") == "true":
+			provider = ProviderGitHub
+		case os.Getenv("GITLAB_CI") == "true":
+			provider = ProviderGitLab
+		default:
+			return nil, errors.New("cannot detect the code host: not running in GitHub Actions or GitLab CI")
+		}
+	}
+
+	if token == "" {
+		token = os.Getenv("SENTINEL_PR_TOKEN")
+	}
+
+	switch provider {
+	case ProviderGitHub:
+		return gitHubFromEnv(number, token)
+	case ProviderGitLab:
+		return gitLabFromEnv(number, token)
+	default:
+		return nil, fmt.Errorf("unknown code host %q (expected auto, github or gitlab)", provider)
+	}
+}
+
+// envNumber parses a pull or merge request number from the environment
+// variable name, returning 0 if it is not set.
+func envNumber(name string) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return number, nil
+}
+
+// apiClient sends JSON requests to a code host's REST API.
+type apiClient struct {
+	name   string
+	client *http.Client
+	// authorize adds the credentials to a request
+	authorize func(req *http.Request)
+}
+
+// statusError is returned for API responses with a non-2xx status.
+type statusError struct {
+	api     string
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	if e.message != "" {
+		return fmt.Sprintf("%s API returned status %d: %s", e.api, e.status, e.message)
+	}
+	return fmt.Sprintf("%s API returned status %d", e.api, e.status)
+}
+
+// hasStatus reports whether err is an API response with the given status.
+func hasStatus(err error, status int) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.status == status
+}
+
+// newAPIClient creates an API client for the named code host.
+func newAPIClient(name string, authorize func(req *http.Request)) apiClient {
+	return apiClient{name: name, client: httpclient.New(30 * time.Second), authorize: authorize}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out.
+func (c apiClient) do(ctx context.Context, method, url string, body, out any) error {
+	_, err := c.send(ctx, method, url, body, out)
+	return err
+}
+
+// get sends a GET request, decodes the JSON response into out and returns
+// the response headers, which carry pagination totals. A response without
+// content leaves out unchanged.
+func (c apiClient) get(ctx context.Context, url string, out any) (http.Header, error) {
+	return c.send(ctx, http.MethodGet, url, nil, out)
+}
+
+// send implements do and get.
+func (c apiClient) send(ctx context.Context, method, url string, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		reqBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(reqBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &statusError{api: c.name, status: resp.StatusCode, message: strings.TrimSpace(string(message))}
+	}
+
+	if resp.StatusCode == http.StatusNoContent {
+		return resp.Header, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp.Header, nil
+}
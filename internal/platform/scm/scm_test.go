@@ -0,0 +1,257 @@
+package scm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub implements the issue comments endpoints of the GitHub API for
+// pull request 7 of acme/shop.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	comments []gitHubComment
+	writes   int
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var request struct {
+		Body string `json:"body"`
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/issues/7/comments":
+		// Serve a page of perPage comments followed by the rest
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		start := min((page-1)*perPage, len(f.comments))
+		json.NewEncoder(w).Encode(f.comments[start:min(start+perPage, len(f.comments))])
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/issues/7/comments":
+		json.NewDecoder(r.Body).Decode(&request)
+		comment := gitHubComment{ID: int64(1000 + len(f.comments)), Body: request.Body}
+		comment.HTMLURL = fmt.Sprintf("https://github.com/acme/shop/pull/7#issuecomment-%d", comment.ID)
+		f.comments = append(f.comments, comment)
+		f.writes++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(comment)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/acme/shop/issues/comments/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/repos/acme/shop/issues/comments/"), 10, 64)
+		json.NewDecoder(r.Body).Decode(&request)
+		for i := range f.comments {
+			if f.comments[i].ID == id {
+				f.comments[i].Body = request.Body
+				f.writes++
+				json.NewEncoder(w).Encode(f.comments[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGitHub_Upsert(t *testing.T) {
+	fake := &fakeGitHub{}
+	// Fill more than a page with other people's comments
+	for i := 0; i < perPage+5; i++ {
+		fake.comments = append(fake.comments, gitHubComment{ID: int64(i + 1), Body: "LGTM"})
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	github := NewGitHub(server.URL, "acme/shop", 7, "secret")
+	ctx := context.Background()
+
+	comment, err := github.Upsert(ctx, "sbom.json", "1 new finding")
+	require.NoError(t, err)
+	assert.Equal(t, ActionCreated, comment.Action)
+	assert.Equal(t, "https://github.com/acme/shop/pull/7#issuecomment-1105", comment.URL)
+
+	// The same summary is not posted again
+	comment, err = github.Upsert(ctx, "sbom.json", "1 new finding")
+	require.NoError(t, err)
+	assert.Equal(t, ActionUnchanged, comment.Action)
+	assert.Equal(t, 1, fake.writes)
+
+	// A changed summary edits the existing comment
+	comment, err = github.Upsert(ctx, "sbom.json", "No new findings")
+	require.NoError(t, err)
+	assert.Equal(t, ActionUpdated, comment.Action)
+	assert.Equal(t, int64(1105), comment.ID)
+	assert.Len(t, fake.comments, perPage+6)
+	assert.Equal(t, Marker("sbom.json")+"\nNo new findings", fake.comments[perPage+5].Body)
+
+	// Another key gets a comment of its own
+	comment, err = github.Upsert(ctx, "images/api.json", "1 new finding")
+	require.NoError(t, err)
+	assert.Equal(t, ActionCreated, comment.Action)
+	assert.Len(t, fake.comments, perPage+7)
+}
+
+func TestGitHub_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(&fakeGitHub{})
+	defer server.Close()
+
+	_, err := NewGitHub(server.URL, "acme/shop", 7, "wrong").Upsert(context.Background(), "sbom.json", "summary")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GitHub API returned status 401")
+}
+
+// fakeGitLab implements the merge request notes endpoints of the GitLab API
+// for merge request 3 of group/shop.
+type fakeGitLab struct {
+	mu    sync.Mutex
+	notes []gitLabNote
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const notesPath = "/api/v4/projects/group%2Fshop/merge_requests/3/notes"
+	var request struct {
+		Body string `json:"body"`
+	}
+	switch path := r.URL.EscapedPath(); {
+	case r.Method == http.MethodGet && path == notesPath:
+		json.NewEncoder(w).Encode(f.notes)
+	case r.Method == http.MethodPost && path == notesPath:
+		json.NewDecoder(r.Body).Decode(&request)
+		note := gitLabNote{ID: int64(500 + len(f.notes)), Body: request.Body}
+		f.notes = append(f.notes, note)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(note)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, notesPath+"/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(path, notesPath+"/"), 10, 64)
+		json.NewDecoder(r.Body).Decode(&request)
+		for i := range f.notes {
+			if f.notes[i].ID == id {
+				f.notes[i].Body = request.Body
+				json.NewEncoder(w).Encode(f.notes[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGitLab_Upsert(t *testing.T) {
+	fake := &fakeGitLab{notes: []gitLabNote{{ID: 1, Body: "Please rebase"}}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	gitlab := NewGitLab(server.URL+"/api/v4", "group/shop", 3, "secret")
+	ctx := context.Background()
+
+	comment, err := gitlab.Upsert(ctx, "sbom.json", "1 new finding")
+	require.NoError(t, err)
+	assert.Equal(t, ActionCreated, comment.Action)
+
+	comment, err = gitlab.Upsert(ctx, "sbom.json", "No new findings")
+	require.NoError(t, err)
+	assert.Equal(t, ActionUpdated, comment.Action)
+	assert.Equal(t, int64(501), comment.ID)
+	require.Len(t, fake.notes, 2)
+	assert.Equal(t, "Please rebase", fake.notes[0].Body)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Run("GitHub pull request event", func(t *testing.T) {
+		eventPath := filepath.Join(t.TempDir(), "event.json")
+		require.NoError(t, os.WriteFile(eventPath, []byte(`{"action": "opened", "pull_request": {"number": 42}}`), 0o644))
+
+		t.Setenv("GITHUB_ACTIONS", "true")
+		t.Setenv("GITHUB_REPOSITORY", "acme/shop")
+		t.Setenv("GITHUB_EVENT_PATH", eventPath)
+		t.Setenv("GITHUB_TOKEN", "secret")
+		t.Setenv("SENTINEL_PR_TOKEN", "")
+
+		commenter, err := FromEnv(ProviderAuto, 0, "")
+		require.NoError(t, err)
+		github := commenter.(*GitHub)
+		assert.Equal(t, 42, github.number)
+		assert.Equal(t, "https://api.github.com", github.baseURL)
+	})
+
+	t.Run("GitHub ref and explicit number", func(t *testing.T) {
+		t.Setenv("GITHUB_REPOSITORY", "acme/shop")
+		t.Setenv("GITHUB_EVENT_PATH", "")
+		t.Setenv("GITHUB_REF", "refs/pull/9/merge")
+
+		commenter, err := FromEnv(ProviderGitHub, 0, "secret")
+		require.NoError(t, err)
+		assert.Equal(t, 9, commenter.(*GitHub).number)
+
+		commenter, err = FromEnv(ProviderGitHub, 11, "secret")
+		require.NoError(t, err)
+		assert.Equal(t, 11, commenter.(*GitHub).number)
+	})
+
+	t.Run("GitLab merge request pipeline", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("GITLAB_CI", "true")
+		t.Setenv("CI_API_V4_URL", "https://gitlab.example.com/api/v4")
+		t.Setenv("CI_PROJECT_ID", "17")
+		t.Setenv("CI_MERGE_REQUEST_IID", "3")
+		t.Setenv("CI_PROJECT_URL", "https://gitlab.example.com/group/shop")
+		t.Setenv("SENTINEL_PR_TOKEN", "secret")
+
+		commenter, err := FromEnv(ProviderAuto, 0, "")
+		require.NoError(t, err)
+		gitlab := commenter.(*GitLab)
+		assert.Equal(t, 3, gitlab.iid)
+		assert.Equal(t, "https://gitlab.example.com/group/shop/-/merge_requests/3#note_5", gitlab.note(gitLabNote{ID: 5}).URL)
+	})
+
+	t.Run("missing settings", func(t *testing.T) {
+		t.Setenv("GITHUB_REPOSITORY", "")
+		t.Setenv("GITHUB_EVENT_PATH", "")
+		t.Setenv("GITHUB_REF", "refs/heads/main")
+		t.Setenv("GITHUB_TOKEN", "")
+		t.Setenv("SENTINEL_PR_TOKEN", "")
+
+		_, err := FromEnv(ProviderGitHub, 0, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a token is required")
+		assert.Contains(t, err.Error(), "GITHUB_REPOSITORY is not set")
+		assert.Contains(t, err.Error(), "pull request number")
+
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("GITLAB_CI", "")
+		_, err = FromEnv(ProviderAuto, 0, "secret")
+		assert.Error(t, err)
+
+		_, err = FromEnv("bitbucket", 1, "secret")
+		assert.Error(t, err)
+	})
+}
+
+func TestMarker(t *testing.T) {
+	assert.Equal(t, "<!-- sbom-sentinel:sbom.json -->", Marker("sbom.json"))
+	assert.NotContains(t, strings.TrimSuffix(strings.TrimPrefix(Marker("a---b"), "<!--"), "-->"), "--")
+}
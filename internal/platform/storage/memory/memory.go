@@ -0,0 +1,704 @@
+// Package memory provides an in-memory implementation of the storage
+// interfaces, for tests and for running the server without a database.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// MemoryRepository implements storage.Repository and its optional
+// interfaces in memory, behaving like the SQLite repository. Nothing is
+// written to disk, so its contents are lost when the process exits.
+type MemoryRepository struct {
+	mu    sync.Mutex
+	state state
+
+	// txMu serializes transactions with each other; operations outside a
+	// transaction are not isolated from it
+	txMu sync.Mutex
+}
+
+// state holds the contents of the repository.
+type state struct {
+	sboms   map[string]entry
+	watches []storage.Watch
+	// findings maps projects to their findings by fingerprint
+	findings map[string]map[string]storage.FindingRecord
+	runs     map[string][]storage.AnalysisRun
+	// responses maps idempotency keys to their responses
+	responses map[string]storage.IdempotentResponse
+}
+
+// entry is a stored SBOM. The document is kept encoded, as in a database,
+// so that callers cannot modify stored SBOMs through shared slices or maps;
+// its tags are kept apart, so that they can be changed on their own.
+type entry struct {
+	document     []byte
+	name         string
+	tags         map[string]string
+	serialNumber string
+	contentHash  string
+	createdAt    time.Time
+	updatedAt    time.Time
+}
+
+// NewMemoryRepository creates an empty in-memory repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{state: state{
+		sboms:     make(map[string]entry),
+		findings:  make(map[string]map[string]storage.FindingRecord),
+		runs:      make(map[string][]storage.AnalysisRun),
+		responses: make(map[string]storage.IdempotentResponse),
+	}}
+}
+
+// clone returns a copy of s that shares nothing modified in place.
+func (s state) clone() state {
+	c := state{
+		sboms:     make(map[string]entry, len(s.sboms)),
+		watches:   append([]storage.Watch(nil), s.watches...),
+		findings:  make(map[string]map[string]storage.FindingRecord, len(s.findings)),
+		runs:      make(map[string][]storage.AnalysisRun, len(s.runs)),
+		responses: make(map[string]storage.IdempotentResponse, len(s.responses)),
+	}
+	for id, e := range s.sboms {
+		c.sboms[id] = e
+	}
+	for project, findings := range s.findings {
+		copied := make(map[string]storage.FindingRecord, len(findings))
+		for fingerprint, finding := range findings {
+			copied[fingerprint] = finding
+		}
+		c.findings[project] = copied
+	}
+	for project, runs := range s.runs {
+		c.runs[project] = append([]storage.AnalysisRun(nil), runs...)
+	}
+	for key, response := range s.responses {
+		c.responses[key] = response
+	}
+	return c
+}
+
+// txKey is the context key marking calls made within InTransaction.
+type txKey struct{}
+
+// InTransaction calls fn and, if it returns an error, undoes every change
+// made to the repository meanwhile. Calls within fn join its transaction.
+func (r *MemoryRepository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+	r.txMu.Lock()
+	defer r.txMu.Unlock()
+
+	r.mu.Lock()
+	snapshot := r.state.clone()
+	r.mu.Unlock()
+
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		r.mu.Lock()
+		r.state = snapshot
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// encode returns the stored form of an SBOM, keeping what the SQLite
+// repository keeps.
+func encode(sbom core.SBOM) ([]byte, error) {
+	document, err := json.Marshal(core.SBOM{
+		ID:         sbom.ID,
+		Name:       sbom.Name,
+		Components: sbom.Components,
+		Services:   sbom.Services,
+		Metadata:   sbom.Metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SBOM: %w", err)
+	}
+	return document, nil
+}
+
+// decode returns the SBOM of a stored entry.
+func (e entry) decode() (*core.SBOM, error) {
+	var sbom core.SBOM
+	if err := json.Unmarshal(e.document, &sbom); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SBOM: %w", err)
+	}
+	sbom.Tags = maps.Clone(e.tags)
+	return &sbom, nil
+}
+
+// Store persists an SBOM document, replacing any SBOM with the same ID but
+// keeping its creation time and content hash. It fails with
+// storage.ErrConflict if another SBOM has its serial number.
+func (r *MemoryRepository) Store(ctx context.Context, sbom core.SBOM) error {
+	document, err := encode(sbom)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store(sbom, document, core.Now())
+}
+
+// checkSerialNumber fails with storage.ErrConflict if an SBOM other than the
+// one with the given ID has the serial number; r.mu must be held.
+func (r *MemoryRepository) checkSerialNumber(id, serialNumber string) error {
+	if serialNumber == "" {
+		return nil
+	}
+	for otherID, other := range r.state.sboms {
+		if other.serialNumber == serialNumber && otherID != id {
+			return fmt.Errorf("failed to store SBOM: %w", storage.ErrConflict)
+		}
+	}
+	return nil
+}
+
+// store stores an encoded SBOM; r.mu must be held.
+func (r *MemoryRepository) store(sbom core.SBOM, document []byte, now time.Time) error {
+	if err := r.checkSerialNumber(sbom.ID, sbom.Metadata["serialNumber"]); err != nil {
+		return err
+	}
+
+	e, exists := r.state.sboms[sbom.ID]
+	if !exists {
+		e.createdAt = now
+	}
+	e.document = document
+	e.name = sbom.Name
+	e.tags = cloneTags(sbom.Tags)
+	e.serialNumber = sbom.Metadata["serialNumber"]
+	e.updatedAt = now
+	r.state.sboms[sbom.ID] = e
+	return nil
+}
+
+// StoreBatch persists several SBOM documents so that either all of them are
+// stored or, if any cannot be, none.
+func (r *MemoryRepository) StoreBatch(ctx context.Context, sboms []core.SBOM) error {
+	documents := make([][]byte, len(sboms))
+	for i, sbom := range sboms {
+		document, err := encode(sbom)
+		if err != nil {
+			return fmt.Errorf("SBOM %d of %d (%s): %w", i+1, len(sboms), sbom.Name, err)
+		}
+		documents[i] = document
+	}
+
+	now := core.Now()
+	return r.InTransaction(ctx, func(ctx context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i, sbom := range sboms {
+			if err := r.store(sbom, documents[i], now); err != nil {
+				return fmt.Errorf("SBOM %d of %d (%s): %w", i+1, len(sboms), sbom.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindByID retrieves an SBOM document by its unique identifier, or nil if
+// it is not stored.
+func (r *MemoryRepository) FindByID(ctx context.Context, id string) (*core.SBOM, error) {
+	r.mu.Lock()
+	e, ok := r.state.sboms[id]
+	r.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	return e.decode()
+}
+
+// sortedIDs returns the IDs of the stored SBOMs ordered by creation time;
+// r.mu must be held.
+func (r *MemoryRepository) sortedIDs() []string {
+	ids := make([]string, 0, len(r.state.sboms))
+	for id := range r.state.sboms {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := r.state.sboms[ids[i]], r.state.sboms[ids[j]]
+		if !a.createdAt.Equal(b.createdAt) {
+			return a.createdAt.Before(b.createdAt)
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// FindAll retrieves every stored SBOM document, ordered by creation time.
+func (r *MemoryRepository) FindAll(ctx context.Context) ([]core.SBOM, error) {
+	r.mu.Lock()
+	ids := r.sortedIDs()
+	entries := make([]entry, len(ids))
+	for i, id := range ids {
+		entries[i] = r.state.sboms[id]
+	}
+	r.mu.Unlock()
+
+	sboms := make([]core.SBOM, 0, len(entries))
+	for _, e := range entries {
+		sbom, err := e.decode()
+		if err != nil {
+			return nil, err
+		}
+		sboms = append(sboms, *sbom)
+	}
+	return sboms, nil
+}
+
+// ListRecords returns the ID, name, creation time and tags of every stored
+// SBOM, ordered by creation time.
+func (r *MemoryRepository) ListRecords(ctx context.Context) ([]storage.SBOMRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]storage.SBOMRecord, 0, len(r.state.sboms))
+	for _, id := range r.sortedIDs() {
+		e := r.state.sboms[id]
+		records = append(records, storage.SBOMRecord{ID: id, Name: e.name, CreatedAt: e.createdAt, Tags: maps.Clone(e.tags)})
+	}
+	return records, nil
+}
+
+// Delete removes the SBOM with the given ID.
+func (r *MemoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.state.sboms, id)
+	return nil
+}
+
+// findEarliest returns the ID of the earliest stored SBOM matching match, or
+// an empty string if there is none.
+func (r *MemoryRepository) findEarliest(match func(entry) bool) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range r.sortedIDs() {
+		if match(r.state.sboms[id]) {
+			return id
+		}
+	}
+	return ""
+}
+
+// FindByContentHash returns the ID of the earliest stored SBOM with the given
+// content hash, or an empty string if there is none.
+func (r *MemoryRepository) FindByContentHash(ctx context.Context, hash string) (string, error) {
+	return r.findEarliest(func(e entry) bool { return e.contentHash != "" && e.contentHash == hash }), nil
+}
+
+// FindBySerialNumber returns the ID of the earliest stored SBOM whose document
+// has the given serial number, or an empty string if there is none.
+func (r *MemoryRepository) FindBySerialNumber(ctx context.Context, serialNumber string) (string, error) {
+	return r.findEarliest(func(e entry) bool { return e.serialNumber != "" && e.serialNumber == serialNumber }), nil
+}
+
+// SetContentHash records the content hash of the SBOM with the given ID, or
+// clears it if hash is empty.
+func (r *MemoryRepository) SetContentHash(ctx context.Context, id, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for otherID, other := range r.state.sboms {
+		if hash != "" && other.contentHash == hash && otherID != id {
+			return fmt.Errorf("failed to set content hash: %w", storage.ErrConflict)
+		}
+	}
+	if e, ok := r.state.sboms[id]; ok {
+		e.contentHash = hash
+		r.state.sboms[id] = e
+	}
+	return nil
+}
+
+// SetTags replaces the tags of the SBOM with the given ID, reporting whether
+// it is stored.
+func (r *MemoryRepository) SetTags(ctx context.Context, id string, tags map[string]string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.state.sboms[id]
+	if !ok {
+		return false, nil
+	}
+	e.tags = cloneTags(tags)
+	e.updatedAt = core.Now()
+	r.state.sboms[id] = e
+	return true, nil
+}
+
+// cloneTags copies tags, or returns nil if there are none, as the SQLite
+// repository reads them back.
+func cloneTags(tags map[string]string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return maps.Clone(tags)
+}
+
+// Restore stores an SBOM with the given creation time, replacing any SBOM
+// with the same ID. It fails with storage.ErrConflict if another SBOM has
+// its serial number.
+func (r *MemoryRepository) Restore(ctx context.Context, sbom core.SBOM, createdAt time.Time) error {
+	document, err := encode(sbom)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkSerialNumber(sbom.ID, sbom.Metadata["serialNumber"]); err != nil {
+		return err
+	}
+	r.state.sboms[sbom.ID] = entry{
+		document:     document,
+		name:         sbom.Name,
+		tags:         cloneTags(sbom.Tags),
+		serialNumber: sbom.Metadata["serialNumber"],
+		createdAt:    createdAt,
+		updatedAt:    core.Now(),
+	}
+	return nil
+}
+
+// AddWatch stores a watch, or returns the existing watch of the same package.
+func (r *MemoryRepository) AddWatch(ctx context.Context, watch storage.Watch) (storage.Watch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.state.watches {
+		if existing.PURL == watch.PURL {
+			return existing, nil
+		}
+	}
+	r.state.watches = append(r.state.watches, watch)
+	return watch, nil
+}
+
+// ListWatches returns every watch, ordered by creation time.
+func (r *MemoryRepository) ListWatches(ctx context.Context) ([]storage.Watch, error) {
+	r.mu.Lock()
+	watches := append(make([]storage.Watch, 0, len(r.state.watches)), r.state.watches...)
+	r.mu.Unlock()
+
+	sort.SliceStable(watches, func(i, j int) bool {
+		if !watches[i].CreatedAt.Equal(watches[j].CreatedAt) {
+			return watches[i].CreatedAt.Before(watches[j].CreatedAt)
+		}
+		return watches[i].ID < watches[j].ID
+	})
+	return watches, nil
+}
+
+// DeleteWatch removes the watch with the given ID, reporting whether it existed.
+func (r *MemoryRepository) DeleteWatch(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, watch := range r.state.watches {
+		if watch.ID == id {
+			r.state.watches = append(r.state.watches[:i:i], r.state.watches[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveIdempotentResponse stores the response to an idempotent request,
+// replacing any stored with the same key.
+func (r *MemoryRepository) SaveIdempotentResponse(ctx context.Context, response storage.IdempotentResponse) error {
+	response.Header = maps.Clone(response.Header)
+	response.Body = bytes.Clone(response.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.responses[response.Key] = response
+	return nil
+}
+
+// FindIdempotentResponse returns the response stored with the given key
+// since the given time, or nil if there is none.
+func (r *MemoryRepository) FindIdempotentResponse(ctx context.Context, key string, since time.Time) (*storage.IdempotentResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	response, ok := r.state.responses[key]
+	if !ok || response.CreatedAt.Before(since) {
+		return nil, nil
+	}
+	response.Header = maps.Clone(response.Header)
+	response.Body = bytes.Clone(response.Body)
+	return &response, nil
+}
+
+// ClaimIdempotencyKey stores claim while the request with its key is
+// handled, unless a response stored since the given time or a claim made
+// since claimedSince is stored with the key, which is returned instead.
+func (r *MemoryRepository) ClaimIdempotencyKey(ctx context.Context, claim storage.IdempotentResponse, since, claimedSince time.Time) (*storage.IdempotentResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.state.responses[claim.Key]; ok {
+		expired := stored.CreatedAt.Before(since)
+		if stored.StatusCode == 0 {
+			expired = stored.CreatedAt.Before(claimedSince)
+		}
+		if !expired {
+			stored.Header = maps.Clone(stored.Header)
+			stored.Body = bytes.Clone(stored.Body)
+			return &stored, nil
+		}
+	}
+	r.state.responses[claim.Key] = storage.IdempotentResponse{Key: claim.Key, Fingerprint: claim.Fingerprint, CreatedAt: claim.CreatedAt}
+	return nil, nil
+}
+
+// ReleaseIdempotencyKey removes the claim stored with the given key, unless
+// a response has replaced it.
+func (r *MemoryRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.state.responses[key]; ok && stored.StatusCode == 0 {
+		delete(r.state.responses, key)
+	}
+	return nil
+}
+
+// DeleteIdempotentResponses removes the responses stored before the given time.
+func (r *MemoryRepository) DeleteIdempotentResponses(ctx context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, response := range r.state.responses {
+		if response.CreatedAt.Before(before) {
+			delete(r.state.responses, key)
+		}
+	}
+	return nil
+}
+
+// RecordAnalysis updates the lifecycle of the project's findings with the
+// findings of an analysis and records the run.
+func (r *MemoryRepository) RecordAnalysis(ctx context.Context, run storage.AnalysisRun, agents []string, findings []storage.FindingRecord) (*storage.AnalysisRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Re-analyzing an older version would reopen the findings fixed since
+	var newestAt time.Time
+	for _, recorded := range r.state.runs[run.Project] {
+		if e, ok := r.state.sboms[recorded.SBOMID]; ok && e.createdAt.After(newestAt) {
+			newestAt = e.createdAt
+		}
+	}
+	if analyzed, ok := r.state.sboms[run.SBOMID]; ok && analyzed.createdAt.Before(newestAt) {
+		return nil, nil
+	}
+
+	tracked := r.state.findings[run.Project]
+	if tracked == nil {
+		tracked = make(map[string]storage.FindingRecord)
+		r.state.findings[run.Project] = tracked
+	}
+	open := make(map[string]string)
+	for fingerprint, finding := range tracked {
+		if finding.ResolvedAt == nil {
+			open[fingerprint] = finding.AgentName
+		}
+	}
+
+	reported := make(map[string]bool, len(findings))
+	for _, finding := range findings {
+		if reported[finding.Fingerprint] {
+			continue
+		}
+		reported[finding.Fingerprint] = true
+
+		if _, ok := open[finding.Fingerprint]; ok {
+			existing := tracked[finding.Fingerprint]
+			existing.Severity = finding.Severity
+			existing.Finding = finding.Finding
+			existing.Component = finding.Component
+			existing.LastSeen = run.AnalyzedAt
+			existing.LastSBOMID = run.SBOMID
+			tracked[finding.Fingerprint] = existing
+			run.Recurring++
+		} else {
+			// A resolved finding that is reported again starts a new lifecycle,
+			// in which only a false positive keeps its triage state
+			previous, seen := tracked[finding.Fingerprint]
+			record := storage.FindingRecord{
+				Fingerprint: finding.Fingerprint,
+				AgentName:   finding.AgentName,
+				Severity:    finding.Severity,
+				Finding:     finding.Finding,
+				Component:   finding.Component,
+				FirstSeen:   run.AnalyzedAt,
+				FirstSBOMID: run.SBOMID,
+				LastSeen:    run.AnalyzedAt,
+				LastSBOMID:  run.SBOMID,
+				Triage:      storage.TriageNew,
+			}
+			if seen {
+				if previous.Triage == storage.TriageFalsePositive {
+					record.Triage = previous.Triage
+				}
+				record.Assignee, record.TriageNote, record.TriagedAt = previous.Assignee, previous.TriageNote, previous.TriagedAt
+			}
+			tracked[finding.Fingerprint] = record
+			run.New++
+		}
+	}
+
+	// Only agents that completed can show that a finding is gone
+	completed := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		completed[agent] = true
+	}
+	for fingerprint, agent := range open {
+		if reported[fingerprint] || !completed[agent] {
+			continue
+		}
+		resolved := tracked[fingerprint]
+		resolvedAt := run.AnalyzedAt
+		resolved.ResolvedAt = &resolvedAt
+		resolved.ResolvedSBOMID = run.SBOMID
+		tracked[fingerprint] = resolved
+		run.Resolved++
+	}
+	run.Open = len(open) - run.Resolved + run.New
+
+	r.state.runs[run.Project] = append(r.state.runs[run.Project], run)
+	return &run, nil
+}
+
+// PruneAnalyses deletes the analysis runs recorded before the given time and
+// the findings resolved before it.
+func (r *MemoryRepository) PruneAnalyses(ctx context.Context, before time.Time) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var runs, findings int
+	for project, recorded := range r.state.runs {
+		kept := slices.DeleteFunc(recorded, func(run storage.AnalysisRun) bool {
+			return run.AnalyzedAt.Before(before)
+		})
+		runs += len(recorded) - len(kept)
+		r.state.runs[project] = kept
+	}
+	for _, tracked := range r.state.findings {
+		for fingerprint, finding := range tracked {
+			if finding.ResolvedAt != nil && finding.ResolvedAt.Before(before) {
+				delete(tracked, fingerprint)
+				findings++
+			}
+		}
+	}
+	return runs, findings, nil
+}
+
+// AnalysisRuns returns the recorded analyses of a project, oldest first.
+func (r *MemoryRepository) AnalysisRuns(ctx context.Context, project string) ([]storage.AnalysisRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(make([]storage.AnalysisRun, 0, len(r.state.runs[project])), r.state.runs[project]...), nil
+}
+
+// ProjectFindings returns the tracked findings of a project, ordered by when
+// they were first seen.
+func (r *MemoryRepository) ProjectFindings(ctx context.Context, project string) ([]storage.FindingRecord, error) {
+	r.mu.Lock()
+	findings := make([]storage.FindingRecord, 0, len(r.state.findings[project]))
+	for _, finding := range r.state.findings[project] {
+		finding.Status = storage.FindingOpen
+		if finding.ResolvedAt != nil {
+			finding.Status = storage.FindingResolved
+			resolvedAt := *finding.ResolvedAt
+			finding.ResolvedAt = &resolvedAt
+		}
+		if finding.TriagedAt != nil {
+			triagedAt := *finding.TriagedAt
+			finding.TriagedAt = &triagedAt
+		}
+		if finding.SLANotifiedAt != nil {
+			notifiedAt := *finding.SLANotifiedAt
+			finding.SLANotifiedAt = &notifiedAt
+		}
+		findings = append(findings, finding)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(findings, func(i, j int) bool {
+		if !findings[i].FirstSeen.Equal(findings[j].FirstSeen) {
+			return findings[i].FirstSeen.Before(findings[j].FirstSeen)
+		}
+		return findings[i].Fingerprint < findings[j].Fingerprint
+	})
+	return findings, nil
+}
+
+// TriageFinding changes the triage state, assignee or note of a tracked
+// finding.
+func (r *MemoryRepository) TriageFinding(ctx context.Context, project, fingerprint string, update storage.TriageUpdate) (*storage.FindingRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	finding, ok := r.state.findings[project][fingerprint]
+	if !ok {
+		return nil, nil
+	}
+	if update.State != nil {
+		finding.Triage = *update.State
+	}
+	if update.Assignee != nil {
+		finding.Assignee = *update.Assignee
+	}
+	if update.Note != nil {
+		finding.TriageNote = *update.Note
+	}
+	triagedAt := core.Now().UTC()
+	finding.TriagedAt = &triagedAt
+	r.state.findings[project][fingerprint] = finding
+
+	finding.Status = storage.FindingOpen
+	if finding.ResolvedAt != nil {
+		finding.Status = storage.FindingResolved
+		resolvedAt := *finding.ResolvedAt
+		finding.ResolvedAt = &resolvedAt
+	}
+	return &finding, nil
+}
+
+// MarkSLANotified records when the SLA breach of a tracked finding was
+// notified.
+func (r *MemoryRepository) MarkSLANotified(ctx context.Context, project, fingerprint string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	finding, ok := r.state.findings[project][fingerprint]
+	if !ok {
+		return false, nil
+	}
+	finding.SLANotifiedAt = &at
+	r.state.findings[project][fingerprint] = finding
+	return true, nil
+}
+
+// Ping reports that the repository is available, which it always is.
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close releases nothing; the contents stay readable until the repository
+// is garbage collected.
+func (r *MemoryRepository) Close() error {
+	return nil
+}
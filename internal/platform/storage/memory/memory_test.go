@@ -0,0 +1,336 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The repository must satisfy every interface the SQLite repository does
+var (
+	_ storage.Repository       = (*MemoryRepository)(nil)
+	_ storage.BatchStore       = (*MemoryRepository)(nil)
+	_ storage.Transactor       = (*MemoryRepository)(nil)
+	_ storage.Pruner           = (*MemoryRepository)(nil)
+	_ storage.Restorer         = (*MemoryRepository)(nil)
+	_ storage.Tagger           = (*MemoryRepository)(nil)
+	_ storage.SerialIndex      = (*MemoryRepository)(nil)
+	_ storage.ContentIndex     = (*MemoryRepository)(nil)
+	_ storage.Watchlist        = (*MemoryRepository)(nil)
+	_ storage.FindingHistory   = (*MemoryRepository)(nil)
+	_ storage.FindingTriage    = (*MemoryRepository)(nil)
+	_ storage.AnalysisPruner   = (*MemoryRepository)(nil)
+	_ storage.SLABreaches      = (*MemoryRepository)(nil)
+	_ storage.IdempotencyStore = (*MemoryRepository)(nil)
+)
+
+func TestMemoryRepository_Store(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := core.NewFixedClock(start)
+	defer core.SetClock(clock)()
+
+	sbom := core.SBOM{ID: "sbom-1", Name: "api", Components: []core.Component{{Name: "lodash"}}, Metadata: map[string]string{"serialNumber": "urn:uuid:1"}}
+	require.NoError(t, repo.Store(ctx, sbom))
+	require.NoError(t, repo.SetContentHash(ctx, "sbom-1", "hash-1"))
+
+	// Stored SBOMs are copies
+	sbom.Components[0].Name = "changed"
+	found, err := repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "lodash", found.Components[0].Name)
+
+	// Updates keep the creation time and content hash
+	clock.Advance(time.Hour)
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api-v2", Metadata: map[string]string{"serialNumber": "urn:uuid:1"}}))
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-0", Name: "web"}))
+	records, err := repo.ListRecords(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, storage.SBOMRecord{ID: "sbom-1", Name: "api-v2", CreatedAt: start}, records[0])
+	assert.Equal(t, "sbom-0", records[1].ID)
+
+	id, err := repo.FindByContentHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sbom-1", id)
+	assert.ErrorIs(t, repo.SetContentHash(ctx, "sbom-0", "hash-1"), storage.ErrConflict)
+	assert.ErrorIs(t, repo.Store(ctx, core.SBOM{ID: "sbom-0", Name: "web", Metadata: map[string]string{"serialNumber": "urn:uuid:1"}}), storage.ErrConflict)
+	id, err = repo.FindBySerialNumber(ctx, "urn:uuid:1")
+	require.NoError(t, err)
+	assert.Equal(t, "sbom-1", id)
+
+	require.NoError(t, repo.Delete(ctx, "sbom-1"))
+	found, err = repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	id, err = repo.FindBySerialNumber(ctx, "urn:uuid:1")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestMemoryRepository_StoreBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	require.NoError(t, repo.StoreBatch(ctx, []core.SBOM{{ID: "sbom-1", Name: "api"}, {ID: "sbom-2", Name: "web"}}))
+
+	// A batch that fails part way stores none of its SBOMs
+	unencodable := core.SBOM{ID: "sbom-4", Name: "bad", Components: []core.Component{{Name: "x", Evidence: &core.Evidence{Confidence: math.NaN()}}}}
+	err := repo.StoreBatch(ctx, []core.SBOM{{ID: "sbom-3", Name: "cli"}, unencodable})
+	assert.ErrorContains(t, err, "SBOM 2 of 2 (bad)")
+	stored, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, stored, 2)
+}
+
+func TestMemoryRepository_SetTags(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	tags := map[string]string{"env": "prod"}
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Tags: tags}))
+	tags["env"] = "changed"
+	found, err := repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod"}, found.Tags)
+
+	ok, err := repo.SetTags(ctx, "sbom-1", map[string]string{"team": "payments"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	records, err := repo.ListRecords(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments"}, records[0].Tags)
+
+	ok, err = repo.SetTags(ctx, "sbom-1", nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	found, err = repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Nil(t, found.Tags)
+
+	ok, err = repo.SetTags(ctx, "missing", map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMemoryRepository_InTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	failure := errors.New("boom")
+
+	err := repo.InTransaction(ctx, func(ctx context.Context) error {
+		require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+		_, err := repo.AddWatch(ctx, storage.Watch{ID: "w1", PURL: "pkg:npm/lodash"})
+		require.NoError(t, err)
+		return repo.InTransaction(ctx, func(ctx context.Context) error {
+			sbom, err := repo.FindByID(ctx, "sbom-1")
+			require.NoError(t, err)
+			require.NotNil(t, sbom)
+			return failure
+		})
+	})
+	assert.ErrorIs(t, err, failure)
+
+	sbom, err := repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Nil(t, sbom)
+	watches, err := repo.ListWatches(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, watches)
+}
+
+func TestMemoryRepository_Watchlist(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	created := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	first, err := repo.AddWatch(ctx, storage.Watch{ID: "w1", PURL: "pkg:npm/lodash", CreatedAt: created})
+	require.NoError(t, err)
+	again, err := repo.AddWatch(ctx, storage.Watch{ID: "w2", PURL: "pkg:npm/lodash", CreatedAt: created.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	deleted, err := repo.DeleteWatch(ctx, "w1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.DeleteWatch(ctx, "w1")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestMemoryRepository_IdempotentResponses(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	created := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	response := storage.IdempotentResponse{Key: "retry-1", StatusCode: 201, Body: []byte(`{"id":"sbom-1"}`), CreatedAt: created}
+	require.NoError(t, repo.SaveIdempotentResponse(ctx, response))
+	found, err := repo.FindIdempotentResponse(ctx, "retry-1", created)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, response.Body, found.Body)
+
+	found, err = repo.FindIdempotentResponse(ctx, "retry-1", created.Add(time.Second))
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	require.NoError(t, repo.DeleteIdempotentResponses(ctx, created.Add(time.Second)))
+	found, err = repo.FindIdempotentResponse(ctx, "retry-1", created)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestMemoryRepository_ClaimIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	claim := storage.IdempotentResponse{Key: "retry-1", Fingerprint: "abc", CreatedAt: created}
+
+	stored, err := repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// A second claim finds the first in flight
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(-time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "abc", stored.Fingerprint)
+	assert.Zero(t, stored.StatusCode)
+
+	// An abandoned claim is taken over
+	claim.Fingerprint = "def"
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Released claims are removed, responses are not
+	require.NoError(t, repo.ReleaseIdempotencyKey(ctx, "retry-1"))
+	stored, err = repo.FindIdempotentResponse(ctx, "retry-1", time.Time{})
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+	require.NoError(t, repo.SaveIdempotentResponse(ctx, storage.IdempotentResponse{Key: "retry-1", Fingerprint: "abc", StatusCode: 201, Body: []byte(`{"id":"sbom-1"}`), CreatedAt: created}))
+	require.NoError(t, repo.ReleaseIdempotencyKey(ctx, "retry-1"))
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(-time.Hour), created.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, 201, stored.StatusCode)
+	assert.Equal(t, []byte(`{"id":"sbom-1"}`), stored.Body)
+
+	// An expired response is replaced by the claim
+	stored, err = repo.ClaimIdempotencyKey(ctx, claim, created.Add(time.Hour), created.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestMemoryRepository_RecordAnalysis(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	clock := core.NewFixedClock(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	defer core.SetClock(clock)()
+
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "api-1", Name: "api"}))
+	clock.Advance(time.Hour)
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "api-2", Name: "api"}))
+
+	start := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	fixed := storage.FindingRecord{Fingerprint: "a", AgentName: "Vulnerability Scanner", Severity: "High"}
+	kept := storage.FindingRecord{Fingerprint: "b", AgentName: "Vulnerability Scanner", Severity: "Medium"}
+	failed := storage.FindingRecord{Fingerprint: "c", AgentName: "License Agent", Severity: "Critical"}
+
+	run, err := repo.RecordAnalysis(ctx, storage.AnalysisRun{SBOMID: "api-1", Project: "api", AnalyzedAt: start},
+		[]string{"Vulnerability Scanner", "License Agent"}, []storage.FindingRecord{fixed, kept, failed})
+	require.NoError(t, err)
+	assert.Equal(t, 3, run.New)
+
+	// Findings of agents that did not complete stay open
+	run, err = repo.RecordAnalysis(ctx, storage.AnalysisRun{SBOMID: "api-2", Project: "api", AnalyzedAt: start.Add(time.Hour)},
+		[]string{"Vulnerability Scanner"}, []storage.FindingRecord{kept})
+	require.NoError(t, err)
+	assert.Equal(t, storage.AnalysisRun{SBOMID: "api-2", Project: "api", AnalyzedAt: start.Add(time.Hour), Recurring: 1, Resolved: 1, Open: 2}, *run)
+
+	// Analyses of older versions are not recorded
+	run, err = repo.RecordAnalysis(ctx, storage.AnalysisRun{SBOMID: "api-1", Project: "api", AnalyzedAt: start.Add(2 * time.Hour)},
+		[]string{"Vulnerability Scanner"}, []storage.FindingRecord{fixed})
+	require.NoError(t, err)
+	assert.Nil(t, run)
+
+	runs, err := repo.AnalysisRuns(ctx, "api")
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+
+	findings, err := repo.ProjectFindings(ctx, "api")
+	require.NoError(t, err)
+	require.Len(t, findings, 3)
+	assert.Equal(t, storage.FindingResolved, findings[0].Status)
+	assert.Equal(t, "api-2", findings[0].ResolvedSBOMID)
+	assert.Equal(t, storage.FindingOpen, findings[1].Status)
+	assert.Equal(t, storage.FindingOpen, findings[2].Status)
+}
+
+func TestMemoryRepository_TriageFinding(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	clock := core.NewFixedClock(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	defer core.SetClock(clock)()
+
+	start := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	record := func(at time.Time, findings ...storage.FindingRecord) {
+		_, err := repo.RecordAnalysis(ctx, storage.AnalysisRun{SBOMID: "api-1", Project: "api", AnalyzedAt: at},
+			[]string{"Vulnerability Scanner"}, findings)
+		require.NoError(t, err)
+	}
+	finding := storage.FindingRecord{Fingerprint: "a", AgentName: "Vulnerability Scanner", Severity: "High"}
+	record(start, finding)
+
+	state, assignee := storage.TriageInProgress, "alice"
+	triaged, err := repo.TriageFinding(ctx, "api", "a", storage.TriageUpdate{State: &state, Assignee: &assignee})
+	require.NoError(t, err)
+	require.NotNil(t, triaged)
+	assert.Equal(t, storage.TriageInProgress, triaged.Triage)
+	assert.Equal(t, "alice", triaged.Assignee)
+	assert.Equal(t, storage.FindingOpen, triaged.Status)
+	require.NotNil(t, triaged.TriagedAt)
+	assert.Equal(t, clock.Now(), *triaged.TriagedAt)
+
+	// Fields left out of the update are kept
+	note := "Waiting for the upstream fix"
+	triaged, err = repo.TriageFinding(ctx, "api", "a", storage.TriageUpdate{Note: &note})
+	require.NoError(t, err)
+	assert.Equal(t, storage.TriageInProgress, triaged.Triage)
+	assert.Equal(t, "alice", triaged.Assignee)
+	assert.Equal(t, note, triaged.TriageNote)
+
+	// The finding is resolved and reported again: its triage starts over
+	record(start.Add(time.Hour))
+	record(start.Add(2*time.Hour), finding)
+	findings, err := repo.ProjectFindings(ctx, "api")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, storage.TriageNew, findings[0].Triage)
+	assert.Equal(t, "alice", findings[0].Assignee)
+
+	// unless it is a false positive
+	state = storage.TriageFalsePositive
+	_, err = repo.TriageFinding(ctx, "api", "a", storage.TriageUpdate{State: &state})
+	require.NoError(t, err)
+	record(start.Add(3 * time.Hour))
+	record(start.Add(4*time.Hour), finding)
+	findings, err = repo.ProjectFindings(ctx, "api")
+	require.NoError(t, err)
+	assert.Equal(t, storage.TriageFalsePositive, findings[0].Triage)
+
+	triaged, err = repo.TriageFinding(ctx, "api", "missing", storage.TriageUpdate{State: &state})
+	require.NoError(t, err)
+	assert.Nil(t, triaged)
+	triaged, err = repo.TriageFinding(ctx, "web", "a", storage.TriageUpdate{State: &state})
+	require.NoError(t, err)
+	assert.Nil(t, triaged)
+}
@@ -4,10 +4,16 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
 )
 
+// ErrConflict is returned, wrapped, by writes that would give a second SBOM
+// the content hash or serial number of a stored SBOM.
+var ErrConflict = errors.New("conflicts with a stored SBOM")
+
 // Repository defines the contract for storing and retrieving SBOM documents.
 // Implementations of this interface handle the persistence layer details
 // while keeping the core business logic database-agnostic.
@@ -20,4 +26,285 @@ type Repository interface {
 	// Returns nil and no error if the SBOM is not found.
 	// Returns an error if there's a problem accessing the storage system.
 	FindByID(ctx context.Context, id string) (*core.SBOM, error)
+
+	// FindAll retrieves every stored SBOM document, ordered by creation time.
+	// Returns an empty slice if no SBOMs are stored.
+	FindAll(ctx context.Context) ([]core.SBOM, error)
+}
+
+// BatchStore is implemented by repositories that can store many SBOMs at
+// once.
+type BatchStore interface {
+	// StoreBatch persists every SBOM in one transaction: if any cannot be
+	// stored, none are.
+	StoreBatch(ctx context.Context, sboms []core.SBOM) error
+}
+
+// Transactor is implemented by repositories whose operations can be grouped
+// into a transaction.
+type Transactor interface {
+	// InTransaction calls fn with a context in which the repository's
+	// operations take place in one transaction, committed if fn returns nil
+	// and rolled back otherwise.
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// InTransaction calls fn in a transaction of repo if it is a Transactor, and
+// directly otherwise.
+func InTransaction(ctx context.Context, repo any, fn func(ctx context.Context) error) error {
+	if transactor, ok := repo.(Transactor); ok {
+		return transactor.InTransaction(ctx, fn)
+	}
+	return fn(ctx)
+}
+
+// SBOMRecord describes a stored SBOM without its contents.
+type SBOMRecord struct {
+	ID string `json:"id"`
+	// Name identifies the project the SBOM describes; SBOMs with the same
+	// name are versions of the same project
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// Tags are the SBOM's own tags, without those of its project
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// Pruner is implemented by repositories whose SBOMs can be listed and
+// deleted to enforce retention policies.
+type Pruner interface {
+	// ListRecords returns every stored SBOM, ordered by creation time.
+	ListRecords(ctx context.Context) ([]SBOMRecord, error)
+
+	// Delete removes the SBOM with the given ID. Deleting an SBOM that
+	// does not exist is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// Tagger is implemented by repositories whose SBOM tags can be changed
+// without storing the SBOM again.
+type Tagger interface {
+	// SetTags replaces the tags of the SBOM with the given ID, reporting
+	// whether it is stored.
+	SetTags(ctx context.Context, id string, tags map[string]string) (bool, error)
+}
+
+// Restorer is implemented by repositories that can restore SBOMs from a
+// backup with their original submission times.
+type Restorer interface {
+	// Restore stores an SBOM as submitted at createdAt, replacing any SBOM
+	// with the same ID.
+	Restore(ctx context.Context, sbom core.SBOM, createdAt time.Time) error
+}
+
+// SerialIndex is implemented by repositories that can find stored SBOMs by
+// the serial number of their document. A serial number identifies one SBOM
+// at most: storing another SBOM with it fails with ErrConflict.
+type SerialIndex interface {
+	// FindBySerialNumber returns the ID of the earliest stored SBOM whose
+	// document has the given serial number, or an empty string if there is
+	// none.
+	FindBySerialNumber(ctx context.Context, serialNumber string) (string, error)
+}
+
+// ContentIndex is implemented by repositories that record the content hash
+// of submitted SBOMs so that identical resubmissions can be detected.
+type ContentIndex interface {
+	// FindByContentHash returns the ID of the earliest stored SBOM with the
+	// given content hash, or an empty string if there is none.
+	FindByContentHash(ctx context.Context, hash string) (string, error)
+
+	// SetContentHash records the content hash of the SBOM with the given ID,
+	// or clears it if hash is empty. A hash is recorded for one SBOM at most:
+	// recording that of another SBOM fails with ErrConflict.
+	SetContentHash(ctx context.Context, id, hash string) error
+}
+
+// Watch is a package whose appearance in new security intelligence is
+// alerted on if a stored SBOM uses it.
+type Watch struct {
+	ID string `json:"id"`
+	// PURL identifies the watched package, e.g. "pkg:npm/lodash"; with a
+	// version only components of that version are watched
+	PURL string `json:"purl"`
+	// Note records why the package is watched
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Watchlist is implemented by repositories that store watched packages.
+type Watchlist interface {
+	// AddWatch stores a watch. If the package is already watched, the
+	// existing watch is returned instead.
+	AddWatch(ctx context.Context, watch Watch) (Watch, error)
+
+	// ListWatches returns every watch, ordered by creation time.
+	ListWatches(ctx context.Context) ([]Watch, error)
+
+	// DeleteWatch removes the watch with the given ID, reporting whether it
+	// existed.
+	DeleteWatch(ctx context.Context, id string) (bool, error)
+}
+
+// Finding lifecycle statuses.
+const (
+	FindingOpen     = "open"
+	FindingResolved = "resolved"
+)
+
+// Finding triage states, set by people working on the remediation of a
+// finding, independently of whether analyses still report it.
+const (
+	TriageNew           = "new"
+	TriageAcknowledged  = "acknowledged"
+	TriageInProgress    = "in-progress"
+	TriageResolved      = "resolved"
+	TriageFalsePositive = "false-positive"
+)
+
+// TriageStates lists the finding triage states in workflow order.
+var TriageStates = []string{TriageNew, TriageAcknowledged, TriageInProgress, TriageResolved, TriageFalsePositive}
+
+// FindingRecord tracks a finding of a project across the analyses of its
+// SBOM versions.
+type FindingRecord struct {
+	// Fingerprint identifies the finding across versions of its component
+	Fingerprint string `json:"fingerprint"`
+	AgentName   string `json:"agent_name"`
+	Severity    string `json:"severity"`
+	Finding     string `json:"finding"`
+	Component   string `json:"component,omitempty"`
+	// Status is FindingOpen or FindingResolved
+	Status      string     `json:"status"`
+	FirstSeen   time.Time  `json:"first_seen"`
+	FirstSBOMID string     `json:"first_sbom_id"`
+	LastSeen    time.Time  `json:"last_seen"`
+	LastSBOMID  string     `json:"last_sbom_id"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	// ResolvedSBOMID is the SBOM whose analysis no longer reported the finding
+	ResolvedSBOMID string `json:"resolved_sbom_id,omitempty"`
+	// Triage is one of TriageStates. It starts as TriageNew, and again when
+	// a resolved finding is reported again, unless it is a false positive
+	Triage     string     `json:"triage"`
+	Assignee   string     `json:"assignee,omitempty"`
+	TriageNote string     `json:"triage_note,omitempty"`
+	TriagedAt  *time.Time `json:"triaged_at,omitempty"`
+	// DueAt is when the finding must be remediated by the SLA of its
+	// severity, and Overdue whether it is open past then; both are set by
+	// the sla package, not stored
+	DueAt   *time.Time `json:"due_at,omitempty"`
+	Overdue bool       `json:"overdue,omitempty"`
+	// SLANotifiedAt is when the breach of the finding's SLA was notified
+	SLANotifiedAt *time.Time `json:"sla_notified_at,omitempty"`
+}
+
+// TriageUpdate changes the triage of a tracked finding. Nil fields are left
+// unchanged, and an empty Assignee unassigns the finding.
+type TriageUpdate struct {
+	State    *string
+	Assignee *string
+	Note     *string
+}
+
+// AnalysisRun records how an analysis changed the findings of a project.
+type AnalysisRun struct {
+	SBOMID     string    `json:"sbom_id"`
+	Project    string    `json:"project"`
+	AnalyzedAt time.Time `json:"analyzed_at"`
+	// New counts findings first reported, or reported again after being
+	// resolved; Recurring findings were already open
+	New       int `json:"new"`
+	Recurring int `json:"recurring"`
+	// Resolved counts open findings no longer reported by their agent
+	Resolved int `json:"resolved"`
+	// Open counts the project's open findings after the analysis
+	Open int `json:"open"`
+}
+
+// FindingHistory is implemented by repositories that track the lifecycle of
+// findings across analyses.
+type FindingHistory interface {
+	// RecordAnalysis updates the findings of run.Project with the findings
+	// reported by an analysis of run.SBOMID, in which agents completed, and
+	// records the run with its counts. Open findings of other agents are
+	// kept open. Analyses of SBOMs older than the newest version already
+	// analyzed are not recorded; nil is then returned.
+	RecordAnalysis(ctx context.Context, run AnalysisRun, agents []string, findings []FindingRecord) (*AnalysisRun, error)
+
+	// AnalysisRuns returns the recorded analyses of a project, oldest first.
+	AnalysisRuns(ctx context.Context, project string) ([]AnalysisRun, error)
+
+	// ProjectFindings returns the tracked findings of a project, ordered by
+	// when they were first seen.
+	ProjectFindings(ctx context.Context, project string) ([]FindingRecord, error)
+}
+
+// AnalysisPruner is implemented by repositories whose recorded analyses can
+// be deleted to enforce retention policies.
+type AnalysisPruner interface {
+	// PruneAnalyses deletes, in one transaction, the analysis runs recorded
+	// before the given time and the findings resolved before it, and returns
+	// how many of each it deleted. Open findings are kept however old they
+	// are, since they describe the current state of their project.
+	PruneAnalyses(ctx context.Context, before time.Time) (runs, findings int, err error)
+}
+
+// FindingTriage is implemented by repositories that record the triage of
+// tracked findings.
+type FindingTriage interface {
+	// TriageFinding applies update to the finding of project with the given
+	// fingerprint and returns the finding, or nil if the project has no such
+	// finding. The state of update must be one of TriageStates.
+	TriageFinding(ctx context.Context, project, fingerprint string, update TriageUpdate) (*FindingRecord, error)
+}
+
+// SLABreaches is implemented by repositories that record which findings
+// were notified as having breached their remediation SLA.
+type SLABreaches interface {
+	// MarkSLANotified records that the SLA breach of the finding of project
+	// with the given fingerprint was notified at the given time, reporting
+	// whether the finding exists. The mark is cleared when a resolved
+	// finding is reported again.
+	MarkSLANotified(ctx context.Context, project, fingerprint string, at time.Time) (bool, error)
+}
+
+// IdempotentResponse is the response to a request made with an
+// Idempotency-Key, replayed when the request is retried with the same key.
+type IdempotentResponse struct {
+	Key string `json:"key"`
+	// Fingerprint identifies the request the key was first used for
+	Fingerprint string `json:"fingerprint"`
+	// StatusCode is 0 for a claim on the key while the request is handled
+	StatusCode int `json:"status_code"`
+	// Header holds the response headers that are replayed with the body
+	Header    map[string]string `json:"header,omitempty"`
+	Body      []byte            `json:"body"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// IdempotencyStore is implemented by repositories that record the responses
+// to idempotent requests.
+type IdempotencyStore interface {
+	// SaveIdempotentResponse stores a response, replacing any stored with
+	// the same key.
+	SaveIdempotentResponse(ctx context.Context, response IdempotentResponse) error
+
+	// FindIdempotentResponse returns the response stored with the given key
+	// since the given time, or nil if there is none.
+	FindIdempotentResponse(ctx context.Context, key string, since time.Time) (*IdempotentResponse, error)
+
+	// ClaimIdempotencyKey stores claim, a response without status code, for
+	// as long as the request with its key is handled. If a response stored
+	// since the given time or a claim made since claimedSince is stored with
+	// the key, it is returned instead and the claim is not stored; otherwise
+	// nil is returned. Claims are checked and stored atomically, so that only
+	// one request with a key is handled at a time.
+	ClaimIdempotencyKey(ctx context.Context, claim IdempotentResponse, since, claimedSince time.Time) (*IdempotentResponse, error)
+
+	// ReleaseIdempotencyKey removes the claim stored with the given key, unless
+	// a response has replaced it.
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+
+	// DeleteIdempotentResponses removes the responses stored before the
+	// given time.
+	DeleteIdempotentResponses(ctx context.Context, before time.Time) error
 }
@@ -0,0 +1,173 @@
+// Package vectordb provides batched, concurrent embedding for the harvester.
+package vectordb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Default batching used by the Harvester.
+const (
+	DefaultEmbeddingBatchSize   = 32
+	DefaultEmbeddingConcurrency = 4
+)
+
+// checkpointEveryBatches controls how often progress is saved while embedding a source.
+const checkpointEveryBatches = 10
+
+// OllamaBatchEmbeddingRequest represents the request structure for the Ollama embed API,
+// which embeds several inputs in one call.
+type OllamaBatchEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// OllamaBatchEmbeddingResponse represents the response structure from the Ollama embed API.
+type OllamaBatchEmbeddingResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// batchResult is the outcome of embedding one batch of documents.
+type batchResult struct {
+	docs    []pendingDocument
+	vectors [][]float64
+	err     error
+}
+
+// embedAll embeds the documents in batches using a pool of concurrent workers
+// and stores them as results arrive. It returns the number of documents
+// embedded and the number that failed.
+func (h *Harvester) embedAll(ctx context.Context, docs []pendingDocument) (int, int) {
+	if len(docs) == 0 {
+		return 0, 0
+	}
+
+	var batches [][]pendingDocument
+	for start := 0; start < len(docs); start += h.batchSize {
+		end := min(start+h.batchSize, len(docs))
+		batches = append(batches, docs[start:end])
+	}
+
+	jobs := make(chan []pendingDocument)
+	results := make(chan batchResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(h.concurrency, len(batches)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				vectors, err := h.embedBatch(ctx, batch)
+				results <- batchResult{docs: batch, vectors: vectors, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, batch := range batches {
+			select {
+			case jobs <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results are stored from this goroutine only, so the vector store and
+	// fingerprints never see concurrent writes
+	embedded, failed, completed := 0, 0, 0
+	for result := range results {
+		completed++
+		if result.err != nil {
+			fmt.Printf("Warning: Failed to embed batch of %d documents: %v\n", len(result.docs), result.err)
+			failed += len(result.docs)
+			continue
+		}
+
+		for i, doc := range result.docs {
+			doc.Vector = result.vectors[i]
+			if err := h.store(doc); err != nil {
+				fmt.Printf("Warning: Failed to ingest document %s: %v\n", doc.ID, err)
+				failed++
+				continue
+			}
+			embedded++
+		}
+
+		if completed%checkpointEveryBatches == 0 {
+			h.saveCheckpoint()
+		}
+	}
+
+	// Batches never dispatched because the context was cancelled
+	if remaining := len(docs) - embedded - failed; remaining > 0 {
+		failed += remaining
+	}
+
+	return embedded, failed
+}
+
+// embedBatch embeds a batch of documents with a single request to the Ollama
+// embed API, falling back to one request per document for Ollama versions
+// that predate it.
+func (h *Harvester) embedBatch(ctx context.Context, batch []pendingDocument) ([][]float64, error) {
+	inputs := make([]string, len(batch))
+	for i, doc := range batch {
+		inputs[i] = doc.Text
+	}
+
+	reqBody, err := json.Marshal(OllamaBatchEmbeddingRequest{
+		Model: h.embeddingModel,
+		Input: inputs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", h.ollamaBatchURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		vectors := make([][]float64, len(batch))
+		for i, doc := range batch {
+			if vectors[i], err = h.generateEmbedding(ctx, doc.Text); err != nil {
+				return nil, err
+			}
+		}
+		return vectors, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama API returned status %d", resp.StatusCode)
+	}
+
+	var ollamaResp OllamaBatchEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(ollamaResp.Embeddings) != len(batch) {
+		return nil, fmt.Errorf("Ollama returned %d embeddings for %d inputs", len(ollamaResp.Embeddings), len(batch))
+	}
+
+	return ollamaResp.Embeddings, nil
+}
@@ -0,0 +1,95 @@
+// Package vectordb provides resumable harvest checkpoints.
+package vectordb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// harvestCheckpoint is the persisted state of the Harvester.
+type harvestCheckpoint struct {
+	LastHarvest   map[string]time.Time `json:"last_harvest"`
+	Fingerprints  map[string]string    `json:"fingerprints"`
+	DocumentDates map[string]string    `json:"document_dates,omitempty"`
+	// Manual holds manually added documents so they survive a restart
+	Manual map[string]SecurityIntelligence `json:"manual,omitempty"`
+}
+
+// SetCheckpointPath enables checkpoints at path and restores any progress
+// saved there by a previous harvest. A missing file is not an error.
+// Checkpoints are most useful with a persistent vector store, where
+// documents embedded before an interruption are still present on resume.
+func (h *Harvester) SetCheckpointPath(path string) error {
+	h.checkpointPath = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read harvest checkpoint: %w", err)
+	}
+
+	var checkpoint harvestCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return fmt.Errorf("failed to parse harvest checkpoint: %w", err)
+	}
+
+	for source, harvestedAt := range checkpoint.LastHarvest {
+		h.lastHarvest[source] = harvestedAt
+	}
+	for id, fingerprint := range checkpoint.Fingerprints {
+		h.fingerprints[id] = fingerprint
+	}
+	for id, date := range checkpoint.DocumentDates {
+		h.documentDates[id] = date
+	}
+	for id, intelligence := range checkpoint.Manual {
+		h.manual[id] = intelligence
+	}
+	return nil
+}
+
+// saveCheckpoint writes the harvest progress if checkpoints are enabled.
+// The file is replaced atomically so a crash never leaves a partial checkpoint.
+func (h *Harvester) saveCheckpoint() {
+	if h.checkpointPath == "" {
+		return
+	}
+
+	data, err := json.Marshal(harvestCheckpoint{
+		LastHarvest:   h.lastHarvest,
+		Fingerprints:  h.fingerprints,
+		DocumentDates: h.documentDates,
+		Manual:        h.manual,
+	})
+	if err != nil {
+		fmt.Printf("Warning: Failed to encode harvest checkpoint: %v\n", err)
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.checkpointPath), ".harvest-checkpoint-*")
+	if err != nil {
+		fmt.Printf("Warning: Failed to write harvest checkpoint: %v\n", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		fmt.Printf("Warning: Failed to write harvest checkpoint: %v\n", err)
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		fmt.Printf("Warning: Failed to write harvest checkpoint: %v\n", err)
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), h.checkpointPath); err != nil {
+		fmt.Printf("Warning: Failed to write harvest checkpoint: %v\n", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package vectordb provides manual management of security intelligence documents.
+package vectordb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManualSource is the source recorded for manually added documents that do not name one.
+const ManualSource = "Internal Advisory"
+
+// ErrInvalidDocument is returned when a manually added document fails validation.
+var ErrInvalidDocument = errors.New("invalid intelligence document")
+
+// AddDocument embeds and stores a manually curated intelligence record, such
+// as an internal advisory, replacing any document with the same ID. Manual
+// documents are kept until removed: they are never evicted by age and, with
+// checkpoints enabled, are restored into a vector store that lost them.
+func (h *Harvester) AddDocument(ctx context.Context, intelligence SecurityIntelligence) (SecurityIntelligence, error) {
+	intelligence, err := normalizeManualDocument(intelligence)
+	if err != nil {
+		return SecurityIntelligence{}, err
+	}
+
+	if _, err := h.ingest(ctx, intelligence); err != nil {
+		return SecurityIntelligence{}, err
+	}
+
+	h.manual[intelligence.ID] = intelligence
+	h.saveCheckpoint()
+	return intelligence, nil
+}
+
+// RemoveDocument deletes a document from the corpus, whether it was added
+// manually or harvested, and reports whether it existed. A harvested document
+// returns if its source later publishes a new revision of it.
+func (h *Harvester) RemoveDocument(id string) bool {
+	_, wasManual := h.manual[id]
+	delete(h.manual, id)
+
+	removed := h.remove(id) || wasManual
+	if removed {
+		h.saveCheckpoint()
+	}
+	return removed
+}
+
+// Documents returns the manually added documents ordered by ID.
+func (h *Harvester) Documents() []SecurityIntelligence {
+	documents := make([]SecurityIntelligence, 0, len(h.manual))
+	for _, intelligence := range h.manual {
+		documents = append(documents, intelligence)
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].ID < documents[j].ID
+	})
+	return documents
+}
+
+// restoreDocuments re-ingests manual documents missing from the vector store,
+// such as after a restart with the in-memory store. It returns how many were restored.
+func (h *Harvester) restoreDocuments(ctx context.Context) int {
+	restored := 0
+	for _, intelligence := range h.Documents() {
+		if _, exists := h.vectorDB.Get(intelligence.ID); exists {
+			continue
+		}
+		if _, err := h.ingest(ctx, intelligence); err != nil {
+			fmt.Printf("Warning: Failed to restore document %s: %v\n", intelligence.ID, err)
+			continue
+		}
+		restored++
+	}
+	return restored
+}
+
+// normalizeManualDocument validates a manually added document and fills in defaults.
+func normalizeManualDocument(intelligence SecurityIntelligence) (SecurityIntelligence, error) {
+	intelligence.ID = strings.TrimSpace(intelligence.ID)
+	intelligence.Title = strings.TrimSpace(intelligence.Title)
+	intelligence.Withdrawn = false
+
+	if intelligence.ID == "" {
+		return intelligence, fmt.Errorf("%w: id is required", ErrInvalidDocument)
+	}
+	if intelligence.Title == "" {
+		return intelligence, fmt.Errorf("%w: title is required", ErrInvalidDocument)
+	}
+
+	if intelligence.Severity != "" {
+		severity := normalizeSeverity(intelligence.Severity)
+		if severity == "" {
+			return intelligence, fmt.Errorf("%w: unknown severity %q", ErrInvalidDocument, intelligence.Severity)
+		}
+		intelligence.Severity = severity
+	}
+
+	if intelligence.Date == "" {
+		intelligence.Date = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", intelligence.Date); err != nil {
+		return intelligence, fmt.Errorf("%w: date must be YYYY-MM-DD, got %q", ErrInvalidDocument, intelligence.Date)
+	}
+
+	if intelligence.Source == "" {
+		intelligence.Source = ManualSource
+	}
+	intelligence.Description = truncate(intelligence.Description, maxDescriptionLength)
+	return intelligence, nil
+}
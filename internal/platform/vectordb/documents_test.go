@@ -0,0 +1,92 @@
+package vectordb
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHarvester_AddDocument(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	db := NewMemoryVectorDB()
+	harvester := newTestHarvester(db, server.URL)
+
+	added, err := harvester.AddDocument(context.Background(), SecurityIntelligence{
+		ID:        " INT-2024-001 ",
+		Title:     "Internal fork of acme-serializer is unsafe",
+		Component: "acme-serializer",
+		Severity:  "important",
+		Date:      "2015-01-01",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "INT-2024-001", added.ID)
+	assert.Equal(t, "High", added.Severity)
+	assert.Equal(t, ManualSource, added.Source)
+
+	doc, found := db.Get("INT-2024-001")
+	require.True(t, found)
+	assert.NotEmpty(t, doc.Vector)
+	assert.Equal(t, []SecurityIntelligence{added}, harvester.Documents())
+
+	// Manual documents are not evicted by age
+	assert.Zero(t, harvester.EvictOlderThan(time.Now()))
+	assert.Equal(t, 1, db.Size())
+
+	assert.True(t, harvester.RemoveDocument("INT-2024-001"))
+	assert.False(t, harvester.RemoveDocument("INT-2024-001"))
+	assert.Empty(t, harvester.Documents())
+	assert.Zero(t, db.Size())
+}
+
+func TestHarvester_AddDocumentValidation(t *testing.T) {
+	harvester := NewHarvester(NewMemoryVectorDB())
+
+	tests := []struct {
+		name         string
+		intelligence SecurityIntelligence
+	}{
+		{name: "Missing ID", intelligence: SecurityIntelligence{Title: "Advisory"}},
+		{name: "Missing title", intelligence: SecurityIntelligence{ID: "INT-1"}},
+		{name: "Unknown severity", intelligence: SecurityIntelligence{ID: "INT-1", Title: "Advisory", Severity: "urgent"}},
+		{name: "Invalid date", intelligence: SecurityIntelligence{ID: "INT-1", Title: "Advisory", Date: "June 2024"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := harvester.AddDocument(context.Background(), tt.intelligence)
+			assert.ErrorIs(t, err, ErrInvalidDocument)
+		})
+	}
+}
+
+func TestIntelligenceStore_RestoresManualDocuments(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	checkpoint := filepath.Join(t.TempDir(), "harvest.json")
+
+	first := newTestHarvester(NewMemoryVectorDB(), server.URL)
+	require.NoError(t, first.SetCheckpointPath(checkpoint))
+	_, err := first.AddDocument(context.Background(), SecurityIntelligence{ID: "INT-1", Title: "Internal advisory"})
+	require.NoError(t, err)
+
+	// A restart with an in-memory store re-embeds the manual documents
+	db := NewMemoryVectorDB()
+	second := newTestHarvester(db, server.URL)
+	require.NoError(t, second.SetCheckpointPath(checkpoint))
+	store := NewIntelligenceStore(db, second)
+	require.NoError(t, store.EnsureInitialized(context.Background()))
+
+	_, found := db.Get("INT-1")
+	assert.True(t, found)
+	assert.Len(t, store.Documents(), 1)
+}
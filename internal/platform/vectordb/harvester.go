@@ -4,13 +4,21 @@ package vectordb
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
 )
 
-// SecurityIntelligence represents mock security intelligence data.
+// SecurityIntelligence represents a security advisory, report or discussion
+// normalized from any intelligence source.
 type SecurityIntelligence struct {
 	ID          string `json:"id"`
 	Title       string `json:"title"`
@@ -20,52 +28,246 @@ type SecurityIntelligence struct {
 	Severity    string `json:"severity"`
 	Source      string `json:"source"`
 	Date        string `json:"date"`
+	URL         string `json:"url,omitempty"`
+	// Withdrawn marks intelligence retracted by its source, which is removed from the corpus
+	Withdrawn bool `json:"withdrawn,omitempty"`
+}
+
+// HarvestReport summarizes a harvest run across all configured sources.
+type HarvestReport struct {
+	Fetched       int `json:"fetched"`
+	Embedded      int `json:"embedded"`
+	Unchanged     int `json:"unchanged"`
+	Failed        int `json:"failed"`
+	Evicted       int `json:"evicted"`
+	SourcesFailed int `json:"sources_failed"`
 }
 
 // Harvester handles the collection and processing of security intelligence data.
 type Harvester struct {
-	vectorDB    *MemoryVectorDB
-	ollamaURL   string
-	client      *http.Client
+	vectorDB       VectorDB
+	ollamaURL      string
+	ollamaBatchURL string
+	embeddingModel string
+	client         *http.Client
+
+	// batchSize is the number of documents embedded per request and
+	// concurrency the number of requests in flight at once
+	batchSize   int
+	concurrency int
+	// checkpointPath persists harvest progress; empty disables checkpoints
+	checkpointPath string
+
+	sources []Source
+	// lastHarvest records when each source was last fetched successfully
+	lastHarvest map[string]time.Time
+	// fingerprints holds a hash of the embedded text per document, so
+	// unchanged documents are not re-embedded
+	fingerprints map[string]string
+	// documentDates holds the publication date of each harvested document,
+	// used to evict stale intelligence
+	documentDates map[string]string
+	// manual holds documents added through AddDocument rather than harvested
+	manual map[string]SecurityIntelligence
 }
 
 // NewHarvester creates a new Harvester instance.
-func NewHarvester(vectorDB *MemoryVectorDB) *Harvester {
+func NewHarvester(vectorDB VectorDB) *Harvester {
 	return &Harvester{
-		vectorDB:  vectorDB,
-		ollamaURL: "http://localhost:11434/api/embeddings",
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		vectorDB:       vectorDB,
+		ollamaURL:      OllamaURL("/api/embeddings"),
+		ollamaBatchURL: OllamaURL("/api/embed"),
+		embeddingModel: DefaultEmbeddingModel,
+		batchSize:      DefaultEmbeddingBatchSize,
+		concurrency:    DefaultEmbeddingConcurrency,
+		client:         httpclient.New(30 * time.Second),
+		lastHarvest:    make(map[string]time.Time),
+		fingerprints:   make(map[string]string),
+		documentDates:  make(map[string]string),
+		manual:         make(map[string]SecurityIntelligence),
+	}
+}
+
+// SetEmbeddingModel sets the Ollama model used to embed documents.
+// Queries against the corpus must be embedded with the same model.
+func (h *Harvester) SetEmbeddingModel(model string) {
+	h.embeddingModel = model
+}
+
+// SetBatching sets how many documents are embedded per request and how many
+// requests run concurrently. Values below one are ignored.
+func (h *Harvester) SetBatching(batchSize, concurrency int) {
+	if batchSize > 0 {
+		h.batchSize = batchSize
+	}
+	if concurrency > 0 {
+		h.concurrency = concurrency
+	}
+}
+
+// ConfigureFromEnv applies harvester settings from the environment:
+// INTEL_SOURCES (see ParseSources), INTEL_EMBED_BATCH_SIZE,
+// INTEL_EMBED_CONCURRENCY and INTEL_CHECKPOINT_PATH. Invalid settings are
+// reported together and otherwise ignored.
+func (h *Harvester) ConfigureFromEnv() error {
+	var errs []error
+
+	if specs := os.Getenv("INTEL_SOURCES"); specs != "" {
+		sources, err := ParseSources(specs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("INTEL_SOURCES: %w", err))
+		}
+		for _, source := range sources {
+			h.AddSource(source)
+		}
+	}
+
+	batchSize, concurrency := 0, 0
+	for name, target := range map[string]*int{"INTEL_EMBED_BATCH_SIZE": &batchSize, "INTEL_EMBED_CONCURRENCY": &concurrency} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			errs = append(errs, fmt.Errorf("%s must be a positive integer, got %q", name, value))
+			continue
+		}
+		*target = n
+	}
+	h.SetBatching(batchSize, concurrency)
+
+	if path := os.Getenv("INTEL_CHECKPOINT_PATH"); path != "" {
+		if err := h.SetCheckpointPath(path); err != nil {
+			errs = append(errs, fmt.Errorf("INTEL_CHECKPOINT_PATH: %w", err))
+		}
 	}
+
+	return errors.Join(errs...)
+}
+
+// AddSource registers an intelligence source to pull from in Harvest.
+func (h *Harvester) AddSource(source Source) {
+	h.sources = append(h.sources, source)
+}
+
+// HasSources reports whether any intelligence sources are configured.
+func (h *Harvester) HasSources() bool {
+	return len(h.sources) > 0
+}
+
+// Harvest pulls new intelligence from every configured source and embeds it.
+// Harvests are incremental: each source is only asked for entries newer than
+// its last harvest in which every document was embedded, and documents whose
+// text has not changed are not re-embedded. Documents are embedded in
+// batches, several at a time, and progress is checkpointed so an interrupted
+// harvest resumes where it left off.
+// A failing source is logged and skipped; an error is only returned when every
+// source fails or the context is cancelled.
+func (h *Harvester) Harvest(ctx context.Context) (HarvestReport, error) {
+	report, _, err := h.harvest(ctx)
+	return report, err
+}
+
+// harvest performs a harvest, also returning the new and changed documents,
+// whether or not they could be embedded.
+func (h *Harvester) harvest(ctx context.Context) (HarvestReport, []SecurityIntelligence, error) {
+	var report HarvestReport
+	var changed []SecurityIntelligence
+
+	for _, source := range h.sources {
+		startedAt := time.Now()
+
+		intelligence, err := source.Fetch(ctx, h.lastHarvest[source.Name()])
+		if err != nil {
+			fmt.Printf("Warning: Failed to harvest from %s: %v\n", source.Name(), err)
+			report.SourcesFailed++
+			continue
+		}
+
+		report.Fetched += len(intelligence)
+
+		var pending []pendingDocument
+		for _, intel := range intelligence {
+			if intel.Withdrawn {
+				if h.remove(intel.ID) {
+					report.Evicted++
+				}
+				continue
+			}
+
+			doc := h.prepare(intel)
+			if h.isUnchanged(doc) {
+				report.Unchanged++
+				continue
+			}
+			pending = append(pending, doc)
+			changed = append(changed, intel)
+		}
+
+		embedded, failed := h.embedAll(ctx, pending)
+		report.Embedded += embedded
+		report.Failed += failed
+
+		if err := ctx.Err(); err != nil {
+			h.saveCheckpoint()
+			return report, changed, fmt.Errorf("harvest interrupted: %w", err)
+		}
+
+		// Documents that failed to embed are asked for again by the next
+		// harvest, which would skip them if the source was marked harvested.
+		if failed == 0 {
+			h.lastHarvest[source.Name()] = startedAt
+		}
+		h.saveCheckpoint()
+	}
+
+	if len(h.sources) > 0 && report.SourcesFailed == len(h.sources) {
+		return report, changed, fmt.Errorf("all %d intelligence sources failed", len(h.sources))
+	}
+
+	fmt.Printf("Harvested %d security intelligence documents (%d embedded, %d unchanged, %d failed, %d evicted)\n",
+		report.Fetched, report.Embedded, report.Unchanged, report.Failed, report.Evicted)
+	return report, changed, nil
 }
 
 // HarvestMockData creates and processes mock security intelligence data.
 func (h *Harvester) HarvestMockData(ctx context.Context) error {
 	mockData := h.generateMockSecurityData()
-	
+
 	for _, intelligence := range mockData {
-		// Create document text from intelligence data
-		docText := fmt.Sprintf("Title: %s. Description: %s. Component: %s, Version: %s. Severity: %s. Source: %s.",
-			intelligence.Title,
-			intelligence.Description,
-			intelligence.Component,
-			intelligence.Version,
-			intelligence.Severity,
-			intelligence.Source)
-		
-		// Generate embedding for the document
-		embedding, err := h.generateEmbedding(ctx, docText)
-		if err != nil {
-			fmt.Printf("Warning: Failed to generate embedding for document %s: %v\n", intelligence.ID, err)
-			continue
+		if _, err := h.ingest(ctx, intelligence); err != nil {
+			fmt.Printf("Warning: Failed to ingest document %s: %v\n", intelligence.ID, err)
 		}
-		
-		// Create document and add to vector database
-		doc := Document{
-			ID:     intelligence.ID,
-			Text:   docText,
-			Vector: embedding,
+	}
+
+	fmt.Printf("Successfully harvested %d security intelligence documents\n", len(mockData))
+	return nil
+}
+
+// pendingDocument is a document awaiting its embedding.
+type pendingDocument struct {
+	Document
+	fingerprint string
+}
+
+// prepare builds the document text and metadata for an intelligence record.
+func (h *Harvester) prepare(intelligence SecurityIntelligence) pendingDocument {
+	// Create document text from intelligence data
+	docText := fmt.Sprintf("Title: %s. Description: %s. Component: %s, Version: %s. Severity: %s. Source: %s.",
+		intelligence.Title,
+		intelligence.Description,
+		intelligence.Component,
+		intelligence.Version,
+		intelligence.Severity,
+		intelligence.Source)
+
+	sum := sha256.Sum256([]byte(docText))
+
+	return pendingDocument{
+		Document: Document{
+			ID:   intelligence.ID,
+			Text: docText,
 			Metadata: map[string]interface{}{
 				"component": intelligence.Component,
 				"version":   intelligence.Version,
@@ -73,16 +275,100 @@ func (h *Harvester) HarvestMockData(ctx context.Context) error {
 				"source":    intelligence.Source,
 				"date":      intelligence.Date,
 				"title":     intelligence.Title,
+				"url":       intelligence.URL,
 			},
+		},
+		fingerprint: hex.EncodeToString(sum[:]),
+	}
+}
+
+// isUnchanged reports whether an identical document is already stored.
+func (h *Harvester) isUnchanged(doc pendingDocument) bool {
+	if h.fingerprints[doc.ID] == doc.fingerprint {
+		_, exists := h.vectorDB.Get(doc.ID)
+		return exists
+	}
+
+	// A shared vector store may already hold this document from another instance
+	if existing, exists := h.vectorDB.Get(doc.ID); exists && existing.Text == doc.Text {
+		h.fingerprints[doc.ID] = doc.fingerprint
+		return true
+	}
+	return false
+}
+
+// store adds an embedded document to the vector database and records its fingerprint.
+func (h *Harvester) store(doc pendingDocument) error {
+	if err := h.vectorDB.Add(doc.Document); err != nil {
+		return fmt.Errorf("failed to add document to vector DB: %w", err)
+	}
+	h.fingerprints[doc.ID] = doc.fingerprint
+	if date, _ := doc.Metadata["date"].(string); date != "" {
+		h.documentDates[doc.ID] = date
+	}
+	return nil
+}
+
+// remove deletes a document from the vector database and forgets it,
+// reporting whether it was stored.
+func (h *Harvester) remove(id string) bool {
+	delete(h.fingerprints, id)
+	delete(h.documentDates, id)
+	return h.vectorDB.Delete(id)
+}
+
+// EvictOlderThan removes harvested documents published before cutoff and
+// returns how many were removed. Documents without a publication date and
+// manually added documents are kept.
+func (h *Harvester) EvictOlderThan(cutoff time.Time) int {
+	evicted := 0
+	for id, date := range h.documentDates {
+		if _, isManual := h.manual[id]; isManual {
+			continue
 		}
-		
-		if err := h.vectorDB.Add(doc); err != nil {
-			fmt.Printf("Warning: Failed to add document to vector DB: %v\n", err)
+		published, err := time.Parse("2006-01-02", date)
+		if err != nil || !published.Before(cutoff) {
+			continue
+		}
+		if h.remove(id) {
+			evicted++
 		}
 	}
-	
-	fmt.Printf("Successfully harvested %d security intelligence documents\n", len(mockData))
-	return nil
+	if evicted > 0 {
+		h.saveCheckpoint()
+	}
+	return evicted
+}
+
+// SourceNames returns the names of the configured intelligence sources.
+func (h *Harvester) SourceNames() []string {
+	names := make([]string, 0, len(h.sources))
+	for _, source := range h.sources {
+		names = append(names, source.Name())
+	}
+	return names
+}
+
+// ingest embeds a single intelligence record and stores it in the vector database.
+// It returns false without calling the embedding model if an identical
+// document is already stored.
+func (h *Harvester) ingest(ctx context.Context, intelligence SecurityIntelligence) (bool, error) {
+	doc := h.prepare(intelligence)
+	if h.isUnchanged(doc) {
+		return false, nil
+	}
+
+	// Generate embedding for the document
+	embedding, err := h.generateEmbedding(ctx, doc.Text)
+	if err != nil {
+		return false, fmt.Errorf("failed to generate embedding: %w", err)
+	}
+	doc.Vector = embedding
+
+	if err := h.store(doc); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // generateMockSecurityData creates mock security intelligence data.
@@ -185,36 +471,36 @@ type OllamaEmbeddingResponse struct {
 // generateEmbedding generates an embedding for the given text using Ollama.
 func (h *Harvester) generateEmbedding(ctx context.Context, text string) ([]float64, error) {
 	reqPayload := OllamaEmbeddingRequest{
-		Model:  "llama3",
+		Model:  h.embeddingModel,
 		Prompt: text,
 	}
-	
+
 	reqBody, err := json.Marshal(reqPayload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	
+
 	req, err := http.NewRequestWithContext(ctx, "POST", h.ollamaURL, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	
+
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("Ollama API returned status %d", resp.StatusCode)
 	}
-	
+
 	var ollamaResp OllamaEmbeddingResponse
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-	
+
 	return ollamaResp.Embedding, nil
-}
\ No newline at end of file
+}
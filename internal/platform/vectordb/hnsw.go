@@ -0,0 +1,301 @@
+// Package vectordb provides an HNSW approximate nearest neighbour index.
+package vectordb
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+)
+
+// HNSW parameters. M bounds the neighbours kept per node on upper layers
+// (twice that on layer 0); efConstruction and efSearch trade build and query
+// time for recall.
+const (
+	hnswM              = 16
+	hnswEfConstruction = 200
+	hnswEfSearch       = 64
+)
+
+// hnswNode is a single vector in the graph.
+type hnswNode struct {
+	id        string
+	vector    []float32
+	neighbors [][]int32 // neighbour node indices per layer
+	deleted   bool
+}
+
+// hnswIndex is a Hierarchical Navigable Small World graph over unit vectors,
+// giving logarithmic-time approximate cosine similarity search.
+// Vectors are normalized on insert, so similarity is a dot product.
+// Deleted nodes stay in the graph as tombstones to keep it navigable and are
+// filtered from results; the owner rebuilds the index once they dominate.
+type hnswIndex struct {
+	dimensions int
+	levelMult  float64
+	nodes      []*hnswNode
+	byID       map[string]int32
+	entry      int32
+	maxLevel   int
+	live       int
+	rng        *rand.Rand
+}
+
+// newHNSWIndex creates an empty index for vectors of the given dimensionality.
+func newHNSWIndex(dimensions int) *hnswIndex {
+	return &hnswIndex{
+		dimensions: dimensions,
+		levelMult:  1 / math.Log(hnswM),
+		byID:       make(map[string]int32),
+		entry:      -1,
+		rng:        rand.New(rand.NewSource(1)), // Deterministic graphs make results reproducible
+	}
+}
+
+// hnswCandidate is a node together with its distance to the query.
+type hnswCandidate struct {
+	node     int32
+	distance float32
+}
+
+// minHeap pops the closest candidate first.
+type minHeap []hnswCandidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].distance < h[j].distance }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(hnswCandidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// maxHeap pops the furthest candidate first.
+type maxHeap struct{ minHeap }
+
+func (h maxHeap) Less(i, j int) bool { return h.minHeap[i].distance > h.minHeap[j].distance }
+
+// insert adds a vector to the index, replacing any existing vector with the same ID.
+func (idx *hnswIndex) insert(id string, vector []float64) {
+	idx.remove(id)
+
+	node := &hnswNode{id: id, vector: normalize(vector)}
+	level := int(-math.Log(1-idx.rng.Float64()) * idx.levelMult)
+	node.neighbors = make([][]int32, level+1)
+
+	nodeIdx := int32(len(idx.nodes))
+	idx.nodes = append(idx.nodes, node)
+	idx.byID[id] = nodeIdx
+	idx.live++
+
+	if idx.entry < 0 {
+		idx.entry = nodeIdx
+		idx.maxLevel = level
+		return
+	}
+
+	// Descend greedily through the layers above the node's level
+	entry := idx.entry
+	for l := idx.maxLevel; l > level; l-- {
+		entry = idx.greedyClosest(node.vector, entry, l)
+	}
+
+	entryPoints := []int32{entry}
+	for l := min(level, idx.maxLevel); l >= 0; l-- {
+		candidates := idx.searchLayer(node.vector, entryPoints, hnswEfConstruction, l)
+
+		maxNeighbors := hnswM
+		if l == 0 {
+			maxNeighbors = 2 * hnswM
+		}
+
+		neighbors := make([]int32, 0, maxNeighbors)
+		for i := 0; i < len(candidates) && i < hnswM; i++ {
+			neighbors = append(neighbors, candidates[i].node)
+		}
+		node.neighbors[l] = neighbors
+
+		// Link back and keep each neighbour's list within bounds
+		for _, neighbor := range neighbors {
+			n := idx.nodes[neighbor]
+			n.neighbors[l] = append(n.neighbors[l], nodeIdx)
+			if len(n.neighbors[l]) > maxNeighbors {
+				n.neighbors[l] = idx.closestOf(n.vector, n.neighbors[l], maxNeighbors)
+			}
+		}
+
+		entryPoints = entryPoints[:0]
+		for _, c := range candidates {
+			entryPoints = append(entryPoints, c.node)
+		}
+	}
+
+	if level > idx.maxLevel {
+		idx.entry = nodeIdx
+		idx.maxLevel = level
+	}
+}
+
+// remove marks the vector with the given ID as deleted, reporting whether it was present.
+func (idx *hnswIndex) remove(id string) bool {
+	nodeIdx, exists := idx.byID[id]
+	if !exists {
+		return false
+	}
+	idx.nodes[nodeIdx].deleted = true
+	delete(idx.byID, id)
+	idx.live--
+	return true
+}
+
+// tombstones returns the number of deleted nodes still held in the graph.
+func (idx *hnswIndex) tombstones() int {
+	return len(idx.nodes) - idx.live
+}
+
+// search returns the IDs of up to k live vectors most similar to the query.
+func (idx *hnswIndex) search(query []float64, k int) []string {
+	if idx.entry < 0 || k <= 0 {
+		return nil
+	}
+
+	q := normalize(query)
+	entry := idx.entry
+	for l := idx.maxLevel; l > 0; l-- {
+		entry = idx.greedyClosest(q, entry, l)
+	}
+
+	// Widen the beam to make up for tombstones that will be filtered out
+	ef := max(hnswEfSearch, k)
+	if idx.live > 0 {
+		ef = min(len(idx.nodes), ef*len(idx.nodes)/idx.live)
+	}
+
+	var ids []string
+	for _, c := range idx.searchLayer(q, []int32{entry}, ef, 0) {
+		node := idx.nodes[c.node]
+		if node.deleted {
+			continue
+		}
+		ids = append(ids, node.id)
+		if len(ids) == k {
+			break
+		}
+	}
+	return ids
+}
+
+// greedyClosest walks from entry towards the query on a single layer.
+func (idx *hnswIndex) greedyClosest(q []float32, entry int32, level int) int32 {
+	current := entry
+	currentDist := distance(q, idx.nodes[current].vector)
+
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range idx.nodes[current].neighbors[level] {
+			if d := distance(q, idx.nodes[neighbor].vector); d < currentDist {
+				current, currentDist = neighbor, d
+				changed = true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer performs a beam search of width ef on a single layer and returns
+// the candidates found, closest first.
+func (idx *hnswIndex) searchLayer(q []float32, entryPoints []int32, ef int, level int) []hnswCandidate {
+	visited := make(map[int32]struct{}, ef*hnswM)
+	candidates := &minHeap{}
+	results := &maxHeap{}
+
+	for _, ep := range entryPoints {
+		if _, seen := visited[ep]; seen {
+			continue
+		}
+		visited[ep] = struct{}{}
+		c := hnswCandidate{node: ep, distance: distance(q, idx.nodes[ep].vector)}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+	}
+	for results.Len() > ef {
+		heap.Pop(results)
+	}
+
+	for candidates.Len() > 0 {
+		closest := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && closest.distance > results.minHeap[0].distance {
+			break
+		}
+
+		node := idx.nodes[closest.node]
+		if level >= len(node.neighbors) {
+			continue
+		}
+		for _, neighbor := range node.neighbors[level] {
+			if _, seen := visited[neighbor]; seen {
+				continue
+			}
+			visited[neighbor] = struct{}{}
+
+			d := distance(q, idx.nodes[neighbor].vector)
+			if results.Len() < ef || d < results.minHeap[0].distance {
+				heap.Push(candidates, hnswCandidate{node: neighbor, distance: d})
+				heap.Push(results, hnswCandidate{node: neighbor, distance: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	// Drain the max-heap into ascending order
+	sorted := make([]hnswCandidate, results.Len())
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return sorted
+}
+
+// closestOf returns the n nodes from candidates closest to v.
+func (idx *hnswIndex) closestOf(v []float32, candidates []int32, n int) []int32 {
+	h := make(minHeap, 0, len(candidates))
+	for _, c := range candidates {
+		h = append(h, hnswCandidate{node: c, distance: distance(v, idx.nodes[c].vector)})
+	}
+	heap.Init(&h)
+
+	closest := make([]int32, 0, n)
+	for h.Len() > 0 && len(closest) < n {
+		closest = append(closest, heap.Pop(&h).(hnswCandidate).node)
+	}
+	return closest
+}
+
+// distance is the cosine distance between two unit vectors.
+func distance(a, b []float32) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+// normalize converts a vector to a unit-length float32 vector.
+func normalize(v []float64) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	norm = math.Sqrt(norm)
+
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	for i, x := range v {
+		out[i] = float32(x / norm)
+	}
+	return out
+}
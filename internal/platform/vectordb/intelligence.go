@@ -0,0 +1,358 @@
+// Package vectordb provides a shared, lazily-initialized security intelligence corpus.
+package vectordb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultEmbeddingModel is the Ollama model used for embeddings unless configured otherwise.
+const DefaultEmbeddingModel = "llama3"
+
+// DefaultOllamaHost is the Ollama server used unless OLLAMA_HOST is set.
+const DefaultOllamaHost = "http://localhost:11434"
+
+// OllamaURL returns the URL of an Ollama API path on the server of the
+// OLLAMA_HOST environment variable, given as a URL or, like the Ollama CLI
+// accepts it, as host:port.
+func OllamaURL(path string) string {
+	host := strings.TrimRight(os.Getenv("OLLAMA_HOST"), "/")
+	if host == "" {
+		host = DefaultOllamaHost
+	} else if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return host + path
+}
+
+// IntelligenceStore is a security intelligence corpus shared by every analysis
+// in a process. The corpus is harvested on first use rather than at startup,
+// and concurrent callers wait for that single harvest instead of each
+// building their own. It can also be kept fresh by a background refresher.
+// It is safe for concurrent use as long as the underlying VectorDB is.
+type IntelligenceStore struct {
+	db        VectorDB
+	harvester *Harvester
+
+	// mu serializes harvests and guards the harvester
+	mu sync.Mutex
+
+	// statusMu guards the settings and refresh status, which are read while
+	// a harvest runs; initialized is only written with both locks held
+	statusMu        sync.RWMutex
+	initialized     bool
+	sources         []string
+	refreshInterval time.Duration
+	maxAge          time.Duration
+	lastRefresh     time.Time
+	lastReport      *HarvestReport
+	lastError       string
+	onNew           func(ctx context.Context, intelligence []SecurityIntelligence)
+}
+
+// IntelligenceStatus describes the state of the security intelligence corpus.
+type IntelligenceStatus struct {
+	Initialized     bool           `json:"initialized"`
+	Documents       int            `json:"documents"`
+	Sources         []string       `json:"sources"`
+	LastRefresh     *time.Time     `json:"last_refresh,omitempty"`
+	LastReport      *HarvestReport `json:"last_report,omitempty"`
+	LastError       string         `json:"last_error,omitempty"`
+	RefreshInterval string         `json:"refresh_interval,omitempty"`
+	MaxAge          string         `json:"max_age,omitempty"`
+}
+
+// NewIntelligenceStore creates a store over db, populated by harvester on first use.
+// A nil harvester means db is already populated and nothing is harvested.
+func NewIntelligenceStore(db VectorDB, harvester *Harvester) *IntelligenceStore {
+	store := &IntelligenceStore{
+		db:        db,
+		harvester: harvester,
+		sources:   []string{},
+	}
+	if harvester != nil {
+		store.sources = harvester.SourceNames()
+	}
+	return store
+}
+
+// ConfigureRefreshFromEnv applies the refresh settings from the environment:
+// INTEL_REFRESH_INTERVAL (how often StartRefresher re-harvests) and
+// INTEL_MAX_AGE (how old a document may be before it is evicted), both as
+// Go durations such as "6h". Invalid values are reported and ignored.
+func (s *IntelligenceStore) ConfigureRefreshFromEnv() error {
+	var errs []error
+
+	if value := os.Getenv("INTEL_REFRESH_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("invalid INTEL_REFRESH_INTERVAL %q", value))
+		} else {
+			s.SetRefreshInterval(interval)
+		}
+	}
+
+	if value := os.Getenv("INTEL_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge <= 0 {
+			errs = append(errs, fmt.Errorf("invalid INTEL_MAX_AGE %q", value))
+		} else {
+			s.SetMaxAge(maxAge)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetRefreshInterval sets how often StartRefresher re-harvests the corpus.
+// Zero disables the refresher.
+func (s *IntelligenceStore) SetRefreshInterval(interval time.Duration) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.refreshInterval = interval
+}
+
+// SetMaxAge sets how long after publication a document is evicted from the
+// corpus on refresh. Zero keeps documents indefinitely.
+func (s *IntelligenceStore) SetMaxAge(maxAge time.Duration) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.maxAge = maxAge
+}
+
+// OnNewIntelligence registers a function called in the background with the
+// new and changed documents of each harvest. The first harvest into an empty
+// corpus is not reported, as every document would be new.
+func (s *IntelligenceStore) OnNewIntelligence(listener func(ctx context.Context, intelligence []SecurityIntelligence)) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.onNew = listener
+}
+
+// EnsureInitialized harvests the corpus if that has not been done yet.
+// With no configured sources, built-in sample data is loaded instead.
+// A failed harvest is retried on the next call.
+func (s *IntelligenceStore) EnsureInitialized(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.initialized || s.harvester == nil {
+		return nil
+	}
+
+	fmt.Println("🔍 Initializing security intelligence database...")
+
+	if err := s.refresh(ctx); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Security intelligence database initialized with %d documents\n", s.db.Size())
+	return nil
+}
+
+// Refresh re-harvests the configured sources, upserting new and changed
+// documents and evicting withdrawn or expired ones. Without sources, the
+// built-in sample data is loaded once and only eviction applies.
+func (s *IntelligenceStore) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.harvester == nil {
+		return nil
+	}
+	return s.refresh(ctx)
+}
+
+// refresh performs a harvest and records its outcome. The caller must hold mu.
+func (s *IntelligenceStore) refresh(ctx context.Context) error {
+	report := HarvestReport{}
+	var changed []SecurityIntelligence
+	var err error
+
+	populated := s.db.Size() > 0
+	if s.harvester.HasSources() {
+		report, changed, err = s.harvester.harvest(ctx)
+	} else if !s.initialized {
+		err = s.harvester.HarvestMockData(ctx)
+	}
+
+	s.statusMu.RLock()
+	maxAge := s.maxAge
+	onNew := s.onNew
+	s.statusMu.RUnlock()
+
+	if onNew != nil && populated && len(changed) > 0 {
+		go onNew(context.WithoutCancel(ctx), changed)
+	}
+
+	if err == nil && !s.initialized {
+		if restored := s.harvester.restoreDocuments(ctx); restored > 0 {
+			fmt.Printf("Restored %d manually added intelligence documents\n", restored)
+		}
+	}
+	if err == nil && maxAge > 0 {
+		report.Evicted += s.harvester.EvictOlderThan(time.Now().Add(-maxAge))
+	}
+
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	if err == nil {
+		s.initialized = true
+	}
+	s.lastRefresh = time.Now()
+	s.lastReport = &report
+	s.lastError = ""
+	if err != nil {
+		s.lastError = err.Error()
+		return fmt.Errorf("failed to harvest security data: %w", err)
+	}
+	return nil
+}
+
+// StartRefresher re-harvests the corpus every refresh interval until ctx is
+// cancelled. It does nothing if no interval is configured or the store has
+// no harvester. Failed refreshes are logged and retried on the next tick.
+func (s *IntelligenceStore) StartRefresher(ctx context.Context) {
+	s.statusMu.RLock()
+	interval := s.refreshInterval
+	s.statusMu.RUnlock()
+
+	if interval <= 0 || s.harvester == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Refresh(ctx); err != nil {
+					fmt.Printf("Warning: Scheduled intelligence refresh failed: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// Status reports the corpus size and the outcome of the last refresh.
+// It does not wait for a harvest in progress.
+func (s *IntelligenceStore) Status() IntelligenceStatus {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+
+	status := IntelligenceStatus{
+		Initialized: s.initialized || s.harvester == nil,
+		Documents:   s.db.Size(),
+		Sources:     append([]string{}, s.sources...),
+		LastError:   s.lastError,
+	}
+	if s.refreshInterval > 0 {
+		status.RefreshInterval = s.refreshInterval.String()
+	}
+	if s.maxAge > 0 {
+		status.MaxAge = s.maxAge.String()
+	}
+	if !s.lastRefresh.IsZero() {
+		lastRefresh := s.lastRefresh
+		status.LastRefresh = &lastRefresh
+	}
+	if s.lastReport != nil {
+		report := *s.lastReport
+		status.LastReport = &report
+	}
+	return status
+}
+
+// Ping verifies that the vector store holding the corpus is reachable.
+func (s *IntelligenceStore) Ping(ctx context.Context) error {
+	if pinger, ok := s.db.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Search returns the k documents most similar to the query vector.
+func (s *IntelligenceStore) Search(queryVector []float64, k int) ([]SearchResult, error) {
+	return s.db.Search(queryVector, k)
+}
+
+// Size returns the number of documents in the corpus.
+func (s *IntelligenceStore) Size() int {
+	return s.db.Size()
+}
+
+// EmbeddingModel returns the Ollama model the corpus is embedded with.
+// Queries must be embedded with the same model to be comparable.
+func (s *IntelligenceStore) EmbeddingModel() string {
+	if s.harvester == nil {
+		return DefaultEmbeddingModel
+	}
+	return s.harvester.embeddingModel
+}
+
+// SetEmbeddingModel sets the Ollama model used to embed the corpus.
+// It has no effect when the store has no harvester.
+func (s *IntelligenceStore) SetEmbeddingModel(model string) {
+	if s.harvester != nil {
+		s.harvester.SetEmbeddingModel(model)
+	}
+}
+
+// AddDocument embeds and stores a manually curated intelligence record,
+// returning it with defaults applied. Validation failures wrap ErrInvalidDocument.
+func (s *IntelligenceStore) AddDocument(ctx context.Context, intelligence SecurityIntelligence) (SecurityIntelligence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.harvester == nil {
+		return SecurityIntelligence{}, fmt.Errorf("intelligence corpus is read-only")
+	}
+	return s.harvester.AddDocument(ctx, intelligence)
+}
+
+// RemoveDocument deletes a document from the corpus and reports whether it existed.
+func (s *IntelligenceStore) RemoveDocument(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.harvester == nil {
+		return s.db.Delete(id)
+	}
+	return s.harvester.RemoveDocument(id)
+}
+
+// Documents returns the manually added documents ordered by ID.
+func (s *IntelligenceStore) Documents() []SecurityIntelligence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.harvester == nil {
+		return []SecurityIntelligence{}
+	}
+	return s.harvester.Documents()
+}
+
+// AddSource registers an additional intelligence source for the next harvest.
+// It has no effect when the store has no harvester.
+func (s *IntelligenceStore) AddSource(source Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.harvester != nil {
+		s.harvester.AddSource(source)
+
+		s.statusMu.Lock()
+		s.sources = s.harvester.SourceNames()
+		s.statusMu.Unlock()
+	}
+}
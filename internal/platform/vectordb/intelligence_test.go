@@ -0,0 +1,208 @@
+package vectordb
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntelligenceStore_EnsureInitializedHarvestsOnce(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	db := NewMemoryVectorDB()
+	harvester := newTestHarvester(db, server.URL)
+	source := &staticSource{intelligence: []SecurityIntelligence{{ID: "a", Title: "First"}}}
+	harvester.AddSource(source)
+	store := NewIntelligenceStore(db, harvester)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.EnsureInitialized(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, source.sinces, 1)
+	assert.Equal(t, 1, store.Size())
+}
+
+// failingSource always fails to fetch.
+type failingSource struct{ calls int }
+
+func (s *failingSource) Name() string { return "failing" }
+
+func (s *failingSource) Fetch(ctx context.Context, since time.Time) ([]SecurityIntelligence, error) {
+	s.calls++
+	return nil, errors.New("unavailable")
+}
+
+func TestIntelligenceStore_RetriesFailedInitialization(t *testing.T) {
+	harvester := NewHarvester(NewMemoryVectorDB())
+	source := &failingSource{}
+	harvester.AddSource(source)
+	store := NewIntelligenceStore(NewMemoryVectorDB(), harvester)
+
+	require.Error(t, store.EnsureInitialized(context.Background()))
+	require.Error(t, store.EnsureInitialized(context.Background()))
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestIntelligenceStore_WithoutHarvester(t *testing.T) {
+	store := NewIntelligenceStore(NewMemoryVectorDB(), nil)
+	assert.NoError(t, store.EnsureInitialized(context.Background()))
+	assert.Equal(t, DefaultEmbeddingModel, store.EmbeddingModel())
+}
+
+func TestIntelligenceStore_RefreshUpsertsAndEvicts(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	db := NewMemoryVectorDB()
+	harvester := newTestHarvester(db, server.URL)
+	recent := time.Now().Format("2006-01-02")
+	source := &staticSource{intelligence: []SecurityIntelligence{
+		{ID: "current", Title: "Current advisory", Date: recent},
+		{ID: "expired", Title: "Old advisory", Date: "2015-03-01"},
+		{ID: "withdrawn", Title: "Retracted advisory", Date: recent},
+	}}
+	harvester.AddSource(source)
+	store := NewIntelligenceStore(db, harvester)
+	store.SetMaxAge(365 * 24 * time.Hour)
+
+	require.NoError(t, store.Refresh(context.Background()))
+	assert.Equal(t, 2, store.Size())
+	_, found := db.Get("expired")
+	assert.False(t, found)
+
+	// The next refresh picks up changes and withdrawals
+	source.intelligence = []SecurityIntelligence{
+		{ID: "current", Title: "Current advisory, updated", Date: recent},
+		{ID: "withdrawn", Title: "Retracted advisory", Date: recent, Withdrawn: true},
+	}
+	require.NoError(t, store.Refresh(context.Background()))
+
+	doc, found := db.Get("current")
+	require.True(t, found)
+	assert.Contains(t, doc.Text, "updated")
+	_, found = db.Get("withdrawn")
+	assert.False(t, found)
+
+	status := store.Status()
+	assert.True(t, status.Initialized)
+	assert.Equal(t, 1, status.Documents)
+	assert.Equal(t, []string{"static"}, status.Sources)
+	require.NotNil(t, status.LastRefresh)
+	require.NotNil(t, status.LastReport)
+	assert.Equal(t, 1, status.LastReport.Embedded)
+	assert.Equal(t, 1, status.LastReport.Evicted)
+	assert.Empty(t, status.LastError)
+	assert.Equal(t, "8760h0m0s", status.MaxAge)
+}
+
+func TestIntelligenceStore_OnNewIntelligence(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	db := NewMemoryVectorDB()
+	harvester := newTestHarvester(db, server.URL)
+	source := &staticSource{intelligence: []SecurityIntelligence{{ID: "a", Title: "First"}}}
+	harvester.AddSource(source)
+	store := NewIntelligenceStore(db, harvester)
+
+	reported := make(chan []SecurityIntelligence, 2)
+	store.OnNewIntelligence(func(ctx context.Context, intelligence []SecurityIntelligence) {
+		reported <- intelligence
+	})
+
+	// The initial harvest into the empty corpus is not reported
+	require.NoError(t, store.EnsureInitialized(context.Background()))
+
+	source.intelligence = []SecurityIntelligence{
+		{ID: "a", Title: "First"},
+		{ID: "b", Title: "Second"},
+	}
+	require.NoError(t, store.Refresh(context.Background()))
+
+	select {
+	case intelligence := <-reported:
+		require.Len(t, intelligence, 1)
+		assert.Equal(t, "b", intelligence[0].ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("new intelligence not reported")
+	}
+	assert.Empty(t, reported)
+}
+
+func TestIntelligenceStore_StatusReportsFailure(t *testing.T) {
+	harvester := NewHarvester(NewMemoryVectorDB())
+	harvester.AddSource(&failingSource{})
+	store := NewIntelligenceStore(NewMemoryVectorDB(), harvester)
+
+	status := store.Status()
+	assert.False(t, status.Initialized)
+	assert.Nil(t, status.LastRefresh)
+
+	require.Error(t, store.Refresh(context.Background()))
+	status = store.Status()
+	assert.False(t, status.Initialized)
+	assert.NotNil(t, status.LastRefresh)
+	assert.Contains(t, status.LastError, "sources failed")
+}
+
+func TestIntelligenceStore_StartRefresher(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	db := NewMemoryVectorDB()
+	harvester := newTestHarvester(db, server.URL)
+	harvester.AddSource(&staticSource{intelligence: []SecurityIntelligence{{ID: "a", Title: "First"}}})
+	store := NewIntelligenceStore(db, harvester)
+	store.SetRefreshInterval(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store.StartRefresher(ctx)
+
+	assert.Eventually(t, func() bool {
+		return store.Status().LastRefresh != nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, store.Size())
+}
+
+func TestIntelligenceStore_ConfigureRefreshFromEnv(t *testing.T) {
+	t.Setenv("INTEL_REFRESH_INTERVAL", "6h")
+	t.Setenv("INTEL_MAX_AGE", "forever")
+
+	store := NewIntelligenceStore(NewMemoryVectorDB(), NewHarvester(NewMemoryVectorDB()))
+	assert.Error(t, store.ConfigureRefreshFromEnv())
+
+	status := store.Status()
+	assert.Equal(t, "6h0m0s", status.RefreshInterval)
+	assert.Empty(t, status.MaxAge)
+}
+
+func TestOllamaURL(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "")
+	assert.Equal(t, "http://localhost:11434/api/tags", OllamaURL("/api/tags"))
+
+	t.Setenv("OLLAMA_HOST", "http://ollama:11434/")
+	assert.Equal(t, "http://ollama:11434/api/generate", OllamaURL("/api/generate"))
+
+	// Like the Ollama CLI, a bare host:port is accepted
+	t.Setenv("OLLAMA_HOST", "ollama:11434")
+	assert.Equal(t, "http://ollama:11434/api/embed", OllamaURL("/api/embed"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"sync"
 )
 
 // Document represents a document stored in the vector database.
@@ -21,15 +22,27 @@ type SearchResult struct {
 	Similarity float64  `json:"similarity"`
 }
 
+// defaultExactSearchThreshold is the corpus size up to which Search scans every
+// document; exact search is cheap at that size and avoids approximation.
+const defaultExactSearchThreshold = 1000
+
 // MemoryVectorDB is a simple in-memory vector database.
+// Larger corpora are searched through an HNSW index per vector dimensionality.
+// It is safe for concurrent use.
 type MemoryVectorDB struct {
+	mu        sync.RWMutex
 	documents map[string]Document
+	indexes   map[int]*hnswIndex
+
+	exactSearchThreshold int
 }
 
 // NewMemoryVectorDB creates a new instance of MemoryVectorDB.
 func NewMemoryVectorDB() *MemoryVectorDB {
 	return &MemoryVectorDB{
-		documents: make(map[string]Document),
+		documents:            make(map[string]Document),
+		indexes:              make(map[int]*hnswIndex),
+		exactSearchThreshold: defaultExactSearchThreshold,
 	}
 }
 
@@ -41,32 +54,98 @@ func (m *MemoryVectorDB) Add(doc Document) error {
 	if len(doc.Vector) == 0 {
 		return fmt.Errorf("document vector cannot be empty")
 	}
-	
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A replaced document may have had a different dimensionality
+	if old, exists := m.documents[doc.ID]; exists && len(old.Vector) != len(doc.Vector) {
+		m.removeFromIndex(old)
+	}
+
 	m.documents[doc.ID] = doc
+
+	index, exists := m.indexes[len(doc.Vector)]
+	if !exists {
+		index = newHNSWIndex(len(doc.Vector))
+		m.indexes[len(doc.Vector)] = index
+	}
+	index.insert(doc.ID, doc.Vector)
 	return nil
 }
 
 // Get retrieves a document by ID.
 func (m *MemoryVectorDB) Get(id string) (Document, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	doc, exists := m.documents[id]
 	return doc, exists
 }
 
 // Delete removes a document from the database.
 func (m *MemoryVectorDB) Delete(id string) bool {
-	if _, exists := m.documents[id]; exists {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if doc, exists := m.documents[id]; exists {
 		delete(m.documents, id)
+		m.removeFromIndex(doc)
 		return true
 	}
 	return false
 }
 
+// removeFromIndex drops a document from its HNSW index, rebuilding the index
+// once deleted entries outnumber live ones so searches stay efficient.
+func (m *MemoryVectorDB) removeFromIndex(doc Document) {
+	index, exists := m.indexes[len(doc.Vector)]
+	if !exists || !index.remove(doc.ID) {
+		return
+	}
+
+	if index.live == 0 {
+		delete(m.indexes, len(doc.Vector))
+		return
+	}
+	if index.tombstones() > index.live {
+		rebuilt := newHNSWIndex(index.dimensions)
+		for _, node := range index.nodes {
+			if !node.deleted {
+				rebuilt.insert(node.id, m.documents[node.id].Vector)
+			}
+		}
+		m.indexes[len(doc.Vector)] = rebuilt
+	}
+}
+
 // Search performs similarity search and returns top k most similar documents.
 func (m *MemoryVectorDB) Search(queryVector []float64, k int) ([]SearchResult, error) {
 	if len(queryVector) == 0 {
 		return nil, fmt.Errorf("query vector cannot be empty")
 	}
 
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Documents with a different dimensionality can never match
+	index, exists := m.indexes[len(queryVector)]
+	if !exists {
+		return nil, nil
+	}
+
+	if index.live > m.exactSearchThreshold {
+		var results []SearchResult
+		for _, id := range index.search(queryVector, k) {
+			doc := m.documents[id]
+			results = append(results, SearchResult{
+				Document:   doc,
+				Similarity: cosineSimilarity(queryVector, doc.Vector),
+			})
+		}
+		return results, nil
+	}
+
 	var results []SearchResult
 	
 	// Calculate cosine similarity for each document
@@ -97,12 +176,19 @@ func (m *MemoryVectorDB) Search(queryVector []float64, k int) ([]SearchResult, e
 
 // Size returns the number of documents in the database.
 func (m *MemoryVectorDB) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	return len(m.documents)
 }
 
 // Clear removes all documents from the database.
 func (m *MemoryVectorDB) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.documents = make(map[string]Document)
+	m.indexes = make(map[int]*hnswIndex)
 }
 
 // cosineSimilarity calculates the cosine similarity between two vectors.
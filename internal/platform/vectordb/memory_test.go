@@ -0,0 +1,138 @@
+package vectordb
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomVectors returns n reproducible random vectors of the given dimensionality.
+func randomVectors(n, dimensions int, seed int64) [][]float64 {
+	rng := rand.New(rand.NewSource(seed))
+	vectors := make([][]float64, n)
+	for i := range vectors {
+		vectors[i] = make([]float64, dimensions)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.NormFloat64()
+		}
+	}
+	return vectors
+}
+
+func TestMemoryVectorDB_IndexedSearchRecall(t *testing.T) {
+	const (
+		corpusSize = 3000
+		dimensions = 32
+		k          = 10
+	)
+
+	db := NewMemoryVectorDB()
+	for i, vector := range randomVectors(corpusSize, dimensions, 1) {
+		require.NoError(t, db.Add(Document{ID: fmt.Sprintf("doc-%d", i), Vector: vector}))
+	}
+
+	// Compare the index against exact search over the same corpus
+	exact := NewMemoryVectorDB()
+	exact.exactSearchThreshold = corpusSize
+	for id, doc := range db.documents {
+		exact.documents[id] = doc
+	}
+	exact.indexes = db.indexes
+
+	found, total := 0, 0
+	for _, query := range randomVectors(50, dimensions, 2) {
+		approximate, err := db.Search(query, k)
+		require.NoError(t, err)
+		require.Len(t, approximate, k)
+
+		expected, err := exact.Search(query, k)
+		require.NoError(t, err)
+
+		ids := make(map[string]bool)
+		for _, result := range approximate {
+			ids[result.Document.ID] = true
+		}
+		for _, result := range expected {
+			total++
+			if ids[result.Document.ID] {
+				found++
+			}
+		}
+
+		// Results are ordered by similarity
+		for i := 1; i < len(approximate); i++ {
+			assert.GreaterOrEqual(t, approximate[i-1].Similarity, approximate[i].Similarity)
+		}
+	}
+
+	recall := float64(found) / float64(total)
+	assert.GreaterOrEqual(t, recall, 0.95, "HNSW recall@%d too low", k)
+}
+
+func TestMemoryVectorDB_IndexedSearchDeleteAndReplace(t *testing.T) {
+	db := NewMemoryVectorDB()
+	db.exactSearchThreshold = 0
+
+	vectors := randomVectors(200, 8, 3)
+	for i, vector := range vectors {
+		require.NoError(t, db.Add(Document{ID: fmt.Sprintf("doc-%d", i), Vector: vector}))
+	}
+
+	results, err := db.Search(vectors[7], 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "doc-7", results[0].Document.ID)
+
+	// Deleted documents are never returned
+	assert.True(t, db.Delete("doc-7"))
+	results, err = db.Search(vectors[7], 5)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.NotEqual(t, "doc-7", result.Document.ID)
+	}
+
+	// Replacing a document moves it in the index
+	require.NoError(t, db.Add(Document{ID: "doc-8", Vector: vectors[7]}))
+	results, err = db.Search(vectors[7], 1)
+	require.NoError(t, err)
+	assert.Equal(t, "doc-8", results[0].Document.ID)
+
+	// Deleting most documents triggers a rebuild without losing the rest
+	for i := 0; i < 150; i++ {
+		db.Delete(fmt.Sprintf("doc-%d", i))
+	}
+	assert.Equal(t, 50, db.Size())
+	assert.LessOrEqual(t, db.indexes[8].tombstones(), db.indexes[8].live)
+
+	results, err = db.Search(vectors[199], 1)
+	require.NoError(t, err)
+	assert.Equal(t, "doc-199", results[0].Document.ID)
+}
+
+func TestMemoryVectorDB_SearchDimensionMismatch(t *testing.T) {
+	db := NewMemoryVectorDB()
+	require.NoError(t, db.Add(Document{ID: "a", Vector: []float64{1, 0, 0}}))
+
+	results, err := db.Search([]float64{1, 0}, 3)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = db.Search(nil, 3)
+	assert.Error(t, err)
+}
+
+func BenchmarkMemoryVectorDB_Search(b *testing.B) {
+	db := NewMemoryVectorDB()
+	for i, vector := range randomVectors(20000, 64, 1) {
+		db.Add(Document{ID: fmt.Sprintf("doc-%d", i), Vector: vector})
+	}
+	queries := randomVectors(100, 64, 2)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db.Search(queries[i%len(queries)], 3)
+	}
+}
@@ -0,0 +1,247 @@
+// Package vectordb provides a PostgreSQL pgvector-backed vector database.
+package vectordb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	// Registers the "pgx" database/sql driver
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PgVectorDB stores documents in a PostgreSQL table using the pgvector extension.
+// The table is created on first write, sized to the first document's vector.
+type PgVectorDB struct {
+	db    *sql.DB
+	table string
+	ready bool
+}
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NewPgVectorDB connects to PostgreSQL using dsn and stores documents in table.
+func NewPgVectorDB(dsn, table string) (*PgVectorDB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	return NewPgVectorDBFromDB(db, table)
+}
+
+// NewPgVectorDBFromDB creates a store on an existing database handle.
+func NewPgVectorDBFromDB(db *sql.DB, table string) (*PgVectorDB, error) {
+	// The table name is interpolated into SQL, so only plain identifiers are accepted
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid pgvector table name %q", table)
+	}
+	return &PgVectorDB{db: db, table: table}, nil
+}
+
+// Close closes the database connection.
+func (p *PgVectorDB) Close() error {
+	return p.db.Close()
+}
+
+// Ping implements the Pinger interface.
+func (p *PgVectorDB) Ping(ctx context.Context) error {
+	if err := p.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to reach PostgreSQL: %w", err)
+	}
+	return nil
+}
+
+// Add implements the VectorDB interface.
+func (p *PgVectorDB) Add(doc Document) error {
+	if doc.ID == "" {
+		return fmt.Errorf("document ID cannot be empty")
+	}
+	if len(doc.Vector) == 0 {
+		return fmt.Errorf("document vector cannot be empty")
+	}
+
+	if err := p.ensureTable(len(doc.Vector)); err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(doc.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, text, metadata, embedding) VALUES ($1, $2, $3, $4::vector)
+		ON CONFLICT (id) DO UPDATE SET text = EXCLUDED.text, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding`, p.table)
+	if _, err := p.db.Exec(query, doc.ID, doc.Text, string(metadata), formatVector(doc.Vector)); err != nil {
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+	return nil
+}
+
+// Get implements the VectorDB interface.
+func (p *PgVectorDB) Get(id string) (Document, bool) {
+	query := fmt.Sprintf(`SELECT id, text, metadata, embedding::text FROM %s WHERE id = $1`, p.table)
+
+	doc, _, err := scanVectorDocument(p.db.QueryRow(query, id), false)
+	if err != nil {
+		return Document{}, false
+	}
+	return doc, true
+}
+
+// Delete implements the VectorDB interface.
+func (p *PgVectorDB) Delete(id string) bool {
+	result, err := p.db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, p.table), id)
+	if err != nil {
+		return false
+	}
+	affected, err := result.RowsAffected()
+	return err == nil && affected > 0
+}
+
+// Search implements the VectorDB interface using cosine distance.
+func (p *PgVectorDB) Search(queryVector []float64, k int) ([]SearchResult, error) {
+	if len(queryVector) == 0 {
+		return nil, fmt.Errorf("query vector cannot be empty")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, text, metadata, embedding::text, 1 - (embedding <=> $1::vector) AS similarity
+		FROM %s ORDER BY embedding <=> $1::vector LIMIT $2`, p.table)
+
+	rows, err := p.db.Query(query, formatVector(queryVector), k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		doc, similarity, err := scanVectorDocument(rows, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, SearchResult{Document: doc, Similarity: similarity})
+	}
+	return results, rows.Err()
+}
+
+// Size implements the VectorDB interface.
+func (p *PgVectorDB) Size() int {
+	var count int
+	if err := p.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s`, p.table)).Scan(&count); err != nil {
+		return 0
+	}
+	return count
+}
+
+// Clear implements the VectorDB interface by dropping the table.
+func (p *PgVectorDB) Clear() {
+	if _, err := p.db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s`, p.table)); err != nil {
+		fmt.Printf("Warning: Failed to clear pgvector table %s: %v\n", p.table, err)
+	}
+	p.ready = false
+}
+
+// ensureTable creates the pgvector extension and documents table if needed.
+func (p *PgVectorDB) ensureTable(dimensions int) error {
+	if p.ready {
+		return nil
+	}
+
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			text TEXT NOT NULL,
+			metadata JSONB,
+			embedding vector(%d) NOT NULL
+		)`, p.table, dimensions),
+	}
+	for _, statement := range statements {
+		if _, err := p.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to initialize pgvector table: %w", err)
+		}
+	}
+
+	p.ready = true
+	return nil
+}
+
+// rowScanner abstracts *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanVectorDocument reads a document row, optionally followed by a similarity column.
+func scanVectorDocument(row rowScanner, withSimilarity bool) (Document, float64, error) {
+	var doc Document
+	var metadata sql.NullString
+	var embedding string
+	var similarity float64
+
+	dest := []interface{}{&doc.ID, &doc.Text, &metadata, &embedding}
+	if withSimilarity {
+		dest = append(dest, &similarity)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return Document{}, 0, err
+	}
+
+	if metadata.Valid {
+		if err := json.Unmarshal([]byte(metadata.String), &doc.Metadata); err != nil {
+			return Document{}, 0, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	vector, err := parseVector(embedding)
+	if err != nil {
+		return Document{}, 0, err
+	}
+	doc.Vector = vector
+
+	return doc, similarity, nil
+}
+
+// formatVector renders a vector in pgvector's text format, e.g. "[0.1,0.2]".
+func formatVector(vector []float64) string {
+	parts := make([]string, len(vector))
+	for i, v := range vector {
+		parts[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// parseVector parses pgvector's text format.
+func parseVector(s string) ([]float64, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("invalid vector %q", s)
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		return []float64{}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	vector := make([]float64, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector component %q: %w", part, err)
+		}
+		vector[i] = v
+	}
+	return vector, nil
+}
@@ -0,0 +1,201 @@
+package vectordb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePostgres is a database served by the fakepostgres database/sql driver.
+// It records the statements executed and answers queries with the rows
+// returned by respond.
+type fakePostgres struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	respond    func(query string) ([]string, [][]driver.Value)
+}
+
+var fakePostgresConnector = struct {
+	sync.Mutex
+	current *fakePostgres
+}{}
+
+func init() {
+	sql.Register("fakepostgres", fakePostgresDriver{})
+}
+
+type fakePostgresDriver struct{}
+
+func (fakePostgresDriver) Open(string) (driver.Conn, error) {
+	fakePostgresConnector.Lock()
+	defer fakePostgresConnector.Unlock()
+	return fakePostgresConn{fakePostgresConnector.current}, nil
+}
+
+type fakePostgresConn struct{ db *fakePostgres }
+
+func (c fakePostgresConn) Prepare(query string) (driver.Stmt, error) {
+	return fakePostgresStmt{db: c.db, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+func (fakePostgresConn) Close() error              { return nil }
+func (fakePostgresConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakePostgresStmt struct {
+	db    *fakePostgres
+	query string
+}
+
+func (fakePostgresStmt) Close() error  { return nil }
+func (fakePostgresStmt) NumInput() int { return -1 }
+
+func (s fakePostgresStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.record(args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakePostgresStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.record(args)
+	columns, rows := s.db.respond(s.query)
+	return &fakePostgresRows{columns: columns, rows: rows}, nil
+}
+
+func (s fakePostgresStmt) record(args []driver.Value) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.statements = append(s.db.statements, s.query)
+	s.db.args = append(s.db.args, args)
+}
+
+type fakePostgresRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakePostgresRows) Columns() []string { return r.columns }
+func (r *fakePostgresRows) Close() error      { return nil }
+
+func (r *fakePostgresRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newFakePgVectorDB returns a store on a fake PostgreSQL answering queries
+// with respond.
+func newFakePgVectorDB(t *testing.T, respond func(query string) ([]string, [][]driver.Value)) (*PgVectorDB, *fakePostgres) {
+	t.Helper()
+	fake := &fakePostgres{respond: respond}
+	fakePostgresConnector.Lock()
+	fakePostgresConnector.current = fake
+	fakePostgresConnector.Unlock()
+
+	db, err := sql.Open("fakepostgres", "")
+	require.NoError(t, err)
+	// Every connection must be opened while fake is current
+	db.SetMaxOpenConns(1)
+	require.NoError(t, db.Ping())
+	t.Cleanup(func() { db.Close() })
+	store, err := NewPgVectorDBFromDB(db, "documents")
+	require.NoError(t, err)
+	return store, fake
+}
+
+func TestPgVectorDB(t *testing.T) {
+	columns := []string{"id", "text", "metadata", "embedding", "similarity"}
+	store, fake := newFakePgVectorDB(t, func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.HasPrefix(query, "SELECT COUNT(*)"):
+			return []string{"count"}, [][]driver.Value{{int64(2)}}
+		case strings.Contains(query, "WHERE id = $1"):
+			return columns[:4], [][]driver.Value{{"doc-1", "log4j advisory", `{"source":"nvd"}`, "[0.5,1]"}}
+		default:
+			return columns, [][]driver.Value{
+				{"doc-1", "log4j advisory", `{"source":"nvd"}`, "[0.5,1]", 0.9},
+				{"doc-2", "openssl advisory", nil, "[1,0]", 0.2},
+			}
+		}
+	})
+
+	require.NoError(t, store.Add(Document{ID: "doc-1", Text: "log4j advisory", Vector: []float64{0.5, 1}, Metadata: map[string]interface{}{"source": "nvd"}}))
+	require.NoError(t, store.Add(Document{ID: "doc-2", Text: "openssl advisory", Vector: []float64{1, 0}}))
+	assert.Error(t, store.Add(Document{ID: "doc-3"}))
+
+	// The table is created once, sized to the first vector
+	assert.Equal(t, []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		"CREATE TABLE IF NOT EXISTS documents ( id TEXT PRIMARY KEY, text TEXT NOT NULL, metadata JSONB, embedding vector(2) NOT NULL )",
+	}, fake.statements[:2])
+	assert.Contains(t, fake.statements[2], "INSERT INTO documents")
+	assert.Equal(t, []driver.Value{"doc-1", "log4j advisory", `{"source":"nvd"}`, "[0.5,1]"}, fake.args[2])
+	assert.Len(t, fake.statements, 4)
+
+	doc, ok := store.Get("doc-1")
+	require.True(t, ok)
+	assert.Equal(t, Document{ID: "doc-1", Text: "log4j advisory", Vector: []float64{0.5, 1}, Metadata: map[string]interface{}{"source": "nvd"}}, doc)
+
+	results, err := store.Search([]float64{0.5, 1}, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "doc-1", results[0].Document.ID)
+	assert.Equal(t, 0.9, results[0].Similarity)
+	assert.Nil(t, results[1].Document.Metadata)
+	assert.Equal(t, []driver.Value{"[0.5,1]", int64(2)}, fake.args[len(fake.args)-1])
+
+	assert.True(t, store.Delete("doc-1"))
+	assert.Equal(t, 2, store.Size())
+
+	// Clearing drops the table, so the next document creates it again
+	store.Clear()
+	assert.Equal(t, "DROP TABLE IF EXISTS documents", fake.statements[len(fake.statements)-1])
+	require.NoError(t, store.Add(Document{ID: "doc-4", Text: "zlib advisory", Vector: []float64{1, 2, 3}}))
+	assert.Contains(t, fake.statements[len(fake.statements)-2], "embedding vector(3)")
+}
+
+func TestNewPgVectorDB(t *testing.T) {
+	// The pgx driver is registered, so only the connection fails
+	_, err := NewPgVectorDB("postgres://sentinel@127.0.0.1:1/sentinel?connect_timeout=5", "documents")
+	assert.ErrorContains(t, err, "failed to connect to PostgreSQL")
+}
+
+// TestPgVectorDB_PostgreSQL runs against the PostgreSQL with pgvector in
+// PGVECTOR_TEST_URL, if set.
+func TestPgVectorDB_PostgreSQL(t *testing.T) {
+	dsn := os.Getenv("PGVECTOR_TEST_URL")
+	if dsn == "" {
+		t.Skip("PGVECTOR_TEST_URL is not set")
+	}
+	store, err := NewPgVectorDB(dsn, "sentinel_test_documents")
+	require.NoError(t, err)
+	defer store.Close()
+	store.Clear()
+	defer store.Clear()
+	require.NoError(t, store.Ping(context.Background()))
+
+	require.NoError(t, store.Add(Document{ID: "doc-1", Text: "log4j advisory", Vector: []float64{1, 0}, Metadata: map[string]interface{}{"source": "nvd"}}))
+	require.NoError(t, store.Add(Document{ID: "doc-2", Text: "openssl advisory", Vector: []float64{0, 1}}))
+	assert.Equal(t, 2, store.Size())
+
+	doc, ok := store.Get("doc-1")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"source": "nvd"}, doc.Metadata)
+	results, err := store.Search([]float64{0.9, 0.1}, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "doc-1", results[0].Document.ID)
+
+	assert.True(t, store.Delete("doc-2"))
+	assert.False(t, store.Delete("doc-2"))
+	assert.Equal(t, 1, store.Size())
+}
@@ -0,0 +1,255 @@
+// Package vectordb provides a Qdrant-backed vector database.
+package vectordb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+)
+
+// QdrantVectorDB stores documents in a Qdrant collection using its REST API.
+// The collection is created on first write, sized to the first document's vector.
+type QdrantVectorDB struct {
+	baseURL    string
+	collection string
+	apiKey     string
+	client     *http.Client
+	ready      bool
+}
+
+// NewQdrantVectorDB creates a store backed by the named collection on the Qdrant server at baseURL.
+func NewQdrantVectorDB(baseURL, collection, apiKey string) *QdrantVectorDB {
+	return &QdrantVectorDB{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		collection: collection,
+		apiKey:     apiKey,
+		client:     httpclient.New(30 * time.Second),
+	}
+}
+
+// qdrantPoint is a point as sent to and returned by Qdrant.
+type qdrantPoint struct {
+	ID      string                 `json:"id"`
+	Vector  []float64              `json:"vector,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+	Score   float64                `json:"score,omitempty"`
+}
+
+// Add implements the VectorDB interface.
+func (q *QdrantVectorDB) Add(doc Document) error {
+	if doc.ID == "" {
+		return fmt.Errorf("document ID cannot be empty")
+	}
+	if len(doc.Vector) == 0 {
+		return fmt.Errorf("document vector cannot be empty")
+	}
+
+	if err := q.ensureCollection(len(doc.Vector)); err != nil {
+		return err
+	}
+
+	// Qdrant point IDs must be integers or UUIDs, so the document ID is kept in the payload
+	point := qdrantPoint{
+		ID:     qdrantPointID(doc.ID),
+		Vector: doc.Vector,
+		Payload: map[string]interface{}{
+			"doc_id":   doc.ID,
+			"text":     doc.Text,
+			"metadata": doc.Metadata,
+		},
+	}
+
+	body := map[string]interface{}{"points": []qdrantPoint{point}}
+	return q.do(http.MethodPut, "/points?wait=true", body, nil)
+}
+
+// Get implements the VectorDB interface.
+func (q *QdrantVectorDB) Get(id string) (Document, bool) {
+	var resp struct {
+		Result qdrantPoint `json:"result"`
+	}
+	if err := q.do(http.MethodGet, "/points/"+qdrantPointID(id), nil, &resp); err != nil {
+		return Document{}, false
+	}
+	return resp.Result.toDocument(), true
+}
+
+// Delete implements the VectorDB interface.
+func (q *QdrantVectorDB) Delete(id string) bool {
+	if _, exists := q.Get(id); !exists {
+		return false
+	}
+
+	body := map[string]interface{}{"points": []string{qdrantPointID(id)}}
+	if err := q.do(http.MethodPost, "/points/delete?wait=true", body, nil); err != nil {
+		fmt.Printf("Warning: Failed to delete document %s from Qdrant: %v\n", id, err)
+		return false
+	}
+	return true
+}
+
+// Search implements the VectorDB interface.
+func (q *QdrantVectorDB) Search(queryVector []float64, k int) ([]SearchResult, error) {
+	if len(queryVector) == 0 {
+		return nil, fmt.Errorf("query vector cannot be empty")
+	}
+
+	body := map[string]interface{}{
+		"vector":       queryVector,
+		"limit":        k,
+		"with_payload": true,
+		"with_vector":  true,
+	}
+	var resp struct {
+		Result []qdrantPoint `json:"result"`
+	}
+	if err := q.do(http.MethodPost, "/points/search", body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to search Qdrant: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Result))
+	for _, point := range resp.Result {
+		results = append(results, SearchResult{
+			Document:   point.toDocument(),
+			Similarity: point.Score,
+		})
+	}
+	return results, nil
+}
+
+// Size implements the VectorDB interface.
+func (q *QdrantVectorDB) Size() int {
+	var resp struct {
+		Result struct {
+			Count int `json:"count"`
+		} `json:"result"`
+	}
+	if err := q.do(http.MethodPost, "/points/count", map[string]interface{}{"exact": true}, &resp); err != nil {
+		return 0
+	}
+	return resp.Result.Count
+}
+
+// Ping implements the Pinger interface by listing the server's collections,
+// as the collection itself is only created on first write.
+func (q *QdrantVectorDB) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, q.baseURL+"/collections", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if q.apiKey != "" {
+		req.Header.Set("api-key", q.apiKey)
+	}
+
+	// The shared client retries, which a probe should not wait for
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to Qdrant: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Qdrant API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Clear implements the VectorDB interface by dropping the collection.
+func (q *QdrantVectorDB) Clear() {
+	if err := q.do(http.MethodDelete, "", nil, nil); err != nil {
+		fmt.Printf("Warning: Failed to clear Qdrant collection %s: %v\n", q.collection, err)
+	}
+	q.ready = false
+}
+
+// ensureCollection creates the collection with cosine distance if it does not exist.
+func (q *QdrantVectorDB) ensureCollection(dimensions int) error {
+	if q.ready {
+		return nil
+	}
+
+	if err := q.do(http.MethodGet, "", nil, nil); err == nil {
+		q.ready = true
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"vectors": map[string]interface{}{
+			"size":     dimensions,
+			"distance": "Cosine",
+		},
+	}
+	if err := q.do(http.MethodPut, "", body, nil); err != nil {
+		return fmt.Errorf("failed to create Qdrant collection %s: %w", q.collection, err)
+	}
+
+	q.ready = true
+	return nil
+}
+
+// do sends a request to the collection endpoint at path and decodes the response into out.
+func (q *QdrantVectorDB) do(method, path string, body interface{}, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		reqBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(reqBody)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	endpoint := q.baseURL + "/collections/" + url.PathEscape(q.collection) + path
+	req, err := http.NewRequestWithContext(context.Background(), method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if q.apiKey != "" {
+		req.Header.Set("api-key", q.apiKey)
+	}
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to Qdrant: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Qdrant API returned status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// toDocument converts a Qdrant point back into a Document.
+func (p qdrantPoint) toDocument() Document {
+	doc := Document{Vector: p.Vector}
+	doc.ID, _ = p.Payload["doc_id"].(string)
+	doc.Text, _ = p.Payload["text"].(string)
+	doc.Metadata, _ = p.Payload["metadata"].(map[string]interface{})
+	return doc
+}
+
+// qdrantPointID derives a deterministic UUID from a document ID.
+func qdrantPointID(id string) string {
+	sum := sha1.Sum([]byte(id))
+	// Set the version (5) and variant bits so the result is a valid name-based UUID
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
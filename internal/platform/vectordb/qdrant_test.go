@@ -0,0 +1,137 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQdrant implements the subset of the Qdrant REST API used by QdrantVectorDB.
+type fakeQdrant struct {
+	mu      sync.Mutex
+	created bool
+	points  map[string]qdrantPoint
+}
+
+func (f *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/collections/test")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		if !f.created {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	case path == "" && r.Method == http.MethodPut:
+		f.created = true
+		f.points = make(map[string]qdrantPoint)
+	case path == "" && r.Method == http.MethodDelete:
+		f.created = false
+		f.points = nil
+	case path == "/points" && r.Method == http.MethodPut:
+		var body struct {
+			Points []qdrantPoint `json:"points"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, point := range body.Points {
+			f.points[point.ID] = point
+		}
+	case path == "/points/delete":
+		var body struct {
+			Points []string `json:"points"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, id := range body.Points {
+			delete(f.points, id)
+		}
+	case path == "/points/count":
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]int{"count": len(f.points)}})
+	case path == "/points/search":
+		var body struct {
+			Vector []float64 `json:"vector"`
+			Limit  int       `json:"limit"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		var results []qdrantPoint
+		for _, point := range f.points {
+			point.Score = cosineSimilarity(body.Vector, point.Vector)
+			results = append(results, point)
+		}
+		if len(results) > body.Limit {
+			results = results[:body.Limit]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": results})
+	case strings.HasPrefix(path, "/points/"):
+		point, exists := f.points[strings.TrimPrefix(path, "/points/")]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": point})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestQdrantVectorDB(t *testing.T) {
+	server := httptest.NewServer(&fakeQdrant{})
+	defer server.Close()
+
+	db := NewQdrantVectorDB(server.URL+"/", "test", "")
+
+	doc := Document{
+		ID:       "GHSA-1234",
+		Text:     "Prototype pollution in lodash",
+		Vector:   []float64{1, 0, 0},
+		Metadata: map[string]interface{}{"component": "lodash"},
+	}
+	require.NoError(t, db.Add(doc))
+	assert.Equal(t, 1, db.Size())
+
+	stored, exists := db.Get("GHSA-1234")
+	require.True(t, exists)
+	assert.Equal(t, doc, stored)
+
+	results, err := db.Search([]float64{1, 0, 0}, 3)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "GHSA-1234", results[0].Document.ID)
+	assert.InDelta(t, 1.0, results[0].Similarity, 0.0001)
+
+	assert.True(t, db.Delete("GHSA-1234"))
+	assert.False(t, db.Delete("GHSA-1234"))
+	assert.Equal(t, 0, db.Size())
+
+	assert.Error(t, db.Add(Document{ID: "empty"}))
+}
+
+func TestQdrantVectorDB_Ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/collections" || r.Header.Get("api-key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"result": {"collections": []}}`))
+	}))
+	defer server.Close()
+
+	assert.NoError(t, NewQdrantVectorDB(server.URL, "test", "secret").Ping(context.Background()))
+	assert.ErrorContains(t, NewQdrantVectorDB(server.URL, "test", "wrong").Ping(context.Background()), "status 401")
+}
+
+func TestQdrantPointID(t *testing.T) {
+	id := qdrantPointID("GHSA-1234")
+	assert.Len(t, id, 36)
+	assert.Equal(t, id, qdrantPointID("GHSA-1234"))
+	assert.NotEqual(t, id, qdrantPointID("GHSA-5678"))
+	assert.Equal(t, byte('5'), id[14], "UUID version should be 5")
+}
@@ -0,0 +1,463 @@
+// Package vectordb provides security intelligence sources for the harvester.
+package vectordb
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+)
+
+// Source is a feed of security intelligence that the Harvester can pull from.
+type Source interface {
+	// Name returns a stable identifier for the source, used to track incremental harvests.
+	Name() string
+
+	// Fetch returns the intelligence published or modified after since.
+	// A zero since requests everything the source has to offer.
+	Fetch(ctx context.Context, since time.Time) ([]SecurityIntelligence, error)
+}
+
+// ParseSources builds sources from a comma-separated list of specs.
+// Supported specs are "osv:<ecosystem>" for an OSV.dev ecosystem dump
+// (e.g. "osv:npm", "osv:PyPI") and "feed:<url>" for an RSS or Atom feed such
+// as a GitHub advisories feed or the oss-security mailing list archive.
+func ParseSources(specs string) ([]Source, error) {
+	var sources []Source
+
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		kind, value, found := strings.Cut(spec, ":")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid intelligence source %q: expected osv:<ecosystem> or feed:<url>", spec)
+		}
+
+		switch strings.ToLower(kind) {
+		case "osv":
+			sources = append(sources, NewOSVDumpSource(value))
+		case "feed":
+			sources = append(sources, NewFeedSource(value))
+		default:
+			return nil, fmt.Errorf("unknown intelligence source type %q", kind)
+		}
+	}
+
+	return sources, nil
+}
+
+// OSVDumpSource harvests vulnerabilities from the OSV.dev bulk export of a
+// single ecosystem, published as a zip archive of OSV JSON records.
+type OSVDumpSource struct {
+	ecosystem string
+	url       string
+	client    *http.Client
+}
+
+// NewOSVDumpSource creates a source for the OSV.dev export of the given ecosystem.
+func NewOSVDumpSource(ecosystem string) *OSVDumpSource {
+	return &OSVDumpSource{
+		ecosystem: ecosystem,
+		url:       fmt.Sprintf("https://osv-vulnerabilities.storage.googleapis.com/%s/all.zip", ecosystem),
+		client:    httpclient.New(5 * time.Minute), // Ecosystem dumps can be hundreds of megabytes
+	}
+}
+
+// Name returns the identifier for this source.
+func (s *OSVDumpSource) Name() string {
+	return "osv:" + s.ecosystem
+}
+
+// osvRecord is the subset of the OSV schema needed to build intelligence documents.
+type osvRecord struct {
+	ID        string    `json:"id"`
+	Summary   string    `json:"summary"`
+	Details   string    `json:"details"`
+	Aliases   []string  `json:"aliases"`
+	Modified  time.Time `json:"modified"`
+	Published time.Time `json:"published"`
+	Withdrawn time.Time `json:"withdrawn"`
+	Affected  []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		Ranges   []osvRange `json:"ranges"`
+		Versions []string   `json:"versions"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// osvRange is an OSV version range; each event has a single
+// "introduced", "fixed", "last_affected" or "limit" key.
+type osvRange struct {
+	Events []map[string]string `json:"events"`
+}
+
+// Fetch downloads the ecosystem dump and returns the records modified after since.
+func (s *OSVDumpSource) Fetch(ctx context.Context, since time.Time) ([]SecurityIntelligence, error) {
+	data, err := fetchURL(ctx, s.client, s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OSV dump: %w", err)
+	}
+
+	var intelligence []SecurityIntelligence
+	for _, file := range archive.File {
+		if !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+
+		record, err := readOSVRecord(file)
+		if err != nil {
+			fmt.Printf("Warning: Skipping OSV record %s: %v\n", file.Name, err)
+			continue
+		}
+
+		if !record.Modified.After(since) {
+			continue
+		}
+
+		// Withdrawn advisories are no longer valid intelligence; on a full
+		// harvest they are skipped, otherwise they are reported for removal
+		withdrawn := !record.Withdrawn.IsZero()
+		if withdrawn && since.IsZero() {
+			continue
+		}
+
+		for _, intel := range s.toIntelligence(record) {
+			intel.Withdrawn = withdrawn
+			intelligence = append(intelligence, intel)
+		}
+	}
+
+	return intelligence, nil
+}
+
+// readOSVRecord decodes a single OSV JSON record from the dump.
+func readOSVRecord(file *zip.File) (*osvRecord, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var record osvRecord
+	if err := json.NewDecoder(rc).Decode(&record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// toIntelligence converts an OSV record into one intelligence entry per affected package.
+func (s *OSVDumpSource) toIntelligence(record *osvRecord) []SecurityIntelligence {
+	title := record.Summary
+	if title == "" {
+		title = record.ID
+	}
+	if len(record.Aliases) > 0 {
+		title = fmt.Sprintf("%s (%s)", title, strings.Join(record.Aliases, ", "))
+	}
+
+	date := record.Published
+	if date.IsZero() {
+		date = record.Modified
+	}
+
+	base := SecurityIntelligence{
+		ID:          record.ID,
+		Title:       title,
+		Description: truncate(record.Details, maxDescriptionLength),
+		Severity:    normalizeSeverity(record.DatabaseSpecific.Severity),
+		Source:      "OSV " + s.ecosystem,
+		Date:        date.Format("2006-01-02"),
+		URL:         "https://osv.dev/vulnerability/" + record.ID,
+	}
+	if base.Description == "" {
+		base.Description = record.Summary
+	}
+
+	if len(record.Affected) == 0 {
+		return []SecurityIntelligence{base}
+	}
+
+	intelligence := make([]SecurityIntelligence, 0, len(record.Affected))
+	for _, affected := range record.Affected {
+		intel := base
+		intel.Component = affected.Package.Name
+		intel.Version = describeAffectedVersions(affected.Versions, affected.Ranges)
+		if len(record.Affected) > 1 {
+			intel.ID = record.ID + "/" + affected.Package.Name
+		}
+		intelligence = append(intelligence, intel)
+	}
+	return intelligence
+}
+
+// describeAffectedVersions summarizes OSV affected versions in a human-readable form,
+// e.g. ">= 2.0.0, < 2.15.0".
+func describeAffectedVersions(versions []string, ranges []osvRange) string {
+	var parts []string
+	for _, r := range ranges {
+		for _, event := range r.Events {
+			switch {
+			case event["introduced"] != "" && event["introduced"] != "0":
+				parts = append(parts, ">= "+event["introduced"])
+			case event["fixed"] != "":
+				parts = append(parts, "< "+event["fixed"])
+			case event["last_affected"] != "":
+				parts = append(parts, "<= "+event["last_affected"])
+			}
+		}
+	}
+	if len(parts) > 0 {
+		return strings.Join(parts, ", ")
+	}
+
+	// Fall back to explicit versions, keeping the document compact
+	if len(versions) > 10 {
+		return strings.Join(versions[:10], ", ") + fmt.Sprintf(" and %d more", len(versions)-10)
+	}
+	return strings.Join(versions, ", ")
+}
+
+// FeedSource harvests advisories and mailing list posts from an RSS 2.0 or Atom feed.
+type FeedSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewFeedSource creates a source for the RSS or Atom feed at url.
+func NewFeedSource(url string) *FeedSource {
+	return &FeedSource{
+		url:    url,
+		client: httpclient.New(30 * time.Second),
+	}
+}
+
+// Name returns the identifier for this source.
+func (s *FeedSource) Name() string {
+	return "feed:" + s.url
+}
+
+// rssFeed is the subset of an RSS 2.0 document needed for harvesting.
+type rssFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+			GUID        string `xml:"guid"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// atomFeed is the subset of an Atom document needed for harvesting.
+type atomFeed struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		ID    string `xml:"id"`
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+// Fetch downloads the feed and returns the entries published after since.
+func (s *FeedSource) Fetch(ctx context.Context, since time.Time) ([]SecurityIntelligence, error) {
+	data, err := fetchURL(ctx, s.client, s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Peek at the root element to tell RSS from Atom
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	var intelligence []SecurityIntelligence
+	keep := func(intel SecurityIntelligence, published time.Time) {
+		// Undated entries cannot be compared against the last harvest, so they are always kept
+		if published.IsZero() || published.After(since) {
+			intelligence = append(intelligence, intel)
+		}
+	}
+
+	switch root.XMLName.Local {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+		for _, item := range feed.Channel.Items {
+			id := item.GUID
+			if id == "" {
+				id = item.Link
+			}
+			keep(feedEntryToIntelligence(id, item.Title, item.Description, item.Link, item.PubDate, feed.Channel.Title))
+		}
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+		}
+		for _, entry := range feed.Entries {
+			var link string
+			if len(entry.Links) > 0 {
+				link = entry.Links[0].Href
+			}
+			body := entry.Summary
+			if body == "" {
+				body = entry.Content
+			}
+			date := entry.Published
+			if date == "" {
+				date = entry.Updated
+			}
+			keep(feedEntryToIntelligence(entry.ID, entry.Title, body, link, date, feed.Title))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format %q", root.XMLName.Local)
+	}
+
+	return intelligence, nil
+}
+
+// feedDateLayouts lists the date formats commonly found in RSS and Atom feeds.
+var feedDateLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700"}
+
+// feedEntryToIntelligence converts a feed entry into a SecurityIntelligence record
+// and returns it with its publication time, which is zero if the date is missing
+// or unparseable. Feeds are free text, so the component is left empty and
+// matching relies on the embedded document text.
+func feedEntryToIntelligence(id, title, body, link, date, source string) (SecurityIntelligence, time.Time) {
+	description := truncate(stripHTML(body), maxDescriptionLength)
+
+	intel := SecurityIntelligence{
+		ID:          id,
+		Title:       strings.TrimSpace(title),
+		Description: description,
+		Severity:    inferSeverity(title + " " + description),
+		Source:      strings.TrimSpace(source),
+		URL:         link,
+	}
+	if intel.ID == "" {
+		intel.ID = link
+	}
+
+	for _, layout := range feedDateLayouts {
+		if published, err := time.Parse(layout, strings.TrimSpace(date)); err == nil {
+			intel.Date = published.Format("2006-01-02")
+			return intel, published
+		}
+	}
+
+	return intel, time.Time{}
+}
+
+// maxDescriptionLength bounds document text so embeddings stay focused.
+const maxDescriptionLength = 2000
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTML removes markup and collapses whitespace in feed content.
+func stripHTML(s string) string {
+	return strings.Join(strings.Fields(htmlTagPattern.ReplaceAllString(s, " ")), " ")
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !isRuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// isRuneStart reports whether b begins a UTF-8 encoded rune.
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// normalizeSeverity maps source-specific severity labels onto Critical/High/Medium/Low.
+func normalizeSeverity(severity string) string {
+	switch strings.ToUpper(strings.TrimSpace(severity)) {
+	case "CRITICAL":
+		return "Critical"
+	case "HIGH", "IMPORTANT":
+		return "High"
+	case "MODERATE", "MEDIUM":
+		return "Medium"
+	case "LOW":
+		return "Low"
+	default:
+		return ""
+	}
+}
+
+// inferSeverity guesses a severity from free text such as a feed title.
+func inferSeverity(text string) string {
+	lower := strings.ToLower(text)
+	for _, severity := range []string{"critical", "high", "moderate", "medium", "low"} {
+		if strings.Contains(lower, severity+" severity") || strings.Contains(lower, "severity: "+severity) {
+			return normalizeSeverity(severity)
+		}
+	}
+	if strings.Contains(lower, "remote code execution") {
+		return "Critical"
+	}
+	return ""
+}
+
+// fetchURL performs a GET request and returns the response body.
+func fetchURL(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", url, err)
+	}
+	return data, nil
+}
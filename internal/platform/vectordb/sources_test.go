@@ -0,0 +1,373 @@
+package vectordb
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSources(t *testing.T) {
+	sources, err := ParseSources("osv:npm, feed:https://seclists.org/rss/oss-sec.rss")
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	assert.Equal(t, "osv:npm", sources[0].Name())
+	assert.Equal(t, "feed:https://seclists.org/rss/oss-sec.rss", sources[1].Name())
+
+	_, err = ParseSources("mailing-list")
+	assert.Error(t, err)
+
+	_, err = ParseSources("ftp:example")
+	assert.Error(t, err)
+}
+
+func TestOSVDumpSource_Fetch(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	records := map[string]string{
+		"GHSA-old.json":       `{"id":"GHSA-old","summary":"Old issue","modified":"2023-01-01T00:00:00Z","affected":[{"package":{"name":"left-pad"},"versions":["1.0.0"]}]}`,
+		"GHSA-new.json":       `{"id":"GHSA-new","summary":"Prototype pollution","details":"Details here","aliases":["CVE-2024-0001"],"modified":"2024-06-01T00:00:00Z","published":"2024-05-30T00:00:00Z","affected":[{"package":{"name":"lodash"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"4.17.21"}]}]}],"database_specific":{"severity":"MODERATE"}}`,
+		"GHSA-withdrawn.json": `{"id":"GHSA-withdrawn","modified":"2024-06-01T00:00:00Z","withdrawn":"2024-06-02T00:00:00Z"}`,
+	}
+	for name, content := range records {
+		f, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	source := NewOSVDumpSource("npm")
+	source.url = server.URL
+
+	intelligence, err := source.Fetch(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, intelligence, 2)
+
+	// Advisories withdrawn since the last harvest are reported for removal
+	byID := make(map[string]SecurityIntelligence)
+	for _, intel := range intelligence {
+		byID[intel.ID] = intel
+	}
+	assert.True(t, byID["GHSA-withdrawn"].Withdrawn)
+
+	intel := byID["GHSA-new"]
+	assert.False(t, intel.Withdrawn)
+	assert.Equal(t, "GHSA-new", intel.ID)
+	assert.Equal(t, "Prototype pollution (CVE-2024-0001)", intel.Title)
+	assert.Equal(t, "lodash", intel.Component)
+	assert.Equal(t, "< 4.17.21", intel.Version)
+	assert.Equal(t, "Medium", intel.Severity)
+	assert.Equal(t, "2024-05-30", intel.Date)
+	assert.Equal(t, "https://osv.dev/vulnerability/GHSA-new", intel.URL)
+
+	// A zero since returns everything that has not been withdrawn
+	intelligence, err = source.Fetch(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, intelligence, 2)
+}
+
+func TestFeedSource_Fetch(t *testing.T) {
+	tests := []struct {
+		name          string
+		feed          string
+		expectedID    string
+		expectedTitle string
+		expectedDate  string
+	}{
+		{
+			name: "RSS feed",
+			feed: `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>oss-security</title>
+<item><title>Old post</title><link>https://example.com/old</link><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+<item><title>Heap overflow in libfoo 2.3</title><link>https://example.com/new</link><guid>post-2</guid><description>&lt;p&gt;A high severity heap overflow&lt;/p&gt;</description><pubDate>Sat, 01 Jun 2024 10:00:00 +0000</pubDate></item>
+</channel></rss>`,
+			expectedID:    "post-2",
+			expectedTitle: "Heap overflow in libfoo 2.3",
+			expectedDate:  "2024-06-01",
+		},
+		{
+			name: "Atom feed",
+			feed: `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>GitHub Advisories</title>
+<entry><id>GHSA-aaaa</id><title>Old advisory</title><updated>2024-01-01T00:00:00Z</updated></entry>
+<entry><id>GHSA-bbbb</id><title>High severity vulnerability in libfoo</title><link href="https://github.com/advisories/GHSA-bbbb"/><summary>A high severity heap overflow</summary><updated>2024-06-01T00:00:00Z</updated></entry>
+</feed>`,
+			expectedID:    "GHSA-bbbb",
+			expectedTitle: "High severity vulnerability in libfoo",
+			expectedDate:  "2024-06-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.feed))
+			}))
+			defer server.Close()
+
+			source := NewFeedSource(server.URL)
+			intelligence, err := source.Fetch(context.Background(), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+			require.Len(t, intelligence, 1)
+
+			intel := intelligence[0]
+			assert.Equal(t, tt.expectedID, intel.ID)
+			assert.Equal(t, tt.expectedTitle, intel.Title)
+			assert.Equal(t, "A high severity heap overflow", intel.Description)
+			assert.Equal(t, "High", intel.Severity)
+			assert.Equal(t, tt.expectedDate, intel.Date)
+		})
+	}
+}
+
+// staticSource returns fixed intelligence and records the since value it was asked for.
+type staticSource struct {
+	intelligence []SecurityIntelligence
+	sinces       []time.Time
+}
+
+func (s *staticSource) Name() string { return "static" }
+
+func (s *staticSource) Fetch(ctx context.Context, since time.Time) ([]SecurityIntelligence, error) {
+	s.sinces = append(s.sinces, since)
+	return s.intelligence, nil
+}
+
+// fakeOllama serves the Ollama embedding APIs, counting the texts it embeds.
+type fakeOllama struct {
+	mu            sync.Mutex
+	embedded      int
+	batchRequests int
+	models        []string
+	// legacyOnly simulates an Ollama version without the batch embed API
+	legacyOnly bool
+	// unavailable fails every embedding request
+	unavailable bool
+}
+
+func (f *fakeOllama) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.unavailable {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	switch r.URL.Path {
+	case "/api/embed":
+		if f.legacyOnly {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req OllamaBatchEmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.batchRequests++
+		f.embedded += len(req.Input)
+		f.models = append(f.models, req.Model)
+
+		resp := OllamaBatchEmbeddingResponse{}
+		for range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float64{0.1, 0.2, 0.3})
+		}
+		json.NewEncoder(w).Encode(resp)
+	case "/api/embeddings":
+		var req OllamaEmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.embedded++
+		f.models = append(f.models, req.Model)
+		json.NewEncoder(w).Encode(OllamaEmbeddingResponse{Embedding: []float64{0.1, 0.2, 0.3}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newTestHarvester creates a harvester that embeds through the given fake Ollama server.
+func newTestHarvester(db VectorDB, ollamaURL string) *Harvester {
+	harvester := NewHarvester(db)
+	harvester.ollamaURL = ollamaURL + "/api/embeddings"
+	harvester.ollamaBatchURL = ollamaURL + "/api/embed"
+	return harvester
+}
+
+func TestHarvester_HarvestIsIncremental(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	db := NewMemoryVectorDB()
+	harvester := newTestHarvester(db, server.URL)
+
+	source := &staticSource{intelligence: []SecurityIntelligence{
+		{ID: "a", Title: "First"},
+		{ID: "b", Title: "Second"},
+	}}
+	harvester.AddSource(source)
+
+	report, err := harvester.Harvest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, HarvestReport{Fetched: 2, Embedded: 2}, report)
+	assert.Equal(t, 2, db.Size())
+	assert.Equal(t, 2, ollama.embedded)
+
+	// Only the changed document is re-embedded
+	source.intelligence[1].Title = "Second, updated"
+	report, err = harvester.Harvest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, HarvestReport{Fetched: 2, Embedded: 1, Unchanged: 1}, report)
+	assert.Equal(t, 3, ollama.embedded)
+
+	// The second harvest only asks for entries newer than the first
+	require.Len(t, source.sinces, 2)
+	assert.True(t, source.sinces[0].IsZero())
+	assert.False(t, source.sinces[1].IsZero())
+}
+
+func TestHarvester_HarvestRetriesFailedDocuments(t *testing.T) {
+	ollama := &fakeOllama{unavailable: true}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	db := NewMemoryVectorDB()
+	harvester := newTestHarvester(db, server.URL)
+	source := &staticSource{intelligence: []SecurityIntelligence{{ID: "a", Title: "First"}}}
+	harvester.AddSource(source)
+
+	report, err := harvester.Harvest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Failed)
+
+	// The failed document is fetched again, then the source moves on
+	ollama.unavailable = false
+	report, err = harvester.Harvest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Embedded)
+	_, err = harvester.Harvest(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, source.sinces, 3)
+	assert.True(t, source.sinces[0].IsZero())
+	assert.True(t, source.sinces[1].IsZero(), "the watermark stays put while documents fail")
+	assert.False(t, source.sinces[2].IsZero())
+	assert.Equal(t, 1, db.Size())
+}
+
+func TestHarvester_HarvestBatches(t *testing.T) {
+	var intelligence []SecurityIntelligence
+	for i := 0; i < 10; i++ {
+		intelligence = append(intelligence, SecurityIntelligence{ID: fmt.Sprintf("doc-%d", i), Title: fmt.Sprintf("Advisory %d", i)})
+	}
+
+	tests := []struct {
+		name                  string
+		legacyOnly            bool
+		expectedBatchRequests int
+	}{
+		{name: "Batch embed API", expectedBatchRequests: 4},
+		{name: "Fallback to single embeddings", legacyOnly: true, expectedBatchRequests: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ollama := &fakeOllama{legacyOnly: tt.legacyOnly}
+			server := httptest.NewServer(ollama)
+			defer server.Close()
+
+			db := NewMemoryVectorDB()
+			harvester := newTestHarvester(db, server.URL)
+			harvester.SetBatching(3, 2)
+			harvester.AddSource(&staticSource{intelligence: intelligence})
+
+			report, err := harvester.Harvest(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 10, report.Embedded)
+			assert.Equal(t, 10, db.Size())
+			assert.Equal(t, 10, ollama.embedded)
+			assert.Equal(t, tt.expectedBatchRequests, ollama.batchRequests)
+		})
+	}
+}
+
+func TestHarvester_CheckpointResumes(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	checkpoint := filepath.Join(t.TempDir(), "harvest.json")
+	db := NewMemoryVectorDB() // Stands in for a persistent store shared across runs
+	source := &staticSource{intelligence: []SecurityIntelligence{{ID: "a", Title: "First"}}}
+
+	first := newTestHarvester(db, server.URL)
+	require.NoError(t, first.SetCheckpointPath(checkpoint))
+	first.AddSource(source)
+	_, err := first.Harvest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, ollama.embedded)
+
+	// A new harvester resumes from the checkpoint without re-embedding
+	second := newTestHarvester(db, server.URL)
+	require.NoError(t, second.SetCheckpointPath(checkpoint))
+	second.AddSource(source)
+	report, err := second.Harvest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Unchanged)
+	assert.Equal(t, 1, ollama.embedded)
+
+	require.Len(t, source.sinces, 2)
+	assert.False(t, source.sinces[1].IsZero())
+}
+
+func TestHarvester_HarvestAllSourcesFail(t *testing.T) {
+	harvester := NewHarvester(NewMemoryVectorDB())
+	source := NewFeedSource("http://127.0.0.1:0/feed")
+	harvester.AddSource(source)
+
+	report, err := harvester.Harvest(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, report.SourcesFailed)
+}
+
+func TestHarvester_UsesEmbeddingModel(t *testing.T) {
+	ollama := &fakeOllama{}
+	server := httptest.NewServer(ollama)
+	defer server.Close()
+
+	harvester := newTestHarvester(NewMemoryVectorDB(), server.URL)
+	harvester.SetEmbeddingModel("nomic-embed-text")
+	harvester.AddSource(&staticSource{intelligence: []SecurityIntelligence{{ID: "a", Title: "Test"}}})
+
+	_, err := harvester.ingest(context.Background(), SecurityIntelligence{ID: "b", Title: "Test"})
+	require.NoError(t, err)
+	_, err = harvester.Harvest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nomic-embed-text", "nomic-embed-text"}, ollama.models)
+}
+
+func TestHarvester_ConfigureFromEnv(t *testing.T) {
+	t.Setenv("INTEL_SOURCES", "osv:PyPI")
+	t.Setenv("INTEL_EMBED_BATCH_SIZE", "64")
+	t.Setenv("INTEL_EMBED_CONCURRENCY", "zero")
+
+	harvester := NewHarvester(NewMemoryVectorDB())
+	err := harvester.ConfigureFromEnv()
+	assert.ErrorContains(t, err, "INTEL_EMBED_CONCURRENCY")
+
+	assert.True(t, harvester.HasSources())
+	assert.Equal(t, 64, harvester.batchSize)
+	assert.Equal(t, DefaultEmbeddingConcurrency, harvester.concurrency)
+}
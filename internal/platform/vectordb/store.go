@@ -0,0 +1,98 @@
+// Package vectordb provides the vector store abstraction and its configuration.
+package vectordb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VectorDB defines the contract for storing and querying document embeddings.
+// MemoryVectorDB keeps documents in process; QdrantVectorDB and PgVectorDB
+// share a corpus across server instances and scale beyond memory.
+type VectorDB interface {
+	// Add stores a document, replacing any existing document with the same ID.
+	Add(doc Document) error
+
+	// Get retrieves a document by ID.
+	Get(id string) (Document, bool)
+
+	// Delete removes a document, reporting whether it existed.
+	Delete(id string) bool
+
+	// Search returns the k documents most similar to the query vector.
+	Search(queryVector []float64, k int) ([]SearchResult, error)
+
+	// Size returns the number of stored documents.
+	Size() int
+
+	// Clear removes all documents.
+	Clear()
+}
+
+// Pinger is implemented by vector stores on a server, which can be checked
+// for reachability. In-process stores are always available.
+type Pinger interface {
+	// Ping verifies that the store's server is reachable.
+	Ping(ctx context.Context) error
+}
+
+// Vector store backends selectable through Config.
+const (
+	BackendMemory   = "memory"
+	BackendQdrant   = "qdrant"
+	BackendPgVector = "pgvector"
+)
+
+// DefaultCollection is the Qdrant collection or PostgreSQL table used when none is configured.
+const DefaultCollection = "security_intelligence"
+
+// Config selects and configures a vector store backend.
+type Config struct {
+	// Backend is one of BackendMemory, BackendQdrant or BackendPgVector
+	Backend string
+	// URL is the Qdrant base URL or the PostgreSQL connection string
+	URL string
+	// Collection is the Qdrant collection or PostgreSQL table name
+	Collection string
+	// APIKey authenticates against Qdrant Cloud; unused by other backends
+	APIKey string
+}
+
+// ConfigFromEnv reads the vector store configuration from the VECTOR_DB,
+// VECTOR_DB_URL, VECTOR_DB_COLLECTION and VECTOR_DB_API_KEY environment variables.
+func ConfigFromEnv() Config {
+	return Config{
+		Backend:    os.Getenv("VECTOR_DB"),
+		URL:        os.Getenv("VECTOR_DB_URL"),
+		Collection: os.Getenv("VECTOR_DB_COLLECTION"),
+		APIKey:     os.Getenv("VECTOR_DB_API_KEY"),
+	}
+}
+
+// New creates the vector store described by cfg.
+// An empty backend selects the in-memory store.
+func New(cfg Config) (VectorDB, error) {
+	collection := cfg.Collection
+	if collection == "" {
+		collection = DefaultCollection
+	}
+
+	switch strings.ToLower(cfg.Backend) {
+	case "", BackendMemory:
+		return NewMemoryVectorDB(), nil
+	case BackendQdrant:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("qdrant vector store requires a URL")
+		}
+		return NewQdrantVectorDB(cfg.URL, collection, cfg.APIKey), nil
+	case BackendPgVector:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("pgvector vector store requires a connection string")
+		}
+		return NewPgVectorDB(cfg.URL, collection)
+	default:
+		return nil, fmt.Errorf("unknown vector store backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,51 @@
+package vectordb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       Config
+		expectedType interface{}
+		expectError  bool
+	}{
+		{name: "Default is memory", config: Config{}, expectedType: &MemoryVectorDB{}},
+		{name: "Explicit memory", config: Config{Backend: "memory"}, expectedType: &MemoryVectorDB{}},
+		{name: "Qdrant", config: Config{Backend: "Qdrant", URL: "http://localhost:6333"}, expectedType: &QdrantVectorDB{}},
+		{name: "Qdrant without URL", config: Config{Backend: "qdrant"}, expectError: true},
+		{name: "pgvector without connection string", config: Config{Backend: "pgvector"}, expectError: true},
+		{name: "pgvector without a server", config: Config{Backend: "pgvector", URL: "postgres://127.0.0.1:1/sentinel"}, expectError: true},
+		{name: "Unknown backend", config: Config{Backend: "faiss"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := New(tt.config)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.expectedType, db)
+		})
+	}
+}
+
+func TestPgVectorFormatting(t *testing.T) {
+	assert.Equal(t, "[0.5,-1,3e-07]", formatVector([]float64{0.5, -1, 3e-7}))
+
+	vector, err := parseVector("[0.5, -1,3e-07]")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, -1, 3e-7}, vector)
+
+	_, err = parseVector("0.5,1")
+	assert.Error(t, err)
+
+	_, err = NewPgVectorDBFromDB(nil, "docs; DROP TABLE users")
+	assert.Error(t, err)
+}
@@ -0,0 +1,220 @@
+// Package plugin provides the subprocess protocol through which third parties
+// add analysis agents without forking SBOM Sentinel. A plugin is an
+// executable named sentinel-agent-<name> in the plugins directory. For each
+// call it is started with a JSON Request on stdin and answers with a JSON
+// Response on stdout; its stderr is included in errors.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// ProtocolVersion is the version of the plugin protocol spoken by this
+// release; plugins must answer with the same version.
+const ProtocolVersion = 1
+
+// ExecutablePrefix is the file name prefix of plugin executables.
+const ExecutablePrefix = "sentinel-agent-"
+
+// DescribeTimeout bounds the describe call made when a plugin is loaded.
+// Analyze calls are bounded by the agent time limits like any other agent.
+const DescribeTimeout = 10 * time.Second
+
+// Methods of the plugin protocol.
+const (
+	// MethodDescribe asks the plugin for its agent name
+	MethodDescribe = "describe"
+	// MethodAnalyze asks the plugin to analyze Request.SBOM
+	MethodAnalyze = "analyze"
+)
+
+// Request is the JSON document written to a plugin's stdin.
+type Request struct {
+	ProtocolVersion int        `json:"protocol_version"`
+	Method          string     `json:"method"`
+	SBOM            *core.SBOM `json:"sbom,omitempty"`
+}
+
+// Response is the JSON document a plugin writes to stdout.
+type Response struct {
+	ProtocolVersion int `json:"protocol_version"`
+	// Name is the agent name, answered to describe
+	Name string `json:"name,omitempty"`
+	// Findings are the results of analyze; their agent_name is set to Name
+	Findings []core.AnalysisResult `json:"findings,omitempty"`
+	// Error reports a failed call; the plugin may still exit successfully
+	Error string `json:"error,omitempty"`
+}
+
+// ProxyAgent runs a plugin executable as an analysis agent.
+type ProxyAgent struct {
+	path string
+	name string
+}
+
+// Load starts the plugin at path to ask for its name and returns the agent
+// proxying it.
+func Load(ctx context.Context, path string) (*ProxyAgent, error) {
+	ctx, cancel := context.WithTimeout(ctx, DescribeTimeout)
+	defer cancel()
+
+	response, err := call(ctx, "plugin '"+path+"'", []string{path}, Request{ProtocolVersion: ProtocolVersion, Method: MethodDescribe})
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(response.Name)
+	if name == "" {
+		return nil, fmt.Errorf("plugin '%s' did not describe its agent name", path)
+	}
+	return &ProxyAgent{path: path, name: name}, nil
+}
+
+// Name implements the analysis.AnalysisAgent interface.
+func (a *ProxyAgent) Name() string {
+	return a.name
+}
+
+// Path returns the plugin executable.
+func (a *ProxyAgent) Path() string {
+	return a.path
+}
+
+// Analyze implements the analysis.AnalysisAgent interface. The plugin is
+// killed if ctx is cancelled.
+func (a *ProxyAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	response, err := call(ctx, "plugin '"+a.path+"'", []string{a.path}, Request{ProtocolVersion: ProtocolVersion, Method: MethodAnalyze, SBOM: &sbom})
+	if err != nil {
+		return nil, err
+	}
+
+	// Findings are attributed to the plugin, whatever it claims
+	for i := range response.Findings {
+		response.Findings[i].AgentName = a.name
+	}
+	return response.Findings, nil
+}
+
+// call runs command on request and decodes its response. Errors name the
+// plugin by label.
+func call(ctx context.Context, label string, command []string, request Request) (*Response, error) {
+	return exchange(ctx, label, request, func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdin = stdin
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		return cmd.Run()
+	})
+}
+
+// exchange runs a plugin with run, passing it request on stdin, and
+// decodes the response it writes to stdout. Errors name the plugin by
+// label and include its stderr.
+func exchange(ctx context.Context, label string, request Request, run func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error) (*Response, error) {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := run(ctx, bytes.NewReader(requestJSON), &stdout, &stderr); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s failed: %w: %s", label, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response of %s: %w", label, err)
+	}
+	if response.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("%s speaks protocol version %d, expected %d", label, response.ProtocolVersion, ProtocolVersion)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("%s failed: %s", label, response.Error)
+	}
+	return &response, nil
+}
+
+// Discover loads every plugin executable in dir, in file name order.
+// Plugins that cannot be loaded, or whose agent name is taken by an earlier
+// plugin, are skipped and reported in the returned error.
+func Discover(ctx context.Context, dir string) ([]*ProxyAgent, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory '%s': %w", dir, err)
+	}
+
+	var agents []*ProxyAgent
+	var errs []error
+	names := make(map[string]string)
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ExecutablePrefix) || !isExecutable(entry) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		agent, err := Load(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := names[agent.Name()]; ok {
+			errs = append(errs, fmt.Errorf("plugin '%s' skipped: agent name %q is taken by '%s'", path, agent.Name(), other))
+			continue
+		}
+		names[agent.Name()] = path
+		agents = append(agents, agent)
+	}
+	return agents, errors.Join(errs...)
+}
+
+// isExecutable reports whether entry is a regular file that can be run.
+func isExecutable(entry os.DirEntry) bool {
+	info, err := entry.Info()
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(entry.Name()), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+var (
+	defaultAgents     []*ProxyAgent
+	defaultAgentsOnce sync.Once
+)
+
+// AgentsFromEnv returns the plugins discovered once in $SENTINEL_PLUGIN_DIR,
+// or nil if it is not set. Plugins that cannot be loaded are reported as a
+// warning and skipped.
+func AgentsFromEnv() []*ProxyAgent {
+	defaultAgentsOnce.Do(func() {
+		dir := os.Getenv("SENTINEL_PLUGIN_DIR")
+		if dir == "" {
+			return
+		}
+
+		agents, err := Discover(context.Background(), dir)
+		if err != nil {
+			fmt.Printf("Warning: Some plugins in SENTINEL_PLUGIN_DIR are not loaded: %v\n", err)
+		}
+		defaultAgents = agents
+	})
+	return defaultAgents
+}
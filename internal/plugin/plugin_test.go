@@ -0,0 +1,116 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePlugin installs a stand-in plugin executable in dir that saves its
+// request next to it and answers describe with describe and analyze with
+// analyze.
+func writePlugin(t *testing.T, dir, name, describe, analyze string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake plugin executable is a shell script")
+	}
+
+	path := filepath.Join(dir, name)
+	script := "#!/bin/sh\n" +
+		"request=$(cat)\n" +
+		"echo \"$request\" > \"" + path + ".request\"\n" +
+		"case \"$request\" in\n" +
+		"*'\"method\":\"describe\"'*) cat <<'EOF'\n" + describe + "\nEOF\n;;\n" +
+		"*) cat <<'EOF'\n" + analyze + "\nEOF\n;;\n" +
+		"esac\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+func TestProxyAgent_Analyze(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "sentinel-agent-secrets",
+		`{"protocol_version": 1, "name": "Secrets Scanner"}`,
+		`{"protocol_version": 1, "findings": [{"agent_name": "License Agent", "finding": "AWS key in metadata", "severity": "High"}]}`)
+
+	agent, err := Load(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "Secrets Scanner", agent.Name())
+	assert.Equal(t, path, agent.Path())
+
+	results, err := agent.Analyze(context.Background(), core.SBOM{ID: "sbom-1", Name: "app"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "AWS key in metadata", results[0].Finding)
+	assert.Equal(t, "Secrets Scanner", results[0].AgentName, "findings are attributed to the plugin")
+
+	request, err := os.ReadFile(path + ".request")
+	require.NoError(t, err)
+	assert.Contains(t, string(request), `"method":"analyze"`)
+	assert.Contains(t, string(request), `"id":"sbom-1"`)
+}
+
+func TestProxyAgent_Errors(t *testing.T) {
+	dir := t.TempDir()
+	describe := `{"protocol_version": 1, "name": "Broken"}`
+
+	t.Run("error response", func(t *testing.T) {
+		agent, err := Load(context.Background(), writePlugin(t, dir, "sentinel-agent-error", describe, `{"protocol_version": 1, "error": "registry unreachable"}`))
+		require.NoError(t, err)
+		_, err = agent.Analyze(context.Background(), core.SBOM{})
+		assert.ErrorContains(t, err, "registry unreachable")
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		agent, err := Load(context.Background(), writePlugin(t, dir, "sentinel-agent-garbage", describe, `not json`))
+		require.NoError(t, err)
+		_, err = agent.Analyze(context.Background(), core.SBOM{})
+		assert.ErrorContains(t, err, "failed to parse response")
+	})
+
+	t.Run("protocol version", func(t *testing.T) {
+		_, err := Load(context.Background(), writePlugin(t, dir, "sentinel-agent-future", `{"protocol_version": 2, "name": "Future"}`, ""))
+		assert.ErrorContains(t, err, "protocol version 2, expected 1")
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := Load(context.Background(), writePlugin(t, dir, "sentinel-agent-anonymous", `{"protocol_version": 1}`, ""))
+		assert.ErrorContains(t, err, "did not describe its agent name")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		path := filepath.Join(dir, "sentinel-agent-slow")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := (&ProxyAgent{path: path, name: "Slow"}).Analyze(ctx, core.SBOM{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "sentinel-agent-a", `{"protocol_version": 1, "name": "Secrets Scanner"}`, "")
+	writePlugin(t, dir, "sentinel-agent-b", `{"protocol_version": 1, "name": "Secrets Scanner"}`, "")
+	writePlugin(t, dir, "sentinel-agent-c", `{"protocol_version": 1, "name": "Typosquat Detector"}`, "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sentinel-agent-readme.txt"), []byte("not executable"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other-tool"), []byte("#!/bin/sh\nexit 1\n"), 0o755))
+
+	agents, err := Discover(context.Background(), dir)
+	assert.ErrorContains(t, err, `agent name "Secrets Scanner" is taken`)
+	require.Len(t, agents, 2)
+	assert.Equal(t, "Secrets Scanner", agents[0].Name())
+	assert.Equal(t, filepath.Join(dir, "sentinel-agent-a"), agents[0].Path())
+	assert.Equal(t, "Typosquat Detector", agents[1].Name())
+
+	_, err = Discover(context.Background(), filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "failed to read plugin directory")
+}
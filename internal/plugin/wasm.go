@@ -0,0 +1,162 @@
+// Package plugin provides custom analysis rules compiled to WebAssembly.
+// Rules are WASI modules, written for example in TinyGo, Rust or
+// JavaScript, that speak the plugin protocol on stdin and stdout. They run
+// in an embedded WebAssembly runtime without access to the file system,
+// network, environment or wall clock, with bounded memory and time.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// WasmMemoryLimitPages bounds the memory of a rule, in 64 KiB WebAssembly
+// pages (64 MiB).
+const WasmMemoryLimitPages = 1024
+
+// WasmTimeout bounds a rule's analysis of an SBOM, within the agent time
+// limits.
+const WasmTimeout = 30 * time.Second
+
+// wasmCache keeps the compiled rules, so that the agents created for every
+// analysis do not compile their modules again.
+var wasmCache = wazero.NewCompilationCache()
+
+// Rule configures a custom analysis rule.
+type Rule struct {
+	// Name is the agent name findings are attributed to
+	Name string `yaml:"name"`
+	// Module is the WASI module (.wasm file) implementing the rule
+	Module string `yaml:"module"`
+	// Projects limits the rule to SBOMs of these names; empty applies it
+	// to every SBOM
+	Projects []string `yaml:"projects"`
+}
+
+// Validate reports whether the rule has a name and a module.
+func (r Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if r.Module == "" {
+		return errors.New("module is required")
+	}
+	return nil
+}
+
+// AppliesTo reports whether the rule analyzes sbom.
+func (r Rule) AppliesTo(sbom core.SBOM) bool {
+	return len(r.Projects) == 0 || slices.Contains(r.Projects, sbom.Name)
+}
+
+// WasmAgent runs a custom rule as an analysis agent.
+type WasmAgent struct {
+	rule   Rule
+	module []byte
+}
+
+// NewWasmAgent creates the agent running rule, reading and compiling its
+// module.
+func NewWasmAgent(rule Rule) (*WasmAgent, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rule %q: %w", rule.Name, err)
+	}
+	module, err := os.ReadFile(rule.Module)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module of rule %q: %w", rule.Name, err)
+	}
+
+	ctx := context.Background()
+	runtime := newWasmRuntime(ctx)
+	defer runtime.Close(ctx)
+	if _, err := runtime.CompileModule(ctx, module); err != nil {
+		return nil, fmt.Errorf("invalid module of rule %q: %w", rule.Name, err)
+	}
+	return &WasmAgent{rule: rule, module: module}, nil
+}
+
+// newWasmRuntime returns a runtime limited to WasmMemoryLimitPages, whose
+// modules are closed once the context of their call is done.
+func newWasmRuntime(ctx context.Context) wazero.Runtime {
+	config := wazero.NewRuntimeConfig().
+		WithCompilationCache(wasmCache).
+		WithMemoryLimitPages(WasmMemoryLimitPages).
+		WithCloseOnContextDone(true)
+	return wazero.NewRuntimeWithConfig(ctx, config)
+}
+
+// WasmAgents creates the agents of rules. Rules that cannot be run are
+// skipped and reported in the returned error.
+func WasmAgents(rules []Rule) ([]*WasmAgent, error) {
+	var agents []*WasmAgent
+	var errs []error
+	for _, rule := range rules {
+		agent, err := NewWasmAgent(rule)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		agents = append(agents, agent)
+	}
+	return agents, errors.Join(errs...)
+}
+
+// Name implements the analysis.AnalysisAgent interface.
+func (a *WasmAgent) Name() string {
+	return a.rule.Name
+}
+
+// Analyze implements the analysis.AnalysisAgent interface. SBOMs of projects
+// the rule does not apply to have no findings.
+func (a *WasmAgent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	if !a.rule.AppliesTo(sbom) {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, WasmTimeout)
+	defer cancel()
+	response, err := exchange(ctx, fmt.Sprintf("rule %q", a.rule.Name), Request{ProtocolVersion: ProtocolVersion, Method: MethodAnalyze, SBOM: &sbom}, a.run)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response.Findings {
+		response.Findings[i].AgentName = a.rule.Name
+		response.Findings[i].RuleID = a.rule.Name
+	}
+	return response.Findings, nil
+}
+
+// run runs the rule's module in a runtime of its own, so that no state is
+// shared between calls. The module is granted only stdin, stdout and stderr:
+// no directories, network, environment, arguments or wall clock.
+func (a *WasmAgent) run(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+	runtime := newWasmRuntime(ctx)
+	defer runtime.Close(context.WithoutCancel(ctx))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return fmt.Errorf("failed to provide WASI: %w", err)
+	}
+	compiled, err := runtime.CompileModule(ctx, a.module)
+	if err != nil {
+		return err
+	}
+
+	config := wazero.NewModuleConfig().WithStdin(stdin).WithStdout(stdout).WithStderr(stderr)
+	_, err = runtime.InstantiateModule(ctx, compiled, config)
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 0 {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wasmSection encodes a section of a WebAssembly module.
+func wasmSection(id byte, contents ...byte) []byte {
+	return append(append([]byte{id}, binary.AppendUvarint(nil, uint64(len(contents)))...), contents...)
+}
+
+// wasmName encodes a name of a WebAssembly module.
+func wasmName(name string) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(name))), name...)
+}
+
+// wasmModule assembles a WASI module whose _start runs code with memory of
+// the given pages, holding data from address 0, and fd_write imported as
+// function 0.
+func wasmModule(pages uint64, data []byte, code ...byte) []byte {
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	// Types: fd_write (i32, i32, i32, i32) -> i32 and _start () -> ()
+	module = append(module, wasmSection(1, 2, 0x60, 4, 0x7f, 0x7f, 0x7f, 0x7f, 1, 0x7f, 0x60, 0, 0)...)
+	imports := append([]byte{1}, wasmName("wasi_snapshot_preview1")...)
+	imports = append(append(imports, wasmName("fd_write")...), 0, 0)
+	module = append(module, wasmSection(2, imports...)...)
+	module = append(module, wasmSection(3, 1, 1)...)
+	module = append(module, wasmSection(5, append([]byte{1, 0}, binary.AppendUvarint(nil, pages)...)...)...)
+	exports := append([]byte{2}, wasmName("memory")...)
+	exports = append(append(exports, 2, 0), wasmName("_start")...)
+	module = append(module, wasmSection(7, append(exports, 0, 1)...)...)
+	body := append([]byte{0}, append(code, 0x0b)...)
+	module = append(module, wasmSection(10, append([]byte{1}, append(binary.AppendUvarint(nil, uint64(len(body))), body...)...)...)...)
+	segment := append([]byte{1, 0, 0x41, 0, 0x0b}, binary.AppendUvarint(nil, uint64(len(data)))...)
+	return append(module, wasmSection(11, append(segment, data...)...)...)
+}
+
+// writingModule returns a module writing output to stdout.
+func writingModule(output string) []byte {
+	// An iovec at 0 points to output at 16; fd_write stores the count at 8
+	data := binary.LittleEndian.AppendUint32(nil, 16)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(output)))
+	data = append(append(data, make([]byte, 8)...), output...)
+	// fd_write(1, 0, 1, 8)
+	return wasmModule(1, data, 0x41, 1, 0x41, 0, 0x41, 1, 0x41, 8, 0x10, 0, 0x1a)
+}
+
+// writeModule writes a module to a file and returns its path.
+func writeModule(t *testing.T, module []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rule.wasm")
+	require.NoError(t, os.WriteFile(path, module, 0o644))
+	return path
+}
+
+func TestWasmAgent_Analyze(t *testing.T) {
+	module := writeModule(t, writingModule(`{"protocol_version": 1, "findings": [{"finding": "Unapproved registry", "severity": "Medium"}]}`))
+
+	agent, err := NewWasmAgent(Rule{Name: "Registry Allowlist", Module: module, Projects: []string{"payments-api"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Registry Allowlist", agent.Name())
+
+	results, err := agent.Analyze(context.Background(), core.SBOM{ID: "sbom-1", Name: "payments-api"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Unapproved registry", results[0].Finding)
+	assert.Equal(t, "Registry Allowlist", results[0].AgentName)
+	assert.Equal(t, "Registry Allowlist", results[0].RuleID)
+
+	// Other projects are not analyzed
+	results, err = agent.Analyze(context.Background(), core.SBOM{ID: "sbom-2", Name: "billing"})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestWasmAgent_Error(t *testing.T) {
+	module := writeModule(t, writingModule(`{"protocol_version": 1, "error": "unsupported specVersion"}`))
+
+	agent, err := NewWasmAgent(Rule{Name: "Strict", Module: module})
+	require.NoError(t, err)
+	_, err = agent.Analyze(context.Background(), core.SBOM{})
+	assert.ErrorContains(t, err, `rule "Strict" failed: unsupported specVersion`)
+}
+
+func TestWasmAgent_Limits(t *testing.T) {
+	// A module asking for more memory than the limit is not run
+	agent, err := NewWasmAgent(Rule{Name: "Greedy", Module: writeModule(t, wasmModule(WasmMemoryLimitPages+1, nil))})
+	if err == nil {
+		_, err = agent.Analyze(context.Background(), core.SBOM{})
+	}
+	assert.Error(t, err)
+
+	// A module that never ends is stopped with its context
+	agent, err = NewWasmAgent(Rule{Name: "Endless", Module: writeModule(t, wasmModule(1, nil, 0x03, 0x40, 0x0c, 0, 0x0b))})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = agent.Analyze(ctx, core.SBOM{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWasmAgents(t *testing.T) {
+	module := writeModule(t, writingModule(`{"protocol_version": 1}`))
+	invalid := writeModule(t, []byte("not a module"))
+
+	agents, err := WasmAgents([]Rule{
+		{Name: "Valid", Module: module},
+		{Name: "Missing", Module: filepath.Join(t.TempDir(), "missing.wasm")},
+		{Name: "Invalid", Module: invalid},
+		{Module: module},
+	})
+	assert.ErrorContains(t, err, `failed to read module of rule "Missing"`)
+	assert.ErrorContains(t, err, `invalid module of rule "Invalid"`)
+	assert.ErrorContains(t, err, "name is required")
+	require.Len(t, agents, 1)
+	assert.Equal(t, "Valid", agents[0].Name())
+}
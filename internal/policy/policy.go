@@ -0,0 +1,283 @@
+// Package policy provides evaluation of gate policies written in Rego against
+// structured analysis results, so that teams can encode rules such as "no
+// Critical vulnerabilities in runtime components unless waived".
+//
+// Policies are evaluated with the Open Policy Agent library. Every policy
+// must be in the "sentinel" package and define a "deny" set of violation
+// messages:
+//
+//	package sentinel
+//
+//	import rego.v1
+//
+//	deny contains msg if {
+//		some finding in input.findings
+//		finding.severity == "Critical"
+//		finding.component.scope == "required"
+//		not data.waivers[finding.vulnerability_id]
+//		msg := sprintf("%s in %s", [finding.vulnerability_id, finding.component.name])
+//	}
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// Query is the Rego query whose result lists the policy violations.
+const Query = "data.sentinel.deny"
+
+// Input is the document a policy is evaluated against, available in Rego as
+// "input".
+type Input struct {
+	SBOM InputSBOM `json:"sbom"`
+	// Components lists every component of the SBOM
+	Components []core.Component `json:"components"`
+	// Findings lists the results of the analysis agents
+	Findings []core.AnalysisResult `json:"findings"`
+	// AgentStatus maps each agent that ran to "ok", "failed" or "timeout"
+	AgentStatus map[string]string `json:"agent_status,omitempty"`
+	// Admission describes the admitted workload when the policies are
+	// evaluated by the Kubernetes admission webhook
+	Admission *InputAdmission `json:"admission,omitempty"`
+}
+
+// InputAdmission describes a container of a workload being admitted to a
+// Kubernetes cluster.
+type InputAdmission struct {
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name,omitempty"`
+	Operation string `json:"operation,omitempty"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+}
+
+// InputSBOM describes the analyzed SBOM.
+type InputSBOM struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Tags label the SBOM, such as {"env": "prod"}, so that policies can be
+	// stricter for some SBOMs; they are empty if it has none
+	Tags map[string]string `json:"tags"`
+}
+
+// NewInput builds the policy input for the results of analyzing sbom. Its
+// tags are passed as they are, so the caller adds those of its project.
+func NewInput(sbom core.SBOM, findings []core.AnalysisResult, agentStatus map[string]string) Input {
+	if findings == nil {
+		findings = []core.AnalysisResult{}
+	}
+	components := sbom.Components
+	if components == nil {
+		components = []core.Component{}
+	}
+	tags := sbom.Tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	return Input{
+		SBOM: InputSBOM{
+			ID:       sbom.ID,
+			Name:     sbom.Name,
+			Metadata: sbom.Metadata,
+			Tags:     tags,
+		},
+		Components:  components,
+		Findings:    findings,
+		AgentStatus: agentStatus,
+	}
+}
+
+// Decision is the outcome of evaluating the policies.
+type Decision struct {
+	// Violations lists the messages of the denied rules, empty if the gate passed
+	Violations []string `json:"violations"`
+}
+
+// Passed reports whether no policy was violated.
+func (d *Decision) Passed() bool {
+	return len(d.Violations) == 0
+}
+
+// Evaluator evaluates gate policies against analysis results.
+type Evaluator interface {
+	Evaluate(ctx context.Context, input Input) (*Decision, error)
+}
+
+// RegoEvaluator evaluates Rego policy files with Open Policy Agent.
+type RegoEvaluator struct {
+	query rego.PreparedEvalQuery
+}
+
+// NewRegoEvaluator creates an evaluator for the given Rego policy files or
+// directories; data files (JSON or YAML, e.g. waivers) may be given as well
+// and are available to policies under "data". The files are read and the
+// policies compiled once, so changes are picked up by a new evaluator.
+func NewRegoEvaluator(paths ...string) (*RegoEvaluator, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no policy files given")
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to read policy '%s': %w", path, err)
+		}
+	}
+
+	query, err := rego.New(rego.Query(Query), rego.Load(paths, nil)).PrepareForEval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile policies: %w", err)
+	}
+	return &RegoEvaluator{query: query}, nil
+}
+
+// Evaluate evaluates the policies against input. A policy without a deny
+// rule, or whose deny rule is undefined, passes.
+func (e *RegoEvaluator) Evaluate(ctx context.Context, input Input) (*Decision, error) {
+	// The input is passed as JSON, so that policies see the JSON field names
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy input: %w", err)
+	}
+	var document any
+	if err := json.Unmarshal(inputJSON, &document); err != nil {
+		return nil, fmt.Errorf("failed to encode policy input: %w", err)
+	}
+
+	results, err := e.query.Eval(ctx, rego.EvalInput(document))
+	if err != nil {
+		return nil, fmt.Errorf("policy evaluation failed: %w", err)
+	}
+
+	decision := &Decision{Violations: []string{}}
+	for _, result := range results {
+		for _, expression := range result.Expressions {
+			value, err := json.Marshal(expression.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", Query, err)
+			}
+			violations, err := parseViolations(value)
+			if err != nil {
+				return nil, err
+			}
+			decision.Violations = append(decision.Violations, violations...)
+		}
+	}
+	return decision, nil
+}
+
+// parseViolations decodes the value of the deny set. Violations are usually
+// strings; objects are accepted if they carry a "msg" or "message" field.
+func parseViolations(value json.RawMessage) ([]string, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(value, &entries); err != nil {
+		return nil, fmt.Errorf("%s must be a set of violations, got %s", Query, value)
+	}
+
+	violations := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		var message string
+		if err := json.Unmarshal(entry, &message); err == nil {
+			violations = append(violations, message)
+			continue
+		}
+
+		var object struct {
+			Msg     string `json:"msg"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(entry, &object); err != nil || (object.Msg == "" && object.Message == "") {
+			violations = append(violations, string(entry))
+			continue
+		}
+		if object.Msg != "" {
+			violations = append(violations, object.Msg)
+		} else {
+			violations = append(violations, object.Message)
+		}
+	}
+	return violations, nil
+}
+
+var (
+	// defaultEvaluatorMu guards defaultEvaluator, which ReloadEvaluator replaces
+	defaultEvaluatorMu   sync.RWMutex
+	defaultEvaluator     Evaluator
+	defaultEvaluatorOnce sync.Once
+)
+
+// EvaluatorFromEnv returns the evaluator for the comma-separated policy files
+// and directories in $POLICY_PATH and the ConfiguredSources, created once or
+// by the last successful ReloadEvaluator. It returns nil if no policy is
+// configured or the policies cannot be used, which is reported as a warning.
+func EvaluatorFromEnv() Evaluator {
+	defaultEvaluatorOnce.Do(func() {
+		evaluator, err := evaluatorFromEnv()
+		if err != nil {
+			fmt.Printf("Warning: Policies in POLICY_PATH and the config file are not enforced: %v\n", err)
+			return
+		}
+		defaultEvaluatorMu.Lock()
+		defaultEvaluator = evaluator
+		defaultEvaluatorMu.Unlock()
+	})
+
+	defaultEvaluatorMu.RLock()
+	defer defaultEvaluatorMu.RUnlock()
+	return defaultEvaluator
+}
+
+// ReloadEvaluator re-creates the evaluator returned by EvaluatorFromEnv,
+// picking up policy files added or removed since. If the policies cannot be
+// used, the error is returned and the current evaluator kept.
+func ReloadEvaluator() (Evaluator, error) {
+	// Create the initial evaluator first, so that it does not replace the
+	// reloaded one
+	current := EvaluatorFromEnv()
+
+	evaluator, err := evaluatorFromEnv()
+	if err != nil {
+		return current, err
+	}
+	defaultEvaluatorMu.Lock()
+	defaultEvaluator = evaluator
+	defaultEvaluatorMu.Unlock()
+	return evaluator, nil
+}
+
+// evaluatorFromEnv creates the evaluator for $POLICY_PATH and the
+// configured policies, or returns nil if there are none.
+func evaluatorFromEnv() (Evaluator, error) {
+	var paths []string
+	for _, path := range strings.Split(os.Getenv("POLICY_PATH"), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if ConfiguredSources != nil {
+		configured, err := sourcePaths(ConfiguredSources())
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, configured...)
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	evaluator, err := NewRegoEvaluator(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return evaluator, nil
+}
@@ -0,0 +1,196 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePolicy writes a policy file named name in dir and returns its path.
+func writePolicy(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// gatePolicy denies Critical findings in required components unless waived
+// in data.waivers, reporting the others as objects.
+const gatePolicy = `package sentinel
+
+import rego.v1
+
+deny contains msg if {
+	some finding in input.findings
+	finding.severity == "Critical"
+	finding.component.scope == "required"
+	not data.waivers[finding.vulnerability_id]
+	msg := sprintf("%s in %s", [finding.vulnerability_id, finding.component.name])
+}
+
+deny contains {"msg": sprintf("agent %s did not run", [name])} if {
+	some name, status in input.agent_status
+	status != "ok"
+}
+`
+
+func testInput() Input {
+	sbom := core.SBOM{
+		ID:   "urn:uuid:1",
+		Name: "app",
+		Components: []core.Component{
+			{Name: "log4j-core", Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+		},
+	}
+	findings := []core.AnalysisResult{
+		{
+			AgentName:       "Vulnerability Scanner",
+			Finding:         "Log4Shell",
+			Severity:        "Critical",
+			Component:       sbom.Components[0].Ref(),
+			VulnerabilityID: "GHSA-jfh8-c2jp-5v3q",
+		},
+	}
+	return NewInput(sbom, findings, map[string]string{"Vulnerability Scanner": "ok"})
+}
+
+func TestRegoEvaluator_Violations(t *testing.T) {
+	policyPath := writePolicy(t, t.TempDir(), "gate.rego", gatePolicy)
+	evaluator, err := NewRegoEvaluator(policyPath)
+	require.NoError(t, err)
+
+	input := testInput()
+	input.AgentStatus["License Scanner"] = "timeout"
+	decision, err := evaluator.Evaluate(context.Background(), input)
+	require.NoError(t, err)
+	assert.False(t, decision.Passed())
+	assert.ElementsMatch(t, []string{"GHSA-jfh8-c2jp-5v3q in log4j-core", "agent License Scanner did not run"}, decision.Violations)
+
+	// Data files are available to the policies
+	dir := t.TempDir()
+	waivers := writePolicy(t, dir, "waivers.json", `{"waivers": {"GHSA-jfh8-c2jp-5v3q": "not reachable"}}`)
+	evaluator, err = NewRegoEvaluator(policyPath, waivers)
+	require.NoError(t, err)
+	decision, err = evaluator.Evaluate(context.Background(), testInput())
+	require.NoError(t, err)
+	assert.True(t, decision.Passed())
+}
+
+func TestRegoEvaluator_Undefined(t *testing.T) {
+	// A policy without a deny rule passes
+	policyPath := writePolicy(t, t.TempDir(), "empty.rego", "package sentinel\n")
+
+	evaluator, err := NewRegoEvaluator(policyPath)
+	require.NoError(t, err)
+
+	decision, err := evaluator.Evaluate(context.Background(), testInput())
+	require.NoError(t, err)
+	assert.True(t, decision.Passed())
+	assert.Empty(t, decision.Violations)
+}
+
+func TestRegoEvaluator_Errors(t *testing.T) {
+	t.Run("compile error", func(t *testing.T) {
+		policyPath := writePolicy(t, t.TempDir(), "gate.rego", "package sentinel\n\ndeny contains msg if {")
+		_, err := NewRegoEvaluator(policyPath)
+		assert.ErrorContains(t, err, "failed to compile policies")
+		assert.ErrorContains(t, err, "rego_parse_error")
+	})
+
+	t.Run("deny is not a set", func(t *testing.T) {
+		policyPath := writePolicy(t, t.TempDir(), "gate.rego", "package sentinel\n\ndeny := true\n")
+		evaluator, err := NewRegoEvaluator(policyPath)
+		require.NoError(t, err)
+
+		_, err = evaluator.Evaluate(context.Background(), testInput())
+		assert.ErrorContains(t, err, "must be a set of violations")
+	})
+
+	t.Run("missing policy", func(t *testing.T) {
+		_, err := NewRegoEvaluator(filepath.Join(t.TempDir(), "missing.rego"))
+		assert.ErrorContains(t, err, "failed to read policy")
+	})
+
+	t.Run("no policies", func(t *testing.T) {
+		_, err := NewRegoEvaluator()
+		assert.Error(t, err)
+	})
+}
+
+func TestNewInput_Empty(t *testing.T) {
+	data, err := json.Marshal(NewInput(core.SBOM{ID: "empty"}, nil, nil))
+	require.NoError(t, err)
+	// Policies can iterate over empty collections rather than undefined ones
+	assert.JSONEq(t, `{"sbom":{"id":"empty","name":"","tags":{}},"components":[],"findings":[]}`, string(data))
+}
+
+func TestNewInput_Tags(t *testing.T) {
+	sbom := core.SBOM{ID: "sbom-1", Name: "storefront", Tags: map[string]string{"env": "prod"}}
+	data, err := json.Marshal(NewInput(sbom, nil, nil).SBOM)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"sbom-1","name":"storefront","tags":{"env":"prod"}}`, string(data))
+}
+
+func TestReloadEvaluator(t *testing.T) {
+	policyPath := writePolicy(t, t.TempDir(), "gate.rego", gatePolicy)
+	t.Setenv("POLICY_PATH", policyPath)
+
+	evaluator, err := ReloadEvaluator()
+	require.NoError(t, err)
+	require.NotNil(t, evaluator)
+	assert.Equal(t, evaluator, EvaluatorFromEnv())
+
+	// Missing policies keep the current evaluator
+	t.Setenv("POLICY_PATH", filepath.Join(t.TempDir(), "missing.rego"))
+	current, err := ReloadEvaluator()
+	assert.ErrorContains(t, err, "failed to read policy")
+	assert.Equal(t, evaluator, current)
+	assert.Equal(t, evaluator, EvaluatorFromEnv())
+
+	// Removing POLICY_PATH disables the gate
+	t.Setenv("POLICY_PATH", "")
+	evaluator, err = ReloadEvaluator()
+	require.NoError(t, err)
+	assert.Nil(t, evaluator)
+	assert.Nil(t, EvaluatorFromEnv())
+}
+
+func TestSource_Validate(t *testing.T) {
+	assert.NoError(t, Source{Path: "gate.rego"}.Validate())
+	assert.NoError(t, Source{Name: "gate", Rego: "package sentinel\n"}.Validate())
+	assert.EqualError(t, Source{Name: "gate"}.Validate(), "path or rego is required")
+	assert.EqualError(t, Source{Path: "gate.rego", Rego: "package sentinel\n"}.Validate(), "path and rego are mutually exclusive")
+}
+
+func TestReloadEvaluator_ConfiguredSources(t *testing.T) {
+	policyPath := writePolicy(t, t.TempDir(), "gate.rego", gatePolicy)
+	t.Setenv("POLICY_PATH", "")
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Cleanup(func() { ConfiguredSources = nil })
+	ConfiguredSources = func() []Source {
+		return []Source{{Path: policyPath}, {Name: "team/gate", Rego: "package sentinel\n\nimport rego.v1\n\ndeny contains \"team gate\" if input.sbom.name == \"app\"\n"}}
+	}
+
+	evaluator, err := ReloadEvaluator()
+	require.NoError(t, err)
+	require.NotNil(t, evaluator)
+	decision, err := evaluator.Evaluate(context.Background(), testInput())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"GHSA-jfh8-c2jp-5v3q in log4j-core", "team gate"}, decision.Violations)
+
+	// Inline modules are evaluated from files named after their content
+	inline, err := filepath.Glob(filepath.Join(os.TempDir(), "sentinel-policies", "team_gate-*.rego"))
+	require.NoError(t, err)
+	assert.Len(t, inline, 1)
+
+	ConfiguredSources = nil
+	evaluator, err = ReloadEvaluator()
+	require.NoError(t, err)
+	assert.Nil(t, evaluator)
+}
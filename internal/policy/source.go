@@ -0,0 +1,75 @@
+// Package policy provides gate policies declared in the configuration file.
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source is a gate policy declared in the configuration file: a Rego policy
+// file, directory or data file at Path, or a Rego module given inline, so
+// that a configuration applied to a remote server carries its policies.
+type Source struct {
+	// Name identifies an inline policy in errors
+	Name string `yaml:"name"`
+	Path string `yaml:"path,omitempty"`
+	Rego string `yaml:"rego,omitempty"`
+}
+
+// Validate checks that the source gives either a path or an inline module.
+func (s Source) Validate() error {
+	hasPath, hasRego := strings.TrimSpace(s.Path) != "", strings.TrimSpace(s.Rego) != ""
+	switch {
+	case hasPath && hasRego:
+		return errors.New("path and rego are mutually exclusive")
+	case !hasPath && !hasRego:
+		return errors.New("path or rego is required")
+	}
+	return nil
+}
+
+// ConfiguredSources returns the policies declared in the configuration
+// file, which are evaluated together with those in $POLICY_PATH. The config
+// package sets it, as it depends on this package.
+var ConfiguredSources func() []Source
+
+// sourcePaths returns the paths evaluated for sources. Inline modules
+// are written to files named after their content in the temporary
+// directory, so that reloading an unchanged configuration writes nothing.
+func sourcePaths(sources []Source) ([]string, error) {
+	var paths []string
+	for _, source := range sources {
+		if source.Path != "" {
+			paths = append(paths, source.Path)
+			continue
+		}
+
+		dir := filepath.Join(os.TempDir(), "sentinel-policies")
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create policy directory: %w", err)
+		}
+		digest := sha256.Sum256([]byte(source.Rego))
+		name := "inline"
+		if source.Name != "" {
+			name = strings.Map(func(r rune) rune {
+				if r == '/' || r == '\\' || r == os.PathSeparator {
+					return '_'
+				}
+				return r
+			}, source.Name)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.rego", name, hex.EncodeToString(digest[:6])))
+		if _, err := os.Stat(path); err != nil {
+			if err := os.WriteFile(path, []byte(source.Rego), 0o600); err != nil {
+				return nil, fmt.Errorf("failed to write policy '%s': %w", source.Name, err)
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
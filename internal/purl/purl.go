@@ -0,0 +1,238 @@
+// Package purl parses and formats Package URLs (PURLs), the
+// "pkg:type/namespace/name@version?qualifiers#subpath" identifiers SBOMs use
+// to name packages across ecosystems, following the purl specification.
+package purl
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PackageURL is a parsed Package URL. Components are stored decoded.
+type PackageURL struct {
+	// Type is the package type, e.g. "npm", "maven" or "deb", always lower-case
+	Type string
+	// Namespace is the type-specific prefix of the name, such as a Maven
+	// group, an npm scope or a Go module path; segments are separated by "/"
+	Namespace string
+	Name      string
+	Version   string
+	// Qualifiers are extra type-specific details, e.g. "arch" or "distro";
+	// keys are lower-case and values non-empty
+	Qualifiers map[string]string
+	// Subpath is a path within the package, e.g. a Go package of a module
+	Subpath string
+}
+
+// typePattern matches valid package types.
+var typePattern = regexp.MustCompile(`^[a-z][a-z0-9.+-]*$`)
+
+// qualifierKeyPattern matches valid qualifier keys.
+var qualifierKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9._-]*$`)
+
+// requiredNamespaces are the types whose packages are not identified
+// without a namespace.
+var requiredNamespaces = map[string]string{
+	"maven":     "group ID",
+	"golang":    "module path",
+	"composer":  "vendor",
+	"github":    "owner",
+	"bitbucket": "owner",
+}
+
+// Parse parses a Package URL. Names of types whose names are
+// case-insensitive (e.g. npm, PyPI, GitHub) are lower-cased, and PyPI names
+// normalized, so that equivalent PURLs parse equally.
+func Parse(s string) (PackageURL, error) {
+	var p PackageURL
+	invalid := func(reason string, args ...interface{}) (PackageURL, error) {
+		return PackageURL{}, fmt.Errorf("invalid package URL %q: %s", s, fmt.Sprintf(reason, args...))
+	}
+
+	if len(s) < len("pkg:") || !strings.EqualFold(s[:len("pkg:")], "pkg:") {
+		return invalid("scheme must be pkg")
+	}
+	rest := strings.TrimLeft(s[len("pkg:"):], "/")
+
+	if before, subpath, ok := strings.Cut(rest, "#"); ok {
+		rest = before
+		segments, err := decodeSegments(subpath)
+		if err != nil {
+			return invalid("subpath: %v", err)
+		}
+		p.Subpath = strings.Join(segments, "/")
+	}
+
+	if before, rawQuery, ok := strings.Cut(rest, "?"); ok {
+		rest = before
+		for _, pair := range strings.Split(rawQuery, "&") {
+			key, value, _ := strings.Cut(pair, "=")
+			key = strings.ToLower(key)
+			if key == "" && value == "" {
+				continue
+			}
+			if !qualifierKeyPattern.MatchString(key) {
+				return invalid("invalid qualifier key %q", key)
+			}
+			decoded, err := url.PathUnescape(value)
+			if err != nil {
+				return invalid("qualifier %s: %v", key, err)
+			}
+			if _, duplicate := p.Qualifiers[key]; duplicate {
+				return invalid("duplicate qualifier %q", key)
+			}
+			if decoded == "" {
+				continue
+			}
+			if p.Qualifiers == nil {
+				p.Qualifiers = make(map[string]string)
+			}
+			p.Qualifiers[key] = decoded
+		}
+	}
+
+	purlType, path, ok := strings.Cut(rest, "/")
+	if !ok {
+		return invalid("name is required")
+	}
+	p.Type = strings.ToLower(purlType)
+	if !typePattern.MatchString(p.Type) {
+		return invalid("invalid type %q", purlType)
+	}
+
+	// The version follows the last "@" of the name; an "@" in the namespace,
+	// such as an unencoded npm scope, does not start a version
+	path = strings.TrimRight(path, "/")
+	if i := strings.LastIndex(path, "@"); i > strings.LastIndex(path, "/") {
+		version, err := url.PathUnescape(path[i+1:])
+		if err != nil {
+			return invalid("version: %v", err)
+		}
+		p.Version, path = version, path[:i]
+	}
+
+	segments, err := decodeSegments(path)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if len(segments) == 0 {
+		return invalid("name is required")
+	}
+	p.Name = segments[len(segments)-1]
+	p.Namespace = strings.Join(segments[:len(segments)-1], "/")
+
+	if what, required := requiredNamespaces[p.Type]; required && p.Namespace == "" {
+		return invalid("%s packages require a namespace (%s)", p.Type, what)
+	}
+
+	p.normalize()
+	return p, nil
+}
+
+// decodeSegments splits a path at "/" and decodes its non-empty segments.
+func decodeSegments(path string) ([]string, error) {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+		if decoded == "." || decoded == ".." {
+			return nil, fmt.Errorf("invalid segment %q", decoded)
+		}
+		segments = append(segments, decoded)
+	}
+	return segments, nil
+}
+
+// normalize applies the type-specific case rules of the purl specification.
+func (p *PackageURL) normalize() {
+	switch p.Type {
+	case "npm", "github", "bitbucket", "composer", "hex", "deb", "apk":
+		p.Namespace = strings.ToLower(p.Namespace)
+		p.Name = strings.ToLower(p.Name)
+	case "pypi":
+		p.Name = strings.ReplaceAll(strings.ToLower(p.Name), "_", "-")
+	}
+}
+
+// String formats the Package URL in canonical form, with qualifiers sorted
+// by key.
+func (p PackageURL) String() string {
+	var b strings.Builder
+	b.WriteString("pkg:")
+	b.WriteString(p.Type)
+	b.WriteString("/")
+	if p.Namespace != "" {
+		for _, segment := range strings.Split(p.Namespace, "/") {
+			b.WriteString(escape(segment, ":@"))
+			b.WriteString("/")
+		}
+	}
+	b.WriteString(escape(p.Name, ":"))
+	if p.Version != "" {
+		b.WriteString("@")
+		b.WriteString(escape(p.Version, ":+"))
+	}
+
+	if len(p.Qualifiers) > 0 {
+		keys := make([]string, 0, len(p.Qualifiers))
+		for key := range p.Qualifiers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if i == 0 {
+				b.WriteString("?")
+			} else {
+				b.WriteString("&")
+			}
+			b.WriteString(key)
+			b.WriteString("=")
+			b.WriteString(escape(p.Qualifiers[key], ":/@+,"))
+		}
+	}
+
+	if p.Subpath != "" {
+		b.WriteString("#")
+		for i, segment := range strings.Split(p.Subpath, "/") {
+			if i > 0 {
+				b.WriteString("/")
+			}
+			b.WriteString(escape(segment, ":@+"))
+		}
+	}
+	return b.String()
+}
+
+// escape percent-encodes every byte of s except unreserved characters and
+// those in keep.
+func escape(s, keep string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', strings.IndexByte(keep, c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// FullName returns the namespace and name joined by "/", or the name alone
+// for packages without a namespace.
+func (p PackageURL) FullName() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+	return p.Namespace + "/" + p.Name
+}
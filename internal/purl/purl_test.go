@@ -0,0 +1,93 @@
+package purl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		purl     string
+		expected PackageURL
+	}{
+		{"pkg:npm/lodash@4.17.21", PackageURL{Type: "npm", Name: "lodash", Version: "4.17.21"}},
+		{"pkg:npm/%40babel/core@7.0.0", PackageURL{Type: "npm", Namespace: "@babel", Name: "core", Version: "7.0.0"}},
+		{"pkg:npm/@Babel/Core@7.0.0", PackageURL{Type: "npm", Namespace: "@babel", Name: "core", Version: "7.0.0"}},
+		{"pkg:MAVEN/org.Apache/Commons@1.0", PackageURL{Type: "maven", Namespace: "org.Apache", Name: "Commons", Version: "1.0"}},
+		{"pkg:pypi/Django_Rest@3.0.0", PackageURL{Type: "pypi", Name: "django-rest", Version: "3.0.0"}},
+		{"pkg:golang/github.com/foo/bar@v1.0.0#cmd/baz", PackageURL{Type: "golang", Namespace: "github.com/foo", Name: "bar", Version: "v1.0.0", Subpath: "cmd/baz"}},
+		{"pkg://nuget/Newtonsoft.Json", PackageURL{Type: "nuget", Name: "Newtonsoft.Json"}},
+		{
+			"pkg:deb/debian/curl@7.50.3-1?arch=i386&Distro=jessie&empty=",
+			PackageURL{Type: "deb", Namespace: "debian", Name: "curl", Version: "7.50.3-1", Qualifiers: map[string]string{"arch": "i386", "distro": "jessie"}},
+		},
+		{
+			"pkg:oci/alpine@sha256%3Aabc?repository_url=docker.io%2Flibrary%2Falpine",
+			PackageURL{Type: "oci", Name: "alpine", Version: "sha256:abc", Qualifiers: map[string]string{"repository_url": "docker.io/library/alpine"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			parsed, err := Parse(tt.purl)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, parsed)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"lodash":                            "scheme must be pkg",
+		"pkg:npm":                           "name is required",
+		"pkg:npm/":                          "name is required",
+		"pkg:1npm/lodash":                   "invalid type",
+		"pkg:maven/commons-lang@2.6":        "require a namespace",
+		"pkg:npm/lodash?arch=x&arch=y":      "duplicate qualifier",
+		"pkg:npm/lodash?1arch=x":            "invalid qualifier key",
+		"pkg:npm/lodash@%zz":                "version",
+		"pkg:golang/github.com/../x@v1.0.0": "invalid segment",
+	}
+
+	for purl, reason := range tests {
+		t.Run(purl, func(t *testing.T) {
+			_, err := Parse(purl)
+			assert.ErrorContains(t, err, reason)
+		})
+	}
+}
+
+func TestPackageURL_String(t *testing.T) {
+	tests := []struct {
+		purl     string
+		expected string
+	}{
+		{"pkg:npm/lodash@4.17.21", "pkg:npm/lodash@4.17.21"},
+		{"pkg:npm/%40babel/core@7.0.0", "pkg:npm/@babel/core@7.0.0"},
+		{"pkg:npm/@Babel/Core@7.0.0", "pkg:npm/@babel/core@7.0.0"},
+		{"pkg:deb/debian/curl@7.50?distro=jessie&arch=i386", "pkg:deb/debian/curl@7.50?arch=i386&distro=jessie"},
+		{"pkg:deb/debian/bash@5.2.15-2+b2?upstream=bash%405.2", "pkg:deb/debian/bash@5.2.15-2+b2?upstream=bash@5.2"},
+		{"pkg:GOLANG/github.com/foo/bar@v1.0.0#cmd/baz", "pkg:golang/github.com/foo/bar@v1.0.0#cmd/baz"},
+		{"pkg:generic/my%20tool@1.0", "pkg:generic/my%20tool@1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			parsed, err := Parse(tt.purl)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, parsed.String())
+
+			// The canonical form parses to the same package
+			reparsed, err := Parse(parsed.String())
+			require.NoError(t, err)
+			assert.Equal(t, parsed, reparsed)
+		})
+	}
+}
+
+func TestPackageURL_FullName(t *testing.T) {
+	assert.Equal(t, "lodash", PackageURL{Type: "npm", Name: "lodash"}.FullName())
+	assert.Equal(t, "github.com/foo/bar", PackageURL{Type: "golang", Namespace: "github.com/foo", Name: "bar"}.FullName())
+}
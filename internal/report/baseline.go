@@ -0,0 +1,141 @@
+// Package report provides findings baselines, which record the findings of
+// a project when it adopts SBOM Sentinel so that CI only fails on findings
+// introduced since.
+package report
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// BaselineFormat identifies findings baseline files.
+const BaselineFormat = "sbom-sentinel-baseline"
+
+// BaselineFinding is a finding recorded in a baseline.
+type BaselineFinding struct {
+	// Fingerprint identifies the finding across versions of its component
+	Fingerprint string `json:"fingerprint"`
+	AgentName   string `json:"agent_name"`
+	Severity    string `json:"severity"`
+	Finding     string `json:"finding"`
+	Component   string `json:"component,omitempty"`
+}
+
+// Baseline is a set of accepted findings, as written by ci --write-baseline.
+type Baseline struct {
+	Format    string            `json:"format"`
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	Source    string            `json:"source"`
+	Findings  []BaselineFinding `json:"findings"`
+
+	fingerprints map[string]bool
+}
+
+// NewBaseline records the findings of the SBOM file at source.
+func NewBaseline(source string, results []core.AnalysisResult, now time.Time) *Baseline {
+	baseline := &Baseline{
+		Format:    BaselineFormat,
+		Version:   1,
+		CreatedAt: now.UTC(),
+		Source:    source,
+		Findings:  make([]BaselineFinding, 0, len(results)),
+	}
+	for _, result := range results {
+		baseline.Findings = append(baseline.Findings, BaselineFinding{
+			Fingerprint: Fingerprint(result),
+			AgentName:   result.AgentName,
+			Severity:    result.Severity,
+			Finding:     result.Finding,
+			Component:   componentLabel(result),
+		})
+	}
+	baseline.index()
+	return baseline
+}
+
+// Fingerprint identifies a finding by its agent, component name and advisory
+// ID or, without one, its text with the component's name and version left
+// out, so that a finding is still recognized after its component is
+// upgraded. Severity changes do not change the fingerprint.
+func Fingerprint(result core.AnalysisResult) string {
+	subject := result.VulnerabilityID
+	if subject == "" {
+		subject = findingTemplate(result)
+	}
+	component := ""
+	if result.Component != nil {
+		component = result.Component.Name
+	}
+
+	sum := sha256.Sum256([]byte(result.AgentName + "\x00" + result.RuleID + "\x00" + component + "\x00" + subject))
+	return hex.EncodeToString(sum[:])
+}
+
+// Contains reports whether the baseline records the finding.
+func (b *Baseline) Contains(result core.AnalysisResult) bool {
+	return b.fingerprints[Fingerprint(result)]
+}
+
+// index builds the fingerprint set of the baseline.
+func (b *Baseline) index() {
+	b.fingerprints = make(map[string]bool, len(b.Findings))
+	for _, finding := range b.Findings {
+		b.fingerprints[finding.Fingerprint] = true
+	}
+}
+
+// WriteBaseline writes the baseline as indented JSON.
+func WriteBaseline(w io.Writer, b *Baseline) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// IsBaseline reports whether data is a findings baseline rather than, for
+// example, an SBOM.
+func IsBaseline(data []byte) bool {
+	var header struct {
+		Format string `json:"format"`
+	}
+	return json.Unmarshal(data, &header) == nil && header.Format == BaselineFormat
+}
+
+// ParseBaseline parses a findings baseline.
+func ParseBaseline(data []byte) (*Baseline, error) {
+	if !IsBaseline(data) {
+		return nil, fmt.Errorf("not a findings baseline (expected format %q)", BaselineFormat)
+	}
+
+	var baseline Baseline
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&baseline); err != nil {
+		return nil, fmt.Errorf("invalid findings baseline: %w", err)
+	}
+	if baseline.Version != 1 {
+		return nil, fmt.Errorf("unsupported findings baseline version %d", baseline.Version)
+	}
+	baseline.index()
+	return &baseline, nil
+}
+
+// LoadBaseline reads the findings baseline at path.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline '%s': %w", path, err)
+	}
+	baseline, err := ParseBaseline(data)
+	if err != nil {
+		return nil, fmt.Errorf("baseline '%s': %w", path, err)
+	}
+	return baseline, nil
+}
@@ -0,0 +1,141 @@
+// Package report provides a CycloneDX Cryptography Bill of Materials (CBOM)
+// rendering of an SBOM's cryptographic library inventory.
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// cbomSpecVersion is the first CycloneDX version defining cryptographic assets.
+const cbomSpecVersion = "1.6"
+
+// cbomDocument is the subset of a CycloneDX 1.6 BOM needed for a CBOM.
+type cbomDocument struct {
+	BOMFormat    string           `json:"bomFormat"`
+	SpecVersion  string           `json:"specVersion"`
+	Version      int              `json:"version"`
+	Metadata     cbomMetadata     `json:"metadata"`
+	Components   []cbomComponent  `json:"components"`
+	Dependencies []cbomDependency `json:"dependencies"`
+}
+
+type cbomMetadata struct {
+	Timestamp string         `json:"timestamp"`
+	Tools     cbomTools      `json:"tools"`
+	Component *cbomComponent `json:"component,omitempty"`
+}
+
+type cbomTools struct {
+	Components []cbomComponent `json:"components"`
+}
+
+type cbomComponent struct {
+	Type             string                `json:"type"`
+	BOMRef           string                `json:"bom-ref,omitempty"`
+	Name             string                `json:"name"`
+	Version          string                `json:"version,omitempty"`
+	PURL             string                `json:"purl,omitempty"`
+	CryptoProperties *cbomCryptoProperties `json:"cryptoProperties,omitempty"`
+	Properties       []cbomProperty        `json:"properties,omitempty"`
+}
+
+type cbomCryptoProperties struct {
+	AssetType           string                  `json:"assetType"`
+	AlgorithmProperties cbomAlgorithmProperties `json:"algorithmProperties"`
+}
+
+type cbomAlgorithmProperties struct {
+	Primitive string `json:"primitive"`
+	// NISTQuantumSecurityLevel is 0 for algorithms a quantum computer breaks.
+	// Quantum-safe algorithms get the minimum level 1, since the key sizes in
+	// use are not known
+	NISTQuantumSecurityLevel int `json:"nistQuantumSecurityLevel"`
+}
+
+type cbomProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cbomDependency struct {
+	Ref      string   `json:"ref"`
+	Provides []string `json:"provides"`
+}
+
+// WriteCBOM writes the cryptographic inventory of the named application as
+// a CycloneDX 1.6 CBOM. Each library is a component that provides its
+// algorithms, which are listed once as cryptographic assets. FIPS 140 status
+// and concerns are recorded as sbom-sentinel properties of the library.
+func WriteCBOM(w io.Writer, name string, assets []analysis.CryptoAsset) error {
+	doc := cbomDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: cbomSpecVersion,
+		Version:     1,
+		Metadata: cbomMetadata{
+			Timestamp: core.Now().UTC().Format(time.RFC3339),
+			Tools:     cbomTools{Components: []cbomComponent{{Type: "application", Name: "SBOM Sentinel"}}},
+		},
+		Components:   make([]cbomComponent, 0),
+		Dependencies: make([]cbomDependency, 0),
+	}
+	if name != "" {
+		doc.Metadata.Component = &cbomComponent{Type: "application", Name: name}
+	}
+
+	var algorithms []cbomComponent
+	seen := make(map[string]bool)
+	for _, asset := range assets {
+		ref := asset.Component.PURL
+		if ref == "" {
+			ref = asset.Component.Name + "@" + asset.Component.Version
+		}
+
+		library := cbomComponent{
+			Type:       "library",
+			BOMRef:     ref,
+			Name:       asset.Component.Name,
+			Version:    asset.Component.Version,
+			PURL:       asset.Component.PURL,
+			Properties: []cbomProperty{{Name: "sbom-sentinel:crypto-library", Value: asset.Library}, {Name: "sbom-sentinel:fips-140", Value: asset.FIPS}},
+		}
+		for _, concern := range asset.Concerns {
+			library.Properties = append(library.Properties, cbomProperty{Name: "sbom-sentinel:concern", Value: concern})
+		}
+		doc.Components = append(doc.Components, library)
+
+		dependency := cbomDependency{Ref: ref, Provides: make([]string, 0, len(asset.Algorithms))}
+		for _, algorithm := range asset.Algorithms {
+			algorithmRef := "crypto/algorithm/" + algorithm.Name
+			dependency.Provides = append(dependency.Provides, algorithmRef)
+			if seen[algorithmRef] {
+				continue
+			}
+			seen[algorithmRef] = true
+
+			level := 0
+			if algorithm.QuantumSafe {
+				level = 1
+			}
+			algorithms = append(algorithms, cbomComponent{
+				Type:   "cryptographic-asset",
+				BOMRef: algorithmRef,
+				Name:   algorithm.Name,
+				CryptoProperties: &cbomCryptoProperties{
+					AssetType:           "algorithm",
+					AlgorithmProperties: cbomAlgorithmProperties{Primitive: algorithm.Primitive, NISTQuantumSecurityLevel: level},
+				},
+			})
+		}
+		doc.Dependencies = append(doc.Dependencies, dependency)
+	}
+	doc.Components = append(doc.Components, algorithms...)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,97 @@
+// Package report provides a Markdown rendering of analysis results for pull
+// and merge request comments, highlighting what changed against the target branch.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteComment writes the report as a pull request comment. Given a diff
+// against the target branch's SBOM, it leads with the new and resolved
+// findings and the dependency changes, listing all findings in a collapsed
+// section; without one it lists all findings.
+func WriteComment(w io.Writer, r *Report, diff *Diff) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "### 🛡️ SBOM Sentinel: %s\n\n", escapeMarkdown(r.SBOM.Name))
+	fmt.Fprintf(out, "%s — %d findings in `%s`", verdict(r), len(r.Results), r.Source)
+	if diff != nil {
+		fmt.Fprintf(out, " (%d new, %d resolved)", len(diff.NewFindings), len(diff.ResolvedFindings))
+	}
+	if len(r.Results) > 0 {
+		risk := r.Risk()
+		fmt.Fprintf(out, " · risk score %d/100 (%s)", risk.Score, risk.Level)
+	}
+	fmt.Fprintf(out, "\n")
+
+	if diff == nil {
+		if len(r.Results) > 0 {
+			fmt.Fprintf(out, "\n")
+			writeFindingsTable(out, r, r.Results)
+		}
+	} else {
+		if len(diff.NewFindings) > 0 {
+			fmt.Fprintf(out, "\n#### 🆕 New Findings\n\n")
+			writeFindingsTable(out, r, diff.NewFindings)
+		}
+
+		if len(diff.ResolvedFindings) > 0 {
+			fmt.Fprintf(out, "\n#### ✅ Resolved Findings\n\n")
+			for i, result := range diff.ResolvedFindings {
+				if i == MaxMarkdownFindings {
+					fmt.Fprintf(out, "- … and %d more\n", len(diff.ResolvedFindings)-MaxMarkdownFindings)
+					break
+				}
+				fmt.Fprintf(out, "- ~~%s %s~~ (%s)\n", severityIcon(result.Severity), escapeMarkdown(result.Finding), escapeMarkdown(result.AgentName))
+			}
+		}
+
+		if len(diff.AddedComponents)+len(diff.RemovedComponents)+len(diff.ChangedComponents) > 0 {
+			fmt.Fprintf(out, "\n#### 📦 Dependency Changes\n\n")
+			fmt.Fprintf(out, "| Component | Change |\n|---|---|\n")
+			for _, component := range diff.AddedComponents {
+				fmt.Fprintf(out, "| %s | added %s |\n", escapeMarkdown(component.Name), escapeMarkdown(component.Version))
+			}
+			for _, change := range diff.ChangedComponents {
+				fmt.Fprintf(out, "| %s | %s → %s |\n", escapeMarkdown(change.Name), escapeMarkdown(change.From), escapeMarkdown(change.To))
+			}
+			for _, component := range diff.RemovedComponents {
+				fmt.Fprintf(out, "| %s | removed %s |\n", escapeMarkdown(component.Name), escapeMarkdown(component.Version))
+			}
+		}
+
+		if diff.Empty() {
+			fmt.Fprintf(out, "\nNo changes against the target branch.\n")
+		}
+
+		if len(r.Results) > 0 {
+			fmt.Fprintf(out, "\n<details>\n<summary>All findings (%d)</summary>\n\n", len(r.Results))
+			writeFindingsTable(out, r, r.Results)
+			fmt.Fprintf(out, "\n</details>\n")
+		}
+	}
+
+	if len(r.Ignored) > 0 {
+		fmt.Fprintf(out, "\n<details>\n<summary>🙈 Ignored findings (%d)</summary>\n\n", len(r.Ignored))
+		writeIgnoredTable(out, r.Ignored)
+		fmt.Fprintf(out, "\n</details>\n")
+	}
+
+	if r.Policy != nil && !r.Policy.Passed() {
+		fmt.Fprintf(out, "\n#### Policy Gate\n\n")
+		for _, violation := range r.Policy.Violations {
+			fmt.Fprintf(out, "- ❌ %s\n", escapeMarkdown(violation))
+		}
+	}
+
+	if len(r.Incomplete) > 0 {
+		fmt.Fprintf(out, "\n#### ⚠️ Incomplete Analysis\n\n")
+		for _, run := range r.Incomplete {
+			fmt.Fprintf(out, "- %s (%s): %s\n", escapeMarkdown(run.Agent), run.Status, escapeMarkdown(fmt.Sprint(run.Err)))
+		}
+	}
+
+	return out.Flush()
+}
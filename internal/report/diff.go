@@ -0,0 +1,103 @@
+// Package report provides comparison of analysis results against a baseline,
+// such as the SBOM of a pull request's target branch.
+package report
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// ComponentChange is a component whose version differs from the baseline.
+type ComponentChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Diff describes how an SBOM and its findings changed relative to a baseline.
+type Diff struct {
+	// NewFindings are reported for the SBOM but not for the baseline
+	NewFindings []core.AnalysisResult
+	// ResolvedFindings were reported for the baseline but no longer are
+	ResolvedFindings []core.AnalysisResult
+	// AddedComponents and RemovedComponents are components present in only
+	// one of the SBOMs
+	AddedComponents   []core.Component
+	RemovedComponents []core.Component
+	// ChangedComponents are components whose version changed
+	ChangedComponents []ComponentChange
+}
+
+// NewDiff compares an SBOM and its findings with a baseline SBOM and its
+// findings. Findings are matched by agent, severity and text, and
+// components by name.
+func NewDiff(baseline core.SBOM, baselineResults []core.AnalysisResult, current core.SBOM, currentResults []core.AnalysisResult) *Diff {
+	diff := &Diff{}
+
+	findingKey := func(result core.AnalysisResult) string {
+		return result.AgentName + "\x00" + result.Severity + "\x00" + result.Finding
+	}
+	before := make(map[string]bool, len(baselineResults))
+	for _, result := range baselineResults {
+		before[findingKey(result)] = true
+	}
+	after := make(map[string]bool, len(currentResults))
+	for _, result := range currentResults {
+		after[findingKey(result)] = true
+		if !before[findingKey(result)] {
+			diff.NewFindings = append(diff.NewFindings, result)
+		}
+	}
+	for _, result := range baselineResults {
+		if !after[findingKey(result)] {
+			diff.ResolvedFindings = append(diff.ResolvedFindings, result)
+		}
+	}
+
+	baseComponents := componentVersions(baseline.Components)
+	currentComponents := componentVersions(current.Components)
+	for _, component := range current.Components {
+		if _, ok := baseComponents[component.Name]; !ok {
+			diff.AddedComponents = append(diff.AddedComponents, component)
+		}
+	}
+	for _, component := range baseline.Components {
+		if _, ok := currentComponents[component.Name]; !ok {
+			diff.RemovedComponents = append(diff.RemovedComponents, component)
+		}
+	}
+	for name, to := range currentComponents {
+		if from, ok := baseComponents[name]; ok && from != to {
+			diff.ChangedComponents = append(diff.ChangedComponents, ComponentChange{Name: name, From: from, To: to})
+		}
+	}
+	sort.Slice(diff.ChangedComponents, func(i, j int) bool {
+		return diff.ChangedComponents[i].Name < diff.ChangedComponents[j].Name
+	})
+
+	return diff
+}
+
+// Empty reports whether nothing changed.
+func (d *Diff) Empty() bool {
+	return len(d.NewFindings) == 0 && len(d.ResolvedFindings) == 0 &&
+		len(d.AddedComponents) == 0 && len(d.RemovedComponents) == 0 && len(d.ChangedComponents) == 0
+}
+
+// componentVersions maps component names to their versions. A component
+// present in several versions maps to all of them, sorted and comma-separated.
+func componentVersions(components []core.Component) map[string]string {
+	versions := make(map[string][]string)
+	for _, component := range components {
+		versions[component.Name] = append(versions[component.Name], component.Version)
+	}
+
+	result := make(map[string]string, len(versions))
+	for name, list := range versions {
+		sort.Strings(list)
+		result[name] = strings.Join(list, ", ")
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+// Package report provides a CSV rendering of the export-control inventory
+// for trade-compliance review.
+package report
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+)
+
+// exportControlHeader names the columns of the export-control inventory.
+var exportControlHeader = []string{"product", "component", "version", "purl", "category", "candidate_eccn", "reason"}
+
+// WriteExportControlCSV writes the export-control inventory of the named
+// product as CSV, one row per component and category, for import into
+// trade-compliance tooling and spreadsheets.
+func WriteExportControlCSV(w io.Writer, name string, entries []analysis.ExportEntry) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(exportControlHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		for _, tag := range entry.Tags {
+			row := []string{name, entry.Component.Name, entry.Component.Version, entry.Component.PURL, tag.Category, tag.ECCN, tag.Reason}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
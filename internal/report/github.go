@@ -0,0 +1,85 @@
+// Package report provides GitHub Actions workflow command annotations for
+// analysis results.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// WriteGitHubAnnotations writes a workflow command for every finding and
+// policy violation, so that GitHub Actions shows them as annotations on the
+// SBOM file. Critical and High findings are errors, Medium findings
+// warnings and the rest notices.
+func WriteGitHubAnnotations(w io.Writer, r *Report) error {
+	out := bufio.NewWriter(w)
+
+	for _, result := range r.Results {
+		title := result.AgentName
+		if component := componentLabel(result); component != "" {
+			title += ": " + component
+		}
+		message := fmt.Sprintf("[%s] %s", result.Severity, result.Finding)
+		if remediation := result.Remediation(); remediation != "" {
+			message += "\n" + remediation
+		}
+		writeWorkflowCommand(out, annotationLevel(result.Severity), r.Source, title, message)
+	}
+
+	if r.Policy != nil {
+		for _, violation := range r.Policy.Violations {
+			writeWorkflowCommand(out, "error", r.Source, "Policy gate", violation)
+		}
+	}
+
+	for _, run := range r.Incomplete {
+		writeWorkflowCommand(out, "warning", r.Source, run.Agent+" did not finish", fmt.Sprint(run.Err))
+	}
+
+	return out.Flush()
+}
+
+// annotationLevel returns the workflow command for a finding of the given severity.
+func annotationLevel(severity string) string {
+	switch rank := core.SeverityRank(severity); {
+	case rank >= core.SeverityRank(core.SeverityHigh):
+		return "error"
+	case rank == core.SeverityRank(core.SeverityMedium):
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// writeWorkflowCommand writes a single ::level file=...,title=...::message command.
+func writeWorkflowCommand(w io.Writer, level, file, title, message string) {
+	var properties []string
+	if file != "" {
+		properties = append(properties, "file="+escapeProperty(file))
+	}
+	if title != "" {
+		properties = append(properties, "title="+escapeProperty(title))
+	}
+	fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(properties, ","), escapeData(message))
+}
+
+// dataEscaper and propertyEscaper encode the characters that would end a
+// workflow command message or property early.
+var (
+	dataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// escapeData escapes a workflow command message.
+func escapeData(text string) string {
+	return dataEscaper.Replace(text)
+}
+
+// escapeProperty escapes a workflow command property value.
+func escapeProperty(text string) string {
+	return propertyEscaper.Replace(text)
+}
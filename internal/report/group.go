@@ -0,0 +1,157 @@
+// Package report provides the grouping of findings shared by several
+// components, so that a license used by 40 components is listed once.
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// maxGroupComponents caps the components named in a group's label.
+const maxGroupComponents = 10
+
+// FindingGroup is a finding reported by the same agent, with the same
+// severity, for one or more components.
+type FindingGroup struct {
+	AgentName string
+	Severity  string
+	// Finding is the finding of a single result or, for several results,
+	// the shared finding with the component's name and version replaced by
+	// {component} and {version}
+	Finding string
+	// Results holds the grouped findings, in their original order
+	Results []core.AnalysisResult
+}
+
+// GroupFindings groups findings that differ only in the component they are
+// about, keeping the order in which each group first appears. Findings
+// without a component are never grouped.
+func GroupFindings(results []core.AnalysisResult) []FindingGroup {
+	var groups []FindingGroup
+	index := make(map[string]int)
+
+	for _, result := range results {
+		template := findingTemplate(result)
+		key := result.AgentName + "\x00" + result.Severity + "\x00" + template
+		if idx, exists := index[key]; exists && result.Component != nil {
+			groups[idx].Finding = template
+			groups[idx].Results = append(groups[idx].Results, result)
+			continue
+		}
+
+		if result.Component != nil {
+			index[key] = len(groups)
+		}
+		groups = append(groups, FindingGroup{
+			AgentName: result.AgentName,
+			Severity:  result.Severity,
+			Finding:   result.Finding,
+			Results:   []core.AnalysisResult{result},
+		})
+	}
+	return groups
+}
+
+// UngroupedFindings returns a group of its own for every finding.
+func UngroupedFindings(results []core.AnalysisResult) []FindingGroup {
+	groups := make([]FindingGroup, 0, len(results))
+	for _, result := range results {
+		groups = append(groups, FindingGroup{
+			AgentName: result.AgentName,
+			Severity:  result.Severity,
+			Finding:   result.Finding,
+			Results:   []core.AnalysisResult{result},
+		})
+	}
+	return groups
+}
+
+// Remediation returns the remediation shared by every finding of the group,
+// or an empty string if there is none or the findings are fixed differently.
+func (g FindingGroup) Remediation() string {
+	remediation := g.Results[0].Remediation()
+	for _, result := range g.Results[1:] {
+		if result.Remediation() != remediation {
+			return ""
+		}
+	}
+	return remediation
+}
+
+// Owners returns the teams owning the components of the group, in the order
+// they first appear.
+func (g FindingGroup) Owners() []string {
+	var owners []string
+	for _, result := range g.Results {
+		if result.Owner != "" && !slices.Contains(owners, result.Owner) {
+			owners = append(owners, result.Owner)
+		}
+	}
+	return owners
+}
+
+// ComponentsLabel names the components of the group, e.g. "lib-a 1.0.0,
+// lib-b 2.1.0 and 38 more", together with the remediation of each when the
+// findings are fixed differently.
+func (g FindingGroup) ComponentsLabel() string {
+	return g.componentsLabel(func(result core.AnalysisResult, label string) string { return label })
+}
+
+// componentsLabel is ComponentsLabel with the label of each component,
+// which starts with componentLabel(result), rewritten by format.
+func (g FindingGroup) componentsLabel(format func(result core.AnalysisResult, label string) string) string {
+	shared := g.Remediation() != "" || len(g.Results) == 1
+	labels := make([]string, 0, min(len(g.Results), maxGroupComponents))
+	for _, result := range g.Results[:min(len(g.Results), maxGroupComponents)] {
+		label := componentLabel(result)
+		if remediation := result.Remediation(); !shared && remediation != "" {
+			label += " (" + remediation + ")"
+		}
+		labels = append(labels, format(result, label))
+	}
+
+	text := strings.Join(labels, ", ")
+	if more := len(g.Results) - len(labels); more > 0 {
+		text += fmt.Sprintf(" and %d more", more)
+	}
+	return text
+}
+
+// findingTemplate returns the finding with the first mention of the name
+// and version of its component replaced by {component} and {version}. Later
+// mentions are kept, as they are more likely to be about something else,
+// such as the "v3.0" of "GNU General Public License v3.0".
+func findingTemplate(result core.AnalysisResult) string {
+	text := result.Finding
+	if result.Component == nil {
+		return text
+	}
+	if name := result.Component.Name; name != "" {
+		text = replaceFirst(text, standalone(name, ""), "{component}")
+	}
+	if version := result.Component.Version; version != "" {
+		text = replaceFirst(text, standalone(version, "v?"), "{version}")
+	}
+	return text
+}
+
+// standalone matches word, optionally preceded by prefix, where it is not
+// part of a longer name or version, such as the "3.0" in "GPL-3.0-only". A
+// sentence-ending period does not count as part of the word. The first
+// group captures the match.
+func standalone(word, prefix string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^\w.-])(` + prefix + regexp.QuoteMeta(word) + `)(?:$|[^\w.-]|\.(?:\s|$))`)
+}
+
+// replaceFirst replaces the first group of the first match of re in text.
+func replaceFirst(text string, re *regexp.Regexp, replacement string) string {
+	match := re.FindStringSubmatchIndex(text)
+	if match == nil {
+		return text
+	}
+	return text[:match[2]] + replacement + text[match[3]:]
+}
@@ -0,0 +1,168 @@
+// Package report provides a JUnit XML rendering of analysis results, as
+// understood by the test report views of GitLab, Jenkins and Azure DevOps.
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one agent.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single finding, or a passing check.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+// junitProblem describes why a test case failed or errored.
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// maxTestCaseName caps test case names derived from finding text.
+const maxTestCaseName = 120
+
+// WriteJUnit writes the report as JUnit XML. Each agent is a test suite in
+// which every finding is a failed test case, typed by its severity; an agent
+// without findings has a single passing test case and an agent that did not
+// finish an errored one. Policy violations form a "Policy Gate" suite.
+func WriteJUnit(w io.Writer, r *Report) error {
+	root := junitTestSuites{Name: "SBOM Sentinel: " + r.SBOM.Name}
+
+	// Suites follow the order in which agents ran
+	suites := make(map[string]*junitTestSuite)
+	var order []string
+	suite := func(name string) *junitTestSuite {
+		if s, ok := suites[name]; ok {
+			return s
+		}
+		suites[name] = &junitTestSuite{Name: name}
+		order = append(order, name)
+		return suites[name]
+	}
+	for _, agent := range r.Agents {
+		suite(agent)
+	}
+
+	for _, result := range r.Results {
+		text := result.Finding
+		if remediation := result.Remediation(); remediation != "" {
+			text += "\n" + remediation
+		}
+
+		s := suite(result.AgentName)
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      testCaseName(result),
+			ClassName: result.AgentName,
+			File:      r.Source,
+			Failure:   &junitProblem{Message: result.Finding, Type: result.Severity, Text: text},
+		})
+	}
+
+	for _, run := range r.Incomplete {
+		s := suite(run.Agent)
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      run.Agent + " did not finish",
+			ClassName: run.Agent,
+			File:      r.Source,
+			Error:     &junitProblem{Message: fmt.Sprint(run.Err), Type: run.Status, Text: fmt.Sprint(run.Err)},
+		})
+	}
+
+	if r.Policy != nil {
+		s := suite("Policy Gate")
+		for _, violation := range r.Policy.Violations {
+			s.Cases = append(s.Cases, junitTestCase{
+				Name:      truncate(violation, maxTestCaseName),
+				ClassName: "Policy Gate",
+				File:      r.Source,
+				Failure:   &junitProblem{Message: violation, Type: "policy", Text: violation},
+			})
+		}
+		if len(r.Policy.Violations) == 0 {
+			s.Cases = append(s.Cases, junitTestCase{Name: "policies passed", ClassName: "Policy Gate", File: r.Source})
+		}
+	}
+
+	for _, name := range order {
+		s := suites[name]
+		if len(s.Cases) == 0 {
+			s.Cases = append(s.Cases, junitTestCase{Name: "no findings", ClassName: name, File: r.Source})
+		}
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				s.Failures++
+			}
+			if c.Error != nil {
+				s.Errors++
+			}
+		}
+		s.Tests = len(s.Cases)
+
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+		root.Errors += s.Errors
+		root.Suites = append(root.Suites, *s)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// testCaseName names the test case of a finding after its component and
+// advisory, falling back to the finding text.
+func testCaseName(result core.AnalysisResult) string {
+	component := componentLabel(result)
+	switch {
+	case component == "":
+		return truncate(result.Finding, maxTestCaseName)
+	case result.VulnerabilityID != "":
+		return component + ": " + result.VulnerabilityID
+	default:
+		return component
+	}
+}
+
+// truncate shortens text to at most limit runes, marking the cut with an ellipsis.
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,255 @@
+// Package report provides a Markdown rendering of analysis results, as used
+// for GitHub job summaries.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ignore"
+)
+
+// MaxMarkdownFindings caps the findings listed in Markdown, keeping the
+// output well inside GitHub's 1 MiB job summary limit.
+const MaxMarkdownFindings = 200
+
+// MaxMarkdownAssemblyRows caps the components listed in the assembly tree
+// of the Markdown report.
+const MaxMarkdownAssemblyRows = 200
+
+// WriteMarkdown writes the report as GitHub-flavored Markdown: the verdict,
+// a count of findings per severity and a table of the findings, most severe
+// first. SBOMs with nested components also get the tree of their assemblies.
+func WriteMarkdown(w io.Writer, r *Report) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "## 🛡️ SBOM Sentinel: %s\n\n", escapeMarkdown(r.SBOM.Name))
+
+	fmt.Fprintf(out, "%s — analyzed `%s` with %d components\n\n", verdict(r), r.Source, len(r.SBOM.Components))
+
+	if len(r.Results) == 0 {
+		fmt.Fprintf(out, "No issues detected.\n")
+	} else {
+		counts := r.SeverityCounts()
+		fmt.Fprintf(out, "| Severity | Findings |\n|---|---|\n")
+		for _, severity := range []string{core.SeverityCritical, core.SeverityHigh, core.SeverityMedium, core.SeverityLow} {
+			if counts[severity] > 0 {
+				fmt.Fprintf(out, "| %s %s | %d |\n", severityIcon(severity), severity, counts[severity])
+			}
+		}
+
+		risk := r.Risk()
+		fmt.Fprintf(out, "\n**Risk score:** %d/100 (%s)\n", risk.Score, risk.Level)
+		if baselined := r.Baselined(); len(baselined) > 0 {
+			fmt.Fprintf(out, "\n**Baseline:** %d findings were accepted in the baseline of %s and do not fail the run\n",
+				len(baselined), r.Baseline.CreatedAt.Format("2006-01-02"))
+		}
+
+		fmt.Fprintf(out, "\n### Findings\n\n")
+		writeFindingsTable(out, r, r.Results)
+	}
+
+	if len(r.Ignored) > 0 {
+		fmt.Fprintf(out, "\n### 🙈 Ignored Findings\n\n")
+		writeIgnoredTable(out, r.Ignored)
+	}
+
+	if r.Policy != nil {
+		fmt.Fprintf(out, "\n### Policy Gate\n\n")
+		if r.Policy.Passed() {
+			fmt.Fprintf(out, "✅ Passed\n")
+		}
+		for _, violation := range r.Policy.Violations {
+			fmt.Fprintf(out, "- ❌ %s\n", escapeMarkdown(violation))
+		}
+	}
+
+	if len(r.Incomplete) > 0 {
+		fmt.Fprintf(out, "\n### ⚠️ Incomplete Analysis\n\n")
+		for _, run := range r.Incomplete {
+			fmt.Fprintf(out, "- %s (%s): %s\n", escapeMarkdown(run.Agent), run.Status, escapeMarkdown(fmt.Sprint(run.Err)))
+		}
+	}
+
+	if core.HasAssemblies(r.SBOM.Components) {
+		fmt.Fprintf(out, "\n### 📦 Assemblies\n\n")
+		writeAssemblyTree(out, r)
+	}
+
+	return out.Flush()
+}
+
+// verdict summarizes in bold whether the report passed and, if not, why.
+func verdict(r *Report) string {
+	if r.Passed() {
+		return "**✅ Passed**"
+	}
+
+	var reasons []string
+	if failing := r.Failing(); len(failing) > 0 {
+		if r.Baseline != nil {
+			reasons = append(reasons, fmt.Sprintf("%d new findings at or above %s", len(failing), r.FailOn))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("%d findings at or above %s", len(failing), r.FailOn))
+		}
+	}
+	if r.Policy != nil && !r.Policy.Passed() {
+		reasons = append(reasons, fmt.Sprintf("%d policy violations", len(r.Policy.Violations)))
+	}
+	return "**❌ Failed:** " + strings.Join(reasons, ", ")
+}
+
+// writeFindingsTable writes findings as a Markdown table, most severe first,
+// listing at most MaxMarkdownFindings rows. Unless r.NoGroup is set,
+// findings shared by several components take a single row.
+func writeFindingsTable(out io.Writer, r *Report, findings []core.AnalysisResult) {
+	results := make([]core.AnalysisResult, len(findings))
+	copy(results, findings)
+	sort.SliceStable(results, func(i, j int) bool {
+		return core.SeverityRank(results[i].Severity) > core.SeverityRank(results[j].Severity)
+	})
+
+	groups := GroupFindings(results)
+	if r.NoGroup {
+		groups = UngroupedFindings(results)
+	}
+
+	links := componentLinks(r.SBOM)
+	fmt.Fprintf(out, "| Severity | Agent | Component | Finding |\n|---|---|---|---|\n")
+	listed := 0
+	for i, group := range groups {
+		if i == MaxMarkdownFindings {
+			fmt.Fprintf(out, "\n… and %d more findings\n", len(results)-listed)
+			break
+		}
+		listed += len(group.Results)
+
+		finding := escapeMarkdown(group.Finding)
+		if remediation := group.Remediation(); remediation != "" {
+			finding += "<br>🔧 " + escapeMarkdown(remediation)
+		}
+		// Components link to their source when the SBOM gives one
+		component := group.componentsLabel(func(result core.AnalysisResult, label string) string {
+			if result.Component == nil {
+				return escapeMarkdown(label)
+			}
+			link := links[componentLinkKey(result.Component.Name, result.Component.Version)]
+			if link == "" {
+				return escapeMarkdown(label)
+			}
+			name := componentLabel(result)
+			return markdownLink(name, link) + escapeMarkdown(label[len(name):])
+		})
+		if len(group.Results) > 1 {
+			component = fmt.Sprintf("%d components: %s", len(group.Results), component)
+		}
+		fmt.Fprintf(out, "| %s %s | %s | %s | %s |\n",
+			severityIcon(group.Severity), escapeMarkdown(group.Severity), escapeMarkdown(group.AgentName),
+			component, finding)
+	}
+}
+
+// writeAssemblyTree writes the assemblies of the SBOM, the components with
+// nested components, as nested lists naming the findings of each component.
+// It lists at most MaxMarkdownAssemblyRows components.
+func writeAssemblyTree(out io.Writer, r *Report) {
+	findings := make(map[string]int)
+	for _, result := range r.Results {
+		if result.Component != nil {
+			findings[componentLinkKey(result.Component.Name, result.Component.Version)]++
+		}
+	}
+
+	listed, truncated := 0, false
+	var write func(node core.ComponentNode, depth int)
+	write = func(node core.ComponentNode, depth int) {
+		if listed == MaxMarkdownAssemblyRows {
+			truncated = true
+			return
+		}
+		listed++
+
+		label := escapeMarkdown(strings.TrimSpace(node.Name + " " + node.Version))
+		if link := node.SourceURL(); link != "" {
+			label = markdownLink(strings.TrimSpace(node.Name+" "+node.Version), link)
+		}
+		if len(node.Children) > 0 {
+			label = "**" + label + "**"
+		}
+		if node.Type != "" {
+			label += " (" + escapeMarkdown(node.Type) + ")"
+		}
+		if count := findings[componentLinkKey(node.Name, node.Version)]; count > 0 {
+			label += fmt.Sprintf(" — %d findings", count)
+		}
+		fmt.Fprintf(out, "%s- %s\n", strings.Repeat("  ", depth), label)
+		for _, child := range node.Children {
+			write(child, depth+1)
+		}
+	}
+
+	for _, node := range core.ComponentTree(r.SBOM.Components) {
+		if len(node.Children) > 0 {
+			write(node, 0)
+		}
+	}
+	if truncated {
+		fmt.Fprintf(out, "\n… the tree is cut at %d components\n", MaxMarkdownAssemblyRows)
+	}
+}
+
+// writeIgnoredTable writes findings excluded by the ignore file as a Markdown
+// table with the reason of each, listing at most MaxMarkdownFindings.
+func writeIgnoredTable(out io.Writer, ignored []ignore.Ignored) {
+	fmt.Fprintf(out, "| Severity | Component | Finding | Ignored by | Reason |\n|---|---|---|---|---|\n")
+	for i, finding := range ignored {
+		if i == MaxMarkdownFindings {
+			fmt.Fprintf(out, "\n… and %d more ignored findings\n", len(ignored)-MaxMarkdownFindings)
+			break
+		}
+		fmt.Fprintf(out, "| %s %s | %s | %s | `%s` | %s |\n",
+			severityIcon(finding.Severity), escapeMarkdown(finding.Severity), escapeMarkdown(componentLabel(finding.AnalysisResult)),
+			escapeMarkdown(finding.Finding), escapeMarkdown(finding.Entry.String()), escapeMarkdown(finding.Reason))
+	}
+}
+
+// componentLinks maps the name and version of the components of sbom to
+// where they link in Markdown, their source repository or website.
+func componentLinks(sbom core.SBOM) map[string]string {
+	links := make(map[string]string)
+	for _, component := range sbom.Components {
+		key := componentLinkKey(component.Name, component.Version)
+		if links[key] == "" {
+			links[key] = component.SourceURL()
+		}
+	}
+	return links
+}
+
+// componentLinkKey identifies a component by name and version, as findings
+// name it.
+func componentLinkKey(name, version string) string {
+	return name + "@" + version
+}
+
+// markdownLinkEscaper escapes the characters of a URL that end a Markdown
+// link or table cell.
+var markdownLinkEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "|", "%7C", "<", "%3C", ">", "%3E")
+
+// markdownLink links text to url, both made safe for a Markdown table cell.
+func markdownLink(text, url string) string {
+	text = strings.NewReplacer("[", `\[`, "]", `\]`).Replace(escapeMarkdown(text))
+	return "[" + text + "](" + markdownLinkEscaper.Replace(url) + ")"
+}
+
+// markdownEscaper escapes text for use in a Markdown table cell.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "<", "&lt;", ">", "&gt;")
+
+// escapeMarkdown makes text safe to place inside a Markdown table cell.
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
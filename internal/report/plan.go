@@ -0,0 +1,133 @@
+// Package report provides upgrade plans, which group vulnerability findings
+// by component into prioritized upgrades.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// Upgrade is a step of an upgrade plan: moving one component to the version
+// that resolves its known vulnerabilities.
+type Upgrade struct {
+	Component core.ComponentRef `json:"component"`
+	// To is the recommended version, or empty if no version is known to fix
+	// every finding
+	To string `json:"to,omitempty"`
+	// Severity is the highest severity among the findings
+	Severity string `json:"severity"`
+	// Findings are the vulnerability findings about the component
+	Findings []core.AnalysisResult `json:"findings"`
+}
+
+// Fixable reports whether a version fixing every finding is known.
+func (u Upgrade) Fixable() bool {
+	return u.To != ""
+}
+
+// VulnerabilityIDs lists the advisories the upgrade resolves.
+func (u Upgrade) VulnerabilityIDs() []string {
+	ids := make([]string, 0, len(u.Findings))
+	for _, finding := range u.Findings {
+		ids = append(ids, finding.VulnerabilityID)
+	}
+	return ids
+}
+
+// NewUpgradePlan groups the vulnerability findings among results by
+// component. Fixable upgrades come first, most urgent first: by highest
+// severity, then by number of findings, then by component name.
+func NewUpgradePlan(results []core.AnalysisResult) []Upgrade {
+	var plan []Upgrade
+	index := make(map[string]int)
+	for _, result := range results {
+		if result.Component == nil || result.VulnerabilityID == "" {
+			continue
+		}
+
+		key := result.Component.Name + "\x00" + result.Component.Version
+		i, ok := index[key]
+		if !ok {
+			i = len(plan)
+			index[key] = i
+			plan = append(plan, Upgrade{Component: *result.Component, To: result.UpgradeTo})
+		}
+
+		upgrade := &plan[i]
+		upgrade.Findings = append(upgrade.Findings, result)
+		if core.SeverityRank(result.Severity) > core.SeverityRank(upgrade.Severity) {
+			upgrade.Severity = result.Severity
+		}
+		// Findings about one component share the recommendation, but a
+		// finding without one means no version is known to fix them all
+		if result.UpgradeTo == "" {
+			upgrade.To = ""
+		}
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		a, b := plan[i], plan[j]
+		if a.Fixable() != b.Fixable() {
+			return a.Fixable()
+		}
+		if rankA, rankB := core.SeverityRank(a.Severity), core.SeverityRank(b.Severity); rankA != rankB {
+			return rankA > rankB
+		}
+		if len(a.Findings) != len(b.Findings) {
+			return len(a.Findings) > len(b.Findings)
+		}
+		return a.Component.Name < b.Component.Name
+	})
+	return plan
+}
+
+// WriteUpgradePlanMarkdown writes an upgrade plan for the named SBOM as a
+// Markdown checklist, followed by the components without a known fix.
+func WriteUpgradePlanMarkdown(w io.Writer, name string, plan []Upgrade) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "## 🔧 Upgrade Plan: %s\n\n", escapeMarkdown(name))
+	if len(plan) == 0 {
+		fmt.Fprintf(out, "No known vulnerabilities.\n")
+		return out.Flush()
+	}
+
+	unfixable := 0
+	for _, upgrade := range plan {
+		if !upgrade.Fixable() {
+			unfixable++
+			continue
+		}
+		fmt.Fprintf(out, "- [ ] %s **%s** %s → **%s** — resolves %s\n",
+			severityIcon(upgrade.Severity), escapeMarkdown(upgrade.Component.Name), escapeMarkdown(upgrade.Component.Version),
+			escapeMarkdown(upgrade.To), describeFindings(upgrade))
+	}
+
+	if unfixable > 0 {
+		fmt.Fprintf(out, "\n### No Known Fix\n\n")
+		for _, upgrade := range plan {
+			if !upgrade.Fixable() {
+				fmt.Fprintf(out, "- %s **%s** %s — %s\n",
+					severityIcon(upgrade.Severity), escapeMarkdown(upgrade.Component.Name), escapeMarkdown(upgrade.Component.Version),
+					describeFindings(upgrade))
+			}
+		}
+	}
+
+	return out.Flush()
+}
+
+// describeFindings describes the findings of an upgrade, such as
+// "2 findings (GHSA-1, GHSA-2)".
+func describeFindings(upgrade Upgrade) string {
+	noun := "findings"
+	if len(upgrade.Findings) == 1 {
+		noun = "finding"
+	}
+	return fmt.Sprintf("%d %s (%s)", len(upgrade.Findings), noun, escapeMarkdown(strings.Join(upgrade.VulnerabilityIDs(), ", ")))
+}
@@ -0,0 +1,139 @@
+// Package report provides renderings of analysis results for CI systems and
+// code review, together with the pass/fail decision of a CI run.
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ignore"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+)
+
+// FailOnNone is the --fail-on threshold that never fails on findings.
+const FailOnNone = "none"
+
+// Report is the outcome of analyzing one SBOM file in CI.
+type Report struct {
+	// Source is the path of the analyzed SBOM file
+	Source string
+	// SBOM is the analyzed SBOM
+	SBOM core.SBOM
+	// Agents names the agents that ran
+	Agents []string
+	// Results holds the findings of every agent
+	Results []core.AnalysisResult
+	// Ignored holds the findings excluded by the ignore file
+	Ignored []ignore.Ignored
+	// Incomplete lists the agents that failed or timed out
+	Incomplete []analysis.AgentRun
+	// Policy is the policy gate decision, or nil if no policies were given
+	Policy *policy.Decision
+	// FailOn is the lowest severity that fails the run, or FailOnNone
+	FailOn string
+	// Baseline holds the accepted findings of a legacy project, which do not
+	// fail the run; nil if there is none
+	Baseline *Baseline
+	// NoGroup lists every finding on its own row instead of grouping the
+	// findings shared by several components
+	NoGroup bool
+}
+
+// ParseFailOn parses a --fail-on threshold: a severity (ignoring case) or
+// "none". It returns the canonical severity label or FailOnNone.
+func ParseFailOn(value string) (string, error) {
+	if strings.EqualFold(value, FailOnNone) {
+		return FailOnNone, nil
+	}
+	for _, severity := range []string{core.SeverityCritical, core.SeverityHigh, core.SeverityMedium, core.SeverityLow} {
+		if strings.EqualFold(value, severity) {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("invalid severity threshold %q (expected critical, high, medium, low or none)", value)
+}
+
+// Failing returns the findings at or above the FailOn severity that the
+// baseline, if any, does not contain.
+func (r *Report) Failing() []core.AnalysisResult {
+	var failing []core.AnalysisResult
+	if r.FailOn == FailOnNone || r.FailOn == "" {
+		return failing
+	}
+
+	threshold := core.SeverityRank(r.FailOn)
+	for _, result := range r.Results {
+		if core.SeverityRank(result.Severity) >= threshold && !r.inBaseline(result) {
+			failing = append(failing, result)
+		}
+	}
+	return failing
+}
+
+// Baselined returns the findings the baseline contains.
+func (r *Report) Baselined() []core.AnalysisResult {
+	var baselined []core.AnalysisResult
+	for _, result := range r.Results {
+		if r.inBaseline(result) {
+			baselined = append(baselined, result)
+		}
+	}
+	return baselined
+}
+
+// inBaseline reports whether the baseline, if any, contains result.
+func (r *Report) inBaseline(result core.AnalysisResult) bool {
+	return r.Baseline != nil && r.Baseline.Contains(result)
+}
+
+// Passed reports whether the run passes: no finding reaches the FailOn
+// severity and the policy gate, if any, passed.
+func (r *Report) Passed() bool {
+	if r.Policy != nil && !r.Policy.Passed() {
+		return false
+	}
+	return len(r.Failing()) == 0
+}
+
+// Risk returns the composite risk score of the findings.
+func (r *Report) Risk() analysis.RiskScore {
+	return analysis.ScoreRisk(r.Results)
+}
+
+// SeverityCounts returns the number of findings of each severity.
+func (r *Report) SeverityCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, result := range r.Results {
+		counts[result.Severity]++
+	}
+	return counts
+}
+
+// componentLabel describes the component a finding is about, or "" if none.
+func componentLabel(result core.AnalysisResult) string {
+	if result.Component == nil {
+		return ""
+	}
+	if result.Component.Version == "" {
+		return result.Component.Name
+	}
+	return result.Component.Name + " " + result.Component.Version
+}
+
+// severityIcon returns an emoji for the given severity level.
+func severityIcon(severity string) string {
+	switch core.SeverityRank(severity) {
+	case 4:
+		return "🚨"
+	case 3:
+		return "🔴"
+	case 2:
+		return "🟡"
+	case 1:
+		return "🟢"
+	default:
+		return "⚠️"
+	}
+}
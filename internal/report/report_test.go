@@ -0,0 +1,533 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ignore"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *Report {
+	return &Report{
+		Source: "sbom.json",
+		SBOM:   core.SBOM{Name: "payments-api", Components: make([]core.Component, 3)},
+		Results: []core.AnalysisResult{
+			{AgentName: "License Agent", Severity: "Medium", Finding: "Component 'x' uses LGPL-2.1"},
+			{
+				AgentName:       "Vulnerability Scanner Agent",
+				Severity:        "Critical",
+				Finding:         "CVE-2021-44228 in log4j-core | remote code execution\nUpgrade to 2.17.1",
+				Component:       &core.ComponentRef{Name: "log4j-core", Version: "2.14.1"},
+				VulnerabilityID: "CVE-2021-44228",
+			},
+			{AgentName: "SBOM Quality Agent", Severity: "Low", Finding: "Missing supplier"},
+		},
+		FailOn: core.SeverityHigh,
+	}
+}
+
+func TestParseFailOn(t *testing.T) {
+	for value, expected := range map[string]string{"critical": "Critical", "HIGH": "High", "Low": "Low", "none": FailOnNone} {
+		severity, err := ParseFailOn(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, severity)
+	}
+
+	_, err := ParseFailOn("severe")
+	assert.Error(t, err)
+}
+
+func TestReport_Passed(t *testing.T) {
+	r := testReport()
+	assert.False(t, r.Passed())
+	assert.Len(t, r.Failing(), 1)
+
+	r.FailOn = core.SeverityMedium
+	assert.Len(t, r.Failing(), 2)
+
+	r.FailOn = FailOnNone
+	assert.True(t, r.Passed())
+
+	// Policy violations fail the run whatever the threshold
+	r.Policy = &policy.Decision{Violations: []string{"log4j is banned"}}
+	assert.False(t, r.Passed())
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	r := testReport()
+	r.Policy = &policy.Decision{Violations: []string{"log4j is banned"}}
+	r.Incomplete = []analysis.AgentRun{{Agent: "Vulnerability Scanner Agent", Status: analysis.AgentStatusTimeout, Err: errors.New("deadline exceeded")}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	markdown := buf.String()
+
+	assert.Contains(t, markdown, "## 🛡️ SBOM Sentinel: payments-api")
+	assert.Contains(t, markdown, "**❌ Failed:** 1 findings at or above High, 1 policy violations")
+	assert.Contains(t, markdown, "| 🚨 Critical | 1 |")
+	assert.Contains(t, markdown, "**Risk score:** 18/100 (Low)")
+	assert.Contains(t, markdown, "- ❌ log4j is banned")
+	assert.Contains(t, markdown, "- Vulnerability Scanner Agent (timeout): deadline exceeded")
+
+	// Findings are listed most severe first, with table syntax escaped
+	critical := strings.Index(markdown, "| 🚨 Critical | Vulnerability Scanner Agent | log4j-core 2.14.1 | CVE-2021-44228 in log4j-core \\| remote code execution<br>Upgrade to 2.17.1 |")
+	medium := strings.Index(markdown, "| 🟡 Medium | License Agent |")
+	require.NotEqual(t, -1, critical)
+	assert.Less(t, critical, medium)
+}
+
+func TestWriteMarkdown_Assemblies(t *testing.T) {
+	r := &Report{Source: "router.cdx.json", FailOn: core.SeverityHigh, SBOM: core.SBOM{Name: "router", Components: []core.Component{
+		{Name: "router-firmware", Version: "2.1", Type: "firmware"},
+		{Name: "busybox", Version: "1.36.1", Type: "library", Parent: "router-firmware@2.1"},
+		{Name: "openssl", Version: "3.0.13", Type: "library", Parent: "router-firmware@2.1"},
+		{Name: "libcrypto", Version: "3.0.13", Parent: "openssl@3.0.13"},
+		{Name: "zlib", Version: "1.3"},
+	}}, Results: []core.AnalysisResult{
+		{AgentName: "Vulnerability Scanner Agent", Severity: core.SeverityMedium, Finding: "CVE-2023-42363", Component: &core.ComponentRef{Name: "busybox", Version: "1.36.1"}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Contains(t, buf.String(), "### 📦 Assemblies\n\n"+
+		"- **router-firmware 2.1** (firmware)\n"+
+		"  - busybox 1.36.1 (library) — 1 findings\n"+
+		"  - **openssl 3.0.13** (library)\n"+
+		"    - libcrypto 3.0.13\n")
+	// Top-level components outside assemblies are not listed
+	assert.NotContains(t, buf.String(), "zlib")
+
+	// SBOMs without nested components have no tree
+	buf.Reset()
+	require.NoError(t, WriteMarkdown(&buf, testReport()))
+	assert.NotContains(t, buf.String(), "Assemblies")
+}
+
+func TestWriteMarkdown_Passed(t *testing.T) {
+	r := &Report{Source: "sbom.json", SBOM: core.SBOM{Name: "clean"}, FailOn: core.SeverityHigh}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Contains(t, buf.String(), "**✅ Passed**")
+	assert.Contains(t, buf.String(), "No issues detected.")
+}
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	r := testReport()
+	r.Source = "sboms/app,v1.json"
+	r.Policy = &policy.Decision{Violations: []string{"log4j is banned"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteGitHubAnnotations(&buf, r))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, "::warning file=sboms/app%2Cv1.json,title=License Agent::[Medium] Component 'x' uses LGPL-2.1", lines[0])
+	assert.Equal(t, "::error file=sboms/app%2Cv1.json,title=Vulnerability Scanner Agent%3A log4j-core 2.14.1::[Critical] CVE-2021-44228 in log4j-core | remote code execution%0AUpgrade to 2.17.1", lines[1])
+	assert.Equal(t, "::notice file=sboms/app%2Cv1.json,title=SBOM Quality Agent::[Low] Missing supplier", lines[2])
+	assert.Equal(t, "::error file=sboms/app%2Cv1.json,title=Policy gate::log4j is banned", lines[3])
+}
+
+func TestWriteJUnit(t *testing.T) {
+	r := testReport()
+	r.Agents = []string{"License Agent", "Vulnerability Scanner Agent", "SBOM Quality Agent", "Dependency Health Agent"}
+	r.Incomplete = []analysis.AgentRun{{Agent: "Dependency Health Agent", Status: analysis.AgentStatusFailed, Err: errors.New("connection refused")}}
+	r.Policy = &policy.Decision{}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, r))
+
+	var parsed junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	assert.Equal(t, 5, parsed.Tests)
+	assert.Equal(t, 3, parsed.Failures)
+	assert.Equal(t, 1, parsed.Errors)
+
+	// Suites follow the agents, with the policy gate last
+	var names []string
+	for _, suite := range parsed.Suites {
+		names = append(names, suite.Name)
+	}
+	assert.Equal(t, []string{"License Agent", "Vulnerability Scanner Agent", "SBOM Quality Agent", "Dependency Health Agent", "Policy Gate"}, names)
+
+	vulnerability := parsed.Suites[1].Cases[0]
+	assert.Equal(t, "log4j-core 2.14.1: CVE-2021-44228", vulnerability.Name)
+	assert.Equal(t, "sbom.json", vulnerability.File)
+	require.NotNil(t, vulnerability.Failure)
+	assert.Equal(t, "Critical", vulnerability.Failure.Type)
+
+	incomplete := parsed.Suites[3].Cases[0]
+	require.NotNil(t, incomplete.Error)
+	assert.Equal(t, "connection refused", incomplete.Error.Message)
+
+	gate := parsed.Suites[4].Cases[0]
+	assert.Equal(t, "policies passed", gate.Name)
+	assert.Nil(t, gate.Failure)
+}
+
+func TestWriteJUnit_NoFindings(t *testing.T) {
+	r := &Report{Source: "sbom.json", SBOM: core.SBOM{Name: "clean"}, Agents: []string{"License Agent"}, FailOn: core.SeverityHigh}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, r))
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var parsed junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	require.Len(t, parsed.Suites, 1)
+	assert.Equal(t, 1, parsed.Tests)
+	assert.Equal(t, 0, parsed.Failures)
+	assert.Equal(t, "no findings", parsed.Suites[0].Cases[0].Name)
+}
+
+func TestNewDiff(t *testing.T) {
+	baseline := core.SBOM{Components: []core.Component{
+		{Name: "log4j-core", Version: "2.14.1"},
+		{Name: "left-pad", Version: "1.3.0"},
+		{Name: "lodash", Version: "4.17.21"},
+	}}
+	current := core.SBOM{Components: []core.Component{
+		{Name: "log4j-core", Version: "2.17.1"},
+		{Name: "lodash", Version: "4.17.21"},
+		{Name: "axios", Version: "1.6.0"},
+	}}
+	log4shell := core.AnalysisResult{AgentName: "Vulnerability Scanner Agent", Severity: "Critical", Finding: "CVE-2021-44228 in log4j-core"}
+	supplier := core.AnalysisResult{AgentName: "SBOM Quality Agent", Severity: "Low", Finding: "Missing supplier"}
+	axios := core.AnalysisResult{AgentName: "Vulnerability Scanner Agent", Severity: "Medium", Finding: "CVE-2023-45857 in axios"}
+
+	diff := NewDiff(baseline, []core.AnalysisResult{log4shell, supplier}, current, []core.AnalysisResult{supplier, axios})
+
+	assert.Equal(t, []core.AnalysisResult{axios}, diff.NewFindings)
+	assert.Equal(t, []core.AnalysisResult{log4shell}, diff.ResolvedFindings)
+	assert.Equal(t, []core.Component{{Name: "axios", Version: "1.6.0"}}, diff.AddedComponents)
+	assert.Equal(t, []core.Component{{Name: "left-pad", Version: "1.3.0"}}, diff.RemovedComponents)
+	assert.Equal(t, []ComponentChange{{Name: "log4j-core", From: "2.14.1", To: "2.17.1"}}, diff.ChangedComponents)
+	assert.False(t, diff.Empty())
+
+	assert.True(t, NewDiff(current, []core.AnalysisResult{axios}, current, []core.AnalysisResult{axios}).Empty())
+}
+
+func TestWriteComment(t *testing.T) {
+	r := testReport()
+	baseline := core.SBOM{Components: []core.Component{{Name: "log4j-core", Version: "2.12.0"}}}
+	current := core.SBOM{Components: []core.Component{{Name: "log4j-core", Version: "2.14.1"}}}
+	resolved := core.AnalysisResult{AgentName: "License Agent", Severity: "High", Finding: "Component 'y' uses AGPL-3.0"}
+	diff := NewDiff(baseline, []core.AnalysisResult{r.Results[0], resolved}, current, r.Results)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteComment(&buf, r, diff))
+	comment := buf.String()
+
+	assert.Contains(t, comment, "### 🛡️ SBOM Sentinel: payments-api")
+	assert.Contains(t, comment, "**❌ Failed:** 1 findings at or above High — 3 findings in `sbom.json` (2 new, 1 resolved)")
+	assert.Contains(t, comment, "- ~~🔴 Component 'y' uses AGPL-3.0~~ (License Agent)")
+	assert.Contains(t, comment, "| log4j-core | 2.12.0 → 2.14.1 |")
+	assert.Contains(t, comment, "<summary>All findings (3)</summary>")
+
+	// New findings come before the collapsed list of all findings
+	newFindings := comment[strings.Index(comment, "#### 🆕 New Findings"):strings.Index(comment, "<details>")]
+	assert.Contains(t, newFindings, "| 🚨 Critical | Vulnerability Scanner Agent |")
+	assert.NotContains(t, newFindings, "| 🟡 Medium | License Agent |")
+}
+
+func TestWriteComment_NoBaseline(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteComment(&buf, testReport(), nil))
+	assert.NotContains(t, buf.String(), "<details>")
+	assert.Contains(t, buf.String(), "| 🟡 Medium | License Agent |")
+}
+
+func TestWriteMarkdown_Remediation(t *testing.T) {
+	r := testReport()
+	r.Results[1].FixedVersion = "2.15.0"
+	r.Results[1].UpgradeTo = "2.17.1"
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Contains(t, buf.String(), "Upgrade to 2.17.1<br>🔧 Upgrade to 2.17.1 (this vulnerability is fixed in 2.15.0) |")
+}
+
+func TestWriteMarkdown_Ignored(t *testing.T) {
+	r := testReport()
+	r.Ignored = []ignore.Ignored{{
+		AnalysisResult: core.AnalysisResult{Severity: "High", Finding: "left-pad is unlicensed", Component: &core.ComponentRef{Name: "left-pad", Version: "1.3.0"}},
+		Entry:          ignore.Entry{Kind: ignore.KindComponent, Value: "left-pad"},
+		Reason:         "Vendored | patched",
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Contains(t, buf.String(), "### 🙈 Ignored Findings")
+	assert.Contains(t, buf.String(), "| 🔴 High | left-pad 1.3.0 | left-pad is unlicensed | `component:left-pad` | Vendored \\| patched |")
+
+	buf.Reset()
+	require.NoError(t, WriteComment(&buf, r, nil))
+	assert.Contains(t, buf.String(), "<summary>🙈 Ignored findings (1)</summary>")
+}
+
+func TestBaseline(t *testing.T) {
+	accepted := []core.AnalysisResult{
+		gplFinding("lib-a", "1.0.0"),
+		{AgentName: "Vulnerability Scanner Agent", Severity: "Critical", Finding: "log4j-core 2.14.1 is vulnerable", VulnerabilityID: "GHSA-jfh8", Component: &core.ComponentRef{Name: "log4j-core", Version: "2.14.1"}},
+	}
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBaseline(&buf, NewBaseline("sbom.json", accepted, created)))
+	require.True(t, IsBaseline(buf.Bytes()))
+	baseline, err := ParseBaseline(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, created, baseline.CreatedAt)
+	require.Len(t, baseline.Findings, 2)
+
+	// Findings are still accepted after their component is upgraded, but not
+	// when another component has them
+	upgraded := accepted[1]
+	upgraded.Component = &core.ComponentRef{Name: "log4j-core", Version: "2.15.0"}
+	assert.True(t, baseline.Contains(gplFinding("lib-a", "1.1.0")))
+	assert.True(t, baseline.Contains(upgraded))
+	assert.False(t, baseline.Contains(gplFinding("lib-b", "1.0.0")))
+
+	r := &Report{
+		Results:  []core.AnalysisResult{gplFinding("lib-a", "1.1.0"), gplFinding("lib-b", "1.0.0")},
+		FailOn:   core.SeverityHigh,
+		Baseline: baseline,
+	}
+	require.Len(t, r.Failing(), 1)
+	assert.Equal(t, "lib-b", r.Failing()[0].Component.Name)
+	assert.Len(t, r.Baselined(), 1)
+
+	buf.Reset()
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Contains(t, buf.String(), "**❌ Failed:** 1 new findings at or above High")
+	assert.Contains(t, buf.String(), "**Baseline:** 1 findings were accepted in the baseline of 2026-03-01")
+}
+
+func TestParseBaseline_NotBaseline(t *testing.T) {
+	sbom := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}`)
+	assert.False(t, IsBaseline(sbom))
+	_, err := ParseBaseline(sbom)
+	assert.Error(t, err)
+
+	_, err = ParseBaseline([]byte(`{"format": "sbom-sentinel-baseline", "version": 2}`))
+	assert.ErrorContains(t, err, "unsupported findings baseline version 2")
+}
+
+// gplFinding returns a License Agent finding for a GPL component.
+func gplFinding(name, version string) core.AnalysisResult {
+	return core.AnalysisResult{
+		AgentName: "License Agent",
+		Severity:  "High",
+		Finding:   "Component '" + name + "' (v" + version + ") uses high-risk copyleft license 'GPL-3.0-only' (GNU General Public License v3.0 only).",
+		Component: &core.ComponentRef{Name: name, Version: version},
+	}
+}
+
+func TestGroupFindings(t *testing.T) {
+	results := []core.AnalysisResult{
+		gplFinding("lib-a", "1.0.0"),
+		{AgentName: "SBOM Quality Agent", Severity: "Low", Finding: "Missing supplier"},
+		gplFinding("lib-b", "3.0"),
+		{AgentName: "SBOM Quality Agent", Severity: "Low", Finding: "Missing supplier"},
+		gplFinding("lib-c", "2.1.0"),
+	}
+	results[4].Severity = "Medium"
+
+	groups := GroupFindings(results)
+	require.Len(t, groups, 4)
+
+	assert.Equal(t, "Component '{component}' ({version}) uses high-risk copyleft license 'GPL-3.0-only' (GNU General Public License v3.0 only).", groups[0].Finding)
+	assert.Len(t, groups[0].Results, 2)
+	assert.Equal(t, "lib-a 1.0.0, lib-b 3.0", groups[0].ComponentsLabel())
+
+	// Findings without a component and findings of another severity stay apart
+	assert.Equal(t, "Missing supplier", groups[1].Finding)
+	assert.Equal(t, "Missing supplier", groups[2].Finding)
+	assert.Equal(t, results[4].Finding, groups[3].Finding)
+
+	assert.Len(t, UngroupedFindings(results), 5)
+}
+
+func TestFindingGroup_Remediation(t *testing.T) {
+	lodash := core.AnalysisResult{Finding: "GHSA-p6mc in lodash", Component: &core.ComponentRef{Name: "lodash", Version: "4.17.15"}, UpgradeTo: "4.17.21"}
+	underscore := core.AnalysisResult{Finding: "GHSA-p6mc in underscore", Component: &core.ComponentRef{Name: "underscore", Version: "1.12.0"}, UpgradeTo: "1.12.1"}
+
+	group := FindingGroup{Results: []core.AnalysisResult{lodash, underscore}}
+	assert.Empty(t, group.Remediation())
+	assert.Equal(t, "lodash 4.17.15 (Upgrade to 4.17.21), underscore 1.12.0 (Upgrade to 1.12.1)", group.ComponentsLabel())
+
+	group = FindingGroup{Results: []core.AnalysisResult{lodash, lodash}}
+	assert.Equal(t, "Upgrade to 4.17.21", group.Remediation())
+	assert.Equal(t, "lodash 4.17.15, lodash 4.17.15", group.ComponentsLabel())
+}
+
+func TestFindingGroup_Owners(t *testing.T) {
+	group := FindingGroup{Results: []core.AnalysisResult{
+		{Finding: "GPL in ledger", Owner: "payments"},
+		{Finding: "GPL in react"},
+		{Finding: "GPL in ui", Owner: "web"},
+		{Finding: "GPL in wallet", Owner: "payments"},
+	}}
+	assert.Equal(t, []string{"payments", "web"}, group.Owners())
+	assert.Empty(t, FindingGroup{Results: group.Results[1:2]}.Owners())
+}
+
+func TestWriteMarkdown_Grouped(t *testing.T) {
+	r := &Report{Source: "sbom.json", SBOM: core.SBOM{Name: "monorepo"}, FailOn: FailOnNone}
+	for i := 0; i < 12; i++ {
+		r.Results = append(r.Results, gplFinding(fmt.Sprintf("lib-%d", i), "1.0.0"))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Contains(t, buf.String(), "| 🔴 High | License Agent | 12 components: lib-0 1.0.0, lib-1 1.0.0, lib-2 1.0.0, lib-3 1.0.0, lib-4 1.0.0, lib-5 1.0.0, lib-6 1.0.0, lib-7 1.0.0, lib-8 1.0.0, lib-9 1.0.0 and 2 more | Component '{component}' ({version}) uses")
+
+	r.NoGroup = true
+	buf.Reset()
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Equal(t, 12, strings.Count(buf.String(), "| 🔴 High | License Agent |"))
+}
+
+func TestWriteMarkdown_ComponentLinks(t *testing.T) {
+	r := &Report{Source: "sbom.json", FailOn: FailOnNone, SBOM: core.SBOM{Name: "web", Components: []core.Component{
+		{Name: "lib-0", Version: "1.0.0", ExternalReferences: []core.ExternalReference{{Type: core.ReferenceVCS, URL: "git+https://github.com/acme/lib-0.git"}}},
+		{Name: "lib-1", Version: "1.0.0", ExternalReferences: []core.ExternalReference{{Type: core.ReferenceWebsite, URL: "https://lib-1.example.com/a (b)"}}},
+		{Name: "lib-2", Version: "1.0.0"},
+	}}}
+	for i := 0; i < 3; i++ {
+		r.Results = append(r.Results, gplFinding(fmt.Sprintf("lib-%d", i), "1.0.0"))
+	}
+	r.Results = append(r.Results, core.AnalysisResult{AgentName: "Vulnerability Scanner Agent", Severity: "Critical", Finding: "CVE-2024-0001",
+		Component: &core.ComponentRef{Name: "lib-0", Version: "1.0.0"}})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, r))
+	assert.Contains(t, buf.String(), "| 🚨 Critical | Vulnerability Scanner Agent | [lib-0 1.0.0](https://github.com/acme/lib-0) | CVE-2024-0001 |")
+	assert.Contains(t, buf.String(), "3 components: [lib-0 1.0.0](https://github.com/acme/lib-0), [lib-1 1.0.0](https://lib-1.example.com/a%20%28b%29), lib-2 1.0.0 |")
+}
+
+func TestNewUpgradePlan(t *testing.T) {
+	log4j := &core.ComponentRef{Name: "log4j-core", Version: "2.14.1"}
+	lodash := &core.ComponentRef{Name: "lodash", Version: "4.17.15"}
+	minimist := &core.ComponentRef{Name: "minimist", Version: "0.0.8"}
+	results := []core.AnalysisResult{
+		{AgentName: "License Agent", Severity: "High", Finding: "AGPL", Component: lodash},
+		{Severity: "High", Component: lodash, VulnerabilityID: "GHSA-p6mc", FixedVersion: "4.17.21", UpgradeTo: "4.17.21"},
+		{Severity: "Medium", Component: lodash, VulnerabilityID: "GHSA-29mw", FixedVersion: "4.17.19", UpgradeTo: "4.17.21"},
+		{Severity: "Critical", Component: minimist, VulnerabilityID: "GHSA-xvch"},
+		{Severity: "Critical", Component: log4j, VulnerabilityID: "GHSA-jfh8", FixedVersion: "2.15.0", UpgradeTo: "2.17.1"},
+	}
+
+	plan := NewUpgradePlan(results)
+	require.Len(t, plan, 3)
+
+	// Fixable upgrades come first, most severe first; license findings are not upgrades
+	assert.Equal(t, "log4j-core", plan[0].Component.Name)
+	assert.Equal(t, "2.17.1", plan[0].To)
+	assert.Equal(t, "lodash", plan[1].Component.Name)
+	assert.Equal(t, "High", plan[1].Severity)
+	assert.Equal(t, []string{"GHSA-p6mc", "GHSA-29mw"}, plan[1].VulnerabilityIDs())
+	assert.Equal(t, "minimist", plan[2].Component.Name)
+	assert.False(t, plan[2].Fixable())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteUpgradePlanMarkdown(&buf, "payments-api", plan))
+	markdown := buf.String()
+	assert.Contains(t, markdown, "## 🔧 Upgrade Plan: payments-api")
+	assert.Contains(t, markdown, "- [ ] 🚨 **log4j-core** 2.14.1 → **2.17.1** — resolves 1 finding (GHSA-jfh8)\n")
+	assert.Contains(t, markdown, "- [ ] 🔴 **lodash** 4.17.15 → **4.17.21** — resolves 2 findings (GHSA-p6mc, GHSA-29mw)\n")
+	assert.Contains(t, markdown, "### No Known Fix\n\n- 🚨 **minimist** 0.0.8 — 1 finding (GHSA-xvch)\n")
+}
+
+func TestWriteCBOM(t *testing.T) {
+	sbom := core.SBOM{
+		Components: []core.Component{
+			{Name: "openssl", Version: "3.0.11", PURL: "pkg:generic/openssl@3.0.11"},
+			{Name: "libressl", Version: "3.8.2"},
+		},
+	}
+
+	defer core.SetClock(core.NewFixedClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)))()
+	var buf bytes.Buffer
+	require.NoError(t, WriteCBOM(&buf, "payments-api", analysis.CryptoInventory(sbom)))
+
+	var doc struct {
+		SpecVersion string `json:"specVersion"`
+		Metadata    struct {
+			Timestamp string `json:"timestamp"`
+			Component struct {
+				Name string `json:"name"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			Type             string `json:"type"`
+			BOMRef           string `json:"bom-ref"`
+			CryptoProperties *struct {
+				AssetType           string `json:"assetType"`
+				AlgorithmProperties struct {
+					Primitive                string `json:"primitive"`
+					NISTQuantumSecurityLevel int    `json:"nistQuantumSecurityLevel"`
+				} `json:"algorithmProperties"`
+			} `json:"cryptoProperties"`
+		} `json:"components"`
+		Dependencies []struct {
+			Ref      string   `json:"ref"`
+			Provides []string `json:"provides"`
+		} `json:"dependencies"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, "1.6", doc.SpecVersion)
+	assert.Equal(t, "payments-api", doc.Metadata.Component.Name)
+	assert.Equal(t, "2025-03-01T12:00:00Z", doc.Metadata.Timestamp)
+
+	// Libraries first, then each algorithm once
+	assert.Equal(t, "library", doc.Components[0].Type)
+	assert.Equal(t, "pkg:generic/openssl@3.0.11", doc.Components[0].BOMRef)
+	assert.Equal(t, "libressl@3.8.2", doc.Components[1].BOMRef)
+
+	algorithms := make(map[string]int)
+	for _, component := range doc.Components[2:] {
+		assert.Equal(t, "cryptographic-asset", component.Type)
+		require.NotNil(t, component.CryptoProperties)
+		assert.Equal(t, "algorithm", component.CryptoProperties.AssetType)
+		algorithms[component.BOMRef] = component.CryptoProperties.AlgorithmProperties.NISTQuantumSecurityLevel
+	}
+	assert.Len(t, algorithms, len(doc.Components)-2)
+	assert.Equal(t, 0, algorithms["crypto/algorithm/RSA"])
+	assert.Equal(t, 1, algorithms["crypto/algorithm/AES"])
+
+	require.Len(t, doc.Dependencies, 2)
+	assert.Contains(t, doc.Dependencies[1].Provides, "crypto/algorithm/ChaCha20-Poly1305")
+}
+
+func TestWriteExportControlCSV(t *testing.T) {
+	sbom := core.SBOM{
+		Components: []core.Component{
+			{Name: "openssl", Version: "3.0.11", PURL: "pkg:generic/openssl@3.0.11"},
+			{Name: "lodash", Version: "4.17.21"},
+			{Name: "libcurl", Version: "8.5.0"},
+		},
+	}
+	entries := analysis.ExportControlInventory(sbom, analysis.DefaultExportRules())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteExportControlCSV(&buf, "payments-api", entries))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "product,component,version,purl,category,candidate_eccn,reason", lines[0])
+	assert.Equal(t, "payments-api,openssl,3.0.11,pkg:generic/openssl@3.0.11,encryption,5D002,provides cryptography (OpenSSL)", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "payments-api,libcurl,8.5.0,,networking,5D991,"))
+}
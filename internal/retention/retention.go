@@ -0,0 +1,257 @@
+// Package retention provides retention policies for stored SBOMs and a
+// background janitor that enforces them.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// Policy decides which stored SBOMs and recorded analyses are pruned. SBOMs
+// with the same name are versions of the same project. Zero values disable a
+// rule.
+type Policy struct {
+	// KeepVersions is how many of the newest versions of each project are kept
+	KeepVersions int
+	// MaxAge is how long after submission an SBOM is deleted. The newest
+	// version of a project is always kept, so projects never disappear.
+	MaxAge time.Duration
+	// MaxAnalysisAge is how long analysis runs, and findings after they
+	// were resolved, are kept. Open findings are never deleted.
+	MaxAnalysisAge time.Duration
+}
+
+// PolicyFromEnv returns the retention policy configured by
+// RETENTION_KEEP_VERSIONS, RETENTION_MAX_AGE and RETENTION_ANALYSIS_MAX_AGE
+// (Go durations such as "720h"). Invalid values are reported and ignored.
+func PolicyFromEnv() (Policy, error) {
+	var policy Policy
+	var errs []error
+
+	if value := os.Getenv("RETENTION_KEEP_VERSIONS"); value != "" {
+		versions, err := strconv.Atoi(value)
+		if err != nil || versions < 1 {
+			errs = append(errs, fmt.Errorf("invalid RETENTION_KEEP_VERSIONS %q", value))
+		} else {
+			policy.KeepVersions = versions
+		}
+	}
+
+	if value := os.Getenv("RETENTION_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge <= 0 {
+			errs = append(errs, fmt.Errorf("invalid RETENTION_MAX_AGE %q", value))
+		} else {
+			policy.MaxAge = maxAge
+		}
+	}
+
+	if value := os.Getenv("RETENTION_ANALYSIS_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge <= 0 {
+			errs = append(errs, fmt.Errorf("invalid RETENTION_ANALYSIS_MAX_AGE %q", value))
+		} else {
+			policy.MaxAnalysisAge = maxAge
+		}
+	}
+
+	return policy, errors.Join(errs...)
+}
+
+// Enabled reports whether the policy prunes anything.
+func (p Policy) Enabled() bool {
+	return p.KeepVersions > 0 || p.MaxAge > 0 || p.MaxAnalysisAge > 0
+}
+
+// Expired returns the records the policy prunes at the given time, oldest first.
+func (p Policy) Expired(records []storage.SBOMRecord, now time.Time) []storage.SBOMRecord {
+	// Group the versions of each project, newest first
+	projects := make(map[string][]storage.SBOMRecord)
+	for _, record := range records {
+		projects[record.Name] = append(projects[record.Name], record)
+	}
+
+	var expired []storage.SBOMRecord
+	for _, versions := range projects {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].CreatedAt.After(versions[j].CreatedAt)
+		})
+
+		for i, version := range versions {
+			switch {
+			case p.KeepVersions > 0 && i >= p.KeepVersions:
+				expired = append(expired, version)
+			case p.MaxAge > 0 && i > 0 && now.Sub(version.CreatedAt) > p.MaxAge:
+				expired = append(expired, version)
+			}
+		}
+	}
+
+	sort.SliceStable(expired, func(i, j int) bool {
+		if !expired[i].CreatedAt.Equal(expired[j].CreatedAt) {
+			return expired[i].CreatedAt.Before(expired[j].CreatedAt)
+		}
+		return expired[i].ID < expired[j].ID
+	})
+	return expired
+}
+
+// DefaultInterval is how often the server prunes when RETENTION_INTERVAL is not set.
+const DefaultInterval = 24 * time.Hour
+
+// Schedule is how often a background janitor prunes, and whether it only
+// reports what it would delete.
+type Schedule struct {
+	Interval time.Duration
+	DryRun   bool
+}
+
+// ScheduleFromEnv returns the schedule configured by RETENTION_INTERVAL (a Go
+// duration, default DefaultInterval) and RETENTION_DRY_RUN. Invalid values
+// are reported and the defaults used.
+func ScheduleFromEnv() (Schedule, error) {
+	schedule := Schedule{Interval: DefaultInterval}
+	var errs []error
+
+	if value := os.Getenv("RETENTION_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("invalid RETENTION_INTERVAL %q", value))
+		} else {
+			schedule.Interval = interval
+		}
+	}
+
+	if value := os.Getenv("RETENTION_DRY_RUN"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid RETENTION_DRY_RUN %q", value))
+		} else {
+			schedule.DryRun = dryRun
+		}
+	}
+
+	return schedule, errors.Join(errs...)
+}
+
+// Report describes the outcome of a pruning run.
+type Report struct {
+	// DryRun is true if nothing was deleted
+	DryRun bool `json:"dry_run"`
+	// Pruned lists the SBOMs deleted, or that would be deleted in a dry run
+	Pruned []storage.SBOMRecord `json:"pruned"`
+	// Kept is the number of SBOMs retained
+	Kept int `json:"kept"`
+	// PrunedAnalyses and PrunedFindings count the analysis runs and resolved
+	// findings deleted; dry runs do not count them
+	PrunedAnalyses int `json:"pruned_analyses"`
+	PrunedFindings int `json:"pruned_findings"`
+}
+
+// Janitor enforces a retention policy on a repository.
+type Janitor struct {
+	store  storage.Pruner
+	policy Policy
+}
+
+// NewJanitor creates a janitor enforcing policy on store. Analyses are only
+// pruned if store also implements storage.AnalysisPruner.
+func NewJanitor(store storage.Pruner, policy Policy) *Janitor {
+	return &Janitor{store: store, policy: policy}
+}
+
+// Prune deletes the SBOMs and analyses expired under the policy. With dryRun
+// it only reports the SBOMs that would be deleted. If a deletion fails, the
+// report lists the SBOMs deleted so far.
+func (j *Janitor) Prune(ctx context.Context, dryRun bool) (*Report, error) {
+	records, err := j.store.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := core.Now()
+	expired := j.policy.Expired(records, now)
+	report := &Report{DryRun: dryRun, Pruned: []storage.SBOMRecord{}, Kept: len(records) - len(expired)}
+	if dryRun {
+		report.Pruned = append(report.Pruned, expired...)
+		return report, nil
+	}
+
+	for i, record := range expired {
+		if err := ctx.Err(); err != nil {
+			report.Kept += len(expired) - i
+			return report, err
+		}
+		if err := j.store.Delete(ctx, record.ID); err != nil {
+			report.Kept += len(expired) - i
+			return report, fmt.Errorf("failed to prune SBOM %s: %w", record.ID, err)
+		}
+		report.Pruned = append(report.Pruned, record)
+	}
+
+	analyses, ok := j.store.(storage.AnalysisPruner)
+	if !ok || j.policy.MaxAnalysisAge <= 0 {
+		return report, nil
+	}
+	report.PrunedAnalyses, report.PrunedFindings, err = analyses.PruneAnalyses(ctx, now.Add(-j.policy.MaxAnalysisAge))
+	if err != nil {
+		return report, fmt.Errorf("failed to prune analyses: %w", err)
+	}
+	return report, nil
+}
+
+// Start prunes in the background on the given schedule until ctx is
+// cancelled, logging what was pruned. It does nothing if the policy is
+// disabled.
+func (j *Janitor) Start(ctx context.Context, schedule Schedule) {
+	if schedule.Interval <= 0 || !j.policy.Enabled() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(schedule.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := j.Prune(ctx, schedule.DryRun)
+				if err != nil {
+					fmt.Printf("Warning: Scheduled retention pruning failed: %v\n", err)
+				}
+				if report != nil {
+					logReport(report)
+				}
+			}
+		}
+	}()
+}
+
+// logReport prints the SBOMs and analyses pruned by a scheduled run.
+func logReport(report *Report) {
+	if report.PrunedAnalyses > 0 || report.PrunedFindings > 0 {
+		fmt.Printf("Retention: Pruned %d analysis runs and %d resolved findings\n", report.PrunedAnalyses, report.PrunedFindings)
+	}
+	if len(report.Pruned) == 0 {
+		return
+	}
+
+	verb := "Pruned"
+	if report.DryRun {
+		verb = "Would prune"
+	}
+	fmt.Printf("Retention: %s %d SBOMs, kept %d\n", verb, len(report.Pruned), report.Kept)
+	for _, record := range report.Pruned {
+		fmt.Printf("  %s (%s, submitted %s)\n", record.ID, record.Name, record.CreatedAt.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,232 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func record(id, name string, age time.Duration) storage.SBOMRecord {
+	return storage.SBOMRecord{ID: id, Name: name, CreatedAt: now.Add(-age)}
+}
+
+func ids(records []storage.SBOMRecord) []string {
+	result := make([]string, len(records))
+	for i, record := range records {
+		result[i] = record.ID
+	}
+	return result
+}
+
+func TestPolicy_Expired(t *testing.T) {
+	day := 24 * time.Hour
+	records := []storage.SBOMRecord{
+		record("api-1", "api", 90*day),
+		record("api-2", "api", 60*day),
+		record("api-3", "api", 10*day),
+		record("api-4", "api", 1*day),
+		record("web-1", "web", 100*day),
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		expected []string
+	}{
+		{"disabled", Policy{}, []string{}},
+		{"keep versions", Policy{KeepVersions: 2}, []string{"api-1", "api-2"}},
+		// The newest version of a project is kept however old it is
+		{"max age", Policy{MaxAge: 30 * day}, []string{"api-1", "api-2"}},
+		{"both", Policy{KeepVersions: 3, MaxAge: 70 * day}, []string{"api-1"}},
+		{"keep one", Policy{KeepVersions: 1}, []string{"api-1", "api-2", "api-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ids(tt.policy.Expired(records, now)))
+		})
+	}
+}
+
+func TestJanitor_Prune(t *testing.T) {
+	ctx := context.Background()
+	repo, err := database.NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	for _, id := range []string{"api-1", "api-2", "api-3"} {
+		require.NoError(t, repo.Store(ctx, core.SBOM{ID: id, Name: "api"}))
+		// Creation times must differ to order the versions
+		time.Sleep(5 * time.Millisecond)
+	}
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "web-1", Name: "web"}))
+
+	janitor := NewJanitor(repo, Policy{KeepVersions: 1})
+
+	// A dry run reports without deleting
+	report, err := janitor.Prune(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, []string{"api-1", "api-2"}, ids(report.Pruned))
+	assert.Equal(t, 2, report.Kept)
+
+	records, err := repo.ListRecords(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 4)
+
+	report, err = janitor.Prune(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, []string{"api-1", "api-2"}, ids(report.Pruned))
+
+	records, err = repo.ListRecords(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api-3", "web-1"}, ids(records))
+
+	sbom, err := repo.FindByID(ctx, "api-1")
+	require.NoError(t, err)
+	assert.Nil(t, sbom)
+}
+
+// failingStore fails to delete a given SBOM.
+type failingStore struct {
+	records []storage.SBOMRecord
+	deleted []string
+	failOn  string
+}
+
+func (s *failingStore) ListRecords(ctx context.Context) ([]storage.SBOMRecord, error) {
+	return s.records, nil
+}
+
+func (s *failingStore) Delete(ctx context.Context, id string) error {
+	if id == s.failOn {
+		return errors.New("disk I/O error")
+	}
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func TestJanitor_PruneError(t *testing.T) {
+	store := &failingStore{
+		records: []storage.SBOMRecord{
+			record("a-1", "a", 3*time.Hour),
+			record("a-2", "a", 2*time.Hour),
+			record("a-3", "a", 1*time.Hour),
+		},
+		failOn: "a-2",
+	}
+	defer core.SetClock(core.NewFixedClock(now))()
+	janitor := NewJanitor(store, Policy{KeepVersions: 1})
+
+	report, err := janitor.Prune(context.Background(), false)
+	assert.ErrorContains(t, err, "failed to prune SBOM a-2")
+	// The report covers the SBOMs deleted before the failure
+	assert.Equal(t, []string{"a-1"}, ids(report.Pruned))
+	assert.Equal(t, 2, report.Kept)
+}
+
+// analysisStore is a repository whose analyses can be pruned.
+type analysisStore interface {
+	storage.Repository
+	storage.Pruner
+	storage.FindingHistory
+}
+
+func TestJanitor_PruneAnalyses(t *testing.T) {
+	ctx := context.Background()
+	day := 24 * time.Hour
+	sqlite, err := database.NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	defer sqlite.Close()
+
+	for name, repo := range map[string]analysisStore{"sqlite": sqlite, "memory": memory.NewMemoryRepository()} {
+		t.Run(name, func(t *testing.T) {
+			clock := core.NewFixedClock(now)
+			defer core.SetClock(clock)()
+			finding := func(fingerprint string) storage.FindingRecord {
+				return storage.FindingRecord{Fingerprint: fingerprint, AgentName: "scanner", Severity: "high", Finding: fingerprint}
+			}
+			// "old" is resolved by the second analysis, "late" by the third,
+			// and "open" is still reported
+			analyses := []struct {
+				at       time.Duration
+				findings []storage.FindingRecord
+			}{
+				{0, []storage.FindingRecord{finding("old"), finding("late")}},
+				{10 * day, []storage.FindingRecord{finding("late"), finding("open")}},
+				{40 * day, []storage.FindingRecord{finding("open")}},
+			}
+			for i, analysis := range analyses {
+				id := fmt.Sprintf("api-%d", i+1)
+				require.NoError(t, repo.Store(ctx, core.SBOM{ID: id, Name: "api"}))
+				run := storage.AnalysisRun{SBOMID: id, Project: "api", AnalyzedAt: now.Add(analysis.at)}
+				_, err := repo.RecordAnalysis(ctx, run, []string{"scanner"}, analysis.findings)
+				require.NoError(t, err)
+				clock.Advance(time.Hour)
+			}
+
+			// The cutoff falls between the second and third analyses
+			clock.Advance(45 * day)
+			report, err := NewJanitor(repo, Policy{MaxAnalysisAge: 30 * day}).Prune(ctx, false)
+			require.NoError(t, err)
+			assert.Empty(t, report.Pruned)
+			assert.Equal(t, 2, report.PrunedAnalyses)
+			assert.Equal(t, 1, report.PrunedFindings)
+
+			runs, err := repo.AnalysisRuns(ctx, "api")
+			require.NoError(t, err)
+			require.Len(t, runs, 1)
+			assert.Equal(t, "api-3", runs[0].SBOMID)
+			findings, err := repo.ProjectFindings(ctx, "api")
+			require.NoError(t, err)
+			fingerprints := make([]string, len(findings))
+			for i, finding := range findings {
+				fingerprints[i] = finding.Fingerprint
+			}
+			assert.ElementsMatch(t, []string{"late", "open"}, fingerprints)
+		})
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv("RETENTION_KEEP_VERSIONS", "5")
+	t.Setenv("RETENTION_MAX_AGE", "720h")
+	t.Setenv("RETENTION_ANALYSIS_MAX_AGE", "2160h")
+	policy, err := PolicyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Policy{KeepVersions: 5, MaxAge: 720 * time.Hour, MaxAnalysisAge: 2160 * time.Hour}, policy)
+
+	t.Setenv("RETENTION_KEEP_VERSIONS", "0")
+	t.Setenv("RETENTION_MAX_AGE", "30d")
+	t.Setenv("RETENTION_ANALYSIS_MAX_AGE", "-1h")
+	policy, err = PolicyFromEnv()
+	assert.Error(t, err)
+	assert.False(t, policy.Enabled())
+}
+
+func TestScheduleFromEnv(t *testing.T) {
+	t.Setenv("RETENTION_INTERVAL", "")
+	t.Setenv("RETENTION_DRY_RUN", "")
+	schedule, err := ScheduleFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Schedule{Interval: DefaultInterval}, schedule)
+
+	t.Setenv("RETENTION_INTERVAL", "1h")
+	t.Setenv("RETENTION_DRY_RUN", "true")
+	schedule, err = ScheduleFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Schedule{Interval: time.Hour, DryRun: true}, schedule)
+}
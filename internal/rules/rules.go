@@ -0,0 +1,305 @@
+// Package rules provides the rule engine agent, which reports the components
+// matching user-defined CEL expressions, such as
+//
+//	component.license == "" && component.purl.startsWith("pkg:npm")
+//
+// Each rule has a severity and a message, and rules are loaded from a YAML
+// rules file. Expressions are type-checked against the declared variables
+// when they are compiled.
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"gopkg.in/yaml.v3"
+)
+
+// AgentName is the name of the rule engine agent.
+const AgentName = "Rule Engine"
+
+// Rule reports the components for which Expression is true.
+type Rule struct {
+	Name string `yaml:"name"`
+	// Expression is evaluated for every component, with the variables
+	// component and sbom
+	Expression string `yaml:"expression"`
+	// Severity is Critical, High, Medium, Low or Info
+	Severity string `yaml:"severity"`
+	// Message describes a finding; {name}, {version}, {purl}, {license}
+	// and {rule} are replaced by the component's values and the rule name
+	Message string `yaml:"message"`
+
+	program cel.Program
+}
+
+// componentVariable is the component variable of rule expressions.
+type componentVariable struct {
+	Name      string   `cel:"name"`
+	Version   string   `cel:"version"`
+	PURL      string   `cel:"purl"`
+	CPE       string   `cel:"cpe"`
+	License   string   `cel:"license"`
+	Licenses  []string `cel:"licenses"`
+	Supplier  string   `cel:"supplier"`
+	Type      string   `cel:"type"`
+	Scope     string   `cel:"scope"`
+	Ecosystem string   `cel:"ecosystem"`
+}
+
+// sbomVariable is the sbom variable of rule expressions.
+type sbomVariable struct {
+	ID       string            `cel:"id"`
+	Name     string            `cel:"name"`
+	Metadata map[string]string `cel:"metadata"`
+}
+
+// environment declares the variables of rule expressions, with the CEL
+// string extensions such as lowerAscii.
+var environment = sync.OnceValues(func() (*cel.Env, error) {
+	componentType, sbomType := reflect.TypeFor[componentVariable](), reflect.TypeFor[sbomVariable]()
+	return cel.NewEnv(
+		ext.NativeTypes(componentType, sbomType, ext.ParseStructTags(true)),
+		ext.Strings(),
+		cel.Variable("component", cel.ObjectType("rules."+componentType.Name())),
+		cel.Variable("sbom", cel.ObjectType("rules."+sbomType.Name())),
+	)
+})
+
+// Compile checks the rule and compiles its expression.
+func (r *Rule) Compile() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if core.SeverityRank(r.Severity) == 0 && !strings.EqualFold(r.Severity, "Info") {
+		return fmt.Errorf("severity must be Critical, High, Medium, Low or Info, got %q", r.Severity)
+	}
+	if r.Message == "" {
+		r.Message = "Component {name}@{version} matches rule '{rule}'"
+	}
+
+	env, err := environment()
+	if err != nil {
+		return fmt.Errorf("failed to create expression environment: %w", err)
+	}
+	ast, issues := env.Compile(r.Expression)
+	if err := issues.Err(); err != nil {
+		return fmt.Errorf("invalid expression: %w", err)
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return fmt.Errorf("invalid expression: yields %s, expected bool", ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return fmt.Errorf("invalid expression: %w", err)
+	}
+	r.program = program
+	return nil
+}
+
+// eval evaluates the compiled expression with the variables of a component.
+func (r *Rule) eval(vars map[string]any) (bool, error) {
+	value, _, err := r.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	matched, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression yields %s, expected bool", value.Type())
+	}
+	return matched, nil
+}
+
+// LoadFile reads and compiles the rules of the rules file at path.
+func LoadFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file '%s': %w", path, err)
+	}
+
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file '%s': %w", path, err)
+	}
+
+	for i := range file.Rules {
+		if err := file.Rules[i].Compile(); err != nil {
+			return nil, fmt.Errorf("rules file '%s' defines an invalid rule %d: %w", path, i+1, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// Agent evaluates rules against every component of an SBOM.
+type Agent struct {
+	rules []Rule
+}
+
+// NewAgent creates a rule engine agent for compiled rules.
+func NewAgent(rules []Rule) *Agent {
+	return &Agent{rules: rules}
+}
+
+// Name implements the analysis.AnalysisAgent interface.
+func (a *Agent) Name() string {
+	return AgentName
+}
+
+// Rules returns the rules of the agent.
+func (a *Agent) Rules() []Rule {
+	return a.rules
+}
+
+// placeholder matches a message placeholder such as {name}.
+var placeholder = regexp.MustCompile(`\{(name|version|purl|license|rule)\}`)
+
+// Analyze implements the analysis.AnalysisAgent interface. A rule that
+// cannot be evaluated, e.g. because it compares values of different types,
+// fails the analysis.
+func (a *Agent) Analyze(ctx context.Context, sbom core.SBOM) ([]core.AnalysisResult, error) {
+	var results []core.AnalysisResult
+	sbomVars := sbomVariables(sbom)
+
+	for _, component := range sbom.Components {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		vars := map[string]any{"component": componentVariables(component), "sbom": sbomVars}
+		for _, rule := range a.rules {
+			matched, err := rule.eval(vars)
+			if err != nil {
+				return nil, fmt.Errorf("rule '%s' failed for component %s: %w", rule.Name, component.Name, err)
+			}
+			if !matched {
+				continue
+			}
+
+			results = append(results, core.AnalysisResult{
+				AgentName: AgentName,
+				Finding:   expandMessage(rule, component),
+				Severity:  rule.Severity,
+				Component: component.Ref(),
+				RuleID:    rule.Name,
+			})
+		}
+	}
+	return results, nil
+}
+
+// expandMessage replaces the placeholders of the rule's message.
+func expandMessage(rule Rule, component core.Component) string {
+	return placeholder.ReplaceAllStringFunc(rule.Message, func(match string) string {
+		switch match {
+		case "{name}":
+			return component.Name
+		case "{version}":
+			return component.Version
+		case "{purl}":
+			return component.PURL
+		case "{license}":
+			return component.License
+		default:
+			return rule.Name
+		}
+	})
+}
+
+// componentVariables returns the component variable of rule expressions.
+func componentVariables(component core.Component) componentVariable {
+	licenses := component.DeclaredLicenses()
+	if licenses == nil {
+		licenses = []string{}
+	}
+	return componentVariable{
+		Name:      component.Name,
+		Version:   component.Version,
+		PURL:      component.PURL,
+		CPE:       component.CPE,
+		License:   component.License,
+		Licenses:  licenses,
+		Supplier:  component.Supplier,
+		Type:      component.Type,
+		Scope:     component.EffectiveScope(),
+		Ecosystem: component.PURLType(),
+	}
+}
+
+// sbomVariables returns the sbom variable of rule expressions.
+func sbomVariables(sbom core.SBOM) sbomVariable {
+	metadata := sbom.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return sbomVariable{ID: sbom.ID, Name: sbom.Name, Metadata: metadata}
+}
+
+var (
+	// defaultAgentMu guards defaultAgent, which ReloadAgent replaces
+	defaultAgentMu   sync.RWMutex
+	defaultAgent     *Agent
+	defaultAgentOnce sync.Once
+)
+
+// AgentFromEnv returns the agent for the rules file in $SENTINEL_RULES_FILE,
+// loaded once or by the last successful ReloadAgent. It returns nil if no
+// rules file is configured or it cannot be loaded, which is reported as a
+// warning.
+func AgentFromEnv() *Agent {
+	defaultAgentOnce.Do(func() {
+		agent, err := agentFromEnv()
+		if err != nil {
+			fmt.Printf("Warning: Rules in SENTINEL_RULES_FILE are not applied: %v\n", err)
+			return
+		}
+		defaultAgentMu.Lock()
+		defaultAgent = agent
+		defaultAgentMu.Unlock()
+	})
+
+	defaultAgentMu.RLock()
+	defer defaultAgentMu.RUnlock()
+	return defaultAgent
+}
+
+// ReloadAgent re-reads the rules file of the agent returned by AgentFromEnv.
+// If it cannot be loaded, the error is returned and the current agent kept.
+func ReloadAgent() (*Agent, error) {
+	// Load the initial agent first, so that it does not replace the
+	// reloaded one
+	current := AgentFromEnv()
+
+	agent, err := agentFromEnv()
+	if err != nil {
+		return current, err
+	}
+	defaultAgentMu.Lock()
+	defaultAgent = agent
+	defaultAgentMu.Unlock()
+	return agent, nil
+}
+
+// agentFromEnv loads the agent for $SENTINEL_RULES_FILE, or returns nil if
+// it is not set.
+func agentFromEnv() (*Agent, error) {
+	path := os.Getenv("SENTINEL_RULES_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	rules, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewAgent(rules), nil
+}
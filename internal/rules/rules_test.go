@@ -0,0 +1,178 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `rules:
+  - name: npm-unlicensed
+    expression: component.license == "" && component.purl.startsWith("pkg:npm")
+    severity: High
+    message: "{name}@{version} is an npm package without a license"
+  - name: acme-internal
+    expression: component.supplier == "Acme" && sbom.metadata["componentType"] != "application"
+    severity: Info
+`
+
+func writeRules(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	return path
+}
+
+func TestAgent_Analyze(t *testing.T) {
+	rules, err := LoadFile(writeRules(t, testRules))
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	agent := NewAgent(rules)
+	assert.Equal(t, AgentName, agent.Name())
+
+	sbom := core.SBOM{
+		Name:     "app",
+		Metadata: map[string]string{"componentType": "container"},
+		Components: []core.Component{
+			{Name: "left-pad", Version: "1.3.0", PURL: "pkg:npm/left-pad@1.3.0"},
+			{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", License: "MIT"},
+			{Name: "acme-auth", Version: "2.0.0", Supplier: "Acme", License: "Proprietary"},
+		},
+	}
+	results, err := agent.Analyze(context.Background(), sbom)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, AgentName, results[0].AgentName)
+	assert.Equal(t, "npm-unlicensed", results[0].RuleID)
+	assert.Equal(t, "left-pad@1.3.0 is an npm package without a license", results[0].Finding)
+	assert.Equal(t, "High", results[0].Severity)
+	assert.Equal(t, "left-pad", results[0].Component.Name)
+
+	assert.Equal(t, "Component acme-auth@2.0.0 matches rule 'acme-internal'", results[1].Finding)
+	assert.Equal(t, "Info", results[1].Severity)
+}
+
+func TestAgent_AnalyzeError(t *testing.T) {
+	rules, err := LoadFile(writeRules(t, "rules:\n  - name: first-license\n    expression: component.licenses[0] == \"MIT\"\n    severity: Low\n"))
+	require.NoError(t, err)
+
+	_, err = NewAgent(rules).Analyze(context.Background(), core.SBOM{Components: []core.Component{{Name: "left-pad"}}})
+	assert.ErrorContains(t, err, "rule 'first-license' failed for component left-pad: index out of bounds: 0")
+}
+
+func TestRule_Expressions(t *testing.T) {
+	component := core.Component{
+		Name: "left-pad", Version: "1.3.0", PURL: "pkg:npm/left-pad@1.3.0",
+		Licenses: []string{"MIT", "Apache-2.0"},
+	}
+	sbom := core.SBOM{ID: "sbom-1", Name: "app", Metadata: map[string]string{"specVersion": "1.5"}}
+	vars := map[string]any{"component": componentVariables(component), "sbom": sbomVariables(sbom)}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{`component.license == "" && component.purl.startsWith("pkg:npm")`, true},
+		{`component.ecosystem == "npm" && component.scope == "required"`, true},
+		{`component.name in ["lodash", "left-pad"]`, true},
+		{`"GPL-3.0" in component.licenses`, false},
+		{`component.licenses.exists(l, l.startsWith("Apache"))`, true},
+		{`component.licenses.all(l, l.size() > 3)`, false},
+		{`component.licenses.exists_one(l, l == "MIT")`, true},
+		{`size(component.licenses) == 2 && component.licenses[0] == 'MIT'`, true},
+		{`component.version.matches("^1\\.[0-9]+\\.")`, true},
+		{`component.name.upperAscii() == "LEFT-PAD"`, true},
+		{`sbom.metadata["specVersion"] >= "1.4" && "specVersion" in sbom.metadata`, true},
+		{`sbom.id == "sbom-1" && sbom.name == "app"`, true},
+		{`has(sbom.metadata.componentType) ? sbom.metadata.componentType == "container" : true`, true},
+		// Errors on one side are absorbed when the other decides the result
+		{`true || sbom.metadata["missing"] == ""`, true},
+		{`sbom.metadata["missing"] == "" && false`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			rule := Rule{Name: "test", Expression: tt.expression, Severity: "Low"}
+			require.NoError(t, rule.Compile())
+			matched, err := rule.eval(vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+
+	rule := Rule{Name: "test", Expression: `sbom.metadata["componentType"] == "container"`, Severity: "Low"}
+	require.NoError(t, rule.Compile())
+	_, err := rule.eval(vars)
+	assert.ErrorContains(t, err, "no such key: componentType")
+}
+
+func TestRule_CompileErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{``, "Syntax error"},
+		{`component.name ==`, "Syntax error"},
+		{`component.name == "open`, "Syntax error"},
+		// Expressions are checked against the declared variables and types
+		{`component.licence == ""`, "undefined field 'licence'"},
+		{`version == "1.0"`, "undeclared reference to 'version'"},
+		{`component.name > 1`, "found no matching overload for '_>_'"},
+		{`component.name.startsWith(1)`, "found no matching overload for 'startsWith'"},
+		{`component.name`, "yields string, expected bool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			rule := Rule{Name: "test", Expression: tt.expression, Severity: "Low"}
+			err := rule.Compile()
+			assert.ErrorContains(t, err, "invalid expression")
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	_, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read rules file")
+
+	_, err = LoadFile(writeRules(t, "rules: [\n"))
+	assert.ErrorContains(t, err, "failed to parse rules file")
+
+	_, err = LoadFile(writeRules(t, "rules:\n  - name: bad\n    expression: component.name ==\n    severity: High\n"))
+	assert.ErrorContains(t, err, "invalid rule 1: invalid expression")
+
+	_, err = LoadFile(writeRules(t, "rules:\n  - name: loud\n    expression: \"true\"\n    severity: Urgent\n"))
+	assert.ErrorContains(t, err, `severity must be Critical, High, Medium, Low or Info, got "Urgent"`)
+
+	_, err = LoadFile(writeRules(t, "rules:\n  - expression: \"true\"\n    severity: Low\n"))
+	assert.ErrorContains(t, err, "name is required")
+}
+
+func TestReloadAgent(t *testing.T) {
+	path := writeRules(t, testRules)
+	t.Setenv("SENTINEL_RULES_FILE", path)
+
+	agent, err := ReloadAgent()
+	require.NoError(t, err)
+	require.NotNil(t, agent)
+	assert.Len(t, agent.Rules(), 2)
+	assert.Same(t, agent, AgentFromEnv())
+
+	// A broken rules file keeps the current agent
+	require.NoError(t, os.WriteFile(path, []byte("rules: [\n"), 0o644))
+	current, err := ReloadAgent()
+	assert.ErrorContains(t, err, "failed to parse rules file")
+	assert.Same(t, agent, current)
+	assert.Same(t, agent, AgentFromEnv())
+
+	t.Setenv("SENTINEL_RULES_FILE", "")
+	agent, err = ReloadAgent()
+	require.NoError(t, err)
+	assert.Nil(t, agent)
+}
@@ -0,0 +1,250 @@
+// Package schema provides strict validation of SBOM documents against the
+// JSON Schemas of their format, CycloneDX or SPDX, reporting every violation
+// by its JSON Pointer path. It is separate from ingestion, which accepts any
+// document it can make sense of.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// bundled holds the schemas used when no official schema file is provided.
+// Official schema files placed in schemas/ under their published names, such
+// as bom-1.6.schema.json, are embedded too and preferred for their version.
+//
+//go:embed schemas/*.json
+var bundled embed.FS
+
+// Bundled schema files.
+const (
+	bundledCycloneDX = "sentinel-cyclonedx.schema.json"
+	bundledSPDX      = "sentinel-spdx.schema.json"
+)
+
+// Result is the outcome of validating a document.
+type Result struct {
+	// Format is the document's format and version, e.g. "CycloneDX 1.6" or "SPDX 2.3"
+	Format string `json:"format"`
+	// Schema is the schema file the document was validated against
+	Schema     string                `json:"schema"`
+	Violations []ingestion.Violation `json:"violations"`
+}
+
+// Valid reports whether the document follows its schema.
+func (r Result) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// Validator validates SBOM documents against JSON Schemas. Official schema
+// files loaded with LoadDir take precedence over the bundled schemas, which
+// cover the structure of the CycloneDX 1.2-1.6 and SPDX 2.2-2.3 schemas.
+type Validator struct {
+	// files holds the decoded schema documents by file name, for resolving
+	// references between them
+	files map[string]interface{}
+	mu    sync.Mutex
+	// compiled caches the compiled schemas by file name
+	compiled map[string]*jsonschema.Schema
+}
+
+// NewValidator creates a validator with the bundled schemas.
+func NewValidator() *Validator {
+	v := &Validator{files: make(map[string]interface{}), compiled: make(map[string]*jsonschema.Schema)}
+	entries, _ := bundled.ReadDir("schemas")
+	for _, entry := range entries {
+		data, _ := bundled.ReadFile("schemas/" + entry.Name())
+		if err := v.add(entry.Name(), data); err != nil {
+			panic(fmt.Sprintf("bundled schema %s: %v", entry.Name(), err))
+		}
+	}
+	return v
+}
+
+// FromEnv creates a validator that also uses the official schema files in
+// SENTINEL_SCHEMA_DIR, if set. A directory that cannot be loaded is
+// reported and the bundled schemas are used.
+func FromEnv() (*Validator, error) {
+	v := NewValidator()
+	if dir := os.Getenv("SENTINEL_SCHEMA_DIR"); dir != "" {
+		if err := v.LoadDir(dir); err != nil {
+			return NewValidator(), err
+		}
+	}
+	return v, nil
+}
+
+// LoadDir loads the JSON Schema files (*.json) of a directory, such as the
+// official bom-1.6.schema.json with the spdx.schema.json and
+// jsf-0.82.schema.json it references, or the SPDX spdx-schema-2.3.json.
+func (v *Validator) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list schemas in '%s': %w", dir, err)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no schema files (*.json) in '%s'", dir)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read schema '%s': %w", path, err)
+		}
+		if err := v.add(filepath.Base(path), data); err != nil {
+			return fmt.Errorf("invalid schema '%s': %w", path, err)
+		}
+	}
+	return nil
+}
+
+// add decodes a schema document.
+func (v *Validator) add(name string, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return err
+	}
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return errors.New("a schema must be a JSON object")
+	}
+	v.files[name] = doc
+	return nil
+}
+
+// Validate validates a CycloneDX or SPDX JSON document against the schema
+// of its format and version: the official schema file for the version if
+// one was loaded, otherwise the bundled schema. It returns an error if the
+// document is not JSON or of neither format.
+func (v *Validator) Validate(data []byte) (Result, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return Result{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	root, _ := doc.(map[string]interface{})
+	var result Result
+	var candidates []string
+	switch {
+	case root["bomFormat"] != nil:
+		version, _ := root["specVersion"].(string)
+		result.Format = strings.TrimSpace("CycloneDX " + version)
+		candidates = []string{"bom-" + version + ".schema.json", bundledCycloneDX}
+	case root["spdxVersion"] != nil:
+		version, _ := root["spdxVersion"].(string)
+		version = strings.TrimPrefix(version, "SPDX-")
+		result.Format = strings.TrimSpace("SPDX " + version)
+		candidates = []string{"spdx-schema-" + version + ".json", "spdx-schema.json", bundledSPDX}
+	default:
+		return Result{}, errors.New("unrecognized document: expected a CycloneDX (bomFormat) or SPDX (spdxVersion) JSON document")
+	}
+
+	for _, name := range candidates {
+		if _, ok := v.files[name]; ok {
+			result.Schema = name
+			break
+		}
+	}
+
+	compiled, err := v.compile(result.Schema)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid schema '%s': %w", result.Schema, err)
+	}
+	result.Violations = []ingestion.Violation{}
+	var validationErr *jsonschema.ValidationError
+	if err := compiled.Validate(doc); errors.As(err, &validationErr) {
+		result.Violations = violations(validationErr)
+	} else if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+// compile compiles the schema file name, resolving its references to the
+// other schema files by their $id or, without one, by their file name.
+func (v *Validator) compile(name string) (*jsonschema.Schema, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if compiled, ok := v.compiled[name]; ok {
+		return compiled, nil
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft7)
+	compiler.AssertFormat()
+	// References are only resolved against the loaded files
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	for file, doc := range v.files {
+		if err := compiler.AddResource(resourceURL(file, doc), doc); err != nil {
+			return nil, err
+		}
+	}
+	compiled, err := compiler.Compile(resourceURL(name, v.files[name]))
+	if err != nil {
+		return nil, err
+	}
+	v.compiled[name] = compiled
+	return compiled, nil
+}
+
+// resourceURL returns the URL a schema file is identified by: its absolute
+// $id, such as http://cyclonedx.org/schema/spdx.schema.json, or else its
+// file name under file:///schemas/.
+func resourceURL(name string, doc interface{}) string {
+	root, _ := doc.(map[string]interface{})
+	if id, ok := root["$id"].(string); ok {
+		if u, err := url.Parse(id); err == nil && u.IsAbs() {
+			u.Fragment = ""
+			return u.String()
+		}
+	}
+	return "file:///schemas/" + name
+}
+
+// violations lists the failed keywords of a validation error, the leaves of
+// its tree of causes, ordered by path.
+func violations(err *jsonschema.ValidationError) []ingestion.Violation {
+	printer := message.NewPrinter(language.English)
+	var found []ingestion.Violation
+	var collect func(err *jsonschema.ValidationError)
+	collect = func(err *jsonschema.ValidationError) {
+		if len(err.Causes) == 0 {
+			found = append(found, ingestion.Violation{Path: pointer(err.InstanceLocation), Message: err.ErrorKind.LocalizedString(printer)})
+			return
+		}
+		for _, cause := range err.Causes {
+			collect(cause)
+		}
+	}
+	collect(err)
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Path < found[j].Path })
+	return slices.Compact(found)
+}
+
+// pointer returns the JSON Pointer of an instance location.
+func pointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return b.String()
+}
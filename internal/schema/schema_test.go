@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validCycloneDX = `{
+	"bomFormat": "CycloneDX",
+	"specVersion": "1.6",
+	"serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+	"version": 1,
+	"metadata": {
+		"timestamp": "2024-01-01T00:00:00Z",
+		"tools": {"components": [{"type": "application", "name": "syft", "version": "1.0.0"}]},
+		"component": {"type": "container", "name": "alpine", "version": "3.19"}
+	},
+	"components": [
+		{
+			"type": "library",
+			"bom-ref": "pkg:npm/lodash@4.17.21",
+			"name": "lodash",
+			"version": "4.17.21",
+			"purl": "pkg:npm/lodash@4.17.21",
+			"licenses": [{"license": {"id": "MIT"}}],
+			"hashes": [{"alg": "SHA-1", "content": "679f4a5f9e5ca3f0e5a4b5a6d8c9f3e2a1b0c9d8"}]
+		},
+		{"type": "library", "name": "dual", "licenses": [{"expression": "MIT OR Apache-2.0"}]}
+	],
+	"dependencies": [{"ref": "pkg:npm/lodash@4.17.21", "dependsOn": []}]
+}`
+
+func TestValidator_Validate_CycloneDX(t *testing.T) {
+	result, err := NewValidator().Validate([]byte(validCycloneDX))
+	require.NoError(t, err)
+	assert.Equal(t, "CycloneDX 1.6", result.Format)
+	assert.Equal(t, "sentinel-cyclonedx.schema.json", result.Schema)
+	assert.True(t, result.Valid(), "%v", result.Violations)
+
+	// Lenient ingestion accepts all of these; the schema does not
+	result, err = NewValidator().Validate([]byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"serialNumber": "urn:uuid:test-12345",
+		"version": 1,
+		"components": [
+			{"type": "library", "name": "lodash", "version": "4.17.21", "x-internal": true},
+			{"type": "library", "name": "both", "licenses": [{"license": {"id": "MIT", "name": "MIT"}}]},
+			{"type": "library", "name": "hash", "hashes": [{"alg": "SHA-256", "content": "abc"}]},
+			{"name": "no-type"}
+		],
+		"extensions": {}
+	}`))
+	require.NoError(t, err)
+	assert.False(t, result.Valid())
+
+	assert.Equal(t, []ingestion.Violation{
+		{Path: "", Message: "additional properties 'extensions' not allowed"},
+		{Path: "/components/0", Message: "additional properties 'x-internal' not allowed"},
+		// Each branch of the licenses oneOf reports why it failed
+		{Path: "/components/1/licenses/0", Message: "missing property 'expression'"},
+		{Path: "/components/1/licenses/0", Message: "additional properties 'license' not allowed"},
+		{Path: "/components/1/licenses/0/license", Message: "'oneOf' failed, subschemas 0, 1 matched"},
+		{Path: "/components/2/hashes/0/content", Message: "'abc' does not match pattern '^([a-fA-F0-9]{32}|[a-fA-F0-9]{40}|[a-fA-F0-9]{64}|[a-fA-F0-9]{96}|[a-fA-F0-9]{128})$'"},
+		{Path: "/components/3", Message: "missing property 'type'"},
+		{Path: "/serialNumber", Message: "'urn:uuid:test-12345' does not match pattern '^urn:uuid:[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$'"},
+	}, result.Violations)
+}
+
+func TestValidator_Validate_SPDX(t *testing.T) {
+	result, err := NewValidator().Validate([]byte(`{
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"spdxVersion": "SPDX-2.3",
+		"dataLicense": "CC0-1.0",
+		"name": "payments-api",
+		"documentNamespace": "https://acme.example/spdx/payments-api-1.4.0",
+		"creationInfo": {"created": "2024-01-01T00:00:00Z", "creators": ["Tool: syft-1.0.0"]},
+		"packages": [
+			{"SPDXID": "SPDXRef-Package-lodash", "name": "lodash", "versionInfo": "4.17.21", "downloadLocation": "NOASSERTION"}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Package-lodash"}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "SPDX 2.3", result.Format)
+	assert.Equal(t, "sentinel-spdx.schema.json", result.Schema)
+	assert.True(t, result.Valid(), "%v", result.Violations)
+
+	result, err = NewValidator().Validate([]byte(`{
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"spdxVersion": "SPDX-2.3",
+		"dataLicense": "MIT",
+		"name": "payments-api",
+		"creationInfo": {"created": "yesterday", "creators": []},
+		"packages": [{"SPDXID": "lodash", "name": "lodash"}],
+		"relationships": [{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "USES", "relatedSpdxElement": "SPDXRef-Package-lodash"}]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, result.Violations, 7)
+	assert.Equal(t, []ingestion.Violation{
+		{Path: "", Message: "missing property 'documentNamespace'"},
+		{Path: "/creationInfo/created", Message: "'yesterday' is not valid date-time: less than 20 characters long"},
+		{Path: "/creationInfo/creators", Message: "minItems: got 0, want 1"},
+		{Path: "/dataLicense", Message: "value must be 'CC0-1.0'"},
+		{Path: "/packages/0", Message: "missing property 'downloadLocation'"},
+		{Path: "/packages/0/SPDXID", Message: "'lodash' does not match pattern '^(DocumentRef-[a-zA-Z0-9.-]+:)?SPDXRef-[a-zA-Z0-9.-]+$'"},
+	}, result.Violations[:6])
+	assert.Equal(t, "/relationships/0/relationshipType", result.Violations[6].Path)
+	assert.Contains(t, result.Violations[6].Message, "value must be one of 'VARIANT_OF', 'COPY_OF'")
+}
+
+func TestValidator_Validate_NotAnSBOM(t *testing.T) {
+	_, err := NewValidator().Validate([]byte(`{"name": "package.json"}`))
+	assert.ErrorContains(t, err, "unrecognized document")
+
+	_, err = NewValidator().Validate([]byte(`{"bomFormat": `))
+	assert.ErrorContains(t, err, "invalid JSON")
+}
+
+func TestValidator_LoadDir(t *testing.T) {
+	// Official schemas reference each other by file name, relative to their $id
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bom-1.6.schema.json"), []byte(`{
+		"$id": "http://cyclonedx.org/schema/bom-1.6.schema.json",
+		"type": "object",
+		"required": ["bomFormat", "metadata"],
+		"properties": {
+			"components": {"type": "array", "items": {"$ref": "#/definitions/component"}}
+		},
+		"definitions": {
+			"component": {
+				"type": "object",
+				"properties": {"licenses": {"type": "array", "items": {"$ref": "spdx.schema.json"}}}
+			}
+		}
+	}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spdx.schema.json"), []byte(`{"$id": "http://cyclonedx.org/schema/spdx.schema.json", "enum": ["MIT", "Apache-2.0"]}`), 0o644))
+
+	validator := NewValidator()
+	require.NoError(t, validator.LoadDir(dir))
+
+	result, err := validator.Validate([]byte(`{"bomFormat": "CycloneDX", "specVersion": "1.6", "components": [{"licenses": ["MIT", "WTFPL"]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "bom-1.6.schema.json", result.Schema)
+	assert.Equal(t, []ingestion.Violation{
+		{Path: "", Message: "missing property 'metadata'"},
+		{Path: "/components/0/licenses/1", Message: "value must be one of 'MIT', 'Apache-2.0'"},
+	}, result.Violations)
+
+	// Versions without an official schema fall back to the bundled one
+	result, err = validator.Validate([]byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "sentinel-cyclonedx.schema.json", result.Schema)
+
+	assert.ErrorContains(t, NewValidator().LoadDir(t.TempDir()), "no schema files")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("SENTINEL_SCHEMA_DIR", filepath.Join(t.TempDir(), "missing"))
+	validator, err := FromEnv()
+	assert.Error(t, err)
+	require.NotNil(t, validator)
+
+	result, err := validator.Validate([]byte(validCycloneDX))
+	require.NoError(t, err)
+	assert.True(t, result.Valid())
+}
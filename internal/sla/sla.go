@@ -0,0 +1,248 @@
+// Package sla provides remediation deadlines for findings by severity,
+// declared in the sla section of the configuration file in days:
+//
+//	sla:
+//	  critical: 7
+//	  high: 30
+//	  medium: 90
+//
+// A finding is due that many days after it was first seen in its current
+// lifecycle, and overdue while it is still open past its due date. Findings
+// of severities without an SLA, and findings triaged as false positives,
+// have no due date. A background monitor notifies each breach once.
+package sla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// AgentName identifies SLA breaches among the findings of notifications.
+const AgentName = "SLA Monitor"
+
+// DefaultInterval is how often the server checks for breaches when
+// SLA_CHECK_INTERVAL is not set.
+const DefaultInterval = time.Hour
+
+// Policy maps severities to the number of days within which findings of
+// that severity must be remediated.
+type Policy map[string]int
+
+// Validate checks that the policy names known severities and positive
+// numbers of days.
+func (p Policy) Validate() error {
+	var errs []error
+	for _, severity := range p.severities() {
+		if core.SeverityRank(severity) == 0 {
+			errs = append(errs, fmt.Errorf("%q is not a severity (expected critical, high, medium or low)", severity))
+		} else if p[severity] <= 0 {
+			errs = append(errs, fmt.Errorf("%s: days must be positive, got %d", severity, p[severity]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// severities returns the severities of the policy in sorted order.
+func (p Policy) severities() []string {
+	severities := make([]string, 0, len(p))
+	for severity := range p {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	return severities
+}
+
+// Days returns the days allowed to remediate findings of severity, or 0 if
+// the policy sets no SLA for it.
+func (p Policy) Days(severity string) int {
+	for key, days := range p {
+		if strings.EqualFold(key, severity) {
+			return days
+		}
+	}
+	return 0
+}
+
+// DueAt returns when finding must be remediated, or nil if it has no SLA.
+func (p Policy) DueAt(finding storage.FindingRecord) *time.Time {
+	days := p.Days(finding.Severity)
+	if days == 0 || finding.Triage == storage.TriageFalsePositive {
+		return nil
+	}
+	due := finding.FirstSeen.AddDate(0, 0, days)
+	return &due
+}
+
+// Annotate sets the due date of each finding, and marks those still open
+// past it at now as overdue.
+func (p Policy) Annotate(findings []storage.FindingRecord, now time.Time) {
+	for i := range findings {
+		findings[i].DueAt = p.DueAt(findings[i])
+		findings[i].Overdue = findings[i].DueAt != nil && findings[i].ResolvedAt == nil && now.After(*findings[i].DueAt)
+	}
+}
+
+// IntervalFromEnv returns how often the server checks for breaches, set by
+// SLA_CHECK_INTERVAL (a Go duration, default DefaultInterval). An invalid
+// value is reported and the default used.
+func IntervalFromEnv() (time.Duration, error) {
+	value := os.Getenv("SLA_CHECK_INTERVAL")
+	if value == "" {
+		return DefaultInterval, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return DefaultInterval, fmt.Errorf("invalid SLA_CHECK_INTERVAL %q", value)
+	}
+	return interval, nil
+}
+
+// Monitor notifies the findings that breach their SLA, once per finding
+// lifecycle.
+type Monitor struct {
+	records  storage.Pruner
+	history  storage.FindingHistory
+	breaches storage.SLABreaches
+	// policy and notifier are called per check so that configuration
+	// reloads apply; notifier returns nil if breaches are sent nowhere
+	policy   func() Policy
+	notifier func() notify.Notifier
+}
+
+// NewMonitor creates a monitor of the findings of the projects of the
+// stored SBOMs.
+func NewMonitor(records storage.Pruner, history storage.FindingHistory, breaches storage.SLABreaches, policy func() Policy, notifier func() notify.Notifier) *Monitor {
+	return &Monitor{records: records, history: history, breaches: breaches, policy: policy, notifier: notifier}
+}
+
+// Check sends a notification per project with the open findings that are
+// overdue at now and whose breach was not notified yet, marks them as
+// notified and returns their number. The findings of a project whose
+// notification fails are tried again by the next check.
+func (m *Monitor) Check(ctx context.Context, now time.Time) (int, error) {
+	policy, notifier := m.policy(), m.notifier()
+	if len(policy) == 0 || notifier == nil {
+		return 0, nil
+	}
+
+	records, err := m.records.ListRecords(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var projects []string
+	seen := make(map[string]bool)
+	for _, record := range records {
+		if !seen[record.Name] {
+			seen[record.Name] = true
+			projects = append(projects, record.Name)
+		}
+	}
+
+	notified := 0
+	var errs []error
+	for _, project := range projects {
+		findings, err := m.history.ProjectFindings(ctx, project)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+			continue
+		}
+		policy.Annotate(findings, now)
+
+		var breached []storage.FindingRecord
+		for _, finding := range findings {
+			if finding.Overdue && finding.SLANotifiedAt == nil {
+				breached = append(breached, finding)
+			}
+		}
+		if len(breached) == 0 {
+			continue
+		}
+
+		if err := notifier.Notify(ctx, Notification(project, breached, now)); err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+			continue
+		}
+		for _, finding := range breached {
+			if _, err := m.breaches.MarkSLANotified(ctx, project, finding.Fingerprint, now); err != nil {
+				errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+				continue
+			}
+			notified++
+		}
+	}
+	return notified, errors.Join(errs...)
+}
+
+// Start checks for breaches in the background every interval until ctx is
+// cancelled, logging what was notified.
+func (m *Monitor) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				notified, err := m.Check(ctx, core.Now())
+				if notified > 0 {
+					fmt.Printf("SLA: notified %d breached findings\n", notified)
+				}
+				if err != nil {
+					fmt.Printf("Warning: SLA breach notifications failed: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// Notification describes the breached findings of a project as findings of
+// the SLA monitor, which keep the severity of the breached finding. It
+// names the SBOM in which the findings were last seen.
+func Notification(project string, breached []storage.FindingRecord, now time.Time) notify.Notification {
+	notification := notify.Notification{Event: notify.EventSLABreached, SBOMName: project}
+	for _, finding := range breached {
+		if finding.LastSBOMID != "" {
+			notification.SBOMID = finding.LastSBOMID
+		}
+		result := core.AnalysisResult{
+			AgentName: AgentName,
+			Severity:  finding.Severity,
+			Finding: fmt.Sprintf("SLA breached %s ago (due %s): %s",
+				overdueFor(now.Sub(*finding.DueAt)), finding.DueAt.UTC().Format("2006-01-02"), finding.Finding),
+		}
+		if finding.Component != "" {
+			// Tracked findings name their component as "name version"
+			name, version := finding.Component, ""
+			if i := strings.LastIndex(name, " "); i > 0 {
+				name, version = name[:i], name[i+1:]
+			}
+			result.Component = &core.ComponentRef{Name: name, Version: version}
+		}
+		notification.Results = append(notification.Results, result)
+	}
+	return notification
+}
+
+// overdueFor describes how long a finding has been overdue, in days once
+// it is a day or more.
+func overdueFor(elapsed time.Duration) string {
+	if elapsed < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(elapsed.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(elapsed.Hours()/24))
+}
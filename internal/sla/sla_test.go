@@ -0,0 +1,145 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var firstSeen = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func TestPolicy_Annotate(t *testing.T) {
+	policy := Policy{"critical": 7, "High": 30}
+	resolvedAt := firstSeen.Add(24 * time.Hour)
+	findings := []storage.FindingRecord{
+		{Fingerprint: "overdue", Severity: "Critical", FirstSeen: firstSeen},
+		{Fingerprint: "due", Severity: "High", FirstSeen: firstSeen},
+		{Fingerprint: "resolved", Severity: "Critical", FirstSeen: firstSeen, ResolvedAt: &resolvedAt},
+		{Fingerprint: "false positive", Severity: "Critical", FirstSeen: firstSeen, Triage: storage.TriageFalsePositive},
+		{Fingerprint: "no SLA", Severity: "Low", FirstSeen: firstSeen},
+	}
+
+	policy.Annotate(findings, firstSeen.AddDate(0, 0, 10))
+	require.NotNil(t, findings[0].DueAt)
+	assert.Equal(t, firstSeen.AddDate(0, 0, 7), *findings[0].DueAt)
+	assert.True(t, findings[0].Overdue)
+	require.NotNil(t, findings[1].DueAt)
+	assert.Equal(t, firstSeen.AddDate(0, 0, 30), *findings[1].DueAt)
+	assert.False(t, findings[1].Overdue)
+	assert.NotNil(t, findings[2].DueAt)
+	assert.False(t, findings[2].Overdue, "resolved findings are not overdue")
+	assert.Nil(t, findings[3].DueAt)
+	assert.False(t, findings[3].Overdue)
+	assert.Nil(t, findings[4].DueAt)
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	require.NoError(t, Policy{"critical": 7, "Low": 180}.Validate())
+	require.NoError(t, Policy(nil).Validate())
+
+	err := Policy{"urgent": 1, "high": -1}.Validate()
+	assert.ErrorContains(t, err, `"urgent" is not a severity`)
+	assert.ErrorContains(t, err, "high: days must be positive, got -1")
+}
+
+// fakeNotifier records notifications, failing while err is set.
+type fakeNotifier struct {
+	notifications []notify.Notification
+	err           error
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.notifications = append(f.notifications, notification)
+	return nil
+}
+
+func TestMonitor_Check(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "api-1", Name: "api"}))
+	_, err := repo.RecordAnalysis(ctx, storage.AnalysisRun{SBOMID: "api-1", Project: "api", AnalyzedAt: firstSeen},
+		[]string{"Vulnerability Scanner"}, []storage.FindingRecord{
+			{Fingerprint: "a", AgentName: "Vulnerability Scanner", Severity: "Critical", Finding: "CVE-2021-44228", Component: "log4j-core 2.14.1"},
+			{Fingerprint: "b", AgentName: "Vulnerability Scanner", Severity: "High", Finding: "CVE-2022-0001"},
+		})
+	require.NoError(t, err)
+
+	notifier := &fakeNotifier{}
+	policy := Policy{"critical": 7, "high": 30}
+	monitor := NewMonitor(repo, repo, repo, func() Policy { return policy }, func() notify.Notifier { return notifier })
+
+	// Nothing is due yet
+	notified, err := monitor.Check(ctx, firstSeen.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Zero(t, notified)
+
+	// A failed notification is sent again by the next check
+	notifier.err = errors.New("webhook down")
+	_, err = monitor.Check(ctx, firstSeen.AddDate(0, 0, 9))
+	require.Error(t, err)
+	notifier.err = nil
+
+	notified, err = monitor.Check(ctx, firstSeen.AddDate(0, 0, 9))
+	require.NoError(t, err)
+	assert.Equal(t, 1, notified)
+	require.Len(t, notifier.notifications, 1)
+	notification := notifier.notifications[0]
+	assert.Equal(t, notify.EventSLABreached, notification.Event)
+	assert.Equal(t, "api", notification.SBOMName)
+	assert.Equal(t, "api-1", notification.SBOMID)
+	require.Len(t, notification.Results, 1)
+	result := notification.Results[0]
+	assert.Equal(t, AgentName, result.AgentName)
+	assert.Equal(t, "Critical", result.Severity)
+	assert.Equal(t, "SLA breached 2d ago (due 2024-06-08): CVE-2021-44228", result.Finding)
+	assert.Equal(t, &core.ComponentRef{Name: "log4j-core", Version: "2.14.1"}, result.Component)
+
+	// Each breach is notified once
+	notified, err = monitor.Check(ctx, firstSeen.AddDate(0, 0, 31))
+	require.NoError(t, err)
+	assert.Equal(t, 1, notified)
+	require.Len(t, notifier.notifications, 2)
+	assert.Equal(t, "SLA breached 1d ago (due 2024-07-01): CVE-2022-0001", notifier.notifications[1].Results[0].Finding)
+
+	notified, err = monitor.Check(ctx, firstSeen.AddDate(0, 0, 40))
+	require.NoError(t, err)
+	assert.Zero(t, notified)
+
+	findings, err := repo.ProjectFindings(ctx, "api")
+	require.NoError(t, err)
+	require.NotNil(t, findings[0].SLANotifiedAt)
+	assert.Equal(t, firstSeen.AddDate(0, 0, 9), *findings[0].SLANotifiedAt)
+
+	// Without an SLA or channels nothing is checked
+	policy = nil
+	notified, err = monitor.Check(ctx, firstSeen.AddDate(0, 0, 40))
+	require.NoError(t, err)
+	assert.Zero(t, notified)
+}
+
+func TestIntervalFromEnv(t *testing.T) {
+	interval, err := IntervalFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultInterval, interval)
+
+	t.Setenv("SLA_CHECK_INTERVAL", "15m")
+	interval, err = IntervalFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, interval)
+
+	t.Setenv("SLA_CHECK_INTERVAL", "soon")
+	interval, err = IntervalFromEnv()
+	assert.Error(t, err)
+	assert.Equal(t, DefaultInterval, interval)
+}
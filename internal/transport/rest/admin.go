@@ -0,0 +1,286 @@
+// Package rest provides the admin endpoints managing the waivers and API
+// keys of the configuration file.
+package rest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ignore"
+	"gopkg.in/yaml.v3"
+)
+
+// WaiversRequest represents the JSON body replacing the waivers.
+type WaiversRequest struct {
+	Waivers []ignore.Waiver `json:"waivers"`
+}
+
+// WaiversResponse represents the JSON response listing the waivers of the
+// configuration.
+type WaiversResponse struct {
+	TotalWaivers int             `json:"total_waivers"`
+	Waivers      []ignore.Waiver `json:"waivers"`
+}
+
+// APIKeyRotationResponse represents the JSON response of an API key
+// rotation. The new key is only ever shown in this response.
+type APIKeyRotationResponse struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	Key  string `json:"key"`
+}
+
+// WaiversHandler creates an HTTP handler for the waivers of the
+// configuration file:
+//
+//	GET  /api/v1/admin/waivers - list waivers
+//	POST /api/v1/admin/waivers - add a waiver (JSON body with "match", "reason" and "projects")
+//	PUT  /api/v1/admin/waivers - replace the waivers (JSON body with "waivers")
+//
+// Changes are written to the configuration file and take effect at once;
+// the rest of the file is kept as it is. Invalid waivers are rejected with
+// 422 Unprocessable Entity.
+func WaiversHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var waivers []ignore.Waiver
+		status := http.StatusOK
+		switch r.Method {
+		case http.MethodGet:
+			writeJSONResponse(w, http.StatusOK, newWaiversResponse(config.Default().Waivers))
+			return
+
+		case http.MethodPost:
+			var waiver ignore.Waiver
+			if err := json.NewDecoder(r.Body).Decode(&waiver); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_json", fmt.Sprintf("Failed to parse waiver: %v", err))
+				return
+			}
+			waivers = []ignore.Waiver{waiver}
+			status = http.StatusCreated
+
+		case http.MethodPut:
+			var request WaiversRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_json", fmt.Sprintf("Failed to parse waivers: %v", err))
+				return
+			}
+			waivers = request.Waivers
+
+		default:
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET, POST and PUT methods are allowed")
+			return
+		}
+
+		for i, waiver := range waivers {
+			if _, err := waiver.Entry(); err != nil {
+				writeErrorResponse(w, http.StatusUnprocessableEntity, "invalid_waiver", fmt.Sprintf("Invalid waiver %d: %v", i+1, err))
+				return
+			}
+		}
+		if configSetByEnv(w) {
+			return
+		}
+
+		configMu.Lock()
+		defer configMu.Unlock()
+
+		document, err := readConfigDocument()
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "config_error", err.Error())
+			return
+		}
+		if r.Method == http.MethodPost {
+			// Added to the waivers of the file, not to those loaded, which
+			// may be older
+			var current []ignore.Waiver
+			if section := configSection(document, "waivers"); section != nil {
+				if err := section.Decode(&current); err != nil {
+					writeErrorResponse(w, http.StatusInternalServerError, "config_error", fmt.Sprintf("Failed to parse waivers of configuration file: %v", err))
+					return
+				}
+			}
+			waivers = append(current, waivers...)
+		}
+		var section yaml.Node
+		if err := section.Encode(waivers); err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "config_error", fmt.Sprintf("Failed to encode waivers: %v", err))
+			return
+		}
+		setConfigSection(document, "waivers", &section)
+		if _, ok := applyConfigDocument(w, document); !ok {
+			return
+		}
+
+		writeJSONResponse(w, status, newWaiversResponse(config.Default().Waivers))
+	}
+}
+
+// newWaiversResponse lists waivers, as an empty list if there are none.
+func newWaiversResponse(waivers []ignore.Waiver) WaiversResponse {
+	if waivers == nil {
+		waivers = []ignore.Waiver{}
+	}
+	return WaiversResponse{TotalWaivers: len(waivers), Waivers: waivers}
+}
+
+// RotateAPIKeyHandler creates an HTTP handler for POST
+// /api/v1/admin/api-keys/{name}/rotate, which replaces the named API key of
+// the configuration file with a new random key and responds with it. The
+// old key is revoked at once. Keys given in API_KEYS, or in the file as a
+// reference to an environment variable, are rotated where they are set and
+// are rejected with 409 Conflict.
+func RotateAPIKeyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodPost {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only POST method is allowed")
+			return
+		}
+		if configSetByEnv(w) {
+			return
+		}
+
+		configMu.Lock()
+		defer configMu.Unlock()
+
+		document, err := readConfigDocument()
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "config_error", err.Error())
+			return
+		}
+
+		name := r.PathValue("name")
+		var entry *yaml.Node
+		if keys := configSection(document, "api_keys"); keys != nil && keys.Kind == yaml.SequenceNode {
+			for i, key := range keys.Content {
+				// Keys without a name are named as the configuration names them
+				keyName := fmt.Sprintf("key-%d", i+1)
+				if value := mappingValue(key, "name"); value != nil && value.Value != "" {
+					keyName = value.Value
+				}
+				if keyName == name {
+					entry = key
+					break
+				}
+			}
+		}
+		if entry == nil {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "API key not found in the configuration file")
+			return
+		}
+
+		key := mappingValue(entry, "key")
+		if key != nil && strings.Contains(key.Value, "$") {
+			writeErrorResponse(w, http.StatusConflict, "conflict", fmt.Sprintf("The key is set by %s and must be rotated there", key.Value))
+			return
+		}
+		rotated := rand.Text()
+		if key != nil {
+			// Changed in place, keeping its comments
+			key.Kind, key.Tag, key.Style, key.Value = yaml.ScalarNode, "!!str", 0, rotated
+		} else {
+			setMappingValue(entry, "key", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: rotated})
+		}
+		if _, ok := applyConfigDocument(w, document); !ok {
+			return
+		}
+
+		response := APIKeyRotationResponse{Name: name, Key: rotated}
+		if role := mappingValue(entry, "role"); role != nil {
+			response.Role = role.Value
+		}
+		writeJSONResponse(w, http.StatusOK, response)
+	}
+}
+
+// configSetByEnv writes 409 Conflict and returns true if the configuration
+// is given in $SENTINEL_CONFIG_YAML, so that there is no file to change.
+func configSetByEnv(w http.ResponseWriter) bool {
+	if os.Getenv(config.EnvYAML) == "" {
+		return false
+	}
+	writeErrorResponse(w, http.StatusConflict, "conflict", fmt.Sprintf("The configuration is set by $%s and cannot be changed", config.EnvYAML))
+	return true
+}
+
+// readConfigDocument reads the configuration file as a YAML document, so
+// that an endpoint can change one section and keep the comments of the
+// rest. A missing file reads as an empty document.
+func readConfigDocument() (*yaml.Node, error) {
+	data, err := os.ReadFile(config.Path())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("failed to parse configuration file: not a mapping")
+	}
+	return &document, nil
+}
+
+// applyConfigDocument encodes document and applies it like a PUT of the
+// configuration file. The caller holds configMu.
+func applyConfigDocument(w http.ResponseWriter, document *yaml.Node) (ReloadResponse, bool) {
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "config_error", fmt.Sprintf("Failed to encode configuration file: %v", err))
+		return ReloadResponse{}, false
+	}
+	return applyConfig(w, data.Bytes())
+}
+
+// configSection returns the value of the named top-level section of a
+// configuration document, or nil if it has none.
+func configSection(document *yaml.Node, name string) *yaml.Node {
+	return mappingValue(document.Content[0], name)
+}
+
+// setConfigSection replaces the value of the named top-level section of a
+// configuration document, adding the section if it has none.
+func setConfigSection(document *yaml.Node, name string, value *yaml.Node) {
+	setMappingValue(document.Content[0], name, value)
+}
+
+// mappingValue returns the value of key in a YAML mapping, or nil if the
+// node is not a mapping or has no such key.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of key in a YAML mapping, adding the
+// key if it is missing.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
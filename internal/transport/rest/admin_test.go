@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaiversHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel.yaml")
+	configtest.SetFile(t, path)
+	require.NoError(t, os.WriteFile(path, []byte("# Payments projects\nprojects:\n  payments-api:\n    distribution: saas\n"), 0o600))
+	_, err := config.Reload()
+	require.NoError(t, err)
+	handler := WaiversHandler()
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(method, "/api/v1/admin/waivers", strings.NewReader(body)))
+		return rr
+	}
+	list := func(rr *httptest.ResponseRecorder) WaiversResponse {
+		var response WaiversResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response
+	}
+
+	rr := serve("GET", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"total_waivers": 0, "waivers": []}`, rr.Body.String())
+
+	rr = serve("POST", `{"match": "vuln:CVE-2021-44228", "reason": "JNDI lookups are disabled", "projects": ["payments-api"]}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	rr = serve("POST", `{"match": "component:left-pad", "reason": "Vendored"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	response := list(rr)
+	assert.Equal(t, 2, response.TotalWaivers)
+	assert.Equal(t, []string{"payments-api"}, response.Waivers[0].Projects)
+	assert.Len(t, config.Default().Waivers, 2)
+
+	// The rest of the file is kept
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Payments projects")
+	assert.Contains(t, string(data), "match: vuln:CVE-2021-44228")
+	assert.Len(t, config.Default().Projects, 1)
+
+	rr = serve("PUT", `{"waivers": [{"match": "rule:Registry Allowlist", "reason": "Mirror"}]}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "rule:Registry Allowlist", list(rr).Waivers[0].Match)
+	assert.Len(t, config.Default().Waivers, 1)
+
+	// Invalid waivers are rejected and the current ones kept
+	rr = serve("POST", `{"match": "vuln:CVE-2024-0001"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "reason is required")
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", "not json").Code)
+	assert.Len(t, config.Default().Waivers, 1)
+
+	assert.Equal(t, http.StatusOK, serve("PUT", `{"waivers": []}`).Code)
+	assert.Empty(t, config.Default().Waivers)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve("DELETE", "").Code)
+
+	t.Setenv(config.EnvYAML, "profiles: {}\n")
+	assert.Equal(t, http.StatusConflict, serve("POST", `{"match": "component:left-pad", "reason": "Vendored"}`).Code)
+}
+
+func TestRotateAPIKeyHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel.yaml")
+	configtest.SetFile(t, path)
+	t.Setenv("API_KEYS", "")
+	t.Setenv("OPS_API_KEY", "k3y-for-ops")
+	document := "api_keys:\n  - name: ci\n    key: k3y-for-ci # rotated quarterly\n    role: analyst\n  - name: ops\n    key: ${OPS_API_KEY}\n    role: admin\n  - key: k3y-for-dashboard\n    role: viewer\n"
+	require.NoError(t, os.WriteFile(path, []byte(document), 0o600))
+	_, err := config.Reload()
+	require.NoError(t, err)
+
+	auth, err := AuthorizerFromEnv()
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/admin/api-keys/{name}/rotate", auth.Require(RoleAdmin, RotateAPIKeyHandler()))
+	rotate := func(name, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/admin/api-keys/"+name+"/rotate", nil)
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	role := func(key string) Role {
+		req := httptest.NewRequest("GET", "/api/v1/sboms", nil)
+		req.Header.Set("X-API-Key", key)
+		role, _ := auth.authenticate(req)
+		return role
+	}
+
+	// Only admins may rotate keys
+	assert.Equal(t, http.StatusForbidden, rotate("ci", "k3y-for-ci").Code)
+
+	rr := rotate("ci", "k3y-for-ops")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response APIKeyRotationResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "ci", response.Name)
+	assert.Equal(t, "analyst", response.Role)
+	assert.NotEmpty(t, response.Key)
+
+	// The old key is revoked at once
+	assert.Zero(t, role("k3y-for-ci"))
+	assert.Equal(t, RoleAnalyst, role(response.Key))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# rotated quarterly")
+	assert.Contains(t, string(data), "${OPS_API_KEY}")
+
+	// Keys without a name are named by their position
+	rr = rotate("key-3", "k3y-for-ops")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Zero(t, role("k3y-for-dashboard"))
+
+	assert.Equal(t, http.StatusConflict, rotate("ops", "k3y-for-ops").Code)
+	assert.Equal(t, http.StatusNotFound, rotate("deploy", "k3y-for-ops").Code)
+	assert.Equal(t, RoleAdmin, role("k3y-for-ops"))
+}
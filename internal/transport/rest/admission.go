@@ -0,0 +1,157 @@
+// Package rest provides the Kubernetes validating admission webhook, which
+// rejects workloads whose container images violate the gate policies.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/admission"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+)
+
+// maxAdmissionReviewSize caps the size of an AdmissionReview request; the
+// API server sends objects of at most a few megabytes.
+const maxAdmissionReviewSize = 8 << 20
+
+// AdmissionHandler creates the handler of the validating admission webhook.
+// For every container image of an admitted Pod, workload or CronJob it
+// finds the image's SBOM, analyzes it with the agents of settings.Profile
+// and evaluates the gate policies, with the admitted workload in the
+// policy input's "admission" field. The request is denied if an image
+// violates a policy, has findings at or above settings.FailOn, or, with
+// settings.RequireSBOM, has no SBOM; images without an SBOM are otherwise
+// admitted with a warning.
+//
+// Problems decoding the AdmissionReview are answered with 400 Bad Request,
+// so that the webhook's failurePolicy applies.
+func AdmissionHandler(resolver *admission.Resolver, intelligence *vectordb.IntelligenceStore, settings admission.Settings) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only POST method is allowed")
+			return
+		}
+
+		var review admission.Review
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxAdmissionReviewSize)).Decode(&review); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_review", fmt.Sprintf("Failed to decode AdmissionReview: %v", err))
+			return
+		}
+		if review.Request == nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_review", "AdmissionReview has no request")
+			return
+		}
+		containers, err := admission.Containers(review.Request.Object)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_review", err.Error())
+			return
+		}
+
+		response := reviewContainers(r.Context(), review.Request, containers, resolver, intelligence, settings)
+		apiVersion := review.APIVersion
+		if apiVersion == "" {
+			apiVersion = admission.APIVersion
+		}
+		w.Header().Set("Content-Type", "application/json")
+		writeJSONResponse(w, http.StatusOK, admission.Review{APIVersion: apiVersion, Kind: admission.Kind, Response: response})
+	}
+}
+
+// reviewContainers decides on the admission of the containers of a request.
+func reviewContainers(ctx context.Context, request *admission.Request, containers []admission.Container, resolver *admission.Resolver, intelligence *vectordb.IntelligenceStore, settings admission.Settings) *admission.Response {
+	response := &admission.Response{UID: request.UID, Allowed: true}
+	if len(containers) == 0 {
+		return response
+	}
+
+	var denials []string
+	deny := func(format string, args ...interface{}) {
+		denials = append(denials, fmt.Sprintf(format, args...))
+	}
+
+	query := url.Values{}
+	if settings.Profile != "" {
+		query.Set("profile", settings.Profile)
+	}
+	orchestrator, err := selectAgents(query, intelligence)
+	if err != nil {
+		// A profile removed from the reloaded configuration
+		deny("ADMISSION_PROFILE: %v", err)
+		containers = nil
+	}
+
+	// Containers running the same image are checked once
+	checked := make(map[string]bool)
+	for _, container := range containers {
+		if checked[container.Image] {
+			continue
+		}
+		checked[container.Image] = true
+
+		resolved, err := resolver.Resolve(ctx, container.Image)
+		if err == nil && resolved == nil {
+			err = fmt.Errorf("no SBOM found")
+		}
+		if err != nil {
+			if settings.RequireSBOM {
+				deny("%s: %v", container.Image, err)
+			} else {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("%s not checked by SBOM Sentinel: %v", container.Image, err))
+			}
+			continue
+		}
+
+		// Policies see the tags of the image's project too
+		sbom := *resolved.SBOM
+		sbom.Tags = sbom.EffectiveTags(config.Default().Projects)
+		report, err := orchestrator.Run(ctx, sbom)
+		if err != nil {
+			deny("%s: analysis failed: %v", container.Image, err)
+			continue
+		}
+		report.Results, _ = waiveFindings(sbom, report.Results)
+		report.Results = config.Default().Owners.Assign(report.Results)
+
+		if gate := gatePolicy(); gate != nil {
+			input := policy.NewInput(sbom, report.Results, report.AgentStatus())
+			input.Admission = &policy.InputAdmission{
+				Namespace: request.Namespace,
+				Kind:      request.Kind.Kind,
+				Name:      request.Name,
+				Operation: request.Operation,
+				Container: container.Name,
+				Image:     container.Image,
+			}
+			result := evaluatePolicy(ctx, gate, input)
+			if result.Error != "" {
+				deny("%s: policies could not be evaluated: %s", container.Image, result.Error)
+			}
+			for _, violation := range result.Violations {
+				deny("%s: %s", container.Image, violation)
+			}
+		}
+
+		if settings.FailOn != "" {
+			if failing := core.FilterBySeverity(report.Results, settings.FailOn); len(failing) > 0 {
+				deny("%s: %d findings at or above %s", container.Image, len(failing), settings.FailOn)
+			}
+		}
+	}
+
+	if len(denials) > 0 {
+		response.Allowed = false
+		response.Status = &admission.Status{
+			Code:    http.StatusForbidden,
+			Message: "SBOM Sentinel denied the request: " + strings.Join(denials, "; "),
+		}
+	}
+	return response
+}
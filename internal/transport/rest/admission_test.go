@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/admission"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cleanDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	agplDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// postAdmissionReview sends an AdmissionReview for a Deployment running
+// images to the admission handler and returns its response.
+func postAdmissionReview(t *testing.T, settings admission.Settings, images ...string) *admission.Response {
+	t.Helper()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindAll", mock.Anything).Return([]core.SBOM{
+		{ID: "clean", Name: "app", Metadata: map[string]string{"componentVersion": cleanDigest},
+			Components: []core.Component{{Name: "mit-component", Version: "1.0.0", License: "MIT"}}},
+		{ID: "agpl", Name: "worker", Metadata: map[string]string{"componentVersion": agplDigest},
+			Components: []core.Component{{Name: "agpl-component", Version: "1.0.0", License: "AGPL-3.0-only"}}},
+	}, nil)
+
+	var containers []admission.Container
+	for _, image := range images {
+		containers = append(containers, admission.Container{Name: "c", Image: image})
+	}
+	object, err := json.Marshal(map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers}}},
+	})
+	require.NoError(t, err)
+	body, err := json.Marshal(admission.Review{
+		APIVersion: admission.APIVersion,
+		Kind:       admission.Kind,
+		Request: &admission.Request{
+			UID:       "705ab4f5-6393-11e8-b7cc-42010a800002",
+			Kind:      admission.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Namespace: "shop",
+			Name:      "storefront",
+			Operation: "CREATE",
+			Object:    object,
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	AdmissionHandler(admission.NewResolver(mockRepo, nil), nil, settings).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var review admission.Review
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &review))
+	assert.Equal(t, admission.Kind, review.Kind)
+	require.NotNil(t, review.Response)
+	assert.Equal(t, "705ab4f5-6393-11e8-b7cc-42010a800002", review.Response.UID)
+	return review.Response
+}
+
+// admissionGate denies Critical findings outside the "sandbox" namespace.
+type admissionGate struct{}
+
+func (admissionGate) Evaluate(ctx context.Context, input policy.Input) (*policy.Decision, error) {
+	if input.Admission != nil && input.Admission.Namespace == "sandbox" {
+		return &policy.Decision{Violations: []string{}}, nil
+	}
+	return fakeGate{}.Evaluate(ctx, input)
+}
+
+func TestAdmissionHandler_Policy(t *testing.T) {
+	original := gatePolicy
+	gatePolicy = func() policy.Evaluator { return admissionGate{} }
+	t.Cleanup(func() { gatePolicy = original })
+
+	response := postAdmissionReview(t, admission.Settings{}, "ghcr.io/acme/app@"+cleanDigest)
+	assert.True(t, response.Allowed)
+	assert.Empty(t, response.Warnings)
+
+	response = postAdmissionReview(t, admission.Settings{}, "ghcr.io/acme/app@"+cleanDigest, "ghcr.io/acme/worker@"+agplDigest)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Status)
+	assert.Equal(t, http.StatusForbidden, response.Status.Code)
+	assert.Equal(t, "SBOM Sentinel denied the request: ghcr.io/acme/worker@"+agplDigest+": critical finding in agpl-component", response.Status.Message)
+}
+
+func TestAdmissionHandler_MissingSBOM(t *testing.T) {
+	original := gatePolicy
+	gatePolicy = func() policy.Evaluator { return nil }
+	t.Cleanup(func() { gatePolicy = original })
+
+	// Images without an SBOM are admitted with a warning
+	response := postAdmissionReview(t, admission.Settings{}, "ghcr.io/acme/unknown:1.0")
+	assert.True(t, response.Allowed)
+	assert.Equal(t, []string{"ghcr.io/acme/unknown:1.0 not checked by SBOM Sentinel: image ghcr.io/acme/unknown:1.0 is not referenced by digest"}, response.Warnings)
+
+	response = postAdmissionReview(t, admission.Settings{RequireSBOM: true}, "ghcr.io/acme/unknown@"+cleanDigest[:len(cleanDigest)-1]+"0")
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Status.Message, "no SBOM found")
+}
+
+func TestAdmissionHandler_FailOn(t *testing.T) {
+	original := gatePolicy
+	gatePolicy = func() policy.Evaluator { return nil }
+	t.Cleanup(func() { gatePolicy = original })
+
+	response := postAdmissionReview(t, admission.Settings{FailOn: "high"}, "ghcr.io/acme/worker@"+agplDigest)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Status.Message, "1 findings at or above high")
+}
+
+func TestAdmissionHandler_InvalidReview(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader([]byte(`{"apiVersion":"admission.k8s.io/v1"}`)))
+	rr := httptest.NewRecorder()
+	AdmissionHandler(admission.NewResolver(new(MockRepository), nil), nil, admission.Settings{}).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,211 @@
+// Package rest provides API key authentication and role-based authorization
+// for the REST API.
+package rest
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+)
+
+// Role is the level of access granted to an API key. Each role includes the
+// permissions of the roles below it.
+type Role int
+
+const (
+	// RoleViewer may read SBOMs, findings and corpus status
+	RoleViewer Role = iota + 1
+	// RoleAnalyst may additionally submit and analyze SBOMs and add intelligence
+	RoleAnalyst
+	// RoleAdmin may additionally perform destructive operations
+	RoleAdmin
+)
+
+// String returns the name of the role as used in API_KEYS.
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleAnalyst:
+		return "analyst"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return fmt.Sprintf("Role(%d)", int(r))
+	}
+}
+
+// ParseRole parses a role name: "viewer", "analyst" or "admin".
+func ParseRole(name string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "viewer":
+		return RoleViewer, nil
+	case "analyst":
+		return RoleAnalyst, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return 0, fmt.Errorf("unknown role %q (expected viewer, analyst or admin)", name)
+	}
+}
+
+// apiKey is a configured API key and the role it grants.
+type apiKey struct {
+	key  []byte
+	role Role
+}
+
+// Authorizer authenticates requests by API key and checks that the key's
+// role permits the operation. An Authorizer without keys allows every
+// request, so that deployments without API_KEYS keep working unchanged.
+type Authorizer struct {
+	keys []apiKey
+	// configured returns the keys of the configuration file, read for every
+	// request so that reloading the file adds and revokes keys
+	configured func() []config.APIKey
+}
+
+// NewAuthorizer creates an authorizer for the given API keys and their roles.
+func NewAuthorizer(keys map[string]Role) *Authorizer {
+	authorizer := &Authorizer{}
+	for key, role := range keys {
+		authorizer.keys = append(authorizer.keys, apiKey{key: []byte(key), role: role})
+	}
+	return authorizer
+}
+
+// AuthorizerFromEnv creates an authorizer for the keys in API_KEYS, a
+// comma-separated list of key:role entries such as
+// "k3y-for-ci:analyst,k3y-for-ops:admin", and the api_keys of the
+// configuration file.
+func AuthorizerFromEnv() (*Authorizer, error) {
+	keys := make(map[string]Role)
+	for _, entry := range strings.Split(os.Getenv("API_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		// Keys may contain colons, so the role follows the last one
+		separator := strings.LastIndex(entry, ":")
+		if separator <= 0 {
+			return nil, fmt.Errorf("invalid API_KEYS entry: expected key:role")
+		}
+		role, err := ParseRole(entry[separator+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid API_KEYS entry: %w", err)
+		}
+		keys[entry[:separator]] = role
+	}
+	authorizer := NewAuthorizer(keys)
+	authorizer.configured = func() []config.APIKey { return config.Default().APIKeys }
+	return authorizer, nil
+}
+
+// Enabled reports whether API keys are configured.
+func (a *Authorizer) Enabled() bool {
+	return len(a.keys) > 0 || len(a.configuredKeys()) > 0
+}
+
+// configuredKeys returns the keys of the configuration file.
+func (a *Authorizer) configuredKeys() []apiKey {
+	if a.configured == nil {
+		return nil
+	}
+	var keys []apiKey
+	for _, key := range a.configured() {
+		value := os.ExpandEnv(key.Key)
+		role, err := ParseRole(key.Role)
+		if value == "" || err != nil {
+			// The file was validated when loaded; a key whose variable
+			// was unset since is skipped
+			continue
+		}
+		keys = append(keys, apiKey{key: []byte(value), role: role})
+	}
+	return keys
+}
+
+// Require wraps next so that it is only served to API keys with at least the given role.
+func (a *Authorizer) Require(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return a.RequireByMethod(map[string]Role{"": role}, next)
+}
+
+// RequireByMethod wraps next so that each request method requires the given
+// role. The "" entry applies to methods not listed; without it, unlisted
+// methods require RoleAdmin.
+func (a *Authorizer) RequireByMethod(roles map[string]Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.Enabled() {
+			next(w, r)
+			return
+		}
+
+		required, ok := roles[r.Method]
+		if !ok {
+			required, ok = roles[""]
+		}
+		if !ok {
+			required = RoleAdmin
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		role, authenticated := a.authenticate(r)
+		if !authenticated {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sbom-sentinel"`)
+			writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "A valid API key is required in the Authorization or X-API-Key header")
+			return
+		}
+		if role < required {
+			writeErrorResponse(w, http.StatusForbidden, "forbidden", fmt.Sprintf("This operation requires the %s role", required))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// presentedKey returns the API key presented with the request, given as
+// "Authorization: Bearer <key>" or "X-API-Key: <key>", or "" if there is none.
+func presentedKey(r *http.Request) string {
+	if scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " "); found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// callerID identifies the caller of a request by a digest of its API key,
+// so that the key itself is never stored, or returns "" for requests made
+// without a key.
+func callerID(r *http.Request) string {
+	key := presentedKey(r)
+	if key == "" {
+		return ""
+	}
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
+
+// authenticate returns the role of the API key presented with the request.
+func (a *Authorizer) authenticate(r *http.Request) (Role, bool) {
+	presented := presentedKey(r)
+	if presented == "" {
+		return 0, false
+	}
+
+	// Compare against every key in constant time so that timing reveals nothing about them
+	var role Role
+	for _, key := range append(a.configuredKeys(), a.keys...) {
+		if subtle.ConstantTimeCompare([]byte(presented), key.key) == 1 {
+			role = key.role
+		}
+	}
+	return role, role != 0
+}
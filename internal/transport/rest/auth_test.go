@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestAuthorizer_RequireByMethod(t *testing.T) {
+	auth := NewAuthorizer(map[string]Role{
+		"viewer-key":  RoleViewer,
+		"analyst-key": RoleAnalyst,
+		"admin-key":   RoleAdmin,
+	})
+	handler := auth.RequireByMethod(map[string]Role{
+		http.MethodGet:    RoleViewer,
+		http.MethodPost:   RoleAnalyst,
+		http.MethodDelete: RoleAdmin,
+	}, okHandler)
+
+	tests := []struct {
+		name           string
+		method         string
+		header         string
+		key            string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"viewer can read", http.MethodGet, "Authorization", "Bearer viewer-key", http.StatusNoContent, ""},
+		{"viewer cannot submit", http.MethodPost, "Authorization", "Bearer viewer-key", http.StatusForbidden, "forbidden"},
+		{"analyst can submit", http.MethodPost, "X-API-Key", "analyst-key", http.StatusNoContent, ""},
+		{"analyst cannot delete", http.MethodDelete, "X-API-Key", "analyst-key", http.StatusForbidden, "forbidden"},
+		{"admin can delete", http.MethodDelete, "Authorization", "bearer admin-key", http.StatusNoContent, ""},
+		{"unlisted methods require admin", http.MethodPut, "X-API-Key", "analyst-key", http.StatusForbidden, "forbidden"},
+		{"missing key", http.MethodGet, "", "", http.StatusUnauthorized, "unauthorized"},
+		{"unknown key", http.MethodGet, "X-API-Key", "guessed-key", http.StatusUnauthorized, "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/intelligence/documents", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.key)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedError != "" {
+				var response ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedError, response.Error)
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Disabled(t *testing.T) {
+	// Without keys every request is served, preserving unauthenticated deployments
+	handler := NewAuthorizer(nil).Require(RoleAdmin, okHandler)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/intelligence/documents/x", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestAuthorizerFromEnv(t *testing.T) {
+	t.Setenv("API_KEYS", "ci:key:analyst, ops-key:ADMIN")
+	auth, err := AuthorizerFromEnv()
+	require.NoError(t, err)
+	assert.True(t, auth.Enabled())
+
+	// Keys may contain colons; the role follows the last one
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sboms", nil)
+	req.Header.Set("X-API-Key", "ci:key")
+	role, ok := auth.authenticate(req)
+	assert.True(t, ok)
+	assert.Equal(t, RoleAnalyst, role)
+
+	t.Setenv("API_KEYS", "")
+	auth, err = AuthorizerFromEnv()
+	require.NoError(t, err)
+	assert.False(t, auth.Enabled())
+
+	for _, value := range []string{"no-role", "key:superuser", ":admin"} {
+		t.Setenv("API_KEYS", value)
+		_, err = AuthorizerFromEnv()
+		assert.Error(t, err, value)
+	}
+}
+
+func TestAuthorizerFromEnv_ConfiguredKeys(t *testing.T) {
+	t.Setenv("API_KEYS", "")
+	t.Setenv("OPS_API_KEY", "ops-key")
+	configtest.Set(t, "api_keys:\n  - name: ops\n    key: ${OPS_API_KEY}\n    role: admin\n")
+
+	auth, err := AuthorizerFromEnv()
+	require.NoError(t, err)
+	assert.True(t, auth.Enabled())
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/watchlist/x", nil)
+	req.Header.Set("Authorization", "Bearer ops-key")
+	role, ok := auth.authenticate(req)
+	assert.True(t, ok)
+	assert.Equal(t, RoleAdmin, role)
+
+	// Reloading the configuration revokes the key
+	t.Setenv(config.EnvYAML, "profiles: {}\n")
+	_, err = config.Reload()
+	require.NoError(t, err)
+	_, ok = auth.authenticate(req)
+	assert.False(t, ok)
+	assert.False(t, auth.Enabled())
+}
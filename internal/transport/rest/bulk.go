@@ -0,0 +1,130 @@
+// Package rest provides HTTP handlers for bulk analysis across stored SBOMs.
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+)
+
+// BulkAnalysisEvent is a single line of the newline-delimited JSON stream
+// returned by the bulk analysis endpoint.
+type BulkAnalysisEvent struct {
+	// Type is "progress" for each analyzed SBOM and "summary" for the final rollup
+	Type     string           `json:"type"`
+	SBOMID   string           `json:"sbom_id,omitempty"`
+	Index    int              `json:"index,omitempty"`
+	Total    int              `json:"total"`
+	Findings int              `json:"findings,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Rollup   *analysis.Rollup `json:"rollup,omitempty"`
+	// Risk is the composite risk score of this SBOM's findings
+	Risk *analysis.RiskScore `json:"risk,omitempty"`
+	// AgentErrors lists the agents that failed or timed out for this SBOM
+	AgentErrors []AgentError `json:"agent_errors,omitempty"`
+	// AgentUsage is the wall time and LLM usage of each agent for this SBOM
+	AgentUsage map[string]AgentUsage `json:"agent_usage,omitempty"`
+	// AgentParameters records the effective settings of parameterized agents in the summary event
+	AgentParameters map[string]map[string]string `json:"agent_parameters,omitempty"`
+	// Components counts the components and unique components analyzed, in the summary event
+	Components *analysis.ComponentDedup `json:"components,omitempty"`
+}
+
+// BulkAnalyzeHandler creates an HTTP handler that analyzes every stored SBOM.
+// It expects a POST request to /api/v1/analyses/bulk and accepts the same
+// agent query parameters as the single SBOM analyze endpoint.
+// Progress is streamed as newline-delimited JSON, one event per SBOM,
+// followed by a summary event carrying the organization-wide rollup, in
+// which projects are ranked by risk score. Components shared by several SBOMs
+// are analyzed once by the agents that query an API or LLM per component.
+// Proactive scans search the shared intelligence corpus, as for AnalyzeSBOMHandler.
+func BulkAnalyzeHandler(repo storage.Repository, intelligence *vectordb.IntelligenceStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow POST requests
+		if r.Method != http.MethodPost {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only POST method is allowed")
+			return
+		}
+
+		orchestrator, err := selectAgents(r.URL.Query(), intelligence)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		sboms, err := repo.FindAll(ctx)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to retrieve SBOMs: %v", err))
+			return
+		}
+
+		orchestrator, dedup := orchestrator.Deduplicate(sboms)
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+
+		flusher, _ := w.(http.Flusher)
+		encoder := json.NewEncoder(w)
+		emit := func(event BulkAnalysisEvent) {
+			if err := encoder.Encode(event); err != nil {
+				// Log the error, but response has already been started
+				fmt.Printf("Error encoding bulk analysis event: %v\n", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		rollup := analysis.NewRollup()
+
+		for i, sbom := range sboms {
+			// Stop early if the client went away
+			if ctx.Err() != nil {
+				return
+			}
+
+			event := BulkAnalysisEvent{
+				Type:   "progress",
+				SBOMID: sbom.ID,
+				Index:  i + 1,
+				Total:  len(sboms),
+			}
+
+			report, err := orchestrator.Run(ctx, sbom)
+			if err != nil {
+				rollup.AddFailure()
+				event.Error = err.Error()
+			} else {
+				report.Results, _ = waiveFindings(sbom, report.Results)
+				rollup.Add(sbom.ID, report.Results)
+				rollup.AddProject(sbom.Name, sbom.ID, report.Results)
+				risk := analysis.ScoreRisk(report.Results)
+				event.Findings = len(report.Results)
+				event.Risk = &risk
+				event.AgentErrors = agentErrors(report)
+				event.AgentUsage = agentUsage(report)
+				if len(event.AgentErrors) > 0 {
+					rollup.AddFailure()
+				}
+			}
+
+			emit(event)
+		}
+
+		rollup.SortFindings()
+		emit(BulkAnalysisEvent{
+			Type:            "summary",
+			Total:           len(sboms),
+			Rollup:          rollup,
+			AgentParameters: orchestrator.Parameters(),
+			Components:      &dedup,
+		})
+	}
+}
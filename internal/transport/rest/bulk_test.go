@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkAnalyzeHandler(t *testing.T) {
+	t.Run("Streams progress and rollup", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("FindAll", mock.Anything).Return([]core.SBOM{
+			{ID: "sbom-1", Components: []core.Component{{Name: "gpl-lib", Version: "1.0.0", License: "GPL-3.0-only"}}},
+			{ID: "sbom-2", Components: []core.Component{{Name: "gpl-lib", Version: "1.0.0", License: "GPL-3.0-only"}}},
+			{ID: "sbom-3", Components: []core.Component{{Name: "mit-lib", Version: "1.0.0", License: "MIT"}}},
+		}, nil)
+
+		req := httptest.NewRequest("POST", "/api/v1/analyses/bulk", nil)
+		rr := httptest.NewRecorder()
+		BulkAnalyzeHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+		var events []BulkAnalysisEvent
+		scanner := bufio.NewScanner(strings.NewReader(rr.Body.String()))
+		for scanner.Scan() {
+			var event BulkAnalysisEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+			events = append(events, event)
+		}
+
+		require.Len(t, events, 4)
+		for i, event := range events[:3] {
+			assert.Equal(t, "progress", event.Type)
+			assert.Equal(t, i+1, event.Index)
+			assert.Equal(t, 3, event.Total)
+		}
+		assert.Equal(t, 1, events[0].Findings)
+		assert.Equal(t, 0, events[2].Findings)
+		require.NotNil(t, events[0].Risk)
+		assert.Equal(t, 11, events[0].Risk.Score)
+
+		summary := events[3]
+		assert.Equal(t, "summary", summary.Type)
+		require.NotNil(t, summary.Rollup)
+		assert.Equal(t, 3, summary.Rollup.SBOMsAnalyzed)
+		assert.Equal(t, 2, summary.Rollup.TotalFindings)
+		require.Len(t, summary.Rollup.Findings, 1)
+		assert.Equal(t, []string{"sbom-1", "sbom-2"}, summary.Rollup.Findings[0].SBOMIDs)
+		require.NotNil(t, summary.Components)
+		assert.Equal(t, 3, summary.Components.Components)
+		assert.Equal(t, 2, summary.Components.Unique)
+		// The SBOMs share a blank name, so the latest stands for the project
+		require.Len(t, summary.Rollup.Projects, 1)
+		assert.Equal(t, "sbom-3", summary.Rollup.Projects[0].SBOMID)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Storage error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("FindAll", mock.Anything).Return(nil, errors.New("database error"))
+
+		req := httptest.NewRequest("POST", "/api/v1/analyses/bulk", nil)
+		rr := httptest.NewRecorder()
+		BulkAnalyzeHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		var response ErrorResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "storage_error", response.Error)
+	})
+
+	t.Run("Wrong HTTP method", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/analyses/bulk", nil)
+		rr := httptest.NewRecorder()
+		BulkAnalyzeHandler(new(MockRepository), nil).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+}
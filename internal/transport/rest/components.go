@@ -0,0 +1,106 @@
+// Package rest provides HTTP handlers for the global component inventory.
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// ComponentInventoryResponse represents the JSON response for the component inventory.
+type ComponentInventoryResponse struct {
+	TotalComponents int                  `json:"total_components"`
+	TotalSBOMs      int                  `json:"total_sboms"`
+	Components      []core.InventoryItem `json:"components"`
+}
+
+// ListComponentsHandler creates an HTTP handler for the global component inventory.
+// It expects a GET request to /api/v1/components and aggregates unique components
+// across every stored SBOM. Optional query parameters filter the result:
+// ?license=MIT matches any declared license and ?ecosystem=npm matches the PURL type,
+// both case-insensitively, ?owner=payments matches the team owning the component
+// by the owners section of the configuration file, and ?tag=key=value, which may
+// be repeated, limits the inventory to the SBOMs with every given tag. Every
+// component names its owner, if any.
+func ListComponentsHandler(repo storage.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		filter, err := tagFilter(r.URL.Query())
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		sboms, err := repo.FindAll(ctx)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to retrieve SBOMs: %v", err))
+			return
+		}
+		cfg := config.Default()
+		if len(filter) > 0 {
+			projects := cfg.Projects
+			tagged := make([]core.SBOM, 0, len(sboms))
+			for _, sbom := range sboms {
+				if core.MatchesTags(sbom.EffectiveTags(projects), filter) {
+					tagged = append(tagged, sbom)
+				}
+			}
+			sboms = tagged
+		}
+
+		licenseFilter := r.URL.Query().Get("license")
+		ecosystemFilter := r.URL.Query().Get("ecosystem")
+		ownerFilter := r.URL.Query().Get("owner")
+
+		components := make([]core.InventoryItem, 0)
+		for _, item := range core.BuildInventory(sboms) {
+			if ecosystemFilter != "" && !strings.EqualFold(item.Ecosystem, ecosystemFilter) {
+				continue
+			}
+			if licenseFilter != "" && !containsFold(item.Licenses, licenseFilter) {
+				continue
+			}
+			item.Owner = cfg.Owners.Owner(core.ComponentRef{Name: item.Name, Version: item.Version, PURL: item.PURL})
+			if ownerFilter != "" && item.Owner != ownerFilter {
+				continue
+			}
+			components = append(components, item)
+		}
+
+		response := ComponentInventoryResponse{
+			TotalComponents: len(components),
+			TotalSBOMs:      len(sboms),
+			Components:      components,
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			// Log the error, but response has already been started
+			fmt.Printf("Error encoding response: %v\n", err)
+		}
+	}
+}
+
+// containsFold reports whether any value equals target, ignoring case.
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListComponentsHandler(t *testing.T) {
+	storedSBOMs := []core.SBOM{
+		{
+			ID: "sbom-1",
+			Components: []core.Component{
+				{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", License: "MIT"},
+				{Name: "requests", Version: "2.31.0", PURL: "pkg:pypi/requests@2.31.0", License: "Apache-2.0"},
+			},
+		},
+		{
+			ID: "sbom-2",
+			Components: []core.Component{
+				{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21", License: "MIT"},
+				{Name: "gpl-lib", Version: "1.0.0", PURL: "pkg:npm/gpl-lib@1.0.0", License: "GPL-3.0-only"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		query              string
+		mockBehavior       func(*MockRepository)
+		expectedStatusCode int
+		expectedNames      []string
+	}{
+		{
+			name:   "All components",
+			method: "GET",
+			mockBehavior: func(mockRepo *MockRepository) {
+				mockRepo.On("FindAll", mock.Anything).Return(storedSBOMs, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedNames:      []string{"lodash", "gpl-lib", "requests"},
+		},
+		{
+			name:   "Filter by ecosystem",
+			method: "GET",
+			query:  "?ecosystem=PyPI",
+			mockBehavior: func(mockRepo *MockRepository) {
+				mockRepo.On("FindAll", mock.Anything).Return(storedSBOMs, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedNames:      []string{"requests"},
+		},
+		{
+			name:   "Filter by license and ecosystem",
+			method: "GET",
+			query:  "?license=mit&ecosystem=npm",
+			mockBehavior: func(mockRepo *MockRepository) {
+				mockRepo.On("FindAll", mock.Anything).Return(storedSBOMs, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedNames:      []string{"lodash"},
+		},
+		{
+			name:   "Storage error",
+			method: "GET",
+			mockBehavior: func(mockRepo *MockRepository) {
+				mockRepo.On("FindAll", mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:               "Wrong HTTP method",
+			method:             "POST",
+			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			tt.mockBehavior(mockRepo)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/components"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			ListComponentsHandler(mockRepo).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+
+			if tt.expectedStatusCode == http.StatusOK {
+				var response ComponentInventoryResponse
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, 2, response.TotalSBOMs)
+				assert.Equal(t, len(tt.expectedNames), response.TotalComponents)
+
+				var names []string
+				for _, item := range response.Components {
+					names = append(names, item.Name)
+				}
+				assert.Equal(t, tt.expectedNames, names)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
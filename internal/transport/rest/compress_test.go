@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat(`{"name": "lodash", "version": "4.17.21"}`, 100)
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "4000")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, body)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/v1/sboms/1", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	assert.Empty(t, rr.Header().Get("Content-Length"))
+	assert.Less(t, rr.Body.Len(), len(body))
+
+	reader, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decompressed))
+
+	// Clients that do not accept gzip get the plain body
+	for _, acceptEncoding := range []string{"", "br", "gzip;q=0"} {
+		req := httptest.NewRequest("GET", "/api/v1/sboms/1", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Empty(t, rr.Header().Get("Content-Encoding"), acceptEncoding)
+		assert.Equal(t, body, rr.Body.String(), acceptEncoding)
+	}
+}
+
+func TestCompress_NoBody(t *testing.T) {
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("DELETE", "/api/v1/sboms/1", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.Zero(t, rr.Body.Len())
+}
+
+func TestCompress_Flush(t *testing.T) {
+	// Streamed events reach the client before the response ends
+	rr := httptest.NewRecorder()
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "{\"type\": \"progress\"}\n")
+		w.(http.Flusher).Flush()
+
+		reader, err := gzip.NewReader(bytes.NewReader(rr.Body.Bytes()))
+		require.NoError(t, err)
+		line := make([]byte, 21)
+		_, err = io.ReadFull(reader, line)
+		require.NoError(t, err)
+		assert.Equal(t, "{\"type\": \"progress\"}\n", string(line))
+	}))
+
+	req := httptest.NewRequest("POST", "/api/v1/analyses/bulk", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	handler.ServeHTTP(rr, req)
+	assert.True(t, rr.Flushed)
+}
@@ -0,0 +1,154 @@
+// Package rest provides the endpoint managing the server configuration file
+// as code.
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+)
+
+// maxConfigBytes bounds the size of an applied configuration file.
+const maxConfigBytes = 1 << 20
+
+// configMu serializes configuration updates, so that concurrent applies do
+// not interleave writing the file and reloading it.
+var configMu sync.Mutex
+
+// ConfigHandler creates an HTTP handler for the configuration file, so that
+// projects, policies, waivers, notification channels and API keys can be
+// managed as code with `sentinel-cli apply`. It expects requests to /api/v1/admin/config:
+//
+//   - GET returns the configuration file as YAML, or 404 Not Found if there is none
+//   - PUT validates the YAML request body, replaces the configuration file
+//     with it and reloads the configuration, responding like POST
+//     /api/v1/admin/reload; an invalid file is rejected with 422
+//     Unprocessable Entity and the current file kept
+//
+// A configuration given in $SENTINEL_CONFIG_YAML cannot be replaced.
+func ConfigHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			getConfig(w)
+		case http.MethodPut:
+			putConfig(w, r)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET and PUT methods are allowed")
+		}
+	}
+}
+
+// getConfig writes the configuration file in effect.
+func getConfig(w http.ResponseWriter) {
+	data := []byte(os.Getenv(config.EnvYAML))
+	if len(data) == 0 {
+		var err error
+		data, err = os.ReadFile(config.Path())
+		if errors.Is(err, os.ErrNotExist) {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "No configuration file")
+			return
+		}
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusInternalServerError, "config_error", fmt.Sprintf("Failed to read configuration file: %v", err))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// putConfig replaces the configuration file with the request body and
+// reloads the configuration.
+func putConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if configSetByEnv(w) {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeErrorResponse(w, http.StatusRequestEntityTooLarge, "payload_too_large", fmt.Sprintf("The configuration file exceeds %d bytes", maxConfigBytes))
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	response, ok := applyConfig(w, data)
+	if !ok {
+		return
+	}
+	response.Message = "Configuration applied"
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// applyConfig validates data, replaces the configuration file with it and
+// reloads the configuration. If any step fails, it writes the error
+// response and returns false; an invalid file is rejected with 422
+// Unprocessable Entity and the current file kept. The caller holds configMu.
+func applyConfig(w http.ResponseWriter, data []byte) (ReloadResponse, bool) {
+	// Validated against the path it is written to, against whose directory
+	// relative paths are resolved
+	path := config.Path()
+	if _, err := config.Parse(data, path); err != nil {
+		writeErrorResponse(w, http.StatusUnprocessableEntity, "invalid_config", err.Error())
+		return ReloadResponse{}, false
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "config_error", fmt.Sprintf("Failed to write configuration file: %v", err))
+		return ReloadResponse{}, false
+	}
+
+	response, err := Reload()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "reload_failed", fmt.Sprintf("Configuration reload incomplete: %v", err))
+		return ReloadResponse{}, false
+	}
+	return response, true
+}
+
+// writeFileAtomic replaces the file at path with data, so that a reload
+// never reads a partly written file. The file may hold API keys, so it is
+// only readable by its owner.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0o600); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel.yaml")
+	configtest.SetFile(t, path)
+	handler := ConfigHandler()
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(method, "/api/v1/admin/config", strings.NewReader(body)))
+		return rr
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve("GET", "").Code)
+
+	document := "projects:\n  storefront:\n    distribution: saas\napi_keys:\n  - name: ci\n    key: k3y-for-ci\n    role: analyst\n"
+	rr := serve("PUT", document)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response ReloadResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "Configuration applied", response.Message)
+	assert.Equal(t, 1, response.Projects)
+	assert.Len(t, config.Default().APIKeys, 1)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the file may hold API keys")
+	rr = serve("GET", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/yaml", rr.Header().Get("Content-Type"))
+	assert.Equal(t, document, rr.Body.String())
+
+	// Invalid files are rejected and the current one kept
+	rr = serve("PUT", "notifications:\n  - type: pagerduty\n    url: https://example.com\n")
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid_config")
+	assert.Equal(t, document, serve("GET", "").Body.String())
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve("POST", document).Code)
+
+	t.Setenv(config.EnvYAML, "profiles: {}\n")
+	assert.Equal(t, http.StatusConflict, serve("PUT", document).Code)
+	assert.Equal(t, "profiles: {}\n", serve("GET", "").Body.String())
+}
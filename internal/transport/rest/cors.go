@@ -0,0 +1,161 @@
+// Package rest provides the CORS configuration that lets browser frontends
+// on other origins call the API, and the middleware applying it.
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS configures the cross-origin requests browsers may make to the API.
+type CORS struct {
+	// AllowedOrigins lists the origins, such as "https://portal.example.com",
+	// allowed to call the API, or "*" for any origin. CORS is disabled when
+	// it is empty
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed in cross-origin requests
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in cross-origin requests
+	AllowedHeaders []string
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// DefaultCORS is the configuration without CORS_* variables: disabled, with
+// the methods and headers of the API ready for when origins are allowed.
+var DefaultCORS = CORS{
+	AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+	AllowedHeaders: []string{"Authorization", "Content-Type", "X-API-Key"},
+	MaxAge:         10 * time.Minute,
+}
+
+// CORSFromEnv returns the CORS configuration of CORS_ALLOWED_ORIGINS,
+// CORS_ALLOWED_METHODS and CORS_ALLOWED_HEADERS, comma-separated lists, and
+// CORS_MAX_AGE, a Go duration. Invalid values are reported and skipped.
+func CORSFromEnv() (CORS, error) {
+	cors := DefaultCORS
+	var errs []error
+
+	for _, origin := range splitList(os.Getenv("CORS_ALLOWED_ORIGINS")) {
+		if err := validateOrigin(origin); err != nil {
+			errs = append(errs, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS entry %q: %w", origin, err))
+			continue
+		}
+		cors.AllowedOrigins = append(cors.AllowedOrigins, origin)
+	}
+
+	if value := os.Getenv("CORS_ALLOWED_METHODS"); value != "" {
+		var methods []string
+		for _, method := range splitList(value) {
+			methods = append(methods, strings.ToUpper(method))
+		}
+		cors.AllowedMethods = methods
+	}
+
+	if value := os.Getenv("CORS_ALLOWED_HEADERS"); value != "" {
+		cors.AllowedHeaders = splitList(value)
+	}
+
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			errs = append(errs, fmt.Errorf("invalid CORS_MAX_AGE %q", value))
+		} else {
+			cors.MaxAge = maxAge
+		}
+	}
+
+	return cors, errors.Join(errs...)
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// validateOrigin checks that an allowed origin is "*" or a scheme and host
+// with an optional port, as browsers send in the Origin header.
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return errors.New("expected scheme://host[:port]")
+	}
+	return nil
+}
+
+// Enabled reports whether any origin is allowed.
+func (c CORS) Enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+// allowsOrigin reports whether requests from origin are allowed.
+func (c CORS) allowsOrigin(origin string) bool {
+	return slices.ContainsFunc(c.AllowedOrigins, func(allowed string) bool {
+		return allowed == "*" || strings.EqualFold(allowed, origin)
+	})
+}
+
+// Handler wraps next with the CORS headers of allowed origins. It answers
+// preflight requests itself, before they reach authentication, as browsers
+// send them without credentials. If CORS is disabled next is returned.
+func (c CORS) Handler(next http.Handler) http.Handler {
+	if !c.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if !c.allowsOrigin(origin) {
+			if preflight {
+				w.Header().Set("Content-Type", "application/json")
+				writeErrorResponse(w, http.StatusForbidden, "forbidden", fmt.Sprintf("Origin %s is not allowed", origin))
+				return
+			}
+			// Without CORS headers the browser withholds the response
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if slices.Contains(c.AllowedOrigins, "*") {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if !preflight {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.AllowedMethods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
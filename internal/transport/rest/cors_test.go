@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSFromEnv(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	t.Setenv("CORS_ALLOWED_METHODS", "")
+	t.Setenv("CORS_ALLOWED_HEADERS", "")
+	t.Setenv("CORS_MAX_AGE", "")
+	cors, err := CORSFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCORS, cors)
+	assert.False(t, cors.Enabled())
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://portal.example.com, http://localhost:3000, portal.example.com, https://x.example.com/app")
+	t.Setenv("CORS_ALLOWED_METHODS", "get, post")
+	t.Setenv("CORS_ALLOWED_HEADERS", "X-API-Key, Content-Type")
+	t.Setenv("CORS_MAX_AGE", "1h")
+	cors, err = CORSFromEnv()
+	assert.ErrorContains(t, err, `invalid CORS_ALLOWED_ORIGINS entry "portal.example.com"`)
+	assert.ErrorContains(t, err, `invalid CORS_ALLOWED_ORIGINS entry "https://x.example.com/app"`)
+	assert.Equal(t, CORS{
+		AllowedOrigins: []string{"https://portal.example.com", "http://localhost:3000"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"X-API-Key", "Content-Type"},
+		MaxAge:         time.Hour,
+	}, cors)
+}
+
+func TestCORS_Handler(t *testing.T) {
+	cors := DefaultCORS
+	cors.AllowedOrigins = []string{"https://portal.example.com"}
+
+	// Authentication rejects requests before the CORS headers matter
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	serve := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/sboms", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			req.Header.Set("Access-Control-Request-Headers", "x-api-key")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Preflights are answered without credentials
+	rr := serve(http.MethodOptions, "https://portal.example.com", true)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "https://portal.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE", rr.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type, X-API-Key", rr.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rr.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "Origin", rr.Header().Get("Vary"))
+
+	rr = serve(http.MethodGet, "https://portal.example.com", false)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "https://portal.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Methods"))
+
+	// Other origins get no CORS headers
+	rr = serve(http.MethodOptions, "https://evil.example.com", true)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+
+	rr = serve(http.MethodGet, "https://evil.example.com", false)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+
+	rr = serve(http.MethodGet, "", false)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+
+	// Any origin with a wildcard
+	cors.AllowedOrigins = []string{"*"}
+	handler = cors.Handler(http.NotFoundHandler())
+	rr = serve(http.MethodGet, "https://anywhere.example.com", false)
+	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_HandlerDisabled(t *testing.T) {
+	next := http.NotFoundHandler()
+	handler := DefaultCORS.Handler(next)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/sboms", nil)
+	req.Header.Set("Origin", "https://portal.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+}
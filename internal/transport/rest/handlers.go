@@ -2,27 +2,56 @@
 package rest
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ignore"
 	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/hueyexe/SBOM-Sentinel/internal/lifecycle"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
 	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/hueyexe/SBOM-Sentinel/internal/plugin"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/hueyexe/SBOM-Sentinel/internal/rules"
+	"github.com/hueyexe/SBOM-Sentinel/internal/schema"
+	"github.com/hueyexe/SBOM-Sentinel/internal/wiring"
 )
 
 // SubmitSBOMResponse represents the JSON response for SBOM submission.
 type SubmitSBOMResponse struct {
-	ID      string `json:"id"`
-	Message string `json:"message"`
+	ID            string                         `json:"id"`
+	Message       string                         `json:"message"`
+	Normalization *ingestion.NormalizationReport `json:"normalization,omitempty"`
+	// Duplicate is set when an identical SBOM was already stored and ID is
+	// that of the stored SBOM
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Replaced is set when the SBOM replaced the stored SBOM with the same
+	// serial number, keeping its ID
+	Replaced bool `json:"replaced,omitempty"`
 }
 
 // ErrorResponse represents a JSON error response.
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
+	// Violations lists every schema violation of a rejected SBOM
+	Violations []ingestion.Violation `json:"violations,omitempty"`
+	// ExistingID is the ID of the stored SBOM a submission conflicts with
+	ExistingID string `json:"existing_id,omitempty"`
 }
 
 // AnalysisResponse represents the JSON response for SBOM analysis.
@@ -37,10 +66,118 @@ type AnalysisSummary struct {
 	TotalFindings      int            `json:"total_findings"`
 	FindingsBySeverity map[string]int `json:"findings_by_severity"`
 	AgentsRun          []string       `json:"agents_run"`
+	// Risk is the composite risk score of the findings
+	Risk analysis.RiskScore `json:"risk"`
+	// AgentStatus is "ok", "failed" or "timeout" for each agent run, by agent name
+	AgentStatus map[string]string `json:"agent_status,omitempty"`
+	// AgentParameters records the effective settings of parameterized agents, by agent name
+	AgentParameters map[string]map[string]string `json:"agent_parameters,omitempty"`
+	// AgentErrors lists the agents that failed or timed out; when present the results are incomplete
+	AgentErrors []AgentError `json:"agent_errors,omitempty"`
+	// AgentUsage is the wall time and LLM usage of each agent run, by agent name
+	AgentUsage map[string]AgentUsage `json:"agent_usage,omitempty"`
+	// Policy is the outcome of the gate policies configured by POLICY_PATH, if any
+	Policy *PolicyResult `json:"policy,omitempty"`
+	// HiddenFindings counts the findings left out of the results by
+	// min_severity and min_confidence; the other counts include them
+	HiddenFindings int `json:"hidden_findings,omitempty"`
+	// Waived lists the findings excluded by the waivers of the configuration
+	// file, with their reasons; no other count includes them
+	Waived []ignore.Ignored `json:"waived,omitempty"`
+	// Lifecycle counts the new, recurring and resolved findings of the SBOM's
+	// project; it is left out when an older version is analyzed
+	Lifecycle *storage.AnalysisRun `json:"lifecycle,omitempty"`
+}
+
+// PolicyResult reports whether the analysis results pass the gate policies.
+type PolicyResult struct {
+	Passed     bool     `json:"passed"`
+	Violations []string `json:"violations"`
+	// Error explains why the policies could not be evaluated; the gate then fails
+	Error string `json:"error,omitempty"`
+}
+
+// gatePolicy returns the gate policies applied to analysis results, or nil if none are configured.
+var gatePolicy = policy.EvaluatorFromEnv
+
+// findingNotifier returns where analysis findings are sent, or nil if the
+// configuration file defines no notification channels.
+var findingNotifier = func() notify.Notifier {
+	return notifiers.forConfig(config.Default())
+}
+
+// notifiers keeps the dispatcher of the current configuration, so that its
+// clients are only re-created when the configuration is reloaded.
+var notifiers notifierCache
+
+// notifierCache holds the dispatcher created for a configuration.
+type notifierCache struct {
+	mu       sync.Mutex
+	config   *config.Config
+	notifier notify.Notifier
+}
+
+// forConfig returns the dispatcher of cfg's notification channels, or nil if it has none.
+func (c *notifierCache) forConfig(cfg *config.Config) notify.Notifier {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.config != cfg {
+		c.config = cfg
+		c.notifier = nil
+		if len(cfg.Notifications) > 0 {
+			c.notifier = notify.NewDispatcher(cfg.Notifications)
+		}
+	}
+	return c.notifier
+}
+
+// strictValidator returns the schema validator of validate=strict
+// submissions, using the official schemas in SENTINEL_SCHEMA_DIR if set.
+var strictValidator = sync.OnceValue(func() *schema.Validator {
+	validator, _ := schema.FromEnv()
+	return validator
+})
+
+// AgentError describes an agent that did not complete its analysis.
+type AgentError struct {
+	Agent  string `json:"agent"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// AgentUsage describes the capacity an agent used during an analysis.
+type AgentUsage struct {
+	WallTimeMS int64 `json:"wall_time_ms"`
+	// LLM is left out for agents that made no LLM requests
+	LLM *LLMUsage `json:"llm,omitempty"`
+}
+
+// LLMUsage describes the LLM requests of an agent, as reported by Ollama.
+type LLMUsage struct {
+	Requests         int   `json:"requests"`
+	PromptTokens     int   `json:"prompt_tokens"`
+	CompletionTokens int   `json:"completion_tokens"`
+	TotalTokens      int   `json:"total_tokens"`
+	DurationMS       int64 `json:"duration_ms"`
 }
 
 // SubmitSBOMHandler creates an HTTP handler for submitting SBOM files.
-// It expects a multipart/form-data request with an SBOM file.
+// It expects a multipart/form-data request with an SBOM file. With
+// ?validate=strict the file must also follow the official JSON Schema of
+// its format and version. If the repository records content hashes and an
+// identical SBOM is already stored, its ID is returned with 200 OK instead
+// of storing another copy, unless ?force=true is given.
+//
+// Stored SBOMs get a new random ID. A document whose serial number is that
+// of a stored SBOM is rejected with 409 Conflict, unless ?replace=true is
+// given to replace the stored SBOM under its ID.
+//
+// Tags are given as tag fields or parameters of the form "key=value", such
+// as tag=env=prod; a duplicate keeps the tags of the stored SBOM.
+//
+// The checks for stored copies and the SBOM and its content hash are read
+// and written in one transaction if the repository supports them.
 func SubmitSBOMHandler(repo storage.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Only allow POST requests
@@ -52,52 +189,129 @@ func SubmitSBOMHandler(repo storage.Repository) http.HandlerFunc {
 		// Set response headers
 		w.Header().Set("Content-Type", "application/json")
 
-		// Parse multipart form (32MB max memory)
-		err := r.ParseMultipartForm(32 << 20)
-		if err != nil {
-			writeErrorResponse(w, http.StatusBadRequest, "invalid_form", "Failed to parse multipart form")
+		if !parseSubmissionForm(w, r) {
 			return
 		}
 
 		// Get the uploaded file
-		file, header, err := r.FormFile("sbom")
-		if err != nil {
+		headers := r.MultipartForm.File["sbom"]
+		if len(headers) == 0 {
 			writeErrorResponse(w, http.StatusBadRequest, "missing_file", "SBOM file is required. Please upload a file with the 'sbom' field name")
 			return
 		}
-		defer file.Close()
 
-		// Validate file type (optional - could check file extension)
-		if header.Size == 0 {
-			writeErrorResponse(w, http.StatusBadRequest, "empty_file", "Uploaded file is empty")
+		options, err := parseSubmissionOptions(r)
+		if err != nil {
+			writeSubmissionError(w, err)
+			return
+		}
+		prepared, err := prepareSubmission(headers[0], options, requestLimits(r))
+		if err != nil {
+			writeSubmissionError(w, err)
 			return
 		}
 
-		// Create parser instance
-		parser := ingestion.NewCycloneDXParser()
-
-		// Parse the SBOM file
-		sbom, err := parser.Parse(file)
+		var response SubmitSBOMResponse
+		err = storeSubmissions(r.Context(), repo, func(ctx context.Context) error {
+			response, err = storeSubmission(ctx, repo, prepared, options)
+			return err
+		})
 		if err != nil {
-			writeErrorResponse(w, http.StatusBadRequest, "parse_error", fmt.Sprintf("Failed to parse SBOM file: %v", err))
+			writeSubmissionError(w, err)
 			return
 		}
 
-		// Store the SBOM in the database
-		ctx := r.Context()
-		err = repo.Store(ctx, *sbom)
+		w.WriteHeader(response.status())
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			// Log the error, but response has already been started
+			fmt.Printf("Error encoding response: %v\n", err)
+		}
+	}
+}
+
+// SubmitSBOMBatchResponse represents the JSON response for a batch submission.
+type SubmitSBOMBatchResponse struct {
+	// SBOMs holds the result of each file, in the order they were given
+	SBOMs []SubmitSBOMResponse `json:"sboms"`
+}
+
+// SubmitSBOMBatchHandler creates an HTTP handler for submitting several SBOM
+// files at once, given as repeated sbom fields of a multipart/form-data
+// request. Each file is handled as by SubmitSBOMHandler, with the query
+// parameters applying to all of them, but they are stored in one
+// transaction: if any is rejected or cannot be stored, none are, and the
+// error response names the file that failed. Otherwise the results of the
+// files are listed in order, with 201 Created if any was stored and 200 OK
+// if all were already.
+//
+// The repository must support transactions.
+func SubmitSBOMBatchHandler(repo storage.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow POST requests
+		if r.Method != http.MethodPost {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only POST method is allowed")
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		transactor, ok := repo.(storage.Transactor)
+		if !ok {
+			writeErrorResponse(w, http.StatusNotImplemented, "not_implemented", "Batch submissions need a repository that supports transactions")
+			return
+		}
+
+		if !parseSubmissionForm(w, r) {
+			return
+		}
+		headers := r.MultipartForm.File["sbom"]
+		if len(headers) == 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_file", "SBOM files are required. Please upload them with the 'sbom' field name")
+			return
+		}
+
+		options, err := parseSubmissionOptions(r)
 		if err != nil {
-			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to store SBOM: %v", err))
+			writeSubmissionError(w, err)
 			return
 		}
 
-		// Return success response
-		response := SubmitSBOMResponse{
-			ID:      sbom.ID,
-			Message: "SBOM submitted successfully",
+		// Every file is checked before any is stored
+		submissions := make([]*submission, len(headers))
+		for i, header := range headers {
+			prepared, err := prepareSubmission(header, options, requestLimits(r))
+			if err != nil {
+				writeSubmissionError(w, inBatch(err, i, len(headers), header.Filename))
+				return
+			}
+			submissions[i] = prepared
 		}
 
-		w.WriteHeader(http.StatusCreated)
+		var response SubmitSBOMBatchResponse
+		err = storeSubmissions(r.Context(), transactor, func(ctx context.Context) error {
+			response.SBOMs = make([]SubmitSBOMResponse, 0, len(submissions))
+			for i, prepared := range submissions {
+				result, err := storeSubmission(ctx, repo, prepared, options)
+				if err != nil {
+					return inBatch(err, i, len(headers), headers[i].Filename)
+				}
+				response.SBOMs = append(response.SBOMs, result)
+			}
+			return nil
+		})
+		if err != nil {
+			writeSubmissionError(w, err)
+			return
+		}
+
+		status := http.StatusOK
+		for _, result := range response.SBOMs {
+			if result.status() == http.StatusCreated {
+				status = http.StatusCreated
+			}
+		}
+		w.WriteHeader(status)
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			// Log the error, but response has already been started
 			fmt.Printf("Error encoding response: %v\n", err)
@@ -105,7 +319,296 @@ func SubmitSBOMHandler(repo storage.Repository) http.HandlerFunc {
 	}
 }
 
+// storeSubmissions calls store in a transaction of repo, if it supports
+// them. A write conflicting with an SBOM stored concurrently, after the
+// checks of store, rolls the transaction back; store is then called once
+// more, so that its checks see the stored SBOM, and a second conflict is
+// rejected with 409 Conflict.
+func storeSubmissions(ctx context.Context, repo any, store func(ctx context.Context) error) error {
+	err := storage.InTransaction(ctx, repo, store)
+	if errors.Is(err, storage.ErrConflict) {
+		err = storage.InTransaction(ctx, repo, store)
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		return conflictFailure("", err.Error())
+	}
+	return err
+}
+
+// status returns the HTTP status of a submission: 201 Created if the SBOM
+// was stored, 200 OK if it was already or replaced a stored SBOM.
+func (r SubmitSBOMResponse) status() int {
+	if r.Duplicate || r.Replaced {
+		return http.StatusOK
+	}
+	return http.StatusCreated
+}
+
+// parseSubmissionForm parses the multipart form of a submission (32MB max
+// memory), bounded by the upload size limit, and writes the error response
+// if it cannot be parsed.
+func parseSubmissionForm(w http.ResponseWriter, r *http.Request) bool {
+	limits := requestLimits(r)
+	r.Body = http.MaxBytesReader(w, r.Body, limits.MaxUploadBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writePayloadTooLarge(w, limits.MaxUploadBytes)
+			return false
+		}
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_form", "Failed to parse multipart form")
+		return false
+	}
+	return true
+}
+
+// submissionOptions are the query parameters and fields of a submission.
+type submissionOptions struct {
+	// strict validates documents against the official schema of their format
+	strict  bool
+	force   bool
+	replace bool
+	tags    map[string]string
+}
+
+// parseSubmissionOptions returns the options of a submission whose form is parsed.
+func parseSubmissionOptions(r *http.Request) (submissionOptions, error) {
+	var options submissionOptions
+
+	// With validate=strict the document must follow the official schema of its format
+	switch mode := r.URL.Query().Get("validate"); mode {
+	case "", "lenient":
+	case "strict":
+		options.strict = true
+	default:
+		return options, rejectSubmission(http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("invalid validate mode %q (expected strict or lenient)", mode))
+	}
+
+	// Tags are given as "key=value" tag fields or query parameters
+	tags, err := core.ParseTags(r.Form["tag"])
+	if err != nil {
+		return options, rejectSubmission(http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("Invalid tags: %v", err))
+	}
+	options.tags = tags
+
+	options.force = r.URL.Query().Get("force") == "true"
+	options.replace = r.URL.Query().Get("replace") == "true"
+	return options, nil
+}
+
+// submission is a parsed and validated SBOM, ready to be stored.
+type submission struct {
+	sbom          *core.SBOM
+	contentHash   string
+	normalization ingestion.NormalizationReport
+}
+
+// prepareSubmission reads, validates, parses and normalizes an uploaded SBOM file.
+func prepareSubmission(header *multipart.FileHeader, options submissionOptions, limits Limits) (*submission, error) {
+	// Validate file type (optional - could check file extension)
+	if header.Size == 0 {
+		return nil, rejectSubmission(http.StatusBadRequest, "empty_file", "Uploaded file is empty")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, rejectSubmission(http.StatusBadRequest, "invalid_form", fmt.Sprintf("Failed to read SBOM file: %v", err))
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, rejectSubmission(http.StatusBadRequest, "invalid_form", fmt.Sprintf("Failed to read SBOM file: %v", err))
+	}
+
+	if options.strict {
+		result, err := strictValidator().Validate(data)
+		if err != nil {
+			return nil, rejectSubmission(http.StatusBadRequest, "parse_error", fmt.Sprintf("Failed to parse SBOM file: %v", err))
+		}
+		if !result.Valid() {
+			return nil, validationFailure(result.Format, result.Violations)
+		}
+	}
+
+	// Reject documents that do not follow the CycloneDX schema with every violation
+	violations, err := ingestion.ValidateCycloneDX(data)
+	if err != nil {
+		return nil, rejectSubmission(http.StatusBadRequest, "parse_error", fmt.Sprintf("Failed to parse SBOM file: %v", err))
+	}
+	if len(violations) > 0 {
+		return nil, validationFailure("CycloneDX", violations)
+	}
+
+	// Parse the SBOM file
+	sbom, err := ingestion.NewCycloneDXParser().Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, rejectSubmission(http.StatusBadRequest, "parse_error", fmt.Sprintf("Failed to parse SBOM file: %v", err))
+	}
+	if len(sbom.Components) > limits.MaxComponents {
+		return nil, rejectSubmission(http.StatusUnprocessableEntity, "too_many_components",
+			fmt.Sprintf("SBOM has %d components, more than the maximum of %d", len(sbom.Components), limits.MaxComponents))
+	}
+
+	contentHash, err := ingestion.ContentHash(data)
+	if err != nil {
+		return nil, rejectSubmission(http.StatusBadRequest, "parse_error", fmt.Sprintf("Failed to parse SBOM file: %v", err))
+	}
+
+	// Normalize PURLs and licenses and drop duplicate components before storage
+	report := ingestion.NewNormalizer().Normalize(sbom)
+
+	return &submission{sbom: sbom, contentHash: contentHash, normalization: report}, nil
+}
+
+// storeSubmission stores a prepared SBOM, unless an identical one is stored,
+// and returns the result of the submission.
+func storeSubmission(ctx context.Context, repo storage.Repository, prepared *submission, options submissionOptions) (SubmitSBOMResponse, error) {
+	sbom := *prepared.sbom
+
+	// Return the stored copy of a document submitted before; a forced copy
+	// is stored without its content hash, which stays with the first
+	index, _ := repo.(storage.ContentIndex)
+	duplicateID := ""
+	if index != nil {
+		existingID, err := index.FindByContentHash(ctx, prepared.contentHash)
+		if err != nil {
+			return SubmitSBOMResponse{}, storageFailure("Failed to check for duplicate SBOM", err)
+		}
+		if existingID != "" && !options.force {
+			return SubmitSBOMResponse{ID: existingID, Message: "SBOM already submitted", Duplicate: true}, nil
+		}
+		duplicateID = existingID
+	}
+
+	// Serial numbers identify documents, so a second one with the same
+	// serial number replaces the first or is rejected
+	sbom.ID = core.NewSBOMID()
+	replaced := false
+	if serialIndex, ok := repo.(storage.SerialIndex); ok && sbom.Metadata["serialNumber"] != "" {
+		existingID, err := serialIndex.FindBySerialNumber(ctx, sbom.Metadata["serialNumber"])
+		if err != nil {
+			return SubmitSBOMResponse{}, storageFailure("Failed to check for conflicting SBOM", err)
+		}
+		if existingID != "" {
+			if !options.replace {
+				return SubmitSBOMResponse{}, conflictFailure(existingID, fmt.Sprintf("An SBOM with serial number %s is already stored as %s; submit with ?replace=true to replace it", sbom.Metadata["serialNumber"], existingID))
+			}
+			sbom.ID = existingID
+			replaced = true
+		}
+	}
+
+	// A replacement submitted without tags keeps those of the SBOM it replaces
+	sbom.Tags = options.tags
+	if replaced && len(options.tags) == 0 {
+		existing, err := repo.FindByID(ctx, sbom.ID)
+		if err != nil {
+			return SubmitSBOMResponse{}, storageFailure("Failed to retrieve replaced SBOM", err)
+		}
+		if existing != nil {
+			sbom.Tags = existing.Tags
+		}
+	}
+
+	// Store the SBOM in the database
+	if err := repo.Store(ctx, sbom); err != nil {
+		return SubmitSBOMResponse{}, storageFailure("Failed to store SBOM", err)
+	}
+	if index != nil {
+		contentHash := prepared.contentHash
+		if duplicateID != "" && duplicateID != sbom.ID {
+			contentHash = ""
+		}
+		if err := index.SetContentHash(ctx, sbom.ID, contentHash); err != nil {
+			return SubmitSBOMResponse{}, storageFailure("Failed to store SBOM", err)
+		}
+	}
+
+	response := SubmitSBOMResponse{
+		ID:      sbom.ID,
+		Message: "SBOM submitted successfully",
+	}
+	if prepared.normalization.HasChanges() {
+		report := prepared.normalization
+		response.Normalization = &report
+	}
+	if replaced {
+		response.Message = "SBOM replaced successfully"
+		response.Replaced = true
+	}
+	return response, nil
+}
+
+// submissionError is the error response to a rejected or failed submission.
+type submissionError struct {
+	status   int
+	response ErrorResponse
+	// err is the storage error the submission failed with, if any
+	err error
+}
+
+func (e *submissionError) Error() string {
+	return e.response.Message
+}
+
+func (e *submissionError) Unwrap() error {
+	return e.err
+}
+
+// storageFailure returns the 500 response to a submission that failed with
+// the storage error err while doing action.
+func storageFailure(action string, err error) *submissionError {
+	return &submissionError{
+		status:   http.StatusInternalServerError,
+		response: ErrorResponse{Error: "storage_error", Message: fmt.Sprintf("%s: %v", action, err)},
+		err:      err,
+	}
+}
+
+// rejectSubmission returns the error response to a submission.
+func rejectSubmission(status int, errorType, message string) *submissionError {
+	return &submissionError{status: status, response: ErrorResponse{Error: errorType, Message: message}}
+}
+
+// conflictFailure returns the 409 response to a submission that conflicts
+// with the stored SBOM existingID.
+func conflictFailure(existingID, message string) *submissionError {
+	return &submissionError{status: http.StatusConflict, response: ErrorResponse{Error: "conflict", Message: message, ExistingID: existingID}}
+}
+
+// inBatch prefixes the message of the error of file i of n in a batch
+// submission with its position and name.
+func inBatch(err error, i, n int, filename string) error {
+	var failed *submissionError
+	if !errors.As(err, &failed) {
+		return fmt.Errorf("SBOM %d of %d (%s): %w", i+1, n, filename, err)
+	}
+	prefixed := *failed
+	prefixed.response.Message = fmt.Sprintf("SBOM %d of %d (%s): %s", i+1, n, filename, failed.response.Message)
+	return &prefixed
+}
+
+// writeSubmissionError writes the error response to a failed submission;
+// errors other than a submissionError, such as a failed commit, are storage
+// errors.
+func writeSubmissionError(w http.ResponseWriter, err error) {
+	var failed *submissionError
+	if !errors.As(err, &failed) {
+		writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to store SBOM: %v", err))
+		return
+	}
+	w.WriteHeader(failed.status)
+	if err := json.NewEncoder(w).Encode(failed.response); err != nil {
+		// Log the error, but response has already been started
+		fmt.Printf("Error encoding error response: %v\n", err)
+	}
+}
+
 // GetSBOMHandler creates an HTTP handler for retrieving SBOM by ID.
+// It serves GET /api/v1/sboms/{id} and, for existing clients, the query
+// form GET /api/v1/sboms/get?id={id}. With ?offset=, ?limit= or
+// ?fields=name,version it returns an SBOMPage with the selected page and
+// fields of the components instead of the whole SBOM.
 func GetSBOMHandler(repo storage.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Only allow GET requests
@@ -117,12 +620,13 @@ func GetSBOMHandler(repo storage.Repository) http.HandlerFunc {
 		// Set response headers
 		w.Header().Set("Content-Type", "application/json")
 
-		// Extract ID from URL path
-		// For simplicity, we'll expect the ID as a query parameter
-		// In a real application, you might use a router like gorilla/mux
-		id := r.URL.Query().Get("id")
+		// Extract ID from the URL path, falling back to the ?id= query parameter
+		id := r.PathValue("id")
+		if id == "" {
+			id = r.URL.Query().Get("id")
+		}
 		if id == "" {
-			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "SBOM ID is required as query parameter")
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "SBOM ID is required in URL path or as query parameter")
 			return
 		}
 
@@ -139,9 +643,19 @@ func GetSBOMHandler(repo storage.Repository) http.HandlerFunc {
 			return
 		}
 
-		// Return the SBOM
+		// Return the SBOM, or the requested page of its components
+		var response interface{} = sbom
+		if query := r.URL.Query(); wantsComponentPage(query) {
+			page, err := pageComponents(*sbom, query)
+			if err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+				return
+			}
+			response = page
+		}
+
 		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(sbom); err != nil {
+		if err := json.NewEncoder(w).Encode(response); err != nil {
 			// Log the error, but response has already been started
 			fmt.Printf("Error encoding response: %v\n", err)
 		}
@@ -150,7 +664,10 @@ func GetSBOMHandler(repo storage.Repository) http.HandlerFunc {
 
 // AnalyzeSBOMHandler creates an HTTP handler for analyzing stored SBOMs.
 // It expects a POST request to /api/v1/sboms/{id}/analyze with optional query parameters.
-func AnalyzeSBOMHandler(repo storage.Repository) http.HandlerFunc {
+// Proactive scans search the shared intelligence corpus; if it is nil, each
+// request builds its own corpus. Findings are also sent to the notification
+// channels of the configuration file.
+func AnalyzeSBOMHandler(repo storage.Repository, intelligence *vectordb.IntelligenceStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Only allow POST requests
 		if r.Method != http.MethodPost {
@@ -163,19 +680,24 @@ func AnalyzeSBOMHandler(repo storage.Repository) http.HandlerFunc {
 
 		// Extract SBOM ID from URL path
 		// Expected format: /api/v1/sboms/{id}/analyze
-		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-		if len(pathParts) < 4 || pathParts[3] == "" {
+		sbomID := r.PathValue("id")
+		if sbomID == "" {
+			// Not routed through a pattern; parse the path directly
+			pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+			if len(pathParts) >= 4 {
+				sbomID = pathParts[3]
+			}
+		}
+		if sbomID == "" {
 			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "SBOM ID is required in URL path")
 			return
 		}
-		sbomID := pathParts[3]
 
-		// Check for AI health check flag
-		enableAIHealthCheck := r.URL.Query().Get("enable-ai-health-check") == "true"
-		// Check for proactive scan flag
-		enableProactiveScan := r.URL.Query().Get("enable-proactive-scan") == "true"
-		// Check for vulnerability scan flag
-		enableVulnScan := r.URL.Query().Get("enable-vuln-scan") == "true"
+		options, err := parseAnalysisOptions(r.URL.Query(), intelligence)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
 
 		// Retrieve SBOM from database
 		ctx := r.Context()
@@ -190,75 +712,237 @@ func AnalyzeSBOMHandler(repo storage.Repository) http.HandlerFunc {
 			return
 		}
 
-		// Run analysis agents
-		var allResults []core.AnalysisResult
-		var agentsRun []string
-
-		// Run license analysis
-		licenseAgent := analysis.NewLicenseAgent()
-		licenseResults, err := licenseAgent.Analyze(ctx, *sbom)
+		response, err := analyzeSBOM(ctx, repo, *sbom, options)
 		if err != nil {
-			writeErrorResponse(w, http.StatusInternalServerError, "analysis_error", fmt.Sprintf("License analysis failed: %v", err))
+			writeErrorResponse(w, http.StatusInternalServerError, "analysis_error", err.Error())
 			return
 		}
-		allResults = append(allResults, licenseResults...)
-		agentsRun = append(agentsRun, licenseAgent.Name())
 
-		// Run AI health check if enabled
-		if enableAIHealthCheck {
-			healthAgent := analysis.NewDependencyHealthAgent()
-			healthResults, err := healthAgent.Analyze(ctx, *sbom)
-			if err != nil {
-				// Log warning but don't fail the entire analysis
-				fmt.Printf("Warning: AI health analysis failed: %v\n", err)
-			} else {
-				allResults = append(allResults, healthResults...)
-			}
-			agentsRun = append(agentsRun, healthAgent.Name())
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			// Log the error, but response has already been started
+			fmt.Printf("Error encoding response: %v\n", err)
 		}
+	}
+}
 
-		// Run proactive vulnerability scan if enabled
-		if enableProactiveScan {
-			proactiveAgent := analysis.NewProactiveVulnerabilityAgent()
-			proactiveResults, err := proactiveAgent.Analyze(ctx, *sbom)
-			if err != nil {
-				// Log warning but don't fail the entire analysis
-				fmt.Printf("Warning: Proactive vulnerability scan failed: %v\n", err)
-			} else {
-				allResults = append(allResults, proactiveResults...)
-			}
-			agentsRun = append(agentsRun, proactiveAgent.Name())
+// analysisOptions are the settings of an analysis given by its query
+// parameters.
+type analysisOptions struct {
+	orchestrator *analysis.Orchestrator
+	// minSeverity and minConfidence hide findings from the response
+	minSeverity   string
+	minConfidence float64
+}
+
+// parseAnalysisOptions reads the settings of an analysis from its query
+// parameters, reporting invalid values as an error.
+func parseAnalysisOptions(query url.Values, intelligence *vectordb.IntelligenceStore) (analysisOptions, error) {
+	orchestrator, err := selectAgents(query, intelligence)
+	if err != nil {
+		return analysisOptions{}, err
+	}
+	options := analysisOptions{orchestrator: orchestrator, minSeverity: query.Get("min_severity")}
+	if options.minSeverity != "" {
+		if err := core.ValidateSeverityThreshold(options.minSeverity); err != nil {
+			return analysisOptions{}, fmt.Errorf("Invalid min_severity: %v", err)
+		}
+	}
+	if value := query.Get("min_confidence"); value != "" {
+		options.minConfidence, err = core.ParseConfidenceThreshold(value)
+		if err != nil {
+			return analysisOptions{}, fmt.Errorf("Invalid min_confidence: %v", err)
 		}
+	}
+	return options, nil
+}
 
-		// Run vulnerability scan if enabled
-		if enableVulnScan {
-			vulnAgent := analysis.NewVulnerabilityScanningAgent()
-			vulnResults, err := vulnAgent.Analyze(ctx, *sbom)
-			if err != nil {
-				// Log warning but don't fail the entire analysis
-				fmt.Printf("Warning: Vulnerability scan failed: %v\n", err)
-			} else {
-				allResults = append(allResults, vulnResults...)
-			}
-			agentsRun = append(agentsRun, vulnAgent.Name())
+// analyzeSBOM runs the agents of options on sbom and builds the response,
+// evaluating the gate policies, tracking the finding lifecycle and sending
+// notifications.
+func analyzeSBOM(ctx context.Context, repo storage.Repository, sbom core.SBOM, options analysisOptions) (*AnalysisResponse, error) {
+	// Policies see the tags of the SBOM's project too
+	sbom.Tags = sbom.EffectiveTags(config.Default().Projects)
+	report, err := options.orchestrator.Run(ctx, sbom)
+	if err != nil {
+		return nil, err
+	}
+	var waived []ignore.Ignored
+	report.Results, waived = waiveFindings(sbom, report.Results)
+	report.Results = config.Default().Owners.Assign(report.Results)
+
+	// Generate summary
+	summary := generateAnalysisSummary(report.Results, report.AgentsRun())
+	summary.Waived = waived
+	summary.AgentStatus = report.AgentStatus()
+	summary.AgentParameters = options.orchestrator.Parameters()
+	summary.AgentErrors = agentErrors(report)
+	summary.AgentUsage = agentUsage(report)
+	if gate := gatePolicy(); gate != nil {
+		summary.Policy = evaluatePolicy(ctx, gate, policy.NewInput(sbom, report.Results, summary.AgentStatus))
+	}
+
+	// Track which findings are new, recurring or resolved for the project;
+	// a failure only leaves the counts out
+	if history, ok := repo.(storage.FindingHistory); ok {
+		run, err := lifecycle.Track(ctx, history, sbom, report, core.Now())
+		if err != nil {
+			fmt.Printf("Warning: Failed to record finding lifecycle of SBOM %s: %v\n", sbom.ID, err)
 		}
+		summary.Lifecycle = run
+	}
+
+	// Notify in the background so that slow webhooks do not delay the response
+	if notifier := findingNotifier(); notifier != nil && len(report.Results) > 0 {
+		notification := notify.Notification{SBOMID: sbom.ID, SBOMName: sbom.Name, Results: report.Results}
+		go notifyFindings(context.WithoutCancel(ctx), notifier, notification)
+	}
 
-		// Generate summary
-		summary := generateAnalysisSummary(allResults, agentsRun)
+	// Leave out findings below min_severity or min_confidence; the
+	// summary, policy and notifications still cover every finding
+	results := core.FilterByConfidence(core.FilterBySeverity(report.Results, options.minSeverity), options.minConfidence)
+	summary.HiddenFindings = len(report.Results) - len(results)
 
-		// Create response
-		response := AnalysisResponse{
-			SBOMID:  sbomID,
-			Results: allResults,
-			Summary: summary,
+	return &AnalysisResponse{
+		SBOMID:  sbom.ID,
+		Results: results,
+		Summary: summary,
+	}, nil
+}
+
+// waiveFindings splits results into the findings of sbom and those waived
+// for its project by the configuration file. Waived findings do not count
+// towards policies, notifications, risk scores or the finding lifecycle.
+func waiveFindings(sbom core.SBOM, results []core.AnalysisResult) ([]core.AnalysisResult, []ignore.Ignored) {
+	return ignore.Waivers(config.Default().Waivers, sbom.Name).Apply(results)
+}
+
+// notifyFindings sends a finding notification, logging failures.
+func notifyFindings(ctx context.Context, notifier notify.Notifier, notification notify.Notification) {
+	if err := notifier.Notify(ctx, notification); err != nil {
+		fmt.Printf("Warning: Failed to send notifications for SBOM %s: %v\n", notification.SBOMID, err)
+	}
+}
+
+// selectAgents builds an orchestrator running the agents enabled by the query
+// parameters. The license agent always runs; the remaining agents are opt-in:
+// ?profile enables a named bundle of agents from the configuration file, and
+// ?enable-ai-health-check, ?enable-proactive-scan, ?enable-vuln-scan,
+// ?enable-quality-check, ?enable-crypto-check, ?enable-export-check,
+// ?enable-base-image-check and ?enable-repo-activity-check switch individual
+// agents on or off.
+// ?license-ignore-scopes overrides the component scopes skipped by license
+// analysis, and ?rag-top-k and ?rag-similarity-threshold the retrieval of the
+// proactive agent. The proactive agent searches the given intelligence
+// corpus, or a private one if it is nil. Invalid parameter values are reported as an error.
+// Agent time limits come from the environment (see analysis.AgentTimeoutsFromEnv).
+func selectAgents(query url.Values, intelligence *vectordb.IntelligenceStore) (*analysis.Orchestrator, error) {
+	var selection wiring.AgentSelection
+	if name := query.Get("profile"); name != "" {
+		profile, err := config.Default().Profile(name)
+		if err != nil {
+			return nil, err
 		}
+		selection = wiring.ProfileSelection(profile)
+	}
+	selection.RequireLicense = true
+	selection.Intelligence = intelligence
+	selection.Projects = config.Default().Projects
+	selection.ExportRules = config.Default().ExportControlRules
+	selection.VulnerableBaseImages = config.Default().VulnerableBaseImages
+	selection.Plugins = plugin.AgentsFromEnv()
+	selection.Rules, _ = plugin.WasmAgents(config.Default().Rules) // Reported at startup and reload
+	selection.RuleEngine = rules.AgentFromEnv()
+
+	// Explicit enable parameters override the profile
+	for param, enabled := range map[string]*bool{
+		"enable-ai-health-check": &selection.AIHealthCheck,
+		"enable-proactive-scan":  &selection.ProactiveScan,
+		"enable-vuln-scan":       &selection.VulnScan,
+		"enable-quality-check":   &selection.QualityCheck,
+		"enable-crypto-check":    &selection.CryptoCheck,
+		"enable-export-check":    &selection.ExportCheck,
+
+		"enable-base-image-check":    &selection.BaseImageCheck,
+		"enable-repo-activity-check": &selection.RepoActivityCheck,
+	} {
+		if query.Has(param) {
+			*enabled = query.Get(param) == "true"
+		}
+	}
+	if scopes, ok := query["license-ignore-scopes"]; ok {
+		selection.LicenseIgnoreScopes = splitCommaList(scopes)
+	}
 
-		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			// Log the error, but response has already been started
-			fmt.Printf("Error encoding response: %v\n", err)
+	topK := 0
+	if value := query.Get("rag-top-k"); value != "" && selection.ProactiveScan {
+		var err error
+		topK, err = strconv.Atoi(value)
+		if err != nil || topK < 1 {
+			return nil, fmt.Errorf("rag-top-k must be a positive integer, got %q", value)
+		}
+	}
+	threshold := -1.0
+	if value := query.Get("rag-similarity-threshold"); value != "" && selection.ProactiveScan {
+		var err error
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			return nil, fmt.Errorf("rag-similarity-threshold must be between 0 and 1, got %q", value)
+		}
+	}
+	selection.ConfigureProactive = func(agent *analysis.ProactiveVulnerabilityAgent) {
+		if topK > 0 {
+			agent.SetTopK(topK)
+		}
+		if threshold >= 0 {
+			agent.SetSimilarityThreshold(threshold)
 		}
 	}
+
+	// Invalid settings are reported once at startup
+	timeouts, _ := analysis.AgentTimeoutsFromEnv()
+	return wiring.NewOrchestrator(timeouts, selection), nil
+}
+
+// evaluatePolicy evaluates the gate policies against input.
+func evaluatePolicy(ctx context.Context, gate policy.Evaluator, input policy.Input) *PolicyResult {
+	decision, err := gate.Evaluate(ctx, input)
+	if err != nil {
+		return &PolicyResult{Violations: []string{}, Error: err.Error()}
+	}
+	return &PolicyResult{Passed: decision.Passed(), Violations: decision.Violations}
+}
+
+// agentErrors describes the agents in report that failed or timed out, or returns nil if all succeeded.
+func agentErrors(report *analysis.OrchestratorReport) []AgentError {
+	var errs []AgentError
+	for _, run := range report.Failures() {
+		errs = append(errs, AgentError{
+			Agent:  run.Agent,
+			Status: run.Status,
+			Error:  run.Err.Error(),
+		})
+	}
+	return errs
+}
+
+// agentUsage converts the agent runs of a report into their usage by agent name.
+func agentUsage(report *analysis.OrchestratorReport) map[string]AgentUsage {
+	usage := make(map[string]AgentUsage, len(report.Runs))
+	for _, run := range report.Runs {
+		agent := AgentUsage{WallTimeMS: run.Duration.Milliseconds()}
+		if run.LLMUsage != nil {
+			agent.LLM = &LLMUsage{
+				Requests:         run.LLMUsage.Requests,
+				PromptTokens:     run.LLMUsage.PromptTokens,
+				CompletionTokens: run.LLMUsage.CompletionTokens,
+				TotalTokens:      run.LLMUsage.Tokens(),
+				DurationMS:       run.LLMUsage.Duration.Milliseconds(),
+			}
+		}
+		usage[run.Agent] = agent
+	}
+	return usage
 }
 
 // generateAnalysisSummary creates a summary of analysis results.
@@ -272,10 +956,24 @@ func generateAnalysisSummary(results []core.AnalysisResult, agentsRun []string)
 	return AnalysisSummary{
 		TotalFindings:      len(results),
 		FindingsBySeverity: findingsBySeverity,
+		Risk:               analysis.ScoreRisk(results),
 		AgentsRun:          agentsRun,
 	}
 }
 
+// splitCommaList flattens repeated and comma-separated query values into a list.
+func splitCommaList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
 // writeErrorResponse writes a standardized error response.
 func writeErrorResponse(w http.ResponseWriter, statusCode int, errorType, message string) {
 	w.WriteHeader(statusCode)
@@ -288,3 +986,25 @@ func writeErrorResponse(w http.ResponseWriter, statusCode int, errorType, messag
 		fmt.Printf("Error encoding error response: %v\n", err)
 	}
 }
+
+// maxReportedViolations bounds the violations listed in a validation error response.
+const maxReportedViolations = 100
+
+// validationFailure returns the 422 response to an SBOM that violates the
+// schema of format, e.g. "CycloneDX 1.6".
+func validationFailure(format string, violations []ingestion.Violation) *submissionError {
+	message := fmt.Sprintf("SBOM does not follow the %s schema: %d violations", format, len(violations))
+	if len(violations) > maxReportedViolations {
+		message += fmt.Sprintf(", the first %d listed", maxReportedViolations)
+		violations = violations[:maxReportedViolations]
+	}
+
+	return &submissionError{
+		status: http.StatusUnprocessableEntity,
+		response: ErrorResponse{
+			Error:      "validation_error",
+			Message:    message,
+			Violations: violations,
+		},
+	}
+}
@@ -5,15 +5,25 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
 	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ingestion"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockRepository is a mock implementation of the storage.Repository interface
@@ -34,6 +44,14 @@ func (m *MockRepository) FindByID(ctx context.Context, id string) (*core.SBOM, e
 	return args.Get(0).(*core.SBOM), args.Error(1)
 }
 
+func (m *MockRepository) FindAll(ctx context.Context) ([]core.SBOM, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]core.SBOM), args.Error(1)
+}
+
 func TestSubmitSBOMHandler(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -180,6 +198,25 @@ func TestSubmitSBOMHandler(t *testing.T) {
 				return req, nil
 			},
 			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: func(t *testing.T, body []byte) {
+				var response ErrorResponse
+				err := json.Unmarshal(body, &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation_error", response.Error)
+				assert.Equal(t, []ingestion.Violation{
+					{Path: "", Message: `missing required property "bomFormat"`},
+					{Path: "", Message: `missing required property "specVersion"`},
+				}, response.Violations)
+			},
+		},
+		{
+			name:   "Malformed JSON",
+			method: "POST",
+			setupRequest: func() (*http.Request, error) {
+				return multipartSBOMRequest(`{"bomFormat": "CycloneDX",`)
+			},
+			mockBehavior:       func(mockRepo *MockRepository) {},
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse: func(t *testing.T, body []byte) {
 				var response ErrorResponse
@@ -188,6 +225,34 @@ func TestSubmitSBOMHandler(t *testing.T) {
 				assert.Equal(t, "parse_error", response.Error)
 			},
 		},
+		{
+			name:   "Schema violations in components",
+			method: "POST",
+			setupRequest: func() (*http.Request, error) {
+				return multipartSBOMRequest(`{
+					"bomFormat": "CycloneDX",
+					"specVersion": "1.5",
+					"components": [
+						{"type": "library", "name": "ok", "version": "1.0.0"},
+						{"type": "gadget", "name": "bad-type"},
+						{"type": "library", "version": 2}
+					]
+				}`)
+			},
+			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: func(t *testing.T, body []byte) {
+				var response ErrorResponse
+				err := json.Unmarshal(body, &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "validation_error", response.Error)
+				assert.Equal(t, "SBOM does not follow the CycloneDX schema: 3 violations", response.Message)
+				require.Len(t, response.Violations, 3)
+				assert.Equal(t, "/components/1/type", response.Violations[0].Path)
+				assert.Equal(t, "/components/2", response.Violations[1].Path)
+				assert.Equal(t, "/components/2/version", response.Violations[2].Path)
+			},
+		},
 		{
 			name:   "Database storage error",
 			method: "POST",
@@ -257,6 +322,358 @@ func TestSubmitSBOMHandler(t *testing.T) {
 	}
 }
 
+func TestSubmitSBOMHandler_StrictValidation(t *testing.T) {
+	// Accepted by lenient ingestion, but the serial number is not a UUID URN
+	sbom := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"serialNumber": "urn:uuid:test-12345",
+		"components": [{"type": "library", "name": "lodash", "version": "4.17.21"}]
+	}`
+
+	serve := func(target string) (*httptest.ResponseRecorder, ErrorResponse) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		req, err := multipartSBOMRequest(sbom)
+		require.NoError(t, err)
+		req.URL, err = req.URL.Parse(target)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		SubmitSBOMHandler(mockRepo).ServeHTTP(rr, req)
+
+		var response ErrorResponse
+		if rr.Code >= 400 {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr, response
+	}
+
+	rr, _ := serve("/api/v1/sboms")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	rr, response := serve("/api/v1/sboms?validate=strict")
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, "validation_error", response.Error)
+	assert.Equal(t, "SBOM does not follow the CycloneDX 1.5 schema: 1 violations", response.Message)
+	require.Len(t, response.Violations, 1)
+	assert.Equal(t, "/serialNumber", response.Violations[0].Path)
+
+	rr, response = serve("/api/v1/sboms?validate=paranoid")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "invalid_parameter", response.Error)
+}
+
+// MockIndexedRepository is a MockRepository that records content hashes.
+type MockIndexedRepository struct {
+	MockRepository
+}
+
+func (m *MockIndexedRepository) FindByContentHash(ctx context.Context, hash string) (string, error) {
+	args := m.Called(ctx, hash)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockIndexedRepository) SetContentHash(ctx context.Context, id, hash string) error {
+	args := m.Called(ctx, id, hash)
+	return args.Error(0)
+}
+
+func TestSubmitSBOMHandler_Duplicate(t *testing.T) {
+	sbom := `{"bomFormat": "CycloneDX", "specVersion": "1.5", "serialNumber": "urn:uuid:test-12345", "components": []}`
+	hash, err := ingestion.ContentHash([]byte(sbom))
+	require.NoError(t, err)
+
+	serve := func(repo storage.Repository, target string) (*httptest.ResponseRecorder, SubmitSBOMResponse) {
+		req, err := multipartSBOMRequest(sbom)
+		require.NoError(t, err)
+		req.URL, err = req.URL.Parse(target)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		SubmitSBOMHandler(repo).ServeHTTP(rr, req)
+
+		var response SubmitSBOMResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return rr, response
+	}
+
+	// A new document is stored with its hash
+	mockRepo := new(MockIndexedRepository)
+	mockRepo.On("FindByContentHash", mock.Anything, hash).Return("", nil)
+	mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SetContentHash", mock.Anything, mock.Anything, hash).Return(nil)
+	rr, response := serve(mockRepo, "/api/v1/sboms")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.False(t, response.Duplicate)
+	mockRepo.AssertExpectations(t)
+
+	// An identical one returns the stored SBOM
+	mockRepo = new(MockIndexedRepository)
+	mockRepo.On("FindByContentHash", mock.Anything, hash).Return("urn:uuid:first", nil)
+	rr, response = serve(mockRepo, "/api/v1/sboms")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "urn:uuid:first", response.ID)
+	assert.True(t, response.Duplicate)
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+
+	// Unless it is forced; the hash stays with the stored SBOM
+	mockRepo = new(MockIndexedRepository)
+	mockRepo.On("FindByContentHash", mock.Anything, hash).Return("urn:uuid:first", nil)
+	mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SetContentHash", mock.Anything, mock.Anything, "").Return(nil)
+	rr, response = serve(mockRepo, "/api/v1/sboms?force=true")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.False(t, response.Duplicate)
+	assert.NotEqual(t, "urn:uuid:first", response.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+// staleIndexRepository is a MemoryRepository whose first lookup of a content
+// hash misses, as if an identical SBOM was stored concurrently after the
+// submission checked for it.
+type staleIndexRepository struct {
+	*memory.MemoryRepository
+	looked bool
+}
+
+func (r *staleIndexRepository) FindByContentHash(ctx context.Context, hash string) (string, error) {
+	if !r.looked {
+		r.looked = true
+		return "", nil
+	}
+	return r.MemoryRepository.FindByContentHash(ctx, hash)
+}
+
+func TestSubmitSBOMHandler_ConcurrentDuplicate(t *testing.T) {
+	document := `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}`
+	hash, err := ingestion.ContentHash([]byte(document))
+	require.NoError(t, err)
+	repo := &staleIndexRepository{MemoryRepository: memory.NewMemoryRepository()}
+	require.NoError(t, repo.Store(context.Background(), core.SBOM{ID: "concurrent", Name: "concurrent"}))
+	require.NoError(t, repo.SetContentHash(context.Background(), "concurrent", hash))
+
+	req, err := multipartSBOMRequest(document)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	SubmitSBOMHandler(repo).ServeHTTP(rr, req)
+
+	// The conflicting write rolls back, and the retry finds the stored copy
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response SubmitSBOMResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.True(t, response.Duplicate)
+	assert.Equal(t, "concurrent", response.ID)
+	sboms, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, sboms, 1)
+}
+
+// MockSerialRepository is a MockRepository that indexes serial numbers.
+type MockSerialRepository struct {
+	MockRepository
+}
+
+func (m *MockSerialRepository) FindBySerialNumber(ctx context.Context, serialNumber string) (string, error) {
+	args := m.Called(ctx, serialNumber)
+	return args.String(0), args.Error(1)
+}
+
+func TestSubmitSBOMHandler_SerialNumberConflict(t *testing.T) {
+	sbom := `{"bomFormat": "CycloneDX", "specVersion": "1.5", "serialNumber": "urn:uuid:test-12345", "components": []}`
+
+	serve := func(repo storage.Repository, target string) *httptest.ResponseRecorder {
+		req, err := multipartSBOMRequest(sbom)
+		require.NoError(t, err)
+		req.URL, err = req.URL.Parse(target)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		SubmitSBOMHandler(repo).ServeHTTP(rr, req)
+		return rr
+	}
+
+	// A new serial number is stored under a generated ID, keeping the serial number
+	mockRepo := new(MockSerialRepository)
+	mockRepo.On("FindBySerialNumber", mock.Anything, "urn:uuid:test-12345").Return("", nil)
+	mockRepo.On("Store", mock.Anything, mock.MatchedBy(func(stored core.SBOM) bool {
+		return stored.ID != "" && stored.ID != "urn:uuid:test-12345" && stored.Metadata["serialNumber"] == "urn:uuid:test-12345"
+	})).Return(nil)
+	rr := serve(mockRepo, "/api/v1/sboms")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockRepo.AssertExpectations(t)
+
+	// A stored one conflicts
+	mockRepo = new(MockSerialRepository)
+	mockRepo.On("FindBySerialNumber", mock.Anything, "urn:uuid:test-12345").Return("stored-id", nil)
+	rr = serve(mockRepo, "/api/v1/sboms")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	var conflict ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &conflict))
+	assert.Equal(t, "conflict", conflict.Error)
+	assert.Equal(t, "stored-id", conflict.ExistingID)
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+
+	// Unless the stored SBOM is replaced under its ID, keeping its tags
+	mockRepo = new(MockSerialRepository)
+	mockRepo.On("FindBySerialNumber", mock.Anything, "urn:uuid:test-12345").Return("stored-id", nil)
+	mockRepo.On("FindByID", mock.Anything, "stored-id").Return(&core.SBOM{ID: "stored-id", Tags: map[string]string{"env": "prod"}}, nil)
+	mockRepo.On("Store", mock.Anything, mock.MatchedBy(func(stored core.SBOM) bool {
+		return stored.ID == "stored-id" && stored.Tags["env"] == "prod"
+	})).Return(nil)
+	rr = serve(mockRepo, "/api/v1/sboms?replace=true")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var replaced SubmitSBOMResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &replaced))
+	assert.Equal(t, "stored-id", replaced.ID)
+	assert.True(t, replaced.Replaced)
+	mockRepo.AssertExpectations(t)
+}
+
+// staleSerialRepository is a MemoryRepository whose first lookup of a serial
+// number misses, as if an SBOM with it was stored concurrently after the
+// submission checked for it.
+type staleSerialRepository struct {
+	*memory.MemoryRepository
+	looked bool
+}
+
+func (r *staleSerialRepository) FindBySerialNumber(ctx context.Context, serialNumber string) (string, error) {
+	if !r.looked {
+		r.looked = true
+		return "", nil
+	}
+	return r.MemoryRepository.FindBySerialNumber(ctx, serialNumber)
+}
+
+func TestSubmitSBOMHandler_ConcurrentSerialNumberConflict(t *testing.T) {
+	repo := &staleSerialRepository{MemoryRepository: memory.NewMemoryRepository()}
+	require.NoError(t, repo.Store(context.Background(), core.SBOM{ID: "concurrent", Name: "api", Metadata: map[string]string{"serialNumber": "urn:uuid:test-12345"}}))
+
+	req, err := multipartSBOMRequest(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "serialNumber": "urn:uuid:test-12345", "components": []}`)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	SubmitSBOMHandler(repo).ServeHTTP(rr, req)
+
+	// The conflicting write rolls back, and the retry reports the stored SBOM
+	require.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+	var conflict ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &conflict))
+	assert.Equal(t, "concurrent", conflict.ExistingID)
+	sboms, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, sboms, 1)
+}
+
+// failingStoreRepository is a MemoryRepository that fails to store SBOMs
+// with a given name.
+type failingStoreRepository struct {
+	*memory.MemoryRepository
+	failName string
+}
+
+func (r *failingStoreRepository) Store(ctx context.Context, sbom core.SBOM) error {
+	if sbom.Name == r.failName {
+		return errors.New("disk full")
+	}
+	return r.MemoryRepository.Store(ctx, sbom)
+}
+
+func TestSubmitSBOMBatchHandler(t *testing.T) {
+	document := func(name, serialNumber string) string {
+		return `{"bomFormat": "CycloneDX", "specVersion": "1.5", "serialNumber": "` + serialNumber + `",
+			"metadata": {"component": {"type": "application", "name": "` + name + `"}}, "components": []}`
+	}
+	serve := func(repo storage.Repository, documents ...string) *httptest.ResponseRecorder {
+		req, err := multipartSBOMBatchRequest(documents...)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		SubmitSBOMBatchHandler(repo).ServeHTTP(rr, req)
+		return rr
+	}
+	stored := func(repo storage.Repository) int {
+		sboms, err := repo.FindAll(context.Background())
+		require.NoError(t, err)
+		return len(sboms)
+	}
+
+	// Every file is stored, and an identical one later in the batch is a duplicate
+	repo := memory.NewMemoryRepository()
+	api := document("api", "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79")
+	rr := serve(repo, api, document("web", "urn:uuid:2f3a1c6e-0c7b-4c55-9d6e-0b0c3a9d2f41"), api)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var response SubmitSBOMBatchResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.SBOMs, 3)
+	assert.False(t, response.SBOMs[0].Duplicate)
+	assert.True(t, response.SBOMs[2].Duplicate)
+	assert.Equal(t, response.SBOMs[0].ID, response.SBOMs[2].ID)
+	assert.Equal(t, 2, stored(repo))
+
+	// A file that cannot be stored rolls back those stored before it
+	failing := &failingStoreRepository{MemoryRepository: memory.NewMemoryRepository(), failName: "web"}
+	rr = serve(failing, api, document("web", "urn:uuid:2f3a1c6e-0c7b-4c55-9d6e-0b0c3a9d2f41"))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	var failure ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &failure))
+	assert.Equal(t, "storage_error", failure.Error)
+	assert.Equal(t, "SBOM 2 of 2 (sbom-2.json): Failed to store SBOM: disk full", failure.Message)
+	assert.Equal(t, 0, stored(failing))
+
+	// As does a conflicting one
+	rr = serve(repo, document("billing", "urn:uuid:5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d"), `{"bomFormat": "CycloneDX", "specVersion": "1.5",
+		"serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", "metadata": {"component": {"type": "application", "name": "api", "version": "2"}}, "components": []}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, 2, stored(repo))
+
+	// Files are checked before any is stored
+	rr = serve(repo, document("billing", "urn:uuid:5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d"), "not json")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &failure))
+	assert.Contains(t, failure.Message, "SBOM 2 of 2 (sbom-2.json)")
+	assert.Equal(t, 2, stored(repo))
+
+	// Without transactions, batches cannot be all or nothing
+	rr = serve(new(MockRepository), api)
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
+
+// multipartSBOMBatchRequest creates a batch submission request uploading
+// each document as an SBOM file.
+func multipartSBOMBatchRequest(documents ...string) (*http.Request, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for i, document := range documents {
+		part, err := writer.CreateFormFile("sbom", fmt.Sprintf("sbom-%d.json", i+1))
+		if err != nil {
+			return nil, err
+		}
+		part.Write([]byte(document))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms/batch", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, nil
+}
+
+// multipartSBOMRequest creates a submission request uploading data as the SBOM file.
+func multipartSBOMRequest(data string) (*http.Request, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("sbom", "sbom.json")
+	if err != nil {
+		return nil, err
+	}
+	part.Write([]byte(data))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, nil
+}
+
 func TestGetSBOMHandler(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -385,6 +802,24 @@ func TestGetSBOMHandler(t *testing.T) {
 	}
 }
 
+func TestGetSBOMHandler_PathID(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-123").Return(&core.SBOM{ID: "test-sbom-123", Name: "Test SBOM"}, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sboms/{id}", GetSBOMHandler(mockRepo))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sboms/test-sbom-123", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var sbom core.SBOM
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &sbom))
+	assert.Equal(t, "test-sbom-123", sbom.ID)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestAnalyzeSBOMHandler(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -430,7 +865,7 @@ func TestAnalyzeSBOMHandler(t *testing.T) {
 			name:        "Analysis with all agents enabled",
 			method:      "POST",
 			urlPath:     "/api/v1/sboms/test-sbom-456/analyze",
-			queryParams: "?enable-ai-health-check=true&enable-proactive-scan=true&enable-vuln-scan=true",
+			queryParams: "?enable-ai-health-check=true&enable-proactive-scan=true&enable-vuln-scan=true&rag-top-k=5",
 			mockBehavior: func(mockRepo *MockRepository) {
 				testSBOM := &core.SBOM{
 					ID:   "test-sbom-456",
@@ -457,6 +892,82 @@ func TestAnalyzeSBOMHandler(t *testing.T) {
 				assert.Contains(t, response.Summary.AgentsRun, "Dependency Health Agent")
 				assert.Contains(t, response.Summary.AgentsRun, "Proactive Vulnerability Agent")
 				assert.Contains(t, response.Summary.AgentsRun, "Vulnerability Scanner")
+				// Every agent reports how it fared
+				assert.Len(t, response.Summary.AgentStatus, 4)
+				assert.Equal(t, "ok", response.Summary.AgentStatus["License Agent"])
+				// The effective retrieval settings are recorded for reproducibility
+				parameters := response.Summary.AgentParameters["Proactive Vulnerability Agent"]
+				assert.Equal(t, "5", parameters["top_k"])
+				assert.Equal(t, "0.3", parameters["similarity_threshold"])
+			},
+		},
+		{
+			name:        "Analysis with vulnerability scanning",
+			method:      "POST",
+			urlPath:     "/api/v1/sboms/test-sbom-789/analyze",
+			queryParams: "?enable-vuln-scan=true",
+			mockBehavior: func(mockRepo *MockRepository) {
+				testSBOM := &core.SBOM{
+					ID:   "test-sbom-789",
+					Name: "Test SBOM",
+				}
+				mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(testSBOM, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: func(t *testing.T, body []byte) {
+				var response AnalysisResponse
+				err := json.Unmarshal(body, &response)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"License Agent", "Vulnerability Scanner"}, response.Summary.AgentsRun)
+				assert.Equal(t, "ok", response.Summary.AgentStatus["Vulnerability Scanner"])
+			},
+		},
+		{
+			name:        "Analysis with a profile",
+			method:      "POST",
+			urlPath:     "/api/v1/sboms/test-sbom-789/analyze",
+			queryParams: "?profile=compliance-only&enable-vuln-scan=true",
+			mockBehavior: func(mockRepo *MockRepository) {
+				mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{ID: "test-sbom-789"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: func(t *testing.T, body []byte) {
+				var response AnalysisResponse
+				err := json.Unmarshal(body, &response)
+				assert.NoError(t, err)
+				// Explicit enable parameters add to the profile's agents
+				assert.ElementsMatch(t, []string{"License Agent", "SBOM Quality Agent", "Vulnerability Scanner"}, response.Summary.AgentsRun)
+			},
+		},
+		{
+			name:               "Unknown profile",
+			method:             "POST",
+			urlPath:            "/api/v1/sboms/test-sbom-789/analyze",
+			queryParams:        "?profile=thorough",
+			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: func(t *testing.T, body []byte) {
+				var response ErrorResponse
+				err := json.Unmarshal(body, &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid_parameter", response.Error)
+				assert.Contains(t, response.Message, `unknown profile "thorough"`)
+			},
+		},
+		{
+			name:        "Invalid RAG parameter",
+			method:      "POST",
+			urlPath:     "/api/v1/sboms/test-sbom-456/analyze",
+			queryParams: "?enable-proactive-scan=true&rag-similarity-threshold=1.5",
+			mockBehavior: func(mockRepo *MockRepository) {
+				// No expectations as parameters are validated first
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: func(t *testing.T, body []byte) {
+				var response ErrorResponse
+				err := json.Unmarshal(body, &response)
+				assert.NoError(t, err)
+				assert.Equal(t, "invalid_parameter", response.Error)
 			},
 		},
 		{
@@ -540,7 +1051,7 @@ func TestAnalyzeSBOMHandler(t *testing.T) {
 			rr := httptest.NewRecorder()
 
 			// Create handler and serve
-			handler := AnalyzeSBOMHandler(mockRepo)
+			handler := AnalyzeSBOMHandler(mockRepo, nil)
 			handler.ServeHTTP(rr, req)
 
 			// Check status code
@@ -557,6 +1068,57 @@ func TestAnalyzeSBOMHandler(t *testing.T) {
 	}
 }
 
+func TestAnalyzeSBOMHandler_AgentErrors(t *testing.T) {
+	// Force the health agent past its time limit so its results are missing
+	t.Setenv("AGENT_TIMEOUTS", "dependency-health-agent=1ns")
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{
+		ID:         "test-sbom-789",
+		Name:       "Test SBOM",
+		Components: []core.Component{{Name: "gpl-component", Version: "1.0.0", License: "GPL-3.0"}},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze?enable-ai-health-check=true", nil)
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response AnalysisResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+	// The license findings are still returned alongside the failure
+	assert.Equal(t, 1, response.Summary.TotalFindings)
+	assert.Equal(t, "timeout", response.Summary.AgentStatus["Dependency Health Agent"])
+	require.Len(t, response.Summary.AgentErrors, 1)
+	assert.Equal(t, "Dependency Health Agent", response.Summary.AgentErrors[0].Agent)
+	assert.Equal(t, "timeout", response.Summary.AgentErrors[0].Status)
+	assert.Contains(t, response.Summary.AgentErrors[0].Error, "timed out")
+	assert.Contains(t, response.Summary.AgentUsage, "License Agent")
+	assert.Nil(t, response.Summary.AgentUsage["License Agent"].LLM)
+}
+
+func TestAgentUsage(t *testing.T) {
+	usage := agentUsage(&analysis.OrchestratorReport{Runs: []analysis.AgentRun{
+		{Agent: "License Agent", Status: analysis.AgentStatusOK, Duration: 5 * time.Millisecond},
+		{
+			Agent:    "Dependency Health Agent",
+			Status:   analysis.AgentStatusOK,
+			Duration: 2 * time.Second,
+			LLMUsage: &analysis.LLMUsage{Requests: 3, PromptTokens: 300, CompletionTokens: 90, Duration: 1500 * time.Millisecond},
+		},
+	}})
+
+	assert.Equal(t, map[string]AgentUsage{
+		"License Agent": {WallTimeMS: 5},
+		"Dependency Health Agent": {
+			WallTimeMS: 2000,
+			LLM:        &LLMUsage{Requests: 3, PromptTokens: 300, CompletionTokens: 90, TotalTokens: 390, DurationMS: 1500},
+		},
+	}, usage)
+}
+
 func TestGenerateAnalysisSummary(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -695,3 +1257,178 @@ func createMultipartRequest(fieldName, fileName, content string) (*http.Request,
 
 	return req, nil
 }
+
+// fakeGate denies every Critical finding.
+type fakeGate struct{}
+
+func (fakeGate) Evaluate(ctx context.Context, input policy.Input) (*policy.Decision, error) {
+	decision := &policy.Decision{Violations: []string{}}
+	for _, finding := range input.Findings {
+		if finding.Severity == "Critical" {
+			decision.Violations = append(decision.Violations, "critical finding in "+finding.Component.Name)
+		}
+	}
+	return decision, nil
+}
+
+func TestAnalyzeSBOMHandler_Policy(t *testing.T) {
+	original := gatePolicy
+	gatePolicy = func() policy.Evaluator { return fakeGate{} }
+	t.Cleanup(func() { gatePolicy = original })
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{
+		ID:         "test-sbom-789",
+		Name:       "Test SBOM",
+		Components: []core.Component{{Name: "agpl-component", Version: "1.0.0", License: "AGPL-3.0-only"}},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze", nil)
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response AnalysisResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.NotNil(t, response.Summary.Policy)
+	assert.False(t, response.Summary.Policy.Passed)
+	assert.Equal(t, []string{"critical finding in agpl-component"}, response.Summary.Policy.Violations)
+}
+
+func TestAnalyzeSBOMHandler_MinSeverity(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{
+		ID:   "test-sbom-789",
+		Name: "Test SBOM",
+		Components: []core.Component{
+			{Name: "agpl-component", Version: "1.0.0", License: "AGPL-3.0-only"},
+			{Name: "lgpl-component", Version: "1.0.0", License: "LGPL-2.1-only"},
+		},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze?min_severity=high", nil)
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response AnalysisResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, "Critical", response.Results[0].Severity)
+	assert.Equal(t, 1, response.Summary.HiddenFindings)
+	assert.Equal(t, 2, response.Summary.TotalFindings)
+}
+
+func TestAnalyzeSBOMHandler_Waivers(t *testing.T) {
+	configtest.Set(t, `waivers:
+  - match: component:agpl-component
+    reason: Internal tool, never distributed
+    projects: [Test SBOM]
+  - match: component:lgpl-component
+    reason: Waived for another project
+    projects: [storefront]
+`)
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{
+		ID:   "test-sbom-789",
+		Name: "Test SBOM",
+		Components: []core.Component{
+			{Name: "agpl-component", Version: "1.0.0", License: "AGPL-3.0-only"},
+			{Name: "lgpl-component", Version: "1.0.0", License: "LGPL-2.1-only"},
+		},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze", nil)
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response AnalysisResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, "lgpl-component", response.Results[0].Component.Name)
+	assert.Equal(t, 1, response.Summary.TotalFindings, "waived findings count for nothing")
+	require.Len(t, response.Summary.Waived, 1)
+	assert.Equal(t, "agpl-component", response.Summary.Waived[0].Component.Name)
+	assert.Equal(t, "Internal tool, never distributed", response.Summary.Waived[0].Reason)
+}
+
+func TestAnalyzeSBOMHandler_InvalidMinSeverity(t *testing.T) {
+	mockRepo := new(MockRepository)
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze?min_severity=severe", nil)
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid_parameter")
+	mockRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+}
+
+func TestAnalyzeSBOMHandler_MinConfidence(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{
+		ID:         "test-sbom-789",
+		Name:       "Test SBOM",
+		Components: []core.Component{{Name: "agpl-component", Version: "1.0.0", License: "AGPL-3.0-only"}},
+	}, nil)
+
+	// Findings of deterministic agents have no confidence and are kept
+	req := httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze?min_confidence=0.9", nil)
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response AnalysisResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response.Results, 1)
+	assert.Zero(t, response.Summary.HiddenFindings)
+
+	req = httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze?min_confidence=high", nil)
+	rr = httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "min_confidence")
+}
+
+// fakeNotifier passes notifications to a channel.
+type fakeNotifier chan notify.Notification
+
+func (f fakeNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	f <- notification
+	return nil
+}
+
+func TestAnalyzeSBOMHandler_Notifications(t *testing.T) {
+	notifications := make(fakeNotifier, 1)
+	original := findingNotifier
+	findingNotifier = func() notify.Notifier { return notifications }
+	t.Cleanup(func() { findingNotifier = original })
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{
+		ID:         "test-sbom-789",
+		Name:       "Test SBOM",
+		Components: []core.Component{{Name: "agpl-component", Version: "1.0.0", License: "AGPL-3.0-only"}},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze", nil)
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	select {
+	case notification := <-notifications:
+		assert.Equal(t, "test-sbom-789", notification.SBOMID)
+		assert.Equal(t, "Test SBOM", notification.SBOMName)
+		require.Len(t, notification.Results, 1)
+		assert.Equal(t, "License Agent", notification.Results[0].AgentName)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification sent")
+	}
+}
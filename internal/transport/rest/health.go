@@ -0,0 +1,123 @@
+// Package rest provides the liveness and readiness endpoints probed by
+// orchestrators such as Kubernetes.
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthCheckTimeout bounds each dependency check of a readiness probe.
+const HealthCheckTimeout = 2 * time.Second
+
+// Health statuses of the server and its dependencies.
+const (
+	// HealthOK means every dependency is available
+	HealthOK = "ok"
+	// HealthDegraded means an optional dependency, such as Ollama, is
+	// unavailable and the features using it fail
+	HealthDegraded = "degraded"
+	// HealthUnavailable means a critical dependency is unavailable and the
+	// server cannot serve requests
+	HealthUnavailable = "unavailable"
+	// HealthError is the status of a dependency whose check failed
+	HealthError = "error"
+)
+
+// HealthCheck checks one dependency of the server.
+type HealthCheck struct {
+	Name string
+	// Critical dependencies make the server unready when they fail; others
+	// only degrade it
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// DependencyHealth is the outcome of a HealthCheck.
+type DependencyHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// HealthResponse represents the JSON response of the health endpoints.
+type HealthResponse struct {
+	Status       string             `json:"status"`
+	Service      string             `json:"service"`
+	Dependencies []DependencyHealth `json:"dependencies,omitempty"`
+}
+
+// LivenessHandler creates an HTTP handler reporting that the server process
+// is running. It checks no dependencies, so an orchestrator does not restart
+// the server for an outage elsewhere.
+func LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		writeJSONResponse(w, http.StatusOK, HealthResponse{Status: HealthOK, Service: "sbom-sentinel"})
+	}
+}
+
+// ReadinessHandler creates an HTTP handler running every check concurrently
+// and reporting the status of each dependency. It responds 503 Service
+// Unavailable if a critical dependency failed, so that an orchestrator stops
+// routing requests to the server, and 200 OK otherwise.
+func ReadinessHandler(checks []HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		dependencies := make([]DependencyHealth, len(checks))
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				dependencies[i] = runHealthCheck(r.Context(), check)
+			}()
+		}
+		wg.Wait()
+
+		response := HealthResponse{Status: HealthOK, Service: "sbom-sentinel", Dependencies: dependencies}
+		statusCode := http.StatusOK
+		for _, dependency := range dependencies {
+			if dependency.Status == HealthOK {
+				continue
+			}
+			if dependency.Critical {
+				response.Status = HealthUnavailable
+				statusCode = http.StatusServiceUnavailable
+				break
+			}
+			response.Status = HealthDegraded
+		}
+
+		writeJSONResponse(w, statusCode, response)
+	}
+}
+
+// runHealthCheck runs a check within HealthCheckTimeout.
+func runHealthCheck(ctx context.Context, check HealthCheck) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	health := DependencyHealth{
+		Name:      check.Name,
+		Status:    HealthOK,
+		Critical:  check.Critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		health.Status = HealthError
+		health.Error = err.Error()
+		if ctx.Err() == context.DeadlineExceeded {
+			health.Error = fmt.Sprintf("no response within %s", HealthCheckTimeout)
+		}
+	}
+	return health
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	LivenessHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status": "ok", "service": "sbom-sentinel"}`, rr.Body.String())
+}
+
+func TestReadinessHandler(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name           string
+		checks         []HealthCheck
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name:           "All dependencies available",
+			checks:         []HealthCheck{{Name: "database", Critical: true, Check: ok}, {Name: "ollama", Check: ok}},
+			expectedCode:   http.StatusOK,
+			expectedStatus: HealthOK,
+		},
+		{
+			name:           "Optional dependency down",
+			checks:         []HealthCheck{{Name: "database", Critical: true, Check: ok}, {Name: "ollama", Check: down}},
+			expectedCode:   http.StatusOK,
+			expectedStatus: HealthDegraded,
+		},
+		{
+			name:           "Critical dependency down",
+			checks:         []HealthCheck{{Name: "database", Critical: true, Check: down}, {Name: "ollama", Check: down}},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: HealthUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			ReadinessHandler(tt.checks).ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			var response HealthResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			require.Len(t, response.Dependencies, len(tt.checks))
+			for i, dependency := range response.Dependencies {
+				assert.Equal(t, tt.checks[i].Name, dependency.Name)
+				assert.Equal(t, tt.checks[i].Critical, dependency.Critical)
+			}
+		})
+	}
+
+	t.Run("Checks time out", func(t *testing.T) {
+		// The probe's own deadline applies before HealthCheckTimeout
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rr := httptest.NewRecorder()
+		ReadinessHandler([]HealthCheck{{Name: "vector_store", Check: hanging}}).ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil).WithContext(ctx))
+
+		var response HealthResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, HealthError, response.Dependencies[0].Status)
+		assert.Equal(t, "context canceled", response.Dependencies[0].Error)
+	})
+}
@@ -0,0 +1,222 @@
+// Package rest provides idempotent handling of retried requests.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// IdempotencyKeyHeader is the request header identifying a request across
+// retries.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyRetention is how long the response to an idempotent request
+// is replayed to retries.
+const IdempotencyRetention = 24 * time.Hour
+
+// idempotencyClaimTimeout is how long a request holds its key against
+// retries, so that a key claimed by a server that stopped before answering
+// is freed.
+const idempotencyClaimTimeout = 10 * time.Minute
+
+// maxIdempotencyKeyLength bounds the length of an Idempotency-Key.
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers recorded and replayed with the
+// response body.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// Idempotency replays the response to a POST request made with an
+// Idempotency-Key header when the request is retried with the same key, so
+// that retries do not submit or queue the same work twice. Keys are scoped
+// to the API key making the request, so that callers cannot collide with or
+// replay each other's requests.
+type Idempotency struct {
+	store storage.IdempotencyStore
+}
+
+// NewIdempotency returns an Idempotency recording responses in store. With
+// a nil store, requests are handled as if they had no Idempotency-Key.
+func NewIdempotency(store storage.IdempotencyStore) *Idempotency {
+	return &Idempotency{store: store}
+}
+
+// Idempotent wraps next so that a successful response to a POST request
+// with an Idempotency-Key is recorded for IdempotencyRetention and replayed,
+// with an Idempotent-Replayed header, to requests with the same key.
+// Reusing a key for a different request is rejected with 422 Unprocessable
+// Entity, and retrying while the first request is still being handled, by
+// any server sharing the store, with 409 Conflict. Failed responses are not recorded, so the request can be
+// retried with the same key.
+func (i *Idempotency) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if i.store == nil || key == "" || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_idempotency_key", fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		// The body is read to identify the request, and handed on to next
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writePayloadTooLarge(w, tooLarge.Limit)
+				return
+			}
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(r, body)
+		key = callerID(r) + ":" + key
+
+		ctx := r.Context()
+		now := core.Now()
+		claim := storage.IdempotentResponse{Key: key, Fingerprint: fingerprint, CreatedAt: now}
+		recorded, err := i.store.ClaimIdempotencyKey(ctx, claim, now.Add(-IdempotencyRetention), now.Add(-idempotencyClaimTimeout))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to claim idempotency key: %v", err))
+			return
+		}
+		if recorded != nil {
+			if recorded.Fingerprint != fingerprint {
+				w.Header().Set("Content-Type", "application/json")
+				writeErrorResponse(w, http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+				return
+			}
+			if recorded.StatusCode == 0 {
+				w.Header().Set("Content-Type", "application/json")
+				writeErrorResponse(w, http.StatusConflict, "idempotency_key_in_use", "A request with this Idempotency-Key is still being processed")
+				return
+			}
+			for name, value := range recorded.Header {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(recorded.StatusCode)
+			w.Write(recorded.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		next(recorder, r)
+		// The outcome is recorded even if the client has gone
+		ctx = context.WithoutCancel(ctx)
+		if recorder.statusCode < 200 || recorder.statusCode > 299 {
+			// The request may be retried with the key
+			if err := i.store.ReleaseIdempotencyKey(ctx, key); err != nil {
+				fmt.Printf("Warning: Failed to release idempotency key: %v\n", err)
+			}
+			return
+		}
+
+		response := storage.IdempotentResponse{
+			Key:         key,
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.statusCode,
+			Header:      make(map[string]string),
+			Body:        recorder.body.Bytes(),
+			CreatedAt:   core.Now(),
+		}
+		for _, name := range replayedHeaders {
+			if value := w.Header().Get(name); value != "" {
+				response.Header[name] = value
+			}
+		}
+		if err := i.store.SaveIdempotentResponse(ctx, response); err != nil {
+			fmt.Printf("Warning: Failed to record idempotent response: %v\n", err)
+		}
+		if err := i.store.DeleteIdempotentResponses(ctx, response.CreatedAt.Add(-IdempotencyRetention)); err != nil {
+			fmt.Printf("Warning: Failed to delete expired idempotent responses: %v\n", err)
+		}
+	}
+}
+
+// requestFingerprint identifies a request by its method, URL and body. The
+// parts of multipart bodies are hashed rather than the body, whose boundary
+// clients choose anew for every attempt.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && mediaType == "multipart/form-data" && params["boundary"] != "" {
+		if parts, ok := multipartDigests(body, params["boundary"]); ok {
+			for _, part := range parts {
+				fmt.Fprintln(hash, part)
+			}
+			return hex.EncodeToString(hash.Sum(nil))
+		}
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// multipartDigests returns the form names, file names and content digests
+// of the parts of a multipart body, sorted, or false if it does not parse.
+func multipartDigests(body []byte, boundary string) ([]string, bool) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		content := sha256.New()
+		if _, err := io.Copy(content, part); err != nil {
+			return nil, false
+		}
+		parts = append(parts, fmt.Sprintf("%q %q %x", part.FormName(), part.FileName(), content.Sum(nil)))
+	}
+	sort.Strings(parts)
+	return parts, true
+}
+
+// responseRecorder records the status and body of a response while writing
+// it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *responseRecorder) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/jobs"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSubmission returns a submission of an SBOM with the given component,
+// encoded with a new multipart boundary as every client attempt is.
+func newSubmission(t *testing.T, key, component string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("sbom", "sbom.json")
+	require.NoError(t, err)
+	part.Write([]byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4", "components": [{"type": "library", "name": "` + component + `", "version": "1.0.0"}]}`))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/api/v1/sboms", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req
+}
+
+func TestIdempotency_Submission(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	handler := DefaultLimits.Limit(NewIdempotency(repo).Idempotent(SubmitSBOMHandler(repo)))
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	first := serve(newSubmission(t, "build-42", "left-pad"))
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	// A retry is answered with the first response without storing the SBOM again
+	retry := serve(newSubmission(t, "build-42", "left-pad"))
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	stored, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+
+	// The key cannot be reused for another SBOM
+	rr := serve(newSubmission(t, "build-42", "right-pad"))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "idempotency_key_reused")
+
+	rr = serve(newSubmission(t, strings.Repeat("k", maxIdempotencyKeyLength+1), "left-pad"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Failed requests are not recorded, so they can be retried with the key
+	invalid := httptest.NewRequest("POST", "/api/v1/sboms", strings.NewReader("not a form"))
+	invalid.Header.Set(IdempotencyKeyHeader, "build-43")
+	assert.Equal(t, http.StatusBadRequest, serve(invalid).Code)
+	assert.Equal(t, http.StatusCreated, serve(newSubmission(t, "build-43", "right-pad")).Code)
+}
+
+func TestIdempotency_AsyncAnalysis(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+	queue := jobs.NewMemoryQueue()
+	clock := core.NewFixedClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer core.SetClock(clock)()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sboms/{id}/analyze", NewIdempotency(repo).Idempotent(AsyncAnalyzeHandler(repo, queue, AnalyzeSBOMHandler(repo, nil))))
+	analyze := func(key string) jobs.Job {
+		req := httptest.NewRequest("POST", "/api/v1/sboms/sbom-1/analyze?async=true", nil)
+		req.Header.Set(IdempotencyKeyHeader, key)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+		var job jobs.Job
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &job))
+		assert.Equal(t, "/api/v1/jobs/"+job.ID, rr.Header().Get("Location"))
+		return job
+	}
+
+	first := analyze("scan-1")
+	assert.Equal(t, first.ID, analyze("scan-1").ID)
+	queued, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, queued.ID)
+
+	// Keys expire after IdempotencyRetention
+	clock.Advance(IdempotencyRetention + time.Second)
+	assert.NotEqual(t, first.ID, analyze("scan-1").ID)
+}
+
+func TestIdempotency_Callers(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	handler := DefaultLimits.Limit(NewIdempotency(repo).Idempotent(SubmitSBOMHandler(repo)))
+	submit := func(apiKey, component string) *httptest.ResponseRecorder {
+		req := newSubmission(t, "build-42", component)
+		req.Header.Set("X-API-Key", apiKey)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	// Each caller's keys are their own
+	assert.Equal(t, http.StatusCreated, submit("k3y-for-ci", "left-pad").Code)
+	other := submit("k3y-for-ops", "right-pad")
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.Empty(t, other.Header().Get("Idempotent-Replayed"))
+	stored, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, stored, 2)
+	assert.Equal(t, "true", submit("k3y-for-ci", "left-pad").Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	started, finish := make(chan struct{}), make(chan struct{})
+	slow := NewIdempotency(repo).Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-finish
+		w.WriteHeader(http.StatusCreated)
+	})
+	done := make(chan int)
+	go func() {
+		rr := httptest.NewRecorder()
+		slow(rr, newSubmission(t, "build-42", "left-pad"))
+		done <- rr.Code
+	}()
+	<-started
+
+	// Another server sharing the store finds the key claimed
+	other := NewIdempotency(repo).Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request handled twice")
+	})
+	rr := httptest.NewRecorder()
+	other(rr, newSubmission(t, "build-42", "left-pad"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "idempotency_key_in_use")
+
+	close(finish)
+	assert.Equal(t, http.StatusCreated, <-done)
+	rr = httptest.NewRecorder()
+	other(rr, newSubmission(t, "build-42", "left-pad"))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get("Idempotent-Replayed"))
+}
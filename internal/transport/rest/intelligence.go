@@ -0,0 +1,114 @@
+// Package rest provides HTTP handlers for the security intelligence corpus.
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+)
+
+// IntelligenceStatusHandler creates an HTTP handler reporting the state of the
+// shared security intelligence corpus. It expects a GET request to
+// /api/v1/intelligence/status and returns the corpus size, configured sources
+// and the outcome of the last refresh.
+func IntelligenceStatusHandler(intelligence *vectordb.IntelligenceStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(intelligence.Status()); err != nil {
+			// Log the error, but response has already been started
+			fmt.Printf("Error encoding response: %v\n", err)
+		}
+	}
+}
+
+// IntelligenceDocumentsResponse represents the JSON response listing manually added documents.
+type IntelligenceDocumentsResponse struct {
+	TotalDocuments int                             `json:"total_documents"`
+	Documents      []vectordb.SecurityIntelligence `json:"documents"`
+}
+
+// IntelligenceDocumentsHandler creates an HTTP handler for managing documents
+// in the security intelligence corpus, such as internal advisories:
+//
+//	GET    /api/v1/intelligence/documents      - list manually added documents
+//	POST   /api/v1/intelligence/documents      - add or replace a document (JSON body)
+//	DELETE /api/v1/intelligence/documents/{id} - remove a document (or ?id={id})
+func IntelligenceDocumentsHandler(intelligence *vectordb.IntelligenceStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			documents := intelligence.Documents()
+			writeJSONResponse(w, http.StatusOK, IntelligenceDocumentsResponse{
+				TotalDocuments: len(documents),
+				Documents:      documents,
+			})
+
+		case http.MethodPost:
+			var document vectordb.SecurityIntelligence
+			if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_json", fmt.Sprintf("Failed to parse document: %v", err))
+				return
+			}
+
+			added, err := intelligence.AddDocument(r.Context(), document)
+			if errors.Is(err, vectordb.ErrInvalidDocument) {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_document", err.Error())
+				return
+			}
+			if err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, "ingestion_error", fmt.Sprintf("Failed to add document: %v", err))
+				return
+			}
+			writeJSONResponse(w, http.StatusCreated, added)
+
+		case http.MethodDelete:
+			// Document IDs may contain slashes, so everything after the prefix is the ID
+			id := r.PathValue("id")
+			if id == "" {
+				_, id, _ = strings.Cut(r.URL.Path, "/intelligence/documents")
+				id = strings.Trim(id, "/")
+			}
+			if id == "" {
+				id = r.URL.Query().Get("id")
+			}
+			if id == "" {
+				writeErrorResponse(w, http.StatusBadRequest, "missing_id", "Document ID is required in URL path or as query parameter")
+				return
+			}
+
+			if !intelligence.RemoveDocument(id) {
+				writeErrorResponse(w, http.StatusNotFound, "not_found", "Document not found")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET, POST and DELETE methods are allowed")
+		}
+	}
+}
+
+// writeJSONResponse writes value as a JSON response with the given status code.
+func writeJSONResponse(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		// Log the error, but response has already been started
+		fmt.Printf("Error encoding response: %v\n", err)
+	}
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntelligenceStatusHandler(t *testing.T) {
+	db := vectordb.NewMemoryVectorDB()
+	require.NoError(t, db.Add(vectordb.Document{ID: "CVE-2024-0001", Vector: []float64{1, 0}}))
+	intelligence := vectordb.NewIntelligenceStore(db, nil)
+	require.NoError(t, intelligence.EnsureInitialized(context.Background()))
+
+	handler := IntelligenceStatusHandler(intelligence)
+
+	req := httptest.NewRequest("GET", "/api/v1/intelligence/status", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var status vectordb.IntelligenceStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	assert.True(t, status.Initialized)
+	assert.Equal(t, 1, status.Documents)
+	assert.Empty(t, status.Sources)
+
+	req = httptest.NewRequest("POST", "/api/v1/intelligence/status", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestIntelligenceDocumentsHandler(t *testing.T) {
+	newStore := func() *vectordb.IntelligenceStore {
+		db := vectordb.NewMemoryVectorDB()
+		require.NoError(t, db.Add(vectordb.Document{ID: "GHSA-xxxx/lodash", Vector: []float64{1, 0}}))
+		return vectordb.NewIntelligenceStore(db, vectordb.NewHarvester(db))
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		expectedStatusCode int
+		expectedError      string
+	}{
+		{
+			name:               "List documents",
+			method:             "GET",
+			path:               "/api/v1/intelligence/documents",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Add document with invalid JSON",
+			method:             "POST",
+			path:               "/api/v1/intelligence/documents",
+			body:               "{",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      "invalid_json",
+		},
+		{
+			name:               "Add document without title",
+			method:             "POST",
+			path:               "/api/v1/intelligence/documents",
+			body:               `{"id":"INT-1"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      "invalid_document",
+		},
+		{
+			name:               "Delete document by path",
+			method:             "DELETE",
+			path:               "/api/v1/intelligence/documents/GHSA-xxxx/lodash",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "Delete document by query",
+			method:             "DELETE",
+			path:               "/api/v1/intelligence/documents?id=GHSA-xxxx/lodash",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "Delete unknown document",
+			method:             "DELETE",
+			path:               "/api/v1/intelligence/documents/INT-404",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError:      "not_found",
+		},
+		{
+			name:               "Delete without ID",
+			method:             "DELETE",
+			path:               "/api/v1/intelligence/documents",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      "missing_id",
+		},
+		{
+			name:               "Wrong HTTP method",
+			method:             "PUT",
+			path:               "/api/v1/intelligence/documents",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedError:      "method_not_allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := IntelligenceDocumentsHandler(newStore())
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errorResponse))
+				assert.Equal(t, tt.expectedError, errorResponse.Error)
+			}
+
+			if tt.method == "GET" {
+				var response IntelligenceDocumentsResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, 0, response.TotalDocuments)
+				assert.NotNil(t, response.Documents)
+			}
+		})
+	}
+}
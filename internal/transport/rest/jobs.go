@@ -0,0 +1,142 @@
+// Package rest provides HTTP handlers for asynchronous analysis jobs.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/jobs"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+)
+
+// AsyncAnalyzeHandler wraps the analyze endpoint so that POST
+// /api/v1/sboms/{id}/analyze?async=true queues the analysis instead of
+// running it, with ?priority=scheduled queueing it behind interactive
+// analyses. The queued job is returned with 202 Accepted and a Location
+// header pointing to its status; without async the request is passed to
+// next.
+func AsyncAnalyzeHandler(repo storage.Repository, queue jobs.Queue, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("async") != "true" {
+			next(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		sbomID := r.PathValue("id")
+		if sbomID == "" {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "SBOM ID is required in URL path")
+			return
+		}
+
+		// Report invalid parameters now rather than in the job
+		query := r.URL.Query()
+		query.Del("async")
+		priority := jobs.PriorityInteractive
+		if query.Has("priority") {
+			priority = query.Get("priority")
+			query.Del("priority")
+		}
+		if err := jobs.ValidatePriority(priority); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+		if _, err := parseAnalysisOptions(query, nil); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		sbom, err := repo.FindByID(ctx, sbomID)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to retrieve SBOM: %v", err))
+			return
+		}
+		if sbom == nil {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "SBOM not found")
+			return
+		}
+
+		job := jobs.New(sbomID, query)
+		job.Priority = priority
+		if err := queue.Enqueue(ctx, job); err != nil {
+			writeErrorResponse(w, http.StatusServiceUnavailable, "queue_error", fmt.Sprintf("Failed to queue analysis: %v", err))
+			return
+		}
+
+		w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+		w.WriteHeader(http.StatusAccepted)
+		if err := json.NewEncoder(w).Encode(job); err != nil {
+			// Log the error, but response has already been started
+			fmt.Printf("Error encoding response: %v\n", err)
+		}
+	}
+}
+
+// JobHandler creates an HTTP handler for analysis jobs at
+// /api/v1/jobs/{id}: GET returns the status of a job, with the analysis
+// response once it succeeded, and DELETE cancels a queued or running job.
+func JobHandler(queue jobs.Queue) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var job *jobs.Job
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			job, err = queue.Get(r.Context(), r.PathValue("id"))
+		case http.MethodDelete:
+			job, err = jobs.Cancel(r.Context(), queue, r.PathValue("id"))
+			if errors.Is(err, jobs.ErrFinished) {
+				writeErrorResponse(w, http.StatusConflict, "conflict", fmt.Sprintf("Job already %s", job.Status))
+				return
+			}
+		default:
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET and DELETE methods are allowed")
+			return
+		}
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "queue_error", fmt.Sprintf("Failed to retrieve job: %v", err))
+			return
+		}
+		if job == nil {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "Job not found")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(job); err != nil {
+			// Log the error, but response has already been started
+			fmt.Printf("Error encoding response: %v\n", err)
+		}
+	}
+}
+
+// AnalysisJobHandler returns the jobs.Handler that runs queued analyses
+// like the analyze endpoint, with the same intelligence corpus.
+func AnalysisJobHandler(repo storage.Repository, intelligence *vectordb.IntelligenceStore) jobs.Handler {
+	return func(ctx context.Context, job jobs.Job) (json.RawMessage, error) {
+		options, err := parseAnalysisOptions(job.Query(), intelligence)
+		if err != nil {
+			return nil, err
+		}
+		sbom, err := repo.FindByID(ctx, job.SBOMID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve SBOM: %w", err)
+		}
+		if sbom == nil {
+			return nil, errors.New("SBOM not found")
+		}
+
+		response, err := analyzeSBOM(ctx, repo, *sbom, options)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(response)
+	}
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/jobs"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncAnalyzeHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Components: []core.Component{
+		{Name: "readline", Version: "8.2", License: "GPL-3.0"},
+	}}))
+	queue := jobs.NewMemoryQueue()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sboms/{id}/analyze", AsyncAnalyzeHandler(repo, queue, AnalyzeSBOMHandler(repo, nil)))
+	mux.HandleFunc("/api/v1/jobs/{id}", JobHandler(queue))
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	// The analysis is queued rather than run
+	rr := serve("POST", "/api/v1/sboms/sbom-1/analyze?async=true&min_severity=high")
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	var job jobs.Job
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &job))
+	assert.Equal(t, jobs.StatusQueued, job.Status)
+	assert.Equal(t, "min_severity=high", job.Parameters)
+	assert.Equal(t, "/api/v1/jobs/"+job.ID, rr.Header().Get("Location"))
+
+	rr = serve("GET", "/api/v1/jobs/"+job.ID)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// A worker runs it like the analyze endpoint
+	queued, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	result, err := AnalysisJobHandler(repo, nil)(ctx, *queued)
+	require.NoError(t, err)
+	var response AnalysisResponse
+	require.NoError(t, json.Unmarshal(result, &response))
+	assert.Equal(t, "sbom-1", response.SBOMID)
+	assert.Equal(t, 1, response.Summary.TotalFindings)
+
+	// Without async the analysis runs synchronously
+	rr = serve("POST", "/api/v1/sboms/sbom-1/analyze")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		expectedStatusCode int
+	}{
+		{"Unknown SBOM", "POST", "/api/v1/sboms/unknown/analyze?async=true", http.StatusNotFound},
+		{"Invalid parameter", "POST", "/api/v1/sboms/sbom-1/analyze?async=true&min_severity=bogus", http.StatusBadRequest},
+		{"Invalid priority", "POST", "/api/v1/sboms/sbom-1/analyze?async=true&priority=urgent", http.StatusBadRequest},
+		{"Unknown job", "GET", "/api/v1/jobs/unknown", http.StatusNotFound},
+		{"Cancel unknown job", "DELETE", "/api/v1/jobs/unknown", http.StatusNotFound},
+		{"Wrong HTTP method", "POST", "/api/v1/jobs/" + job.ID, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatusCode, serve(tt.method, tt.path).Code)
+		})
+	}
+
+	// Scheduled re-scans can be canceled while queued, but only once
+	rr = serve("POST", "/api/v1/sboms/sbom-1/analyze?async=true&priority=scheduled")
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	var rescan jobs.Job
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rescan))
+	assert.Equal(t, jobs.PriorityScheduled, rescan.Priority)
+	assert.Empty(t, rescan.Parameters)
+	rr = serve("DELETE", "/api/v1/jobs/"+rescan.ID)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rescan))
+	assert.Equal(t, jobs.StatusCanceled, rescan.Status)
+	assert.Equal(t, http.StatusConflict, serve("DELETE", "/api/v1/jobs/"+rescan.ID).Code)
+
+	// Jobs of SBOMs deleted since they were queued fail
+	_, err = AnalysisJobHandler(repo, nil)(ctx, jobs.New("deleted", nil))
+	assert.ErrorContains(t, err, "SBOM not found")
+}
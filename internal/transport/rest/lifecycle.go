@@ -0,0 +1,99 @@
+// Package rest provides the HTTP handler for the finding lifecycle of
+// projects across analyses.
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/lifecycle"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// ProjectFindingsHandler creates an HTTP handler reporting the finding
+// lifecycle of a project: the new, recurring and resolved findings of each
+// recorded analysis, the tracked findings and the mean time to remediate.
+// It expects a GET request to /api/v1/projects/{id}/findings, where id is the
+// project name shared by the SBOM versions; ?status=open or ?status=resolved
+// lists only those findings, without changing the counts, as do ?triage=
+// with a triage state, ?assignee= (empty for unassigned findings) and
+// ?overdue=true, which lists the open findings past the due date set by the
+// SLA of their severity in the configuration file.
+func ProjectFindingsHandler(history storage.FindingHistory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		project := r.PathValue("id")
+		if project == "" {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "Project is required in URL path")
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		if status != "" && status != storage.FindingOpen && status != storage.FindingResolved {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "status must be open or resolved")
+			return
+		}
+
+		query := r.URL.Query()
+		triage := query.Get("triage")
+		if triage != "" && !slices.Contains(storage.TriageStates, triage) {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "triage must be one of "+strings.Join(storage.TriageStates, ", "))
+			return
+		}
+		_, byAssignee := query["assignee"]
+		assignee := query.Get("assignee")
+		overdue := false
+		if value := query.Get("overdue"); value != "" {
+			var err error
+			if overdue, err = strconv.ParseBool(value); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "overdue must be true or false")
+				return
+			}
+		}
+
+		ctx := r.Context()
+		runs, err := history.AnalysisRuns(ctx, project)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to list analyses: %v", err))
+			return
+		}
+		if len(runs) == 0 {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "No analyses recorded for project")
+			return
+		}
+		findings, err := history.ProjectFindings(ctx, project)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to list findings: %v", err))
+			return
+		}
+
+		config.Default().SLA.Annotate(findings, core.Now())
+		timeline := lifecycle.NewTimeline(project, runs, findings)
+		if status != "" || triage != "" || byAssignee || overdue {
+			filtered := make([]storage.FindingRecord, 0, len(timeline.Findings))
+			for _, finding := range timeline.Findings {
+				if (status == "" || finding.Status == status) &&
+					(triage == "" || finding.Triage == triage) &&
+					(!byAssignee || finding.Assignee == assignee) &&
+					(!overdue || finding.Overdue) {
+					filtered = append(filtered, finding)
+				}
+			}
+			timeline.Findings = filtered
+		}
+		writeJSONResponse(w, http.StatusOK, timeline)
+	}
+}
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/lifecycle"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFindingHistory serves a fixed finding history.
+type fakeFindingHistory struct {
+	runs []storage.AnalysisRun
+	// findings are keyed by project
+	findings map[string][]storage.FindingRecord
+}
+
+func (f *fakeFindingHistory) RecordAnalysis(ctx context.Context, run storage.AnalysisRun, agents []string, findings []storage.FindingRecord) (*storage.AnalysisRun, error) {
+	f.runs = append(f.runs, run)
+	return &run, nil
+}
+
+func (f *fakeFindingHistory) AnalysisRuns(ctx context.Context, project string) ([]storage.AnalysisRun, error) {
+	var runs []storage.AnalysisRun
+	for _, run := range f.runs {
+		if run.Project == project {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func (f *fakeFindingHistory) ProjectFindings(ctx context.Context, project string) ([]storage.FindingRecord, error) {
+	return f.findings[project], nil
+}
+
+func TestProjectFindingsHandler(t *testing.T) {
+	firstSeen := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	resolvedAt := firstSeen.Add(24 * time.Hour)
+	history := &fakeFindingHistory{
+		runs: []storage.AnalysisRun{
+			{SBOMID: "api-1", Project: "api", AnalyzedAt: firstSeen, New: 2, Open: 2},
+			{SBOMID: "api-2", Project: "api", AnalyzedAt: resolvedAt, Recurring: 1, Resolved: 1, Open: 1},
+		},
+		findings: map[string][]storage.FindingRecord{"api": {
+			{Fingerprint: "a", Severity: "High", Status: storage.FindingResolved, FirstSeen: firstSeen, ResolvedAt: &resolvedAt},
+			{Fingerprint: "b", Severity: "Low", Status: storage.FindingOpen, FirstSeen: firstSeen},
+		}},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/{id}/findings", ProjectFindingsHandler(history))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	rr := serve("/api/v1/projects/api/findings")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var timeline lifecycle.Timeline
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &timeline))
+	assert.Len(t, timeline.Runs, 2)
+	assert.Equal(t, 1, timeline.Open)
+	assert.Equal(t, 1, timeline.Resolved)
+	require.NotNil(t, timeline.MeanTimeToRemediateHours)
+	assert.Equal(t, 24.0, *timeline.MeanTimeToRemediateHours)
+	require.Len(t, timeline.Findings, 2)
+	assert.Equal(t, "b", timeline.Findings[0].Fingerprint)
+
+	rr = serve("/api/v1/projects/api/findings?status=resolved")
+	require.Equal(t, http.StatusOK, rr.Code)
+	timeline = lifecycle.Timeline{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &timeline))
+	require.Len(t, timeline.Findings, 1)
+	assert.Equal(t, "a", timeline.Findings[0].Fingerprint)
+	assert.Equal(t, 1, timeline.Open)
+
+	assert.Equal(t, http.StatusBadRequest, serve("/api/v1/projects/api/findings?status=fixed").Code)
+	assert.Equal(t, http.StatusNotFound, serve("/api/v1/projects/web/findings").Code)
+}
@@ -0,0 +1,112 @@
+// Package rest provides the size limits applied to SBOM submissions and the
+// middleware enforcing them.
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Limits bounds the size of submitted SBOMs.
+type Limits struct {
+	// MaxUploadBytes is the largest request body accepted
+	MaxUploadBytes int64
+	// MaxComponents is the most components an SBOM may have
+	MaxComponents int
+}
+
+// DefaultLimits are the limits applied without MAX_UPLOAD_SIZE and
+// MAX_SBOM_COMPONENTS.
+var DefaultLimits = Limits{
+	MaxUploadBytes: 32 << 20,
+	MaxComponents:  50000,
+}
+
+// LimitsFromEnv returns the limits configured by MAX_UPLOAD_SIZE, a number
+// of bytes with an optional KB, MB or GB suffix such as "64MB", and
+// MAX_SBOM_COMPONENTS. Invalid values are reported and the defaults kept.
+func LimitsFromEnv() (Limits, error) {
+	limits := DefaultLimits
+	var errs []error
+
+	if value := os.Getenv("MAX_UPLOAD_SIZE"); value != "" {
+		size, err := parseByteSize(value)
+		if err != nil || size < 1 {
+			errs = append(errs, fmt.Errorf("invalid MAX_UPLOAD_SIZE %q", value))
+		} else {
+			limits.MaxUploadBytes = size
+		}
+	}
+
+	if value := os.Getenv("MAX_SBOM_COMPONENTS"); value != "" {
+		components, err := strconv.Atoi(value)
+		if err != nil || components < 1 {
+			errs = append(errs, fmt.Errorf("invalid MAX_SBOM_COMPONENTS %q", value))
+		} else {
+			limits.MaxComponents = components
+		}
+	}
+
+	return limits, errors.Join(errs...)
+}
+
+// parseByteSize parses a number of bytes with an optional binary KB, MB or
+// GB suffix.
+func parseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(number), unit.bytes
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * multiplier, nil
+}
+
+// limitsKey is the request context key of the limits applied to a request.
+type limitsKey struct{}
+
+// Limit wraps next so that request bodies larger than MaxUploadBytes are
+// rejected with 413 Payload Too Large, and makes the limits available to
+// the handler.
+func (l Limits) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > l.MaxUploadBytes {
+			w.Header().Set("Content-Type", "application/json")
+			writePayloadTooLarge(w, l.MaxUploadBytes)
+			return
+		}
+
+		// Bodies without a declared length fail once they exceed the limit
+		r.Body = http.MaxBytesReader(w, r.Body, l.MaxUploadBytes)
+		next(w, r.WithContext(context.WithValue(r.Context(), limitsKey{}, l)))
+	}
+}
+
+// requestLimits returns the limits applied to a request by Limit, or the
+// defaults for handlers served without it.
+func requestLimits(r *http.Request) Limits {
+	if limits, ok := r.Context().Value(limitsKey{}).(Limits); ok {
+		return limits
+	}
+	return DefaultLimits
+}
+
+// writePayloadTooLarge writes the error response for a request body over the limit.
+func writePayloadTooLarge(w http.ResponseWriter, maxBytes int64) {
+	writeErrorResponse(w, http.StatusRequestEntityTooLarge, "payload_too_large", fmt.Sprintf("Request body exceeds the maximum upload size of %d bytes", maxBytes))
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitsFromEnv(t *testing.T) {
+	t.Setenv("MAX_UPLOAD_SIZE", "")
+	t.Setenv("MAX_SBOM_COMPONENTS", "")
+	limits, err := LimitsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLimits, limits)
+
+	t.Setenv("MAX_UPLOAD_SIZE", "64MB")
+	t.Setenv("MAX_SBOM_COMPONENTS", "1000")
+	limits, err = LimitsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Limits{MaxUploadBytes: 64 << 20, MaxComponents: 1000}, limits)
+
+	t.Setenv("MAX_UPLOAD_SIZE", "lots")
+	t.Setenv("MAX_SBOM_COMPONENTS", "0")
+	limits, err = LimitsFromEnv()
+	assert.ErrorContains(t, err, `invalid MAX_UPLOAD_SIZE "lots"`)
+	assert.ErrorContains(t, err, `invalid MAX_SBOM_COMPONENTS "0"`)
+	assert.Equal(t, DefaultLimits, limits)
+}
+
+func TestParseByteSize(t *testing.T) {
+	for value, expected := range map[string]int64{"1024": 1024, "512KB": 512 << 10, "64 mb": 64 << 20, "1GB": 1 << 30, "10B": 10} {
+		size, err := parseByteSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	_, err := parseByteSize("1.5MB")
+	assert.Error(t, err)
+}
+
+func TestLimits_Limit(t *testing.T) {
+	sbom := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"components": [
+			{"type": "library", "name": "a", "version": "1.0.0"},
+			{"type": "library", "name": "b", "version": "1.0.0"}
+		]
+	}`
+
+	serve := func(limits Limits, req *http.Request) (*httptest.ResponseRecorder, ErrorResponse) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		rr := httptest.NewRecorder()
+		limits.Limit(SubmitSBOMHandler(mockRepo)).ServeHTTP(rr, req)
+
+		var response ErrorResponse
+		if rr.Code >= 400 {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr, response
+	}
+
+	req, err := multipartSBOMRequest(sbom)
+	require.NoError(t, err)
+	rr, _ := serve(DefaultLimits, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	// Declared lengths over the limit are rejected before the body is read
+	req, err = multipartSBOMRequest(sbom)
+	require.NoError(t, err)
+	rr, response := serve(Limits{MaxUploadBytes: 100, MaxComponents: 10}, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Equal(t, "payload_too_large", response.Error)
+
+	// Bodies without a declared length are cut off at the limit
+	req, err = multipartSBOMRequest(sbom + strings.Repeat(" ", 1000))
+	require.NoError(t, err)
+	req.ContentLength = -1
+	rr, response = serve(Limits{MaxUploadBytes: 600, MaxComponents: 10}, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Equal(t, "payload_too_large", response.Error)
+
+	req, err = multipartSBOMRequest(sbom)
+	require.NoError(t, err)
+	rr, response = serve(Limits{MaxUploadBytes: 1 << 20, MaxComponents: 1}, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, "too_many_components", response.Error)
+	assert.Equal(t, "SBOM has 2 components, more than the maximum of 1", response.Message)
+}
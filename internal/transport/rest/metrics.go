@@ -0,0 +1,117 @@
+// Package rest provides the Prometheus metrics endpoint exposing the runs,
+// wall time and LLM usage of the analysis agents and the LLM request queue.
+package rest
+
+import (
+	"net/http"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/llmlimit"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// agentMetrics holds the Prometheus metrics of the analysis agents and the
+// LLM request queue, registered on their own registry.
+type agentMetrics struct {
+	registry    *prometheus.Registry
+	runs        *prometheus.CounterVec
+	duration    *prometheus.CounterVec
+	runDuration *prometheus.HistogramVec
+	llmRequests *prometheus.CounterVec
+	llmTokens   *prometheus.CounterVec
+	llmDuration *prometheus.CounterVec
+}
+
+// serverMetrics counts the agent runs of the server process.
+var serverMetrics = newAgentMetrics(func() llmlimit.Stats { return llmlimit.Shared().Stats() })
+
+func init() {
+	analysis.ObserveRuns(serverMetrics.observe)
+}
+
+// newAgentMetrics creates the agent metrics on a new registry, reading the
+// state of the LLM request queue from queue when scraped.
+func newAgentMetrics(queue func() llmlimit.Stats) *agentMetrics {
+	m := &agentMetrics{
+		registry: prometheus.NewRegistry(),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentinel_agent_runs_total",
+			Help: "Analysis agent runs by status.",
+		}, []string{"agent", "status"}),
+		duration: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentinel_agent_duration_seconds_total",
+			Help: "Wall time spent in analysis agent runs.",
+		}, []string{"agent"}),
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "sentinel_agent_run_duration_seconds",
+			Help:    "Wall time of analysis agent runs.",
+			Buckets: []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300},
+		}, []string{"agent"}),
+		llmRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentinel_llm_requests_total",
+			Help: "LLM generate requests made by analysis agents.",
+		}, []string{"agent"}),
+		llmTokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentinel_llm_tokens_total",
+			Help: "LLM tokens used by analysis agents, by prompt or completion.",
+		}, []string{"agent", "type"}),
+		llmDuration: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentinel_llm_duration_seconds_total",
+			Help: "Time the LLM spent answering the requests of analysis agents.",
+		}, []string{"agent"}),
+	}
+	m.registry.MustRegister(m.runs, m.duration, m.runDuration, m.llmRequests, m.llmTokens, m.llmDuration)
+
+	// A limit of zero means LLM requests are not limited
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "sentinel_llm_max_concurrency",
+			Help: "Maximum LLM requests in flight at once (LLM_MAX_CONCURRENCY).",
+		}, func() float64 { return float64(queue().MaxConcurrency) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "sentinel_llm_requests_in_flight",
+			Help: "LLM requests being answered.",
+		}, func() float64 { return float64(queue().Active) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "sentinel_llm_requests_waiting",
+			Help: "LLM requests queued for a free slot.",
+		}, func() float64 { return float64(queue().Waiting) }),
+	)
+	return m
+}
+
+// observe counts a finished agent run.
+func (m *agentMetrics) observe(run analysis.AgentRun) {
+	m.runs.WithLabelValues(run.Agent, run.Status).Inc()
+	m.duration.WithLabelValues(run.Agent).Add(run.Duration.Seconds())
+	m.runDuration.WithLabelValues(run.Agent).Observe(run.Duration.Seconds())
+	if run.LLMUsage == nil {
+		return
+	}
+	m.llmRequests.WithLabelValues(run.Agent).Add(float64(run.LLMUsage.Requests))
+	m.llmTokens.WithLabelValues(run.Agent, "prompt").Add(float64(run.LLMUsage.PromptTokens))
+	m.llmTokens.WithLabelValues(run.Agent, "completion").Add(float64(run.LLMUsage.CompletionTokens))
+	m.llmDuration.WithLabelValues(run.Agent).Add(run.LLMUsage.Duration.Seconds())
+}
+
+// handler serves the metrics in the Prometheus exposition format.
+func (m *agentMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// MetricsHandler creates an HTTP handler serving the agent metrics of the
+// server process in the Prometheus exposition format. It expects a GET
+// request to /metrics.
+func MetricsHandler() http.HandlerFunc {
+	metrics := serverMetrics.handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+		metrics.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/llmlimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rr.Body.String(), "# TYPE sentinel_llm_requests_waiting gauge")
+
+	rr = httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rr, httptest.NewRequest("POST", "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestAgentMetrics(t *testing.T) {
+	metrics := newAgentMetrics(func() llmlimit.Stats { return llmlimit.Stats{MaxConcurrency: 4, Active: 4, Waiting: 7} })
+	usage := &analysis.LLMUsage{Requests: 6, PromptTokens: 600, CompletionTokens: 150, Duration: 2 * time.Second}
+	metrics.observe(analysis.AgentRun{Agent: "Dependency Health Agent", Status: "ok", Duration: 1500 * time.Millisecond, LLMUsage: usage})
+	metrics.observe(analysis.AgentRun{Agent: "Dependency Health Agent", Status: "ok", Duration: 2 * time.Second, LLMUsage: usage})
+	metrics.observe(analysis.AgentRun{Agent: "Dependency Health Agent", Status: "timeout", Duration: time.Second})
+	metrics.observe(analysis.AgentRun{Agent: `Odd "Agent"`, Status: "failed"})
+
+	rr := httptest.NewRecorder()
+	metrics.handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	for _, line := range []string{
+		`sentinel_agent_runs_total{agent="Dependency Health Agent",status="ok"} 2`,
+		`sentinel_agent_runs_total{agent="Dependency Health Agent",status="timeout"} 1`,
+		`sentinel_agent_duration_seconds_total{agent="Dependency Health Agent"} 4.5`,
+		`sentinel_agent_run_duration_seconds_bucket{agent="Dependency Health Agent",le="1"} 1`,
+		`sentinel_agent_run_duration_seconds_count{agent="Dependency Health Agent"} 3`,
+		`sentinel_llm_requests_total{agent="Dependency Health Agent"} 12`,
+		`sentinel_llm_tokens_total{agent="Dependency Health Agent",type="prompt"} 1200`,
+		`sentinel_llm_tokens_total{agent="Dependency Health Agent",type="completion"} 300`,
+		`sentinel_llm_duration_seconds_total{agent="Dependency Health Agent"} 4`,
+		`sentinel_agent_runs_total{agent="Odd \"Agent\"",status="failed"} 1`,
+		"# TYPE sentinel_agent_run_duration_seconds histogram",
+		"# TYPE sentinel_llm_requests_waiting gauge",
+		`sentinel_llm_max_concurrency 4`,
+		`sentinel_llm_requests_in_flight 4`,
+		`sentinel_llm_requests_waiting 7`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+}
@@ -0,0 +1,23 @@
+// Package rest provides the HTTP handler serving the OpenAPI description.
+package rest
+
+import (
+	"net/http"
+)
+
+// OpenAPIHandler creates an HTTP handler serving the OpenAPI description
+// of the API, so that clients can be generated from a running server.
+// It expects a GET request to /api/openapi.yaml.
+func OpenAPIHandler(spec []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Content-Type", "application/json")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(spec)
+	}
+}
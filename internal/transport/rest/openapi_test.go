@@ -0,0 +1,24 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	handler := OpenAPIHandler(api.OpenAPI)
+
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("GET", "/api/openapi.yaml", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/yaml", rr.Header().Get("Content-Type"))
+	assert.Equal(t, api.OpenAPI, rr.Body.Bytes())
+
+	rr = httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("POST", "/api/openapi.yaml", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
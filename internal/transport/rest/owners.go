@@ -0,0 +1,71 @@
+// Package rest provides the HTTP handler listing the teams owning
+// components, and the assignment of findings to them.
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/ownership"
+)
+
+// OwnersResponse represents the JSON response listing the owners section of
+// the configuration file.
+type OwnersResponse struct {
+	Teams []TeamOwnership `json:"teams"`
+}
+
+// TeamOwnership describes the components a team owns and where its
+// findings are sent.
+type TeamOwnership struct {
+	Name  string   `json:"name"`
+	Match []string `json:"match"`
+	// Channels are the notification channels routed to the team
+	Channels []string `json:"channels,omitempty"`
+}
+
+// OwnersHandler creates an HTTP handler for GET /api/v1/owners, which lists
+// the teams of the configuration file in name order, with the Package URL
+// prefixes and component names they own. Owners are changed in the
+// configuration file, for example with `sentinel-cli apply`.
+func OwnersHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		cfg := config.Default()
+		response := OwnersResponse{Teams: make([]TeamOwnership, 0, len(cfg.Owners))}
+		for _, name := range cfg.Owners.Teams() {
+			team := TeamOwnership{Name: name, Match: cfg.Owners[name].Match}
+			for _, channel := range cfg.Notifications {
+				for _, owner := range channel.Owners {
+					if owner == name {
+						team.Channels = append(team.Channels, channel.Name)
+					}
+				}
+			}
+			response.Teams = append(response.Teams, team)
+		}
+		writeJSONResponse(w, http.StatusOK, response)
+	}
+}
+
+// ownedNotifier assigns findings to the teams owning their components
+// before sending them, so that channels can route them by team.
+type ownedNotifier struct {
+	notify.Notifier
+	owners ownership.Map
+}
+
+// Notify implements the notify.Notifier interface.
+func (n ownedNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	notification.Results = n.owners.Assign(notification.Results)
+	return n.Notifier.Notify(ctx, notification)
+}
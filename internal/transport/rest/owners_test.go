@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// withOwners loads a configuration in which the payments team owns the
+// com.acme.payments Maven namespace and has a channel of its own.
+func withOwners(t *testing.T) {
+	configtest.Set(t, `owners:
+  payments:
+    match: [pkg:maven/com.acme.payments/, component:agpl-*]
+  web:
+    match: [pkg:npm/@acme/]
+notifications:
+  - name: payments-alerts
+    type: webhook
+    url: https://example.com/payments
+    owners: [payments]
+`)
+}
+
+func TestOwnersHandler(t *testing.T) {
+	withOwners(t)
+
+	rr := httptest.NewRecorder()
+	OwnersHandler()(rr, httptest.NewRequest("GET", "/api/v1/owners", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response OwnersResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, []TeamOwnership{
+		{Name: "payments", Match: []string{"pkg:maven/com.acme.payments/", "component:agpl-*"}, Channels: []string{"payments-alerts"}},
+		{Name: "web", Match: []string{"pkg:npm/@acme/"}},
+	}, response.Teams)
+
+	rr = httptest.NewRecorder()
+	OwnersHandler()(rr, httptest.NewRequest("POST", "/api/v1/owners", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestListComponentsHandler_Owners(t *testing.T) {
+	withOwners(t)
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(context.Background(), core.SBOM{ID: "sbom-1", Name: "api", Components: []core.Component{
+		{Name: "ledger", Version: "1.2.0", PURL: "pkg:maven/com.acme.payments/ledger@1.2.0"},
+		{Name: "react", Version: "18.2.0", PURL: "pkg:npm/react@18.2.0"},
+	}}))
+
+	list := func(query string) []core.InventoryItem {
+		rr := httptest.NewRecorder()
+		ListComponentsHandler(repo)(rr, httptest.NewRequest("GET", "/api/v1/components?"+query, nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response ComponentInventoryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.Components
+	}
+
+	components := list("")
+	require.Len(t, components, 2)
+	assert.Equal(t, "payments", components[0].Owner)
+	assert.Empty(t, components[1].Owner)
+
+	owned := list("owner=payments")
+	require.Len(t, owned, 1)
+	assert.Equal(t, "ledger", owned[0].Name)
+	assert.Empty(t, list("owner=web"))
+}
+
+func TestAnalyzeSBOMHandler_Owners(t *testing.T) {
+	withOwners(t)
+	notifications := make(fakeNotifier, 1)
+	original := findingNotifier
+	findingNotifier = func() notify.Notifier { return notifications }
+	t.Cleanup(func() { findingNotifier = original })
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindByID", mock.Anything, "test-sbom-789").Return(&core.SBOM{
+		ID:         "test-sbom-789",
+		Name:       "Test SBOM",
+		Components: []core.Component{{Name: "agpl-component", Version: "1.0.0", License: "AGPL-3.0-only"}},
+	}, nil)
+
+	rr := httptest.NewRecorder()
+	AnalyzeSBOMHandler(mockRepo, nil).ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/sboms/test-sbom-789/analyze", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response AnalysisResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, "payments", response.Results[0].Owner)
+
+	select {
+	case notification := <-notifications:
+		require.Len(t, notification.Results, 1)
+		assert.Equal(t, "payments", notification.Results[0].Owner)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification sent")
+	}
+}
+
+func TestWatchlistNotifier_Owners(t *testing.T) {
+	withOwners(t)
+	notifications := make(fakeNotifier, 1)
+	original := findingNotifier
+	findingNotifier = func() notify.Notifier { return notifications }
+	t.Cleanup(func() { findingNotifier = original })
+
+	results := []core.AnalysisResult{{AgentName: "Watchlist", Component: &core.ComponentRef{Name: "ui", PURL: "pkg:npm/@acme/ui@1.0.0"}}}
+	require.NoError(t, WatchlistNotifier().Notify(context.Background(), notify.Notification{SBOMID: "sbom-1", Results: results}))
+
+	notification := <-notifications
+	assert.Equal(t, "web", notification.Results[0].Owner)
+	assert.Empty(t, results[0].Owner, "the alerter's findings are not modified")
+
+	findingNotifier = func() notify.Notifier { return nil }
+	assert.Nil(t, WatchlistNotifier())
+}
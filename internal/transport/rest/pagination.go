@@ -0,0 +1,138 @@
+// Package rest provides pagination and field selection of the components of
+// retrieved SBOMs.
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+)
+
+// SBOMPage is a stored SBOM with a page of its components, optionally
+// reduced to selected fields.
+type SBOMPage struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Services []core.Service    `json:"services,omitempty"`
+	Metadata map[string]string `json:"metadata"`
+	// Components holds core.Component values, or objects with only the
+	// selected fields
+	Components []interface{} `json:"components"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// Pagination describes the page of components in an SBOMPage.
+type Pagination struct {
+	Offset int `json:"offset"`
+	// Limit is the page size requested, 0 if all remaining components were
+	Limit int `json:"limit,omitempty"`
+	// Total is the number of components in the SBOM
+	Total int `json:"total"`
+}
+
+// componentFields lists the JSON field names of core.Component.
+var componentFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(core.Component{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// wantsComponentPage reports whether a request selects a page or fields of
+// the components instead of the whole SBOM.
+func wantsComponentPage(query url.Values) bool {
+	return query.Has("fields") || query.Has("offset") || query.Has("limit")
+}
+
+// pageComponents returns the page of sbom's components selected by the
+// ?offset=, ?limit= and ?fields= parameters of query.
+func pageComponents(sbom core.SBOM, query url.Values) (*SBOMPage, error) {
+	offset := 0
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = parsed
+	}
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("limit must be a positive integer")
+		}
+		limit = parsed
+	}
+
+	var fields []string
+	if value := query.Get("fields"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if !componentFields[field] {
+				return nil, fmt.Errorf("unknown component field %q", field)
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	total := len(sbom.Components)
+	start := min(offset, total)
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+
+	components := make([]interface{}, 0, end-start)
+	for _, component := range sbom.Components[start:end] {
+		if len(fields) == 0 {
+			components = append(components, component)
+			continue
+		}
+		selected, err := selectFields(component, fields)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, selected)
+	}
+
+	return &SBOMPage{
+		ID:         sbom.ID,
+		Name:       sbom.Name,
+		Services:   sbom.Services,
+		Metadata:   sbom.Metadata,
+		Components: components,
+		Pagination: Pagination{Offset: offset, Limit: limit, Total: total},
+	}, nil
+}
+
+// selectFields returns the given JSON fields of a component. Empty optional
+// fields are left out, as in the full component.
+func selectFields(component core.Component, fields []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(component)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode component: %w", err)
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("failed to encode component: %w", err)
+	}
+
+	selected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSBOMHandler_ComponentPage(t *testing.T) {
+	sbom := &core.SBOM{
+		ID:   "test-sbom-123",
+		Name: "Test SBOM",
+		Components: []core.Component{
+			{Name: "a", Version: "1.0.0", PURL: "pkg:npm/a@1.0.0", License: "MIT"},
+			{Name: "b", Version: "2.0.0", PURL: "pkg:npm/b@2.0.0", License: "MIT", Supplier: "Acme"},
+			{Name: "c", Version: "3.0.0", PURL: "pkg:npm/c@3.0.0", License: "Apache-2.0"},
+		},
+		Metadata: map[string]string{"bomFormat": "CycloneDX"},
+	}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		mockRepo := new(MockRepository)
+		mockRepo.On("FindByID", mock.Anything, "test-sbom-123").Return(sbom, nil)
+
+		req := httptest.NewRequest("GET", target, nil)
+		req.SetPathValue("id", "test-sbom-123")
+		rr := httptest.NewRecorder()
+		GetSBOMHandler(mockRepo).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/api/v1/sboms/test-sbom-123?offset=1&limit=1&fields=name,supplier")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var page map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Equal(t, "test-sbom-123", page["id"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "b", "supplier": "Acme"}}, page["components"])
+	assert.Equal(t, map[string]interface{}{"offset": 1.0, "limit": 1.0, "total": 3.0}, page["pagination"])
+
+	// Without a limit the page runs to the end, past which it is empty
+	var rest SBOMPage
+	require.NoError(t, json.Unmarshal(get("/api/v1/sboms/test-sbom-123?offset=2").Body.Bytes(), &rest))
+	assert.Len(t, rest.Components, 1)
+	require.NoError(t, json.Unmarshal(get("/api/v1/sboms/test-sbom-123?offset=10").Body.Bytes(), &rest))
+	assert.Empty(t, rest.Components)
+	assert.Equal(t, 3, rest.Pagination.Total)
+
+	// Without paging parameters the whole SBOM is returned
+	var whole core.SBOM
+	require.NoError(t, json.Unmarshal(get("/api/v1/sboms/test-sbom-123").Body.Bytes(), &whole))
+	assert.Equal(t, sbom.Components, whole.Components)
+
+	for _, query := range []string{"limit=0", "offset=-1", "fields=name,color"} {
+		rr := get("/api/v1/sboms/test-sbom-123?" + query)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+
+		var response ErrorResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "invalid_parameter", response.Error)
+	}
+}
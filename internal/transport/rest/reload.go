@@ -0,0 +1,100 @@
+// Package rest provides the endpoint reloading the server configuration
+// without a restart.
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/plugin"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/hueyexe/SBOM-Sentinel/internal/rules"
+)
+
+// reloadConfig, reloadPolicy and reloadRules re-read the configuration
+// file, the gate policies and the rules file; tests replace them.
+var (
+	reloadConfig = config.Reload
+	reloadPolicy = policy.ReloadEvaluator
+	reloadRules  = rules.ReloadAgent
+)
+
+// ReloadResponse represents the JSON response of a configuration reload,
+// summarizing the configuration now in effect.
+type ReloadResponse struct {
+	Message              string   `json:"message"`
+	Profiles             []string `json:"profiles"`
+	Projects             int      `json:"projects"`
+	NotificationChannels int      `json:"notification_channels"`
+	Rules                int      `json:"rules"`
+	APIKeys              int      `json:"api_keys"`
+	ExpressionRules      int      `json:"expression_rules"`
+	PolicyGate           bool     `json:"policy_gate"`
+}
+
+// Reload re-reads the configuration file (profiles, projects, agent rules,
+// notification channels, policies and API keys), the gate policies in
+// POLICY_PATH and the rules file in SENTINEL_RULES_FILE. Requests use the
+// new configuration from then on, while analyses in progress finish with
+// the agents they started with. Whatever fails to load keeps its
+// previous configuration, and the errors are returned with the summary of
+// the configuration in effect.
+func Reload() (ReloadResponse, error) {
+	cfg, configErr := reloadConfig()
+	if configErr != nil {
+		configErr = fmt.Errorf("configuration file not reloaded: %w", configErr)
+	}
+	// Rules that cannot be run are skipped by every analysis
+	_, rulesErr := plugin.WasmAgents(cfg.Rules)
+	if rulesErr != nil {
+		rulesErr = fmt.Errorf("rules skipped: %w", rulesErr)
+	}
+	gate, policyErr := reloadPolicy()
+	if policyErr != nil {
+		policyErr = fmt.Errorf("policies not reloaded: %w", policyErr)
+	}
+	engine, engineErr := reloadRules()
+	if engineErr != nil {
+		engineErr = fmt.Errorf("rules file not reloaded: %w", engineErr)
+	}
+
+	response := ReloadResponse{
+		Message:              "Configuration reloaded",
+		Profiles:             cfg.ProfileNames(),
+		Projects:             len(cfg.Projects),
+		NotificationChannels: len(cfg.Notifications),
+		Rules:                len(cfg.Rules),
+		APIKeys:              len(cfg.APIKeys),
+		PolicyGate:           gate != nil,
+	}
+	if engine != nil {
+		response.ExpressionRules = len(engine.Rules())
+	}
+	return response, errors.Join(configErr, rulesErr, policyErr, engineErr)
+}
+
+// ReloadHandler creates an HTTP handler that reloads the configuration.
+// It expects a POST request to /api/v1/admin/reload and responds with the
+// configuration now in effect, or 500 Internal Server Error if any part of
+// it could not be reloaded.
+func ReloadHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Only allow POST requests
+		if r.Method != http.MethodPost {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only POST method is allowed")
+			return
+		}
+
+		response, err := Reload()
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "reload_failed", fmt.Sprintf("Configuration reload incomplete: %v", err))
+			return
+		}
+
+		writeJSONResponse(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/policy"
+	"github.com/hueyexe/SBOM-Sentinel/internal/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubReload replaces the configuration, policy and rules file reloads for a test.
+func stubReload(t *testing.T, cfg *config.Config, configErr error, gate policy.Evaluator, policyErr error) {
+	t.Helper()
+	originalConfig, originalPolicy, originalRules := reloadConfig, reloadPolicy, reloadRules
+	reloadConfig = func() (*config.Config, error) { return cfg, configErr }
+	reloadPolicy = func() (policy.Evaluator, error) { return gate, policyErr }
+	reloadRules = func() (*rules.Agent, error) { return rules.NewAgent(make([]rules.Rule, 3)), nil }
+	t.Cleanup(func() { reloadConfig, reloadPolicy, reloadRules = originalConfig, originalPolicy, originalRules })
+}
+
+func TestReloadHandler(t *testing.T) {
+	cfg := config.New()
+	cfg.Profiles["nightly"] = config.Profile{VulnScan: true}
+	cfg.Notifications = []notify.Channel{{Type: notify.TypeSlack, URL: "https://hooks.slack.com/services/T/B/X"}}
+	stubReload(t, cfg, nil, fakeGate{}, nil)
+
+	rr := httptest.NewRecorder()
+	ReloadHandler().ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/admin/reload", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response ReloadResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, []string{"compliance-only", "full", "nightly", "quick"}, response.Profiles)
+	assert.Equal(t, 1, response.NotificationChannels)
+	assert.True(t, response.PolicyGate)
+	assert.Equal(t, 3, response.ExpressionRules)
+}
+
+func TestReloadHandler_Failed(t *testing.T) {
+	stubReload(t, config.New(), errors.New("failed to parse config file 'sentinel.yaml'"), nil, nil)
+
+	rr := httptest.NewRecorder()
+	ReloadHandler().ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/admin/reload", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	var response ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "reload_failed", response.Error)
+	assert.Contains(t, response.Message, "configuration file not reloaded: failed to parse config file")
+	assert.Contains(t, response.Message, "Configuration reload incomplete")
+}
+
+func TestReloadHandler_MethodNotAllowed(t *testing.T) {
+	rr := httptest.NewRecorder()
+	ReloadHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/admin/reload", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestNotifierCache(t *testing.T) {
+	var cache notifierCache
+
+	assert.Nil(t, cache.forConfig(config.New()))
+
+	cfg := config.New()
+	cfg.Notifications = []notify.Channel{{Type: notify.TypeSlack, URL: "https://hooks.slack.com/services/T/B/X"}}
+	notifier := cache.forConfig(cfg)
+	require.NotNil(t, notifier)
+	assert.Same(t, notifier, cache.forConfig(cfg), "the dispatcher is reused until the configuration changes")
+
+	reloaded := config.New()
+	reloaded.Notifications = cfg.Notifications
+	assert.NotSame(t, notifier, cache.forConfig(reloaded))
+}
@@ -0,0 +1,95 @@
+// Package rest provides the HTTP handler listing stored SBOMs.
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// SBOMListResponse represents the JSON response listing stored SBOMs.
+type SBOMListResponse struct {
+	TotalSBOMs int                  `json:"total_sboms"`
+	SBOMs      []storage.SBOMRecord `json:"sboms"`
+}
+
+// ListSBOMsHandler wraps the submission endpoint so that GET /api/v1/sboms
+// lists the stored SBOMs, oldest first, without their contents; ?name=
+// lists the versions of one project and ?tag=key=value, which may be
+// repeated, the SBOMs with every given tag, their own or their project's.
+// Other requests are passed to next.
+func ListSBOMsHandler(records storage.Pruner, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		filter, err := tagFilter(r.URL.Query())
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+
+		list, err := records.ListRecords(r.Context())
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to list SBOMs: %v", err))
+			return
+		}
+		name := r.URL.Query().Get("name")
+		if name != "" || len(filter) > 0 {
+			projects := config.Default().Projects
+			matching := make([]storage.SBOMRecord, 0)
+			for _, record := range list {
+				if name != "" && record.Name != name {
+					continue
+				}
+				tags := core.SBOM{Name: record.Name, Tags: record.Tags}.EffectiveTags(projects)
+				if !core.MatchesTags(tags, filter) {
+					continue
+				}
+				matching = append(matching, record)
+			}
+			list = matching
+		}
+
+		writeJSONResponse(w, http.StatusOK, SBOMListResponse{TotalSBOMs: len(list), SBOMs: list})
+	}
+}
+
+// DeleteSBOMHandler wraps the SBOM endpoint so that DELETE
+// /api/v1/sboms/{id} removes the SBOM, responding 204 No Content, or 404 Not
+// Found if there is none. Other requests are passed to next.
+func DeleteSBOMHandler(repo storage.Repository, records storage.Pruner, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			next(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		ctx := r.Context()
+		id := r.PathValue("id")
+		sbom, err := repo.FindByID(ctx, id)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to retrieve SBOM: %v", err))
+			return
+		}
+		if sbom == nil {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "SBOM not found")
+			return
+		}
+
+		if err := records.Delete(ctx, id); err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to delete SBOM: %v", err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
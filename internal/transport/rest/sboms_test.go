@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSBOMsHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-2", Name: "web"}))
+	handler := ListSBOMsHandler(repo, SubmitSBOMHandler(repo))
+
+	list := func(path string) SBOMListResponse {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest("GET", path, nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response SBOMListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response
+	}
+
+	response := list("/api/v1/sboms")
+	assert.Equal(t, 2, response.TotalSBOMs)
+	response = list("/api/v1/sboms?name=web")
+	require.Len(t, response.SBOMs, 1)
+	assert.Equal(t, "sbom-2", response.SBOMs[0].ID)
+	assert.Empty(t, list("/api/v1/sboms?name=cli").SBOMs)
+
+	// Other methods are passed to the submission endpoint
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("PUT", "/api/v1/sboms", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestDeleteSBOMHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sboms/{id}", DeleteSBOMHandler(repo, repo, GetSBOMHandler(repo)))
+	serve := func(method string) int {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, "/api/v1/sboms/sbom-1", nil))
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("GET"))
+	assert.Equal(t, http.StatusNoContent, serve("DELETE"))
+	sbom, err := repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Nil(t, sbom)
+	assert.Equal(t, http.StatusNotFound, serve("DELETE"))
+	assert.Equal(t, http.StatusNotFound, serve("GET"))
+}
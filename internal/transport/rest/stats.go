@@ -0,0 +1,185 @@
+// Package rest provides the HTTP handler for the summary statistics shown on
+// dashboard landing pages.
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// maxRiskiestComponents is the number of components ranked by risk.
+const maxRiskiestComponents = 10
+
+// StatsResponse represents the JSON response with the summary statistics.
+type StatsResponse struct {
+	SBOMs    int `json:"sboms"`
+	Projects int `json:"projects"`
+	// Components counts the unique components across all SBOMs
+	Components     int `json:"components"`
+	UniqueLicenses int `json:"unique_licenses"`
+	// OpenFindings are the findings still open in each project's finding
+	// history, as of its latest recorded analysis; findings triaged as false
+	// positives are not counted
+	OpenFindings           int            `json:"open_findings"`
+	OpenFindingsBySeverity map[string]int `json:"open_findings_by_severity"`
+	// OverdueFindings are the open findings past the due date set by the
+	// SLA of their severity in the configuration file
+	OverdueFindings           int            `json:"overdue_findings"`
+	OverdueFindingsBySeverity map[string]int `json:"overdue_findings_by_severity"`
+	// RiskiestComponents ranks the components with open findings by the
+	// risk score of those findings
+	RiskiestComponents []ComponentRisk `json:"riskiest_components"`
+	GeneratedAt        time.Time       `json:"generated_at"`
+}
+
+// ComponentRisk describes the open findings of a component.
+type ComponentRisk struct {
+	// Component is the component's name and version
+	Component          string             `json:"component"`
+	Risk               analysis.RiskScore `json:"risk"`
+	OpenFindings       int                `json:"open_findings"`
+	FindingsBySeverity map[string]int     `json:"findings_by_severity"`
+	// Projects lists the projects in which the component has open findings
+	Projects []string `json:"projects"`
+}
+
+// StatsHandler creates an HTTP handler returning the summary statistics of
+// a dashboard landing page in one call. It expects a GET request to
+// /api/v1/stats. Findings come from the finding history recorded by analyses,
+// so they are zero if history is nil or no SBOM has been analyzed.
+func StatsHandler(repo storage.Repository, history storage.FindingHistory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		stats, err := buildStats(r.Context(), repo, history)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to compute statistics: %v", err))
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, stats)
+	}
+}
+
+// buildStats computes the summary statistics of the stored SBOMs and their
+// open findings.
+func buildStats(ctx context.Context, repo storage.Repository, history storage.FindingHistory) (*StatsResponse, error) {
+	sboms, err := repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &StatsResponse{
+		SBOMs:                     len(sboms),
+		OpenFindingsBySeverity:    make(map[string]int),
+		OverdueFindingsBySeverity: make(map[string]int),
+		RiskiestComponents:        make([]ComponentRisk, 0),
+		GeneratedAt:               core.Now().UTC(),
+	}
+
+	inventory := core.BuildInventory(sboms)
+	stats.Components = len(inventory)
+	licenses := make(map[string]bool)
+	for _, item := range inventory {
+		for _, license := range item.Licenses {
+			licenses[license] = true
+		}
+	}
+	stats.UniqueLicenses = len(licenses)
+
+	var projects []string
+	seen := make(map[string]bool)
+	for _, sbom := range sboms {
+		if !seen[sbom.Name] {
+			seen[sbom.Name] = true
+			projects = append(projects, sbom.Name)
+		}
+	}
+	stats.Projects = len(projects)
+
+	if history == nil {
+		return stats, nil
+	}
+
+	// Open findings by component, with results to score their risk
+	type componentFindings struct {
+		results  []core.AnalysisResult
+		projects []string
+	}
+	byComponent := make(map[string]*componentFindings)
+	policy := config.Default().SLA
+	for _, project := range projects {
+		findings, err := history.ProjectFindings(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		policy.Annotate(findings, stats.GeneratedAt)
+		for _, finding := range findings {
+			if finding.Status != storage.FindingOpen || finding.Triage == storage.TriageFalsePositive {
+				continue
+			}
+			stats.OpenFindings++
+			stats.OpenFindingsBySeverity[finding.Severity]++
+			if finding.Overdue {
+				stats.OverdueFindings++
+				stats.OverdueFindingsBySeverity[finding.Severity]++
+			}
+
+			if finding.Component == "" {
+				continue
+			}
+			entry := byComponent[finding.Component]
+			if entry == nil {
+				entry = &componentFindings{}
+				byComponent[finding.Component] = entry
+			}
+			entry.results = append(entry.results, core.AnalysisResult{AgentName: finding.AgentName, Severity: finding.Severity})
+			if len(entry.projects) == 0 || entry.projects[len(entry.projects)-1] != project {
+				entry.projects = append(entry.projects, project)
+			}
+		}
+	}
+
+	for component, entry := range byComponent {
+		bySeverity := make(map[string]int)
+		for _, result := range entry.results {
+			bySeverity[result.Severity]++
+		}
+		stats.RiskiestComponents = append(stats.RiskiestComponents, ComponentRisk{
+			Component:          component,
+			Risk:               analysis.ScoreRisk(entry.results),
+			OpenFindings:       len(entry.results),
+			FindingsBySeverity: bySeverity,
+			Projects:           entry.projects,
+		})
+	}
+	sort.Slice(stats.RiskiestComponents, func(i, j int) bool {
+		a, b := stats.RiskiestComponents[i], stats.RiskiestComponents[j]
+		if a.Risk.Score != b.Risk.Score {
+			return a.Risk.Score > b.Risk.Score
+		}
+		if a.OpenFindings != b.OpenFindings {
+			return a.OpenFindings > b.OpenFindings
+		}
+		return a.Component < b.Component
+	})
+	if len(stats.RiskiestComponents) > maxRiskiestComponents {
+		stats.RiskiestComponents = stats.RiskiestComponents[:maxRiskiestComponents]
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsHandler(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.StoreBatch(context.Background(), []core.SBOM{
+		{ID: "api-1", Name: "api", Components: []core.Component{
+			{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20", License: "MIT"},
+			{Name: "log4j-core", Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", License: "Apache-2.0"},
+		}},
+		{ID: "api-2", Name: "api", Components: []core.Component{
+			{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20", License: "MIT"},
+		}},
+		{ID: "web-1", Name: "web", Components: []core.Component{
+			{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20", License: "MIT"},
+			{Name: "gpl-lib", Version: "1.0.0", PURL: "pkg:npm/gpl-lib@1.0.0", License: "GPL-3.0-only"},
+		}},
+	}))
+
+	history := &fakeFindingHistory{findings: map[string][]storage.FindingRecord{
+		"api": {
+			{Fingerprint: "a", AgentName: "Vulnerability Scanner", Severity: "Critical", Component: "log4j-core 2.14.1", Status: storage.FindingOpen},
+			{Fingerprint: "b", AgentName: "Vulnerability Scanner", Severity: "Medium", Component: "lodash 4.17.20", Status: storage.FindingOpen},
+			{Fingerprint: "c", AgentName: "Vulnerability Scanner", Severity: "High", Component: "lodash 4.17.20", Status: storage.FindingResolved},
+		},
+		"web": {
+			{Fingerprint: "b", AgentName: "Vulnerability Scanner", Severity: "Medium", Component: "lodash 4.17.20", Status: storage.FindingOpen},
+			{Fingerprint: "d", AgentName: "SBOM Quality Agent", Severity: "Low", Status: storage.FindingOpen},
+			// False positives are not counted
+			{Fingerprint: "e", AgentName: "Vulnerability Scanner", Severity: "Critical", Component: "react 18.2.0", Status: storage.FindingOpen, Triage: storage.TriageFalsePositive},
+		},
+	}}
+
+	rr := httptest.NewRecorder()
+	StatsHandler(repo, history).ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/stats", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var stats StatsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	assert.Equal(t, 3, stats.SBOMs)
+	assert.Equal(t, 2, stats.Projects)
+	assert.Equal(t, 3, stats.Components)
+	assert.Equal(t, 3, stats.UniqueLicenses)
+	assert.Equal(t, 4, stats.OpenFindings)
+	assert.Equal(t, map[string]int{"Critical": 1, "Medium": 2, "Low": 1}, stats.OpenFindingsBySeverity)
+
+	require.Len(t, stats.RiskiestComponents, 2)
+	assert.Equal(t, "log4j-core 2.14.1", stats.RiskiestComponents[0].Component)
+	lodash := stats.RiskiestComponents[1]
+	assert.Equal(t, "lodash 4.17.20", lodash.Component)
+	assert.Equal(t, 2, lodash.OpenFindings)
+	assert.Equal(t, []string{"api", "web"}, lodash.Projects)
+
+	// Without a finding history only the inventory is counted
+	rr = httptest.NewRecorder()
+	StatsHandler(repo, nil).ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/stats", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	stats = StatsResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	assert.Equal(t, 3, stats.SBOMs)
+	assert.Zero(t, stats.OpenFindings)
+	assert.Empty(t, stats.RiskiestComponents)
+
+	rr = httptest.NewRecorder()
+	StatsHandler(repo, history).ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/stats", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestStatsHandler_OverdueFindings(t *testing.T) {
+	configtest.Set(t, "sla:\n  critical: 7\n  high: 30\n")
+
+	now := core.Now()
+	history := &fakeFindingHistory{findings: map[string][]storage.FindingRecord{
+		"api": {
+			{Fingerprint: "a", Severity: "Critical", FirstSeen: now.AddDate(0, 0, -8), Status: storage.FindingOpen},
+			{Fingerprint: "b", Severity: "High", FirstSeen: now.AddDate(0, 0, -8), Status: storage.FindingOpen},
+			{Fingerprint: "c", Severity: "Low", FirstSeen: now.AddDate(-1, 0, 0), Status: storage.FindingOpen},
+			{Fingerprint: "d", Severity: "Critical", FirstSeen: now.AddDate(0, 0, -8), Status: storage.FindingOpen, Triage: storage.TriageFalsePositive},
+		},
+	}}
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(context.Background(), core.SBOM{ID: "api-1", Name: "api"}))
+
+	rr := httptest.NewRecorder()
+	StatsHandler(repo, history).ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/stats", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var stats StatsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	assert.Equal(t, 3, stats.OpenFindings)
+	assert.Equal(t, 1, stats.OverdueFindings)
+	assert.Equal(t, map[string]int{"Critical": 1}, stats.OverdueFindingsBySeverity)
+}
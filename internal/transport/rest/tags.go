@@ -0,0 +1,148 @@
+// Package rest provides the HTTP handler for the tags of stored SBOMs and
+// the filtering of SBOMs by tag.
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// tagsMu serializes tag updates, so that concurrent PATCH requests do not
+// undo each other's changes.
+var tagsMu sync.Mutex
+
+// TagsRequest represents the JSON body setting the tags of an SBOM. A null
+// value removes the tag in a PATCH request.
+type TagsRequest struct {
+	Tags map[string]*string `json:"tags"`
+}
+
+// TagsResponse represents the JSON response describing the tags of an SBOM.
+type TagsResponse struct {
+	ID string `json:"id"`
+	// Tags are the SBOM's own tags
+	Tags map[string]string `json:"tags"`
+	// EffectiveTags adds the tags of the SBOM's project, as seen by filters
+	// and policies
+	EffectiveTags map[string]string `json:"effective_tags"`
+}
+
+// SBOMTagsHandler creates an HTTP handler for the tags of a stored SBOM:
+//
+//	GET   /api/v1/sboms/{id}/tags - the SBOM's tags and those of its project
+//	PUT   /api/v1/sboms/{id}/tags - replace the tags (JSON body with "tags")
+//	PATCH /api/v1/sboms/{id}/tags - add or change tags, removing those set to null
+//
+// Tags of the project an SBOM belongs to are declared in the configuration
+// file and cannot be changed here.
+func SBOMTagsHandler(repo storage.Repository, tagger storage.Tagger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodPatch {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET, PUT and PATCH methods are allowed")
+			return
+		}
+
+		id := r.PathValue("id")
+		if id == "" {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "SBOM ID is required in URL path")
+			return
+		}
+
+		var request TagsRequest
+		if r.Method != http.MethodGet {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_json", fmt.Sprintf("Failed to parse tags: %v", err))
+				return
+			}
+			tagsMu.Lock()
+			defer tagsMu.Unlock()
+		}
+
+		ctx := r.Context()
+		sbom, err := repo.FindByID(ctx, id)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to retrieve SBOM: %v", err))
+			return
+		}
+		if sbom == nil {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "SBOM not found")
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			tags, err := updateTags(sbom.Tags, request.Tags, r.Method == http.MethodPatch)
+			if err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_tags", err.Error())
+				return
+			}
+			found, err := tagger.SetTags(ctx, id, tags)
+			if err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to set tags: %v", err))
+				return
+			}
+			if !found {
+				writeErrorResponse(w, http.StatusNotFound, "not_found", "SBOM not found")
+				return
+			}
+			sbom.Tags = tags
+		}
+
+		writeJSONResponse(w, http.StatusOK, tagsResponse(*sbom))
+	}
+}
+
+// updateTags returns the tags replacing current: changes alone, or current
+// with changes applied if merge is set.
+func updateTags(current map[string]string, changes map[string]*string, merge bool) (map[string]string, error) {
+	tags := make(map[string]string, len(current)+len(changes))
+	if merge {
+		maps.Copy(tags, current)
+	}
+	for key, value := range changes {
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch {
+		case value != nil:
+			tags[key] = *value
+		case merge:
+			delete(tags, key)
+		default:
+			return nil, fmt.Errorf("tag %q has no value", key)
+		}
+	}
+	return core.NormalizeTags(tags)
+}
+
+// tagsResponse describes the tags of sbom.
+func tagsResponse(sbom core.SBOM) TagsResponse {
+	tags := sbom.Tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	return TagsResponse{
+		ID:            sbom.ID,
+		Tags:          tags,
+		EffectiveTags: sbom.EffectiveTags(config.Default().Projects),
+	}
+}
+
+// tagFilter returns the tags given as ?tag=key=value parameters, which an
+// SBOM must all have to be listed.
+func tagFilter(query url.Values) (map[string]string, error) {
+	filter, err := core.ParseTags(query["tag"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid tag filter: %v", err)
+	}
+	return filter, nil
+}
@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withProjectTags loads a configuration tagging the SBOMs of the api project.
+func withProjectTags(t *testing.T) {
+	configtest.Set(t, "projects:\n  api:\n    tags:\n      team: platform\n      env: prod\n")
+}
+
+func TestSubmitSBOMHandler_Tags(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+
+	req, err := multipartSBOMRequest(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}`)
+	require.NoError(t, err)
+	req.URL.RawQuery = "tag=Env=prod&tag=team=payments"
+	rr := httptest.NewRecorder()
+	SubmitSBOMHandler(repo).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	var response SubmitSBOMResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	stored, err := repo.FindByID(context.Background(), response.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "payments"}, stored.Tags)
+
+	req, err = multipartSBOMRequest(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}`)
+	require.NoError(t, err)
+	req.URL.RawQuery = "tag=prod"
+	rr = httptest.NewRecorder()
+	SubmitSBOMHandler(repo).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "expected key=value")
+}
+
+func TestListSBOMsHandler_Tags(t *testing.T) {
+	withProjectTags(t)
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api"}))
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-2", Name: "api", Tags: map[string]string{"env": "staging"}}))
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-3", Name: "web", Tags: map[string]string{"env": "prod"}}))
+	handler := ListSBOMsHandler(repo, SubmitSBOMHandler(repo))
+
+	list := func(query string) []string {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest("GET", "/api/v1/sboms?"+query, nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var response SBOMListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		ids := []string{}
+		for _, record := range response.SBOMs {
+			ids = append(ids, record.ID)
+		}
+		return ids
+	}
+
+	// Project tags apply unless the SBOM has its own tag of the same key
+	assert.Equal(t, []string{"sbom-1", "sbom-3"}, list("tag=env=prod"))
+	assert.Equal(t, []string{"sbom-1"}, list("tag=env=prod&tag=team=platform"))
+	assert.Equal(t, []string{"sbom-2"}, list("name=api&tag=env=staging"))
+	assert.Empty(t, list("tag=env=dev"))
+
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("GET", "/api/v1/sboms?tag=env", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestListComponentsHandler_Tags(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Tags: map[string]string{"env": "prod"},
+		Components: []core.Component{{Name: "lodash", Version: "4.17.21"}}}))
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-2", Name: "web",
+		Components: []core.Component{{Name: "react", Version: "18.2.0"}}}))
+
+	rr := httptest.NewRecorder()
+	ListComponentsHandler(repo)(rr, httptest.NewRequest("GET", "/api/v1/components?tag=env=prod", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response ComponentInventoryResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.TotalSBOMs)
+	require.Len(t, response.Components, 1)
+	assert.Equal(t, "lodash", response.Components[0].Name)
+}
+
+func TestSBOMTagsHandler(t *testing.T) {
+	withProjectTags(t)
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Tags: map[string]string{"env": "staging"}}))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sboms/{id}/tags", SBOMTagsHandler(repo, repo))
+	serve := func(method, id, body string) (*httptest.ResponseRecorder, TagsResponse) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, "/api/v1/sboms/"+id+"/tags", strings.NewReader(body)))
+		var response TagsResponse
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr, response
+	}
+
+	rr, response := serve("GET", "sbom-1", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, map[string]string{"env": "staging"}, response.Tags)
+	assert.Equal(t, map[string]string{"env": "staging", "team": "platform"}, response.EffectiveTags)
+
+	// PATCH adds, changes and removes tags
+	rr, response = serve("PATCH", "sbom-1", `{"tags": {"Criticality": "high", "env": null}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, map[string]string{"criticality": "high"}, response.Tags)
+	assert.Equal(t, map[string]string{"criticality": "high", "env": "prod", "team": "platform"}, response.EffectiveTags)
+
+	// PUT replaces them
+	rr, response = serve("PUT", "sbom-1", `{"tags": {"team": "payments"}}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, map[string]string{"team": "payments"}, response.Tags)
+	stored, err := repo.FindByID(ctx, "sbom-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments"}, stored.Tags)
+
+	rr, _ = serve("PUT", "sbom-1", `{"tags": {"team": null}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr, _ = serve("PATCH", "sbom-1", `{"tags": {"env": ""}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr, _ = serve("PATCH", "sbom-1", `not json`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr, _ = serve("PATCH", "missing", `{"tags": {"env": "prod"}}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr, _ = serve("DELETE", "sbom-1", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
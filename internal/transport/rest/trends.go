@@ -0,0 +1,187 @@
+// Package rest provides HTTP handlers for trend analytics across the stored
+// versions of a project.
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+)
+
+// DefaultTrendVersions is how many of a project's most recent SBOM versions
+// the trends endpoint analyzes when ?limit is not given.
+const DefaultTrendVersions = 20
+
+// RecordLister lists stored SBOMs without their contents.
+// database.SQLiteRepository implements this interface.
+type RecordLister interface {
+	ListRecords(ctx context.Context) ([]storage.SBOMRecord, error)
+}
+
+// TrendPoint summarizes one stored SBOM version of a project.
+type TrendPoint struct {
+	SBOMID      string    `json:"sbom_id"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Components  int       `json:"components"`
+	// TotalFindings and FindingsBySeverity count the findings of every agent
+	TotalFindings      int            `json:"total_findings"`
+	FindingsBySeverity map[string]int `json:"findings_by_severity"`
+	// Risk is the composite risk score of the findings
+	Risk analysis.RiskScore `json:"risk"`
+	// LicenseRisk counts the License Agent's findings by severity
+	LicenseRisk map[string]int `json:"license_risk"`
+	// AgentErrors lists the agents that failed or timed out for this version
+	AgentErrors []AgentError `json:"agent_errors,omitempty"`
+	// Error explains why the version could not be analyzed
+	Error string `json:"error,omitempty"`
+}
+
+// ProjectTrendsResponse represents the JSON response for a project's trends.
+type ProjectTrendsResponse struct {
+	Project string `json:"project"`
+	// TotalVersions is how many versions of the project are stored; Points
+	// covers the most recent of them, oldest first
+	TotalVersions int          `json:"total_versions"`
+	AgentsRun     []string     `json:"agents_run"`
+	Points        []TrendPoint `json:"points"`
+}
+
+// licenseAgentName is the agent whose findings make up the license risk.
+var licenseAgentName = analysis.NewLicenseAgent().Name()
+
+// ProjectTrendsHandler creates an HTTP handler that reports how findings,
+// component counts and license risk evolved across the stored versions of
+// a project.
+// It expects a GET request to /api/v1/projects/{id}/trends, where id is the
+// project name shared by the SBOM versions. Each version is analyzed with the
+// agents selected by the same query parameters as the analyze endpoint;
+// ?limit caps the number of most recent versions analyzed (default
+// DefaultTrendVersions).
+func ProjectTrendsHandler(repo storage.Repository, records RecordLister, intelligence *vectordb.IntelligenceStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		// Extract project name from URL path
+		// Expected format: /api/v1/projects/{id}/trends
+		project := r.PathValue("id")
+		if project == "" {
+			// Not routed through a pattern; parse the path directly
+			pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+			if len(pathParts) == 5 && pathParts[4] == "trends" {
+				project = pathParts[3]
+			}
+		}
+		if project == "" {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "Project is required in URL path")
+			return
+		}
+
+		limit := DefaultTrendVersions
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "limit must be a positive integer")
+				return
+			}
+			limit = parsed
+		}
+
+		orchestrator, err := selectAgents(r.URL.Query(), intelligence)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		all, err := records.ListRecords(ctx)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to list SBOMs: %v", err))
+			return
+		}
+
+		// Records are listed oldest first
+		var versions []storage.SBOMRecord
+		for _, record := range all {
+			if record.Name == project {
+				versions = append(versions, record)
+			}
+		}
+		if len(versions) == 0 {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "No SBOMs stored for project")
+			return
+		}
+
+		response := ProjectTrendsResponse{
+			Project:       project,
+			TotalVersions: len(versions),
+			AgentsRun:     make([]string, 0, len(orchestrator.Agents())),
+			Points:        make([]TrendPoint, 0, min(limit, len(versions))),
+		}
+		for _, agent := range orchestrator.Agents() {
+			response.AgentsRun = append(response.AgentsRun, agent.Name())
+		}
+
+		for _, version := range versions[max(0, len(versions)-limit):] {
+			// Stop early if the client went away
+			if ctx.Err() != nil {
+				return
+			}
+			response.Points = append(response.Points, trendPoint(ctx, repo, orchestrator, version))
+		}
+
+		writeJSONResponse(w, http.StatusOK, response)
+	}
+}
+
+// trendPoint analyzes one stored SBOM version. Problems are reported in the
+// point so that one bad version does not hide the rest of the series.
+func trendPoint(ctx context.Context, repo storage.Repository, orchestrator *analysis.Orchestrator, record storage.SBOMRecord) TrendPoint {
+	point := TrendPoint{
+		SBOMID:             record.ID,
+		SubmittedAt:        record.CreatedAt,
+		FindingsBySeverity: make(map[string]int),
+		LicenseRisk:        make(map[string]int),
+	}
+
+	sbom, err := repo.FindByID(ctx, record.ID)
+	if err != nil || sbom == nil {
+		point.Error = fmt.Sprintf("failed to retrieve SBOM: %v", err)
+		if err == nil {
+			point.Error = "SBOM was deleted"
+		}
+		return point
+	}
+	point.Components = len(sbom.Components)
+
+	report, err := orchestrator.Run(ctx, *sbom)
+	if err != nil {
+		point.Error = err.Error()
+		return point
+	}
+	report.Results, _ = waiveFindings(*sbom, report.Results)
+
+	point.TotalFindings = len(report.Results)
+	for _, result := range report.Results {
+		point.FindingsBySeverity[result.Severity]++
+		if result.AgentName == licenseAgentName {
+			point.LicenseRisk[result.Severity]++
+		}
+	}
+	point.Risk = analysis.ScoreRisk(report.Results)
+	point.AgentErrors = agentErrors(report)
+	return point
+}
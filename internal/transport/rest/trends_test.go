@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRecordLister serves a fixed list of stored SBOM records.
+type fakeRecordLister struct {
+	records []storage.SBOMRecord
+	err     error
+}
+
+func (f *fakeRecordLister) ListRecords(ctx context.Context) ([]storage.SBOMRecord, error) {
+	return f.records, f.err
+}
+
+func TestProjectTrendsHandler(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := &fakeRecordLister{records: []storage.SBOMRecord{
+		{ID: "web-v1", Name: "web-app", CreatedAt: base},
+		{ID: "api-v1", Name: "api", CreatedAt: base.Add(time.Hour)},
+		{ID: "web-v2", Name: "web-app", CreatedAt: base.Add(24 * time.Hour)},
+		{ID: "web-v3", Name: "web-app", CreatedAt: base.Add(48 * time.Hour)},
+	}}
+
+	versions := map[string]*core.SBOM{
+		"web-v1": {ID: "web-v1", Name: "web-app", Components: []core.Component{
+			{Name: "express", Version: "4.18.2", License: "MIT"},
+		}},
+		"web-v2": {ID: "web-v2", Name: "web-app", Components: []core.Component{
+			{Name: "express", Version: "4.18.2", License: "MIT"},
+			{Name: "readline", Version: "8.2", License: "GPL-3.0"},
+		}},
+		"web-v3": {ID: "web-v3", Name: "web-app", Components: []core.Component{
+			{Name: "express", Version: "4.19.0", License: "MIT"},
+			{Name: "readline", Version: "8.2", License: "GPL-3.0"},
+			{Name: "mysql-connector", Version: "8.0.33", License: "GPL-2.0"},
+		}},
+	}
+	storeVersions := func(mockRepo *MockRepository) {
+		for id, sbom := range versions {
+			mockRepo.On("FindByID", mock.Anything, id).Return(sbom, nil).Maybe()
+		}
+	}
+	repo := memory.NewMemoryRepository()
+	for _, record := range records.records {
+		sbom := versions[record.ID]
+		if sbom == nil {
+			sbom = &core.SBOM{ID: record.ID, Name: record.Name}
+		}
+		require.NoError(t, repo.Restore(context.Background(), *sbom, record.CreatedAt))
+	}
+
+	t.Run("Series across versions", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/projects/web-app/trends", nil)
+		rr := httptest.NewRecorder()
+		ProjectTrendsHandler(repo, repo, nil).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var response ProjectTrendsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "web-app", response.Project)
+		assert.Equal(t, 3, response.TotalVersions)
+		assert.Equal(t, []string{"License Agent"}, response.AgentsRun)
+		require.Len(t, response.Points, 3)
+
+		// Oldest first, with license risk growing as GPL components arrive
+		ids := []string{response.Points[0].SBOMID, response.Points[1].SBOMID, response.Points[2].SBOMID}
+		assert.Equal(t, []string{"web-v1", "web-v2", "web-v3"}, ids)
+		assert.Equal(t, []int{1, 2, 3}, []int{response.Points[0].Components, response.Points[1].Components, response.Points[2].Components})
+		assert.Equal(t, 0, response.Points[0].TotalFindings)
+		assert.Equal(t, 1, response.Points[1].TotalFindings)
+		assert.Equal(t, 2, response.Points[2].TotalFindings)
+		assert.Equal(t, 2, response.Points[2].FindingsBySeverity[core.SeverityHigh])
+		assert.Equal(t, 2, response.Points[2].LicenseRisk[core.SeverityHigh])
+		assert.Equal(t, base.Add(48*time.Hour), response.Points[2].SubmittedAt.UTC())
+	})
+
+	t.Run("Limit keeps the most recent versions", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/projects/web-app/trends?limit=2", nil)
+		rr := httptest.NewRecorder()
+		ProjectTrendsHandler(repo, repo, nil).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response ProjectTrendsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.TotalVersions)
+		require.Len(t, response.Points, 2)
+		assert.Equal(t, "web-v2", response.Points[0].SBOMID)
+		assert.Equal(t, "web-v3", response.Points[1].SBOMID)
+	})
+
+	t.Run("Version that cannot be loaded", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		storeVersions(mockRepo)
+		broken := &fakeRecordLister{records: append(records.records, storage.SBOMRecord{ID: "web-v4", Name: "web-app", CreatedAt: base.Add(72 * time.Hour)})}
+		mockRepo.On("FindByID", mock.Anything, "web-v4").Return(nil, errors.New("database error"))
+
+		req := httptest.NewRequest("GET", "/api/v1/projects/web-app/trends", nil)
+		rr := httptest.NewRecorder()
+		ProjectTrendsHandler(mockRepo, broken, nil).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response ProjectTrendsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Len(t, response.Points, 4)
+		assert.Contains(t, response.Points[3].Error, "database error")
+	})
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		records            *fakeRecordLister
+		expectedStatusCode int
+	}{
+		{"Unknown project", "GET", "/api/v1/projects/billing/trends", records, http.StatusNotFound},
+		{"Invalid limit", "GET", "/api/v1/projects/web-app/trends?limit=0", records, http.StatusBadRequest},
+		{"Unknown profile", "GET", "/api/v1/projects/web-app/trends?profile=bogus", records, http.StatusBadRequest},
+		{"Storage error", "GET", "/api/v1/projects/web-app/trends", &fakeRecordLister{err: errors.New("database error")}, http.StatusInternalServerError},
+		{"Malformed path", "GET", "/api/v1/projects/web-app", records, http.StatusBadRequest},
+		{"Wrong HTTP method", "POST", "/api/v1/projects/web-app/trends", records, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			ProjectTrendsHandler(new(MockRepository), tt.records, nil).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Package rest provides the HTTP handlers for the triage of tracked
+// findings: their remediation state and who is working on them.
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// TriageRequest represents the JSON body changing the triage of findings.
+// Omitted fields are left unchanged; an empty assignee unassigns.
+type TriageRequest struct {
+	// Fingerprints lists the findings to change when the request is sent to
+	// /api/v1/projects/{id}/findings rather than to a single finding
+	Fingerprints []string `json:"fingerprints,omitempty"`
+	State        *string  `json:"state,omitempty"`
+	Assignee     *string  `json:"assignee,omitempty"`
+	Note         *string  `json:"note,omitempty"`
+}
+
+// TriageResponse represents the JSON response listing the findings changed
+// by a triage request sent to /api/v1/projects/{id}/findings.
+type TriageResponse struct {
+	Findings []storage.FindingRecord `json:"findings"`
+}
+
+// ProjectFindingHandler creates an HTTP handler returning a tracked finding
+// of a project. It expects a GET request to
+// /api/v1/projects/{id}/findings/{fingerprint}.
+func ProjectFindingHandler(history storage.FindingHistory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET and PATCH methods are allowed")
+			return
+		}
+
+		project, fingerprint := r.PathValue("id"), r.PathValue("fingerprint")
+		if project == "" || fingerprint == "" {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "Project and finding fingerprint are required in URL path")
+			return
+		}
+
+		findings, err := history.ProjectFindings(r.Context(), project)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to list findings: %v", err))
+			return
+		}
+		config.Default().SLA.Annotate(findings, core.Now())
+		for _, finding := range findings {
+			if finding.Fingerprint == fingerprint {
+				writeJSONResponse(w, http.StatusOK, finding)
+				return
+			}
+		}
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Finding not found")
+	}
+}
+
+// TriageFindingsHandler wraps the finding endpoints of a project so that
+// PATCH requests change the triage state, assignee or note of findings.
+// PATCH /api/v1/projects/{id}/findings/{fingerprint} changes one finding and
+// returns it; PATCH /api/v1/projects/{id}/findings changes the findings
+// listed by fingerprints, none of them if any is unknown. Other requests are
+// passed to next.
+func TriageFindingsHandler(history storage.FindingHistory, triage storage.FindingTriage, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			next(w, r)
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		project := r.PathValue("id")
+		if project == "" {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "Project is required in URL path")
+			return
+		}
+
+		var request TriageRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_json", fmt.Sprintf("Failed to parse triage: %v", err))
+			return
+		}
+		fingerprint := r.PathValue("fingerprint")
+		fingerprints := request.Fingerprints
+		if fingerprint != "" {
+			if len(fingerprints) > 0 {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_triage", "fingerprints is only accepted by /api/v1/projects/{id}/findings")
+				return
+			}
+			fingerprints = []string{fingerprint}
+		}
+		update, err := triageUpdate(request, fingerprints)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_triage", err.Error())
+			return
+		}
+
+		// Unknown fingerprints are reported before any finding is changed
+		ctx := r.Context()
+		findings, err := history.ProjectFindings(ctx, project)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to list findings: %v", err))
+			return
+		}
+		tracked := make(map[string]bool, len(findings))
+		for _, finding := range findings {
+			tracked[finding.Fingerprint] = true
+		}
+		var unknown []string
+		for _, fingerprint := range fingerprints {
+			if !tracked[fingerprint] {
+				unknown = append(unknown, fingerprint)
+			}
+		}
+		if len(unknown) > 0 {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", fmt.Sprintf("Findings not found: %s", strings.Join(unknown, ", ")))
+			return
+		}
+
+		response := TriageResponse{Findings: make([]storage.FindingRecord, 0, len(fingerprints))}
+		for _, fingerprint := range fingerprints {
+			finding, err := triage.TriageFinding(ctx, project, fingerprint, update)
+			if err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to triage finding: %v", err))
+				return
+			}
+			if finding == nil {
+				writeErrorResponse(w, http.StatusNotFound, "not_found", fmt.Sprintf("Findings not found: %s", fingerprint))
+				return
+			}
+			response.Findings = append(response.Findings, *finding)
+		}
+		config.Default().SLA.Annotate(response.Findings, core.Now())
+
+		if r.PathValue("fingerprint") != "" {
+			writeJSONResponse(w, http.StatusOK, response.Findings[0])
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, response)
+	}
+}
+
+// triageUpdate validates a triage request for the given findings and
+// returns the update to apply to each of them.
+func triageUpdate(request TriageRequest, fingerprints []string) (storage.TriageUpdate, error) {
+	if len(fingerprints) == 0 {
+		return storage.TriageUpdate{}, errors.New("fingerprints must list the findings to triage")
+	}
+	for _, fingerprint := range fingerprints {
+		if strings.TrimSpace(fingerprint) == "" {
+			return storage.TriageUpdate{}, errors.New("fingerprints must not be empty")
+		}
+	}
+	if request.State == nil && request.Assignee == nil && request.Note == nil {
+		return storage.TriageUpdate{}, errors.New("state, assignee or note is required")
+	}
+	if request.State != nil && !slices.Contains(storage.TriageStates, *request.State) {
+		return storage.TriageUpdate{}, fmt.Errorf("state must be one of %s", strings.Join(storage.TriageStates, ", "))
+	}
+
+	update := storage.TriageUpdate{State: request.State, Note: request.Note}
+	if request.Assignee != nil {
+		assignee := strings.TrimSpace(*request.Assignee)
+		update.Assignee = &assignee
+	}
+	return update, nil
+}
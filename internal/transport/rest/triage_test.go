@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/lifecycle"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// triageMux serves the finding endpoints of projects from a memory
+// repository in which the api project has findings a and b.
+func triageMux(t *testing.T) *http.ServeMux {
+	repo := memory.NewMemoryRepository()
+	_, err := repo.RecordAnalysis(context.Background(), storage.AnalysisRun{SBOMID: "api-1", Project: "api", AnalyzedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		[]string{"Vulnerability Scanner"}, []storage.FindingRecord{
+			{Fingerprint: "a", AgentName: "Vulnerability Scanner", Severity: "High"},
+			{Fingerprint: "b", AgentName: "Vulnerability Scanner", Severity: "Low"},
+		})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/{id}/findings", TriageFindingsHandler(repo, repo, ProjectFindingsHandler(repo)))
+	mux.HandleFunc("/api/v1/projects/{id}/findings/{fingerprint}", TriageFindingsHandler(repo, repo, ProjectFindingHandler(repo)))
+	return mux
+}
+
+func serveTriage(mux *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rr
+}
+
+func TestTriageFindingsHandler(t *testing.T) {
+	mux := triageMux(t)
+
+	rr := serveTriage(mux, "PATCH", "/api/v1/projects/api/findings/a", `{"state":"in-progress","assignee":" alice ","note":"Upgrading"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var finding storage.FindingRecord
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &finding))
+	assert.Equal(t, "a", finding.Fingerprint)
+	assert.Equal(t, storage.TriageInProgress, finding.Triage)
+	assert.Equal(t, "alice", finding.Assignee)
+	assert.Equal(t, "Upgrading", finding.TriageNote)
+	assert.NotNil(t, finding.TriagedAt)
+
+	rr = serveTriage(mux, "GET", "/api/v1/projects/api/findings/a", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	finding = storage.FindingRecord{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &finding))
+	assert.Equal(t, "alice", finding.Assignee)
+
+	// Several findings at once
+	rr = serveTriage(mux, "PATCH", "/api/v1/projects/api/findings", `{"fingerprints":["a","b"],"state":"acknowledged"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response TriageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Findings, 2)
+	for _, finding := range response.Findings {
+		assert.Equal(t, storage.TriageAcknowledged, finding.Triage)
+	}
+	assert.Equal(t, "alice", response.Findings[0].Assignee, "the assignee is kept")
+
+	// The timeline lists findings by triage state and assignee
+	list := func(query string) *lifecycle.Timeline {
+		rr := serveTriage(mux, "GET", "/api/v1/projects/api/findings?"+query, "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		var timeline lifecycle.Timeline
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &timeline))
+		return &timeline
+	}
+	assert.Equal(t, map[string]int{storage.TriageAcknowledged: 2}, list("").Triage)
+	assert.Len(t, list("triage=acknowledged").Findings, 2)
+	assert.Empty(t, list("triage=new").Findings)
+	require.Len(t, list("assignee=alice").Findings, 1)
+	unassigned := list("assignee=").Findings
+	require.Len(t, unassigned, 1)
+	assert.Equal(t, "b", unassigned[0].Fingerprint)
+	assert.Equal(t, http.StatusBadRequest, serveTriage(mux, "GET", "/api/v1/projects/api/findings?triage=done", "").Code)
+}
+
+func TestTriageFindingsHandler_Errors(t *testing.T) {
+	mux := triageMux(t)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid JSON", "/api/v1/projects/api/findings/a", `{`, http.StatusBadRequest},
+		{"unknown state", "/api/v1/projects/api/findings/a", `{"state":"done"}`, http.StatusBadRequest},
+		{"nothing to change", "/api/v1/projects/api/findings/a", `{}`, http.StatusBadRequest},
+		{"fingerprints of a single finding", "/api/v1/projects/api/findings/a", `{"fingerprints":["b"],"state":"new"}`, http.StatusBadRequest},
+		{"no fingerprints", "/api/v1/projects/api/findings", `{"state":"new"}`, http.StatusBadRequest},
+		{"unknown finding", "/api/v1/projects/api/findings/c", `{"state":"new"}`, http.StatusNotFound},
+		{"unknown project", "/api/v1/projects/web/findings/a", `{"state":"new"}`, http.StatusNotFound},
+		{"one unknown finding", "/api/v1/projects/api/findings", `{"fingerprints":["a","c"],"state":"false-positive"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, serveTriage(mux, "PATCH", tt.path, tt.body).Code)
+		})
+	}
+
+	// Nothing was changed by the failed requests
+	rr := serveTriage(mux, "GET", "/api/v1/projects/api/findings/a", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var finding storage.FindingRecord
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &finding))
+	assert.Equal(t, storage.TriageNew, finding.Triage)
+	assert.Nil(t, finding.TriagedAt)
+
+	assert.Equal(t, http.StatusNotFound, serveTriage(mux, "GET", "/api/v1/projects/api/findings/c", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serveTriage(mux, "DELETE", "/api/v1/projects/api/findings/a", "").Code)
+}
+
+func TestProjectFindingsHandler_Overdue(t *testing.T) {
+	configtest.Set(t, "sla:\n  high: 30\n")
+	mux := triageMux(t)
+
+	rr := serveTriage(mux, "GET", "/api/v1/projects/api/findings?overdue=true", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var timeline lifecycle.Timeline
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &timeline))
+	assert.Equal(t, 1, timeline.Overdue)
+	require.Len(t, timeline.Findings, 1)
+	finding := timeline.Findings[0]
+	assert.Equal(t, "a", finding.Fingerprint)
+	assert.True(t, finding.Overdue)
+	require.NotNil(t, finding.DueAt)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), finding.DueAt.UTC())
+
+	// A false positive has no due date
+	rr = serveTriage(mux, "PATCH", "/api/v1/projects/api/findings/a", `{"state":"false-positive"}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	finding = storage.FindingRecord{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &finding))
+	assert.Nil(t, finding.DueAt)
+	assert.False(t, finding.Overdue)
+
+	assert.Equal(t, http.StatusBadRequest, serveTriage(mux, "GET", "/api/v1/projects/api/findings?overdue=maybe", "").Code)
+}
@@ -0,0 +1,288 @@
+// Package rest provides the versions of the API: the deprecation headers of
+// v1 and the compatibility shim serving v2 from the v1 handlers.
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/report"
+)
+
+// API path prefixes of each version.
+const (
+	V1Prefix = "/api/v1"
+	V2Prefix = "/api/v2"
+)
+
+// Versioning configures the deprecation of API v1 in favour of v2.
+type Versioning struct {
+	// Deprecation is when v1 is, or was, deprecated; zero if it is not
+	Deprecation time.Time
+	// Sunset is when v1 is expected to be removed; zero if it is not planned
+	Sunset time.Time
+}
+
+// VersioningFromEnv returns the deprecation of v1 configured by
+// API_V1_DEPRECATION and API_V1_SUNSET, dates such as "2026-01-31" or
+// RFC 3339 times. Invalid values are reported and left unset.
+func VersioningFromEnv() (Versioning, error) {
+	var versioning Versioning
+	var errs []error
+	for _, setting := range []struct {
+		name string
+		date *time.Time
+	}{{"API_V1_DEPRECATION", &versioning.Deprecation}, {"API_V1_SUNSET", &versioning.Sunset}} {
+		value := os.Getenv(setting.name)
+		if value == "" {
+			continue
+		}
+		date, err := parseDate(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q", setting.name, value))
+			continue
+		}
+		*setting.date = date
+	}
+	return versioning, errors.Join(errs...)
+}
+
+// parseDate parses a date or an RFC 3339 time.
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// V1 wraps a v1 handler so that its responses announce the deprecation of
+// v1 with Deprecation (RFC 9745) and Sunset (RFC 8594) headers, once
+// configured, and link to the same resource in v2 when routes serves it.
+func (v Versioning) V1(routes *http.ServeMux, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !v.Deprecation.IsZero() {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(v.Deprecation.Unix(), 10))
+		}
+		if !v.Sunset.IsZero() {
+			w.Header().Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if path, ok := strings.CutPrefix(r.URL.Path, V1Prefix); ok {
+			successor := r.Clone(r.Context())
+			successor.URL.Path = V2Prefix + path
+			successor.URL.RawPath = ""
+			if _, pattern := routes.Handler(successor); pattern != "" {
+				w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor.URL.Path))
+			}
+		}
+		next(w, r)
+	}
+}
+
+// FindingV2 is a finding in API v2 analysis responses. Where v1 returns the
+// details of a finding as flat fields of its result, v2 groups them by what
+// they describe and identifies the finding.
+type FindingV2 struct {
+	// ID identifies the finding across analyses and versions of its
+	// component, as in the finding lifecycle
+	ID    string `json:"id"`
+	Agent string `json:"agent"`
+	// Severity is critical, high, medium or low, in lower case
+	Severity      string             `json:"severity"`
+	Message       string             `json:"message"`
+	RuleID        string             `json:"rule_id,omitempty"`
+	Confidence    *float64           `json:"confidence,omitempty"`
+	Component     *core.ComponentRef `json:"component,omitempty"`
+	Vulnerability *VulnerabilityV2   `json:"vulnerability,omitempty"`
+	Remediation   *RemediationV2     `json:"remediation,omitempty"`
+	Citations     []core.Citation    `json:"citations,omitempty"`
+	// Owner is the team owning the component
+	Owner string `json:"owner,omitempty"`
+}
+
+// VulnerabilityV2 is the known vulnerability a finding reports.
+type VulnerabilityV2 struct {
+	ID string `json:"id"`
+	// FixedVersion is the nearest version fixing this vulnerability
+	FixedVersion string `json:"fixed_version,omitempty"`
+}
+
+// RemediationV2 describes how to fix a finding.
+type RemediationV2 struct {
+	Description string `json:"description"`
+	// UpgradeTo is the nearest version fixing every known vulnerability of
+	// the component
+	UpgradeTo string `json:"upgrade_to,omitempty"`
+}
+
+// StructuredFindings returns the v2 findings of the results of a v1
+// analysis response.
+func StructuredFindings(results []core.AnalysisResult) []FindingV2 {
+	findings := make([]FindingV2, 0, len(results))
+	for _, result := range results {
+		finding := FindingV2{
+			ID:         report.Fingerprint(result),
+			Agent:      result.AgentName,
+			Severity:   strings.ToLower(result.Severity),
+			Message:    result.Finding,
+			RuleID:     result.RuleID,
+			Confidence: result.Confidence,
+			Component:  result.Component,
+			Citations:  result.Citations,
+			Owner:      result.Owner,
+		}
+		if result.VulnerabilityID != "" {
+			finding.Vulnerability = &VulnerabilityV2{ID: result.VulnerabilityID, FixedVersion: result.FixedVersion}
+		}
+		if description := result.Remediation(); description != "" {
+			finding.Remediation = &RemediationV2{Description: description, UpgradeTo: result.UpgradeTo}
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// V2 wraps a v1 handler to serve the same resource in v2. JSON responses
+// are rewritten by the compatibility shim: analysis responses, returned
+// directly or in a field such as the result of a job, have structured
+// "findings" instead of "results". Other responses, such as NDJSON streams,
+// are passed through, and Location headers point into v2.
+func V2(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		shim := &v2ResponseWriter{ResponseWriter: w}
+		next(shim, r)
+		shim.finish()
+	}
+}
+
+// v2ResponseWriter buffers JSON responses until the handler returns, to
+// rewrite them for v2, and writes other responses through.
+type v2ResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	// buffer holds the body of a JSON response; it is nil for responses
+	// written through
+	buffer *bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *v2ResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode != 0 {
+		return
+	}
+	w.statusCode = statusCode
+
+	header := w.Header()
+	if location, ok := strings.CutPrefix(header.Get("Location"), V1Prefix+"/"); ok {
+		header.Set("Location", V2Prefix+"/"+location)
+	}
+	if strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+		// The length changes with the body
+		header.Del("Content-Length")
+		w.buffer = &bytes.Buffer{}
+		return
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter.
+func (w *v2ResponseWriter) Write(data []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffer != nil {
+		return w.buffer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush implements http.Flusher for streamed responses.
+func (w *v2ResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.buffer == nil {
+		flusher.Flush()
+	}
+}
+
+// finish writes a buffered JSON response, rewritten for v2.
+func (w *v2ResponseWriter) finish() {
+	if w.buffer == nil {
+		return
+	}
+	body := w.buffer.Bytes()
+	if rewritten, err := shimV2(body); err == nil {
+		body = rewritten
+	} else {
+		fmt.Printf("Warning: Failed to convert response to API v2: %v\n", err)
+	}
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	w.ResponseWriter.Write(body)
+}
+
+// shimV2 rewrites the analysis responses in a v1 response body for v2:
+// the body itself, or any of its fields, when it is an object.
+func shimV2(body []byte) ([]byte, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil || object == nil {
+		// Arrays and other values hold no analysis response
+		return body, nil
+	}
+
+	rewritten, err := structureAnalysisResponse(object)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range object {
+		var field map[string]json.RawMessage
+		if json.Unmarshal(value, &field) != nil || field == nil {
+			continue
+		}
+		fieldRewritten, err := structureAnalysisResponse(field)
+		if err != nil {
+			return nil, err
+		}
+		if fieldRewritten {
+			if object[name], err = json.Marshal(field); err != nil {
+				return nil, err
+			}
+			rewritten = true
+		}
+	}
+	if !rewritten {
+		return body, nil
+	}
+
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, '\n'), nil
+}
+
+// structureAnalysisResponse replaces the results of an AnalysisResponse,
+// given by its fields, with structured findings, reporting whether the
+// fields were those of an analysis response.
+func structureAnalysisResponse(fields map[string]json.RawMessage) (bool, error) {
+	results, ok := fields["results"]
+	if _, hasSummary := fields["summary"]; !ok || !hasSummary || fields["sbom_id"] == nil {
+		return false, nil
+	}
+
+	var parsed []core.AnalysisResult
+	if err := json.Unmarshal(results, &parsed); err != nil {
+		return false, fmt.Errorf("failed to parse analysis results: %w", err)
+	}
+	findings, err := json.Marshal(StructuredFindings(parsed))
+	if err != nil {
+		return false, err
+	}
+	delete(fields, "results")
+	fields["findings"] = findings
+	return true, nil
+}
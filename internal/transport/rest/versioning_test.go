@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/jobs"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersioningFromEnv(t *testing.T) {
+	t.Setenv("API_V1_DEPRECATION", "")
+	t.Setenv("API_V1_SUNSET", "")
+	versioning, err := VersioningFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Versioning{}, versioning)
+
+	t.Setenv("API_V1_DEPRECATION", "2026-01-31")
+	t.Setenv("API_V1_SUNSET", "2026-07-01T00:00:00Z")
+	versioning, err = VersioningFromEnv()
+	require.NoError(t, err)
+	assert.True(t, versioning.Deprecation.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, versioning.Sunset.Equal(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)))
+
+	t.Setenv("API_V1_SUNSET", "next summer")
+	versioning, err = VersioningFromEnv()
+	assert.ErrorContains(t, err, "API_V1_SUNSET")
+	assert.True(t, versioning.Sunset.IsZero())
+}
+
+func TestVersioning(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "sbom-1", Name: "api", Components: []core.Component{
+		{Name: "readline", Version: "8.2", License: "GPL-3.0"},
+	}}))
+	queue := jobs.NewMemoryQueue()
+	versioning := Versioning{
+		Deprecation: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+		Sunset:      time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	mux := http.NewServeMux()
+	analyze := AsyncAnalyzeHandler(repo, queue, AnalyzeSBOMHandler(repo, nil))
+	mux.HandleFunc("/api/v1/sboms/{id}/analyze", versioning.V1(mux, analyze))
+	mux.HandleFunc("/api/v2/sboms/{id}/analyze", V2(analyze))
+	mux.HandleFunc("/api/v1/jobs/{id}", versioning.V1(mux, JobHandler(queue)))
+	mux.HandleFunc("/api/v2/jobs/{id}", V2(JobHandler(queue)))
+	mux.HandleFunc("/api/v1/sboms/get", versioning.V1(mux, GetSBOMHandler(repo)))
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	// v1 keeps its schema and announces its deprecation
+	rr := serve("POST", "/api/v1/sboms/sbom-1/analyze")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "@1769817600", rr.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", rr.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v2/sboms/sbom-1/analyze>; rel="successor-version"`, rr.Header().Get("Link"))
+	var v1 AnalysisResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &v1))
+	require.Len(t, v1.Results, 1)
+
+	// v2 returns the same analysis with structured findings
+	rr = serve("POST", "/api/v2/sboms/sbom-1/analyze")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+	var v2 struct {
+		SBOMID   string          `json:"sbom_id"`
+		Results  json.RawMessage `json:"results"`
+		Findings []FindingV2     `json:"findings"`
+		Summary  AnalysisSummary `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &v2))
+	assert.Equal(t, "sbom-1", v2.SBOMID)
+	assert.Nil(t, v2.Results)
+	assert.Equal(t, 1, v2.Summary.TotalFindings)
+	require.Len(t, v2.Findings, 1)
+	assert.Equal(t, v1.Results[0].AgentName, v2.Findings[0].Agent)
+	assert.Contains(t, v2.Findings[0].Message, "readline")
+	assert.Equal(t, "high", v2.Findings[0].Severity)
+	assert.Equal(t, "readline", v2.Findings[0].Component.Name)
+	assert.NotEmpty(t, v2.Findings[0].ID)
+
+	// Queued jobs link into v2, and their results are converted
+	rr = serve("POST", "/api/v2/sboms/sbom-1/analyze?async=true")
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	var job jobs.Job
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &job))
+	assert.Equal(t, "/api/v2/jobs/"+job.ID, rr.Header().Get("Location"))
+	queued, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	result, err := AnalysisJobHandler(repo, nil)(ctx, *queued)
+	require.NoError(t, err)
+	finished := core.Now()
+	queued.Status, queued.Result, queued.FinishedAt = jobs.StatusSucceeded, result, &finished
+	stored, err := queue.Transition(ctx, *queued, jobs.StatusQueued)
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	rr = serve("GET", "/api/v2/jobs/"+job.ID)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var v2Job struct {
+		Status string `json:"status"`
+		Result struct {
+			Findings []FindingV2 `json:"findings"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &v2Job))
+	assert.Equal(t, jobs.StatusSucceeded, v2Job.Status)
+	assert.Len(t, v2Job.Result.Findings, 1)
+
+	// Errors are passed through, and v1 routes without a v2 successor link nowhere
+	rr = serve("POST", "/api/v2/sboms/unknown/analyze")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), "not_found")
+	rr = serve("GET", "/api/v1/sboms/get?id=sbom-1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Link"))
+}
+
+func TestStructuredFindings(t *testing.T) {
+	confidence := 0.8
+	findings := StructuredFindings([]core.AnalysisResult{{
+		AgentName:       "Vulnerability Scanner",
+		Finding:         "log4j-core 2.14.1 is affected by CVE-2021-44228",
+		Severity:        "Critical",
+		Component:       &core.ComponentRef{Name: "log4j-core", Version: "2.14.1"},
+		VulnerabilityID: "CVE-2021-44228",
+		FixedVersion:    "2.15.0",
+		UpgradeTo:       "2.17.1",
+		Confidence:      &confidence,
+		Owner:           "platform",
+	}})
+
+	require.Len(t, findings, 1)
+	finding := findings[0]
+	assert.Equal(t, "critical", finding.Severity)
+	assert.Equal(t, &VulnerabilityV2{ID: "CVE-2021-44228", FixedVersion: "2.15.0"}, finding.Vulnerability)
+	assert.Equal(t, "2.17.1", finding.Remediation.UpgradeTo)
+	assert.Equal(t, "Upgrade to 2.17.1 (this vulnerability is fixed in 2.15.0)", finding.Remediation.Description)
+	assert.Equal(t, &confidence, finding.Confidence)
+	assert.Equal(t, "platform", finding.Owner)
+}
@@ -0,0 +1,148 @@
+// Package rest provides HTTP handlers for vulnerability impact lookups.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+)
+
+// VulnerabilitySource retrieves vulnerability records and matches them against components.
+// analysis.VulnerabilityScanningAgent implements this interface using OSV.dev.
+type VulnerabilitySource interface {
+	FetchVulnerability(ctx context.Context, id string) (*analysis.OSVVulnerability, error)
+	AffectsComponent(vuln analysis.OSVVulnerability, component core.Component) bool
+}
+
+// AffectedSBOM is a stored SBOM containing components affected by a vulnerability.
+type AffectedSBOM struct {
+	SBOMID     string           `json:"sbom_id"`
+	SBOMName   string           `json:"sbom_name"`
+	Components []core.Component `json:"components"`
+}
+
+// AffectedSBOMsResponse represents the JSON response for a vulnerability impact lookup.
+type AffectedSBOMsResponse struct {
+	VulnerabilityID string         `json:"vulnerability_id"`
+	Aliases         []string       `json:"aliases"`
+	Summary         string         `json:"summary"`
+	TotalAffected   int            `json:"total_affected"`
+	AffectedSBOMs   []AffectedSBOM `json:"affected_sboms"`
+}
+
+// maxAliasLookups bounds the alias records fetched for one vulnerability.
+const maxAliasLookups = 10
+
+// AffectedSBOMsHandler creates an HTTP handler that lists every stored SBOM
+// affected by a vulnerability.
+// It expects a GET request to /api/v1/vulnerabilities/{id}/affected, where id
+// is a CVE, GHSA or OSV identifier resolved through the vulnerability source.
+// The affected packages of the records the vulnerability is an alias of are
+// matched too, since a CVE record usually names no ecosystem packages and
+// the GHSA or ecosystem advisories for the same CVE do.
+func AffectedSBOMsHandler(repo storage.Repository, source VulnerabilitySource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		// Extract vulnerability ID from URL path
+		// Expected format: /api/v1/vulnerabilities/{id}/affected
+		vulnID := r.PathValue("id")
+		if vulnID == "" {
+			// Not routed through a pattern; parse the path directly
+			pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+			if len(pathParts) == 5 && pathParts[4] == "affected" {
+				vulnID = pathParts[3]
+			}
+		}
+		if vulnID == "" {
+			writeErrorResponse(w, http.StatusBadRequest, "missing_id", "Vulnerability ID is required in URL path")
+			return
+		}
+
+		ctx := r.Context()
+		vuln, err := source.FetchVulnerability(ctx, vulnID)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadGateway, "vulnerability_lookup_error", fmt.Sprintf("Failed to retrieve vulnerability: %v", err))
+			return
+		}
+		if vuln == nil {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "Vulnerability not found")
+			return
+		}
+
+		affected := withAliasRecords(ctx, source, *vuln)
+
+		sboms, err := repo.FindAll(ctx)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to retrieve SBOMs: %v", err))
+			return
+		}
+
+		affectedSBOMs := make([]AffectedSBOM, 0)
+		for _, sbom := range sboms {
+			var components []core.Component
+			for _, component := range sbom.Components {
+				if source.AffectsComponent(affected, component) {
+					components = append(components, component)
+				}
+			}
+			if len(components) > 0 {
+				affectedSBOMs = append(affectedSBOMs, AffectedSBOM{
+					SBOMID:     sbom.ID,
+					SBOMName:   sbom.Name,
+					Components: components,
+				})
+			}
+		}
+
+		response := AffectedSBOMsResponse{
+			VulnerabilityID: vuln.ID,
+			Aliases:         vuln.Aliases,
+			Summary:         vuln.Summary,
+			TotalAffected:   len(affectedSBOMs),
+			AffectedSBOMs:   affectedSBOMs,
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			// Log the error, but response has already been started
+			fmt.Printf("Error encoding response: %v\n", err)
+		}
+	}
+}
+
+// withAliasRecords returns vuln with the affected packages of its aliases
+// added. Aliases that cannot be fetched are logged and skipped.
+func withAliasRecords(ctx context.Context, source VulnerabilitySource, vuln analysis.OSVVulnerability) analysis.OSVVulnerability {
+	merged := vuln
+	merged.Affected = append([]analysis.OSVAffected(nil), vuln.Affected...)
+	looked := 0
+	for _, alias := range vuln.Aliases {
+		if alias == "" || strings.EqualFold(alias, vuln.ID) || looked == maxAliasLookups {
+			continue
+		}
+		looked++
+		record, err := source.FetchVulnerability(ctx, alias)
+		if err != nil {
+			fmt.Printf("Warning: Failed to retrieve alias %s of %s: %v\n", alias, vuln.ID, err)
+			continue
+		}
+		if record != nil {
+			merged.Affected = append(merged.Affected, record.Affected...)
+		}
+	}
+	return merged
+}
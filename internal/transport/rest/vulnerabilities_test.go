@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVulnerabilitySource serves a fixed vulnerability and delegates matching
+// to the real OSV matcher.
+type fakeVulnerabilitySource struct {
+	vuln *analysis.OSVVulnerability
+	err  error
+}
+
+func (f *fakeVulnerabilitySource) FetchVulnerability(ctx context.Context, id string) (*analysis.OSVVulnerability, error) {
+	return f.vuln, f.err
+}
+
+func (f *fakeVulnerabilitySource) AffectsComponent(vuln analysis.OSVVulnerability, component core.Component) bool {
+	return analysis.NewVulnerabilityScanningAgent().AffectsComponent(vuln, component)
+}
+
+func TestAffectedSBOMsHandler(t *testing.T) {
+	log4shell := &analysis.OSVVulnerability{
+		ID:      "GHSA-jfh8-c2jp-5v3q",
+		Aliases: []string{"CVE-2021-44228"},
+		Summary: "Remote code injection in Log4j",
+		Affected: []analysis.OSVAffected{
+			{
+				Package: analysis.OSVPackage{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"},
+				Ranges: []analysis.OSVRange{
+					{Type: "ECOSYSTEM", Events: []analysis.OSVEvent{{Introduced: "2.0-beta9"}, {Fixed: "2.15.0"}}},
+				},
+			},
+		},
+	}
+
+	storedSBOMs := []core.SBOM{
+		{
+			ID:   "sbom-vulnerable",
+			Name: "billing-service",
+			Components: []core.Component{
+				{Name: "log4j-core", Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+				{Name: "commons-lang3", Version: "3.12.0", PURL: "pkg:maven/org.apache.commons/commons-lang3@3.12.0"},
+			},
+		},
+		{
+			ID:   "sbom-patched",
+			Name: "search-service",
+			Components: []core.Component{
+				{Name: "log4j-core", Version: "2.17.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		source             *fakeVulnerabilitySource
+		mockBehavior       func(*MockRepository)
+		expectedStatusCode int
+		expectedSBOMIDs    []string
+	}{
+		{
+			name:   "Affected SBOMs found",
+			method: "GET",
+			path:   "/api/v1/vulnerabilities/CVE-2021-44228/affected",
+			source: &fakeVulnerabilitySource{vuln: log4shell},
+			mockBehavior: func(mockRepo *MockRepository) {
+				mockRepo.On("FindAll", mock.Anything).Return(storedSBOMs, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedSBOMIDs:    []string{"sbom-vulnerable"},
+		},
+		{
+			name:               "Unknown vulnerability",
+			method:             "GET",
+			path:               "/api/v1/vulnerabilities/CVE-0000-0000/affected",
+			source:             &fakeVulnerabilitySource{},
+			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "Vulnerability lookup error",
+			method:             "GET",
+			path:               "/api/v1/vulnerabilities/CVE-2021-44228/affected",
+			source:             &fakeVulnerabilitySource{err: errors.New("connection refused")},
+			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusBadGateway,
+		},
+		{
+			name:   "Storage error",
+			method: "GET",
+			path:   "/api/v1/vulnerabilities/CVE-2021-44228/affected",
+			source: &fakeVulnerabilitySource{vuln: log4shell},
+			mockBehavior: func(mockRepo *MockRepository) {
+				mockRepo.On("FindAll", mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:               "Malformed path",
+			method:             "GET",
+			path:               "/api/v1/vulnerabilities/CVE-2021-44228",
+			source:             &fakeVulnerabilitySource{vuln: log4shell},
+			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Wrong HTTP method",
+			method:             "POST",
+			path:               "/api/v1/vulnerabilities/CVE-2021-44228/affected",
+			source:             &fakeVulnerabilitySource{vuln: log4shell},
+			mockBehavior:       func(mockRepo *MockRepository) {},
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			tt.mockBehavior(mockRepo)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			AffectedSBOMsHandler(mockRepo, tt.source).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+
+			if tt.expectedStatusCode == http.StatusOK {
+				var response AffectedSBOMsResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, "GHSA-jfh8-c2jp-5v3q", response.VulnerabilityID)
+				assert.Equal(t, len(tt.expectedSBOMIDs), response.TotalAffected)
+
+				var ids []string
+				for _, affected := range response.AffectedSBOMs {
+					ids = append(ids, affected.SBOMID)
+				}
+				assert.Equal(t, tt.expectedSBOMIDs, ids)
+				require.Len(t, response.AffectedSBOMs[0].Components, 1)
+				assert.Equal(t, "log4j-core", response.AffectedSBOMs[0].Components[0].Name)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// recordsVulnerabilitySource serves vulnerability records by ID.
+type recordsVulnerabilitySource struct {
+	records map[string]*analysis.OSVVulnerability
+	fetched []string
+}
+
+func (f *recordsVulnerabilitySource) FetchVulnerability(ctx context.Context, id string) (*analysis.OSVVulnerability, error) {
+	f.fetched = append(f.fetched, id)
+	if id == "GHSA-unreachable" {
+		return nil, errors.New("connection refused")
+	}
+	return f.records[id], nil
+}
+
+func (f *recordsVulnerabilitySource) AffectsComponent(vuln analysis.OSVVulnerability, component core.Component) bool {
+	return analysis.NewVulnerabilityScanningAgent().AffectsComponent(vuln, component)
+}
+
+func TestAffectedSBOMsHandler_CVEAliases(t *testing.T) {
+	// A CVE record names no ecosystem packages; its GHSA alias does
+	source := &recordsVulnerabilitySource{records: map[string]*analysis.OSVVulnerability{
+		"CVE-2021-44228": {
+			ID:      "CVE-2021-44228",
+			Aliases: []string{"GHSA-unreachable", "GHSA-jfh8-c2jp-5v3q"},
+			Summary: "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP",
+		},
+		"GHSA-jfh8-c2jp-5v3q": {
+			ID:      "GHSA-jfh8-c2jp-5v3q",
+			Aliases: []string{"CVE-2021-44228"},
+			Affected: []analysis.OSVAffected{{
+				Package: analysis.OSVPackage{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"},
+				Ranges:  []analysis.OSVRange{{Type: "ECOSYSTEM", Events: []analysis.OSVEvent{{Introduced: "2.0-beta9"}, {Fixed: "2.15.0"}}}},
+			}},
+		},
+	}}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("FindAll", mock.Anything).Return([]core.SBOM{{
+		ID:   "sbom-vulnerable",
+		Name: "billing-service",
+		Components: []core.Component{
+			{Name: "log4j-core", Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+		},
+	}}, nil)
+
+	rr := httptest.NewRecorder()
+	AffectedSBOMsHandler(mockRepo, source).ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/vulnerabilities/CVE-2021-44228/affected", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response AffectedSBOMsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "CVE-2021-44228", response.VulnerabilityID)
+	assert.Equal(t, 1, response.TotalAffected)
+	require.Len(t, response.AffectedSBOMs, 1)
+	assert.Equal(t, "sbom-vulnerable", response.AffectedSBOMs[0].SBOMID)
+	assert.Equal(t, []string{"CVE-2021-44228", "GHSA-unreachable", "GHSA-jfh8-c2jp-5v3q"}, source.fetched)
+}
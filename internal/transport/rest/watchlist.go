@@ -0,0 +1,115 @@
+// Package rest provides HTTP handlers for the watchlist of packages whose
+// mentions in new security intelligence are alerted on.
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/watchlist"
+)
+
+// WatchRequest represents the JSON body adding a watch.
+type WatchRequest struct {
+	PURL string `json:"purl"`
+	Note string `json:"note,omitempty"`
+}
+
+// WatchlistResponse represents the JSON response listing the watches.
+type WatchlistResponse struct {
+	TotalWatches int             `json:"total_watches"`
+	Watches      []storage.Watch `json:"watches"`
+}
+
+// WatchlistHandler creates an HTTP handler for managing watched packages:
+//
+//	GET    /api/v1/watchlist      - list watches
+//	POST   /api/v1/watchlist      - watch a package (JSON body with "purl" and "note")
+//	DELETE /api/v1/watchlist/{id} - remove a watch
+//
+// Watching an already watched package returns the existing watch with 200 OK
+// instead of 201 Created.
+func WatchlistHandler(watches storage.Watchlist) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			list, err := watches.ListWatches(r.Context())
+			if err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to list watches: %v", err))
+				return
+			}
+			writeJSONResponse(w, http.StatusOK, WatchlistResponse{TotalWatches: len(list), Watches: list})
+
+		case http.MethodPost:
+			var request WatchRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_json", fmt.Sprintf("Failed to parse watch: %v", err))
+				return
+			}
+
+			watch, err := watchlist.NewWatch(request.PURL, request.Note)
+			if errors.Is(err, watchlist.ErrInvalidWatch) {
+				writeErrorResponse(w, http.StatusBadRequest, "invalid_watch", err.Error())
+				return
+			}
+			stored, err := watches.AddWatch(r.Context(), watch)
+			if err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to add watch: %v", err))
+				return
+			}
+
+			status := http.StatusCreated
+			if stored.ID != watch.ID {
+				status = http.StatusOK
+			}
+			writeJSONResponse(w, status, stored)
+
+		case http.MethodDelete:
+			id := r.PathValue("id")
+			if id == "" {
+				writeErrorResponse(w, http.StatusBadRequest, "missing_id", "Watch ID is required in URL path")
+				return
+			}
+
+			deleted, err := watches.DeleteWatch(r.Context(), id)
+			if err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, "storage_error", fmt.Sprintf("Failed to remove watch: %v", err))
+				return
+			}
+			if !deleted {
+				writeErrorResponse(w, http.StatusNotFound, "not_found", "Watch not found")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET, POST and DELETE methods are allowed")
+		}
+	}
+}
+
+// WatchlistNotifier returns where watchlist alerts are sent: the
+// notification channels of the current configuration, or nil if it has none.
+// Alerts are assigned to the teams owning the watched components.
+func WatchlistNotifier() notify.Notifier {
+	notifier := findingNotifier()
+	if notifier == nil {
+		return nil
+	}
+	return ownedNotifier{Notifier: notifier, owners: config.Default().Owners}
+}
+
+// SLANotifier returns where SLA breaches are sent: the same channels as
+// watchlist alerts, with breaches assigned to the teams owning their
+// components.
+func SLANotifier() notify.Notifier {
+	return WatchlistNotifier()
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWatchlist keeps watches in memory.
+type fakeWatchlist struct {
+	watches []storage.Watch
+}
+
+func (f *fakeWatchlist) AddWatch(ctx context.Context, watch storage.Watch) (storage.Watch, error) {
+	for _, existing := range f.watches {
+		if existing.PURL == watch.PURL {
+			return existing, nil
+		}
+	}
+	f.watches = append(f.watches, watch)
+	return watch, nil
+}
+
+func (f *fakeWatchlist) ListWatches(ctx context.Context) ([]storage.Watch, error) {
+	return append([]storage.Watch{}, f.watches...), nil
+}
+
+func (f *fakeWatchlist) DeleteWatch(ctx context.Context, id string) (bool, error) {
+	for i, watch := range f.watches {
+		if watch.ID == id {
+			f.watches = append(f.watches[:i], f.watches[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestWatchlistHandler(t *testing.T) {
+	watches := &fakeWatchlist{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/watchlist", WatchlistHandler(watches))
+	mux.HandleFunc("/api/v1/watchlist/{id}", WatchlistHandler(watches))
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("POST", "/api/v1/watchlist", `{"purl": "pkg:npm/lodash", "note": "core dependency"}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var watch storage.Watch
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &watch))
+	assert.Equal(t, "pkg:npm/lodash", watch.PURL)
+	assert.Equal(t, "core dependency", watch.Note)
+
+	// Watching the package again returns the existing watch
+	rr = serve("POST", "/api/v1/watchlist", `{"purl": "pkg:npm/lodash"}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var existing storage.Watch
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &existing))
+	assert.Equal(t, watch.ID, existing.ID)
+
+	rr = serve("POST", "/api/v1/watchlist", `{"purl": "lodash"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid_watch")
+
+	rr = serve("GET", "/api/v1/watchlist", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var list WatchlistResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.TotalWatches)
+
+	rr = serve("DELETE", "/api/v1/watchlist/"+watch.ID, "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	rr = serve("DELETE", "/api/v1/watchlist/"+watch.ID, "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = serve("PUT", "/api/v1/watchlist", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -0,0 +1,341 @@
+// Package versions provides the version orderings of Linux distribution
+// packages: dpkg for Debian and Ubuntu, rpm for Red Hat and its rebuilds,
+// and apk for Alpine.
+package versions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareDebian compares two Debian package versions
+// ([epoch:]upstream[-revision]) as dpkg does, returning -1, 0 or 1. Letters
+// sort before other characters, and "~" before anything, even the end of
+// the version, so "1.0~rc1" < "1.0".
+func CompareDebian(a, b string) int {
+	aEpoch, aUpstream, aRevision := splitEpoch(a)
+	bEpoch, bUpstream, bRevision := splitEpoch(b)
+
+	if c := compareInts(aEpoch, bEpoch); c != 0 {
+		return c
+	}
+	if c := compareDpkg(aUpstream, bUpstream); c != 0 {
+		return c
+	}
+	return compareDpkg(aRevision, bRevision)
+}
+
+// splitEpoch splits a distribution package version into its numeric epoch
+// (0 if absent), its version and its revision or release: the part after
+// the last "-", if any.
+func splitEpoch(version string) (uint64, string, string) {
+	version = strings.TrimSpace(version)
+
+	var epoch uint64
+	if before, after, ok := strings.Cut(version, ":"); ok {
+		if n, err := strconv.ParseUint(before, 10, 64); err == nil {
+			epoch, version = n, after
+		}
+	}
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		return epoch, version[:i], version[i+1:]
+	}
+	return epoch, version, ""
+}
+
+// dpkgOrder is the weight of the character at i in the non-digit part of a
+// Debian version; the end of the part weighs 0.
+func dpkgOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	switch c := s[i]; {
+	case c == '~':
+		return -1
+	case isDigit(c):
+		return 0
+	case isLetter(c):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// compareDpkg compares upstream versions or revisions with the dpkg
+// algorithm: alternating non-digit parts, compared by dpkgOrder, and digit
+// parts, compared numerically.
+func compareDpkg(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			if ac, bc := dpkgOrder(a, i), dpkgOrder(b, j); ac != bc {
+				return sign(ac - bc)
+			}
+			i, j = i+1, j+1
+		}
+
+		var an, bn string
+		an, i = run(a, i, isDigit)
+		bn, j = run(b, j, isDigit)
+		if c := compareNumeric(an, bn); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// CompareRPM compares two RPM package versions ([epoch:]version[-release])
+// as rpm does, returning -1, 0 or 1. A missing epoch is 0, and releases are
+// only compared if both versions have one.
+func CompareRPM(a, b string) int {
+	aEpoch, aVersion, aRelease := splitEpoch(a)
+	bEpoch, bVersion, bRelease := splitEpoch(b)
+
+	if c := compareInts(aEpoch, bEpoch); c != 0 {
+		return c
+	}
+	if c := rpmvercmp(aVersion, bVersion); c != 0 || aRelease == "" || bRelease == "" {
+		return c
+	}
+	return rpmvercmp(aRelease, bRelease)
+}
+
+// rpmvercmp compares two RPM version or release strings as rpm's rpmvercmp:
+// runs of digits and of letters are compared pairwise, separators are
+// skipped, a numeric run is newer than an alphabetic one, "~" sorts before
+// anything and "^" after the end of the string but before anything else.
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		aTilde, bTilde := i < len(a) && a[i] == '~', j < len(b) && b[j] == '~'
+		if aTilde || bTilde {
+			if !aTilde {
+				return 1
+			}
+			if !bTilde {
+				return -1
+			}
+			i, j = i+1, j+1
+			continue
+		}
+
+		aCaret, bCaret := i < len(a) && a[i] == '^', j < len(b) && b[j] == '^'
+		if aCaret || bCaret {
+			switch {
+			case i >= len(a):
+				return -1
+			case j >= len(b):
+				return 1
+			case !aCaret:
+				return 1
+			case !bCaret:
+				return -1
+			}
+			i, j = i+1, j+1
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		var an, bn string
+		numeric := isDigit(a[i])
+		if numeric {
+			an, i = run(a, i, isDigit)
+			bn, j = run(b, j, isDigit)
+		} else {
+			an, i = run(a, i, isLetter)
+			bn, j = run(b, j, isLetter)
+		}
+		if bn == "" {
+			// The runs differ in kind: numeric is newer than alphabetic
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		c := 0
+		if numeric {
+			c = compareNumeric(an, bn)
+		} else {
+			c = strings.Compare(an, bn)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i < len(a):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// alpineSuffixes ranks the suffixes of Alpine versions; pre-release
+// suffixes sort before a version without a suffix (rank 4), and
+// post-release suffixes after it.
+var alpineSuffixes = map[string]int{
+	"alpha": 0,
+	"beta":  1,
+	"pre":   2,
+	"rc":    3,
+	"cvs":   5,
+	"svn":   6,
+	"git":   7,
+	"hg":    8,
+	"p":     9,
+}
+
+// alpineNoSuffix is the rank of the absence of a suffix.
+const alpineNoSuffix = 4
+
+// alpineVersion is a parsed Alpine package version:
+// numbers[letter][_suffix[number]]...[-r revision].
+type alpineVersion struct {
+	numbers  []string
+	letter   byte
+	suffixes [][2]string
+	revision string
+}
+
+// CompareAlpine compares two Alpine package versions as apk does, returning
+// -1, 0 or 1, e.g. "3.1.4-r5" < "3.1.4-r6" < "3.1.5_rc1-r0" < "3.1.5-r0".
+// Versions that do not follow the apk format are compared generically.
+func CompareAlpine(a, b string) int {
+	av, aOK := parseAlpine(a)
+	bv, bOK := parseAlpine(b)
+	if !aOK || !bOK {
+		return CompareGeneric(a, b)
+	}
+
+	for i := 0; i < len(av.numbers) || i < len(bv.numbers); i++ {
+		switch {
+		case i >= len(av.numbers):
+			return -1
+		case i >= len(bv.numbers):
+			return 1
+		}
+		if c := compareNumeric(av.numbers[i], bv.numbers[i]); c != 0 {
+			return c
+		}
+	}
+
+	if c := sign(int(av.letter) - int(bv.letter)); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(av.suffixes) || i < len(bv.suffixes); i++ {
+		aRank, bRank := alpineNoSuffix, alpineNoSuffix
+		var aNumber, bNumber string
+		if i < len(av.suffixes) {
+			aRank, aNumber = alpineSuffixes[av.suffixes[i][0]], av.suffixes[i][1]
+		}
+		if i < len(bv.suffixes) {
+			bRank, bNumber = alpineSuffixes[bv.suffixes[i][0]], bv.suffixes[i][1]
+		}
+		if c := sign(aRank - bRank); c != 0 {
+			return c
+		}
+		if c := compareNumeric(aNumber, bNumber); c != 0 {
+			return c
+		}
+	}
+
+	return compareNumeric(av.revision, bv.revision)
+}
+
+// parseAlpine parses an Alpine package version, reporting false if it does
+// not follow the apk format.
+func parseAlpine(version string) (alpineVersion, bool) {
+	var v alpineVersion
+	s := strings.TrimSpace(version)
+
+	if before, revision, ok := strings.Cut(s, "-r"); ok {
+		if _, err := strconv.ParseUint(revision, 10, 64); err != nil {
+			return v, false
+		}
+		s, v.revision = before, revision
+	}
+
+	main, suffixes, _ := strings.Cut(s, "_")
+	numbers := strings.Split(main, ".")
+	for i, number := range numbers {
+		// The last number may be followed by a single letter
+		if n := len(number); i == len(numbers)-1 && n > 1 && isLetter(number[n-1]) {
+			v.letter = number[n-1]
+			number = number[:n-1]
+		}
+		if number == "" {
+			return v, false
+		}
+		if _, err := strconv.ParseUint(number, 10, 64); err != nil {
+			return v, false
+		}
+		v.numbers = append(v.numbers, number)
+	}
+
+	if suffixes != "" {
+		for _, suffix := range strings.Split(suffixes, "_") {
+			name, number := run(suffix, 0, isLetter)
+			if _, known := alpineSuffixes[name]; !known {
+				return v, false
+			}
+			v.suffixes = append(v.suffixes, [2]string{name, suffix[number:]})
+		}
+	}
+	return v, true
+}
+
+// run returns the run of characters of s from i accepted by accept, and the
+// index following it.
+func run(s string, i int, accept func(byte) bool) (string, int) {
+	start := i
+	for i < len(s) && accept(s[i]) {
+		i++
+	}
+	return s[start:i], i
+}
+
+// compareNumeric compares two strings of digits numerically, ignoring
+// leading zeros; empty strings count as zero.
+func compareNumeric(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if c := sign(len(a) - len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isAlnum(c byte) bool  { return isDigit(c) || isLetter(c) }
+
+// sign returns -1, 0 or 1 according to the sign of n.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,160 @@
+// Package versions provides Maven version comparison.
+package versions
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// mavenQualifiers ranks the well-known Maven qualifiers; a release has the
+// empty qualifier. Unknown qualifiers sort after all of these, lexically.
+var mavenQualifiers = map[string]int{
+	"alpha":     0,
+	"beta":      1,
+	"milestone": 2,
+	"rc":        3,
+	"snapshot":  4,
+	"":          5,
+	"sp":        6,
+}
+
+// mavenItem is a single token of a Maven version: a number or a qualifier.
+type mavenItem struct {
+	numeric   bool
+	number    uint64
+	qualifier string
+}
+
+// CompareMaven compares two Maven versions following the ordering of Maven's
+// ComparableVersion, returning -1, 0 or 1. Versions are split into numbers
+// and qualifiers at ".", "-" and digit/letter transitions; numbers sort
+// after qualifiers, and qualifiers order as
+// alpha < beta < milestone < rc < snapshot < release < sp. Trailing zeros and
+// release qualifiers ("ga", "final", "release") are insignificant, so
+// "1.0" == "1.0.0" == "1.0-final".
+func CompareMaven(a, b string) int {
+	aItems := parseMaven(a)
+	bItems := parseMaven(b)
+
+	for i := 0; i < len(aItems) || i < len(bItems); i++ {
+		var c int
+		switch {
+		case i >= len(aItems):
+			c = -compareMavenToPadding(bItems[i])
+		case i >= len(bItems):
+			c = compareMavenToPadding(aItems[i])
+		default:
+			c = compareMavenItems(aItems[i], bItems[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// parseMaven tokenizes a Maven version into numbers and normalized qualifiers.
+func parseMaven(version string) []mavenItem {
+	version = strings.ToLower(strings.TrimSpace(version))
+
+	var (
+		items   []mavenItem
+		current strings.Builder
+		digits  bool
+	)
+	flush := func(next rune) {
+		if current.Len() == 0 {
+			return
+		}
+		token := current.String()
+		current.Reset()
+
+		if digits {
+			n, _ := strconv.ParseUint(token, 10, 64)
+			items = append(items, mavenItem{numeric: true, number: n})
+			return
+		}
+
+		// A single letter directly followed by a number abbreviates a qualifier, e.g. "1.0-a1"
+		if unicode.IsDigit(next) {
+			switch token {
+			case "a":
+				token = "alpha"
+			case "b":
+				token = "beta"
+			case "m":
+				token = "milestone"
+			}
+		}
+		switch token {
+		case "ga", "final", "release":
+			token = ""
+		case "cr":
+			token = "rc"
+		}
+		items = append(items, mavenItem{qualifier: token})
+	}
+
+	runes := []rune(version)
+	for i, r := range runes {
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case r == '.' || r == '-' || r == '_':
+			flush(next)
+		case unicode.IsDigit(r) != digits && current.Len() > 0:
+			flush(r)
+			digits = unicode.IsDigit(r)
+			current.WriteRune(r)
+		default:
+			digits = unicode.IsDigit(r)
+			current.WriteRune(r)
+		}
+	}
+	flush(0)
+
+	return items
+}
+
+// compareMavenItems compares two tokens; numbers sort after qualifiers.
+func compareMavenItems(a, b mavenItem) int {
+	switch {
+	case a.numeric && b.numeric:
+		return compareInts(a.number, b.number)
+	case a.numeric:
+		return 1
+	case b.numeric:
+		return -1
+	default:
+		return compareQualifiers(a.qualifier, b.qualifier)
+	}
+}
+
+// compareMavenToPadding compares a token with the missing token of a shorter
+// version, which behaves as zero or as a release.
+func compareMavenToPadding(item mavenItem) int {
+	if item.numeric {
+		return compareInts(item.number, 0)
+	}
+	return compareQualifiers(item.qualifier, "")
+}
+
+// compareQualifiers compares two qualifiers by rank, then lexically.
+func compareQualifiers(a, b string) int {
+	aRank, aKnown := mavenQualifiers[a]
+	bRank, bKnown := mavenQualifiers[b]
+	switch {
+	case aKnown && bKnown:
+		return compareInts(uint64(aRank), uint64(bRank))
+	case aKnown:
+		return -1
+	case bKnown:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
@@ -0,0 +1,192 @@
+// Package versions provides PEP 440 version comparison for Python packages.
+package versions
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pep440Pattern matches a PEP 440 version, accepting the alternative
+// spellings and separators that normalize to the canonical form.
+var pep440Pattern = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|alpha|b|beta|c|rc|pre|preview)[-_.]?(\d*))?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+	`(?:[-_.]?(dev)[-_.]?(\d*))?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// pep440Version is a parsed PEP 440 version.
+type pep440Version struct {
+	epoch   uint64
+	release []uint64
+	// preKind is "a", "b" or "rc", or empty for no pre-release
+	preKind string
+	pre     uint64
+	hasPost bool
+	post    uint64
+	hasDev  bool
+	dev     uint64
+	local   []string
+}
+
+// ComparePEP440 compares two Python package versions following PEP 440,
+// returning -1, 0 or 1. Development releases sort before pre-releases, which
+// sort before the final release, which sorts before post-releases; trailing
+// zeros in the release are insignificant ("1.0" == "1.0.0"). Versions that
+// are not valid PEP 440 fall back to CompareGeneric.
+func ComparePEP440(a, b string) int {
+	av, aOK := parsePEP440(a)
+	bv, bOK := parsePEP440(b)
+	if !aOK || !bOK {
+		return CompareGeneric(a, b)
+	}
+
+	if c := compareInts(av.epoch, bv.epoch); c != 0 {
+		return c
+	}
+	if c := compareRelease(av.release, bv.release); c != 0 {
+		return c
+	}
+	if c := compareKeys(av.preKey(), bv.preKey()); c != 0 {
+		return c
+	}
+	if c := compareKeys(av.postKey(), bv.postKey()); c != 0 {
+		return c
+	}
+	if c := compareKeys(av.devKey(), bv.devKey()); c != 0 {
+		return c
+	}
+	return compareLocal(av.local, bv.local)
+}
+
+// parsePEP440 parses a version, reporting false if it is not valid PEP 440.
+func parsePEP440(version string) (pep440Version, bool) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(version)))
+	if m == nil {
+		return pep440Version{}, false
+	}
+
+	var v pep440Version
+	v.epoch = parseUint(m[1])
+	for _, part := range strings.Split(m[2], ".") {
+		v.release = append(v.release, parseUint(part))
+	}
+
+	switch m[3] {
+	case "a", "alpha":
+		v.preKind = "a"
+	case "b", "beta":
+		v.preKind = "b"
+	case "c", "rc", "pre", "preview":
+		v.preKind = "rc"
+	}
+	v.pre = parseUint(m[4])
+
+	if m[5] != "" {
+		v.hasPost, v.post = true, parseUint(m[5])
+	} else if m[6] != "" {
+		v.hasPost, v.post = true, parseUint(m[7])
+	}
+
+	if m[8] != "" {
+		v.hasDev, v.dev = true, parseUint(m[9])
+	}
+
+	if m[10] != "" {
+		v.local = strings.FieldsFunc(m[10], func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	}
+	return v, true
+}
+
+// sortKey is a (rank, number) pair; ranks place absent components before
+// or after present ones.
+type sortKey [2]uint64
+
+// preKey orders pre-releases: a bare development release sorts before any
+// pre-release, and a version without a pre-release sorts after them.
+func (v pep440Version) preKey() sortKey {
+	switch {
+	case v.preKind == "" && !v.hasPost && v.hasDev:
+		return sortKey{0, 0}
+	case v.preKind == "a":
+		return sortKey{1, v.pre}
+	case v.preKind == "b":
+		return sortKey{2, v.pre}
+	case v.preKind == "rc":
+		return sortKey{3, v.pre}
+	default:
+		return sortKey{4, 0}
+	}
+}
+
+// postKey orders post-releases after the release they follow.
+func (v pep440Version) postKey() sortKey {
+	if !v.hasPost {
+		return sortKey{0, 0}
+	}
+	return sortKey{1, v.post}
+}
+
+// devKey orders development releases before the release they precede.
+func (v pep440Version) devKey() sortKey {
+	if !v.hasDev {
+		return sortKey{1, 0}
+	}
+	return sortKey{0, v.dev}
+}
+
+// compareKeys compares two sort keys.
+func compareKeys(a, b sortKey) int {
+	if c := compareInts(a[0], b[0]); c != 0 {
+		return c
+	}
+	return compareInts(a[1], b[1])
+}
+
+// compareRelease compares release segments, ignoring trailing zeros.
+func compareRelease(a, b []uint64) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var an, bn uint64
+		if i < len(a) {
+			an = a[i]
+		}
+		if i < len(b) {
+			bn = b[i]
+		}
+		if c := compareInts(an, bn); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareLocal compares local version labels. A version without a label
+// sorts first; numeric segments sort after alphanumeric ones, and a longer
+// label sorts after its prefix.
+func compareLocal(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.ParseUint(a[i], 10, 64)
+		bn, bErr := strconv.ParseUint(b[i], 10, 64)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareInts(an, bn)
+		case aErr == nil:
+			c = 1
+		case bErr == nil:
+			c = -1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInts(uint64(len(a)), uint64(len(b)))
+}
+
+// parseUint parses a non-negative integer, treating an empty or invalid value as zero.
+func parseUint(s string) uint64 {
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
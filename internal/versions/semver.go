@@ -0,0 +1,51 @@
+// Package versions provides Semantic Versioning 2.0.0 comparison.
+package versions
+
+import "strings"
+
+// CompareSemver compares two Semantic Versioning 2.0.0 versions, as used by
+// npm, Go modules (including pseudo-versions) and Cargo, returning -1, 0 or 1.
+// A leading "v" or "=" is ignored, missing minor and patch numbers count as
+// zero, and build metadata does not affect precedence.
+func CompareSemver(a, b string) int {
+	aCore, aPre := splitSemver(a)
+	bCore, bPre := splitSemver(b)
+
+	if c := compareSegments(aCore, bCore); c != 0 {
+		return c
+	}
+
+	// A version without pre-release identifiers has higher precedence
+	switch {
+	case aPre == nil && bPre == nil:
+		return 0
+	case aPre == nil:
+		return 1
+	case bPre == nil:
+		return -1
+	}
+
+	// Identifiers compare pairwise; a larger set of otherwise equal
+	// identifiers has higher precedence
+	for i := 0; i < len(aPre) && i < len(bPre); i++ {
+		if c := compareIdentifiers(aPre[i], bPre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(uint64(len(aPre)), uint64(len(bPre)))
+}
+
+// splitSemver splits a version into its major.minor.patch numbers and its
+// pre-release identifiers, which are nil for a release.
+func splitSemver(version string) ([]string, []string) {
+	version = strings.TrimSpace(version)
+	version = strings.TrimLeft(version, "v=")
+	version, _, _ = strings.Cut(version, "+")
+
+	main, pre, hasPre := strings.Cut(version, "-")
+	core := strings.Split(main, ".")
+	if !hasPre {
+		return core, nil
+	}
+	return core, strings.Split(pre, ".")
+}
@@ -0,0 +1,110 @@
+// Package versions compares package versions using the ordering rules of
+// their ecosystem, so that vulnerability ranges such as "< 2.17.1" can be
+// evaluated for npm, PyPI, Maven and Go packages.
+package versions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare compares two versions of a package in the given OSV ecosystem
+// (e.g. "npm", "PyPI", "Maven", "Go", "Debian:12"), returning -1, 0 or 1.
+// Distribution ecosystems are identified by their name without the release.
+// Ecosystems without specific rules use a generic dotted-version ordering.
+func Compare(ecosystem, a, b string) int {
+	name, _, _ := strings.Cut(ecosystem, ":")
+	switch strings.ToLower(name) {
+	case "npm", "go", "crates.io", "nuget", "semver":
+		return CompareSemver(a, b)
+	case "pypi":
+		return ComparePEP440(a, b)
+	case "maven":
+		return CompareMaven(a, b)
+	case "debian", "ubuntu":
+		return CompareDebian(a, b)
+	case "red hat", "rocky linux", "almalinux":
+		return CompareRPM(a, b)
+	case "alpine", "wolfi", "chainguard":
+		return CompareAlpine(a, b)
+	default:
+		return CompareGeneric(a, b)
+	}
+}
+
+// CompareGeneric compares two dotted version strings, returning -1, 0 or 1.
+// Numeric segments compare numerically and other segments lexically; a
+// pre-release ("1.0.0-rc1") sorts before its release ("1.0.0") and build
+// metadata ("+build1") is ignored.
+func CompareGeneric(a, b string) int {
+	a = strings.TrimPrefix(a, "v")
+	b = strings.TrimPrefix(b, "v")
+
+	// Build metadata does not affect precedence
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+
+	aMain, aPre, aHasPre := strings.Cut(a, "-")
+	bMain, bPre, bHasPre := strings.Cut(b, "-")
+
+	if c := compareSegments(strings.Split(aMain, "."), strings.Split(bMain, ".")); c != 0 {
+		return c
+	}
+
+	switch {
+	case aHasPre && !bHasPre:
+		return -1
+	case !aHasPre && bHasPre:
+		return 1
+	case aHasPre && bHasPre:
+		return compareSegments(strings.Split(aPre, "."), strings.Split(bPre, "."))
+	}
+	return 0
+}
+
+// compareSegments compares version segments pairwise; missing segments count as zero.
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		as, bs := "0", "0"
+		if i < len(a) {
+			as = a[i]
+		}
+		if i < len(b) {
+			bs = b[i]
+		}
+		if c := compareIdentifiers(as, bs); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareIdentifiers compares two version identifiers: numerically if both
+// are numeric, lexically if neither is, and otherwise with the numeric one first.
+func compareIdentifiers(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(an, bn)
+	case aErr == nil:
+		// Numeric identifiers sort before alphanumeric ones
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareInts compares two integers, returning -1, 0 or 1.
+func compareInts(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,155 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// versionCase expects Compare(ecosystem, a, b) to return expected.
+type versionCase struct {
+	a, b     string
+	expected int
+}
+
+func runCases(t *testing.T, ecosystem string, cases []versionCase) {
+	t.Helper()
+	for _, tt := range cases {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Compare(ecosystem, tt.a, tt.b))
+			// Comparison is antisymmetric
+			assert.Equal(t, -tt.expected, Compare(ecosystem, tt.b, tt.a))
+		})
+	}
+}
+
+func TestCompareGeneric(t *testing.T) {
+	runCases(t, "Hex", []versionCase{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0+build1", "1.0.0", 0},
+	})
+}
+
+func TestCompareSemver(t *testing.T) {
+	runCases(t, "npm", []versionCase{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"v2.17.1", "2.17.0", 1},
+		{"=1.0.0", "1.0.0", 0},
+		// Pre-release precedence from the SemVer specification
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0+build.5", "1.0.0+build.7", 0},
+	})
+
+	// Go pseudo-versions order by timestamp and precede the release
+	runCases(t, "Go", []versionCase{
+		{"v0.0.0-20210101000000-abcdef123456", "v0.0.0-20220101000000-123456abcdef", -1},
+		{"v1.2.4-0.20210101000000-abcdef123456", "v1.2.4", -1},
+		{"v1.2.4-0.20210101000000-abcdef123456", "v1.2.3", 1},
+	})
+}
+
+func TestComparePEP440(t *testing.T) {
+	runCases(t, "PyPI", []versionCase{
+		{"1.0", "1.0.0", 0},
+		{"2.31.0", "2.4.0", 1},
+		{"1.0.dev1", "1.0a1", -1},
+		{"1.0a1", "1.0a2", -1},
+		{"1.0a2", "1.0b1", -1},
+		{"1.0b1", "1.0rc1", -1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0", "1.0.post1", -1},
+		{"1.0.post1.dev1", "1.0.post1", -1},
+		{"1.0", "1.0+local.1", -1},
+		{"1.0+local.1", "1.0+local.2", -1},
+		{"1!0.1", "2.0", 1},
+		// Alternative spellings normalize to the canonical form
+		{"1.0-alpha1", "1.0a1", 0},
+		{"1.0.c1", "1.0rc1", 0},
+		{"1.0-1", "1.0.post1", 0},
+		{"1.0.0.dev0", "1.0.dev", 0},
+	})
+}
+
+func TestCompareMaven(t *testing.T) {
+	runCases(t, "Maven", []versionCase{
+		{"1.0", "1.0.0", 0},
+		{"1.0-final", "1.0", 0},
+		{"1.0-ga", "1.0", 0},
+		{"2.14.1", "2.17.1", -1},
+		{"2.0-beta9", "2.0", -1},
+		{"2.0-beta9", "2.0-beta10", -1},
+		{"1.0-alpha1", "1.0-beta1", -1},
+		{"1.0-a1", "1.0-alpha1", 0},
+		{"1.0-m1", "1.0-rc1", -1},
+		{"1.0-cr1", "1.0-rc1", 0},
+		{"1.0-rc1", "1.0-SNAPSHOT", -1},
+		{"1.0-SNAPSHOT", "1.0", -1},
+		{"1.0", "1.0-sp1", -1},
+		{"1.0-sp1", "1.0.1", -1},
+		{"1.0-foo", "1.0-sp", 1},
+		{"5.3.9.RELEASE", "5.3.10", -1},
+	})
+}
+
+func TestCompareDebian(t *testing.T) {
+	runCases(t, "Debian:12", []versionCase{
+		{"3.0.11-1~deb12u2", "3.0.11-1~deb12u2", 0},
+		{"3.0.11-1~deb12u2", "3.0.11-1", -1},
+		{"3.0.11-1~deb12u2", "3.0.13-1~deb12u1", -1},
+		{"1.0~rc1", "1.0", -1},
+		{"1:1.0", "2.0", 1},
+		{"2.36-9+deb12u3", "2.36-9+deb12u4", -1},
+		{"2.36-9+deb12u10", "2.36-9+deb12u9", 1},
+		{"1.0a", "1.0+", -1},
+		{"1.0", "1.0-0", 0},
+	})
+	runCases(t, "Ubuntu:22.04:LTS", []versionCase{
+		{"3.0.2-0ubuntu1.10", "3.0.2-0ubuntu1.12", -1},
+	})
+}
+
+func TestCompareRPM(t *testing.T) {
+	runCases(t, "Rocky Linux:9", []versionCase{
+		{"3.0.7-25.el9_3", "3.0.7-25.el9_3", 0},
+		{"3.0.7-24.el9", "3.0.7-25.el9_3", -1},
+		{"1:3.0.7-25.el9_3", "3.0.8-1.el9", 1},
+		{"0:3.0.7-25.el9_3", "3.0.7-25.el9_3", 0},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0^git1", "1.0", 1},
+		{"1.0^git1", "1.0.1", -1},
+		{"1.0a", "1.0", 1},
+		{"1.0.a", "1.0.1", -1},
+		{"2.02", "2.2", 0},
+		// Releases are only compared if both versions have one
+		{"3.0.7", "3.0.7-25.el9_3", 0},
+	})
+}
+
+func TestCompareAlpine(t *testing.T) {
+	runCases(t, "Alpine:v3.19", []versionCase{
+		{"3.1.4-r5", "3.1.4-r5", 0},
+		{"3.1.4-r5", "3.1.4-r6", -1},
+		{"3.1.4-r6", "3.1.5_rc1-r0", -1},
+		{"3.1.5_rc1-r0", "3.1.5-r0", -1},
+		{"3.1.5-r0", "3.1.5_p1-r0", -1},
+		{"1.2", "1.2.1", -1},
+		{"1.2a", "1.2", 1},
+		{"1.2a", "1.2b", -1},
+		{"1.36.1-r15", "1.36.1-r2", 1},
+	})
+}
+
+func TestCompare_UnknownEcosystem(t *testing.T) {
+	assert.Equal(t, -1, Compare("", "1.2.3", "1.10.0"))
+}
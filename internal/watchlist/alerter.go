@@ -0,0 +1,142 @@
+// Package watchlist provides the alerts sent when new security intelligence
+// mentions a watched package that a stored SBOM uses.
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+)
+
+// AgentName identifies watchlist alerts among the findings of notifications.
+const AgentName = "Watchlist"
+
+// Alerter sends a notification for every stored SBOM using a watched
+// package that new security intelligence mentions.
+type Alerter struct {
+	watches storage.Watchlist
+	repo    storage.Repository
+	// notifier returns where alerts are sent, or nil if nowhere; it is
+	// called per alert so that configuration reloads apply
+	notifier func() notify.Notifier
+}
+
+// NewAlerter creates an alerter for the watches and SBOMs of the given
+// repositories.
+func NewAlerter(watches storage.Watchlist, repo storage.Repository, notifier func() notify.Notifier) *Alerter {
+	return &Alerter{watches: watches, repo: repo, notifier: notifier}
+}
+
+// Listen alerts on new intelligence, logging failures. It is meant to be
+// registered with IntelligenceStore.OnNewIntelligence.
+func (a *Alerter) Listen(ctx context.Context, intelligence []vectordb.SecurityIntelligence) {
+	sent, err := a.Alert(ctx, intelligence)
+	if sent > 0 {
+		fmt.Printf("Watchlist: alerted on %d SBOMs\n", sent)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Watchlist alerts failed: %v\n", err)
+	}
+}
+
+// Alert notifies about every stored SBOM with components that are watched
+// packages mentioned in intelligence, with one finding per component and
+// document, and returns the number of notifications sent.
+func (a *Alerter) Alert(ctx context.Context, intelligence []vectordb.SecurityIntelligence) (int, error) {
+	notifier := a.notifier()
+	if notifier == nil || len(intelligence) == 0 {
+		return 0, nil
+	}
+
+	watches, err := a.watches.ListWatches(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Find the watches that are mentioned before loading any SBOM
+	type mentioned struct {
+		matcher   *Matcher
+		documents []vectordb.SecurityIntelligence
+	}
+	var matches []mentioned
+	var errs []error
+	for _, watch := range watches {
+		matcher, err := NewMatcher(watch)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var documents []vectordb.SecurityIntelligence
+		for _, intel := range intelligence {
+			if matcher.Mentions(intel) {
+				documents = append(documents, intel)
+			}
+		}
+		if len(documents) > 0 {
+			matches = append(matches, mentioned{matcher: matcher, documents: documents})
+		}
+	}
+	if len(matches) == 0 {
+		return 0, errors.Join(errs...)
+	}
+
+	sboms, err := a.repo.FindAll(ctx)
+	if err != nil {
+		return 0, errors.Join(append(errs, err)...)
+	}
+
+	sent := 0
+	for _, sbom := range sboms {
+		var results []core.AnalysisResult
+		for _, match := range matches {
+			for _, component := range match.matcher.Components(sbom) {
+				for _, intel := range match.documents {
+					results = append(results, finding(component, intel))
+				}
+			}
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		notification := notify.Notification{SBOMID: sbom.ID, SBOMName: sbom.Name, Results: results}
+		if err := notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("SBOM %s: %w", sbom.ID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// finding describes a watched component mentioned in a document. Documents
+// without a known severity are reported as medium.
+func finding(component core.Component, intel vectordb.SecurityIntelligence) core.AnalysisResult {
+	severity := intel.Severity
+	if core.SeverityRank(severity) == 0 {
+		severity = core.SeverityMedium
+	}
+	label := component.Name
+	if component.Version != "" {
+		label += "@" + component.Version
+	}
+
+	result := core.AnalysisResult{
+		AgentName: AgentName,
+		Finding:   fmt.Sprintf("Watched package %s is mentioned in new security intelligence: %s", label, intel.Title),
+		Severity:  severity,
+		Citations: []core.Citation{{ID: intel.ID, Title: intel.Title, Source: intel.Source, URL: intel.URL}},
+		Component: &core.ComponentRef{Name: component.Name, Version: component.Version, PURL: component.PURL, Scope: component.Scope},
+	}
+	if strings.HasPrefix(intel.Source, osvSourcePrefix) {
+		// Advisories affecting several packages have one document per package
+		result.VulnerabilityID, _, _ = strings.Cut(intel.ID, "/")
+	}
+	return result
+}
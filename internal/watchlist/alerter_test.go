@@ -0,0 +1,91 @@
+package watchlist
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/notify"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier records the notifications it is sent.
+type recordingNotifier struct {
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestAlerter_Alert(t *testing.T) {
+	ctx := context.Background()
+	repo, err := database.NewSQLiteRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "api", Name: "api", Components: []core.Component{
+		{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20"},
+	}}))
+	require.NoError(t, repo.Store(ctx, core.SBOM{ID: "web", Name: "web", Components: []core.Component{
+		{Name: "react", Version: "18.2.0", PURL: "pkg:npm/react@18.2.0"},
+	}}))
+
+	watch, err := NewWatch("pkg:npm/lodash", "")
+	require.NoError(t, err)
+	stored, err := repo.AddWatch(ctx, watch)
+	require.NoError(t, err)
+	assert.Equal(t, watch.ID, stored.ID)
+
+	// Watching the package again keeps the existing watch
+	again, err := NewWatch("pkg:npm/lodash", "")
+	require.NoError(t, err)
+	stored, err = repo.AddWatch(ctx, again)
+	require.NoError(t, err)
+	assert.Equal(t, watch.ID, stored.ID)
+
+	notifier := &recordingNotifier{}
+	alerter := NewAlerter(repo, repo, func() notify.Notifier { return notifier })
+
+	sent, err := alerter.Alert(ctx, []vectordb.SecurityIntelligence{
+		{ID: "GHSA-35jh-r3h4-6jhm/lodash", Title: "Command injection in lodash", Component: "lodash", Severity: "High", Source: "OSV npm"},
+		{ID: "GHSA-unrelated", Title: "Flaw in express", Component: "express", Source: "OSV npm"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, notifier.notifications, 1)
+
+	notification := notifier.notifications[0]
+	assert.Equal(t, "api", notification.SBOMID)
+	require.Len(t, notification.Results, 1)
+	result := notification.Results[0]
+	assert.Equal(t, AgentName, result.AgentName)
+	assert.Equal(t, "High", result.Severity)
+	assert.Equal(t, "GHSA-35jh-r3h4-6jhm", result.VulnerabilityID)
+	require.NotNil(t, result.Component)
+	assert.Equal(t, "pkg:npm/lodash@4.17.20", result.Component.PURL)
+
+	// Removed watches no longer alert
+	deleted, err := repo.DeleteWatch(ctx, watch.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	sent, err = alerter.Alert(ctx, []vectordb.SecurityIntelligence{{ID: "GHSA-2", Component: "lodash", Source: "OSV npm"}})
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	deleted, err = repo.DeleteWatch(ctx, watch.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestAlerter_WithoutNotifier(t *testing.T) {
+	alerter := NewAlerter(nil, nil, func() notify.Notifier { return nil })
+	sent, err := alerter.Alert(context.Background(), []vectordb.SecurityIntelligence{{ID: "GHSA-1"}})
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+}
@@ -0,0 +1,155 @@
+// Package watchlist provides watched packages, identified by Package URL,
+// and the matching of security intelligence and SBOM components against
+// them.
+package watchlist
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/hueyexe/SBOM-Sentinel/internal/purl"
+)
+
+// ErrInvalidWatch is returned for watches whose package URL cannot be parsed.
+var ErrInvalidWatch = errors.New("invalid watch")
+
+// osvSourcePrefix starts the source of documents harvested from OSV dumps,
+// which is followed by the OSV ecosystem.
+const osvSourcePrefix = "OSV "
+
+// NewWatch creates a watch of the package with the given Package URL, e.g.
+// "pkg:npm/lodash" or, to watch a single version, "pkg:npm/lodash@4.17.20".
+// Qualifiers and subpaths are dropped, as they do not identify a package.
+func NewWatch(packageURL, note string) (storage.Watch, error) {
+	parsed, err := purl.Parse(strings.TrimSpace(packageURL))
+	if err != nil {
+		return storage.Watch{}, fmt.Errorf("%w: %v", ErrInvalidWatch, err)
+	}
+	parsed.Qualifiers = nil
+	parsed.Subpath = ""
+
+	return storage.Watch{
+		ID:        core.NewSBOMID(),
+		PURL:      parsed.String(),
+		Note:      strings.TrimSpace(note),
+		CreatedAt: core.Now().UTC(),
+	}, nil
+}
+
+// Matcher matches security intelligence and SBOM components against a watch.
+type Matcher struct {
+	Watch storage.Watch
+
+	purl purl.PackageURL
+	// mention matches the package name in free text
+	mention *regexp.Regexp
+}
+
+// NewMatcher creates a matcher for a stored watch.
+func NewMatcher(watch storage.Watch) (*Matcher, error) {
+	parsed, err := purl.Parse(watch.PURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWatch, err)
+	}
+
+	// Names are delimited by anything that cannot be part of a package
+	// name; a trailing dot ends a sentence
+	name := regexp.QuoteMeta(mentionName(parsed))
+	mention := regexp.MustCompile(`(?i)(^|[^a-z0-9_.@/-])` + name + `($|[^a-z0-9_/-])`)
+
+	return &Matcher{Watch: watch, purl: parsed, mention: mention}, nil
+}
+
+// Mentions reports whether a security intelligence document is about the
+// watched package. Documents naming a component, such as OSV advisories,
+// match by package name and ecosystem; others, such as feed entries, match
+// if their title or description mentions the package name.
+func (m *Matcher) Mentions(intelligence vectordb.SecurityIntelligence) bool {
+	if intelligence.Component == "" {
+		return m.mention.MatchString(intelligence.Title) || m.mention.MatchString(intelligence.Description)
+	}
+
+	if ecosystem, ok := strings.CutPrefix(intelligence.Source, osvSourcePrefix); ok {
+		return strings.EqualFold(ecosystem, osvEcosystem(m.purl.Type)) &&
+			strings.EqualFold(intelligence.Component, osvName(m.purl))
+	}
+	// Manually added documents may name the package either way
+	return strings.EqualFold(intelligence.Component, osvName(m.purl)) ||
+		strings.EqualFold(intelligence.Component, m.purl.FullName()) ||
+		strings.EqualFold(intelligence.Component, m.purl.Name)
+}
+
+// Components returns the components of sbom that are the watched package,
+// and of the watched version if the watch has one.
+func (m *Matcher) Components(sbom core.SBOM) []core.Component {
+	var matched []core.Component
+	for _, component := range sbom.Components {
+		if component.PURL == "" {
+			continue
+		}
+		parsed, err := purl.Parse(component.PURL)
+		if err != nil {
+			continue
+		}
+		if parsed.Type != m.purl.Type || parsed.Namespace != m.purl.Namespace || parsed.Name != m.purl.Name {
+			continue
+		}
+		if m.purl.Version != "" && parsed.Version != m.purl.Version {
+			continue
+		}
+		matched = append(matched, component)
+	}
+	return matched
+}
+
+// osvName returns the name OSV gives the package.
+func osvName(p purl.PackageURL) string {
+	if p.Type == "maven" {
+		return p.Namespace + ":" + p.Name
+	}
+	return p.FullName()
+}
+
+// mentionName returns the name the package is mentioned by in free text.
+func mentionName(p purl.PackageURL) string {
+	switch p.Type {
+	case "npm", "golang":
+		// Scoped npm packages and Go modules are known by their full name
+		return p.FullName()
+	default:
+		return p.Name
+	}
+}
+
+// osvEcosystem maps a PURL type to its OSV ecosystem.
+func osvEcosystem(purlType string) string {
+	switch purlType {
+	case "npm":
+		return "npm"
+	case "pypi":
+		return "PyPI"
+	case "maven":
+		return "Maven"
+	case "cargo":
+		return "crates.io"
+	case "golang":
+		return "Go"
+	case "nuget":
+		return "NuGet"
+	case "composer":
+		return "Packagist"
+	case "gem":
+		return "RubyGems"
+	case "hex":
+		return "Hex"
+	case "pub":
+		return "Pub"
+	default:
+		return purlType
+	}
+}
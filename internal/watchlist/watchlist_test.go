@@ -0,0 +1,85 @@
+package watchlist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func matcher(t *testing.T, packageURL string) *Matcher {
+	t.Helper()
+	watch, err := NewWatch(packageURL, "")
+	require.NoError(t, err)
+	m, err := NewMatcher(watch)
+	require.NoError(t, err)
+	return m
+}
+
+func TestNewWatch(t *testing.T) {
+	watch, err := NewWatch(" pkg:npm/Lodash@4.17.20?arch=any#lib ", " used everywhere ")
+	require.NoError(t, err)
+	assert.Equal(t, "pkg:npm/lodash@4.17.20", watch.PURL)
+	assert.Equal(t, "used everywhere", watch.Note)
+	assert.NotEmpty(t, watch.ID)
+	assert.False(t, watch.CreatedAt.IsZero())
+
+	// Watches take their ID and creation time from the process defaults
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	defer core.SetClock(core.NewFixedClock(created))()
+	defer core.SetIDGenerator(&core.SequentialIDs{})()
+	watch, err = NewWatch("pkg:npm/lodash", "")
+	require.NoError(t, err)
+	assert.Equal(t, "00000000-0000-4000-8000-000000000001", watch.ID)
+	assert.Equal(t, created, watch.CreatedAt)
+
+	_, err = NewWatch("lodash", "")
+	assert.True(t, errors.Is(err, ErrInvalidWatch))
+}
+
+func TestMatcher_Mentions(t *testing.T) {
+	tests := []struct {
+		name         string
+		purl         string
+		intelligence vectordb.SecurityIntelligence
+		want         bool
+	}{
+		{"OSV package", "pkg:npm/lodash", vectordb.SecurityIntelligence{Component: "lodash", Source: "OSV npm"}, true},
+		{"OSV other ecosystem", "pkg:npm/lodash", vectordb.SecurityIntelligence{Component: "lodash", Source: "OSV PyPI"}, false},
+		{"OSV other package", "pkg:npm/lodash", vectordb.SecurityIntelligence{Component: "lodash-es", Source: "OSV npm"}, false},
+		{"OSV Maven package", "pkg:maven/org.apache.logging.log4j/log4j-core", vectordb.SecurityIntelligence{Component: "org.apache.logging.log4j:log4j-core", Source: "OSV Maven"}, true},
+		{"OSV scoped package", "pkg:npm/%40babel/core", vectordb.SecurityIntelligence{Component: "@babel/core", Source: "OSV npm"}, true},
+		{"manual document", "pkg:maven/org.apache.logging.log4j/log4j-core", vectordb.SecurityIntelligence{Component: "log4j-core", Source: "internal"}, true},
+		{"feed title", "pkg:npm/lodash", vectordb.SecurityIntelligence{Title: "Prototype pollution in Lodash."}, true},
+		{"feed description", "pkg:npm/lodash", vectordb.SecurityIntelligence{Title: "Advisory", Description: "Affects lodash before 4.17.21"}, true},
+		{"feed other package", "pkg:npm/lodash", vectordb.SecurityIntelligence{Title: "Prototype pollution in lodash-es"}, false},
+		{"feed unrelated", "pkg:npm/lodash", vectordb.SecurityIntelligence{Title: "Flaw in OpenSSL"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matcher(t, tt.purl).Mentions(tt.intelligence))
+		})
+	}
+}
+
+func TestMatcher_Components(t *testing.T) {
+	sbom := core.SBOM{Components: []core.Component{
+		{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20"},
+		{Name: "lodash", Version: "4.17.21", PURL: "pkg:npm/lodash@4.17.21"},
+		{Name: "lodash-es", Version: "4.17.21", PURL: "pkg:npm/lodash-es@4.17.21"},
+		{Name: "lodash", Version: "1.0.0"},
+	}}
+
+	assert.Len(t, matcher(t, "pkg:npm/lodash").Components(sbom), 2)
+
+	matched := matcher(t, "pkg:npm/lodash@4.17.20").Components(sbom)
+	require.Len(t, matched, 1)
+	assert.Equal(t, "4.17.20", matched[0].Version)
+
+	assert.Empty(t, matcher(t, "pkg:pypi/lodash").Components(sbom))
+}
@@ -0,0 +1,171 @@
+// Package wiring provides the constructors shared by the SBOM Sentinel server
+// and CLI, so that both binaries open storage and assemble analysis agents
+// the same way.
+package wiring
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/core"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/database"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/vectordb"
+	"github.com/hueyexe/SBOM-Sentinel/internal/plugin"
+	"github.com/hueyexe/SBOM-Sentinel/internal/rules"
+)
+
+// DefaultDatabasePath is the SQLite database used when none is configured.
+const DefaultDatabasePath = "./sentinel.db"
+
+// DatabasePath returns path if it is set, otherwise $DATABASE_PATH or
+// DefaultDatabasePath.
+func DatabasePath(path string) string {
+	if path == "" {
+		path = os.Getenv("DATABASE_PATH")
+	}
+	if path == "" {
+		path = DefaultDatabasePath
+	}
+	return path
+}
+
+// OpenRepository opens the SQLite SBOM repository at path, creating it if
+// needed, with the database settings of the default configuration.
+func OpenRepository(path string) (*database.SQLiteRepository, error) {
+	repo, err := database.NewSQLiteRepositoryWithOptions(path, config.Default().Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database '%s': %w", path, err)
+	}
+	return repo, nil
+}
+
+// AgentSelection describes the analysis agents to run. The license agent
+// always runs; the others are opt-in.
+type AgentSelection struct {
+	// RequireLicense fails the whole run if license analysis fails, instead
+	// of reporting it as a failed agent
+	RequireLicense bool
+	// LicenseIgnoreScopes overrides the component scopes skipped by license
+	// analysis; nil keeps the defaults
+	LicenseIgnoreScopes []string
+	// Projects declares project license contexts by SBOM name, and
+	// ProjectOverride replaces the declared license or distribution model
+	// where set; license findings are rated in this context
+	Projects        map[string]core.ProjectContext
+	ProjectOverride core.ProjectContext
+
+	AIHealthCheck bool
+	ProactiveScan bool
+	VulnScan      bool
+	QualityCheck  bool
+	CryptoCheck   bool
+	ExportCheck   bool
+
+	// ExportRules are applied by the export control agent in addition to
+	// its built-in rules
+	ExportRules []analysis.ExportRule
+
+	// BaseImageCheck checks the base image of container image SBOMs, and
+	// VulnerableBaseImages are the base images it reports as known vulnerable
+	BaseImageCheck       bool
+	VulnerableBaseImages []analysis.VulnerableImage
+
+	// RepoActivityCheck checks the activity of the source repositories of
+	// components on GitHub and GitLab
+	RepoActivityCheck bool
+
+	// Intelligence is the corpus searched by the proactive scan; if nil the
+	// agent builds a private one
+	Intelligence *vectordb.IntelligenceStore
+	// ConfigureProactive, if set, adjusts the proactive agent before it is
+	// added, e.g. to apply retrieval settings
+	ConfigureProactive func(*analysis.ProactiveVulnerabilityAgent)
+
+	// Plugins are third-party agents, which run in every analysis
+	Plugins []*plugin.ProxyAgent
+	// Rules are custom WebAssembly rules, which analyze the SBOMs of the
+	// projects they apply to
+	Rules []*plugin.WasmAgent
+	// RuleEngine, if set, reports components matching the expressions of
+	// a rules file
+	RuleEngine *rules.Agent
+}
+
+// ProfileSelection returns a selection running the agents enabled by profile.
+func ProfileSelection(profile config.Profile) AgentSelection {
+	return AgentSelection{
+		AIHealthCheck: profile.AIHealthCheck,
+		ProactiveScan: profile.ProactiveScan,
+		VulnScan:      profile.VulnScan,
+		QualityCheck:  profile.QualityCheck,
+		CryptoCheck:   profile.CryptoCheck,
+		ExportCheck:   profile.ExportCheck,
+
+		BaseImageCheck:    profile.BaseImageCheck,
+		RepoActivityCheck: profile.RepoActivityCheck,
+	}
+}
+
+// NewOrchestrator builds an orchestrator running the selected agents with the given time limits.
+func NewOrchestrator(timeouts analysis.AgentTimeouts, selection AgentSelection) *analysis.Orchestrator {
+	orchestrator := analysis.NewOrchestrator(timeouts)
+
+	licenseAgent := analysis.NewLicenseAgent()
+	if selection.LicenseIgnoreScopes != nil {
+		licenseAgent.SetIgnoredScopes(selection.LicenseIgnoreScopes)
+	}
+	licenseAgent.SetProjects(selection.Projects)
+	licenseAgent.SetProjectOverride(selection.ProjectOverride)
+	if selection.RequireLicense {
+		orchestrator.AddRequired(licenseAgent)
+	} else {
+		orchestrator.Add(licenseAgent)
+	}
+
+	if selection.AIHealthCheck {
+		orchestrator.Add(analysis.NewDependencyHealthAgent())
+	}
+	if selection.ProactiveScan {
+		var proactiveAgent *analysis.ProactiveVulnerabilityAgent
+		if selection.Intelligence != nil {
+			proactiveAgent = analysis.NewProactiveVulnerabilityAgentWithStore(selection.Intelligence)
+		} else {
+			proactiveAgent = analysis.NewProactiveVulnerabilityAgent()
+		}
+		if selection.ConfigureProactive != nil {
+			selection.ConfigureProactive(proactiveAgent)
+		}
+		orchestrator.Add(proactiveAgent)
+	}
+	if selection.VulnScan {
+		orchestrator.Add(analysis.NewVulnerabilityScanningAgent())
+	}
+	if selection.QualityCheck {
+		orchestrator.Add(analysis.NewQualityAgent())
+	}
+	if selection.CryptoCheck {
+		orchestrator.Add(analysis.NewCryptoAgent())
+	}
+	if selection.ExportCheck {
+		orchestrator.Add(analysis.NewExportControlAgent(selection.ExportRules...))
+	}
+	if selection.BaseImageCheck {
+		orchestrator.Add(analysis.NewBaseImageAgent(selection.VulnerableBaseImages...))
+	}
+	if selection.RepoActivityCheck {
+		orchestrator.Add(analysis.NewRepositoryActivityAgent())
+	}
+	for _, agent := range selection.Plugins {
+		orchestrator.Add(agent)
+	}
+	for _, agent := range selection.Rules {
+		orchestrator.Add(agent)
+	}
+	if selection.RuleEngine != nil {
+		orchestrator.Add(selection.RuleEngine)
+	}
+
+	return orchestrator
+}
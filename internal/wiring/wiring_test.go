@@ -0,0 +1,47 @@
+package wiring
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/analysis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabasePath(t *testing.T) {
+	t.Setenv("DATABASE_PATH", "")
+	assert.Equal(t, DefaultDatabasePath, DatabasePath(""))
+
+	t.Setenv("DATABASE_PATH", "/var/lib/sentinel.db")
+	assert.Equal(t, "/var/lib/sentinel.db", DatabasePath(""))
+	assert.Equal(t, "custom.db", DatabasePath("custom.db"))
+}
+
+func TestOpenRepository(t *testing.T) {
+	repo, err := OpenRepository(filepath.Join(t.TempDir(), "sentinel.db"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	_, err = OpenRepository(filepath.Join(t.TempDir(), "missing", "sentinel.db"))
+	assert.ErrorContains(t, err, "failed to open database")
+}
+
+func TestNewOrchestrator(t *testing.T) {
+	orchestrator := NewOrchestrator(analysis.NewAgentTimeouts(), AgentSelection{})
+	require.Len(t, orchestrator.Agents(), 1)
+	assert.Equal(t, analysis.NewLicenseAgent().Name(), orchestrator.Agents()[0].Name())
+
+	configured := false
+	orchestrator = NewOrchestrator(analysis.NewAgentTimeouts(), AgentSelection{
+		ProactiveScan: true,
+		VulnScan:      true,
+		QualityCheck:  true,
+		ConfigureProactive: func(agent *analysis.ProactiveVulnerabilityAgent) {
+			agent.SetTopK(7)
+			configured = true
+		},
+	})
+	assert.Len(t, orchestrator.Agents(), 4)
+	assert.True(t, configured)
+}
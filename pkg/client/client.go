@@ -0,0 +1,697 @@
+// Package client provides a Go client for the SBOM Sentinel REST API, so
+// that services can submit, retrieve and analyze SBOMs without writing HTTP
+// requests by hand.
+//
+// Responses are decoded into the wire types of this package, which mirror
+// the JSON of the API and do not depend on the server's packages. Requests
+// failing with a network error or a 429, 502, 503 or 504 status are retried
+// with backoff. Submissions and analyses carry an Idempotency-Key, so a
+// retry never stores an SBOM or queues an analysis twice. Admins can also
+// manage the waivers of the server's configuration file and rotate API keys.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+)
+
+// DefaultTimeout bounds a request made by a client without WithHTTPClient,
+// including its retries. Analyses with LLM agents can take minutes.
+const DefaultTimeout = 10 * time.Minute
+
+// DefaultPollInterval is how often WaitForJob checks a job by default.
+const DefaultPollInterval = 2 * time.Second
+
+// idempotencyKeyHeader carries the idempotency key of a submission or
+// analysis.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// APIError is returned for requests the API rejected.
+type APIError struct {
+	StatusCode int
+	// Code is the error type, such as "not_found" or "validation_error"
+	Code    string
+	Message string
+	// Violations lists every schema violation of a rejected SBOM
+	Violations []Violation
+	// ExistingID is the ID of the stored SBOM a submission conflicts with
+	ExistingID string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("SBOM Sentinel API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("SBOM Sentinel API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Client calls the REST API of an SBOM Sentinel server. It is safe for
+// concurrent use.
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey authenticates requests with an API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithHTTPClient sends requests with httpClient instead of a client that
+// retries transient failures.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// New creates a client for the server at baseURL, such as
+// "http://localhost:8080".
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: httpclient.NewTransport(nil, httpclient.DefaultConfig()),
+		},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// SubmitOptions controls how a submitted SBOM is stored.
+type SubmitOptions struct {
+	// Strict also validates the SBOM against the full JSON Schema of its format
+	Strict bool
+	// Force stores the SBOM even if an identical document is stored
+	Force bool
+	// Replace replaces the stored SBOM with the same serial number
+	Replace bool
+	// Tags label the stored SBOM, such as {"env": "prod"}; a replacement
+	// without tags keeps those of the SBOM it replaces
+	Tags map[string]string
+	// IdempotencyKey identifies the submission across retries, including
+	// retries by the caller; a random key is used if it is empty
+	IdempotencyKey string
+}
+
+// Submit stores an SBOM document, named filename, and returns its ID.
+func (c *Client) Submit(ctx context.Context, filename string, document []byte, options SubmitOptions) (*SubmitResponse, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("sbom", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	part.Write(document)
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	query := url.Values{}
+	if options.Strict {
+		query.Set("validate", "strict")
+	}
+	if options.Force {
+		query.Set("force", "true")
+	}
+	if options.Replace {
+		query.Set("replace", "true")
+	}
+	setTags(query, options.Tags)
+
+	var response SubmitResponse
+	header := http.Header{"Content-Type": {writer.FormDataContentType()}}
+	setIdempotencyKey(header, options.IdempotencyKey)
+	if err := c.do(ctx, http.MethodPost, "/api/v1/sboms", query, header, body.Bytes(), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Get returns the stored SBOM with the given ID, or nil if there is none.
+func (c *Client) Get(ctx context.Context, id string) (*SBOM, error) {
+	var sbom SBOM
+	err := c.do(ctx, http.MethodGet, "/api/v1/sboms/"+url.PathEscape(id), nil, nil, nil, &sbom)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sbom, nil
+}
+
+// DeleteSBOM deletes the stored SBOM with the given ID, reporting whether
+// it existed. It requires the admin role.
+func (c *Client) DeleteSBOM(ctx context.Context, id string) (bool, error) {
+	err := c.do(ctx, http.MethodDelete, "/api/v1/sboms/"+url.PathEscape(id), nil, nil, nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List returns the stored SBOMs, oldest first, or the versions of the
+// project name if it is not empty.
+func (c *Client) List(ctx context.Context, name string) ([]SBOMRecord, error) {
+	return c.ListMatching(ctx, ListOptions{Name: name})
+}
+
+// ListOptions selects the SBOMs listed.
+type ListOptions struct {
+	// Name lists the versions of one project
+	Name string
+	// Tags lists the SBOMs with every tag, their own or their project's
+	Tags map[string]string
+}
+
+// ListMatching returns the stored SBOMs selected by options, oldest first.
+func (c *Client) ListMatching(ctx context.Context, options ListOptions) ([]SBOMRecord, error) {
+	query := url.Values{}
+	if options.Name != "" {
+		query.Set("name", options.Name)
+	}
+	setTags(query, options.Tags)
+	var response sbomListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/sboms", query, nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.SBOMs, nil
+}
+
+// Tags returns the tags of the stored SBOM with the given ID, or nil if
+// there is none.
+func (c *Client) Tags(ctx context.Context, id string) (*TagsResponse, error) {
+	var response TagsResponse
+	err := c.do(ctx, http.MethodGet, "/api/v1/sboms/"+url.PathEscape(id)+"/tags", nil, nil, nil, &response)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// SetTags replaces the tags of the stored SBOM with the given ID.
+func (c *Client) SetTags(ctx context.Context, id string, tags map[string]string) (*TagsResponse, error) {
+	changes := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		changes[key] = &value
+	}
+	return c.changeTags(ctx, http.MethodPut, id, changes)
+}
+
+// UpdateTags adds or changes the tags set and removes the tags remove of
+// the stored SBOM with the given ID, keeping its other tags.
+func (c *Client) UpdateTags(ctx context.Context, id string, set map[string]string, remove []string) (*TagsResponse, error) {
+	changes := make(map[string]*string, len(set)+len(remove))
+	for _, key := range remove {
+		changes[key] = nil
+	}
+	for key, value := range set {
+		changes[key] = &value
+	}
+	return c.changeTags(ctx, http.MethodPatch, id, changes)
+}
+
+// changeTags sends the tags of an SBOM with a PUT or PATCH request.
+func (c *Client) changeTags(ctx context.Context, method, id string, changes map[string]*string) (*TagsResponse, error) {
+	body, err := json.Marshal(tagsRequest{Tags: changes})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	var response TagsResponse
+	header := http.Header{"Content-Type": {"application/json"}}
+	if err := c.do(ctx, method, "/api/v1/sboms/"+url.PathEscape(id)+"/tags", nil, header, body, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// setTags adds tags to query as tag=key=value parameters, in key order.
+func setTags(query url.Values, tags map[string]string) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		query.Add("tag", key+"="+tags[key])
+	}
+}
+
+// AnalyzeOptions controls an analysis.
+type AnalyzeOptions struct {
+	// Profile is a named analysis profile, such as "quick" or "full"
+	Profile string
+	// MinSeverity and MinConfidence leave less severe, or less confident,
+	// findings out of the results
+	MinSeverity   string
+	MinConfidence float64
+	// Parameters are further query parameters, such as enable-vuln-scan=true
+	Parameters url.Values
+	// Priority is PriorityInteractive or PriorityScheduled; it
+	// only applies to AnalyzeAsync
+	Priority string
+	// IdempotencyKey identifies the analysis across retries; a random key
+	// is used if it is empty
+	IdempotencyKey string
+}
+
+// query returns the query parameters of the analysis.
+func (o AnalyzeOptions) query() url.Values {
+	query := url.Values{}
+	for name, values := range o.Parameters {
+		query[name] = append([]string(nil), values...)
+	}
+	if o.Profile != "" {
+		query.Set("profile", o.Profile)
+	}
+	if o.MinSeverity != "" {
+		query.Set("min_severity", o.MinSeverity)
+	}
+	if o.MinConfidence > 0 {
+		query.Set("min_confidence", strconv.FormatFloat(o.MinConfidence, 'f', -1, 64))
+	}
+	return query
+}
+
+// Analyze analyzes the stored SBOM with the given ID and returns the
+// analysis once it is done.
+func (c *Client) Analyze(ctx context.Context, id string, options AnalyzeOptions) (*AnalysisResponse, error) {
+	header := http.Header{}
+	setIdempotencyKey(header, options.IdempotencyKey)
+	var response AnalysisResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/sboms/"+url.PathEscape(id)+"/analyze", options.query(), header, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// AnalyzeAsync queues an analysis of the stored SBOM with the given ID and
+// returns the queued job, whose progress Job and WaitForJob report.
+func (c *Client) AnalyzeAsync(ctx context.Context, id string, options AnalyzeOptions) (*Job, error) {
+	query := options.query()
+	query.Set("async", "true")
+	if options.Priority != "" {
+		query.Set("priority", options.Priority)
+	}
+	header := http.Header{}
+	setIdempotencyKey(header, options.IdempotencyKey)
+
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/api/v1/sboms/"+url.PathEscape(id)+"/analyze", query, header, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Job returns the analysis job with the given ID, or nil if there is none.
+func (c *Client) Job(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := c.do(ctx, http.MethodGet, "/api/v1/jobs/"+url.PathEscape(id), nil, nil, nil, &job)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelJob cancels a queued or running analysis job and returns it, or nil
+// if there is none. Canceling a finished job fails with a 409 APIError.
+func (c *Client) CancelJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := c.do(ctx, http.MethodDelete, "/api/v1/jobs/"+url.PathEscape(id), nil, nil, nil, &job)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitForJob checks the job with the given ID every pollInterval, or
+// DefaultPollInterval if it is zero, until it finished or ctx is done.
+func (c *Client) WaitForJob(ctx context.Context, id string, pollInterval time.Duration) (*Job, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, fmt.Errorf("job %s not found", id)
+		}
+		if job.Finished() {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// JobResult returns the analysis of a succeeded job.
+func JobResult(job *Job) (*AnalysisResponse, error) {
+	if job.Status != StatusSucceeded {
+		if job.Error != "" {
+			return nil, fmt.Errorf("job %s %s: %s", job.ID, job.Status, job.Error)
+		}
+		return nil, fmt.Errorf("job %s %s", job.ID, job.Status)
+	}
+	var response AnalysisResponse
+	if err := json.Unmarshal(job.Result, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode job result: %w", err)
+	}
+	return &response, nil
+}
+
+// ListWatches returns the watched packages.
+func (c *Client) ListWatches(ctx context.Context) ([]Watch, error) {
+	var response watchlistResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/watchlist", nil, nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Watches, nil
+}
+
+// AddWatch watches a package by Package URL, or returns the existing watch
+// of the package.
+func (c *Client) AddWatch(ctx context.Context, purl, note string) (*Watch, error) {
+	body, err := json.Marshal(watchRequest{PURL: purl, Note: note})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	var watch Watch
+	header := http.Header{"Content-Type": {"application/json"}}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/watchlist", nil, header, body, &watch); err != nil {
+		return nil, err
+	}
+	return &watch, nil
+}
+
+// DeleteWatch stops watching a package, reporting whether the watch existed.
+func (c *Client) DeleteWatch(ctx context.Context, id string) (bool, error) {
+	err := c.do(ctx, http.MethodDelete, "/api/v1/watchlist/"+url.PathEscape(id), nil, nil, nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Owners returns the teams owning components, from the owners section of
+// the server's configuration file.
+func (c *Client) Owners(ctx context.Context) ([]TeamOwnership, error) {
+	var response ownersResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/owners", nil, nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Teams, nil
+}
+
+// FindingOptions selects the tracked findings listed with a project's
+// finding lifecycle. Counts are never filtered.
+type FindingOptions struct {
+	// Status is FindingOpen or FindingResolved
+	Status string
+	// Triage is TriageNew, TriageAcknowledged, TriageInProgress, TriageResolved or
+	// TriageFalsePositive
+	Triage   string
+	Assignee string
+	// Overdue lists only the open findings past the due date set by the
+	// SLA of their severity
+	Overdue bool
+}
+
+// ProjectFindings returns the finding lifecycle of a project, with the
+// tracked findings selected by options, or nil if no analysis of the
+// project was recorded.
+func (c *Client) ProjectFindings(ctx context.Context, project string, options FindingOptions) (*Timeline, error) {
+	query := url.Values{}
+	if options.Status != "" {
+		query.Set("status", options.Status)
+	}
+	if options.Triage != "" {
+		query.Set("triage", options.Triage)
+	}
+	if options.Assignee != "" {
+		query.Set("assignee", options.Assignee)
+	}
+	if options.Overdue {
+		query.Set("overdue", "true")
+	}
+	var timeline Timeline
+	err := c.do(ctx, http.MethodGet, "/api/v1/projects/"+url.PathEscape(project)+"/findings", query, nil, nil, &timeline)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &timeline, nil
+}
+
+// TriageFinding changes the triage state, assignee or note of a tracked
+// finding of a project, leaving the fields triage omits unchanged.
+func (c *Client) TriageFinding(ctx context.Context, project, fingerprint string, triage TriageRequest) (*FindingRecord, error) {
+	var finding FindingRecord
+	path := "/api/v1/projects/" + url.PathEscape(project) + "/findings/" + url.PathEscape(fingerprint)
+	if err := c.triage(ctx, path, triage, &finding); err != nil {
+		return nil, err
+	}
+	return &finding, nil
+}
+
+// TriageFindings changes the triage of the findings of a project listed by
+// triage.Fingerprints. If any of them is unknown, none is changed.
+func (c *Client) TriageFindings(ctx context.Context, project string, triage TriageRequest) ([]FindingRecord, error) {
+	var response triageResponse
+	if err := c.triage(ctx, "/api/v1/projects/"+url.PathEscape(project)+"/findings", triage, &response); err != nil {
+		return nil, err
+	}
+	return response.Findings, nil
+}
+
+// triage sends a triage request to path.
+func (c *Client) triage(ctx context.Context, path string, triage TriageRequest, out any) error {
+	body, err := json.Marshal(triage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	header := http.Header{"Content-Type": {"application/json"}}
+	return c.do(ctx, http.MethodPatch, path, nil, header, body, out)
+}
+
+// Config returns the server's configuration file, or nil if it has none.
+// It requires the admin role.
+func (c *Client) Config(ctx context.Context) ([]byte, error) {
+	var document []byte
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/config", nil, nil, nil, &document); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return document, nil
+}
+
+// ApplyConfig replaces the server's configuration file with document and
+// reloads it, returning the configuration now in effect. An invalid
+// document is rejected with an *APIError and the current file kept. It
+// requires the admin role.
+func (c *Client) ApplyConfig(ctx context.Context, document []byte) (*ReloadResponse, error) {
+	header := http.Header{"Content-Type": {"application/yaml"}}
+	var response ReloadResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/admin/config", nil, header, document, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Waivers returns the waivers of the server's configuration file. It
+// requires the admin role.
+func (c *Client) Waivers(ctx context.Context) ([]Waiver, error) {
+	var response waiversBody
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/waivers", nil, nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Waivers, nil
+}
+
+// AddWaiver adds a waiver to the server's configuration file and returns
+// the waivers now in effect. An invalid waiver is rejected with a 422
+// APIError. It requires the admin role.
+func (c *Client) AddWaiver(ctx context.Context, waiver Waiver) ([]Waiver, error) {
+	return c.sendWaivers(ctx, http.MethodPost, waiver)
+}
+
+// SetWaivers replaces the waivers of the server's configuration file and
+// returns the waivers now in effect. It requires the admin role.
+func (c *Client) SetWaivers(ctx context.Context, waivers []Waiver) ([]Waiver, error) {
+	if waivers == nil {
+		waivers = []Waiver{}
+	}
+	return c.sendWaivers(ctx, http.MethodPut, waiversBody{Waivers: waivers})
+}
+
+// DeleteWaiver removes the waivers with the given match from the server's
+// configuration file, reporting whether there were any. The server has no
+// endpoint deleting one waiver, so the other waivers are written back; a
+// waiver added meanwhile by another admin is lost. It requires the admin
+// role.
+func (c *Client) DeleteWaiver(ctx context.Context, match string) (bool, error) {
+	waivers, err := c.Waivers(ctx)
+	if err != nil {
+		return false, err
+	}
+	kept := make([]Waiver, 0, len(waivers))
+	for _, waiver := range waivers {
+		if waiver.Match != match {
+			kept = append(kept, waiver)
+		}
+	}
+	if len(kept) == len(waivers) {
+		return false, nil
+	}
+	if _, err := c.SetWaivers(ctx, kept); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sendWaivers sends body to the waivers endpoint and returns the waivers in
+// effect.
+func (c *Client) sendWaivers(ctx context.Context, method string, body any) ([]Waiver, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	var response waiversBody
+	header := http.Header{"Content-Type": {"application/json"}}
+	if err := c.do(ctx, method, "/api/v1/admin/waivers", nil, header, data, &response); err != nil {
+		return nil, err
+	}
+	return response.Waivers, nil
+}
+
+// RotateAPIKey replaces the API key with the given name in the server's
+// configuration file with a new random key, which is only ever returned
+// here. The old key stops working at once. It requires the admin role.
+func (c *Client) RotateAPIKey(ctx context.Context, name string) (*APIKeyRotation, error) {
+	var rotation APIKeyRotation
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/api-keys/"+url.PathEscape(name)+"/rotate", nil, nil, nil, &rotation); err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
+
+// do sends a request and decodes its JSON response into result, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body []byte, result interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		// A bytes.Reader lets the transport replay the body when retrying
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to SBOM Sentinel: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var response errorResponse
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&response); err == nil {
+			apiErr.Code = response.Error
+			apiErr.Message = response.Message
+			apiErr.Violations = response.Violations
+			apiErr.ExistingID = response.ExistingID
+		}
+		return apiErr
+	}
+
+	if raw, ok := result.(*[]byte); ok {
+		if *raw, err = io.ReadAll(resp.Body); err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return nil
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// setIdempotencyKey sets the Idempotency-Key of a request, a random one if
+// key is empty.
+func setIdempotencyKey(header http.Header, key string) {
+	if key == "" {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			// Without a key retries are still sent, only not deduplicated
+			return
+		}
+		key = hex.EncodeToString(random)
+	}
+	header.Set(idempotencyKeyHeader, key)
+}
+
+// IsNotFound reports whether err is an APIError for a missing SBOM, job,
+// watch or API key.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound && apiErr.Code == "not_found"
+}
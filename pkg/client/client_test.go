@@ -0,0 +1,360 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hueyexe/SBOM-Sentinel/internal/config"
+	"github.com/hueyexe/SBOM-Sentinel/internal/config/configtest"
+	"github.com/hueyexe/SBOM-Sentinel/internal/jobs"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage"
+	"github.com/hueyexe/SBOM-Sentinel/internal/platform/storage/memory"
+	"github.com/hueyexe/SBOM-Sentinel/internal/transport/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `{"bomFormat": "CycloneDX", "specVersion": "1.4", "metadata": {"component": {"type": "application", "name": "api"}},
+	"components": [{"type": "library", "name": "readline", "version": "8.2", "licenses": [{"license": {"id": "GPL-3.0-only"}}]}]}`
+
+// newServer serves the API routes the client uses from memory. The first
+// response to the first submission is lost, as on a flaky network, after
+// the SBOM was stored.
+func newServer(t *testing.T) *httptest.Server {
+	repo := memory.NewMemoryRepository()
+	queue := jobs.NewMemoryQueue()
+	idempotency := rest.NewIdempotency(repo)
+	auth := rest.NewAuthorizer(map[string]rest.Role{"secret": rest.RoleAnalyst})
+
+	var once sync.Once
+	submit := idempotency.Idempotent(rest.SubmitSBOMHandler(repo))
+	flakySubmit := func(w http.ResponseWriter, r *http.Request) {
+		lost := false
+		once.Do(func() { lost = true })
+		if !lost {
+			submit(w, r)
+			return
+		}
+		submit(httptest.NewRecorder(), r)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sboms", auth.Require(rest.RoleViewer, rest.ListSBOMsHandler(repo, flakySubmit)))
+	mux.HandleFunc("/api/v1/sboms/{id}", auth.Require(rest.RoleViewer, rest.GetSBOMHandler(repo)))
+	mux.HandleFunc("/api/v1/sboms/{id}/tags", auth.Require(rest.RoleAnalyst, rest.SBOMTagsHandler(repo, repo)))
+	mux.HandleFunc("/api/v1/sboms/{id}/analyze", auth.Require(rest.RoleAnalyst, idempotency.Idempotent(rest.AsyncAnalyzeHandler(repo, queue, rest.AnalyzeSBOMHandler(repo, nil)))))
+	mux.HandleFunc("/api/v1/jobs/{id}", auth.Require(rest.RoleAnalyst, rest.JobHandler(queue)))
+	mux.HandleFunc("/api/v1/watchlist", auth.Require(rest.RoleAnalyst, rest.WatchlistHandler(repo)))
+	mux.HandleFunc("/api/v1/watchlist/{id}", auth.Require(rest.RoleAnalyst, rest.WatchlistHandler(repo)))
+	mux.HandleFunc("/api/v1/owners", auth.Require(rest.RoleViewer, rest.OwnersHandler()))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// Analyses queued by the test are run in the background
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		jobs.Work(ctx, queue, 1, rest.AnalysisJobHandler(repo, nil))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return server
+}
+
+// newClient returns a client of server that retries without delay.
+func newClient(server *httptest.Server, options ...Option) *Client {
+	config := httpclient.Config{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	httpClient := &http.Client{Transport: httpclient.NewTransport(nil, config)}
+	return New(server.URL+"/", append([]Option{WithHTTPClient(httpClient)}, options...)...)
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+	c := newClient(server, WithAPIKey("secret"))
+
+	// The retried submission stores the SBOM once and returns the first response
+	submitted, err := c.Submit(ctx, "sbom.json", []byte(document), SubmitOptions{})
+	require.NoError(t, err)
+	assert.False(t, submitted.Duplicate)
+	records, err := c.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, submitted.ID, records[0].ID)
+
+	sbom, err := c.Get(ctx, submitted.ID)
+	require.NoError(t, err)
+	require.NotNil(t, sbom)
+	assert.Equal(t, "readline", sbom.Components[0].Name)
+	sbom, err = c.Get(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, sbom)
+
+	analysis, err := c.Analyze(ctx, submitted.ID, AnalyzeOptions{MinSeverity: "low"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, analysis.Summary.TotalFindings)
+	_, err = c.Analyze(ctx, "unknown", AnalyzeOptions{})
+	assert.True(t, IsNotFound(err))
+
+	job, err := c.AnalyzeAsync(ctx, submitted.ID, AnalyzeOptions{Parameters: url.Values{"profile": {"quick"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "profile=quick", job.Parameters)
+	job, err = c.WaitForJob(ctx, job.ID, 10*time.Millisecond)
+	require.NoError(t, err)
+	result, err := JobResult(job)
+	require.NoError(t, err)
+	assert.Equal(t, submitted.ID, result.SBOMID)
+
+	// Finished jobs cannot be canceled
+	_, err = c.CancelJob(ctx, job.ID)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	missing, err := c.Job(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	watch, err := c.AddWatch(ctx, "pkg:npm/lodash", "used everywhere")
+	require.NoError(t, err)
+	watches, err := c.ListWatches(ctx)
+	require.NoError(t, err)
+	assert.Len(t, watches, 1)
+	deleted, err := c.DeleteWatch(ctx, watch.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = c.DeleteWatch(ctx, watch.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestClient_Tags(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+	c := newClient(server, WithAPIKey("secret"))
+
+	submitted, err := c.Submit(ctx, "sbom.json", []byte(document), SubmitOptions{Tags: map[string]string{"env": "prod", "team": "web"}})
+	require.NoError(t, err)
+	records, err := c.ListMatching(ctx, ListOptions{Tags: map[string]string{"env": "prod", "team": "web"}})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "web"}, records[0].Tags)
+
+	tags, err := c.UpdateTags(ctx, submitted.ID, map[string]string{"criticality": "high"}, []string{"team"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "criticality": "high"}, tags.Tags)
+	tags, err = c.SetTags(ctx, submitted.ID, map[string]string{"env": "staging"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "staging"}, tags.Tags)
+	tags, err = c.Tags(ctx, submitted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "staging"}, tags.EffectiveTags)
+
+	records, err = c.ListMatching(ctx, ListOptions{Tags: map[string]string{"env": "prod"}})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	tags, err = c.Tags(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, tags)
+}
+
+func TestClient_Owners(t *testing.T) {
+	configtest.Set(t, "owners:\n  web:\n    match: [pkg:npm/@acme/]\n")
+
+	owners, err := newClient(newServer(t), WithAPIKey("secret")).Owners(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []TeamOwnership{{Name: "web", Match: []string{"pkg:npm/@acme/"}}}, owners)
+}
+
+func TestClient_TriageFindings(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemoryRepository()
+	_, err := repo.RecordAnalysis(ctx, storage.AnalysisRun{SBOMID: "api-1", Project: "api", AnalyzedAt: time.Now()},
+		[]string{"Vulnerability Scanner"}, []storage.FindingRecord{
+			{Fingerprint: "a", AgentName: "Vulnerability Scanner", Severity: "High"},
+			{Fingerprint: "b", AgentName: "Vulnerability Scanner", Severity: "Low"},
+		})
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/{id}/findings", rest.TriageFindingsHandler(repo, repo, rest.ProjectFindingsHandler(repo)))
+	mux.HandleFunc("/api/v1/projects/{id}/findings/{fingerprint}", rest.TriageFindingsHandler(repo, repo, rest.ProjectFindingHandler(repo)))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := newClient(server)
+
+	state, assignee := storage.TriageInProgress, "alice"
+	finding, err := c.TriageFinding(ctx, "api", "a", TriageRequest{State: &state, Assignee: &assignee})
+	require.NoError(t, err)
+	assert.Equal(t, TriageInProgress, finding.Triage)
+	assert.Equal(t, "alice", finding.Assignee)
+
+	state = storage.TriageFalsePositive
+	findings, err := c.TriageFindings(ctx, "api", TriageRequest{Fingerprints: []string{"a", "b"}, State: &state})
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, TriageFalsePositive, findings[1].Triage)
+
+	timeline, err := c.ProjectFindings(ctx, "api", FindingOptions{Assignee: "alice"})
+	require.NoError(t, err)
+	require.Len(t, timeline.Findings, 1)
+	assert.Equal(t, "a", timeline.Findings[0].Fingerprint)
+	assert.Equal(t, 2, timeline.Open)
+
+	// No SLA is configured, so nothing is overdue
+	timeline, err = c.ProjectFindings(ctx, "api", FindingOptions{Overdue: true})
+	require.NoError(t, err)
+	assert.Empty(t, timeline.Findings)
+
+	timeline, err = c.ProjectFindings(ctx, "web", FindingOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, timeline)
+
+	_, err = c.TriageFinding(ctx, "api", "c", TriageRequest{State: &state})
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+
+	_, err := newClient(server).List(ctx, "")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.Equal(t, "unauthorized", apiErr.Code)
+
+	c := newClient(server, WithAPIKey("secret"))
+	_, err = c.Submit(ctx, "sbom.json", []byte(document), SubmitOptions{})
+	require.NoError(t, err)
+	_, err = c.Submit(ctx, "bad.json", []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4", "components": [{"type": "gadget"}]}`), SubmitOptions{})
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.NotEmpty(t, apiErr.Violations)
+
+	_, err = JobResult(&Job{ID: "job-1", Status: StatusFailed, Error: "SBOM not found"})
+	assert.EqualError(t, err, "job job-1 failed: SBOM not found")
+}
+
+func TestClient_Config(t *testing.T) {
+	ctx := context.Background()
+	configtest.SetFile(t, filepath.Join(t.TempDir(), "sentinel.yaml"))
+	server := httptest.NewServer(rest.ConfigHandler())
+	t.Cleanup(server.Close)
+	c := New(server.URL)
+
+	document, err := c.Config(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, document)
+
+	applied := []byte("projects:\n  storefront:\n    distribution: saas\n")
+	response, err := c.ApplyConfig(ctx, applied)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Projects)
+	document, err = c.Config(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, applied, document)
+
+	_, err = c.ApplyConfig(ctx, []byte("profiles: [quick]\n"))
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "invalid_config", apiErr.Code)
+}
+
+func TestClient_Admin(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sentinel.yaml")
+	configtest.SetFile(t, path)
+	t.Setenv("API_KEYS", "")
+	require.NoError(t, os.WriteFile(path, []byte("api_keys:\n  - name: ci\n    key: old-key\n    role: analyst\n"), 0o600))
+	_, err := config.Reload()
+	require.NoError(t, err)
+
+	repo := memory.NewMemoryRepository()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sboms", rest.ListSBOMsHandler(repo, rest.SubmitSBOMHandler(repo)))
+	mux.HandleFunc("/api/v1/sboms/{id}", rest.DeleteSBOMHandler(repo, repo, rest.GetSBOMHandler(repo)))
+	mux.HandleFunc("/api/v1/admin/waivers", rest.WaiversHandler())
+	mux.HandleFunc("/api/v1/admin/api-keys/{name}/rotate", rest.RotateAPIKeyHandler())
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	c := newClient(server)
+
+	submitted, err := c.Submit(ctx, "sbom.json", []byte(document), SubmitOptions{})
+	require.NoError(t, err)
+	deleted, err := c.DeleteSBOM(ctx, submitted.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = c.DeleteSBOM(ctx, submitted.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	waivers, err := c.AddWaiver(ctx, Waiver{Match: "vuln:CVE-2021-44228", Reason: "JNDI lookups are disabled", Projects: []string{"api"}})
+	require.NoError(t, err)
+	require.Len(t, waivers, 1)
+	_, err = c.AddWaiver(ctx, Waiver{Match: "component:left-pad", Reason: "Vendored"})
+	require.NoError(t, err)
+	waivers, err = c.Waivers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Waiver{
+		{Match: "vuln:CVE-2021-44228", Reason: "JNDI lookups are disabled", Projects: []string{"api"}},
+		{Match: "component:left-pad", Reason: "Vendored"},
+	}, waivers)
+
+	deleted, err = c.DeleteWaiver(ctx, "vuln:CVE-2021-44228")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = c.DeleteWaiver(ctx, "vuln:CVE-2021-44228")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.Len(t, config.Default().Waivers, 1)
+
+	_, err = c.AddWaiver(ctx, Waiver{Match: "vuln:CVE-2024-0001"})
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	waivers, err = c.SetWaivers(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, waivers)
+
+	rotation, err := c.RotateAPIKey(ctx, "ci")
+	require.NoError(t, err)
+	assert.Equal(t, "analyst", rotation.Role)
+	assert.NotEqual(t, "old-key", rotation.Key)
+	_, err = c.RotateAPIKey(ctx, "unknown")
+	assert.True(t, IsNotFound(err))
+}
+
+// TestClient_Imports keeps the package free of the server's packages, so
+// that services using it do not build the server's dependencies.
+func TestClient_Imports(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+		require.NoError(t, err)
+		for _, spec := range parsed.Imports {
+			path := strings.Trim(spec.Path.Value, `"`)
+			if strings.HasPrefix(path, "github.com/hueyexe/SBOM-Sentinel/") {
+				assert.Equal(t, "github.com/hueyexe/SBOM-Sentinel/internal/platform/httpclient", path, file)
+			}
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SBOM is a stored Software Bill of Materials.
+type SBOM struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Components []Component `json:"components"`
+	Services   []Service   `json:"services,omitempty"`
+	// Metadata holds document details such as "serialNumber" and "specVersion"
+	Metadata map[string]string `json:"metadata"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// Component is a software component of an SBOM.
+type Component struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl"`
+	CPE     string `json:"cpe,omitempty"`
+	// License is the first of Licenses
+	License            string              `json:"license"`
+	Licenses           []string            `json:"licenses,omitempty"`
+	Supplier           string              `json:"supplier,omitempty"`
+	Type               string              `json:"type,omitempty"`
+	Scope              string              `json:"scope,omitempty"`
+	Evidence           *Evidence           `json:"evidence,omitempty"`
+	ExternalReferences []ExternalReference `json:"external_references,omitempty"`
+	// Parent is the key of the assembly the component is nested in
+	Parent string `json:"parent,omitempty"`
+}
+
+// Evidence records how a component was identified.
+type Evidence struct {
+	Confidence  float64  `json:"confidence,omitempty"`
+	Occurrences []string `json:"occurrences,omitempty"`
+	Licenses    []string `json:"licenses,omitempty"`
+	Copyright   []string `json:"copyright,omitempty"`
+}
+
+// ExternalReference links to a resource related to a component or service,
+// such as its "vcs", "website" or "issue-tracker".
+type ExternalReference struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Service is an external service the software of an SBOM depends on.
+type Service struct {
+	Name                 string              `json:"name"`
+	Version              string              `json:"version,omitempty"`
+	Provider             string              `json:"provider,omitempty"`
+	Endpoints            []string            `json:"endpoints,omitempty"`
+	Authenticated        *bool               `json:"authenticated,omitempty"`
+	CrossesTrustBoundary bool                `json:"crosses_trust_boundary,omitempty"`
+	ExternalReferences   []ExternalReference `json:"external_references,omitempty"`
+}
+
+// AnalysisResult is a finding of an analysis agent.
+type AnalysisResult struct {
+	AgentName string     `json:"agent_name"`
+	Finding   string     `json:"finding"`
+	Severity  string     `json:"severity"`
+	Citations []Citation `json:"citations,omitempty"`
+	// Component is the component the finding is about, if any
+	Component       *ComponentRef `json:"component,omitempty"`
+	VulnerabilityID string        `json:"vulnerability_id,omitempty"`
+	FixedVersion    string        `json:"fixed_version,omitempty"`
+	UpgradeTo       string        `json:"upgrade_to,omitempty"`
+	RuleID          string        `json:"rule_id,omitempty"`
+	// Confidence is set for findings of LLM agents, from 0 to 1
+	Confidence *float64 `json:"confidence,omitempty"`
+	Owner      string   `json:"owner,omitempty"`
+}
+
+// ComponentRef identifies the component of a finding.
+type ComponentRef struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+// Citation is a document supporting a finding.
+type Citation struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title,omitempty"`
+	Source     string  `json:"source,omitempty"`
+	URL        string  `json:"url,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+// SBOMRecord describes a stored SBOM in a list.
+type SBOMRecord struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	CreatedAt time.Time         `json:"created_at"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// Violation is a schema violation of a rejected SBOM.
+type Violation struct {
+	// Path is the JSON Pointer of the offending value
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// SubmitResponse is the response to a submitted SBOM.
+type SubmitResponse struct {
+	ID            string               `json:"id"`
+	Message       string               `json:"message"`
+	Normalization *NormalizationReport `json:"normalization,omitempty"`
+	// Duplicate is set when an identical SBOM was already stored and ID is
+	// that of the stored SBOM
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Replaced is set when the SBOM replaced the stored SBOM with the same
+	// serial number, keeping its ID
+	Replaced bool `json:"replaced,omitempty"`
+}
+
+// NormalizationReport counts the changes made to a submitted SBOM.
+type NormalizationReport struct {
+	PURLsNormalized       int      `json:"purls_normalized"`
+	LicensesCanonicalized int      `json:"licenses_canonicalized"`
+	DuplicatesRemoved     int      `json:"duplicates_removed"`
+	Changes               []string `json:"changes,omitempty"`
+	InvalidPURLs          int      `json:"invalid_purls"`
+}
+
+// AnalysisResponse is the analysis of a stored SBOM.
+type AnalysisResponse struct {
+	SBOMID  string           `json:"sbom_id"`
+	Results []AnalysisResult `json:"results"`
+	Summary AnalysisSummary  `json:"summary"`
+}
+
+// AnalysisSummary summarizes the findings and agents of an analysis.
+type AnalysisSummary struct {
+	TotalFindings      int            `json:"total_findings"`
+	FindingsBySeverity map[string]int `json:"findings_by_severity"`
+	AgentsRun          []string       `json:"agents_run"`
+	Risk               RiskScore      `json:"risk"`
+	// AgentStatus is "ok", "failed" or "timeout", by agent name
+	AgentStatus     map[string]string            `json:"agent_status,omitempty"`
+	AgentParameters map[string]map[string]string `json:"agent_parameters,omitempty"`
+	// AgentErrors lists the agents that failed or timed out; when present
+	// the results are incomplete
+	AgentErrors []AgentError          `json:"agent_errors,omitempty"`
+	AgentUsage  map[string]AgentUsage `json:"agent_usage,omitempty"`
+	Policy      *PolicyResult         `json:"policy,omitempty"`
+	// HiddenFindings counts the findings left out by MinSeverity and
+	// MinConfidence
+	HiddenFindings int `json:"hidden_findings,omitempty"`
+	// Waived lists the findings excluded by waivers
+	Waived    []WaivedFinding `json:"waived,omitempty"`
+	Lifecycle *AnalysisRun    `json:"lifecycle,omitempty"`
+}
+
+// RiskScore is the composite risk score of the findings of an analysis.
+type RiskScore struct {
+	Score  int                `json:"score"`
+	Level  string             `json:"level"`
+	Points map[string]float64 `json:"points,omitempty"`
+}
+
+// AgentError describes an agent that failed or timed out.
+type AgentError struct {
+	Agent  string `json:"agent"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// AgentUsage describes the capacity an agent used during an analysis.
+type AgentUsage struct {
+	WallTimeMS int64     `json:"wall_time_ms"`
+	LLM        *LLMUsage `json:"llm,omitempty"`
+}
+
+// LLMUsage describes the LLM requests of an agent.
+type LLMUsage struct {
+	Requests         int   `json:"requests"`
+	PromptTokens     int   `json:"prompt_tokens"`
+	CompletionTokens int   `json:"completion_tokens"`
+	TotalTokens      int   `json:"total_tokens"`
+	DurationMS       int64 `json:"duration_ms"`
+}
+
+// PolicyResult reports whether the findings pass the gate policies.
+type PolicyResult struct {
+	Passed     bool     `json:"passed"`
+	Violations []string `json:"violations"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// WaivedFinding is a finding excluded by a waiver, with its reason.
+type WaivedFinding struct {
+	AnalysisResult
+	Reason string `json:"reason"`
+}
+
+// Job statuses.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Job priorities. Interactive jobs are processed before any scheduled job.
+const (
+	PriorityInteractive = "interactive"
+	PriorityScheduled   = "scheduled"
+)
+
+// Job is an analysis of a stored SBOM, run asynchronously.
+type Job struct {
+	ID         string `json:"id"`
+	SBOMID     string `json:"sbom_id"`
+	Parameters string `json:"parameters,omitempty"`
+	Priority   string `json:"priority"`
+	Status     string `json:"status"`
+	// Error describes why a failed job failed
+	Error string `json:"error,omitempty"`
+	// Result is the analysis response of a succeeded job; see JobResult
+	Result     json.RawMessage `json:"result,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job succeeded, failed or was canceled.
+func (j Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCanceled
+}
+
+// Watch is a watched package.
+type Watch struct {
+	ID        string    `json:"id"`
+	PURL      string    `json:"purl"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagsResponse lists the tags of a stored SBOM.
+type TagsResponse struct {
+	ID   string            `json:"id"`
+	Tags map[string]string `json:"tags"`
+	// EffectiveTags adds the tags of the SBOM's project
+	EffectiveTags map[string]string `json:"effective_tags"`
+}
+
+// TeamOwnership describes the components a team owns.
+type TeamOwnership struct {
+	Name     string   `json:"name"`
+	Match    []string `json:"match"`
+	Channels []string `json:"channels,omitempty"`
+}
+
+// Finding lifecycle statuses.
+const (
+	FindingOpen     = "open"
+	FindingResolved = "resolved"
+)
+
+// Finding triage states.
+const (
+	TriageNew           = "new"
+	TriageAcknowledged  = "acknowledged"
+	TriageInProgress    = "in-progress"
+	TriageResolved      = "resolved"
+	TriageFalsePositive = "false-positive"
+)
+
+// Timeline is the finding lifecycle of a project.
+type Timeline struct {
+	Project  string        `json:"project"`
+	Runs     []AnalysisRun `json:"runs"`
+	Open     int           `json:"open"`
+	Resolved int           `json:"resolved"`
+	Overdue  int           `json:"overdue"`
+	// Triage counts the open findings by triage state
+	Triage                        map[string]int     `json:"triage,omitempty"`
+	MeanTimeToRemediateHours      *float64           `json:"mean_time_to_remediate_hours,omitempty"`
+	MeanTimeToRemediateBySeverity map[string]float64 `json:"mean_time_to_remediate_by_severity,omitempty"`
+	Findings                      []FindingRecord    `json:"findings"`
+}
+
+// AnalysisRun records how an analysis changed the findings of a project.
+type AnalysisRun struct {
+	SBOMID     string    `json:"sbom_id"`
+	Project    string    `json:"project"`
+	AnalyzedAt time.Time `json:"analyzed_at"`
+	New        int       `json:"new"`
+	Recurring  int       `json:"recurring"`
+	Resolved   int       `json:"resolved"`
+	Open       int       `json:"open"`
+}
+
+// FindingRecord is a finding tracked across the analyses of a project.
+type FindingRecord struct {
+	Fingerprint    string     `json:"fingerprint"`
+	AgentName      string     `json:"agent_name"`
+	Severity       string     `json:"severity"`
+	Finding        string     `json:"finding"`
+	Component      string     `json:"component,omitempty"`
+	Status         string     `json:"status"`
+	FirstSeen      time.Time  `json:"first_seen"`
+	FirstSBOMID    string     `json:"first_sbom_id"`
+	LastSeen       time.Time  `json:"last_seen"`
+	LastSBOMID     string     `json:"last_sbom_id"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ResolvedSBOMID string     `json:"resolved_sbom_id,omitempty"`
+	Triage         string     `json:"triage"`
+	Assignee       string     `json:"assignee,omitempty"`
+	TriageNote     string     `json:"triage_note,omitempty"`
+	TriagedAt      *time.Time `json:"triaged_at,omitempty"`
+	DueAt          *time.Time `json:"due_at,omitempty"`
+	Overdue        bool       `json:"overdue,omitempty"`
+	SLANotifiedAt  *time.Time `json:"sla_notified_at,omitempty"`
+}
+
+// TriageRequest changes the triage of tracked findings. Nil fields are left
+// unchanged.
+type TriageRequest struct {
+	// Fingerprints lists the findings changed by TriageFindings
+	Fingerprints []string `json:"fingerprints,omitempty"`
+	State        *string  `json:"state,omitempty"`
+	Assignee     *string  `json:"assignee,omitempty"`
+	Note         *string  `json:"note,omitempty"`
+}
+
+// ReloadResponse summarizes the configuration in effect after a reload.
+type ReloadResponse struct {
+	Message              string   `json:"message"`
+	Profiles             []string `json:"profiles"`
+	Projects             int      `json:"projects"`
+	NotificationChannels int      `json:"notification_channels"`
+	Rules                int      `json:"rules"`
+	APIKeys              int      `json:"api_keys"`
+	ExpressionRules      int      `json:"expression_rules"`
+	PolicyGate           bool     `json:"policy_gate"`
+}
+
+// Waiver excludes the findings matching Match from analyses, such as
+// "vuln:CVE-2021-44228" or "pkg:npm/lodash".
+type Waiver struct {
+	Match  string `json:"match"`
+	Reason string `json:"reason"`
+	// Projects limits the waiver to SBOMs of these names; empty waives the
+	// findings of every project
+	Projects []string `json:"projects,omitempty"`
+}
+
+// APIKeyRotation is a rotated API key. The new key is only ever shown once.
+type APIKeyRotation struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	Key  string `json:"key"`
+}
+
+// Bodies of requests and list responses.
+type (
+	sbomListResponse struct {
+		SBOMs []SBOMRecord `json:"sboms"`
+	}
+	tagsRequest struct {
+		Tags map[string]*string `json:"tags"`
+	}
+	watchRequest struct {
+		PURL string `json:"purl"`
+		Note string `json:"note,omitempty"`
+	}
+	watchlistResponse struct {
+		Watches []Watch `json:"watches"`
+	}
+	ownersResponse struct {
+		Teams []TeamOwnership `json:"teams"`
+	}
+	triageResponse struct {
+		Findings []FindingRecord `json:"findings"`
+	}
+	waiversBody struct {
+		Waivers []Waiver `json:"waivers"`
+	}
+	errorResponse struct {
+		Error      string      `json:"error"`
+		Message    string      `json:"message"`
+		Violations []Violation `json:"violations,omitempty"`
+		ExistingID string      `json:"existing_id,omitempty"`
+	}
+)
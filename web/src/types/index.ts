@@ -3,8 +3,10 @@ export interface SbomComponent {
   name: string;
   version: string;
   license?: string;
+  licenses?: string[];
   purl?: string;
   type?: string;
+  scope?: 'required' | 'optional' | 'excluded';
 }
 
 export interface SbomMetadata {